@@ -229,6 +229,17 @@ func (gc *GenericContainer[T, U]) Iter() <-chan T {
 	return ch
 }
 
+// Function iterator (Go 1.23 range-over-func)
+func (gc *GenericContainer[T, U]) All() func(yield func(int, T) bool) {
+	return func(yield func(int, T) bool) {
+		for i, item := range gc.items {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
 // Function with various parameter types
 func ComplexFunction[T any, U fmt.Stringer](
 	reference string,
@@ -362,6 +373,11 @@ func main() {
 		break // Just test one item
 	}
 
+	// Test function iterators
+	for i, item := range container.All() {
+		fmt.Printf("Item %d: %d\n", i, item)
+	}
+
 	// Test async operation
 	ctx := context.Background()
 	resultChan := AsyncOperation(ctx, "https://example.com")
@@ -0,0 +1,290 @@
+//! Analyze command implementations using UnifiedOutput schema
+//!
+//! Analyses run over the syntax trees of the indexed Go files (see
+//! [`crate::parsing::go::analysis`]) and report findings rather than symbols.
+
+use crate::SimpleIndexer;
+use crate::io::{
+    EntityType, ExitCode, OutputFormat, OutputManager,
+    schema::{OutputMetadata, UnifiedOutputBuilder},
+};
+use crate::parsing::go::analysis::{self, GoSourceFile};
+use serde::Serialize;
+use std::borrow::Cow;
+use std::fmt::Display;
+
+/// Parse every indexed Go file
+///
+/// Files that can no longer be read are skipped with a warning.
+pub fn load_go_files(indexer: &SimpleIndexer) -> Vec<GoSourceFile> {
+    let mut paths: Vec<_> = indexer
+        .get_all_indexed_paths()
+        .into_iter()
+        .filter(|p| p.extension().is_some_and(|ext| ext == "go"))
+        .collect();
+    paths.sort();
+
+    paths
+        .iter()
+        .filter_map(|path| match GoSourceFile::read(path) {
+            Ok(file) => Some(file),
+            Err(e) => {
+                eprintln!("Warning: {e}");
+                None
+            }
+        })
+        .collect()
+}
+
+/// Write findings using the unified output schema
+fn write_findings<T: Serialize + Display>(
+    findings: Vec<T>,
+    query: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    let mut output = OutputManager::new(format);
+
+    let unified = UnifiedOutputBuilder::items(findings, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(query)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute analyze env-vars command
+pub fn analyze_env_vars(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let reads = analysis::find_env_var_reads(&files);
+    write_findings(reads, "env-vars", format)
+}
+
+/// Execute analyze unwrapped-errors command
+pub fn analyze_unwrapped_errors(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_unwrapped_error_returns(&files);
+    write_findings(findings, "unwrapped-errors", format)
+}
+
+/// Execute analyze unchecked-errors command
+///
+/// With `allow_blank`, errors assigned to `_` are accepted.
+pub fn analyze_unchecked_errors(
+    indexer: &SimpleIndexer,
+    allow_blank: bool,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings =
+        analysis::find_unchecked_errors(&files, allow_blank, indexer.settings().project.stdlib);
+    write_findings(findings, "unchecked-errors", format)
+}
+
+/// Execute analyze enum-literals command
+pub fn analyze_enum_literals(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let enums = analysis::EnumTable::build(&files);
+    let findings = analysis::find_enum_literal_comparisons(&files, &enums);
+    write_findings(findings, "enum-literals", format)
+}
+
+/// Execute analyze exhaustive command
+pub fn analyze_exhaustive(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let enums = analysis::EnumTable::build(&files);
+    let findings = analysis::find_non_exhaustive_switches(&files, &enums);
+    write_findings(findings, "exhaustive", format)
+}
+
+/// Execute analyze nil-receivers command
+pub fn analyze_nil_receivers(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_nil_receiver_calls(&files);
+    write_findings(findings, "nil-receivers", format)
+}
+
+/// Execute analyze broad-interfaces command
+pub fn analyze_broad_interfaces(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_broad_interface_params(&files, indexer.settings().project.stdlib);
+    write_findings(findings, "broad-interfaces", format)
+}
+
+/// Execute analyze lock-imbalance command
+pub fn analyze_lock_imbalance(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_lock_imbalances(&files);
+    write_findings(findings, "lock-imbalance", format)
+}
+
+/// Execute analyze printf-args command
+pub fn analyze_printf_args(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_printf_mismatches(&files);
+    write_findings(findings, "printf-args", format)
+}
+
+/// Execute analyze interface-pollution command
+pub fn analyze_interface_pollution(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_sole_implementors(
+        &files,
+        indexer.settings().project.implements_include_tests,
+        indexer.settings().project.stdlib,
+    );
+    write_findings(findings, "interface-pollution", format)
+}
+
+/// Execute analyze channels command
+pub fn analyze_channels(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let channels = analysis::find_channels(&files);
+    write_findings(channels, "channels", format)
+}
+
+/// Execute analyze channel-flow command
+pub fn analyze_channel_flow(
+    indexer: &SimpleIndexer,
+    channel: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let flows = analysis::find_channel_flow(&files, channel);
+    if flows.is_empty() {
+        eprintln!("No Go channel named '{channel}' in the index");
+        return ExitCode::NotFound;
+    }
+    write_findings(flows, "channel-flow", format)
+}
+
+/// Execute analyze missing-docs command
+pub fn analyze_missing_docs(
+    indexer: &SimpleIndexer,
+    include_interface_methods: bool,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_missing_docs(
+        &files,
+        include_interface_methods,
+        indexer.settings().project.implements_include_tests,
+        indexer.settings().project.stdlib,
+    );
+    write_findings(findings, "missing-docs", format)
+}
+
+/// Execute analyze import-cycles command
+///
+/// Imports into the module that match no indexed package can't close a
+/// cycle; they are warned about so that no cycles isn't mistaken for a
+/// complete answer.
+pub fn analyze_import_cycles(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let graph = analysis::PackageGraph::build(&files);
+    for import in graph.unresolved() {
+        eprintln!("Warning: {import}");
+    }
+    let cycles = analysis::find_import_cycles(&graph);
+    write_findings(cycles, "import-cycles", format)
+}
+
+/// Execute analyze test-coverage command
+pub fn analyze_test_coverage(
+    indexer: &SimpleIndexer,
+    package: Option<&str>,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let coverage = analysis::find_test_coverage(&files, package);
+    if let Some(package) = package.filter(|_| coverage.is_empty()) {
+        eprintln!("No exported symbols of Go package '{package}' in the index");
+        return ExitCode::NotFound;
+    }
+    write_findings(coverage, "test-coverage", format)
+}
+
+/// Execute analyze noreturn command
+pub fn analyze_noreturn(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let functions = analysis::find_noreturn_functions(&files);
+    write_findings(functions, "noreturn", format)
+}
+
+/// Execute analyze long-signatures command
+pub fn analyze_long_signatures(
+    indexer: &SimpleIndexer,
+    thresholds: analysis::SignatureThresholds,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_long_signatures(&files, thresholds);
+    write_findings(findings, "long-signatures", format)
+}
+
+/// Execute diagnostics duplicate-definitions command
+pub fn diagnose_duplicate_definitions(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_duplicate_definitions(&files);
+    write_findings(findings, "duplicate-definitions", format)
+}
+
+/// Execute diagnostics loop-var-capture command
+pub fn diagnose_loop_var_captures(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_loop_var_captures(&files);
+    write_findings(findings, "loop-var-capture", format)
+}
+
+/// Execute diagnostics ambiguous-selectors command
+pub fn diagnose_ambiguous_selectors(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    let files = load_go_files(indexer);
+    let findings = analysis::find_ambiguous_selectors(&files);
+    write_findings(findings, "ambiguous-selectors", format)
+}
+
+/// Execute analyze impact command
+pub fn analyze_impact(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    depth: u32,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let impacts = analysis::find_type_impact(&files, type_name, depth);
+    if impacts.is_empty() {
+        eprintln!("No Go type named '{type_name}' in the index");
+        return ExitCode::NotFound;
+    }
+    write_findings(impacts, "impact", format)
+}
+
+/// Execute analyze method-coverage command
+pub fn analyze_method_coverage(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let coverage = analysis::find_method_coverage(
+        &files,
+        type_name,
+        indexer.settings().project.implements_include_tests,
+        indexer.settings().project.stdlib,
+    );
+    if coverage.is_empty() {
+        eprintln!("No Go type named '{type_name}' in the index");
+        return ExitCode::NotFound;
+    }
+    write_findings(coverage, "method-coverage", format)
+}
@@ -14,6 +14,7 @@
 //! - `CI_MCP__DEBUG=true` sets `mcp.debug`
 //! - `CI_INDEXING__INCLUDE_TESTS=false` sets `indexing.include_tests`
 
+use crate::project_config::ProjectConfig;
 use figment::{
     Figment,
     providers::{Env, Format, Serialized, Toml},
@@ -72,6 +73,14 @@ pub struct Settings {
     /// AI guidance settings for multi-hop queries
     #[serde(default)]
     pub guidance: GuidanceConfig,
+
+    /// Comment markers listed by `retrieve todos`
+    #[serde(default)]
+    pub todos: TodosConfig,
+
+    /// Project configuration from `codanna.toml` (not serialized)
+    #[serde(skip)]
+    pub project: ProjectConfig,
 }
 
 #[derive(Debug, Deserialize, Serialize, Clone)]
@@ -192,6 +201,13 @@ pub struct GuidanceConfig {
     pub variables: HashMap<String, String>,
 }
 
+#[derive(Debug, Deserialize, Serialize, Clone)]
+pub struct TodosConfig {
+    /// Markers to look for in comments, matched as whole words
+    #[serde(default = "default_todo_markers")]
+    pub markers: Vec<String>,
+}
+
 #[derive(Debug, Deserialize, Serialize, Clone)]
 pub struct GuidanceTemplate {
     /// Template for no results
@@ -282,6 +298,8 @@ impl Default for Settings {
             file_watch: FileWatchConfig::default(),
             server: ServerConfig::default(),
             guidance: GuidanceConfig::default(),
+            todos: TodosConfig::default(),
+            project: ProjectConfig::default(),
         }
     }
 }
@@ -342,6 +360,14 @@ impl Default for ServerConfig {
     }
 }
 
+impl Default for TodosConfig {
+    fn default() -> Self {
+        Self {
+            markers: default_todo_markers(),
+        }
+    }
+}
+
 impl Default for GuidanceConfig {
     fn default() -> Self {
         Self {
@@ -444,6 +470,13 @@ fn default_guidance_templates() -> HashMap<String, GuidanceTemplate> {
     templates
 }
 
+fn default_todo_markers() -> Vec<String> {
+    crate::parsing::go::analysis::todos::DEFAULT_MARKERS
+        .iter()
+        .map(|m| m.to_string())
+        .collect()
+}
+
 fn default_guidance_variables() -> HashMap<String, String> {
     let mut vars = HashMap::new();
     vars.insert("project".to_string(), "codanna".to_string());
@@ -841,6 +874,11 @@ impl Settings {
                 result.push_str("\n# HTTP server bind address (only used when mode = \"http\" or --http flag)\n");
             } else if line.starts_with("watch_interval = ") {
                 result.push_str("\n# Watch interval for stdio mode in seconds (how often to check for file changes)\n");
+            } else if line == "[todos]" {
+                result.push_str("\n[todos]\n");
+                result.push_str("# Comment markers listed by: codanna retrieve todos\n");
+                prev_line_was_section = true;
+                continue;
             } else if line.starts_with("[languages.") {
                 if !in_languages_section {
                     result.push_str("\n# Language-specific settings\n");
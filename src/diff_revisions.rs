@@ -0,0 +1,112 @@
+//! Symbol-level changes between two git revisions
+//!
+//! `codanna diff-revisions v1.2.0 HEAD` diffs the two trees with git, then
+//! compares the declarations of every changed Go file (see
+//! [`crate::parsing::go::analysis::symbol_changes`]). File contents are read
+//! from the repository, so neither revision has to be checked out or
+//! indexed. Renamed files show up as removed from the old path and added to
+//! the new one.
+
+use crate::io::{
+    EntityType, ExitCode, OutputFormat, OutputManager,
+    schema::{OutputMetadata, UnifiedOutputBuilder},
+};
+use crate::parsing::go::analysis::{GoSourceFile, SymbolChange, diff_symbols};
+use git2::{Oid, Repository};
+use std::borrow::Cow;
+use std::collections::HashMap;
+use std::path::Path;
+
+/// Symbol changes of every Go file that differs between two revisions
+pub fn symbol_changes(
+    repo: &Repository,
+    old_rev: &str,
+    new_rev: &str,
+) -> Result<Vec<SymbolChange>, git2::Error> {
+    let old_tree = repo.revparse_single(old_rev)?.peel_to_tree()?;
+    let new_tree = repo.revparse_single(new_rev)?.peel_to_tree()?;
+    let diff = repo.diff_tree_to_tree(Some(&old_tree), Some(&new_tree), None)?;
+
+    let mut changes = Vec::new();
+    for delta in diff.deltas() {
+        let old = go_file(repo, delta.old_file().path(), delta.old_file().id())?;
+        let new = go_file(repo, delta.new_file().path(), delta.new_file().id())?;
+        if old.is_some() || new.is_some() {
+            changes.extend(diff_symbols(old.as_ref(), new.as_ref()));
+        }
+    }
+    changes.sort_by(|a, b| (a.change, &a.file, a.line).cmp(&(b.change, &b.file, b.line)));
+    Ok(changes)
+}
+
+/// Parse the blob of one side of a delta, if it is a Go file that exists
+fn go_file(
+    repo: &Repository,
+    path: Option<&Path>,
+    id: Oid,
+) -> Result<Option<GoSourceFile>, git2::Error> {
+    let Some(path) = path.filter(|p| p.extension().is_some_and(|ext| ext == "go")) else {
+        return Ok(None);
+    };
+    if id.is_zero() {
+        return Ok(None);
+    }
+    let blob = repo.find_blob(id)?;
+    let source = String::from_utf8_lossy(blob.content()).into_owned();
+    match GoSourceFile::parse(path, source) {
+        Ok(file) => Ok(Some(file)),
+        Err(e) => {
+            eprintln!("Warning: {}: {e}", path.display());
+            Ok(None)
+        }
+    }
+}
+
+/// Execute diff-revisions command
+///
+/// Changes are grouped by kind: `added`, `removed`, `signature-changed` and
+/// `body-changed`.
+pub fn diff_revisions(old_rev: &str, new_rev: &str, format: OutputFormat) -> ExitCode {
+    let repo = match Repository::discover(".") {
+        Ok(repo) => repo,
+        Err(e) => {
+            eprintln!("Error: not in a git repository: {}", e.message());
+            return ExitCode::GeneralError;
+        }
+    };
+    let changes = match symbol_changes(&repo, old_rev, new_rev) {
+        Ok(changes) => changes,
+        Err(e) if e.code() == git2::ErrorCode::NotFound => {
+            eprintln!("Error: {}", e.message());
+            return ExitCode::NotFound;
+        }
+        Err(e) => {
+            eprintln!("Error: {}", e.message());
+            return ExitCode::GeneralError;
+        }
+    };
+
+    let mut groups: HashMap<Cow<str>, Vec<SymbolChange>> = HashMap::new();
+    for change in changes {
+        groups
+            .entry(Cow::Owned(change.change.to_string()))
+            .or_default()
+            .push(change);
+    }
+    let query = format!("{old_rev}..{new_rev}");
+    let unified = UnifiedOutputBuilder::grouped(groups, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Owned(query)),
+            ..Default::default()
+        })
+        .build();
+
+    let mut output = OutputManager::new(format);
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
@@ -0,0 +1,570 @@
+//! Export the index to formats other tools read
+//!
+//! `ctags` writes a classic `tags` file in the extended format, so editors
+//! without an LSP client (vim, emacs) can jump to definitions:
+//!
+//! ```text
+//! Verify	models/user.go	42;"	kind:method	line:42	type:User
+//! User.Verify	models/user.go	42;"	kind:method	line:42	type:User
+//! ```
+//!
+//! Tags address their definition by line number. Members are tagged both by
+//! their own name and qualified by their type (Go methods by receiver, struct
+//! fields by struct), as `ctags --extras=+q` does.
+//!
+//! `manifest` writes the Go API of the index as JSON, for documentation
+//! generators: packages, their types with fields, methods and (for
+//! interfaces) implementors, then functions, constants and variables. Every
+//! list is sorted and symbol ids are left out, so two manifests of the same
+//! code are identical and a diff shows only API changes.
+
+use crate::io::ExitCode;
+use crate::parsing::go::receiver_type_from_signature;
+use crate::{ScopeContext, SimpleIndexer, Symbol, SymbolKind, Visibility};
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::fmt;
+use std::io::Write;
+use std::path::Path;
+
+/// Default name of the tags file
+pub const DEFAULT_TAGS_FILE: &str = "tags";
+
+/// One line of a tags file
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct CtagsEntry {
+    pub name: String,
+    pub file: String,
+    /// 1-based line of the definition
+    pub line: u32,
+    /// Long kind name, such as `function` or `method`
+    pub kind: &'static str,
+    /// Type the symbol belongs to
+    pub scope: Option<String>,
+}
+
+impl fmt::Display for CtagsEntry {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}\t{}\t{};\"\tkind:{}\tline:{}",
+            self.name, self.file, self.line, self.kind, self.line
+        )?;
+        if let Some(scope) = &self.scope {
+            write!(f, "\ttype:{scope}")?;
+        }
+        Ok(())
+    }
+}
+
+/// ctags kind name of a symbol kind; parameters are not tagged
+pub fn ctags_kind(kind: SymbolKind) -> Option<&'static str> {
+    Some(match kind {
+        SymbolKind::Function => "function",
+        SymbolKind::Method => "method",
+        SymbolKind::Struct => "struct",
+        SymbolKind::Enum => "enum",
+        SymbolKind::Trait => "trait",
+        SymbolKind::Interface => "interface",
+        SymbolKind::Class => "class",
+        SymbolKind::Module => "package",
+        SymbolKind::Variable => "variable",
+        SymbolKind::Constant => "constant",
+        SymbolKind::Field => "member",
+        SymbolKind::TypeAlias => "type",
+        SymbolKind::Macro => "macro",
+        SymbolKind::Label => "label",
+        SymbolKind::Parameter => return None,
+    })
+}
+
+/// Tags for `symbols`, sorted by name as the `!_TAG_FILE_SORTED` header says
+///
+/// Local variables and parameters are skipped. Names containing tabs or
+/// newlines can't be represented and are skipped too.
+pub fn ctags_entries(symbols: &[Symbol]) -> Vec<CtagsEntry> {
+    let mut entries = Vec::new();
+    for symbol in symbols {
+        if matches!(symbol.scope_context, Some(ScopeContext::Local { .. })) {
+            continue;
+        }
+        let Some(kind) = ctags_kind(symbol.kind) else {
+            continue;
+        };
+        let name: &str = &symbol.name;
+        if name.contains(['\t', '\n', '\r']) {
+            continue;
+        }
+
+        // Go fields are indexed as `Struct.field`; methods carry their
+        // receiver in the signature
+        let (short, scope) = match (symbol.kind, name.split_once('.')) {
+            (SymbolKind::Field, Some((owner, field))) => (field, Some(owner)),
+            (SymbolKind::Method, _) => (
+                name,
+                symbol
+                    .signature
+                    .as_deref()
+                    .and_then(receiver_type_from_signature),
+            ),
+            _ => (name, None),
+        };
+
+        let entry = CtagsEntry {
+            name: short.to_string(),
+            file: symbol.file_path.to_string(),
+            line: symbol.range.start_line + 1,
+            kind,
+            scope: scope.map(str::to_string),
+        };
+        if let Some(scope) = scope {
+            entries.push(CtagsEntry {
+                name: format!("{scope}.{short}"),
+                ..entry.clone()
+            });
+        }
+        entries.push(entry);
+    }
+    entries.sort_by(|a, b| (&a.name, &a.file, a.line).cmp(&(&b.name, &b.file, b.line)));
+    entries.dedup();
+    entries
+}
+
+/// Write a tags file: the pseudo-tag header, then one line per entry
+pub fn write_ctags<W: Write>(entries: &[CtagsEntry], out: &mut W) -> std::io::Result<()> {
+    writeln!(
+        out,
+        "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/"
+    )?;
+    writeln!(
+        out,
+        "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/"
+    )?;
+    writeln!(out, "!_TAG_PROGRAM_NAME\tcodanna\t//")?;
+    writeln!(
+        out,
+        "!_TAG_PROGRAM_VERSION\t{}\t//",
+        env!("CARGO_PKG_VERSION")
+    )?;
+    for entry in entries {
+        writeln!(out, "{entry}")?;
+    }
+    Ok(())
+}
+
+/// Execute export ctags command
+///
+/// Writes to `output`, or to stdout when it is `-`.
+pub fn export_ctags(indexer: &SimpleIndexer, output: &Path) -> ExitCode {
+    let entries = ctags_entries(&indexer.get_all_symbols());
+
+    let result = if output == Path::new("-") {
+        write_ctags(&entries, &mut std::io::stdout().lock())
+    } else {
+        std::fs::File::create(output).and_then(|file| {
+            let mut out = std::io::BufWriter::new(file);
+            write_ctags(&entries, &mut out)?;
+            out.flush()
+        })
+    };
+
+    match result {
+        Ok(()) => {
+            if output != Path::new("-") {
+                eprintln!("Wrote {} tags to {}", entries.len(), output.display());
+            }
+            ExitCode::Success
+        }
+        Err(e) => {
+            eprintln!("Error writing {}: {e}", output.display());
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Layout version of the manifest, raised on incompatible changes
+pub const MANIFEST_VERSION: u32 = 1;
+
+/// The Go API of an index, package by package
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Manifest {
+    pub version: u32,
+    /// Whether unexported symbols were left out
+    pub exported_only: bool,
+    /// Packages sorted by path
+    pub packages: Vec<PackageManifest>,
+}
+
+/// The declarations of one package
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PackageManifest {
+    /// Package path as indexed
+    pub path: String,
+    /// Files declaring the listed symbols
+    pub files: Vec<String>,
+    pub types: Vec<TypeManifest>,
+    pub functions: Vec<ManifestEntry>,
+    pub constants: Vec<ManifestEntry>,
+    pub variables: Vec<ManifestEntry>,
+}
+
+/// A declared type with its members
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeManifest {
+    #[serde(flatten)]
+    pub entry: ManifestEntry,
+    /// `struct`, `interface` or `type`, as in ctags
+    pub kind: &'static str,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub fields: Vec<ManifestEntry>,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub methods: Vec<ManifestEntry>,
+    /// Types implementing an interface, as `package/path.Type`
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub implementors: Vec<String>,
+}
+
+/// A documented declaration
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ManifestEntry {
+    /// Name within its package or type
+    pub name: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub signature: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub doc: Option<String>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl ManifestEntry {
+    fn new(symbol: &Symbol, name: &str) -> Self {
+        Self {
+            name: name.to_string(),
+            signature: symbol.signature.as_deref().map(str::to_string),
+            doc: symbol.doc_comment.as_deref().map(str::to_string),
+            file: symbol.file_path.to_string(),
+            line: symbol.range.start_line + 1,
+        }
+    }
+
+    fn sort_key(&self) -> (&str, &str, u32) {
+        (&self.name, &self.file, self.line)
+    }
+}
+
+/// Build the manifest of the Go symbols in `symbols`
+///
+/// `implementors` gives the types implementing an interface symbol. Fields
+/// and interface methods are attached by their `Type.member` names, other
+/// methods by the receiver of their signature; members of a type left out
+/// are left out with it. Locals and parameters are never listed.
+pub fn build_manifest(
+    symbols: &[Symbol],
+    implementors: &dyn Fn(&Symbol) -> Vec<Symbol>,
+    exported_only: bool,
+) -> Manifest {
+    let go = Some(crate::parsing::LanguageId::new("go"));
+    let listed: Vec<&Symbol> = symbols
+        .iter()
+        .filter(|s| s.language_id == go)
+        .filter(|s| {
+            !matches!(
+                s.scope_context,
+                Some(ScopeContext::Local { .. } | ScopeContext::Parameter)
+            )
+        })
+        .filter(|s| !exported_only || s.visibility == Visibility::Public)
+        .collect();
+    let package_of = |symbol: &Symbol| symbol.module_path.as_deref().unwrap_or("").to_string();
+
+    let mut packages: BTreeMap<String, PackageManifest> = BTreeMap::new();
+    let mut members: Vec<(&Symbol, String, String)> = Vec::new();
+    for &symbol in &listed {
+        let path = package_of(symbol);
+        let package = packages
+            .entry(path.clone())
+            .or_insert_with(|| PackageManifest {
+                path,
+                files: Vec::new(),
+                types: Vec::new(),
+                functions: Vec::new(),
+                constants: Vec::new(),
+                variables: Vec::new(),
+            });
+        let name: &str = &symbol.name;
+        let owner = match (symbol.kind, name.split_once('.')) {
+            (SymbolKind::Field | SymbolKind::Method, Some((owner, member))) => {
+                Some((owner, member))
+            }
+            (SymbolKind::Method, None) => symbol
+                .signature
+                .as_deref()
+                .and_then(receiver_type_from_signature)
+                .map(|owner| (owner, name)),
+            _ => None,
+        };
+        if let Some((owner, member)) = owner {
+            members.push((symbol, owner.to_string(), member.to_string()));
+            continue;
+        }
+        let entry = ManifestEntry::new(symbol, name);
+        match symbol.kind {
+            SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias => {
+                let implementors = if symbol.kind == SymbolKind::Interface {
+                    let mut names: Vec<String> = implementors(symbol)
+                        .iter()
+                        .filter(|t| !exported_only || t.visibility == Visibility::Public)
+                        .map(|t| format!("{}.{}", package_of(t), t.name))
+                        .collect();
+                    names.sort();
+                    names.dedup();
+                    names
+                } else {
+                    Vec::new()
+                };
+                package.types.push(TypeManifest {
+                    entry,
+                    kind: ctags_kind(symbol.kind).unwrap_or("type"),
+                    fields: Vec::new(),
+                    methods: Vec::new(),
+                    implementors,
+                });
+            }
+            SymbolKind::Function => package.functions.push(entry),
+            SymbolKind::Constant => package.constants.push(entry),
+            SymbolKind::Variable => package.variables.push(entry),
+            _ => continue,
+        }
+        package.files.push(symbol.file_path.to_string());
+    }
+
+    for (symbol, owner, member) in members {
+        let Some(owner) = packages
+            .get_mut(&package_of(symbol))
+            .and_then(|p| p.types.iter_mut().find(|t| t.entry.name == owner))
+        else {
+            continue;
+        };
+        let entry = ManifestEntry::new(symbol, &member);
+        match symbol.kind {
+            SymbolKind::Field => owner.fields.push(entry),
+            _ => owner.methods.push(entry),
+        }
+    }
+
+    let mut packages: Vec<PackageManifest> = packages.into_values().collect();
+    for package in &mut packages {
+        package.files.sort();
+        package.files.dedup();
+        package
+            .types
+            .sort_by(|a, b| a.entry.sort_key().cmp(&b.entry.sort_key()));
+        for type_manifest in &mut package.types {
+            // Fields keep declaration order, as documentation shows them
+            type_manifest
+                .fields
+                .sort_by(|a, b| (&a.file, a.line, &a.name).cmp(&(&b.file, b.line, &b.name)));
+            type_manifest
+                .methods
+                .sort_by(|a, b| a.sort_key().cmp(&b.sort_key()));
+        }
+        for entries in [
+            &mut package.functions,
+            &mut package.constants,
+            &mut package.variables,
+        ] {
+            entries.sort_by(|a, b| a.sort_key().cmp(&b.sort_key()));
+        }
+    }
+    packages.retain(|p| !p.files.is_empty());
+
+    Manifest {
+        version: MANIFEST_VERSION,
+        exported_only,
+        packages,
+    }
+}
+
+/// Execute export manifest command
+///
+/// Writes pretty-printed JSON to `output`, or to stdout when it is `-`.
+pub fn export_manifest(indexer: &SimpleIndexer, exported_only: bool, output: &Path) -> ExitCode {
+    let manifest = build_manifest(
+        &indexer.get_all_symbols(),
+        &|interface| indexer.get_implementations(interface.id),
+        exported_only,
+    );
+    let json = match serde_json::to_string_pretty(&manifest) {
+        Ok(json) => json,
+        Err(e) => {
+            eprintln!("Error serializing manifest: {e}");
+            return ExitCode::GeneralError;
+        }
+    };
+
+    let result = if output == Path::new("-") {
+        writeln!(std::io::stdout().lock(), "{json}")
+    } else {
+        std::fs::write(output, format!("{json}\n"))
+    };
+
+    match result {
+        Ok(()) => {
+            if output != Path::new("-") {
+                eprintln!(
+                    "Wrote the manifest of {} packages to {}",
+                    manifest.packages.len(),
+                    output.display()
+                );
+            }
+            ExitCode::Success
+        }
+        Err(e) => {
+            eprintln!("Error writing {}: {e}", output.display());
+            ExitCode::GeneralError
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::types::FileId;
+    use crate::{Range, SymbolId};
+
+    fn symbol(id: u32, name: &str, kind: SymbolKind, signature: Option<&str>) -> Symbol {
+        let mut symbol = Symbol::new(
+            SymbolId::new(id).unwrap(),
+            name,
+            kind,
+            FileId::new(1).unwrap(),
+            Range::new(id * 10, 0, id * 10 + 3, 1),
+        );
+        symbol.file_path = "models/user.go".into();
+        symbol.signature = signature.map(Into::into);
+        symbol
+    }
+
+    #[test]
+    fn test_ctags_entries_qualify_members() {
+        let symbols = vec![
+            symbol(1, "User", SymbolKind::Struct, None),
+            symbol(2, "User.Email", SymbolKind::Field, Some("Email string")),
+            symbol(
+                3,
+                "Verify",
+                SymbolKind::Method,
+                Some("func (u *User) Verify() bool"),
+            ),
+            symbol(4, "NewUser", SymbolKind::Function, None),
+            symbol(5, "name", SymbolKind::Parameter, None),
+        ];
+
+        let entries = ctags_entries(&symbols);
+        let lines: Vec<_> = entries.iter().map(ToString::to_string).collect();
+        assert_eq!(
+            lines,
+            vec![
+                "Email\tmodels/user.go\t21;\"\tkind:member\tline:21\ttype:User",
+                "NewUser\tmodels/user.go\t41;\"\tkind:function\tline:41",
+                "User\tmodels/user.go\t11;\"\tkind:struct\tline:11",
+                "User.Email\tmodels/user.go\t21;\"\tkind:member\tline:21\ttype:User",
+                "User.Verify\tmodels/user.go\t31;\"\tkind:method\tline:31\ttype:User",
+                "Verify\tmodels/user.go\t31;\"\tkind:method\tline:31\ttype:User",
+            ]
+        );
+
+        let mut out = Vec::new();
+        write_ctags(&entries, &mut out).unwrap();
+        let text = String::from_utf8(out).unwrap();
+        assert!(text.starts_with("!_TAG_FILE_FORMAT\t2\t"));
+        assert!(text.contains("!_TAG_FILE_SORTED\t1\t"));
+        assert_eq!(text.lines().count(), 4 + entries.len());
+    }
+
+    #[test]
+    fn test_manifest_groups_members_and_implementors() {
+        let go_symbol = |id, name, kind, package: &str, public: bool| {
+            let visibility = if public {
+                Visibility::Public
+            } else {
+                Visibility::Private
+            };
+            symbol(id, name, kind, None)
+                .with_module_path(package)
+                .with_visibility(visibility)
+                .with_language_id(crate::parsing::LanguageId::new("go"))
+        };
+        let symbols = vec![
+            go_symbol(1, "Store", SymbolKind::Interface, "app/models", true),
+            go_symbol(2, "Store.Save", SymbolKind::Method, "app/models", true)
+                .with_signature("Save(u *User) error"),
+            go_symbol(3, "User", SymbolKind::Struct, "app/models", true)
+                .with_doc("User is a registered account"),
+            go_symbol(4, "User.Email", SymbolKind::Field, "app/models", true),
+            go_symbol(5, "User.name", SymbolKind::Field, "app/models", false),
+            go_symbol(6, "Verify", SymbolKind::Method, "app/models", true)
+                .with_signature("func (u *User) Verify() bool"),
+            go_symbol(7, "NewUser", SymbolKind::Function, "app/models", true),
+            go_symbol(8, "counter", SymbolKind::Variable, "app/models", false),
+            go_symbol(9, "MaxUsers", SymbolKind::Constant, "app/models", true),
+            go_symbol(10, "u", SymbolKind::Parameter, "app/models", false)
+                .with_scope(ScopeContext::Parameter),
+            go_symbol(11, "memStore", SymbolKind::Struct, "app/store", false),
+        ];
+        let implementors = |interface: &Symbol| {
+            if interface.name == "Store" {
+                vec![symbols[10].clone()]
+            } else {
+                Vec::new()
+            }
+        };
+
+        let manifest = build_manifest(&symbols, &implementors, false);
+        let paths: Vec<_> = manifest.packages.iter().map(|p| p.path.as_str()).collect();
+        assert_eq!(paths, vec!["app/models", "app/store"]);
+        let models = &manifest.packages[0];
+        let types: Vec<_> = models
+            .types
+            .iter()
+            .map(|t| (t.entry.name.as_str(), t.kind))
+            .collect();
+        assert_eq!(types, vec![("Store", "interface"), ("User", "struct")]);
+        let store = &models.types[0];
+        assert_eq!(store.methods[0].name, "Save");
+        assert_eq!(store.implementors, vec!["app/store.memStore"]);
+        let user = &models.types[1];
+        let fields: Vec<_> = user.fields.iter().map(|f| f.name.as_str()).collect();
+        assert_eq!(fields, vec!["Email", "name"]);
+        assert_eq!(user.methods[0].name, "Verify");
+        assert_eq!(
+            user.entry.doc.as_deref(),
+            Some("User is a registered account")
+        );
+        assert_eq!(models.variables[0].name, "counter");
+        assert_eq!(models.files, vec!["models/user.go"]);
+
+        // The same symbols in another order give the same manifest
+        let mut reversed = symbols.clone();
+        reversed.reverse();
+        assert_eq!(build_manifest(&reversed, &implementors, false), manifest);
+
+        // Public API only: unexported members, packages and implementors go
+        let public = build_manifest(&symbols, &implementors, true);
+        assert_eq!(public.packages.len(), 1);
+        let models = &public.packages[0];
+        assert_eq!(models.types[1].fields.len(), 1);
+        assert!(models.types[0].implementors.is_empty());
+        assert!(models.variables.is_empty());
+        let json = serde_json::to_value(&public).unwrap();
+        assert_eq!(json["exported_only"], true);
+        assert_eq!(json["packages"][0]["types"][1]["name"], "User");
+        assert!(
+            json["packages"][0]["types"][0]
+                .get("implementors")
+                .is_none()
+        );
+    }
+}
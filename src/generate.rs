@@ -0,0 +1,47 @@
+//! Generate Go source from the index
+//!
+//! `stub` prints the methods a type is missing to implement an interface,
+//! ready to paste next to the type:
+//!
+//! ```text
+//! $ codanna generate stub UserStore models.Store
+//! // Load implements models.Store.
+//! func (s *UserStore) Load(id string) (*models.User, error) {
+//! 	panic("not implemented")
+//! }
+//! ```
+//!
+//! Imports the stubs need (`context`, the interface's package) are left for
+//! the user or goimports to add.
+
+use crate::SimpleIndexer;
+use crate::analyze::load_go_files;
+use crate::io::ExitCode;
+use crate::parsing::go::analysis::{StubError, generate_method_stubs};
+
+/// Execute generate stub command
+///
+/// Stubs go to stdout; messages about the lookup go to stderr so the output
+/// can be piped or appended to a file as is.
+pub fn generate_stub(indexer: &SimpleIndexer, type_name: &str, interface: &str) -> ExitCode {
+    let files = load_go_files(indexer);
+    match generate_method_stubs(&files, type_name, interface) {
+        Ok(stubs) if stubs.is_empty() => {
+            eprintln!("{type_name} already has every method of {interface}");
+            ExitCode::Success
+        }
+        Ok(stubs) => {
+            let sources: Vec<_> = stubs.iter().map(|stub| stub.source.as_str()).collect();
+            println!("{}", sources.join("\n\n"));
+            ExitCode::Success
+        }
+        Err(e @ (StubError::TypeNotFound(_) | StubError::InterfaceNotFound(_))) => {
+            eprintln!("Error: {e}");
+            ExitCode::NotFound
+        }
+        Err(e) => {
+            eprintln!("Error: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
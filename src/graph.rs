@@ -0,0 +1,434 @@
+//! Render relationships between indexed types as graphs
+//!
+//! `embeds` draws the composition hierarchy built by struct and interface
+//! embedding, starting from the types nothing else embeds:
+//!
+//! ```text
+//! $ codanna graph embeds
+//! complex.Application
+//! └── *WorkerPool
+//! interfaces.ReadWriteCloser
+//! ├── Reader
+//! ├── Writer
+//! └── io.Closer
+//! structs.Person
+//! └── User
+//! ```
+//!
+//! A type embedded in several places is expanded the first time and shown
+//! as `(see above)` afterwards, and branches deeper than the depth limit end
+//! in `…`. `--format dot` prints the same edges for Graphviz, with interface
+//! embeddings dashed.
+//!
+//! `packages` lists the internal packages with the packages each imports
+//! directly. With `--topo` they come in build order, every package after
+//! its dependencies:
+//!
+//! ```text
+//! $ codanna graph packages --topo
+//! app/config
+//! app/models
+//! app/services
+//! └── app/models
+//! app/utils
+//! app
+//! ├── app/config
+//! ├── app/models
+//! ├── app/services
+//! └── app/utils
+//! ```
+//!
+//! An import cycle leaves no such order and is reported as an error.
+
+use crate::SimpleIndexer;
+use crate::analyze::load_go_files;
+use crate::io::ExitCode;
+use crate::parsing::go::analysis::{EmbedEdge, PackageGraph, find_embeds, find_import_cycles};
+use std::collections::{BTreeMap, BTreeSet, HashSet};
+use std::fmt::Write;
+
+/// Default number of embedding levels drawn below a root
+pub const DEFAULT_DEPTH: usize = 8;
+
+/// Output formats of the graph commands
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum GraphFormat {
+    /// Indented tree with box-drawing branches
+    Tree,
+    /// Graphviz `digraph`
+    Dot,
+}
+
+impl GraphFormat {
+    /// Parse a `--format` value
+    pub fn parse(value: &str) -> Option<Self> {
+        match value.to_ascii_lowercase().as_str() {
+            "tree" => Some(Self::Tree),
+            "dot" | "graphviz" => Some(Self::Dot),
+            _ => None,
+        }
+    }
+}
+
+/// Execute graph embeds command
+///
+/// With `root`, only the hierarchy below that type is drawn; it matches a
+/// `package.Name` key or a bare type name.
+pub fn graph_embeds(
+    indexer: &SimpleIndexer,
+    format: GraphFormat,
+    root: Option<&str>,
+    depth: usize,
+) -> ExitCode {
+    let files = load_go_files(indexer);
+    let edges = find_embeds(&files);
+    let roots = match root {
+        Some(root) => {
+            let roots = matching_owners(&edges, root);
+            if roots.is_empty() {
+                eprintln!("Error: no indexed type named '{root}' embeds other types");
+                return ExitCode::NotFound;
+            }
+            Some(roots)
+        }
+        None => None,
+    };
+    if edges.is_empty() {
+        eprintln!("No embedded types found");
+        return ExitCode::Success;
+    }
+
+    let output = match format {
+        GraphFormat::Tree => render_embed_tree(&edges, roots.as_deref(), depth),
+        GraphFormat::Dot => render_embed_dot(&edges, roots.as_deref(), depth),
+    };
+    print!("{output}");
+    ExitCode::Success
+}
+
+/// Execute graph packages command
+///
+/// Packages are listed by import path, or in build order with `topo`. A
+/// cycle makes `topo` fail with the cycles on stderr instead of printing a
+/// partial order.
+pub fn graph_packages(indexer: &SimpleIndexer, format: GraphFormat, topo: bool) -> ExitCode {
+    let files = load_go_files(indexer);
+    let graph = PackageGraph::build(&files);
+    if graph.packages.is_empty() {
+        eprintln!("No Go packages found");
+        return ExitCode::NotFound;
+    }
+    for import in graph.unresolved() {
+        eprintln!("Warning: {import}");
+    }
+
+    let order = if topo {
+        match graph.topological_order() {
+            Some(order) => order,
+            None => {
+                eprintln!("Error: import cycle, no build order exists");
+                for cycle in find_import_cycles(&graph) {
+                    eprintln!("{cycle}");
+                }
+                return ExitCode::GeneralError;
+            }
+        }
+    } else {
+        graph.packages.iter().map(String::as_str).collect()
+    };
+
+    let dependencies = graph.dependencies();
+    let output = match format {
+        GraphFormat::Tree => render_package_tree(&order, &dependencies),
+        GraphFormat::Dot => render_package_dot(&order, &dependencies),
+    };
+    print!("{output}");
+    ExitCode::Success
+}
+
+/// List `order` with the direct dependencies of each package below it
+pub fn render_package_tree(
+    order: &[&str],
+    dependencies: &BTreeMap<&str, BTreeSet<&str>>,
+) -> String {
+    let mut out = String::new();
+    for &package in order {
+        let _ = writeln!(out, "{package}");
+        let Some(imports) = dependencies.get(package) else {
+            continue;
+        };
+        for (i, import) in imports.iter().enumerate() {
+            let branch = if i + 1 == imports.len() {
+                "└── "
+            } else {
+                "├── "
+            };
+            let _ = writeln!(out, "{branch}{import}");
+        }
+    }
+    out
+}
+
+/// Draw the imports between the packages of `order` as a Graphviz digraph
+pub fn render_package_dot(order: &[&str], dependencies: &BTreeMap<&str, BTreeSet<&str>>) -> String {
+    let mut out = String::from("digraph packages {\n    rankdir=LR;\n    node [shape=box];\n");
+    for &package in order {
+        let package_id = package.replace('"', "\\\"");
+        let _ = writeln!(out, "    \"{package_id}\";");
+        for import in dependencies.get(package).into_iter().flatten() {
+            let _ = writeln!(
+                out,
+                "    \"{package_id}\" -> \"{}\";",
+                import.replace('"', "\\\"")
+            );
+        }
+    }
+    out.push_str("}\n");
+    out
+}
+
+/// Embedding types matching `name`, by `package.Name` key or bare name
+fn matching_owners(edges: &[EmbedEdge], name: &str) -> Vec<String> {
+    let owners: BTreeSet<&str> = edges
+        .iter()
+        .map(|e| e.owner.as_str())
+        .filter(|owner| {
+            *owner == name || owner.rsplit_once('.').is_some_and(|(_, bare)| bare == name)
+        })
+        .collect();
+    owners.into_iter().map(str::to_string).collect()
+}
+
+/// Edges of each embedding type, in source order
+fn edges_by_owner(edges: &[EmbedEdge]) -> BTreeMap<&str, Vec<&EmbedEdge>> {
+    let mut by_owner: BTreeMap<&str, Vec<&EmbedEdge>> = BTreeMap::new();
+    for edge in edges {
+        by_owner.entry(edge.owner.as_str()).or_default().push(edge);
+    }
+    by_owner
+}
+
+/// Types no other type embeds, sorted
+///
+/// Types only reachable through an embedding cycle have no such root and are
+/// picked up by the renderers afterwards.
+fn top_level_owners<'e>(by_owner: &BTreeMap<&'e str, Vec<&'e EmbedEdge>>) -> Vec<&'e str> {
+    let embedded: HashSet<&str> = by_owner
+        .values()
+        .flatten()
+        .map(|e| e.embedded.as_str())
+        .collect();
+    by_owner
+        .keys()
+        .copied()
+        .filter(|owner| !embedded.contains(owner))
+        .collect()
+}
+
+/// Draw the embedding hierarchy as an indented tree
+///
+/// Without `roots`, every type nothing embeds is a root. At least one level
+/// is drawn below each root whatever `depth` is.
+pub fn render_embed_tree(edges: &[EmbedEdge], roots: Option<&[String]>, depth: usize) -> String {
+    let by_owner = edges_by_owner(edges);
+    let mut tree = TreeRenderer {
+        by_owner: &by_owner,
+        depth,
+        expanded: HashSet::new(),
+        out: String::new(),
+    };
+    match roots {
+        Some(roots) => {
+            for root in roots {
+                tree.write_root(root);
+            }
+        }
+        None => {
+            for root in top_level_owners(&by_owner) {
+                tree.write_root(root);
+            }
+            for owner in by_owner.keys() {
+                if !tree.expanded.contains(owner) {
+                    tree.write_root(owner);
+                }
+            }
+        }
+    }
+    tree.out
+}
+
+struct TreeRenderer<'a, 'e> {
+    by_owner: &'a BTreeMap<&'e str, Vec<&'e EmbedEdge>>,
+    depth: usize,
+    /// Types whose embeddings have been drawn
+    expanded: HashSet<&'e str>,
+    out: String,
+}
+
+impl<'e> TreeRenderer<'_, 'e> {
+    fn write_root(&mut self, root: &str) {
+        let Some((&key, _)) = self.by_owner.get_key_value(root) else {
+            return;
+        };
+        let _ = writeln!(self.out, "{key}");
+        self.expanded.insert(key);
+        self.write_children(key, "", 1, &mut vec![key]);
+    }
+
+    fn write_children(&mut self, owner: &str, prefix: &str, level: usize, path: &mut Vec<&'e str>) {
+        let by_owner = self.by_owner;
+        let Some(children) = by_owner.get(owner) else {
+            return;
+        };
+        for (i, &edge) in children.iter().enumerate() {
+            let last = i + 1 == children.len();
+            let branch = if last { "└── " } else { "├── " };
+            let _ = write!(self.out, "{prefix}{branch}{}", edge.written);
+
+            let key = edge.embedded.as_str();
+            let has_children = by_owner.contains_key(key);
+            if path.contains(&key) {
+                let _ = writeln!(self.out, " (cycle)");
+            } else if has_children && self.expanded.contains(key) {
+                let _ = writeln!(self.out, " (see above)");
+            } else if has_children && level >= self.depth {
+                let _ = writeln!(self.out, " …");
+            } else {
+                self.out.push('\n');
+                if has_children {
+                    self.expanded.insert(key);
+                    path.push(key);
+                    let prefix = format!("{prefix}{}", if last { "    " } else { "│   " });
+                    self.write_children(key, &prefix, level + 1, path);
+                    path.pop();
+                }
+            }
+        }
+    }
+}
+
+/// Draw the embedding edges as a Graphviz digraph
+///
+/// With `roots`, only edges within `depth` levels below them are drawn.
+pub fn render_embed_dot(edges: &[EmbedEdge], roots: Option<&[String]>, depth: usize) -> String {
+    let by_owner = edges_by_owner(edges);
+    let selected: Vec<&EmbedEdge> = match roots {
+        Some(roots) => {
+            let mut selected = Vec::new();
+            let mut visited: HashSet<&str> = HashSet::new();
+            let mut frontier: Vec<&str> = roots
+                .iter()
+                .filter_map(|r| by_owner.get_key_value(r.as_str()).map(|(&k, _)| k))
+                .collect();
+            for _ in 0..depth.max(1) {
+                let mut next = Vec::new();
+                for owner in frontier {
+                    if !visited.insert(owner) {
+                        continue;
+                    }
+                    for &edge in by_owner.get(owner).into_iter().flatten() {
+                        selected.push(edge);
+                        next.push(edge.embedded.as_str());
+                    }
+                }
+                frontier = next;
+            }
+            selected
+        }
+        None => edges.iter().collect(),
+    };
+
+    let mut out = String::from("digraph embeds {\n    rankdir=LR;\n    node [shape=box];\n");
+    let mut seen = HashSet::new();
+    for edge in selected {
+        if !seen.insert((&edge.owner, &edge.embedded)) {
+            continue;
+        }
+        let style = if edge.interface {
+            " [style=dashed]"
+        } else {
+            ""
+        };
+        let _ = writeln!(
+            out,
+            "    \"{}\" -> \"{}\"{style};",
+            edge.owner.replace('"', "\\\""),
+            edge.embedded.replace('"', "\\\"")
+        );
+    }
+    out.push_str("}\n");
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::parsing::go::analysis::GoSourceFile;
+
+    #[test]
+    fn test_embed_tree_with_shared_embeds_and_depth() {
+        let code = r#"
+package shapes
+
+type Base struct{ ID int }
+type Named struct {
+    Base
+    Name string
+}
+type Timestamps struct{ Base }
+type User struct {
+    Named
+    *Timestamps
+}
+type Admin struct{ User }
+type Audited struct{ *Timestamps }
+"#;
+        let file = GoSourceFile::parse("shapes/shapes.go", code.to_string()).unwrap();
+        let edges = find_embeds(&[file]);
+
+        assert_eq!(
+            render_embed_tree(&edges, None, DEFAULT_DEPTH),
+            "shapes.Admin\n\
+             └── User\n    \
+                 ├── Named\n    \
+                 │   └── Base\n    \
+                 └── *Timestamps\n        \
+                     └── Base\n\
+             shapes.Audited\n\
+             └── *Timestamps (see above)\n"
+        );
+        assert_eq!(
+            render_embed_tree(&edges, Some(&["shapes.User".to_string()]), 1),
+            "shapes.User\n├── Named …\n└── *Timestamps …\n"
+        );
+        assert_eq!(matching_owners(&edges, "Audited"), vec!["shapes.Audited"]);
+        assert!(
+            render_embed_dot(&edges, None, DEFAULT_DEPTH)
+                .contains("    \"shapes.User\" -> \"shapes.Timestamps\";\n")
+        );
+    }
+
+    #[test]
+    fn test_package_tree_in_build_order() {
+        let dependencies = BTreeMap::from([
+            ("app", BTreeSet::from(["app/models", "app/services"])),
+            ("app/models", BTreeSet::new()),
+            ("app/services", BTreeSet::from(["app/models"])),
+        ]);
+        let order = ["app/models", "app/services", "app"];
+
+        assert_eq!(
+            render_package_tree(&order, &dependencies),
+            "app/models\n\
+             app/services\n\
+             └── app/models\n\
+             app\n\
+             ├── app/models\n\
+             └── app/services\n"
+        );
+        assert!(
+            render_package_dot(&order, &dependencies)
+                .contains("    \"app/services\" -> \"app/models\";\n")
+        );
+    }
+}
@@ -0,0 +1,165 @@
+//! Memoized cross-package symbol lookups
+//!
+//! Resolving a package-qualified selector such as `models.UserRole` goes
+//! through the importing file's resolution context and a Tantivy fetch of the
+//! target symbol. Import-heavy files repeat the same selectors many times, so
+//! successful lookups are kept per (importing file, selector).
+//!
+//! An entry depends on the importing file, the file defining the target and
+//! the target's package (a new file in that package can change what the
+//! selector names). Re-indexing or removing any of them drops the entry.
+
+use crate::Symbol;
+use crate::types::FileId;
+use std::collections::HashMap;
+
+/// A resolved selector and the package it was looked up from
+#[derive(Debug, Clone)]
+struct CachedLookup {
+    symbol: Symbol,
+    /// Package of the importing file, since same-package declarations can
+    /// shadow an import
+    importer_module: Option<String>,
+}
+
+/// Cache of resolved cross-package lookups keyed by (importing file, selector)
+#[derive(Debug, Default)]
+pub struct CrossPackageLookupCache {
+    entries: HashMap<(FileId, String), CachedLookup>,
+}
+
+impl CrossPackageLookupCache {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Whether `name` is a qualified selector worth caching
+    pub fn is_selector(name: &str) -> bool {
+        name.contains('.')
+    }
+
+    /// Target of `selector` as previously resolved from `file_id`
+    pub fn get(&self, file_id: FileId, selector: &str) -> Option<&Symbol> {
+        self.entries
+            .get(&(file_id, selector.to_string()))
+            .map(|entry| &entry.symbol)
+    }
+
+    /// Remember that `selector` in `file_id` resolved to `symbol`
+    ///
+    /// Only lookups that land in another file are kept; same-file targets are
+    /// already cheap to resolve.
+    pub fn insert(
+        &mut self,
+        file_id: FileId,
+        selector: &str,
+        importer_module: Option<String>,
+        symbol: Symbol,
+    ) {
+        if !Self::is_selector(selector) || symbol.file_id == file_id {
+            return;
+        }
+        self.entries.insert(
+            (file_id, selector.to_string()),
+            CachedLookup {
+                symbol,
+                importer_module,
+            },
+        );
+    }
+
+    /// Drop every entry that depends on `file_id` or its package
+    ///
+    /// Call when the file is re-indexed or removed. Returns the number of
+    /// entries dropped.
+    pub fn invalidate_file(&mut self, file_id: FileId, module_path: Option<&str>) -> usize {
+        let before = self.entries.len();
+        self.entries.retain(|(importer, _), entry| {
+            let same_package = module_path.is_some_and(|module| {
+                entry.symbol.module_path.as_deref() == Some(module)
+                    || entry.importer_module.as_deref() == Some(module)
+            });
+            *importer != file_id && entry.symbol.file_id != file_id && !same_package
+        });
+        before - self.entries.len()
+    }
+
+    pub fn clear(&mut self) {
+        self.entries.clear();
+    }
+
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{Range, SymbolId, SymbolKind};
+
+    fn symbol(id: u32, name: &str, file: u32, module: &str) -> Symbol {
+        let mut symbol = Symbol::new(
+            SymbolId::new(id).unwrap(),
+            name,
+            SymbolKind::Struct,
+            FileId::new(file).unwrap(),
+            Range::new(1, 0, 1, 10),
+        );
+        symbol.module_path = Some(module.to_string().into());
+        symbol
+    }
+
+    #[test]
+    fn test_lookups_invalidate_with_their_dependencies() {
+        let main = FileId::new(1).unwrap();
+        let handlers = FileId::new(2).unwrap();
+        let user_file = FileId::new(3).unwrap();
+        let role_file = FileId::new(4).unwrap();
+        let sibling = FileId::new(5).unwrap();
+
+        let mut cache = CrossPackageLookupCache::new();
+        let user = symbol(10, "User", 3, "models");
+        let role = symbol(11, "UserRole", 4, "models");
+        cache.insert(main, "models.User", Some(".".into()), user.clone());
+        cache.insert(main, "models.UserRole", Some(".".into()), role.clone());
+        cache.insert(handlers, "models.User", Some("handlers".into()), user);
+        // Plain names and same-file targets are not cached
+        cache.insert(main, "User", Some(".".into()), role.clone());
+        cache.insert(role_file, "models.UserRole", Some("models".into()), role);
+        assert_eq!(cache.len(), 3);
+        assert_eq!(
+            cache.get(main, "models.User").map(|s| s.name.as_str()),
+            Some("User")
+        );
+
+        // Re-indexing the importer drops only its own lookups
+        assert_eq!(cache.invalidate_file(main, Some(".")), 2);
+        assert!(cache.get(main, "models.User").is_none());
+        assert!(cache.get(handlers, "models.User").is_some());
+
+        // A new file in the target package drops lookups into that package
+        cache.insert(
+            main,
+            "models.UserRole",
+            Some(".".into()),
+            symbol(11, "UserRole", 4, "models"),
+        );
+        assert_eq!(cache.invalidate_file(sibling, Some("models")), 2);
+        assert!(cache.is_empty());
+
+        // Re-indexing the defining file drops lookups that landed there
+        cache.insert(
+            handlers,
+            "models.User",
+            Some("handlers".into()),
+            symbol(10, "User", 3, "models"),
+        );
+        assert_eq!(cache.invalidate_file(user_file, None), 1);
+        assert!(cache.is_empty());
+    }
+}
@@ -1,6 +1,7 @@
 pub mod config_watcher;
 pub mod file_info;
 pub mod fs_watcher;
+pub mod lookup_cache;
 pub mod progress;
 pub mod simple;
 pub mod transaction;
@@ -12,6 +13,7 @@ pub mod import_resolution_proof;
 pub use config_watcher::ConfigFileWatcher;
 pub use file_info::{FileInfo, calculate_hash, get_utc_timestamp};
 pub use fs_watcher::{FileSystemWatcher, WatchError};
+pub use lookup_cache::CrossPackageLookupCache;
 pub use progress::IndexStats;
 pub use simple::SimpleIndexer;
 pub use transaction::{FileTransaction, IndexTransaction};
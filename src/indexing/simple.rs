@@ -2,10 +2,13 @@
 //! This version uses Tantivy as the single source of truth for all data
 
 use crate::indexing::{
-    FileWalker, IndexStats, IndexTransaction, calculate_hash, get_utc_timestamp,
+    CrossPackageLookupCache, FileWalker, IndexStats, IndexTransaction, calculate_hash,
+    get_utc_timestamp,
 };
 use crate::io::status_line::StatusLine;
 use crate::io::{ProgressBar, ProgressBarOptions, ProgressBarStyle};
+use crate::parsing::go::analysis::GoSourceFile;
+use crate::parsing::go::extractor::{Extractor, ExtractorRegistry};
 use crate::parsing::resolution::ResolutionScope;
 use crate::parsing::{LanguageId, MethodCall, ParserFactory, get_registry};
 use crate::relationship::RelationshipMetadata;
@@ -73,6 +76,9 @@ pub struct SimpleIndexer {
     unresolved_relationships: Vec<UnresolvedRelationship>,
     /// Variable type information for method resolution
     variable_types: std::collections::HashMap<(FileId, String), String>,
+    /// Receiver types at each method call, keyed by the call's position,
+    /// where the language tracks them (see `find_receiver_types`)
+    receiver_types: std::collections::HashMap<(FileId, u32, u16), (String, Option<String>)>,
     /// Trait symbols by file for relationship extraction
     trait_symbols_by_file:
         std::collections::HashMap<FileId, std::collections::HashMap<String, crate::SymbolKind>>,
@@ -96,6 +102,13 @@ pub struct SimpleIndexer {
     file_behaviors: std::collections::HashMap<FileId, Box<dyn crate::parsing::LanguageBehavior>>,
     /// Indexed directory paths (canonicalized) to track which directories are currently indexed
     indexed_paths: std::collections::HashSet<std::path::PathBuf>,
+    /// Resolved package-qualified selectors, kept across incremental updates
+    cross_package_lookups: CrossPackageLookupCache,
+    /// Custom relationship extractors run over every Go file
+    extractors: ExtractorRegistry,
+    /// Parsed Go files sorted by path, so updating the implements
+    /// relationships after a change parses only the file that changed
+    go_sources: Vec<GoSourceFile>,
 }
 
 impl Default for SimpleIndexer {
@@ -139,6 +152,7 @@ impl SimpleIndexer {
             symbol_cache,
             unresolved_relationships: Vec::new(),
             variable_types: std::collections::HashMap::new(),
+            receiver_types: std::collections::HashMap::new(),
             trait_symbols_by_file: std::collections::HashMap::new(),
             method_calls_by_file: std::collections::HashMap::new(),
             vector_engine: None,
@@ -148,6 +162,9 @@ impl SimpleIndexer {
             file_languages: std::collections::HashMap::new(),
             file_behaviors: std::collections::HashMap::new(),
             indexed_paths: std::collections::HashSet::new(),
+            cross_package_lookups: CrossPackageLookupCache::new(),
+            extractors: ExtractorRegistry::with_builtin(),
+            go_sources: Vec::new(),
         };
 
         // Try to load symbol cache for fast lookups
@@ -183,6 +200,7 @@ impl SimpleIndexer {
             symbol_cache: None,
             unresolved_relationships: Vec::new(),
             variable_types: std::collections::HashMap::new(),
+            receiver_types: std::collections::HashMap::new(),
             trait_symbols_by_file: std::collections::HashMap::new(),
             method_calls_by_file: std::collections::HashMap::new(),
             vector_engine: None,
@@ -192,6 +210,9 @@ impl SimpleIndexer {
             file_languages: std::collections::HashMap::new(),
             file_behaviors: std::collections::HashMap::new(),
             indexed_paths: std::collections::HashSet::new(),
+            cross_package_lookups: CrossPackageLookupCache::new(),
+            extractors: ExtractorRegistry::with_builtin(),
+            go_sources: Vec::new(),
         };
 
         // Resolution system now handled through LanguageBehavior:
@@ -441,6 +462,7 @@ impl SimpleIndexer {
         path: impl AsRef<Path>,
         force: bool,
     ) -> IndexResult<crate::IndexingResult> {
+        let path = path.as_ref();
         self.start_tantivy_batch()?;
 
         match self.index_file_internal(path, force) {
@@ -448,6 +470,10 @@ impl SimpleIndexer {
                 self.commit_tantivy_batch()?;
                 // Resolve relationships after committing
                 self.resolve_cross_file_relationships()?;
+                if let crate::IndexingResult::Indexed(_) = result {
+                    self.resolve_go_implementations(Some(path))?;
+                    self.resolve_go_enum_literals(Some(path))?;
+                }
                 Ok(result)
             }
             Err(e) => {
@@ -628,6 +654,14 @@ impl SimpleIndexer {
             return Ok(());
         };
 
+        if let Some(file_id) = symbols_to_remove.first().map(|s| s.file_id) {
+            let module_path = symbols_to_remove
+                .first()
+                .and_then(|s| s.module_path.as_deref());
+            self.cross_package_lookups
+                .invalidate_file(file_id, module_path);
+        }
+
         // Remove ALL documents for this file from Tantivy
         self.document_index
             .remove_file_documents(path_str)
@@ -742,6 +776,10 @@ impl SimpleIndexer {
         let behavior = parser_with_behavior.behavior;
         let module_path = self.calculate_module_path(path, &*behavior);
 
+        // Lookups into or out of this file (or its package) may now differ
+        self.cross_package_lookups
+            .invalidate_file(file_id, module_path.as_deref());
+
         // Store language ID for this file to enable language-specific resolution
         self.file_languages.insert(file_id, language_id);
 
@@ -779,6 +817,12 @@ impl SimpleIndexer {
             behavior.as_ref(),
             &symbol_map,
         )?;
+        if language_id == LanguageId::new("go") {
+            if let Ok(file) = GoSourceFile::parse(self.workspace_file(path), content.to_string()) {
+                self.extract_and_store_custom_relationships(&file, path_str, &symbol_map)?;
+                self.cache_go_source(file);
+            }
+        }
         self.update_symbol_counter(&symbol_counter)?;
 
         // Store behavior for persistent state (imports, etc.) - AFTER it's been configured
@@ -1295,6 +1339,60 @@ impl SimpleIndexer {
             )?;
         }
 
+        // 3.5. Field accesses (functions reading or writing `Type.field`)
+        let field_accesses = parser.find_field_accesses(content);
+        for (context_name, field, _range) in &field_accesses {
+            let from_id = symbol_map.get(context_name.as_str()).copied();
+            self.add_relationships_by_name(
+                from_id,
+                context_name,
+                field,
+                file_id,
+                behavior.map_relationship("uses"),
+                None,
+            )?;
+        }
+
+        // 3.6. Generic instantiations, one relationship per distinct list of
+        // type arguments
+        for (context_name, generic, type_arguments, range) in parser.find_instantiations(content) {
+            let kind = behavior.map_relationship("instantiates");
+            let site = format!("{generic}[{}]", type_arguments.unwrap_or_default());
+            if !added.insert((context_name.to_string(), site, kind)) {
+                continue;
+            }
+            let metadata = RelationshipMetadata::new()
+                .at_position(range.start_line, range.start_column)
+                .with_type_arguments(type_arguments);
+            let from_id = symbol_map.get(context_name).copied();
+            self.add_relationships_by_name(
+                from_id,
+                context_name,
+                generic,
+                file_id,
+                kind,
+                Some(metadata),
+            )?;
+        }
+
+        // 3.7. Closure captures, from the enclosing function to the captured
+        // parameter or local, resolved by its declaration position
+        for (context_name, closure, variable, declaration, mutated) in parser.find_captures(content)
+        {
+            let metadata = RelationshipMetadata::new()
+                .at_position(declaration.start_line, declaration.start_column)
+                .with_capture(&closure, mutated);
+            let from_id = symbol_map.get(context_name).copied();
+            self.add_relationships_by_name(
+                from_id,
+                context_name,
+                variable,
+                file_id,
+                behavior.map_relationship("captures"),
+                Some(metadata),
+            )?;
+        }
+
         // 4. Method definitions (trait defines methods)
         let defines = parser.find_defines(content);
         debug_print!(
@@ -1348,6 +1446,12 @@ impl SimpleIndexer {
             self.variable_types
                 .insert((file_id, var_name.to_string()), type_name.to_string());
         }
+        for (receiver, type_name, range) in parser.find_receiver_types(content) {
+            self.receiver_types.insert(
+                (file_id, range.start_line, range.start_column),
+                (receiver.to_string(), type_name.map(str::to_string)),
+            );
+        }
 
         Ok(())
     }
@@ -1612,6 +1716,42 @@ impl SimpleIndexer {
         Ok(())
     }
 
+    /// Run the registered extractors over a Go file and store their
+    /// relationships under the target name the extractor gave
+    ///
+    /// Targets are kept as names rather than resolved to symbols, since most
+    /// (an environment variable, say) are not indexed symbols. Relationships
+    /// from package-level code have no source symbol and are skipped.
+    fn extract_and_store_custom_relationships(
+        &mut self,
+        file: &GoSourceFile,
+        path_str: &str,
+        symbol_map: &std::collections::HashMap<String, SymbolId>,
+    ) -> IndexResult<()> {
+        for relationship in self.extractors.extract_all(file) {
+            let Some(&from_id) = symbol_map.get(relationship.from.as_str()) else {
+                continue;
+            };
+            // Extractors report 1-based lines, the index stores 0-based rows
+            let metadata = RelationshipMetadata::new()
+                .at_position(relationship.line.saturating_sub(1), 0)
+                .with_custom_kind(&relationship.kind);
+            self.document_index
+                .store_custom_target(from_id, &relationship.to, path_str, &metadata)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "store_custom_target".to_string(),
+                    cause: e.to_string(),
+                })?;
+        }
+        Ok(())
+    }
+
+    /// Register a custom relationship extractor for Go files indexed from
+    /// now on, replacing any previous one with the same name
+    pub fn register_extractor(&mut self, extractor: Box<dyn Extractor>) {
+        self.extractors.register(extractor);
+    }
+
     /// Helper method to add relationships by symbol names
     /// Stores them as unresolved for later processing with import context
     fn add_relationships_by_name(
@@ -1689,6 +1829,54 @@ impl SimpleIndexer {
             .collect()
     }
 
+    /// Find symbols by a name that may be qualified by its owning type
+    ///
+    /// `Writer.Close` matches the field or interface method of that name and
+    /// the Go method declared on a `Writer` receiver, so a single symbol for
+    /// unique owners. A bare `Close` also matches every `Type.Close` member,
+    /// which keeps identical method and field names of different types apart.
+    pub fn find_symbols_by_qualified_name(
+        &self,
+        name: &str,
+        language_filter: Option<&str>,
+    ) -> Vec<Symbol> {
+        let mut symbols = self.find_symbols_by_name(name, language_filter);
+        let members = match name.rsplit_once('.') {
+            Some((owner, member)) if !owner.is_empty() && !member.is_empty() => self
+                .find_symbols_by_name(member, language_filter)
+                .into_iter()
+                .filter(|symbol| {
+                    symbol.kind == SymbolKind::Method
+                        && symbol
+                            .signature
+                            .as_deref()
+                            .and_then(crate::parsing::go::receiver_type_from_signature)
+                            == Some(owner)
+                })
+                .collect(),
+            Some(_) => Vec::new(),
+            None => self
+                .document_index
+                .find_symbols_by_member_name(name, language_filter)
+                .unwrap_or_default()
+                .into_iter()
+                .filter(|symbol| matches!(symbol.kind, SymbolKind::Method | SymbolKind::Field))
+                .map(|mut symbol| {
+                    if let Some(language_id) = self.file_languages.get(&symbol.file_id) {
+                        symbol.language_id = Some(*language_id);
+                    }
+                    symbol
+                })
+                .collect(),
+        };
+        for symbol in members {
+            if !symbols.iter().any(|existing| existing.id == symbol.id) {
+                symbols.push(symbol);
+            }
+        }
+        symbols
+    }
+
     pub fn get_symbol(&self, id: SymbolId) -> Option<Symbol> {
         self.document_index
             .find_symbol_by_id(id)
@@ -1767,6 +1955,58 @@ impl SimpleIndexer {
             .collect()
     }
 
+    /// Targets `symbol_id` relates to through custom extractors of `kind`,
+    /// named as the extractor reported them
+    pub fn get_custom_related_symbols(
+        &self,
+        symbol_id: SymbolId,
+        kind: &str,
+    ) -> Vec<(String, RelationshipMetadata)> {
+        self.document_index
+            .get_custom_targets_from(symbol_id)
+            .ok()
+            .unwrap_or_default()
+            .into_iter()
+            .filter(|(_, metadata)| metadata.custom_kind() == Some(kind))
+            .collect()
+    }
+
+    /// Symbols instantiating the generic function or type `symbol_id`, with
+    /// the metadata holding the type arguments of each instantiation
+    pub fn get_instantiating_symbols_with_metadata(
+        &self,
+        symbol_id: SymbolId,
+    ) -> Vec<(Symbol, RelationshipMetadata)> {
+        self.document_index
+            .get_relationships_to(symbol_id, RelationKind::References)
+            .ok()
+            .unwrap_or_default()
+            .into_iter()
+            .filter_map(|(from_id, _, rel)| {
+                let metadata = rel.metadata.filter(|m| m.type_arguments().is_some())?;
+                self.get_symbol(from_id).map(|symbol| (symbol, metadata))
+            })
+            .collect()
+    }
+
+    /// Functions writing an integer literal in place of the enum constant
+    /// `symbol_id`, with the metadata holding each literal and its position
+    pub fn get_enum_literal_references(
+        &self,
+        symbol_id: SymbolId,
+    ) -> Vec<(Symbol, RelationshipMetadata)> {
+        self.document_index
+            .get_relationships_to(symbol_id, RelationKind::References)
+            .ok()
+            .unwrap_or_default()
+            .into_iter()
+            .filter_map(|(from_id, _, rel)| {
+                let metadata = rel.metadata.filter(|m| m.enum_literal().is_some())?;
+                self.get_symbol(from_id).map(|symbol| (symbol, metadata))
+            })
+            .collect()
+    }
+
     /// Get comprehensive context for a symbol including all relationships.
     ///
     /// Aggregates symbol data with configurable relationship information.
@@ -1811,6 +2051,10 @@ impl SimpleIndexer {
             if let Some(defines) = deps.get(&RelationKind::Defines) {
                 relationships.defines = Some(defines.clone());
             }
+            let promoted = self.get_promoted_methods(&symbol);
+            if !promoted.is_empty() {
+                relationships.promoted = Some(promoted);
+            }
         }
 
         if include.contains(crate::symbol::context::ContextIncludes::CALLS) {
@@ -1827,13 +2071,34 @@ impl SimpleIndexer {
             }
         }
 
+        let receiver = SymbolContext::method_receiver(&symbol);
+        let alias_target = SymbolContext::alias_target(&symbol);
         Some(SymbolContext {
             symbol,
             file_path,
             relationships,
+            receiver,
+            alias_target,
         })
     }
 
+    /// Methods a Go struct gets from the structs it embeds within its package
+    pub fn get_promoted_methods(
+        &self,
+        symbol: &Symbol,
+    ) -> Vec<crate::symbol::context::PromotedMethod> {
+        let go = Some(crate::parsing::LanguageId::new("go"));
+        if symbol.kind != SymbolKind::Struct || symbol.language_id != go {
+            return Vec::new();
+        }
+        let package: Vec<Symbol> = self
+            .get_all_symbols()
+            .into_iter()
+            .filter(|s| s.language_id == go && s.module_path == symbol.module_path)
+            .collect();
+        crate::parsing::go::GoBehavior::promoted_methods(&package, &symbol.name)
+    }
+
     pub fn get_implementations(&self, trait_id: SymbolId) -> Vec<Symbol> {
         // Query relationships where to_symbol_id = trait_id and kind = Implements
         self.document_index
@@ -2291,6 +2556,8 @@ impl SimpleIndexer {
         // No centralized resolver to clear anymore
         self.trait_symbols_by_file.clear();
         self.variable_types.clear();
+        self.receiver_types.clear();
+        self.cross_package_lookups.clear();
 
         // Clear semantic search if enabled
         if let Some(ref semantic) = self.semantic_search {
@@ -2471,6 +2738,8 @@ impl SimpleIndexer {
         // Resolve cross-file relationships after all files are indexed
         if !dry_run {
             self.resolve_cross_file_relationships()?;
+            self.resolve_go_implementations(None)?;
+            self.resolve_go_enum_literals(None)?;
         }
 
         // Stop timing and update final stats before returning
@@ -2620,6 +2889,13 @@ impl SimpleIndexer {
                 return Some(id);
             }
 
+            // Go method expressions `(*User).String` name the type directly,
+            // and Go registers methods as `Type.Method`
+            let qualified = format!("{}.{}", receiver, method_call.method_name);
+            if let Some(id) = context.resolve(&qualified) {
+                return Some(id);
+            }
+
             // Fall back to method name only (receiver was already checked as non-external above)
             let result = context.resolve(&method_call.method_name);
             debug_print!(
@@ -2631,11 +2907,56 @@ impl SimpleIndexer {
             return result;
         }
 
-        // For instance methods, look up receiver's type
-        let type_name = self.variable_types.get(&(file_id, receiver.to_string()))?;
+        // For instance methods, look up receiver's type: at the call when
+        // the language tracks it, else the file's last binding of the name
+        let at_call = self
+            .receiver_types
+            .get(&(
+                file_id,
+                method_call.range.start_line,
+                method_call.range.start_column,
+            ))
+            .filter(|(name, _)| name == receiver);
+        let type_name = match at_call {
+            Some((_, Some(type_name))) => Some(type_name),
+            // A local of unknown type
+            Some((_, None)) => return None,
+            None => self.variable_types.get(&(file_id, receiver.to_string())),
+        };
+        let Some(type_name) = type_name else {
+            // Not a variable: a package-qualified call such as Go's
+            // `set.New()` with `set "container/list"`, resolvable only when
+            // the imported package is indexed
+            if context.import_binding(receiver).is_none() || context.is_external_import(receiver) {
+                return None;
+            }
+            let qualified = format!("{receiver}.{}", method_call.method_name);
+            debug_print!(self, "Package-qualified call: {}", qualified);
+            return context.resolve(&qualified);
+        };
 
         debug_print!(self, "Found type for {}: {}", receiver, type_name);
 
+        // Prefer the method of the receiver's type when the language registers
+        // qualified method names (Go: `Type.Method`)
+        let qualified = format!("{type_name}.{}", method_call.method_name);
+        if let Some(id) = context.resolve(&qualified) {
+            return Some(id);
+        }
+        // Go: promoted from two embedded types at once, so no method is called
+        if context.is_ambiguous_selector(&qualified) {
+            debug_print!(self, "Ambiguous selector: {}", qualified);
+            return None;
+        }
+        // A type of an unindexed package, such as Go's `bytes.Buffer` from
+        // `bytes.NewBuffer()`, has its methods there and not under a local name
+        if let Some((package, _)) = type_name.rsplit_once('.') {
+            if context.is_external_import(package) {
+                debug_print!(self, "Type {} is from external import", type_name);
+                return None;
+            }
+        }
+
         // Check if method comes from a trait
         // Without legacy resolution, just try direct resolution
         context.resolve(&method_call.method_name)
@@ -2647,13 +2968,23 @@ impl SimpleIndexer {
         let behavior = self.get_behavior_for_file(file_id)?;
 
         // NEW: Check if we can use cache-based resolution
-        if let Some(cache) = self.symbol_cache() {
+        let mut context = if let Some(cache) = self.symbol_cache() {
             // Build context with cache (fast path)
-            behavior.build_resolution_context_with_cache(file_id, cache, &self.document_index)
+            behavior.build_resolution_context_with_cache(file_id, cache, &self.document_index)?
         } else {
             // Fall back to existing path (compatibility)
-            behavior.build_resolution_context(file_id, &self.document_index)
+            behavior.build_resolution_context(file_id, &self.document_index)?
+        };
+
+        // Go: `stdlib` in codanna.toml decides whether standard library
+        // constructor results resolve
+        if let Some(go_context) = context
+            .as_any_mut()
+            .downcast_mut::<crate::parsing::go::GoResolutionContext>()
+        {
+            go_context.set_stdlib_resolution(self.settings.project.stdlib);
         }
+        Ok(context)
     }
 
     /// Resolve cross-file relationships using imports
@@ -2826,8 +3157,25 @@ impl SimpleIndexer {
                     from_symbols
                 };
 
+                // Package-qualified selectors resolved earlier from this file
+                // skip both resolution and the symbol fetch
+                let mut cached_target = self
+                    .cross_package_lookups
+                    .get(file_id, &rel.to_name)
+                    .cloned();
+
                 // Use the clean resolution API that delegates to language-specific logic
-                let to_symbol_id = if rel.kind == RelationKind::Calls && from_symbols.len() == 1 {
+                let capture = rel
+                    .metadata
+                    .as_ref()
+                    .filter(|metadata| metadata.capture().is_some());
+                let to_symbol_id = if let Some(symbol) = &cached_target {
+                    debug_print!(self, "Cached lookup: {} -> {:?}", rel.to_name, symbol.id);
+                    Some(symbol.id)
+                } else if let Some(metadata) = capture {
+                    // Locals are out of the resolution context's reach
+                    self.captured_declaration(file_id, &rel.to_name, metadata)
+                } else if rel.kind == RelationKind::Calls && from_symbols.len() == 1 {
                     // Special handling for method calls with enhanced resolution
                     debug_print!(self, "Resolving as method call: '{}'", rel.to_name);
                     let res = self.resolve_method_call_enhanced(
@@ -2908,12 +3256,21 @@ impl SimpleIndexer {
                         rel.to_name,
                         rel.kind
                     );
-                    let result = context.resolve_relationship(
-                        &rel.from_name,
-                        &rel.to_name,
-                        rel.kind,
-                        file_id,
-                    );
+                    let result = match from_symbols.as_slice() {
+                        [from] => context.resolve_relationship_from(
+                            from.id,
+                            &rel.from_name,
+                            &rel.to_name,
+                            rel.kind,
+                            file_id,
+                        ),
+                        _ => context.resolve_relationship(
+                            &rel.from_name,
+                            &rel.to_name,
+                            rel.kind,
+                            file_id,
+                        ),
+                    };
                     debug_print!(self, "Resolution result: {:?}", result);
                     // If unresolved call, try language behavior external mapping
                     if result.is_none() && rel.kind == RelationKind::Calls {
@@ -2971,15 +3328,31 @@ impl SimpleIndexer {
 
                 // Get the full symbol data
                 debug_print!(self, "Looking up symbol by ID: {:?}", to_symbol_id);
-                let to_symbol = match self
-                    .document_index
-                    .find_symbol_by_id(to_symbol_id)
-                    .map_err(|e| IndexError::TantivyError {
-                        operation: "find_symbol_by_id".to_string(),
-                        cause: e.to_string(),
-                    })? {
+                let fetched = match cached_target.take() {
+                    Some(symbol) => Some(symbol),
+                    None => self
+                        .document_index
+                        .find_symbol_by_id(to_symbol_id)
+                        .map_err(|e| IndexError::TantivyError {
+                            operation: "find_symbol_by_id".to_string(),
+                            cause: e.to_string(),
+                        })?,
+                };
+                let to_symbol = match fetched {
                     Some(symbol) => {
                         debug_print!(self, "Found target symbol: {}", symbol.name);
+                        if CrossPackageLookupCache::is_selector(&rel.to_name) {
+                            let importer_module = self
+                                .get_behavior_for_file(file_id)
+                                .ok()
+                                .and_then(|b| b.get_module_path_for_file(file_id));
+                            self.cross_package_lookups.insert(
+                                file_id,
+                                &rel.to_name,
+                                importer_module,
+                                symbol.clone(),
+                            );
+                        }
                         symbol
                     }
                     None => {
@@ -3007,9 +3380,21 @@ impl SimpleIndexer {
                         to_symbol.name
                     );
 
+                    // A Go call to a type is a conversion, `Celsius(f)` or
+                    // `auth.Token(raw)`: it uses the type
+                    let kind = if rel.kind == RelationKind::Calls
+                        && to_symbol.language_id == Some(crate::parsing::LanguageId::new("go"))
+                        && matches!(
+                            to_symbol.kind,
+                            SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias
+                        ) {
+                        RelationKind::Uses
+                    } else {
+                        rel.kind
+                    };
+
                     // Check symbol kind compatibility
-                    if !Self::is_compatible_relationship(from_symbol.kind, to_symbol.kind, rel.kind)
-                    {
+                    if !Self::is_compatible_relationship(from_symbol.kind, to_symbol.kind, kind) {
                         debug_print!(
                             self,
                             "[SKIP-INCOMPATIBLE] {} ({:?}) -> {} ({:?}) for {:?}",
@@ -3017,7 +3402,7 @@ impl SimpleIndexer {
                             from_symbol.kind,
                             to_symbol.name,
                             to_symbol.kind,
-                            rel.kind
+                            kind
                         );
                         skipped_count += 1;
                         if let Some((bar, _)) = &progress {
@@ -3027,7 +3412,7 @@ impl SimpleIndexer {
                     }
 
                     // Check visibility (skip for Defines - a type can always see its own methods)
-                    if rel.kind != RelationKind::Defines {
+                    if kind != RelationKind::Defines {
                         debug_print!(
                             self,
                             "Checking visibility: {} (vis: {:?}, module: {:?}) from {} (module: {:?})",
@@ -3063,9 +3448,9 @@ impl SimpleIndexer {
                         from_symbol.id,
                         to_symbol.name,
                         to_symbol.id,
-                        rel.kind
+                        kind
                     );
-                    let mut relationship = Relationship::new(rel.kind);
+                    let mut relationship = Relationship::new(kind);
                     if let Some(ref metadata) = rel.metadata {
                         relationship = relationship.with_metadata(metadata.clone());
                     }
@@ -3074,6 +3459,50 @@ impl SimpleIndexer {
                     if let Some((bar, _)) = &progress {
                         bar.add_extra1(1);
                     }
+
+                    // A use of `StringMap[int]` is a use of `Map[string, int]`:
+                    // follow Go aliases to the types they stand for
+                    if matches!(kind, RelationKind::Uses | RelationKind::References)
+                        && to_symbol.kind == SymbolKind::TypeAlias
+                    {
+                        let mut arguments = rel
+                            .metadata
+                            .as_ref()
+                            .and_then(|metadata| metadata.type_arguments())
+                            .filter(|arguments| {
+                                *arguments != crate::relationship::INFERRED_TYPE_ARGUMENTS
+                            })
+                            .map(str::to_string);
+                        let mut alias = to_symbol.clone();
+                        for target in self.go_alias_targets(&to_symbol) {
+                            let mut relationship = Relationship::new(kind);
+                            if let Some(metadata) = &rel.metadata {
+                                let mut metadata = metadata.clone();
+                                if kind == RelationKind::References {
+                                    // Only instantiations carry type arguments
+                                    arguments = arguments.as_deref().and_then(|arguments| {
+                                        let signature = alias.signature.as_deref()?;
+                                        let target = crate::parsing::go::alias_instantiation(
+                                            signature, arguments,
+                                        )?;
+                                        let (_, bound) = target.split_once('[')?;
+                                        Some(bound.strip_suffix(']')?.to_string())
+                                    });
+                                    let Some(bound) = &arguments else {
+                                        break;
+                                    };
+                                    metadata = metadata.with_type_arguments(Some(bound));
+                                }
+                                relationship = relationship.with_metadata(metadata);
+                            }
+                            self.add_relationship_internal(
+                                from_symbol.id,
+                                target.id,
+                                relationship,
+                            )?;
+                            alias = target;
+                        }
+                    }
                 }
             }
         }
@@ -3097,58 +3526,448 @@ impl SimpleIndexer {
         Ok(())
     }
 
-    // Note: external symbol creation moved to language behavior implementations
+    /// Parameter or local variable of `file_id` a closure captures, the one
+    /// named `name` declared at the position of `metadata`
+    fn captured_declaration(
+        &self,
+        file_id: FileId,
+        name: &str,
+        metadata: &RelationshipMetadata,
+    ) -> Option<SymbolId> {
+        let (line, column) = (metadata.line?, metadata.column?);
+        let symbols = self.document_index.find_symbols_by_file(file_id).ok()?;
+        symbols
+            .into_iter()
+            .filter(|symbol| {
+                *symbol.name == *name
+                    && matches!(symbol.kind, SymbolKind::Parameter | SymbolKind::Variable)
+            })
+            .filter(|symbol| {
+                let range = &symbol.range;
+                (range.start_line, range.start_column) <= (line, column)
+                    && (line, column) <= (range.end_line, range.end_column)
+            })
+            // The innermost statement declaring it
+            .min_by_key(|symbol| {
+                (
+                    symbol.range.end_line - symbol.range.start_line,
+                    symbol.range.end_column,
+                )
+            })
+            .map(|symbol| symbol.id)
+    }
 
-    /// Process pending embeddings after a successful Tantivy commit
-    fn process_pending_embeddings(
-        &mut self,
-        vector_engine: &Arc<Mutex<VectorSearchEngine>>,
-        embedding_generator: &Arc<dyn EmbeddingGenerator>,
-    ) -> IndexResult<()> {
-        if self.pending_embeddings.is_empty() {
-            return Ok(());
+    /// Types a Go alias stands for, following `type A = B` chains
+    ///
+    /// Each target is looked up by its base name: an unqualified one in the
+    /// alias's package first and otherwise only when a single Go type has
+    /// that name, a qualified one (`models.User`) only in the package its
+    /// qualifier imports. The chain ends at a defined type, an unresolved
+    /// target, a cycle or after eight aliases.
+    fn go_alias_targets(&self, alias: &Symbol) -> Vec<Symbol> {
+        use crate::parsing::go::resolution::{package_matches_import, qualifier_import_path};
+
+        let mut targets: Vec<Symbol> = Vec::new();
+        let mut current = alias.clone();
+        while current.kind == SymbolKind::TypeAlias && targets.len() < 8 {
+            let Some(target) = current
+                .signature
+                .as_deref()
+                .and_then(crate::parsing::go::alias_target_from_signature)
+            else {
+                break;
+            };
+            let (qualifier, name) = match target.rsplit_once('.') {
+                Some((qualifier, name)) => (Some(qualifier), name),
+                None => (None, target),
+            };
+            let candidates: Vec<Symbol> = self
+                .find_symbols_by_name(name, Some("go"))
+                .into_iter()
+                .filter(|symbol| {
+                    matches!(
+                        symbol.kind,
+                        SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias
+                    )
+                })
+                .collect();
+            let target = if let Some(qualifier) = qualifier {
+                let imports = self
+                    .get_behavior_for_file(current.file_id)
+                    .map(|behavior| behavior.get_imports_for_file(current.file_id))
+                    .unwrap_or_default();
+                let import = qualifier_import_path(&imports, qualifier).unwrap_or(qualifier);
+                let imported: Vec<&Symbol> = candidates
+                    .iter()
+                    .filter(|symbol| {
+                        symbol.module_path != current.module_path
+                            && symbol
+                                .module_path
+                                .as_deref()
+                                .is_some_and(|package| package_matches_import(package, import))
+                    })
+                    .collect();
+                match imported.as_slice() {
+                    [symbol] => (*symbol).clone(),
+                    _ => break,
+                }
+            } else {
+                let local = candidates
+                    .iter()
+                    .find(|symbol| symbol.module_path == current.module_path);
+                match (local, candidates.as_slice()) {
+                    (Some(symbol), _) | (None, [symbol]) => symbol.clone(),
+                    _ => break,
+                }
+            };
+            if target.id == alias.id || targets.iter().any(|seen| seen.id == target.id) {
+                break;
+            }
+            targets.push(target.clone());
+            current = target;
         }
+        targets
+    }
 
-        // Extract texts for embedding generation
-        let texts: Vec<&str> = self
-            .pending_embeddings
-            .iter()
-            .map(|(_, text)| text.as_str())
+    /// Add Implements relationships between Go types and the interfaces
+    /// their method sets satisfy
+    ///
+    /// Go never declares implementations, so they are computed over all
+    /// indexed Go files once their symbols are stored, across packages. See
+    /// [`crate::parsing::go::analysis::find_implementations`]. After a single
+    /// file changes, only the relationships from or to the types of its
+    /// package are replaced; a batch adds the ones missing.
+    fn resolve_go_implementations(&mut self, changed: Option<&Path>) -> IndexResult<()> {
+        use crate::parsing::go::analysis::find_implementations;
+
+        let paths: Vec<PathBuf> = self
+            .get_all_indexed_paths()
+            .into_iter()
+            .filter(|p| p.extension().is_some_and(|ext| ext == "go"))
             .collect();
+        let sources: std::collections::HashSet<PathBuf> =
+            paths.iter().map(|p| self.workspace_file(p)).collect();
+        self.go_sources.retain(|file| sources.contains(&file.path));
+        // Files not parsed while indexing, e.g. after loading a saved index
+        for source in &sources {
+            if self.go_source_index(source).is_err() {
+                if let Ok(file) = GoSourceFile::read(source) {
+                    self.cache_go_source(file);
+                }
+            }
+        }
+        if self.go_sources.is_empty() {
+            return Ok(());
+        }
 
-        // Generate embeddings
-        let embeddings = embedding_generator
-            .generate_embeddings(&texts)
-            .map_err(|e| IndexError::General(format!("Vector embedding generation failed: {e}")))?;
+        let package =
+            changed.and_then(|path| self.workspace_file(path).parent().map(Path::to_path_buf));
+        let in_package = |file: &str| {
+            package
+                .as_deref()
+                .is_some_and(|package| Path::new(file).parent() == Some(package))
+        };
+        let implementations: Vec<_> = find_implementations(
+            &self.go_sources,
+            self.settings.project.implements_include_tests,
+            self.settings.project.stdlib,
+        )
+        .into_iter()
+        .filter(|i| {
+            package.is_none()
+                || in_package(&i.type_file)
+                || i.interface_file.as_deref().is_some_and(in_package)
+        })
+        .collect();
 
-        // Validate embedding count matches input
-        if embeddings.len() != texts.len() {
-            return Err(IndexError::General(format!(
-                "Embedding count mismatch: expected {}, got {}",
-                texts.len(),
-                embeddings.len()
-            )));
+        self.start_tantivy_batch()?;
+        if let Some(package) = &package {
+            // Types of the package may have gained or lost implementations
+            for path in paths
+                .iter()
+                .filter(|p| self.workspace_file(p).parent() == Some(package.as_path()))
+            {
+                let Some(path_str) = path.to_str() else {
+                    continue;
+                };
+                let Ok(Some((file_id, _))) = self.document_index.get_file_info(path_str) else {
+                    continue;
+                };
+                let symbols = self
+                    .document_index
+                    .find_symbols_by_file(file_id)
+                    .map_err(|e| IndexError::TantivyError {
+                        operation: "find_symbols_by_file".to_string(),
+                        cause: e.to_string(),
+                    })?;
+                for symbol in symbols.iter().filter(|s| {
+                    matches!(
+                        s.kind,
+                        SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias
+                    )
+                }) {
+                    self.document_index
+                        .delete_relationships_of_kind(symbol.id, RelationKind::Implements)
+                        .map_err(|e| IndexError::TantivyError {
+                            operation: "delete_relationships_of_kind".to_string(),
+                            cause: e.to_string(),
+                        })?;
+                }
+            }
+            // Deletions must be visible before checking for existing edges
+            self.commit_tantivy_batch()?;
+            self.start_tantivy_batch()?;
         }
 
-        // Create vector IDs and embeddings pairs
-        let mut vectors = Vec::with_capacity(self.pending_embeddings.len());
-        for (i, (symbol_id, _)) in self.pending_embeddings.iter().enumerate() {
-            // Convert SymbolId to VectorId (both wrap u32)
-            if let Some(vector_id) = crate::vector::VectorId::new(symbol_id.value()) {
-                vectors.push((vector_id, embeddings[i].clone()));
+        let mut added = 0;
+        for implementation in &implementations {
+            // Standard library interfaces have no symbol to point at
+            let Some(interface_file) = &implementation.interface_file else {
+                continue;
+            };
+            let (Some(from), Some(to)) = (
+                self.find_type_symbol(&implementation.type_name, &implementation.type_file)?,
+                self.find_type_symbol(&implementation.interface, interface_file)?,
+            ) else {
+                continue;
+            };
+            let existing = self
+                .document_index
+                .get_relationships_from(from, RelationKind::Implements)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_relationships_from".to_string(),
+                    cause: e.to_string(),
+                })?;
+            if existing.iter().any(|(_, target, _)| *target == to) {
+                continue;
+            }
+            let mut relationship = Relationship::new(RelationKind::Implements);
+            if implementation.pointer_receiver {
+                // Only *T is in the interface's method set. The index keeps
+                // the context only alongside a position: the type's line
+                relationship = relationship.with_metadata(
+                    RelationshipMetadata::new()
+                        .at_position(implementation.type_line, 0)
+                        .with_context("pointer receiver"),
+                );
             }
+            self.add_relationship_internal(from, to, relationship)?;
+            added += 1;
         }
+        self.commit_tantivy_batch()?;
+
+        debug_print!(
+            self,
+            "Go implementations: {} found, {} relationships added",
+            implementations.len(),
+            added
+        );
+        Ok(())
+    }
+
+    /// Add References relationships from Go functions to the enum constants
+    /// their integer literals stand for
+    ///
+    /// `case 3:` on a `models.UserErrorCode` references
+    /// `models.ErrUserNotFound`; the metadata holds the literal's position
+    /// and value. See
+    /// [`crate::parsing::go::analysis::find_enum_literal_comparisons`]. Runs
+    /// after [`Self::resolve_go_implementations`], which refreshes the parsed
+    /// files; after a single file changes, only the literals in it or
+    /// standing for its constants are resolved.
+    fn resolve_go_enum_literals(&mut self, changed: Option<&Path>) -> IndexResult<()> {
+        use crate::parsing::go::analysis::{EnumTable, find_enum_literal_comparisons};
+
+        if self.go_sources.is_empty() {
+            return Ok(());
+        }
+        let changed = changed.map(|path| self.workspace_file(path));
+        let enums = EnumTable::build(&self.go_sources);
+        let findings = find_enum_literal_comparisons(&self.go_sources, &enums);
+        let is_changed = |file: &str| {
+            changed
+                .as_deref()
+                .is_none_or(|changed| Path::new(file) == changed)
+        };
+
+        self.start_tantivy_batch()?;
+        let mut added = 0;
+        for finding in &findings {
+            let Some(function) = finding.function.as_deref() else {
+                continue;
+            };
+            let constant = finding
+                .enum_type
+                .rsplit_once('.')
+                .and_then(|(package, name)| enums.get(package, name))
+                .and_then(|enum_type| enum_type.constant_with_value(finding.literal));
+            let Some(constant) = constant else {
+                continue;
+            };
+            if !is_changed(&finding.file) && !is_changed(&constant.file) {
+                continue;
+            }
+            // Methods are reported as `Type.Method`, indexed as `Method`
+            let function = function.rsplit('.').next().unwrap_or(function);
+            let row = finding.line.saturating_sub(1);
+            let (Some(from), Some(to)) = (
+                self.find_symbol_in_file(function, &finding.file, |s| {
+                    matches!(s.kind, SymbolKind::Function | SymbolKind::Method)
+                        && s.range.start_line <= row
+                        && row <= s.range.end_line
+                })?,
+                self.find_symbol_in_file(&constant.name, &constant.file, |s| {
+                    s.kind == SymbolKind::Constant
+                })?,
+            ) else {
+                continue;
+            };
+            let existing = self
+                .document_index
+                .get_relationships_from(from, RelationKind::References)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_relationships_from".to_string(),
+                    cause: e.to_string(),
+                })?;
+            let recorded = existing.iter().any(|(_, target, rel)| {
+                *target == to
+                    && rel.metadata.as_ref().is_some_and(|m| {
+                        m.line == Some(row) && m.enum_literal() == Some(finding.literal)
+                    })
+            });
+            if recorded {
+                continue;
+            }
+            let relationship = Relationship::new(RelationKind::References).with_metadata(
+                RelationshipMetadata::new()
+                    .at_position(row, 0)
+                    .with_enum_literal(finding.literal),
+            );
+            self.add_relationship_internal(from, to, relationship)?;
+            added += 1;
+        }
+        self.commit_tantivy_batch()?;
+
+        debug_print!(
+            self,
+            "Go enum literals: {} found, {} relationships added",
+            findings.len(),
+            added
+        );
+        Ok(())
+    }
+
+    /// Path of an indexed file on disk: stored paths are relative to the
+    /// workspace root
+    fn workspace_file(&self, path: &Path) -> PathBuf {
+        match &self.settings.workspace_root {
+            Some(root) if path.is_relative() => root.join(path),
+            _ => path.to_path_buf(),
+        }
+    }
+
+    /// Position of the parsed Go file at `path`, or where it belongs
+    fn go_source_index(&self, path: &Path) -> Result<usize, usize> {
+        self.go_sources
+            .binary_search_by(|file| file.path.as_path().cmp(path))
+    }
+
+    /// Keep a parsed Go file for the implements pass, replacing an older
+    /// parse of the same file
+    fn cache_go_source(&mut self, file: GoSourceFile) {
+        match self.go_source_index(&file.path) {
+            Ok(index) => self.go_sources[index] = file,
+            Err(index) => self.go_sources.insert(index, file),
+        }
+    }
+
+    /// Symbol of the type named `name` declared in `file_path`
+    fn find_type_symbol(&self, name: &str, file_path: &str) -> IndexResult<Option<SymbolId>> {
+        self.find_symbol_in_file(name, file_path, |symbol| {
+            matches!(
+                symbol.kind,
+                SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias
+            )
+        })
+    }
+
+    /// First symbol named `name` declared in `file_path` that `accept` takes
+    fn find_symbol_in_file(
+        &self,
+        name: &str,
+        file_path: &str,
+        accept: impl Fn(&Symbol) -> bool,
+    ) -> IndexResult<Option<SymbolId>> {
+        let symbols = self
+            .document_index
+            .find_symbols_by_name(name, None)
+            .map_err(|e| IndexError::TantivyError {
+                operation: "find_symbols_by_name".to_string(),
+                cause: e.to_string(),
+            })?;
+        let file_path = self.workspace_file(Path::new(file_path.trim_start_matches("./")));
+        Ok(symbols
+            .into_iter()
+            .find(|s| {
+                accept(s)
+                    && self.workspace_file(Path::new(s.file_path.trim_start_matches("./")))
+                        == file_path
+            })
+            .map(|s| s.id))
+    }
+
+    // Note: external symbol creation moved to language behavior implementations
+
+    /// Process pending embeddings after a successful Tantivy commit
+    fn process_pending_embeddings(
+        &mut self,
+        vector_engine: &Arc<Mutex<VectorSearchEngine>>,
+        embedding_generator: &Arc<dyn EmbeddingGenerator>,
+    ) -> IndexResult<()> {
+        if self.pending_embeddings.is_empty() {
+            return Ok(());
+        }
+
+        // Extract texts for embedding generation
+        let texts: Vec<&str> = self
+            .pending_embeddings
+            .iter()
+            .map(|(_, text)| text.as_str())
+            .collect();
+
+        // Generate embeddings
+        let embeddings = embedding_generator
+            .generate_embeddings(&texts)
+            .map_err(|e| IndexError::General(format!("Vector embedding generation failed: {e}")))?;
+
+        // Validate embedding count matches input
+        if embeddings.len() != texts.len() {
+            return Err(IndexError::General(format!(
+                "Embedding count mismatch: expected {}, got {}",
+                texts.len(),
+                embeddings.len()
+            )));
+        }
+
+        // Create vector IDs and embeddings pairs
+        let mut vectors = Vec::with_capacity(self.pending_embeddings.len());
+        for (i, (symbol_id, _)) in self.pending_embeddings.iter().enumerate() {
+            // Convert SymbolId to VectorId (both wrap u32)
+            if let Some(vector_id) = crate::vector::VectorId::new(symbol_id.value()) {
+                vectors.push((vector_id, embeddings[i].clone()));
+            }
+        }
+
+        // Index vectors
+        vector_engine
+            .lock()
+            .map_err(|_| IndexError::General("Vector engine mutex poisoned".to_string()))?
+            .index_vectors(&vectors)
+            .map_err(|e| IndexError::General(format!("Vector indexing failed: {e}")))?;
+
+        // Clear pending embeddings
+        self.pending_embeddings.clear();
 
-        // Index vectors
-        vector_engine
-            .lock()
-            .map_err(|_| IndexError::General("Vector engine mutex poisoned".to_string()))?
-            .index_vectors(&vectors)
-            .map_err(|e| IndexError::General(format!("Vector indexing failed: {e}")))?;
-
-        // Clear pending embeddings
-        self.pending_embeddings.clear();
-
         Ok(())
     }
 
@@ -5796,4 +6615,1728 @@ fn main() {
         }
         println!("✓ Real Rust TDD integration test completed!");
     }
+
+    #[test]
+    fn test_go_cross_package_implements() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/module_project");
+        let mut paths = Vec::new();
+        for file in ["models/store.go", "services/user_store.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            paths.push(target);
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer.index_file(path).expect("Failed to index file");
+        }
+
+        let find = |indexer: &SimpleIndexer, name: &str| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| matches!(s.kind, SymbolKind::Struct | SymbolKind::Interface))
+                .unwrap_or_else(|| panic!("{name} not indexed"))
+        };
+        let user_store = find(&indexer, "UserStore");
+        let store = find(&indexer, "Store");
+        let versioned = find(&indexer, "versionedStore");
+
+        let implemented: Vec<SymbolId> = indexer
+            .document_index
+            .get_relationships_from(user_store.id, RelationKind::Implements)
+            .unwrap()
+            .into_iter()
+            .map(|(_, to, _)| to)
+            .collect();
+        assert_eq!(implemented, vec![store.id]);
+        assert!(!implemented.contains(&versioned.id));
+
+        let implementors: Vec<_> = indexer
+            .get_implementations(store.id)
+            .iter()
+            .map(|s| s.name.to_string())
+            .collect();
+        assert_eq!(implementors, vec!["UserStore"]);
+
+        // Re-indexing doesn't duplicate the relationship
+        indexer
+            .index_file(&paths[0])
+            .expect("Failed to re-index file");
+        let store = find(&indexer, "Store");
+        assert_eq!(indexer.get_implementations(store.id).len(), 1);
+    }
+
+    #[test]
+    fn test_go_implements_replaced_when_package_file_changes() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/module_project");
+        for file in ["models/store.go", "services/user_store.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+        }
+        // Move Load to a second file of the package
+        let user_store = temp_dir.path().join("services/user_store.go");
+        let source = fs::read_to_string(&user_store).unwrap();
+        let (declared, load) = source.split_once("func (s *UserStore) Load(").unwrap();
+        let (load, rest) = load.split_once("\n}\n").unwrap();
+        fs::write(&user_store, format!("{declared}{rest}")).unwrap();
+        let loader = temp_dir.path().join("services/loader.go");
+        let header = "package services\n\nimport \"example.com/myproject/models\"\n\n";
+        fs::write(
+            &loader,
+            format!("{header}func (s *UserStore) Load({load}\n}}\n"),
+        )
+        .unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for file in [
+            "models/store.go",
+            "services/user_store.go",
+            "services/loader.go",
+        ] {
+            indexer
+                .index_file(temp_dir.path().join(file))
+                .expect("Failed to index file");
+        }
+        let implementors = |indexer: &SimpleIndexer| -> Vec<String> {
+            let store = indexer
+                .find_symbols_by_name("Store", None)
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Interface)
+                .expect("Store not indexed");
+            indexer
+                .get_implementations(store.id)
+                .iter()
+                .map(|s| s.name.to_string())
+                .collect()
+        };
+        assert_eq!(implementors(&indexer), vec!["UserStore"]);
+
+        // UserStore is declared in the unchanged file, and loses Load
+        fs::write(&loader, "package services\n").unwrap();
+        indexer
+            .index_file(&loader)
+            .expect("Failed to re-index file");
+        assert!(implementors(&indexer).is_empty());
+    }
+
+    #[test]
+    fn test_go_pointer_receiver_implements() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("interfaces.go");
+        fs::copy("tests/fixtures/go/interfaces.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let data_processor = indexer
+            .document_index
+            .find_symbols_by_name("DataProcessor", None)
+            .unwrap()
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Interface)
+            .expect("DataProcessor not indexed");
+        let mut implementors: Vec<_> = indexer
+            .get_implementations(data_processor.id)
+            .into_iter()
+            .map(|s| s.name.to_string())
+            .collect();
+        implementors.sort();
+        assert_eq!(implementors, vec!["FileProcessor", "JSONProcessor"]);
+
+        // Both satisfy it through pointer receivers only
+        let json_processor = indexer
+            .document_index
+            .find_symbols_by_name("JSONProcessor", None)
+            .unwrap()
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Struct)
+            .expect("JSONProcessor not indexed");
+        let relationships = indexer
+            .document_index
+            .get_relationships_from(json_processor.id, RelationKind::Implements)
+            .unwrap();
+        let (_, _, relationship) = relationships
+            .iter()
+            .find(|(_, to, _)| *to == data_processor.id)
+            .expect("JSONProcessor should implement DataProcessor");
+        assert_eq!(
+            relationship
+                .metadata
+                .as_ref()
+                .and_then(|m| m.context.as_deref()),
+            Some("pointer receiver")
+        );
+    }
+
+    #[test]
+    fn test_go_embedded_interfaces_flattened_when_indexing() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("interfaces.go");
+        fs::copy("tests/fixtures/go/interfaces.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        // ReadWriteCloser embeds Reader, Writer and the stdlib io.Closer
+        let read_write_closer = indexer
+            .document_index
+            .find_symbols_by_name("ReadWriteCloser", None)
+            .unwrap()
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Interface)
+            .expect("ReadWriteCloser not indexed");
+        let implementors: Vec<_> = indexer
+            .get_implementations(read_write_closer.id)
+            .into_iter()
+            .map(|s| s.name.to_string())
+            .collect();
+        assert!(implementors.contains(&"FileProcessor".to_string()));
+        // JSONProcessor has none of Read, Write and Close
+        assert!(!implementors.contains(&"JSONProcessor".to_string()));
+    }
+
+    #[test]
+    fn test_go_constant_references_across_package_files() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/module_project");
+        // auth.go refers to constants of database.go and is indexed first
+        let mut paths = Vec::new();
+        for file in ["services/auth.go", "services/database.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            paths.push(target);
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer
+                .index_file_no_resolve(path)
+                .expect("Failed to index file");
+        }
+        indexer.resolve_cross_file_relationships().unwrap();
+
+        let users = |name: &str| -> Vec<String> {
+            let constant = indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Constant)
+                .unwrap_or_else(|| panic!("{name} not indexed"));
+            let mut users: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(constant.id, RelationKind::Uses)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .map(|s| s.name.to_string())
+                .collect();
+            users.sort();
+            users
+        };
+        // Referenced from both files of the package
+        assert_eq!(users("MaxConnections"), vec!["Acquire", "CanConnect"]);
+        assert_eq!(users("TokenExpiry"), vec!["NewSession"]);
+        // Unexported constants are visible to the whole package too
+        assert_eq!(users("defaultPoolSize"), vec!["CanConnect"]);
+    }
+
+    #[test]
+    fn test_go_shadowed_package_variables() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("scoping.go");
+        fs::copy("tests/fixtures/go/scoping.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let users = |name: &str| -> Vec<String> {
+            let package_level = indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| {
+                    !matches!(
+                        s.scope_context,
+                        Some(crate::symbol::ScopeContext::Local { .. })
+                    )
+                })
+                .unwrap_or_else(|| panic!("{name} not indexed"));
+            let mut users: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(package_level.id, RelationKind::Uses)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .map(|s| s.name.to_string())
+                .collect();
+            users.sort();
+            users.dedup();
+            users
+        };
+        // DemonstrateScoping and GetDisplayName only see their own globalVar
+        assert_eq!(users("globalVar"), vec!["Initialize", "main"]);
+        assert!(users("packageCount").contains(&"DemonstrateScoping".to_string()));
+        // ProcessData's `debug := false` shadows the constant
+        assert_eq!(users("debug"), vec!["UpdatePort"]);
+
+        // The three nested shadows are distinct locals of DemonstrateScoping
+        let mut shadows: Vec<u32> = indexer
+            .document_index
+            .find_symbols_by_name("globalVar", None)
+            .unwrap()
+            .into_iter()
+            .filter(|s| {
+                matches!(
+                    &s.scope_context,
+                    Some(crate::symbol::ScopeContext::Local { parent_name: Some(parent), .. })
+                        if parent.as_str() == "DemonstrateScoping"
+                )
+            })
+            .map(|s| s.range.start_line)
+            .collect();
+        shadows.sort();
+        assert_eq!(shadows, vec![205, 211, 217]);
+    }
+
+    #[test]
+    fn test_go_closure_captures_reference_declarations() {
+        let temp_dir = TempDir::new().unwrap();
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for file in ["scoping.go", "complex.go"] {
+            let target = temp_dir.path().join(file);
+            fs::copy(Path::new("tests/fixtures/go").join(file), &target).unwrap();
+            indexer.index_file(&target).expect("Failed to index file");
+        }
+
+        // (closure, captured symbol, its kind, mutated) for a function
+        let captures = |function: &str| {
+            let function = indexer
+                .document_index
+                .find_symbols_by_name(function, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .unwrap_or_else(|| panic!("{function} not indexed"));
+            let mut captures: Vec<(String, String, SymbolKind, bool)> = indexer
+                .document_index
+                .get_relationships_from(function.id, RelationKind::References)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(_, to, relationship)| {
+                    let (closure, mutated) = relationship
+                        .metadata?
+                        .capture()
+                        .map(|(closure, mutated)| (closure.to_string(), mutated))?;
+                    let target = indexer.get_symbol(to)?;
+                    Some((closure, target.name.to_string(), target.kind, mutated))
+                })
+                .collect();
+            captures.sort_by(|a, b| (&a.0, &a.1).cmp(&(&b.0, &b.1)));
+            captures
+        };
+
+        // The counter's closure shares `count` with CreateCounter
+        assert_eq!(
+            captures("CreateCounter"),
+            vec![(
+                "CreateCounter.func1".to_string(),
+                "count".to_string(),
+                SymbolKind::Variable,
+                true
+            )]
+        );
+
+        // The retry wrapper only reads its configuration
+        let retry = captures("CreateRetryFunc");
+        for parameter in ["maxAttempts", "backoff"] {
+            for closure in ["CreateRetryFunc.func1", "CreateRetryFunc.func1.1"] {
+                assert!(
+                    retry.contains(&(
+                        closure.to_string(),
+                        parameter.to_string(),
+                        SymbolKind::Parameter,
+                        false
+                    )),
+                    "{closure} should capture {parameter}: {retry:?}"
+                );
+            }
+        }
+        assert!(retry.iter().all(|(_, _, _, mutated)| !mutated));
+    }
+
+    #[test]
+    fn test_go_variadic_interface_method_calls() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("interfaces.go");
+        fs::copy("tests/fixtures/go/interfaces.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let callees = |caller: &str| -> Vec<String> {
+            let function = indexer
+                .document_index
+                .find_symbols_by_name(caller, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .unwrap_or_else(|| panic!("{caller} not indexed"));
+            indexer
+                .document_index
+                .get_relationships_from(function.id, RelationKind::Calls)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(_, to, _)| indexer.get_symbol(to))
+                .map(|s| s.name.to_string())
+                .collect()
+        };
+        // Fewer arguments than parameters, and a spread slice
+        assert!(callees("LogMessage").contains(&"Logger.Log".to_string()));
+        assert!(callees("LogEach").contains(&"Logger.Log".to_string()));
+    }
+
+    #[test]
+    fn test_go_dot_imports() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/dot_imports");
+        // The importing file comes first, before the packages it dot-imports
+        let mut paths = Vec::new();
+        for file in [
+            "shapes.go",
+            "math/math.go",
+            "geometry/geometry.go",
+            "units/units.go",
+        ] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            paths.push(target);
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer
+                .index_file_no_resolve(path)
+                .expect("Failed to index file");
+        }
+        indexer.resolve_cross_file_relationships().unwrap();
+
+        let targets = |from: &str, kind: RelationKind| -> Vec<(String, String)> {
+            let function = indexer
+                .document_index
+                .find_symbols_by_name(from, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .unwrap_or_else(|| panic!("{from} not indexed"));
+            indexer
+                .document_index
+                .get_relationships_from(function.id, kind)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(_, to, _)| indexer.get_symbol(to))
+                .map(|s| {
+                    let package = s.module_path.as_deref().unwrap_or_default().to_string();
+                    (package, s.name.to_string())
+                })
+                .collect()
+        };
+        // Standard library package
+        assert_eq!(
+            targets("circumference", RelationKind::Uses),
+            vec![("math".to_string(), "Pi".to_string())]
+        );
+        assert_eq!(
+            targets("hypotenuse", RelationKind::Calls),
+            vec![("math".to_string(), "Sqrt".to_string())]
+        );
+        // Local package
+        assert_eq!(
+            targets("square", RelationKind::Calls),
+            vec![("geometry".to_string(), "Area".to_string())]
+        );
+        // geometry and units both export Scale: neither is picked
+        assert!(targets("doubled", RelationKind::Calls).is_empty());
+    }
+
+    #[test]
+    fn test_go_relative_imports() {
+        use crate::parsing::go::resolution::{GoResolutionContext, RelativeImport};
+
+        // Index `files` (fixture path, path in the workspace) and return the
+        // indexer with the file id of main.go
+        let index = |temp_dir: &TempDir, files: &[(&str, &str)]| {
+            let mut paths = Vec::new();
+            for (fixture, file) in files {
+                let target = temp_dir.path().join(file);
+                fs::create_dir_all(target.parent().unwrap()).unwrap();
+                fs::copy(Path::new("tests/fixtures/go").join(fixture), &target).unwrap();
+                paths.push(target);
+            }
+            let settings = Settings {
+                workspace_root: Some(temp_dir.path().to_path_buf()),
+                index_path: temp_dir.path().join("index"),
+                ..Default::default()
+            };
+            let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+            let mut main_id = None;
+            for path in &paths {
+                let result = indexer
+                    .index_file_no_resolve(path)
+                    .expect("Failed to index file");
+                if let (crate::IndexingResult::Indexed(file_id), true) =
+                    (result, path.ends_with("main.go"))
+                {
+                    main_id = Some(file_id);
+                }
+            }
+            indexer.resolve_cross_file_relationships().unwrap();
+            (indexer, main_id.expect("main.go not indexed"))
+        };
+        let calls = |indexer: &SimpleIndexer| -> Vec<(String, String)> {
+            let main = indexer
+                .document_index
+                .find_symbols_by_name("main", None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .expect("main not indexed");
+            let mut calls: Vec<_> = indexer
+                .document_index
+                .get_relationships_from(main.id, RelationKind::Calls)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(_, to, _)| indexer.get_symbol(to))
+                .map(|s| {
+                    let package = s.module_path.as_deref().unwrap_or_default().to_string();
+                    (package, s.name.to_string())
+                })
+                .collect();
+            calls.sort();
+            calls
+        };
+
+        // Sibling ./local and parent ../shared, both indexed
+        let temp_dir = TempDir::new().unwrap();
+        let (indexer, _) = index(
+            &temp_dir,
+            &[
+                ("module_project/main.go", "module_project/main.go"),
+                (
+                    "module_project/local/local.go",
+                    "module_project/local/local.go",
+                ),
+                ("shared/shared.go", "shared/shared.go"),
+            ],
+        );
+        assert_eq!(
+            calls(&indexer),
+            vec![
+                (
+                    "module_project/local".to_string(),
+                    "DoSomething".to_string()
+                ),
+                ("shared".to_string(), "Helper".to_string()),
+            ]
+        );
+
+        // With module_project as the workspace, ../shared leaves the indexed
+        // tree: recorded as external-relative and left unresolved
+        let temp_dir = TempDir::new().unwrap();
+        let (indexer, main_id) = index(
+            &temp_dir,
+            &[
+                ("module_project/main.go", "main.go"),
+                ("module_project/local/local.go", "local/local.go"),
+            ],
+        );
+        assert_eq!(
+            calls(&indexer),
+            vec![("local".to_string(), "DoSomething".to_string())]
+        );
+        let mut context = indexer.build_resolution_context(main_id).unwrap();
+        let context = context
+            .as_any_mut()
+            .downcast_mut::<GoResolutionContext>()
+            .unwrap();
+        assert_eq!(
+            context.relative_import("./local"),
+            Some(&RelativeImport::Package("local".to_string()))
+        );
+        assert_eq!(
+            context.relative_import("../shared"),
+            Some(&RelativeImport::ExternalRelative)
+        );
+    }
+
+    #[test]
+    fn test_go_aliased_imports_resolve_to_package() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("examples/go/app");
+        let mut main_id = None;
+        let mut paths = Vec::new();
+        for file in ["go.mod", "main.go", "models/user.go", "config/settings.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            if file.ends_with(".go") {
+                paths.push(target);
+            }
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            let result = indexer
+                .index_file_no_resolve(path)
+                .expect("Failed to index file");
+            if let (crate::IndexingResult::Indexed(file_id), true) =
+                (result, path.ends_with("main.go"))
+            {
+                main_id = Some(file_id);
+            }
+        }
+        indexer.resolve_cross_file_relationships().unwrap();
+        let main_id = main_id.expect("main.go not indexed");
+
+        // Each import keeps its path next to the alias
+        let imports = indexer
+            .document_index
+            .get_imports_for_file(main_id)
+            .unwrap();
+        let alias_of = |path: &str| -> Vec<Option<String>> {
+            let mut aliases: Vec<_> = imports
+                .iter()
+                .filter(|import| import.path == path)
+                .map(|import| import.alias.clone())
+                .collect();
+            aliases.sort();
+            aliases
+        };
+        assert_eq!(
+            alias_of("app/models"),
+            vec![None, Some("userModel".to_string())]
+        );
+        assert_eq!(
+            alias_of("app/config"),
+            vec![None, Some("appConfig".to_string())]
+        );
+
+        let function = |name: &str, package: &str| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| {
+                    s.kind == SymbolKind::Function && s.module_path.as_deref() == Some(package)
+                })
+                .unwrap_or_else(|| panic!("{package}.{name} not indexed"))
+        };
+        let new_user = function("NewUser", "models");
+        let new_settings = function("NewSettings", "config");
+
+        // The alias and the package name resolve to the same definition
+        let context = indexer.build_resolution_context(main_id).unwrap();
+        assert_eq!(context.resolve("userModel.NewUser"), Some(new_user.id));
+        assert_eq!(context.resolve("models.NewUser"), Some(new_user.id));
+        assert_eq!(
+            context.resolve("appConfig.NewSettings"),
+            Some(new_settings.id)
+        );
+        assert_eq!(context.resolve("config.NewSettings"), Some(new_settings.id));
+        assert!(!context.is_external_import("userModel"));
+
+        // Callers through the alias count as references to the definition
+        let callers = |symbol: &Symbol| -> Vec<String> {
+            let mut callers: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(symbol.id, RelationKind::Calls)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .filter(|s| s.file_id == main_id)
+                .map(|s| s.name.to_string())
+                .collect();
+            callers.sort();
+            callers.dedup();
+            callers
+        };
+        assert!(callers(&new_user).contains(&"main".to_string()));
+        assert!(callers(&new_settings).contains(&"main".to_string()));
+    }
+
+    #[test]
+    fn test_go_qualified_calls_cross_packages() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/cross_module");
+        let mut paths = Vec::new();
+        for file in ["go.mod", "config/dirs.go", "utils/helper/helper.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            if file.ends_with(".go") {
+                paths.push(target);
+            }
+        }
+        // Calls config.InitGlobalDirs and declares its own InitGlobalDirs
+        let main = temp_dir.path().join("main.go");
+        fs::copy("examples/go/test_cross_module_calls.go", &main).unwrap();
+        paths.push(main.clone());
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        let mut main_id = None;
+        for path in &paths {
+            let result = indexer
+                .index_file_no_resolve(path)
+                .expect("Failed to index file");
+            if let (crate::IndexingResult::Indexed(file_id), true) = (result, *path == main) {
+                main_id = Some(file_id);
+            }
+        }
+        indexer.resolve_cross_file_relationships().unwrap();
+        let main_id = main_id.expect("main.go not indexed");
+
+        let function = |name: &str, in_main: bool| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function && (s.file_id == main_id) == in_main)
+                .unwrap_or_else(|| panic!("{name} not indexed"))
+        };
+        let imported_init = function("InitGlobalDirs", false);
+        let local_init = function("InitGlobalDirs", true);
+        let process_data = function("ProcessData", false);
+        assert_eq!(imported_init.module_path.as_deref(), Some("config"));
+
+        let callers = |symbol: &Symbol| -> Vec<String> {
+            let mut callers: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(symbol.id, RelationKind::Calls)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .map(|s| s.name.to_string())
+                .collect();
+            callers.sort();
+            callers.dedup();
+            callers
+        };
+        // `config.InitGlobalDirs()` crosses into config, the bare call stays
+        assert_eq!(
+            callers(&imported_init),
+            vec!["InitConfigFile", "ProcessLocalData"]
+        );
+        assert_eq!(callers(&local_init), vec!["ProcessLocalData"]);
+        assert_eq!(callers(&process_data), vec!["InitConfigFile"]);
+
+        // The cached context resolves them the same way
+        indexer.build_symbol_cache().unwrap();
+        let context = indexer.build_resolution_context(main_id).unwrap();
+        assert_eq!(context.resolve("InitGlobalDirs"), Some(local_init.id));
+        assert_eq!(
+            context.resolve("config.InitGlobalDirs"),
+            Some(imported_init.id)
+        );
+        assert_eq!(context.resolve("helper.ProcessData"), Some(process_data.id));
+    }
+
+    #[test]
+    fn test_go_method_context_carries_receiver_kind() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("structs.go");
+        fs::copy("tests/fixtures/go/structs.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let receiver = |name: &str| {
+            let method = indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Method)
+                .unwrap_or_else(|| panic!("{name} not indexed"));
+            indexer
+                .get_symbol_context(method.id, crate::symbol::context::ContextIncludes::empty())
+                .unwrap()
+                .receiver
+                .unwrap_or_else(|| panic!("{name} has no receiver"))
+        };
+
+        // User mixes value and pointer receivers
+        let display = receiver("GetDisplayName");
+        assert_eq!(display.kind, crate::symbol::ReceiverKind::Value);
+        assert_eq!(display.name.as_deref(), Some("u"));
+        assert_eq!(display.type_name, "User");
+        let set_age = receiver("SetAge");
+        assert_eq!(set_age.kind, crate::symbol::ReceiverKind::Pointer);
+        assert_eq!(set_age.to_string(), "u *User (Pointer)");
+
+        // Functions carry no receiver
+        let new_user = indexer
+            .document_index
+            .find_symbols_by_name("NewUser", None)
+            .unwrap()
+            .into_iter()
+            .next()
+            .unwrap();
+        let context = indexer
+            .get_symbol_context(
+                new_user.id,
+                crate::symbol::context::ContextIncludes::empty(),
+            )
+            .unwrap();
+        assert!(context.receiver.is_none());
+    }
+
+    #[test]
+    fn test_go_promoted_method_resolves_to_embedded_type() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("structs.go");
+        fs::copy("tests/fixtures/go/structs.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        let file_id = indexer
+            .index_file(&target)
+            .expect("Failed to index file")
+            .file_id();
+
+        let find = |name: &str, kind: SymbolKind| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == kind)
+                .unwrap_or_else(|| panic!("{name} not indexed"))
+        };
+        let set_age = find("SetAge", SymbolKind::Method);
+
+        // `person.SetAge(30)` on a *Person calls User.SetAge
+        let context = indexer.build_resolution_context(file_id).unwrap();
+        assert_eq!(context.resolve("Person.SetAge"), Some(set_age.id));
+        let celebrate = find("CelebrateBirthday", SymbolKind::Function);
+        let called: Vec<SymbolId> = indexer
+            .get_called_functions(celebrate.id)
+            .into_iter()
+            .map(|s| s.id)
+            .collect();
+        assert_eq!(called, vec![set_age.id]);
+
+        // Person's own GetFullName is its only one; User's methods are promoted
+        let person = find("Person", SymbolKind::Struct);
+        let promoted = indexer
+            .get_symbol_context(
+                person.id,
+                crate::symbol::context::ContextIncludes::DEFINITIONS,
+            )
+            .unwrap()
+            .relationships
+            .promoted
+            .expect("Person should have promoted methods");
+        let names: Vec<_> = promoted.iter().map(|p| p.method.name.as_str()).collect();
+        for name in ["GetDisplayName", "SetAge", "Verify"] {
+            assert!(names.contains(&name), "{names:?}");
+        }
+        assert!(!names.contains(&"GetFullName"));
+        assert!(promoted.iter().all(|p| p.origin == "User"));
+    }
+
+    #[test]
+    fn test_go_type_parameter_constraints_are_used() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("generics.go");
+        fs::copy("tests/fixtures/go/generics.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        for (constraint, user) in [("Comparable", "Max"), ("Number", "Sum")] {
+            let interface = indexer
+                .find_symbols_by_name(constraint, None)
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Interface)
+                .unwrap_or_else(|| panic!("{constraint} not indexed"));
+            let users: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(interface.id, RelationKind::Uses)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .map(|s| s.name.to_string())
+                .collect();
+            assert!(
+                users.iter().any(|name| name == user),
+                "{constraint}: {users:?}"
+            );
+        }
+    }
+
+    #[test]
+    fn test_go_instantiations_record_type_arguments() {
+        let temp_dir = TempDir::new().unwrap();
+        let mut paths = Vec::new();
+        for (source, file) in [
+            ("examples/go/comprehensive.go", "main/comprehensive.go"),
+            ("tests/fixtures/go/generics.go", "generics/generics.go"),
+        ] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(source, &target).unwrap();
+            paths.push(target);
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer.index_file(path).expect("Failed to index file");
+        }
+
+        let instantiations = |name: &str| {
+            let generic = indexer
+                .find_symbols_by_name(name, None)
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .unwrap_or_else(|| panic!("{name} not indexed"));
+            let mut found: Vec<(String, String, Option<u32>)> = indexer
+                .get_instantiating_symbols_with_metadata(generic.id)
+                .into_iter()
+                .map(|(user, metadata)| {
+                    (
+                        user.name.to_string(),
+                        metadata.type_arguments().unwrap().to_string(),
+                        metadata.line,
+                    )
+                })
+                .collect();
+            found.sort();
+            found
+        };
+
+        assert_eq!(
+            instantiations("NewGenericContainer"),
+            vec![("main".to_string(), "int, string".to_string(), Some(366))]
+        );
+        assert_eq!(
+            instantiations("NewMap"),
+            vec![(
+                "ExampleUsage".to_string(),
+                "int, string".to_string(),
+                Some(353)
+            )]
+        );
+        // Sum(numbers) infers its type argument
+        let sum: Vec<_> = instantiations("Sum")
+            .into_iter()
+            .map(|(user, arguments, _)| (user, arguments))
+            .collect();
+        assert_eq!(
+            sum,
+            vec![
+                ("ExampleUsage".to_string(), "inferred".to_string()),
+                (
+                    "ProcessSerializableNumbers".to_string(),
+                    "inferred".to_string()
+                ),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_go_qualified_names_disambiguate_members() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = temp_dir.path().join("qualified_names.go");
+        fs::copy("tests/fixtures/go/qualified_names_test.go", &fixture).unwrap();
+        // A declared method shares the interfaces' Close name
+        let file = temp_dir.path().join("file.go");
+        fs::write(
+            &file,
+            "package test\n\ntype File struct{}\n\nfunc (f *File) Close() error { return nil }\n",
+        )
+        .unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&fixture).expect("Failed to index file");
+        indexer.index_file(&file).expect("Failed to index file");
+
+        let names = |query: &str| {
+            let mut names: Vec<String> = indexer
+                .find_symbols_by_qualified_name(query, None)
+                .into_iter()
+                .map(|s| s.name.to_string())
+                .collect();
+            names.sort();
+            names
+        };
+        assert_eq!(
+            names("Close"),
+            vec!["Close", "Reader.Close", "Writer.Close"]
+        );
+        assert_eq!(names("Name"), vec!["Person.Name", "Product.Name"]);
+
+        for query in [
+            "Reader.Close",
+            "Writer.Close",
+            "Person.Name",
+            "Product.Name",
+        ] {
+            assert_eq!(names(query), vec![query.to_string()]);
+        }
+        let file_close = indexer.find_symbols_by_qualified_name("File.Close", None);
+        assert_eq!(file_close.len(), 1, "{file_close:?}");
+        assert!(
+            file_close[0]
+                .signature
+                .as_deref()
+                .is_some_and(|sig| sig.contains("(f *File)"))
+        );
+        assert!(
+            indexer
+                .find_symbols_by_qualified_name("Person.Close", None)
+                .is_empty()
+        );
+    }
+
+    #[test]
+    fn test_go_type_references_in_every_position() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("type_references.go");
+        fs::copy("tests/fixtures/go/type_references.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let job = indexer
+            .document_index
+            .find_symbols_by_name("Job", None)
+            .unwrap()
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Struct)
+            .expect("Job not indexed");
+        let mut users: Vec<String> = indexer
+            .document_index
+            .get_relationships_to(job.id, RelationKind::Uses)
+            .unwrap()
+            .into_iter()
+            .filter_map(|(from, _, _)| indexer.get_symbol(from))
+            .map(|s| s.name.to_string())
+            .collect();
+        users.sort();
+        // The conversion `Job(old)` counts as a use, not a call
+        assert_eq!(
+            users,
+            vec![
+                "Allocate",
+                "Buffer",
+                "Describe",
+                "FirstJob",
+                "IsJob",
+                "NextJob",
+                "PriorityJob",
+                "Queue",
+                "Sample",
+                "Submit",
+                "Upgrade",
+            ]
+        );
+        assert!(
+            indexer
+                .document_index
+                .get_relationships_to(job.id, RelationKind::Calls)
+                .unwrap()
+                .is_empty()
+        );
+    }
+
+    #[test]
+    fn test_go_promoted_field_resolves_in_cached_context() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("structs.go");
+        fs::copy("tests/fixtures/go/structs.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        let file_id = indexer
+            .index_file(&target)
+            .expect("Failed to index file")
+            .file_id();
+        // Resolution goes through the symbol cache from here on
+        indexer.build_symbol_cache().unwrap();
+
+        let find = |name: &str, kind: SymbolKind| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == kind)
+                .unwrap_or_else(|| panic!("{name} not indexed"))
+        };
+        let email = find("User.Email", SymbolKind::Field);
+        let set_age = find("SetAge", SymbolKind::Method);
+
+        // Person embeds User: `person.Email` is User's field
+        let context = indexer.build_resolution_context(file_id).unwrap();
+        assert_eq!(context.resolve("Person.Email"), Some(email.id));
+        assert_eq!(context.resolve("Person.SetAge"), Some(set_age.id));
+    }
+
+    #[test]
+    fn test_go_calls_on_field_elements_narrow_to_stored_type() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("field_dispatch.go");
+        fs::copy("tests/fixtures/go/field_dispatch.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let method_of = |receiver: &str, name: &str| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| {
+                    s.kind == SymbolKind::Method
+                        && s.signature
+                            .as_deref()
+                            .is_some_and(|sig| sig.contains(&format!("*{receiver})")))
+                })
+                .unwrap_or_else(|| panic!("{receiver}.{name} not indexed"))
+        };
+        let called_by = |caller: &str| -> Vec<SymbolId> {
+            let caller = indexer
+                .document_index
+                .find_symbols_by_name(caller, None)
+                .unwrap()
+                .remove(0);
+            indexer
+                .get_called_functions(caller.id)
+                .into_iter()
+                .map(|s| s.id)
+                .collect()
+        };
+
+        // processors only ever holds &DefaultProcessor{}
+        assert!(called_by("RunAll").contains(&method_of("DefaultProcessor", "Process").id));
+        assert!(called_by("Lookup").contains(&method_of("DefaultProcessor", "Name").id));
+        assert!(!called_by("RunAll").contains(&method_of("RetryingProcessor", "Process").id));
+    }
+
+    #[test]
+    fn test_go_assignments_narrow_receivers_within_their_function() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("interfaces.go");
+        fs::copy("tests/fixtures/go/interfaces.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let method_of = |receiver: &str, name: &str| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| {
+                    s.kind == SymbolKind::Method
+                        && s.signature
+                            .as_deref()
+                            .is_some_and(|sig| sig.contains(&format!("*{receiver})")))
+                })
+                .unwrap_or_else(|| panic!("{receiver}.{name} not indexed"))
+                .id
+        };
+        let called_by = |caller: &str| -> Vec<SymbolId> {
+            let caller = indexer
+                .document_index
+                .find_symbols_by_name(caller, None)
+                .unwrap()
+                .remove(0);
+            indexer
+                .get_called_functions(caller.id)
+                .into_iter()
+                .map(|s| s.id)
+                .collect()
+        };
+
+        // ProcessWithFile's p holds a FileProcessor, and q a JSONProcessor
+        // once assigned; ProcessWithJSON, later in the file, has its own p
+        let with_file = called_by("ProcessWithFile");
+        assert!(with_file.contains(&method_of("FileProcessor", "Process")));
+        assert!(with_file.contains(&method_of("FileProcessor", "Validate")));
+        assert!(with_file.contains(&method_of("JSONProcessor", "GetMetadata")));
+        assert!(!with_file.contains(&method_of("JSONProcessor", "Process")));
+        assert!(called_by("ProcessWithJSON").contains(&method_of("JSONProcessor", "Process")));
+    }
+
+    #[test]
+    fn test_go_ambiguous_promoted_method_not_resolved() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("promotion_conflicts.go");
+        fs::copy("tests/fixtures/go/promotion_conflicts.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        let file_id = indexer
+            .index_file(&target)
+            .expect("Failed to index file")
+            .file_id();
+
+        // File and Conn both promote Close into Session at depth 1
+        let context = indexer.build_resolution_context(file_id).unwrap();
+        assert!(context.is_ambiguous_selector("Session.Close"));
+        assert_eq!(context.resolve("Session.Close"), None);
+        assert!(!context.is_ambiguous_selector("ManagedSession.Close"));
+        assert!(!context.is_ambiguous_selector("Pool.Close"));
+
+        // No promoted edge from Shutdown to either Close
+        let shutdown = indexer
+            .document_index
+            .find_symbols_by_name("Shutdown", None)
+            .unwrap()
+            .remove(0);
+        let called: Vec<String> = indexer
+            .get_called_functions(shutdown.id)
+            .into_iter()
+            .map(|s| s.name.to_string())
+            .collect();
+        assert!(!called.contains(&"Close".to_string()), "{called:?}");
+        assert!(called.contains(&"Write".to_string()), "{called:?}");
+    }
+
+    #[test]
+    fn test_go_parenthesized_receivers_resolve() {
+        let temp_dir = TempDir::new().unwrap();
+        let target = temp_dir.path().join("parenthesized.go");
+        fs::copy("tests/fixtures/go/parenthesized.go", &target).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&target).expect("Failed to index file");
+
+        let method = |name: &str| {
+            indexer
+                .document_index
+                .find_symbols_by_name(name, None)
+                .unwrap()
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Method)
+                .unwrap_or_else(|| panic!("{name} not indexed"))
+        };
+        let callers = |name: &str| -> Vec<String> {
+            let mut callers: Vec<String> = indexer
+                .document_index
+                .get_relationships_to(method(name).id, RelationKind::Calls)
+                .unwrap()
+                .into_iter()
+                .filter_map(|(from, _, _)| indexer.get_symbol(from))
+                .map(|s| s.name.to_string())
+                .collect();
+            callers.sort();
+            callers
+        };
+        assert_eq!(callers("Port"), vec!["Address", "literalPort", "portOf"]);
+        assert_eq!(
+            callers("Address"),
+            vec!["addressOf", "convertedAddress", "defaultAddress"]
+        );
+    }
+
+    #[test]
+    fn test_go_generic_alias_resolves_to_target() {
+        let temp_dir = TempDir::new().unwrap();
+        let generics = temp_dir.path().join("generics.go");
+        fs::copy("tests/fixtures/go/generics.go", &generics).unwrap();
+        let counts = temp_dir.path().join("counts.go");
+        fs::write(
+            &counts,
+            "package generics\n\nfunc Counts() StringMap[int] {\n\tvar counts StringMap[int]\n\treturn counts\n}\n",
+        )
+        .unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&generics).expect("Failed to index file");
+        indexer.index_file(&counts).expect("Failed to index file");
+
+        let string_map = indexer
+            .find_symbols_by_name("StringMap", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::TypeAlias)
+            .expect("StringMap is a type alias");
+        let context = indexer
+            .get_symbol_context(
+                string_map.id,
+                crate::symbol::context::ContextIncludes::empty(),
+            )
+            .unwrap();
+        assert_eq!(context.alias_target.as_deref(), Some("Map[string, V]"));
+
+        let map = indexer
+            .find_symbols_by_name("Map", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Struct)
+            .expect("Map not indexed");
+        let users: Vec<String> = indexer
+            .document_index
+            .get_relationships_to(map.id, RelationKind::Uses)
+            .unwrap()
+            .into_iter()
+            .filter_map(|(from, _, _)| indexer.get_symbol(from))
+            .map(|s| s.name.to_string())
+            .collect();
+        for user in ["StringMap", "IntSet", "Counts"] {
+            assert!(users.iter().any(|name| name == user), "{user}: {users:?}");
+        }
+
+        // StringMap[int] instantiates Map with the alias's arguments bound
+        let instantiations: Vec<(String, String)> = indexer
+            .get_instantiating_symbols_with_metadata(map.id)
+            .into_iter()
+            .map(|(user, metadata)| {
+                (
+                    user.name.to_string(),
+                    metadata.type_arguments().unwrap_or_default().to_string(),
+                )
+            })
+            .collect();
+        assert!(
+            instantiations.contains(&("Counts".to_string(), "string, int".to_string())),
+            "{instantiations:?}"
+        );
+        assert!(
+            instantiations.contains(&("IntSet".to_string(), "int, struct{}".to_string())),
+            "{instantiations:?}"
+        );
+    }
+
+    #[test]
+    fn test_go_alias_methods_come_from_target_package() {
+        let temp_dir = TempDir::new().unwrap();
+        let fixture = Path::new("tests/fixtures/go/alias_targets");
+        let mut paths = Vec::new();
+        for file in ["go.mod", "models/user.go", "api/account.go"] {
+            let target = temp_dir.path().join(file);
+            fs::create_dir_all(target.parent().unwrap()).unwrap();
+            fs::copy(fixture.join(file), &target).unwrap();
+            if file.ends_with(".go") {
+                paths.push(target);
+            }
+        }
+        // PublicInnerStruct = InnerStruct, unqualified within one package
+        let structs = temp_dir.path().join("structs.go");
+        fs::copy("tests/fixtures/go/structs.go", &structs).unwrap();
+        paths.push(structs);
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer
+                .index_file_no_resolve(path)
+                .expect("Failed to index file");
+        }
+        indexer.resolve_cross_file_relationships().unwrap();
+
+        let callees = |caller: &str| -> Vec<(String, Option<String>)> {
+            let caller = indexer
+                .find_symbols_by_name(caller, None)
+                .into_iter()
+                .find(|s| s.kind == SymbolKind::Function)
+                .unwrap_or_else(|| panic!("{caller} not indexed"));
+            indexer
+                .get_called_functions(caller.id)
+                .into_iter()
+                .map(|s| {
+                    (
+                        s.name.to_string(),
+                        s.module_path.as_deref().map(str::to_string),
+                    )
+                })
+                .collect()
+        };
+        // api declares its own User.Greeting; Account still means models.User
+        assert_eq!(
+            callees("Welcome"),
+            vec![("Greeting".to_string(), Some("models".to_string()))]
+        );
+
+        let describe_alias = indexer
+            .find_symbols_by_name("DescribeAlias", None)
+            .into_iter()
+            .next()
+            .expect("DescribeAlias not indexed");
+        let describe = indexer
+            .get_called_functions(describe_alias.id)
+            .into_iter()
+            .find(|s| s.name.as_ref() == "Describe")
+            .expect("DescribeAlias calls Describe");
+        assert_eq!(
+            describe
+                .signature
+                .as_deref()
+                .and_then(crate::parsing::go::receiver_type_from_signature),
+            Some("InnerStruct")
+        );
+    }
+
+    #[test]
+    fn test_go_cgo_calls_resolve_to_preamble() {
+        let temp_dir = TempDir::new().unwrap();
+        let file = temp_dir.path().join("comprehensive.go");
+        fs::copy("examples/go/comprehensive.go", &file).unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&file).expect("Failed to index file");
+
+        let caller = indexer
+            .find_symbols_by_name("CallExternalFunction", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Function)
+            .expect("CallExternalFunction not indexed");
+        let called: Vec<String> = indexer
+            .get_called_functions(caller.id)
+            .into_iter()
+            .map(|s| s.name.to_string())
+            .collect();
+        assert_eq!(called, vec!["C.external_function"]);
+
+        let external = indexer
+            .find_symbols_by_name("C.external_function", Some("go"))
+            .into_iter()
+            .next()
+            .expect("preamble function not indexed");
+        assert_eq!(
+            external.signature.as_deref(),
+            Some("int external_function(int x)")
+        );
+    }
+
+    #[test]
+    fn test_go_custom_extractor_edges_are_indexed() {
+        use crate::parsing::go::analysis::{
+            GoSourceFile, enclosing_function_name, line_of, walk_tree,
+        };
+        use crate::parsing::go::extractor::{CustomRelationship, Extractor};
+
+        /// Records calls to `fatalf` as `terminates-via`
+        struct FatalExtractor;
+
+        impl Extractor for FatalExtractor {
+            fn name(&self) -> &str {
+                "fatal-calls"
+            }
+
+            fn extract(&self, file: &GoSourceFile) -> Vec<CustomRelationship> {
+                let mut relationships = Vec::new();
+                walk_tree(file.root(), &mut |node| {
+                    if node.kind() == "call_expression"
+                        && node
+                            .child_by_field_name("function")
+                            .is_some_and(|f| file.text(f) == "fatalf")
+                    {
+                        relationships.push(CustomRelationship {
+                            kind: "terminates-via".to_string(),
+                            from: enclosing_function_name(file, node).unwrap_or_default(),
+                            to: "fatalf".to_string(),
+                            file: file.display_path(),
+                            line: line_of(node),
+                        });
+                    }
+                });
+                relationships
+            }
+        }
+
+        let temp_dir = TempDir::new().unwrap();
+        let file = temp_dir.path().join("basic.go");
+        fs::copy("tests/fixtures/go/basic.go", &file).unwrap();
+        let code = fs::read_to_string(&file).unwrap();
+        let row = code
+            .lines()
+            .position(|l| l.contains("fatalf(\"fatal: %v\", err)"))
+            .unwrap() as u32;
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.register_extractor(Box::new(FatalExtractor));
+        indexer.index_file(&file).expect("Failed to index file");
+
+        let exit_with = indexer
+            .find_symbols_by_name("exitWith", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Function)
+            .expect("exitWith not indexed");
+        let related: Vec<(String, Option<u32>)> = indexer
+            .get_custom_related_symbols(exit_with.id, "terminates-via")
+            .into_iter()
+            .map(|(target, metadata)| (target, metadata.line))
+            .collect();
+        assert_eq!(related, vec![("fatalf".to_string(), Some(row))]);
+    }
+
+    #[test]
+    fn test_go_builtin_env_var_edges_are_indexed() {
+        let temp_dir = TempDir::new().unwrap();
+        let file = temp_dir.path().join("settings.go");
+        fs::write(
+            &file,
+            r#"package config
+
+import "os"
+
+const envPrefix = "APP_"
+
+func Load() (string, bool) {
+    port := os.Getenv(envPrefix + "PORT")
+    _, debug := os.LookupEnv("APP_DEBUG")
+    return port, debug
+}
+"#,
+        )
+        .unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&file).expect("Failed to index file");
+
+        let load = indexer
+            .find_symbols_by_name("Load", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Function)
+            .expect("Load not indexed");
+        let mut reads: Vec<(String, Option<u32>)> = indexer
+            .get_custom_related_symbols(load.id, "reads-env-var")
+            .into_iter()
+            .map(|(target, metadata)| (target, metadata.line))
+            .collect();
+        reads.sort();
+        assert_eq!(
+            reads,
+            vec![
+                ("APP_DEBUG".to_string(), Some(8)),
+                ("APP_PORT".to_string(), Some(7)),
+            ]
+        );
+        assert!(
+            indexer
+                .get_custom_related_symbols(load.id, "terminates-via")
+                .is_empty()
+        );
+    }
+
+    #[test]
+    fn test_go_enum_literal_references_constant() {
+        let temp_dir = TempDir::new().unwrap();
+        let models = temp_dir.path().join("models/user.go");
+        let services = temp_dir.path().join("services/auth.go");
+        fs::create_dir_all(models.parent().unwrap()).unwrap();
+        fs::create_dir_all(services.parent().unwrap()).unwrap();
+        fs::write(
+            &models,
+            r#"package models
+
+type UserErrorCode int
+
+const (
+    ErrInvalidName UserErrorCode = iota
+    ErrUserNotFound
+)
+
+type UserError struct {
+    Code UserErrorCode
+}
+"#,
+        )
+        .unwrap();
+        fs::write(
+            &services,
+            r#"package services
+
+import "example.com/app/models"
+
+func Describe(userErr *models.UserError) string {
+    switch userErr.Code {
+    case 1:
+        return "not found"
+    }
+    return ""
+}
+"#,
+        )
+        .unwrap();
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        indexer.index_file(&models).expect("Failed to index models");
+        indexer
+            .index_file(&services)
+            .expect("Failed to index services");
+
+        let constant = indexer
+            .find_symbols_by_name("ErrUserNotFound", None)
+            .into_iter()
+            .find(|s| s.kind == SymbolKind::Constant)
+            .expect("ErrUserNotFound not indexed");
+        let references: Vec<(String, Option<u32>, Option<i64>)> = indexer
+            .get_enum_literal_references(constant.id)
+            .into_iter()
+            .map(|(symbol, metadata)| {
+                (
+                    symbol.name.to_string(),
+                    metadata.line,
+                    metadata.enum_literal(),
+                )
+            })
+            .collect();
+        assert_eq!(references, vec![("Describe".to_string(), Some(6), Some(1))]);
+
+        // Resolving again doesn't duplicate the reference
+        indexer.resolve_go_enum_literals(None).unwrap();
+        assert_eq!(indexer.get_enum_literal_references(constant.id).len(), 1);
+    }
 }
@@ -3,11 +3,14 @@
 //! This module provides efficient directory traversal with support for:
 //! - .gitignore rules
 //! - Custom ignore patterns from configuration
+//! - Include/exclude globs and `vendor/` skipping from `codanna.toml`
+//! - Go build constraints, when build tags are configured
 //! - Language filtering
 //! - Hidden file handling
 
 use crate::Settings;
 use crate::parsing::get_registry;
+use crate::parsing::go::{read_file_header, satisfies_build_tags};
 use ignore::WalkBuilder;
 use std::path::{Path, PathBuf};
 use std::sync::Arc;
@@ -49,6 +52,26 @@ impl FileWalker {
         // One approach would be to create a temporary .codanna-ignore file
         // or use the glob filtering in the iterator below
 
+        // Skip vendored dependencies unless codanna.toml asks for them
+        if !self.settings.project.index_deps {
+            builder.filter_entry(|entry| {
+                !(entry.file_type().is_some_and(|ft| ft.is_dir()) && entry.file_name() == "vendor")
+            });
+        }
+
+        // Include/exclude globs from codanna.toml
+        let file_filter = match self.settings.project.file_filter() {
+            Ok(filter) => Some(filter),
+            Err(e) => {
+                eprintln!("Warning: ignoring include/exclude in codanna.toml: {e}");
+                None
+            }
+        };
+
+        // Build tags from codanna.toml or `index --build-tags`; none means
+        // every Go file is indexed
+        let build_tags = self.settings.project.build_tags.clone();
+
         // Get enabled extensions from the registry
         let enabled_extensions = self.get_enabled_extensions();
 
@@ -69,6 +92,23 @@ impl FileWalker {
                     }
                 }
 
+                if file_filter
+                    .as_ref()
+                    .is_some_and(|filter| !filter.allows(path))
+                {
+                    return None;
+                }
+
+                // Go files whose build constraints the configured tags don't
+                // satisfy are not part of the build
+                if !build_tags.is_empty()
+                    && path.extension().is_some_and(|ext| ext == "go")
+                    && read_file_header(path)
+                        .is_ok_and(|header| !satisfies_build_tags(&header, &build_tags))
+                {
+                    return None;
+                }
+
                 // Check if this file extension is enabled
                 if let Some(extension) = path.extension() {
                     if let Some(ext_str) = extension.to_str() {
@@ -179,4 +219,32 @@ mod tests {
         assert_eq!(files.len(), 1);
         assert!(files[0].ends_with("included.rs"));
     }
+
+    #[test]
+    fn test_go_build_tags_select_files() {
+        let temp_dir = TempDir::new().unwrap();
+        let root = temp_dir.path();
+
+        fs::write(
+            root.join("sys_linux.go"),
+            "//go:build linux && amd64\n\npackage sys\n",
+        )
+        .unwrap();
+        fs::write(root.join("gen.go"), "// +build ignore\n\npackage main\n").unwrap();
+        fs::write(root.join("sys.go"), "package sys\n").unwrap();
+
+        let mut settings = Settings::default();
+        settings.project.build_tags = vec!["linux".to_string(), "amd64".to_string()];
+        let walker = FileWalker::new(Arc::new(settings));
+        let mut files: Vec<_> = walker
+            .walk(root)
+            .filter_map(|p| p.file_name()?.to_str().map(str::to_string))
+            .collect();
+        files.sort();
+        assert_eq!(files, vec!["sys.go", "sys_linux.go"]);
+
+        // Without tags every Go file is indexed
+        let walker = FileWalker::new(Arc::new(Settings::default()));
+        assert_eq!(walker.count_files(root), 3);
+    }
 }
@@ -430,6 +430,8 @@ mod tests {
                 symbol,
                 file_path: format!("src/{name}.rs:11"),
                 relationships: SymbolRelationships::default(),
+                receiver: None,
+                alias_target: None,
             }
         }
 
@@ -477,6 +479,8 @@ mod tests {
             symbol,
             file_path: "src/test.rs:43".to_string(),
             relationships: SymbolRelationships::default(),
+            receiver: None,
+            alias_target: None,
         };
 
         let stdout = Vec::new();
@@ -511,6 +515,8 @@ mod tests {
             symbol,
             file_path: "test.rs:1".to_string(),
             relationships: SymbolRelationships::default(),
+            receiver: None,
+            alias_target: None,
         };
 
         // Test with broken pipe on stdout
@@ -67,6 +67,7 @@ pub enum EntityType {
     Impact,
     IndexInfo,
     Mixed,
+    Finding,
 }
 
 /// Different shapes of output data
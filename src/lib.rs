@@ -14,16 +14,23 @@ macro_rules! debug_print {
     };
 }
 
+pub mod analyze;
 pub mod config;
+pub mod diff_revisions;
 pub mod display;
 pub mod error;
+pub mod export;
+pub mod generate;
+pub mod graph;
 pub mod indexing;
 pub mod init;
 pub mod io;
+pub mod lsp;
 pub mod mcp;
 pub mod parsing;
 pub mod plugins;
 pub mod profiles;
+pub mod project_config;
 pub mod project_resolver;
 pub mod relationship;
 pub mod retrieve;
@@ -32,6 +39,7 @@ pub mod storage;
 pub mod symbol;
 pub mod types;
 pub mod vector;
+pub mod watch;
 
 // Explicit exports for better API clarity
 pub use config::Settings;
@@ -0,0 +1,297 @@
+//! LSP symbol-provider mode
+//!
+//! Answers Language Server Protocol queries from the index so editors can
+//! use codanna as a symbol provider. Results are printed as the JSON payload
+//! of the matching LSP response, ready to be forwarded to the client.
+//!
+//! `workspace-symbols` returns `WorkspaceSymbol[]` for a
+//! `workspace/symbol` request: every top-level symbol whose name contains
+//! the query, case-insensitively. Exact and prefix matches rank first.
+
+use crate::io::ExitCode;
+use crate::parsing::go::receiver_type_from_signature;
+use crate::{ScopeContext, SimpleIndexer, Symbol, SymbolKind};
+use serde::Serialize;
+use std::path::Path;
+
+/// Default number of workspace symbols returned
+pub const DEFAULT_WORKSPACE_SYMBOL_LIMIT: usize = 100;
+
+/// LSP `SymbolKind` values used by codanna symbols
+pub mod lsp_kind {
+    pub const MODULE: u32 = 2;
+    pub const CLASS: u32 = 5;
+    pub const METHOD: u32 = 6;
+    pub const FIELD: u32 = 8;
+    pub const ENUM: u32 = 10;
+    pub const INTERFACE: u32 = 11;
+    pub const FUNCTION: u32 = 12;
+    pub const VARIABLE: u32 = 13;
+    pub const CONSTANT: u32 = 14;
+    pub const STRUCT: u32 = 23;
+}
+
+/// LSP `SymbolKind` of a codanna symbol kind
+///
+/// Traits map to `Interface` and type aliases to `Class`, as gopls and
+/// rust-analyzer report them. Parameters and labels are never workspace
+/// symbols.
+pub fn to_lsp_kind(kind: SymbolKind) -> Option<u32> {
+    Some(match kind {
+        SymbolKind::Function | SymbolKind::Macro => lsp_kind::FUNCTION,
+        SymbolKind::Method => lsp_kind::METHOD,
+        SymbolKind::Struct => lsp_kind::STRUCT,
+        SymbolKind::Enum => lsp_kind::ENUM,
+        SymbolKind::Trait | SymbolKind::Interface => lsp_kind::INTERFACE,
+        SymbolKind::Class | SymbolKind::TypeAlias => lsp_kind::CLASS,
+        SymbolKind::Module => lsp_kind::MODULE,
+        SymbolKind::Variable => lsp_kind::VARIABLE,
+        SymbolKind::Constant => lsp_kind::CONSTANT,
+        SymbolKind::Field => lsp_kind::FIELD,
+        SymbolKind::Parameter | SymbolKind::Label => return None,
+    })
+}
+
+/// Parse a kind filter: an LSP kind number or name such as `struct`
+pub fn parse_lsp_kind(value: &str) -> Option<u32> {
+    if let Ok(number) = value.parse() {
+        return Some(number);
+    }
+    Some(match value.to_ascii_lowercase().as_str() {
+        "module" | "package" => lsp_kind::MODULE,
+        "class" | "type" => lsp_kind::CLASS,
+        "method" => lsp_kind::METHOD,
+        "field" => lsp_kind::FIELD,
+        "enum" => lsp_kind::ENUM,
+        "interface" | "trait" => lsp_kind::INTERFACE,
+        "function" | "func" => lsp_kind::FUNCTION,
+        "variable" | "var" => lsp_kind::VARIABLE,
+        "constant" | "const" => lsp_kind::CONSTANT,
+        "struct" => lsp_kind::STRUCT,
+        _ => return None,
+    })
+}
+
+/// LSP `Position`: 0-based line and character
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+pub struct Position {
+    pub line: u32,
+    pub character: u32,
+}
+
+/// LSP `Range`
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+pub struct LspRange {
+    pub start: Position,
+    pub end: Position,
+}
+
+/// LSP `Location`
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Location {
+    pub uri: String,
+    pub range: LspRange,
+}
+
+/// LSP `WorkspaceSymbol`
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "camelCase")]
+pub struct WorkspaceSymbol {
+    pub name: String,
+    pub kind: u32,
+    pub location: Location,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub container_name: Option<String>,
+}
+
+impl WorkspaceSymbol {
+    fn from_symbol(symbol: &Symbol, kind: u32) -> Self {
+        let range = symbol.range;
+        Self {
+            name: symbol.name.to_string(),
+            kind,
+            location: Location {
+                uri: file_uri(&symbol.file_path),
+                range: LspRange {
+                    start: Position {
+                        line: range.start_line,
+                        character: range.start_column as u32,
+                    },
+                    end: Position {
+                        line: range.end_line,
+                        character: range.end_column as u32,
+                    },
+                },
+            },
+            container_name: container_name(symbol),
+        }
+    }
+}
+
+/// Symbols matching `query`, best matches first
+///
+/// An empty `kinds` accepts every kind. Local variables and parameters are
+/// skipped, as language servers do for workspace symbols.
+pub fn workspace_symbols(
+    symbols: Vec<Symbol>,
+    query: &str,
+    kinds: &[u32],
+    limit: usize,
+) -> Vec<WorkspaceSymbol> {
+    let query = query.to_lowercase();
+    let mut matches: Vec<(u8, Symbol, u32)> = symbols
+        .into_iter()
+        .filter(|s| !matches!(s.scope_context, Some(ScopeContext::Local { .. })))
+        .filter_map(|s| {
+            let kind = to_lsp_kind(s.kind)?;
+            if !kinds.is_empty() && !kinds.contains(&kind) {
+                return None;
+            }
+            let name = s.name.to_lowercase();
+            let rank = if name == query {
+                0
+            } else if name.starts_with(&query) {
+                1
+            } else if name.contains(&query) {
+                2
+            } else {
+                return None;
+            };
+            Some((rank, s, kind))
+        })
+        .collect();
+    matches.sort_by(|(rank_a, a, _), (rank_b, b, _)| {
+        (rank_a, a.name.as_str(), &a.file_path, a.range.start_line).cmp(&(
+            rank_b,
+            b.name.as_str(),
+            &b.file_path,
+            b.range.start_line,
+        ))
+    });
+
+    matches
+        .iter()
+        .take(limit)
+        .map(|(_, symbol, kind)| WorkspaceSymbol::from_symbol(symbol, *kind))
+        .collect()
+}
+
+/// Name of the type or package a symbol belongs to
+///
+/// Go methods belong to their receiver type, other symbols to their module.
+fn container_name(symbol: &Symbol) -> Option<String> {
+    if symbol.kind == SymbolKind::Method {
+        if let Some(receiver) = symbol
+            .signature
+            .as_deref()
+            .and_then(receiver_type_from_signature)
+        {
+            return Some(receiver.to_string());
+        }
+    }
+    symbol.module_path.as_deref().map(str::to_string)
+}
+
+/// `file://` URI of a path, made absolute against the working directory
+fn file_uri(path: &str) -> String {
+    let path = Path::new(path);
+    let absolute = if path.is_absolute() {
+        path.to_path_buf()
+    } else {
+        std::env::current_dir()
+            .map(|dir| dir.join(path))
+            .unwrap_or_else(|_| path.to_path_buf())
+    };
+    let mut uri = String::from("file://");
+    for c in absolute.to_string_lossy().chars() {
+        match c {
+            ' ' => uri.push_str("%20"),
+            '#' => uri.push_str("%23"),
+            '%' => uri.push_str("%25"),
+            '?' => uri.push_str("%3F"),
+            '\\' => uri.push('/'),
+            c => uri.push(c),
+        }
+    }
+    uri
+}
+
+/// Execute lsp workspace-symbols command
+pub fn lsp_workspace_symbols(
+    indexer: &SimpleIndexer,
+    query: &str,
+    kinds: &[u32],
+    limit: usize,
+) -> ExitCode {
+    let symbols = workspace_symbols(indexer.get_all_symbols(), query, kinds, limit);
+    match serde_json::to_string(&symbols) {
+        Ok(json) => {
+            println!("{json}");
+            ExitCode::Success
+        }
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::types::FileId;
+    use crate::{Range, SymbolId};
+
+    fn symbol(id: u32, name: &str, kind: SymbolKind, signature: Option<&str>) -> Symbol {
+        let mut symbol = Symbol::new(
+            SymbolId::new(id).unwrap(),
+            name,
+            kind,
+            FileId::new(1).unwrap(),
+            Range::new(id, 5, id, 20),
+        );
+        symbol.file_path = "/src/models/user.go".into();
+        symbol.module_path = Some("models".into());
+        symbol.signature = signature.map(Into::into);
+        symbol
+    }
+
+    #[test]
+    fn test_workspace_symbols_rank_and_map_kinds() {
+        let symbols = vec![
+            symbol(1, "NewUser", SymbolKind::Function, None),
+            symbol(2, "User", SymbolKind::Struct, None),
+            symbol(
+                3,
+                "Verify",
+                SymbolKind::Method,
+                Some("func (u *User) Verify()"),
+            ),
+            symbol(4, "UserRole", SymbolKind::TypeAlias, None),
+            symbol(5, "user", SymbolKind::Parameter, None),
+        ];
+
+        let found = workspace_symbols(symbols.clone(), "user", &[], 10);
+        let names: Vec<_> = found.iter().map(|s| (s.name.as_str(), s.kind)).collect();
+        assert_eq!(
+            names,
+            vec![
+                ("User", lsp_kind::STRUCT),
+                ("UserRole", lsp_kind::CLASS),
+                ("NewUser", lsp_kind::FUNCTION),
+            ]
+        );
+        assert_eq!(found[0].location.uri, "file:///src/models/user.go");
+        assert_eq!(found[0].location.range.start.line, 2);
+        assert_eq!(found[0].container_name.as_deref(), Some("models"));
+
+        let methods = workspace_symbols(symbols.clone(), "", &[lsp_kind::METHOD], 10);
+        assert_eq!(methods.len(), 1);
+        assert_eq!(methods[0].container_name.as_deref(), Some("User"));
+
+        assert_eq!(workspace_symbols(symbols, "", &[], 2).len(), 2);
+        assert_eq!(parse_lsp_kind("Struct"), Some(lsp_kind::STRUCT));
+        assert_eq!(parse_lsp_kind("6"), Some(lsp_kind::METHOD));
+        assert_eq!(parse_lsp_kind("widget"), None);
+    }
+}
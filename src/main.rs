@@ -4,13 +4,14 @@
 //! Main components: Cli parser, Commands enum, and async runtime with MCP server support.
 
 use clap::{
-    Parser, Subcommand,
+    ArgMatches, CommandFactory, FromArgMatches, Parser, Subcommand,
     builder::styling::{AnsiColor, Effects, Styles},
 };
 use codanna::FileId;
 use codanna::parsing::{
     CSharpParser, GoParser, LanguageParser, PhpParser, PythonParser, RustParser, TypeScriptParser,
 };
+use codanna::project_config::{DefaultOutput, ProjectConfig};
 use codanna::project_resolver::{
     providers::typescript::TypeScriptProvider, registry::SimpleProviderRegistry,
 };
@@ -101,6 +102,9 @@ fn create_custom_help() -> String {
     help.push_str("  remove-dir    Remove a directory from indexed paths\n");
     help.push_str("  list-dirs     List all directories that are being indexed\n");
     help.push_str("  retrieve      Query symbols, relationships, and dependencies\n");
+    help.push_str("  analyze       Run source-level analyses over indexed Go code\n");
+    help.push_str("  diagnostics   Report compile errors and index inconsistencies\n");
+    help.push_str("  watch         Re-index changed files, optionally streaming diagnostics\n");
     help.push_str("  serve         Start MCP server\n");
     help.push_str("  config        Display active settings\n");
     help.push_str("  mcp-test      Test MCP connection\n");
@@ -154,6 +158,10 @@ struct Cli {
     #[arg(long, global = true)]
     info: bool,
 
+    /// Print text even when codanna.toml sets output = "json"
+    #[arg(long, global = true)]
+    no_json: bool,
+
     #[command(subcommand)]
     command: Commands,
 }
@@ -195,6 +203,11 @@ enum Commands {
         /// Maximum number of files to index
         #[arg(long)]
         max_files: Option<usize>,
+
+        /// Go build tags, e.g. "linux,amd64"; files whose build constraints
+        /// they don't satisfy are skipped (overrides codanna.toml)
+        #[arg(long, value_name = "TAGS")]
+        build_tags: Option<String>,
     },
 
     /// Add a directory to the indexed paths list
@@ -219,13 +232,117 @@ enum Commands {
     #[command(
         about = "Search symbols, find callers/callees, analyze impact",
         long_about = "Query indexed symbols, relationships, and dependencies.",
-        after_help = "Examples:\n  codanna retrieve symbol main\n  codanna retrieve callers process_file\n  codanna retrieve callers symbol_id:1771\n  codanna retrieve calls init\n  codanna retrieve calls symbol_id:1771\n  codanna retrieve implementations Parser\n  codanna retrieve describe OutputManager\n  codanna retrieve search \"parse\" --limit 10\n\nJSON paths:\n  retrieve symbol     .data.items[0].symbol.name\n  retrieve search     .data.items[].symbol.name\n  retrieve callers    .data.items[].symbol.name\n  retrieve describe   .data.items[0].symbol.name"
+        after_help = "Examples:\n  codanna retrieve symbol main\n  codanna retrieve callers process_file\n  codanna retrieve callers symbol_id:1771\n  codanna retrieve calls init\n  codanna retrieve calls symbol_id:1771\n  codanna retrieve callees main\n  codanna retrieve range main.go:10-42\n  codanna retrieve mutations userCounter\n  codanna retrieve implementations Parser\n  codanna retrieve methods UserRole\n  codanna retrieve describe OutputManager\n  codanna retrieve search \"parse\" --limit 10\n\nJSON paths:\n  retrieve symbol     .data.items[0].symbol.name\n  retrieve search     .data.items[].symbol.name\n  retrieve callers    .data.items[].symbol.name\n  retrieve describe   .data.items[0].symbol.name"
     )]
     Retrieve {
         #[command(subcommand)]
         query: RetrieveQuery,
     },
 
+    /// Run source-level analyses over indexed code
+    #[command(
+        about = "Run source-level analyses over indexed Go code",
+        long_about = "Analyze the syntax trees of indexed Go files and report findings.",
+        after_help = "Examples:\n  codanna analyze env-vars\n  codanna analyze env-vars --json\n  codanna analyze unwrapped-errors\n  codanna analyze unchecked-errors\n  codanna analyze enum-literals\n  codanna analyze exhaustive\n  codanna analyze nil-receivers\n  codanna analyze broad-interfaces\n  codanna analyze lock-imbalance\n  codanna analyze printf-args\n  codanna analyze interface-pollution\n  codanna analyze channels\n  codanna analyze channel-flow jobQueue\n  codanna analyze missing-docs\n  codanna analyze import-cycles\n  codanna analyze test-coverage --package models\n  codanna analyze noreturn\n  codanna analyze impact models.User\n  codanna analyze method-coverage FileProcessor\n  codanna analyze long-signatures --threshold 5\n\nJSON paths:\n  analyze env-vars            .data.items[].name\n  analyze unwrapped-errors    .data.items[].call\n  analyze unchecked-errors    .data.items[].kind\n  analyze enum-literals       .data.items[].constant\n  analyze exhaustive          .data.items[].missing[]\n  analyze nil-receivers       .data.items[].source\n  analyze broad-interfaces    .data.items[].suggestion\n  analyze lock-imbalance      .data.items[].lock\n  analyze printf-args         .data.items[].call\n  analyze interface-pollution .data.items[].implementor\n  analyze channels            .data.items[].sites[].operation\n  analyze channel-flow        .data.items[].producers[].value_type\n  analyze missing-docs        .data.items[].symbol\n  analyze import-cycles       .data.items[].packages[]\n  analyze test-coverage       .data.items[].untested\n  analyze noreturn            .data.items[].terminator\n  analyze impact              .data.items[].entries[].symbol\n  analyze method-coverage     .data.items[].methods[].interfaces[]\n  analyze long-signatures     .data.items[].parameters"
+    )]
+    Analyze {
+        #[command(subcommand)]
+        query: AnalyzeQuery,
+    },
+
+    /// Report problems that break the build or the index
+    #[command(
+        about = "Report compile errors and index inconsistencies in indexed Go code",
+        long_about = "Check indexed Go files for problems the compiler rejects and the index \
+                      can't represent faithfully.",
+        after_help = "Examples:\n  codanna diagnostics duplicate-definitions\n  codanna diagnostics duplicate-definitions --json\n  codanna diagnostics ambiguous-selectors\n\nJSON paths:\n  diagnostics duplicate-definitions    .data.items[].other_file\n  diagnostics ambiguous-selectors      .data.items[].providers"
+    )]
+    Diagnostics {
+        #[command(subcommand)]
+        query: DiagnosticsQuery,
+    },
+
+    /// List symbols changed between two git revisions
+    #[command(
+        about = "List Go symbols added, removed or changed between two git revisions",
+        long_about = "Diff two git revisions and report which package-level Go declarations \
+                      were added, removed, or had their signature or body changed. File \
+                      contents are read from git, so no checkout or index is needed.",
+        after_help = "Examples:\n  codanna diff-revisions v1.2.0 HEAD\n  codanna diff-revisions main feature --json\n\nJSON paths:\n  diff-revisions    .data.groups[\"signature-changed\"][].name"
+    )]
+    DiffRevisions {
+        /// Old revision (commit, branch or tag)
+        old_rev: String,
+
+        /// New revision (commit, branch or tag)
+        new_rev: String,
+
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Re-index indexed files as they change
+    #[command(
+        about = "Re-index changed files, optionally streaming diagnostics",
+        long_about = "Watch indexed files and re-index them as they change. With --diagnostics, \
+                      print the delta of diagnostics (unresolved references, unused imports, \
+                      shadowing, duplicate definitions, loop variable captures, ambiguous selectors) after each re-index as NDJSON on stdout.",
+        after_help = "Examples:\n  codanna watch\n  codanna watch --diagnostics\n\nEvents (one JSON object per line):\n  {\"event\":\"added\",\"file\":...,\"line\":...,\"column\":...,\"kind\":...,\"message\":...}\n  {\"event\":\"resolved\",...}   diagnostic no longer applies\n  {\"event\":\"cleared\",\"file\":...}   file has no diagnostics left"
+    )]
+    Watch {
+        /// Emit diagnostics deltas as NDJSON after each re-index
+        #[arg(long)]
+        diagnostics: bool,
+    },
+
+    /// Answer Language Server Protocol queries from the index
+    #[command(
+        about = "Answer LSP symbol queries from the index",
+        long_about = "Serve Language Server Protocol queries from the index so editors can use \
+                      codanna as a symbol provider. Each query prints the JSON result of the \
+                      matching LSP request on stdout."
+    )]
+    Lsp {
+        #[command(subcommand)]
+        query: LspQuery,
+    },
+
+    /// Export the index in formats other tools read
+    #[command(
+        about = "Export the index for other tools",
+        long_about = "Write the index in formats read by other tools, such as a classic ctags \
+                      `tags` file for editors without an LSP client, or a JSON manifest of \
+                      the Go API for documentation generators."
+    )]
+    Export {
+        #[command(subcommand)]
+        query: ExportQuery,
+    },
+
+    /// Generate Go source from the index
+    #[command(
+        about = "Generate Go source such as interface method stubs",
+        long_about = "Generate Go source from indexed code and print it on stdout, ready to \
+                      paste into a file."
+    )]
+    Generate {
+        #[command(subcommand)]
+        query: GenerateQuery,
+    },
+
+    /// Render relationships between indexed types and packages as graphs
+    #[command(
+        about = "Render type and package relationships as a tree or Graphviz graph",
+        long_about = "Draw relationships between indexed types and packages, such as the \
+                      composition hierarchy built by struct and interface embedding or the \
+                      imports between packages, as an indented tree or a Graphviz digraph."
+    )]
+    Graph {
+        #[command(subcommand)]
+        query: GraphQuery,
+    },
+
     /// Show current configuration settings
     #[command(about = "Display active settings from .codanna/settings.toml")]
     Config,
@@ -478,9 +595,9 @@ enum PluginAction {
 /// Supports symbol lookups, relationship queries, impact analysis, and full-text search.
 #[derive(Subcommand)]
 enum RetrieveQuery {
-    /// Find a symbol by name
+    /// Find a symbol by name, or a method or field as Type.Name
     #[command(
-        after_help = "Examples:\n  codanna retrieve symbol main\n  codanna retrieve symbol symbol_id:1771\n  codanna retrieve symbol name:main --json\n  codanna retrieve symbol MyStruct --json | jq '.file'"
+        after_help = "Examples:\n  codanna retrieve symbol main\n  codanna retrieve symbol Writer.Close\n  codanna retrieve symbol symbol_id:1771\n  codanna retrieve symbol name:main --json\n  codanna retrieve symbol MyStruct --json | jq '.file'"
     )]
     Symbol {
         /// Positional arguments (symbol name and/or key:value pairs)
@@ -491,123 +608,735 @@ enum RetrieveQuery {
         json: bool,
     },
 
-    /// Show what functions a given function calls
+    /// Show what functions a given function calls
+    #[command(
+        after_help = "Examples:\n  codanna retrieve calls process_file\n  codanna retrieve calls symbol_id:1771\n  codanna retrieve calls function:process_file --json"
+    )]
+    Calls {
+        /// Positional arguments (function name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List every call site inside a function body, in source order
+    #[command(
+        after_help = "Examples:\n  codanna retrieve callees main\n  codanna retrieve callees symbol_id:1771\n  codanna retrieve callees function:main --json"
+    )]
+    Callees {
+        /// Positional arguments (function name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show what functions call a given function
+    #[command(
+        after_help = "Examples:\n  codanna retrieve callers main\n  codanna retrieve callers symbol_id:1771\n  codanna retrieve callers function:main --json"
+    )]
+    Callers {
+        /// Positional arguments (function name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show what types implement a given trait
+    #[command(
+        after_help = "Examples:\n  codanna retrieve implementations Parser\n  codanna retrieve implementations trait:Parser --json"
+    )]
+    Implementations {
+        /// Positional arguments (trait name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the methods declared on a type
+    #[command(
+        after_help = "Methods declared in _test.go files are only part of the method set under\ngo test; they are listed with --include-tests.\n\nExamples:\n  codanna retrieve methods UserRole\n  codanna retrieve methods UserRole --include-tests\n  codanna retrieve methods type:UserRole --json"
+    )]
+    Methods {
+        /// Positional arguments (type name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Also list methods declared in _test.go files
+        #[arg(long)]
+        include_tests: bool,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the types implementing the error interface
+    #[command(
+        about = "List types implementing the error interface (Go)",
+        after_help = "Types are listed with their error-code enum, if any.\n\nExamples:\n  codanna retrieve errors\n  codanna retrieve errors --json | jq '.data.items[].name'"
+    )]
+    Errors {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the constants of an enum type
+    #[command(
+        about = "Show the constants of an enum type, in value order (Go)",
+        after_help = "Enums are types with typed constants, usually numbered with iota.\nQualify the type as package.Type to pick one package.\n\nExamples:\n  codanna retrieve enum UserRole\n  codanna retrieve enum models.UserErrorCode --json | jq '.data.items[].constants[].name'"
+    )]
+    Enum {
+        /// Name of the enum type
+        type_name: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the functions returning a type
+    #[command(
+        about = "Show the functions and methods returning a type (Go)",
+        after_help = "Every result position counts, so (*User, error) returns User. Pointer,\nslice, map and channel forms match unless --exact is given; exact types may\nbe qualified by package.\n\nExamples:\n  codanna retrieve returns User\n  codanna retrieve returns '*models.User' --exact\n  codanna retrieve returns User --json | jq '.data.items[].name'"
+    )]
+    Returns {
+        /// Type to look for, e.g. User or *models.User
+        type_name: String,
+        /// Match the type as written instead of through pointers and containers
+        #[arg(long)]
+        exact: bool,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the functions accepting a type
+    #[command(
+        about = "Show the functions and methods accepting a type as parameter (Go)",
+        after_help = "Pointer, slice, variadic, map and channel forms match unless --exact is\ngiven, as do interface parameters the type satisfies (reported as interface).\n\nExamples:\n  codanna retrieve accepts User\n  codanna retrieve accepts context.Context\n  codanna retrieve accepts '*models.User' --exact --json | jq '.data.items[].name'"
+    )]
+    Accepts {
+        /// Type to look for, e.g. User or *models.User
+        type_name: String,
+        /// Match the type as written instead of through pointers, containers
+        /// and interfaces
+        #[arg(long)]
+        exact: bool,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the conversions to or from a type
+    #[command(
+        about = "List the conversion expressions producing or consuming a type (Go)",
+        after_help = "A call whose callee names a type, such as AuthToken(raw), []byte(s) or\n(*Config)(p), is a conversion. Each entry shows the direction, to when\nit produces the type and from when it consumes it, and the source and\ntarget types; the source type is left as ? when it isn't evident.\nWithout a package qualifier, named types of any package match.\n\nExamples:\n  codanna retrieve conversions AuthToken\n  codanna retrieve conversions '[]byte'\n  codanna retrieve conversions auth.AuthToken --json | jq '.data.items[] | select(.direction == \"from\")'"
+    )]
+    Conversions {
+        /// Type as written, e.g. AuthToken, auth.AuthToken or []byte
+        type_name: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the functions passed to a higher-order function
+    #[command(
+        name = "higher-order-args",
+        about = "Show the functions passed to a higher-order function (Go)",
+        after_help = "Each call site binds a function name, method value, literal or function\nvariable to a function-typed parameter.\n\nExamples:\n  codanna retrieve higher-order-args Filter\n  codanna retrieve higher-order-args processItems --json | jq '.data.items[].argument'"
+    )]
+    HigherOrderArgs {
+        /// Name of the higher-order function or method
+        function: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List where a generic function or type is instantiated
+    #[command(
+        about = "List the instantiations of a generic function or type with their type arguments (Go)",
+        after_help = "Explicit type arguments are listed as written and calls leaving them to\ninference as inferred. A type's instantiations include those of the\ngeneric functions returning it.\n\nExamples:\n  codanna retrieve instantiations NewMap\n  codanna retrieve instantiations GenericContainer --with int\n  codanna retrieve instantiations Sum --json | jq '.data.items[].type_arguments'"
+    )]
+    Instantiations {
+        /// Name of the generic function or type
+        name: String,
+        /// Only instantiations whose type arguments mention this type
+        #[arg(long = "with")]
+        argument: Option<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the writes to a package-level variable
+    #[command(
+        about = "List every assignment and increment of a package-level variable (Go)",
+        after_help = "Plain and compound assignments and ++/-- count, as do writes to an\nelement or field of the variable. Exported variables are also followed\ninto importing packages. Use package.name to pick one package.\n\nExamples:\n  codanna retrieve mutations userCounter\n  codanna retrieve mutations models.DefaultRole --json | jq '.data.items[].function'"
+    )]
+    Mutations {
+        /// Name of the variable, optionally as package.name
+        variable: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the functions using an imported package
+    #[command(
+        name = "import-users",
+        about = "List the functions using symbols of an imported package (Go)",
+        after_help = "Selectors and qualified types through the package name count, per\nfunction, with the symbols referenced. The path matches a full import\npath or its trailing segments.\n\nExamples:\n  codanna retrieve import-users app/models\n  codanna retrieve import-users github.com/google/uuid --json | jq '.data.items[].symbols'"
+    )]
+    ImportUsers {
+        /// Import path, e.g. app/models or example.com/app/models
+        path: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the imports of a file
+    #[command(
+        about = "List the imports of a file, side-effect imports apart (Go)",
+        after_help = "Imports binding a name come first, then blank imports (`import _ \"pkg\"`),\nwhich are kept only for their side effects. A blank import is listed with\nkind `blank` and no name; the index records it with the alias `_`. A blank\nimport of an indexed package lists the init functions it runs. The file\nmatches a full path or its trailing segments.\n\nExamples:\n  codanna retrieve imports --file basic.go\n  codanna retrieve imports --file cmd/server/main.go --json | jq '.data.items[] | select(.kind == \"blank\")'"
+    )]
+    Imports {
+        /// Path of the file, e.g. basic.go or cmd/server/main.go
+        #[arg(long)]
+        file: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the anonymous functions declared in a function or file
+    #[command(
+        name = "anon-funcs",
+        about = "List the anonymous functions of a function or file (Go)",
+        after_help = "Literals are named as the Go compiler names them: Function.func1, and\nFunction.func1.1 for one nested in it. Each shows whether it runs in a\ngoroutine, is deferred, called in place or kept as a value, and the\nvariables of the enclosing functions it captures. A path ending in .go\nlists the literals of every function in that file.\n\nExamples:\n  codanna retrieve anon-funcs CreateRetryFunc\n  codanna retrieve anon-funcs WorkerPool.dispatch\n  codanna retrieve anon-funcs internal/pool/pool.go --json | jq '.data.items[].captures'"
+    )]
+    AnonFuncs {
+        /// Function (Name or Type.Method) or .go file
+        #[arg(value_name = "FUNCTION")]
+        target: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List TODO/FIXME markers with the symbols they belong to
+    #[command(
+        about = "List TODO/FIXME comment markers by symbol (Go)",
+        after_help = "Markers default to TODO, FIXME, XXX and HACK; set [todos] markers in\nsettings.toml or pass --marker to look for others. An author written as\nTODO(name) is reported separately.\n\nExamples:\n  codanna retrieve todos\n  codanna retrieve todos --marker FIXME --marker BUG\n  codanna retrieve todos --json | jq '.data.items[] | select(.author == \"alice\")'"
+    )]
+    Todos {
+        /// Marker to look for instead of the configured ones (repeatable)
+        #[arg(long = "marker", value_name = "MARKER")]
+        markers: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the go:generate directives of the indexed files
+    #[command(
+        about = "List go:generate commands with the symbols they sit above (Go)",
+        after_help = "Each directive shows its command as written, the declaration below it\n(or the function it is in) and its line. --file limits the list to one\nfile, matching a full path or its trailing segments.\n\nExamples:\n  codanna retrieve generators\n  codanna retrieve generators --file comprehensive.go\n  codanna retrieve generators --json | jq -r '.data.items[].command'"
+    )]
+    Generators {
+        /// Path of a file, e.g. basic.go or cmd/server/main.go
+        #[arg(long)]
+        file: Option<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List the definitions and references in a range of lines
+    #[command(
+        about = "List definitions and references within a line range of a file",
+        after_help = "Each entry has a role: definition, call, use, field or jump. Definitions\nthat straddle the range are clipped to it and flagged as clipped. A jump\nis a break, continue or goto naming a label; it points at the label\ndefinition, listed with kind Label. Lines are\n1-based and inclusive; a single line may be given without an end.\n\nExamples:\n  codanna retrieve range src/models/user.go:10-42\n  codanna retrieve range main.go:27\n  codanna retrieve range src/models/user.go:10-42 --json | jq '.data.items[] | select(.role == \"call\")'"
+    )]
+    Range {
+        /// File and lines as <file>:<startLine>-<endLine>
+        spec: String,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Print the source of a symbol's declaration
+    #[command(
+        about = "Print the exact source of a symbol's declaration",
+        after_help = "Functions and methods are printed from their doc comment through the\nclosing brace, types with their full declaration.\n\nExamples:\n  codanna retrieve source NewUser\n  codanna retrieve source symbol_id:1771\n  codanna retrieve source User --json | jq -r '.data.items[0].source'"
+    )]
+    Source {
+        /// Positional arguments (symbol name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show what types a given symbol uses
+    Uses {
+        /// Name of the symbol
+        symbol: String,
+    },
+
+    // DISABLED: Impact command has been deprecated in favor of:
+    // 1. MCP semantic_search_with_context for comprehensive analysis
+    // 2. Slash commands that compose simple retrieve commands
+    // 3. Enhanced describe command (future)
+    //
+    // The impact command had fundamental issues:
+    // - Didn't work for non-function symbols (structs, traits, enums)
+    // - Returned empty results for valid symbols
+    // - Conceptually flawed (not all symbols have "impact" in the same way)
+    //
+    // /// Show the impact radius of changing a symbol
+    // #[command(
+    //     after_help = "Examples:\n  codanna retrieve impact MyStruct\n  codanna retrieve impact symbol:MyStruct depth:3\n  codanna retrieve impact main --depth 2 --json"
+    // )]
+    // Impact {
+    //     /// Positional arguments (symbol name and/or key:value pairs)
+    //     #[arg(num_args = 0..)]
+    //     args: Vec<String>,
+    //     /// Maximum depth to search (flag format)
+    //     #[arg(short, long)]
+    //     depth: Option<usize>,
+    //     /// Output in JSON format
+    //     #[arg(long)]
+    //     json: bool,
+    // },
+    /// Search for symbols using full-text search
+    #[command(
+        after_help = "Examples:\n  # Traditional flag format\n  codanna retrieve search \"parse\" --limit 5 --kind function\n  \n  # Key:value format (Unix-style)\n  codanna retrieve search query:parse limit:5 kind:function\n  \n  # Mixed format\n  codanna retrieve search \"parse\" limit:5 --json"
+    )]
+    Search {
+        /// Positional arguments (query and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+
+        /// Maximum number of results (flag format)
+        #[arg(short, long)]
+        limit: Option<usize>,
+
+        /// Filter by symbol kind (flag format)
+        #[arg(short, long)]
+        kind: Option<String>,
+
+        /// Filter by module path (flag format)
+        #[arg(short, long)]
+        module: Option<String>,
+
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show what methods a type or trait defines
+    Defines {
+        /// Name of the type or trait
+        symbol: String,
+    },
+
+    /// Show dependency analysis for a symbol
+    Dependencies {
+        /// Name of the symbol
+        symbol: String,
+    },
+
+    /// Show information about a symbol
+    #[command(
+        after_help = "Examples:\n  codanna retrieve describe SimpleIndexer\n  codanna retrieve describe symbol:SimpleIndexer --json"
+    )]
+    Describe {
+        /// Positional arguments (symbol name and/or key:value pairs)
+        #[arg(num_args = 0..)]
+        args: Vec<String>,
+        /// Output as JSON
+        #[arg(long)]
+        json: bool,
+    },
+}
+
+/// LSP queries answered from the index.
+#[derive(Subcommand)]
+enum LspQuery {
+    /// Find symbols across the workspace (`workspace/symbol`)
+    #[command(
+        after_help = "Prints a WorkspaceSymbol[] array. Names containing the query match,\ncase-insensitively; exact and prefix matches come first.\n\nExamples:\n  codanna lsp workspace-symbols User\n  codanna lsp workspace-symbols Role --kind struct --kind method\n  codanna lsp workspace-symbols Get --kind 6 --limit 20"
+    )]
+    WorkspaceSymbols {
+        /// Text to match against symbol names; empty matches every symbol
+        #[arg(default_value = "")]
+        query: String,
+        /// Only return these LSP kinds, by name (struct, method, ...) or number
+        #[arg(long = "kind")]
+        kinds: Vec<String>,
+        /// Maximum number of symbols returned
+        #[arg(long, default_value_t = codanna::lsp::DEFAULT_WORKSPACE_SYMBOL_LIMIT)]
+        limit: usize,
+    },
+}
+
+/// Export formats.
+#[derive(Subcommand)]
+enum ExportQuery {
+    /// Write a ctags `tags` file for vim, emacs and other ctags-aware editors
+    #[command(
+        after_help = "Tags use the extended format with `kind:` and `line:` fields. Methods are\nalso tagged as Receiver.Method and struct fields as Struct.field.\n\nExamples:\n  codanna export ctags\n  codanna export ctags --output .git/tags\n  codanna export ctags -o - | grep Process"
+    )]
+    Ctags {
+        /// File to write, or `-` for stdout
+        #[arg(short, long, default_value = codanna::export::DEFAULT_TAGS_FILE)]
+        output: PathBuf,
+    },
+
+    /// Write the Go API as JSON for documentation generators
+    #[command(
+        after_help = "Packages list their types (with fields, methods and the implementors of\ninterfaces), functions, constants and variables, with signatures and doc\ncomments. Lists are sorted and carry no symbol ids, so manifests diff cleanly.\n\nExamples:\n  codanna export manifest > manifest.json\n  codanna export manifest --exported-only -o api.json\n  codanna export manifest | jq '.packages[].types[] | select(.kind == \"interface\")'"
+    )]
+    Manifest {
+        /// Leave out unexported symbols, for public API docs
+        #[arg(long)]
+        exported_only: bool,
+        /// File to write, or `-` for stdout
+        #[arg(short, long, default_value = "-")]
+        output: PathBuf,
+    },
+}
+
+/// Source generators.
+#[derive(Subcommand)]
+enum GenerateQuery {
+    /// Print stubs for the methods a type is missing to implement an interface
+    #[command(
+        after_help = "Methods of embedded interfaces are required too. Stubs use the receiver of\nthe type's existing methods and panic(\"not implemented\") as body.\n\nExamples:\n  codanna generate stub UserStore Store\n  codanna generate stub services.UserStore models.Store >> services/user_store.go"
+    )]
+    Stub {
+        /// Type to complete, optionally qualified by package (services.UserStore)
+        #[arg(value_name = "TYPE")]
+        type_name: String,
+        /// Interface to implement, optionally qualified by package (models.Store)
+        interface: String,
+    },
+}
+
+/// Graphs of type relationships.
+#[derive(Subcommand)]
+enum GraphQuery {
+    /// Draw the hierarchy of embedded structs and interfaces
+    #[command(
+        after_help = "Roots are the types nothing else embeds. A type embedded in several places\nis expanded once and marked (see above) afterwards; branches beyond --depth\nend in an ellipsis. In dot output interface embeddings are dashed.\n\nExamples:\n  codanna graph embeds\n  codanna graph embeds Application --depth 2\n  codanna graph embeds --format dot | dot -Tsvg > embeds.svg"
+    )]
+    Embeds {
+        /// Only draw the hierarchy below this type (Name or package.Name)
+        #[arg(value_name = "TYPE")]
+        root: Option<String>,
+        /// Output format: tree or dot
+        #[arg(long, default_value = "tree")]
+        format: String,
+        /// Maximum number of embedding levels below each root
+        #[arg(long, default_value_t = codanna::graph::DEFAULT_DEPTH)]
+        depth: usize,
+    },
+
+    /// List internal packages with the packages each imports
+    #[command(
+        after_help = "Each package of the module is followed by its direct imports of other\nindexed packages. With --topo packages come in build order, each after\nevery package it imports; an import cycle is reported as an error instead\nof a partial order. Imports into the module that match no indexed package\nare listed as warnings.\n\nExamples:\n  codanna graph packages\n  codanna graph packages --topo\n  codanna graph packages --format dot | dot -Tsvg > packages.svg"
+    )]
+    Packages {
+        /// Order packages so that each comes after its dependencies
+        #[arg(long)]
+        topo: bool,
+        /// Output format: tree or dot
+        #[arg(long, default_value = "tree")]
+        format: String,
+    },
+}
+
+/// Analyses over the syntax trees of indexed files.
+#[derive(Subcommand)]
+enum AnalyzeQuery {
+    /// List environment variables read via os.Getenv/os.LookupEnv
+    #[command(
+        after_help = "Examples:\n  codanna analyze env-vars\n  codanna analyze env-vars --json"
+    )]
+    EnvVars {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Flag `return err` sites that pass a call's error through without wrapping (advisory)
+    #[command(
+        after_help = "Findings are candidates to review, not defects. Suppress one with a\n`// codanna:ignore unwrapped-errors` comment on the return or the line above.\n\nExamples:\n  codanna analyze unwrapped-errors\n  codanna analyze unwrapped-errors --json"
+    )]
+    UnwrappedErrors {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Flag calls whose error result is assigned to _ or ignored (advisory)
+    #[command(
+        after_help = "Reports calls returning an error, by their declared signature or a\nbuilt-in table of standard library functions, whose error is assigned to\n_ or dropped in an expression statement. Set\n`unchecked_errors_allow_blank = true` in codanna.toml to accept errors\nassigned to _. Suppress one with a `// codanna:ignore unchecked-errors`\ncomment on the call line or the line above.\n\nExamples:\n  codanna analyze unchecked-errors\n  codanna analyze unchecked-errors --json | jq '.data.items[] | select(.kind == \"ignored\") | .call'"
+    )]
+    UncheckedErrors {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Match integer literals compared with enum-typed values to enum constants
+    #[command(
+        after_help = "Each finding names the constant the literal stands for, or reports that\nno constant of the enum has its value. Suppress one with a\n`// codanna:ignore enum-literals` comment.\n\nExamples:\n  codanna analyze enum-literals\n  codanna analyze enum-literals --json"
+    )]
+    EnumLiterals {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Find switches over enum-typed values that miss enum constants
+    #[command(
+        after_help = "Switches with a default case are skipped. Suppress one with a\n`// codanna:ignore exhaustive` comment on the switch or the line above.\n\nExamples:\n  codanna analyze exhaustive\n  codanna analyze exhaustive --json"
+    )]
+    Exhaustive {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Find method calls on results of functions that may return nil
+    #[command(
+        after_help = "Advisory: a function returning nil on some path (and no error result)\nis a nil source; the first method call on its result without a nil\ncheck in between is reported. Suppress one with a\n`// codanna:ignore nil-receivers` comment on the call or the line above.\n\nExamples:\n  codanna analyze nil-receivers\n  codanna analyze nil-receivers --json"
+    )]
+    NilReceivers {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Find interface parameters with methods the function never calls
+    #[command(
+        after_help = "Advisory: reports the methods actually called on the parameter (the\nminimal interface) and a known interface with exactly those methods.\nParameters passed on, assigned or returned are skipped. Suppress one\nwith a `// codanna:ignore broad-interfaces` comment on the parameter\nor the line above.\n\nExamples:\n  codanna analyze broad-interfaces\n  codanna analyze broad-interfaces --json"
+    )]
+    BroadInterfaces {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Find locks acquired without a release on every path
+    #[command(
+        after_help = "Advisory: follows each non-deferred Lock/RLock to the returns and the end\nof its function and reports those reached before Unlock/RUnlock. A\n`defer mu.Unlock()` anywhere in the function counts as balanced. Suppress\none with a `// codanna:ignore lock-imbalance` comment on the Lock line or\nthe line above.\n\nExamples:\n  codanna analyze lock-imbalance\n  codanna analyze lock-imbalance --json | jq '.data.items[] | {function, lock, problem}'"
+    )]
+    LockImbalance {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Printf-style calls whose verbs and arguments don't add up
+    #[command(
+        after_help = "Advisory: counts the verbs of literal formats passed to fmt.Printf,\nSprintf, Errorf, Fprintf and Appendf and reports calls passing more or\nfewer arguments. %% is no verb and * takes an argument; formats with\nexplicit indexes (%[1]s) and calls spreading a slice are skipped. Suppress\none with a `// codanna:ignore printf-args` comment on the call line or the\nline above.\n\nExamples:\n  codanna analyze printf-args\n  codanna analyze printf-args --json | jq '.data.items[] | {call, verbs, arguments}'"
+    )]
+    PrintfArgs {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Interfaces only one indexed type implements
+    #[command(
+        after_help = "Advisory: reports declared interfaces with exactly one implementor among\nthe indexed types, which may be abstractions introduced ahead of need.\nInterfaces with no implementor and standard library interfaces are left\nout. Suppress one with a `// codanna:ignore interface-pollution` comment\non the interface line or the line above.\n\nExamples:\n  codanna analyze interface-pollution\n  codanna analyze interface-pollution --json | jq '.data.items[] | {interface, implementor}'"
+    )]
+    InterfacePollution {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Map channels to their send, receive and close sites
+    #[command(
+        after_help = "Lists each channel field, package variable, parameter and local with its\ndeclaration, whether the make call creating it is buffered, and every\nsend (ch <- v), receive (<-ch, range ch) and close(ch) site.\n\nExamples:\n  codanna analyze channels\n  codanna analyze channels --json | jq '.data.items[] | select(.buffered == false) | .name'"
+    )]
+    Channels {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Show the values sent into a channel and where they are received
+    #[command(
+        after_help = "Lists the producers of a channel, each send with the expression sent and\nits type, and its consumers, each receive with the variable the value is\nbound to. A sent value's type is the one evident from the expression, or\nthe channel's element type.\n\nExamples:\n  codanna analyze channel-flow jobQueue\n  codanna analyze channel-flow WorkerPool.jobQueue --json | jq '.data.items[].consumers[].value'"
+    )]
+    ChannelFlow {
+        /// Channel name: Struct.field, a bare field or variable name, or package-qualified
+        channel: String,
+
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// List exported identifiers without a doc comment (advisory)
+    #[command(
+        after_help = "Reports exported types, functions, methods, constants and variables with\nno comment directly above their declaration; a grouped const or var block's\ncomment covers its members. Methods of unexported types and _test.go files\nare skipped, as are methods satisfying an interface unless\n--include-interface-methods is given. Suppress one with a\n`// codanna:ignore missing-docs` comment on the declaration line or the\nline above.\n\nExamples:\n  codanna analyze missing-docs\n  codanna analyze missing-docs --include-interface-methods\n  codanna analyze missing-docs --json | jq '.data.items[] | select(.kind == \"function\") | .symbol'"
+    )]
+    MissingDocs {
+        /// Also report methods that satisfy an interface, such as String()
+        #[arg(long)]
+        include_interface_methods: bool,
+
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Find cycles in the import graph of the indexed packages
+    #[command(
+        after_help = "Builds the graph of imports between the packages of the module and reports\neach cycle as the packages in import order, with the import statement\nclosing each step. Imports into the module that match no indexed package\ncan't close a cycle; they are listed as warnings instead.\n\nExamples:\n  codanna analyze import-cycles\n  codanna analyze import-cycles --json | jq '.data.items[].packages'"
+    )]
+    ImportCycles {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+
+    /// Report which exported symbols tests refer to
     #[command(
-        after_help = "Examples:\n  codanna retrieve calls process_file\n  codanna retrieve calls symbol_id:1771\n  codanna retrieve calls function:process_file --json"
+        after_help = "An exported function, type, constant, variable or method counts as tested\nwhen a _test.go file, or a Test*, Benchmark*, Example* or Fuzz* function,\nrefers to it. This is a static reference check, not line coverage; methods\nare matched by name. Each package shows its tested/untested counts, then\nevery symbol with the tests referring to it.\n\nExamples:\n  codanna analyze test-coverage\n  codanna analyze test-coverage --package models\n  codanna analyze test-coverage --json | jq '.data.items[].symbols[] | select(.tested | not) | .symbol'"
     )]
-    Calls {
-        /// Positional arguments (function name and/or key:value pairs)
-        #[arg(num_args = 0..)]
-        args: Vec<String>,
+    TestCoverage {
+        /// Only report this package (by package name)
+        #[arg(long)]
+        package: Option<String>,
+
         /// Output in JSON format
         #[arg(long)]
         json: bool,
     },
 
-    /// Show what functions call a given function
+    /// List functions that never return (always panic, os.Exit or log.Fatal)
     #[command(
-        after_help = "Examples:\n  codanna retrieve callers main\n  codanna retrieve callers symbol_id:1771\n  codanna retrieve callers function:main --json"
+        after_help = "A function is listed when it has no return and every path ends in panic,\nos.Exit, log.Fatal*, log.Panic* or a call to another function listed here.\nCode after a call to one of them is dead.\n\nExamples:\n  codanna analyze noreturn\n  codanna analyze noreturn --json | jq '.data.items[].terminator'"
     )]
-    Callers {
-        /// Positional arguments (function name and/or key:value pairs)
-        #[arg(num_args = 0..)]
-        args: Vec<String>,
+    Noreturn {
         /// Output in JSON format
         #[arg(long)]
         json: bool,
     },
 
-    /// Show what types implement a given trait
+    /// Estimate what changing a type's definition reaches
     #[command(
-        after_help = "Examples:\n  codanna retrieve implementations Parser\n  codanna retrieve implementations trait:Parser --json"
+        after_help = "Lists the methods on the type, functions taking or returning it and\nstruct fields holding it, then the functions calling those, up to --depth\ncalls away. The summary counts each group; the list below drills down.\n\nExamples:\n  codanna analyze impact User\n  codanna analyze impact models.User --depth 3\n  codanna analyze impact models.User --json | jq '.data.items[0].counts'"
     )]
-    Implementations {
-        /// Positional arguments (trait name and/or key:value pairs)
-        #[arg(num_args = 0..)]
-        args: Vec<String>,
+    Impact {
+        /// Type name, optionally qualified by package (models.User)
+        type_name: String,
+
+        /// Call levels to follow from the direct references
+        #[arg(long, default_value_t = codanna::parsing::go::analysis::impact::DEFAULT_DEPTH)]
+        depth: u32,
+
         /// Output in JSON format
         #[arg(long)]
         json: bool,
     },
 
-    /// Show what types a given symbol uses
-    Uses {
-        /// Name of the symbol
-        symbol: String,
+    /// Map a type's methods to the interfaces they help satisfy
+    #[command(
+        after_help = "For every interface the type implements, declared or well-known standard\nlibrary, each method the interface requires is attributed to it. Methods\nno satisfied interface requires are listed as satisfying none. Only the\nmethods declared on the type are listed.\n\nExamples:\n  codanna analyze method-coverage FileProcessor\n  codanna analyze method-coverage interfaces.FileProcessor --json | jq '.data.items[].methods[] | select(.interfaces == []) | .method'"
+    )]
+    MethodCoverage {
+        /// Type name, optionally qualified by package (models.User)
+        type_name: String,
+
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
     },
 
-    // DISABLED: Impact command has been deprecated in favor of:
-    // 1. MCP semantic_search_with_context for comprehensive analysis
-    // 2. Slash commands that compose simple retrieve commands
-    // 3. Enhanced describe command (future)
-    //
-    // The impact command had fundamental issues:
-    // - Didn't work for non-function symbols (structs, traits, enums)
-    // - Returned empty results for valid symbols
-    // - Conceptually flawed (not all symbols have "impact" in the same way)
-    //
-    // /// Show the impact radius of changing a symbol
-    // #[command(
-    //     after_help = "Examples:\n  codanna retrieve impact MyStruct\n  codanna retrieve impact symbol:MyStruct depth:3\n  codanna retrieve impact main --depth 2 --json"
-    // )]
-    // Impact {
-    //     /// Positional arguments (symbol name and/or key:value pairs)
-    //     #[arg(num_args = 0..)]
-    //     args: Vec<String>,
-    //     /// Maximum depth to search (flag format)
-    //     #[arg(short, long)]
-    //     depth: Option<usize>,
-    //     /// Output in JSON format
-    //     #[arg(long)]
-    //     json: bool,
-    // },
-    /// Search for symbols using full-text search
+    /// Find functions and methods taking many parameters (advisory)
     #[command(
-        after_help = "Examples:\n  # Traditional flag format\n  codanna retrieve search \"parse\" --limit 5 --kind function\n  \n  # Key:value format (Unix-style)\n  codanna retrieve search query:parse limit:5 kind:function\n  \n  # Mixed format\n  codanna retrieve search \"parse\" limit:5 --json"
+        after_help = "Counts value parameters, without the receiver, and type parameters apart;
+`a, b int` is two parameters. A declaration is reported when its value
+parameters exceed the threshold for its kind, or its type parameters
+exceed --type-param-threshold when given. Suppress one with a
+`// codanna:ignore long-signatures` comment on the declaration line or the
+line above.
+
+Examples:
+  codanna analyze long-signatures
+  codanna analyze long-signatures --threshold 4 --method-threshold 3
+  codanna analyze long-signatures --type-param-threshold 2
+  codanna analyze long-signatures --json | jq '.data.items[] | {function, parameters, type_parameters}'"
     )]
-    Search {
-        /// Positional arguments (query and/or key:value pairs)
-        #[arg(num_args = 0..)]
-        args: Vec<String>,
+    LongSignatures {
+        /// Value parameters allowed before a function or method is reported
+        #[arg(long, default_value_t = codanna::parsing::go::analysis::long_signatures::DEFAULT_THRESHOLD)]
+        threshold: usize,
 
-        /// Maximum number of results (flag format)
-        #[arg(short, long)]
-        limit: Option<usize>,
+        /// Value parameters allowed for functions, overriding --threshold
+        #[arg(long)]
+        function_threshold: Option<usize>,
 
-        /// Filter by symbol kind (flag format)
-        #[arg(short, long)]
-        kind: Option<String>,
+        /// Value parameters allowed for methods, overriding --threshold
+        #[arg(long)]
+        method_threshold: Option<usize>,
 
-        /// Filter by module path (flag format)
-        #[arg(short, long)]
-        module: Option<String>,
+        /// Type parameters allowed; not checked unless given
+        #[arg(long)]
+        type_param_threshold: Option<usize>,
 
         /// Output in JSON format
         #[arg(long)]
         json: bool,
     },
+}
 
-    /// Show what methods a type or trait defines
-    Defines {
-        /// Name of the type or trait
-        symbol: String,
+/// Checks for problems the compiler rejects.
+#[derive(Subcommand)]
+enum DiagnosticsQuery {
+    /// Report top-level identifiers declared in more than one file of a package
+    #[command(
+        after_help = "Both declarations are listed. Methods with the same name on different\nreceiver types, `init` functions and files with build constraints are not\nduplicates.\n\nExamples:\n  codanna diagnostics duplicate-definitions\n  codanna diagnostics duplicate-definitions --json"
+    )]
+    DuplicateDefinitions {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
     },
 
-    /// Show dependency analysis for a symbol
-    Dependencies {
-        /// Name of the symbol
-        symbol: String,
+    /// Report goroutines and deferred closures capturing a loop variable
+    #[command(
+        after_help = "Before Go 1.22 a for loop shares its variables across iterations, so a\nclosure run later by `go`, `defer`, errgroup's `Go`, or kept via append or a\nchannel send sees the last value. Modules whose go.mod declares go 1.22 or\nlater are skipped; files without a go.mod are checked.\n\nExamples:\n  codanna diagnostics loop-var-capture\n  codanna diagnostics loop-var-capture --json"
+    )]
+    LoopVarCapture {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
     },
 
-    /// Show information about a symbol
+    /// Report fields and methods promoted from two embedded types at the same depth
     #[command(
-        after_help = "Examples:\n  codanna retrieve describe SimpleIndexer\n  codanna retrieve describe symbol:SimpleIndexer --json"
+        after_help = "Go rejects a use of such a field or method as an ambiguous selector, so\nit is not resolved to either. A field or method declared on the struct\nitself, or promoted from a shallower depth, settles the name.\n\nExamples:\n  codanna diagnostics ambiguous-selectors\n  codanna diagnostics ambiguous-selectors --json"
     )]
-    Describe {
-        /// Positional arguments (symbol name and/or key:value pairs)
-        #[arg(num_args = 0..)]
-        args: Vec<String>,
-        /// Output as JSON
+    AmbiguousSelectors {
+        /// Output in JSON format
         #[arg(long)]
         json: bool,
     },
@@ -867,13 +1596,22 @@ fn add_paths_to_settings(
     Ok((settings, added_paths, skipped_paths))
 }
 
+/// Whether the subcommand given, at any depth, was passed `--json`
+fn json_requested(matches: &ArgMatches) -> bool {
+    matches!(matches.try_get_one::<bool>("json"), Ok(Some(true)))
+        || matches
+            .subcommand()
+            .is_some_and(|(_, matches)| json_requested(matches))
+}
+
 /// Entry point with tokio async runtime.
 ///
 /// Handles config initialization, index loading/creation, and command dispatch.
 /// Auto-initializes config for index command. Persists index after modifications.
 #[tokio::main]
 async fn main() {
-    let cli = Cli::parse();
+    let matches = Cli::command().get_matches();
+    let cli = Cli::from_arg_matches(&matches).unwrap_or_else(|e| e.exit());
 
     // For index command, auto-initialize if needed (but not when using --config)
     if matches!(cli.command, Commands::Index { .. }) && cli.config.is_none() {
@@ -915,6 +1653,25 @@ async fn main() {
         })
     };
 
+    // Project configuration (codanna.toml); CLI flags still take precedence
+    let project_start = config
+        .workspace_root
+        .clone()
+        .or_else(|| std::env::current_dir().ok())
+        .unwrap_or_default();
+    config.project = ProjectConfig::discover(&project_start);
+    if let Commands::Index {
+        build_tags: Some(tags),
+        ..
+    } = &cli.command
+    {
+        config.project.build_tags = codanna::parsing::go::parse_build_tags(tags);
+    }
+    // Output format of the command: --json, then --no-json, then codanna.toml
+    let format = codanna::io::OutputFormat::from_json_flag(
+        json_requested(&matches) || (config.project.output == DefaultOutput::Json && !cli.no_json),
+    );
+
     match &cli.command {
         Commands::Init { force } => {
             let config_path = PathBuf::from(".codanna/settings.toml");
@@ -948,6 +1705,14 @@ async fn main() {
                 Ok(toml_str) => println!("{toml_str}"),
                 Err(e) => eprintln!("Error displaying config: {e}"),
             }
+            if let Some(path) = &config.project.path {
+                println!("Project Configuration ({}):", path.display());
+                println!("{}", "=".repeat(50));
+                match toml::to_string_pretty(&config.project) {
+                    Ok(toml_str) => println!("{toml_str}"),
+                    Err(e) => eprintln!("Error displaying project config: {e}"),
+                }
+            }
             return;
         }
 
@@ -986,6 +1751,17 @@ async fn main() {
         // run_parse_command already calls std::process::exit
     }
 
+    // diff-revisions reads both revisions from git, not from the index
+    if let Commands::DiffRevisions {
+        ref old_rev,
+        ref new_rev,
+        ..
+    } = cli.command
+    {
+        let exit_code = codanna::diff_revisions::diff_revisions(old_rev, new_rev, format);
+        std::process::exit(exit_code as i32);
+    }
+
     // Set up persistence based on config
     // Use global path resolution that handles --config properly
     let index_path = codanna::init::resolve_index_path(&config, cli.config.as_deref());
@@ -1000,6 +1776,7 @@ async fn main() {
         cli.command,
         Commands::McpTest { .. }
             | Commands::Parse { .. }
+            | Commands::DiffRevisions { .. }
             | Commands::Init { .. }
             | Commands::Config
             | Commands::Benchmark { .. }
@@ -1015,6 +1792,7 @@ async fn main() {
             ..
         } | Commands::Index { .. }
             | Commands::Serve { .. }
+            | Commands::Watch { .. }
     );
 
     // Load existing index or create new one (unless we're in thin client mode)
@@ -1835,7 +2613,7 @@ async fn main() {
             use codanna::retrieve;
 
             let exit_code = match query {
-                RetrieveQuery::Symbol { args, json } => {
+                RetrieveQuery::Symbol { args, .. } => {
                     use codanna::io::args::parse_positional_args;
 
                     // Parse positional arguments for symbol name and key:value pairs
@@ -1855,11 +2633,9 @@ async fn main() {
 
                     // Extract language filter
                     let language = params.get("lang").map(|s| s.as_str());
-
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_symbol(&indexer, &final_name, language, format)
                 }
-                RetrieveQuery::Callers { args, json } => {
+                RetrieveQuery::Callers { args, .. } => {
                     use codanna::io::args::parse_positional_args;
 
                     // Parse positional arguments for function name and key:value pairs
@@ -1879,11 +2655,9 @@ async fn main() {
 
                     // Extract language filter
                     let language = params.get("lang").map(|s| s.as_str());
-
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_callers(&indexer, &final_function, language, format)
                 }
-                RetrieveQuery::Calls { args, json } => {
+                RetrieveQuery::Calls { args, .. } => {
                     use codanna::io::args::parse_positional_args;
 
                     // Parse positional arguments for function name and key:value pairs
@@ -1903,11 +2677,31 @@ async fn main() {
 
                     // Extract language filter
                     let language = params.get("lang").map(|s| s.as_str());
-
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_calls(&indexer, &final_function, language, format)
                 }
-                RetrieveQuery::Implementations { args, json } => {
+                RetrieveQuery::Callees { args, .. } => {
+                    use codanna::io::args::parse_positional_args;
+
+                    // Parse positional arguments for function name and key:value pairs
+                    let (positional_function, params) = parse_positional_args(&args);
+
+                    // Determine function name or symbol_id (priority: positional > key:value)
+                    let final_function = positional_function
+                        .or_else(|| params.get("function").cloned())
+                        .or_else(|| params.get("symbol_id").map(|id| format!("symbol_id:{id}")))
+                        .unwrap_or_else(|| {
+                            eprintln!("Error: callees requires a function name or symbol_id");
+                            eprintln!("Usage: codanna retrieve callees main");
+                            eprintln!("   or: codanna retrieve callees function:main");
+                            eprintln!("   or: codanna retrieve callees symbol_id:1771");
+                            std::process::exit(1);
+                        });
+
+                    // Extract language filter
+                    let language = params.get("lang").map(|s| s.as_str());
+                    retrieve::retrieve_callees(&indexer, &final_function, language, format)
+                }
+                RetrieveQuery::Implementations { args, .. } => {
                     use codanna::io::args::parse_positional_args;
 
                     // Parse positional arguments for trait name and key:value pairs
@@ -1925,16 +2719,110 @@ async fn main() {
 
                     // Extract language filter
                     let language = params.get("lang").map(|s| s.as_str());
-
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_implementations(&indexer, &final_trait, language, format)
                 }
+                RetrieveQuery::Methods {
+                    args,
+                    include_tests,
+                    ..
+                } => {
+                    use codanna::io::args::parse_positional_args;
+
+                    // Parse positional arguments for type name and key:value pairs
+                    let (positional_type, params) = parse_positional_args(&args);
+
+                    // Determine type name (priority: positional > key:value)
+                    let final_type = positional_type
+                        .or_else(|| params.get("type").cloned())
+                        .unwrap_or_else(|| {
+                            eprintln!("Error: methods requires a type name");
+                            eprintln!("Usage: codanna retrieve methods UserRole");
+                            eprintln!("   or: codanna retrieve methods type:UserRole");
+                            std::process::exit(1);
+                        });
+
+                    // Extract language filter
+                    let language = params.get("lang").map(|s| s.as_str());
+                    retrieve::retrieve_methods(
+                        &indexer,
+                        &final_type,
+                        language,
+                        include_tests,
+                        format,
+                    )
+                }
+                RetrieveQuery::Source { args, .. } => {
+                    use codanna::io::args::parse_positional_args;
+
+                    // Parse positional arguments for symbol name and key:value pairs
+                    let (positional_name, params) = parse_positional_args(&args);
+
+                    // Determine symbol name or symbol_id (priority: positional > key:value)
+                    let final_name = positional_name
+                        .or_else(|| params.get("name").cloned())
+                        .or_else(|| params.get("symbol_id").map(|id| format!("symbol_id:{id}")))
+                        .unwrap_or_else(|| {
+                            eprintln!("Error: source requires a name or symbol_id");
+                            eprintln!("Usage: codanna retrieve source NewUser");
+                            eprintln!("   or: codanna retrieve source symbol_id:1771");
+                            std::process::exit(1);
+                        });
+
+                    // Extract language filter
+                    let language = params.get("lang").map(|s| s.as_str());
+                    retrieve::retrieve_source(&indexer, &final_name, language, format)
+                }
+                RetrieveQuery::Errors { .. } => retrieve::retrieve_errors(&indexer, format),
+                RetrieveQuery::Enum { type_name, .. } => {
+                    retrieve::retrieve_enum(&indexer, &type_name, format)
+                }
+                RetrieveQuery::Returns {
+                    type_name, exact, ..
+                } => retrieve::retrieve_returns(&indexer, &type_name, exact, format),
+                RetrieveQuery::Accepts {
+                    type_name, exact, ..
+                } => retrieve::retrieve_accepts(&indexer, &type_name, exact, format),
+                RetrieveQuery::Conversions { type_name, .. } => {
+                    retrieve::retrieve_conversions(&indexer, &type_name, format)
+                }
+                RetrieveQuery::HigherOrderArgs { function, .. } => {
+                    retrieve::retrieve_higher_order_args(&indexer, &function, format)
+                }
+                RetrieveQuery::Instantiations { name, argument, .. } => {
+                    retrieve::retrieve_instantiations(&indexer, &name, argument.as_deref(), format)
+                }
+                RetrieveQuery::Mutations { variable, .. } => {
+                    retrieve::retrieve_mutations(&indexer, &variable, format)
+                }
+                RetrieveQuery::ImportUsers { path, .. } => {
+                    retrieve::retrieve_import_users(&indexer, &path, format)
+                }
+                RetrieveQuery::Imports { file, .. } => {
+                    retrieve::retrieve_imports(&indexer, &file, format)
+                }
+                RetrieveQuery::AnonFuncs { target, .. } => {
+                    retrieve::retrieve_anon_funcs(&indexer, &target, format)
+                }
+                RetrieveQuery::Todos { markers, .. } => {
+                    let markers = if markers.is_empty() {
+                        config.todos.markers.clone()
+                    } else {
+                        markers
+                    };
+                    retrieve::retrieve_todos(&indexer, &markers, format)
+                }
+                RetrieveQuery::Generators { file, .. } => {
+                    retrieve::retrieve_generators(&indexer, file.as_deref(), format)
+                }
+                RetrieveQuery::Range { spec, .. } => {
+                    retrieve::retrieve_range(&indexer, &spec, format)
+                }
                 RetrieveQuery::Search {
                     args,
                     limit,
-                    json,
                     kind,
                     module,
+                    ..
                 } => {
                     use codanna::io::args::parse_positional_args;
 
@@ -1968,7 +2856,6 @@ async fn main() {
                     let language = params.get("lang").map(|s| s.as_str());
 
                     // Call retrieve function with merged parameters
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_search(
                         &indexer,
                         &final_query,
@@ -2005,10 +2892,9 @@ async fn main() {
                 //             .unwrap_or(5)
                 //     });
                 //
-                //     let format = OutputFormat::from_json_flag(json);
                 //     retrieve::retrieve_impact(&indexer, &final_symbol, final_depth, format)
                 // }
-                RetrieveQuery::Describe { args, json } => {
+                RetrieveQuery::Describe { args, .. } => {
                     use codanna::io::args::parse_positional_args;
 
                     // Parse positional arguments for symbol name and key:value pairs
@@ -2028,8 +2914,6 @@ async fn main() {
 
                     // Extract language filter
                     let language = params.get("lang").map(|s| s.as_str());
-
-                    let format = OutputFormat::from_json_flag(json);
                     retrieve::retrieve_describe(&indexer, &final_symbol, language, format)
                 }
                 RetrieveQuery::Uses { symbol } => {
@@ -2049,6 +2933,172 @@ async fn main() {
             std::process::exit(exit_code as i32);
         }
 
+        Commands::Analyze { query } => {
+            use codanna::analyze;
+            use codanna::io::OutputFormat;
+
+            let exit_code = match query {
+                AnalyzeQuery::EnvVars { .. } => analyze::analyze_env_vars(&indexer, format),
+                AnalyzeQuery::UnwrappedErrors { .. } => {
+                    analyze::analyze_unwrapped_errors(&indexer, format)
+                }
+                AnalyzeQuery::UncheckedErrors { .. } => analyze::analyze_unchecked_errors(
+                    &indexer,
+                    config.project.unchecked_errors_allow_blank,
+                    format,
+                ),
+                AnalyzeQuery::EnumLiterals { .. } => {
+                    analyze::analyze_enum_literals(&indexer, format)
+                }
+                AnalyzeQuery::Exhaustive { .. } => analyze::analyze_exhaustive(&indexer, format),
+                AnalyzeQuery::NilReceivers { .. } => {
+                    analyze::analyze_nil_receivers(&indexer, format)
+                }
+                AnalyzeQuery::BroadInterfaces { .. } => {
+                    analyze::analyze_broad_interfaces(&indexer, format)
+                }
+                AnalyzeQuery::LockImbalance { .. } => {
+                    analyze::analyze_lock_imbalance(&indexer, format)
+                }
+                AnalyzeQuery::PrintfArgs { .. } => analyze::analyze_printf_args(&indexer, format),
+                AnalyzeQuery::InterfacePollution { .. } => {
+                    analyze::analyze_interface_pollution(&indexer, format)
+                }
+                AnalyzeQuery::Channels { .. } => analyze::analyze_channels(&indexer, format),
+                AnalyzeQuery::ChannelFlow { channel, .. } => {
+                    analyze::analyze_channel_flow(&indexer, &channel, format)
+                }
+                AnalyzeQuery::MissingDocs {
+                    include_interface_methods,
+                    ..
+                } => analyze::analyze_missing_docs(&indexer, include_interface_methods, format),
+                AnalyzeQuery::ImportCycles { .. } => {
+                    analyze::analyze_import_cycles(&indexer, format)
+                }
+                AnalyzeQuery::TestCoverage { package, .. } => {
+                    analyze::analyze_test_coverage(&indexer, package.as_deref(), format)
+                }
+                AnalyzeQuery::Noreturn { .. } => analyze::analyze_noreturn(&indexer, format),
+                AnalyzeQuery::Impact {
+                    type_name, depth, ..
+                } => analyze::analyze_impact(&indexer, &type_name, depth, format),
+                AnalyzeQuery::LongSignatures {
+                    threshold,
+                    function_threshold,
+                    method_threshold,
+                    type_param_threshold,
+                    ..
+                } => analyze::analyze_long_signatures(
+                    &indexer,
+                    codanna::parsing::go::analysis::SignatureThresholds {
+                        function: function_threshold.unwrap_or(threshold),
+                        method: method_threshold.unwrap_or(threshold),
+                        type_parameters: type_param_threshold,
+                    },
+                    format,
+                ),
+                AnalyzeQuery::MethodCoverage { type_name, .. } => {
+                    analyze::analyze_method_coverage(&indexer, &type_name, format)
+                }
+            };
+
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Diagnostics { query } => {
+            use codanna::analyze;
+            use codanna::io::OutputFormat;
+
+            let exit_code = match query {
+                DiagnosticsQuery::DuplicateDefinitions { .. } => {
+                    analyze::diagnose_duplicate_definitions(&indexer, format)
+                }
+                DiagnosticsQuery::LoopVarCapture { .. } => {
+                    analyze::diagnose_loop_var_captures(&indexer, format)
+                }
+                DiagnosticsQuery::AmbiguousSelectors { .. } => {
+                    analyze::diagnose_ambiguous_selectors(&indexer, format)
+                }
+            };
+
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Lsp { query } => {
+            let exit_code = match query {
+                LspQuery::WorkspaceSymbols {
+                    query,
+                    kinds,
+                    limit,
+                } => {
+                    let kinds: Vec<u32> = kinds
+                        .iter()
+                        .map(|kind| {
+                            codanna::lsp::parse_lsp_kind(kind).unwrap_or_else(|| {
+                                eprintln!("Error: unknown symbol kind '{kind}'");
+                                eprintln!("Use an LSP kind name (struct, method, ...) or number");
+                                std::process::exit(1);
+                            })
+                        })
+                        .collect();
+                    codanna::lsp::lsp_workspace_symbols(&indexer, &query, &kinds, limit)
+                }
+            };
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Export { query } => {
+            let exit_code = match query {
+                ExportQuery::Ctags { output } => codanna::export::export_ctags(&indexer, &output),
+                ExportQuery::Manifest {
+                    exported_only,
+                    output,
+                } => codanna::export::export_manifest(&indexer, exported_only, &output),
+            };
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Generate { query } => {
+            let exit_code = match query {
+                GenerateQuery::Stub {
+                    type_name,
+                    interface,
+                } => codanna::generate::generate_stub(&indexer, &type_name, &interface),
+            };
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Graph { query } => {
+            let exit_code = match query {
+                GraphQuery::Embeds {
+                    root,
+                    format,
+                    depth,
+                } => {
+                    let format = codanna::graph::GraphFormat::parse(&format).unwrap_or_else(|| {
+                        eprintln!("Error: unknown graph format '{format}'");
+                        eprintln!("Use tree or dot");
+                        std::process::exit(1);
+                    });
+                    codanna::graph::graph_embeds(&indexer, format, root.as_deref(), depth)
+                }
+                GraphQuery::Packages { topo, format } => {
+                    let format = codanna::graph::GraphFormat::parse(&format).unwrap_or_else(|| {
+                        eprintln!("Error: unknown graph format '{format}'");
+                        eprintln!("Use tree or dot");
+                        std::process::exit(1);
+                    });
+                    codanna::graph::graph_packages(&indexer, format, topo)
+                }
+            };
+            std::process::exit(exit_code as i32);
+        }
+
+        Commands::Watch { diagnostics } => {
+            let exit_code = codanna::watch::watch(indexer, &config, &index_path, diagnostics).await;
+            std::process::exit(exit_code as i32);
+        }
+
         Commands::McpTest {
             server_binary,
             tool,
@@ -2213,6 +3263,8 @@ async fn main() {
                                     symbol,
                                     file_path,
                                     relationships: Default::default(),
+                                    receiver: None,
+                                    alias_target: None,
                                 });
                             }
                         }
@@ -3474,6 +4526,11 @@ async fn main() {
             unreachable!("Parse command should have been handled earlier");
         }
 
+        Commands::DiffRevisions { .. } => {
+            // Already handled with early return above
+            unreachable!("DiffRevisions command should have been handled earlier");
+        }
+
         Commands::Plugin { action } => {
             // Execute plugin management command
             use codanna::plugins;
@@ -0,0 +1,263 @@
+//! Anonymous functions declared within a function
+//!
+//! ```go
+//! func CreateRetryFunc(maxAttempts int, backoff time.Duration) func(JobFunc) JobFunc {
+//!     return func(original JobFunc) JobFunc {
+//!         return func(ctx context.Context) error {
+//!             ... original(ctx) ... maxAttempts ... backoff ...
+//!         }
+//!     }
+//! }
+//! ```
+//!
+//! Literals are named the way the Go compiler names them in stack traces:
+//! `CreateRetryFunc.func1` for the first literal of the function and
+//! `CreateRetryFunc.func1.1` for the first literal nested in it. Each is
+//! reported with how it runs (`go`, `defer`, called in place, or kept as a
+//! value) and the variables of the enclosing functions it captures: the
+//! parameters, receivers and locals declared before it that it refers to,
+//! directly or from a nested literal, without redeclaring them.
+
+use super::{GoSourceFile, declaration_name, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::BTreeSet;
+use std::fmt;
+use tree_sitter::Node;
+
+/// A function literal inside a declared function
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct AnonymousFunction {
+    /// Synthetic name, e.g. `Worker.Start.func1` or `CreateRetryFunc.func1.1`
+    pub name: String,
+    /// Enclosing declared function; methods as `Type.Method`
+    pub function: String,
+    /// How the literal runs: `go`, `defer`, `call` or `value`
+    pub launch: &'static str,
+    /// Variables of the enclosing functions it refers to, sorted
+    pub captures: Vec<String>,
+    pub file: String,
+    /// 1-based line of the `func` keyword
+    pub line: u32,
+    /// 1-based line of the closing brace
+    pub end_line: u32,
+}
+
+impl fmt::Display for AnonymousFunction {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{} ({})", self.name, self.launch)?;
+        if !self.captures.is_empty() {
+            write!(f, " captures {}", self.captures.join(", "))?;
+        }
+        write!(f, " at {}:{}-{}", self.file, self.line, self.end_line)
+    }
+}
+
+/// Find the anonymous functions of a function, or of every function in a
+/// file when `target` is a `.go` path, sorted by file and line
+///
+/// A function matches by name, as `Type.Method` or by its bare method name.
+/// Returns `None` when no function or file matches.
+pub fn find_anonymous_functions(
+    files: &[GoSourceFile],
+    target: &str,
+) -> Option<Vec<AnonymousFunction>> {
+    let by_file = target.ends_with(".go");
+    let mut matched = false;
+    let mut literals = Vec::new();
+    for file in files {
+        let path = file.display_path();
+        if by_file && !(path == target || path.ends_with(&format!("/{target}"))) {
+            continue;
+        }
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let Some(function) = declaration_name(file, decl) else {
+                continue;
+            };
+            let named = function == target
+                || function
+                    .rsplit_once('.')
+                    .is_some_and(|(_, method)| method == target)
+                || file
+                    .package_name()
+                    .is_some_and(|package| format!("{package}.{function}") == target);
+            if !by_file && !named {
+                continue;
+            }
+            matched = true;
+            if let Some(body) = decl.child_by_field_name("body") {
+                collect_literals(file, decl, body, &function, &function, &mut literals);
+            }
+        }
+        matched |= by_file;
+    }
+    if !matched {
+        return None;
+    }
+    literals.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    Some(literals)
+}
+
+/// Record the literals directly within `scope`, then those nested in each
+///
+/// Top-level literals are `prefix.funcN`; nested ones `prefix.N`.
+fn collect_literals(
+    file: &GoSourceFile,
+    decl: Node,
+    scope: Node,
+    function: &str,
+    prefix: &str,
+    literals: &mut Vec<AnonymousFunction>,
+) {
+    let mut direct = Vec::new();
+    let mut stack = vec![scope];
+    while let Some(node) = stack.pop() {
+        for child in node.named_children(&mut node.walk()) {
+            if child.kind() == "func_literal" {
+                direct.push(child);
+            } else {
+                stack.push(child);
+            }
+        }
+    }
+    direct.sort_by_key(|n| n.start_byte());
+
+    let nested = scope.kind() == "func_literal";
+    for (index, literal) in direct.into_iter().enumerate() {
+        let name = if nested {
+            format!("{prefix}.{}", index + 1)
+        } else {
+            format!("{prefix}.func{}", index + 1)
+        };
+        literals.push(AnonymousFunction {
+            name: name.clone(),
+            function: function.to_string(),
+            launch: launch(literal),
+            captures: captures(file, decl, literal),
+            file: file.display_path(),
+            line: line_of(literal),
+            end_line: literal.end_position().row as u32 + 1,
+        });
+        collect_literals(file, decl, literal, function, &name, literals);
+    }
+}
+
+/// How a literal runs: in a `go` or `defer` statement, called in place, or
+/// kept as a value
+fn launch(literal: Node) -> &'static str {
+    let Some(call) = literal
+        .parent()
+        .filter(|p| p.kind() == "call_expression")
+        .filter(|p| p.child_by_field_name("function") == Some(literal))
+    else {
+        return "value";
+    };
+    match call.parent().map(|p| p.kind()) {
+        Some("go_statement") => "go",
+        Some("defer_statement") => "defer",
+        _ => "call",
+    }
+}
+
+/// Variables declared in `decl` before `literal`, outside it, that the
+/// literal refers to without declaring its own
+fn captures(file: &GoSourceFile, decl: Node, literal: Node) -> Vec<String> {
+    let inside = |node: Node| {
+        literal.start_byte() <= node.start_byte() && node.end_byte() <= literal.end_byte()
+    };
+    let mut outer = BTreeSet::new();
+    let mut inner = BTreeSet::new();
+    walk_tree(decl, &mut |node| {
+        for name in declared_names(node) {
+            if inside(name) {
+                inner.insert(file.text(name));
+            } else if name.start_byte() < literal.start_byte() {
+                outer.insert(file.text(name));
+            }
+        }
+    });
+
+    let mut captured = BTreeSet::new();
+    walk_tree(literal, &mut |node| {
+        if node.kind() != "identifier" {
+            return;
+        }
+        let name = file.text(node);
+        if outer.contains(name) && !inner.contains(name) {
+            captured.insert(name.to_string());
+        }
+    });
+    captured.into_iter().collect()
+}
+
+/// Identifiers a parameter, receiver or local declaration introduces
+fn declared_names(node: Node) -> Vec<Node> {
+    match node.kind() {
+        "parameter_declaration" | "variadic_parameter_declaration" | "var_spec" | "const_spec" => {
+            node.children_by_field_name("name", &mut node.walk())
+                .collect()
+        }
+        "short_var_declaration" | "range_clause" | "receive_statement" => node
+            .child_by_field_name("left")
+            .map(|left| {
+                left.named_children(&mut left.walk())
+                    .filter(|n| n.kind() == "identifier")
+                    .collect()
+            })
+            .unwrap_or_default(),
+        "type_switch_statement" => node
+            .child_by_field_name("alias")
+            .map(|alias| alias.named_children(&mut alias.walk()).collect())
+            .unwrap_or_default(),
+        _ => Vec::new(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_anonymous_functions_with_captures() {
+        let file =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/complex.go")).unwrap();
+        let files = [file];
+
+        let retry = find_anonymous_functions(&files, "CreateRetryFunc").unwrap();
+        let summary: Vec<_> = retry
+            .iter()
+            .map(|f| format!("{} {} [{}]", f.name, f.launch, f.captures.join(" ")))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                "CreateRetryFunc.func1 value [backoff maxAttempts]",
+                "CreateRetryFunc.func1.1 value [backoff maxAttempts original]",
+            ]
+        );
+
+        // The goroutine's own `job` parameter shadows the select's `job`
+        let dispatch = find_anonymous_functions(&files, "WorkerPool.dispatch").unwrap();
+        assert_eq!(dispatch.len(), 1);
+        assert_eq!(dispatch[0].launch, "go");
+        assert_eq!(dispatch[0].captures, vec!["wp"]);
+
+        let process = find_anonymous_functions(&files, "Process").unwrap();
+        assert_eq!(process[0].launch, "defer");
+        assert_eq!(process[0].captures, vec!["p", "start"]);
+
+        // Stop is declared on both WorkerPool and Worker
+        let stops = find_anonymous_functions(&files, "Stop").unwrap();
+        assert_eq!(
+            stops.iter().map(|f| f.name.as_str()).collect::<Vec<_>>(),
+            vec!["WorkerPool.Stop.func1", "Worker.Stop.func1"]
+        );
+
+        let all = find_anonymous_functions(&files, "complex.go").unwrap();
+        assert!(all.len() > retry.len() + dispatch.len());
+        assert!(find_anonymous_functions(&files, "NoSuchFunction").is_none());
+    }
+}
@@ -0,0 +1,315 @@
+//! Interface parameters wider than the function needs (advisory)
+//!
+//! "Accept interfaces" works best with small ones. A function that calls
+//! only some methods of its interface parameter could accept a narrower
+//! interface, which more types satisfy and tests can fake more easily:
+//!
+//! ```go
+//! type Reader interface {
+//!     Read(data []byte) (int, error)
+//!     Close() error
+//! }
+//!
+//! func CopyData(src Reader, dst Writer) error // only calls src.Read
+//! ```
+//!
+//! Each finding lists the methods the body calls, which make up the minimal
+//! interface, and names a known interface with exactly those methods when
+//! there is one. Method sets come from the [`InterfaceTable`] of the
+//! analyzed files, with their embeds and the standard library interfaces.
+//! A parameter used other than as a method receiver (passed on, assigned,
+//! returned, compared) may need its full type and is not reported. A
+//! `// codanna:ignore broad-interfaces` comment on the parameter or the line
+//! above suppresses the finding.
+
+use super::{
+    GoSourceFile, InterfaceTable, declaration_name, is_suppressed, line_of, type_key, walk_tree,
+};
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::HashSet;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "broad-interfaces";
+
+/// Standard library interfaces suggested as narrower parameter types
+const STDLIB_SUGGESTIONS: &[&str] = &[
+    "error",
+    "fmt.Stringer",
+    "io.Reader",
+    "io.Writer",
+    "io.Closer",
+    "io.ReadWriter",
+    "io.ReadCloser",
+    "io.WriteCloser",
+    "io.ReadWriteCloser",
+];
+
+/// An interface parameter with methods the function never calls
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct BroadInterfaceParam {
+    pub function: String,
+    pub parameter: String,
+    /// Parameter type as written
+    pub interface: String,
+    /// Methods called on the parameter, with their signatures: the minimal
+    /// interface
+    pub used: Vec<String>,
+    /// Methods of the interface that are never called
+    pub unused: Vec<String>,
+    /// Known interface with exactly the used methods
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub suggestion: Option<String>,
+    pub file: String,
+    /// 1-based line of the parameter
+    pub line: u32,
+}
+
+impl fmt::Display for BroadInterfaceParam {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}({} {}) only needs interface {{ {} }}",
+            self.function,
+            self.parameter,
+            self.interface,
+            self.used.join("; ")
+        )?;
+        if let Some(suggestion) = &self.suggestion {
+            write!(f, " ({suggestion})")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// A known interface other than `key` whose methods are named `names`
+///
+/// Standard library interfaces come first. Only method names are compared,
+/// so the suggestion is a hint rather than a proof.
+fn suggestion(
+    interfaces: &InterfaceTable,
+    key: &str,
+    names: &[&str],
+    package: &str,
+) -> Option<String> {
+    let matches = |candidate: &str| {
+        candidate != key
+            && interfaces.method_set(candidate).is_some_and(|methods| {
+                methods
+                    .iter()
+                    .map(|(name, _)| name.as_str())
+                    .eq(names.iter().copied())
+            })
+    };
+    if let Some(stdlib) = STDLIB_SUGGESTIONS.iter().find(|c| matches(c)) {
+        return Some(stdlib.to_string());
+    }
+    // Declared interfaces come sorted by key
+    let (found, _) = interfaces.declared().find(|(c, _)| matches(c))?;
+    // Interfaces of the function's own package are named unqualified
+    Some(
+        match found
+            .strip_prefix(package)
+            .and_then(|n| n.strip_prefix('.'))
+        {
+            Some(name) => name.to_string(),
+            None => found.to_string(),
+        },
+    )
+}
+
+/// Find interface parameters wider than their function needs, sorted by
+/// file and line
+pub fn find_broad_interface_params(
+    files: &[GoSourceFile],
+    stdlib: StdlibResolution,
+) -> Vec<BroadInterfaceParam> {
+    let interfaces = InterfaceTable::build(files, stdlib);
+    let mut findings = Vec::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |decl| {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                return;
+            }
+            let (Some(params), Some(body), Some(function)) = (
+                decl.child_by_field_name("parameters"),
+                decl.child_by_field_name("body"),
+                declaration_name(file, decl),
+            ) else {
+                return;
+            };
+            for param in params.named_children(&mut params.walk()) {
+                let Some(type_node) = param.child_by_field_name("type") else {
+                    continue;
+                };
+                let Some(key) = type_key(file, type_node, &aliases) else {
+                    continue;
+                };
+                let Some(methods) = interfaces.method_set(&key) else {
+                    continue;
+                };
+                if is_suppressed(file, line_of(param), ANALYSIS_NAME) {
+                    continue;
+                }
+                for name in param.children_by_field_name("name", &mut param.walk()) {
+                    let name = file.text(name);
+                    if name == "_" {
+                        continue;
+                    }
+                    let Some(called) = called_methods(file, body, name) else {
+                        continue;
+                    };
+                    if called.is_empty() || called.len() >= methods.len() {
+                        continue;
+                    }
+                    let (used, unused): (Vec<_>, Vec<_>) = methods
+                        .iter()
+                        .partition(|(method, _)| called.contains(method.as_str()));
+                    if used.len() != called.len() {
+                        // A call to a method the interface lacks: the method
+                        // set is not what we think it is
+                        continue;
+                    }
+                    let used_names: Vec<&str> = used.iter().map(|(n, _)| n.as_str()).collect();
+                    findings.push(BroadInterfaceParam {
+                        function: function.clone(),
+                        parameter: name.to_string(),
+                        interface: file.text(type_node).to_string(),
+                        used: used
+                            .iter()
+                            .map(|(_, signature)| signature.clone())
+                            .collect(),
+                        unused: unused.iter().map(|(method, _)| method.clone()).collect(),
+                        suggestion: suggestion(&interfaces, &key, &used_names, package),
+                        file: file.display_path(),
+                        line: line_of(param),
+                    });
+                }
+            }
+        });
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Methods called on `name` in `body`
+///
+/// Returns `None` when `name` is used any other way, including being
+/// redeclared, since the value may then need its full type.
+fn called_methods<'s>(file: &'s GoSourceFile, body: Node, name: &str) -> Option<HashSet<&'s str>> {
+    let mut called = HashSet::new();
+    let mut escapes = false;
+    walk_tree(body, &mut |node| {
+        if escapes || node.kind() != "identifier" || file.text(node) != name {
+            return;
+        }
+        let method = node
+            .parent()
+            .filter(|parent| parent.kind() == "selector_expression")
+            .filter(|parent| {
+                parent
+                    .child_by_field_name("operand")
+                    .is_some_and(|operand| operand.id() == node.id())
+            })
+            .and_then(|selector| selector.child_by_field_name("field"));
+        match method {
+            Some(method) => {
+                called.insert(file.text(method));
+            }
+            None => escapes = true,
+        }
+    });
+    (!escapes).then_some(called)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_interface_params_wider_than_used() {
+        let code = r#"
+package storage
+
+import "io"
+
+type Reader interface {
+    Read(data []byte) (int, error)
+    Close() error
+}
+
+type Writer interface {
+    Write(data []byte) (int, error)
+}
+
+type ReadWriteCloser interface {
+    Reader
+    Writer
+}
+
+func CopyData(src Reader, dst Writer) error {
+    buffer := make([]byte, 1024)
+    n, _ := src.Read(buffer)
+    _, err := dst.Write(buffer[:n])
+    return err
+}
+
+func Drain(rwc ReadWriteCloser, out io.ReadCloser) {
+    rwc.Write(nil)
+    out.Close()
+}
+
+func Forward(src Reader) {
+    defer src.Close()
+    src.Read(nil)
+}
+
+func Keep(src Reader) Reader {
+    src.Read(nil)
+    return src
+}
+
+// codanna:ignore broad-interfaces
+func Quiet(src Reader) { src.Read(nil) }
+"#;
+        let files = vec![GoSourceFile::parse("storage/copy.go", code.to_string()).unwrap()];
+        let findings = find_broad_interface_params(&files, StdlibResolution::Builtin);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| {
+                (
+                    f.function.as_str(),
+                    f.parameter.as_str(),
+                    f.unused.clone(),
+                    f.suggestion.as_deref(),
+                )
+            })
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (
+                    "CopyData",
+                    "src",
+                    vec!["Close".to_string()],
+                    Some("io.Reader")
+                ),
+                (
+                    "Drain",
+                    "rwc",
+                    vec!["Close".to_string(), "Read".to_string()],
+                    Some("io.Writer")
+                ),
+                ("Drain", "out", vec!["Read".to_string()], Some("io.Closer")),
+            ]
+        );
+        assert_eq!(findings[0].used, vec!["Read(data []byte) (int, error)"]);
+        assert_eq!(findings[0].interface, "Reader");
+        assert_eq!(findings[0].line, 20);
+    }
+}
@@ -0,0 +1,165 @@
+//! What flows through a channel: its producers and consumers
+//!
+//! ```go
+//! func (a *Application) SubmitJob(jobID string, fn JobFunc) error {
+//!     job := Job{ID: jobID, Function: fn}
+//!     a.WorkerPool.jobQueue <- job // producer: job (Job)
+//!     ...
+//! }
+//!
+//! func (wp *WorkerPool) dispatch() {
+//!     select {
+//!     case job := <-wp.jobQueue: // consumer: job (Job)
+//!     ...
+//! }
+//! ```
+//!
+//! Built on [`find_channels`]: the send sites of a channel are its
+//! producers, with the expression sent and its type, and the receive sites
+//! its consumers, with the variable the value is bound to. A sent value's
+//! type is the one evident from the expression when it is a literal or a
+//! typed variable, and the channel's element type otherwise.
+
+use super::channels::element_type;
+use super::{ChannelOperation, ChannelSite, GoSourceFile, find_channels};
+use serde::Serialize;
+use std::fmt;
+
+/// The values sent into a channel and where they are received
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ChannelFlow {
+    /// `Struct.field` for fields, the variable name otherwise
+    pub channel: String,
+    pub package: String,
+    /// Function declaring a local channel
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    /// Element type of the channel, such as `Job` for `chan Job`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub element_type: Option<String>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+    /// Send sites
+    pub producers: Vec<ChannelSite>,
+    /// Receive sites
+    pub consumers: Vec<ChannelSite>,
+}
+
+impl fmt::Display for ChannelFlow {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.channel)?;
+        if let Some(function) = &self.function {
+            write!(f, " (in {function})")?;
+        }
+        if let Some(element_type) = &self.element_type {
+            write!(f, " of {element_type}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)?;
+        for (heading, sites) in [
+            ("producers", &self.producers),
+            ("consumers", &self.consumers),
+        ] {
+            write!(f, "\n  {heading}:")?;
+            if sites.is_empty() {
+                write!(f, " none")?;
+            }
+            for site in sites {
+                write!(f, "\n    {}", site.value.as_deref().unwrap_or("_"))?;
+                if let Some(value_type) = &site.value_type {
+                    write!(f, " ({value_type})")?;
+                }
+                if let Some(function) = &site.function {
+                    write!(f, " in {function}")?;
+                }
+                write!(f, " at {}:{}:{}", site.file, site.line, site.column)?;
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Find the producers and consumers of the channels matching `channel`,
+/// sorted by file and line
+///
+/// A channel matches by name (`WorkerPool.jobQueue` or a variable name),
+/// by its bare field name, or prefixed with its package.
+pub fn find_channel_flow(files: &[GoSourceFile], channel: &str) -> Vec<ChannelFlow> {
+    find_channels(files)
+        .into_iter()
+        .filter(|usage| {
+            usage.name == channel
+                || usage
+                    .name
+                    .rsplit_once('.')
+                    .is_some_and(|(_, field)| field == channel)
+                || format!("{}.{}", usage.package, usage.name) == channel
+        })
+        .map(|usage| {
+            let (producers, consumers) = usage
+                .sites
+                .into_iter()
+                .filter(|site| site.operation != ChannelOperation::Close)
+                .partition(|site| site.operation == ChannelOperation::Send);
+            ChannelFlow {
+                channel: usage.name,
+                package: usage.package,
+                function: usage.function,
+                element_type: usage
+                    .channel_type
+                    .as_deref()
+                    .and_then(element_type)
+                    .map(str::to_string),
+                file: usage.file,
+                line: usage.line,
+                producers,
+                consumers,
+            }
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_job_queue_producers_and_consumers() {
+        let file =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/complex.go")).unwrap();
+        let files = [file];
+
+        let flows = find_channel_flow(&files, "jobQueue");
+        assert_eq!(flows.len(), 1);
+        let job_queue = &flows[0];
+        assert_eq!(job_queue.channel, "WorkerPool.jobQueue");
+        assert_eq!(job_queue.element_type.as_deref(), Some("Job"));
+
+        let producers: Vec<_> = job_queue
+            .producers
+            .iter()
+            .map(|s| (s.value.as_deref(), s.value_type.as_deref(), s.line))
+            .collect();
+        assert_eq!(producers, vec![(Some("job"), Some("Job"), 162)]);
+        let consumers: Vec<_> = job_queue
+            .consumers
+            .iter()
+            .map(|s| (s.value.as_deref(), s.function.as_deref(), s.line))
+            .collect();
+        assert_eq!(
+            consumers,
+            vec![(Some("job"), Some("WorkerPool.dispatch"), 259)]
+        );
+        assert!(
+            job_queue
+                .to_string()
+                .contains("\n  producers:\n    job (Job) in Application.SubmitJob at ")
+        );
+
+        // Receiving from a channel of channels binds the worker's channel
+        let workers = &find_channel_flow(&files, "complex.WorkerPool.workers")[0];
+        assert_eq!(workers.element_type.as_deref(), Some("chan Job"));
+        assert_eq!(workers.consumers[0].value.as_deref(), Some("jobChannel"));
+        assert!(find_channel_flow(&files, "noSuchChannel").is_empty());
+    }
+}
@@ -0,0 +1,860 @@
+//! Channels and the places they are sent on and received from
+//!
+//! A concurrency map of the program: each declared channel, whether struct
+//! field, package variable, parameter or local, with its send, receive and
+//! close sites:
+//!
+//! ```go
+//! type WorkerPool struct {
+//!     jobQueue chan Job
+//! }
+//!
+//! func NewWorkerPool(n int) *WorkerPool {
+//!     return &WorkerPool{jobQueue: make(chan Job, n*2)} // buffered
+//! }
+//!
+//! func (wp *WorkerPool) dispatch() {
+//!     job := <-wp.jobQueue // receive
+//!     ...
+//! }
+//! ```
+//!
+//! `ch <- v` sends, `<-ch` and `for v := range ch` receive, `close(ch)`
+//! closes. Sends record the expression sent and receives the variable
+//! bound to the value, each with its type. Whether a channel is buffered
+//! comes from the `make` call creating it, in its declaration, a keyed
+//! struct literal or an assignment. Field
+//! selectors are matched to the struct declaring a channel field of that
+//! name; when several do, the receiver, parameter or embedded field type of
+//! the operand decides. Locals received from a channel of channels
+//! (`ch := <-wp.workers`) are channels too. Channels created and used in
+//! expressions only, like `time.After(...)`, are not tracked.
+
+use super::{GoSourceFile, base_type_name, enclosing_function_name, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Where a channel is declared
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum ChannelScope {
+    /// Field of a struct
+    Field,
+    /// Package-level variable
+    Package,
+    /// Parameter or local variable of a function
+    Local,
+}
+
+/// What a site does with a channel
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum ChannelOperation {
+    Send,
+    Receive,
+    Close,
+}
+
+impl fmt::Display for ChannelOperation {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(match self {
+            ChannelOperation::Send => "send",
+            ChannelOperation::Receive => "receive",
+            ChannelOperation::Close => "close",
+        })
+    }
+}
+
+/// One use of a channel
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ChannelSite {
+    pub operation: ChannelOperation,
+    /// Function or method containing the site
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the site
+    pub line: u32,
+    /// 1-based column of the site
+    pub column: u32,
+    /// Expression sent, or the variable a received value is bound to
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub value: Option<String>,
+    /// Type of the value sent or received: the sent expression's own type
+    /// when evident, the channel's element type otherwise
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub value_type: Option<String>,
+}
+
+impl fmt::Display for ChannelSite {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{:<8}", self.operation.to_string())?;
+        if let Some(function) = &self.function {
+            write!(f, "{function} ")?;
+        }
+        write!(f, "at {}:{}:{}", self.file, self.line, self.column)
+    }
+}
+
+/// A declared channel with its sites
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ChannelUsage {
+    /// `Struct.field` for fields, the variable name otherwise
+    pub name: String,
+    pub scope: ChannelScope,
+    pub package: String,
+    /// Function declaring a local channel
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    /// Channel type, such as `chan Job` or `<-chan error`; unknown for
+    /// locals received from another channel without a declared type
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub channel_type: Option<String>,
+    /// Whether the `make` call creating it gives a capacity; unknown when no
+    /// `make` call was found
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub buffered: Option<bool>,
+    /// Capacity expression of the `make` call
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub capacity: Option<String>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+    pub sites: Vec<ChannelSite>,
+}
+
+impl fmt::Display for ChannelUsage {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.name)?;
+        if let Some(function) = &self.function {
+            write!(f, " (in {function})")?;
+        }
+        if let Some(channel_type) = &self.channel_type {
+            write!(f, " {channel_type}")?;
+        }
+        match (self.buffered, &self.capacity) {
+            (Some(true), Some(capacity)) => write!(f, ", buffered ({capacity})")?,
+            (Some(true), None) => write!(f, ", buffered")?,
+            (Some(false), _) => write!(f, ", unbuffered")?,
+            (None, _) => {}
+        }
+        write!(f, " at {}:{}", self.file, self.line)?;
+        if self.sites.is_empty() {
+            write!(f, "\n  no sends or receives")?;
+        }
+        for site in &self.sites {
+            write!(f, "\n  {site}")?;
+        }
+        Ok(())
+    }
+}
+
+/// Key of a declared channel
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+enum ChannelKey {
+    /// (package, struct, field)
+    Field(String, String, String),
+    /// (package, name)
+    Package(String, String),
+    /// (file index, start byte of the declaring function, name)
+    Local(usize, usize, String),
+}
+
+/// Declared channels, in declaration order
+#[derive(Default)]
+struct ChannelTable {
+    usages: Vec<ChannelUsage>,
+    index: HashMap<ChannelKey, usize>,
+}
+
+impl ChannelTable {
+    fn declare(&mut self, key: ChannelKey, usage: ChannelUsage) -> usize {
+        if let Some(&existing) = self.index.get(&key) {
+            return existing;
+        }
+        self.usages.push(usage);
+        self.index.insert(key, self.usages.len() - 1);
+        self.usages.len() - 1
+    }
+
+    fn get(&self, key: &ChannelKey) -> Option<usize> {
+        self.index.get(key).copied()
+    }
+
+    /// Structs of `package` declaring a channel field named `field`
+    fn field_owners(&self, package: &str, field: &str) -> Vec<&str> {
+        self.index
+            .keys()
+            .filter_map(|key| match key {
+                ChannelKey::Field(p, owner, f) if p == package && f == field => {
+                    Some(owner.as_str())
+                }
+                _ => None,
+            })
+            .collect()
+    }
+
+    fn set_make(&mut self, slot: usize, make: MakeCall) {
+        let usage = &mut self.usages[slot];
+        if usage.buffered.is_none() {
+            usage.buffered = Some(make.buffered);
+            usage.capacity = make.capacity;
+        }
+        if usage.channel_type.is_none() {
+            usage.channel_type = Some(make.channel_type);
+        }
+    }
+}
+
+/// A `make(chan T, n)` call
+struct MakeCall {
+    channel_type: String,
+    buffered: bool,
+    capacity: Option<String>,
+}
+
+/// Find the declared channels with their sites, sorted by file and line
+pub fn find_channels(files: &[GoSourceFile]) -> Vec<ChannelUsage> {
+    let mut table = ChannelTable::default();
+    for (index, file) in files.iter().enumerate() {
+        declare_channels(&mut table, index, file);
+    }
+    for (index, file) in files.iter().enumerate() {
+        record_makes(&mut table, index, file);
+    }
+    for (index, file) in files.iter().enumerate() {
+        record_sites(&mut table, index, file);
+    }
+
+    let mut usages = table.usages;
+    for usage in &mut usages {
+        usage
+            .sites
+            .sort_by(|a, b| (&a.file, a.line, a.column).cmp(&(&b.file, b.line, b.column)));
+    }
+    usages.sort_by(|a, b| (&a.file, a.line, &a.name).cmp(&(&b.file, b.line, &b.name)));
+    usages
+}
+
+/// The `make(chan T, n)` call `node` is, if any
+fn make_call(file: &GoSourceFile, node: Node) -> Option<MakeCall> {
+    if node.kind() != "call_expression" {
+        return None;
+    }
+    let function = node.child_by_field_name("function")?;
+    if function.kind() != "identifier" || file.text(function) != "make" {
+        return None;
+    }
+    let arguments = node.child_by_field_name("arguments")?;
+    let channel_type = arguments.named_child(0)?;
+    if channel_type.kind() != "channel_type" {
+        return None;
+    }
+    let capacity = arguments.named_child(1).map(|n| file.text(n).to_string());
+    Some(MakeCall {
+        channel_type: file.text(channel_type).to_string(),
+        buffered: capacity.as_deref().is_some_and(|c| c != "0"),
+        capacity,
+    })
+}
+
+/// Top-level function or method declaration containing `node`
+fn enclosing_declaration(node: Node) -> Option<Node> {
+    let mut current = Some(node);
+    while let Some(n) = current {
+        if matches!(n.kind(), "function_declaration" | "method_declaration") {
+            return Some(n);
+        }
+        current = n.parent();
+    }
+    None
+}
+
+fn declare_channels(table: &mut ChannelTable, index: usize, file: &GoSourceFile) {
+    let package = file.package_name().unwrap_or_default().to_string();
+    let usage = |name: String,
+                 scope: ChannelScope,
+                 function: Option<String>,
+                 channel_type: Option<String>,
+                 line: u32| ChannelUsage {
+        name,
+        scope,
+        package: package.clone(),
+        function,
+        channel_type,
+        buffered: None,
+        capacity: None,
+        file: file.display_path(),
+        line,
+        sites: Vec::new(),
+    };
+
+    walk_tree(file.root(), &mut |node| match node.kind() {
+        "type_spec" => {
+            let (Some(name), Some(body)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type"),
+            ) else {
+                return;
+            };
+            if body.kind() != "struct_type" {
+                return;
+            }
+            let owner = file.text(name);
+            walk_tree(body, &mut |field| {
+                if field.kind() != "field_declaration" {
+                    return;
+                }
+                let Some(field_type) = field
+                    .child_by_field_name("type")
+                    .filter(|t| t.kind() == "channel_type")
+                else {
+                    return;
+                };
+                for field_name in field.children_by_field_name("name", &mut field.walk()) {
+                    let field_name = file.text(field_name);
+                    table.declare(
+                        ChannelKey::Field(
+                            package.clone(),
+                            owner.to_string(),
+                            field_name.to_string(),
+                        ),
+                        usage(
+                            format!("{owner}.{field_name}"),
+                            ChannelScope::Field,
+                            None,
+                            Some(file.text(field_type).to_string()),
+                            line_of(field),
+                        ),
+                    );
+                }
+            });
+        }
+        "var_spec" | "parameter_declaration" | "variadic_parameter_declaration" => {
+            let declared_type = node
+                .child_by_field_name("type")
+                .filter(|t| t.kind() == "channel_type");
+            let values: Vec<Node> = node
+                .child_by_field_name("value")
+                .map(|v| v.named_children(&mut v.walk()).collect())
+                .unwrap_or_default();
+            let declaration = enclosing_declaration(node);
+            for (i, name) in node
+                .children_by_field_name("name", &mut node.walk())
+                .enumerate()
+            {
+                let make = values.get(i).and_then(|v| make_call(file, *v));
+                if declared_type.is_none() && make.is_none() {
+                    continue;
+                }
+                let name = file.text(name).to_string();
+                let channel_type = declared_type.map(|t| file.text(t).to_string());
+                let slot = match declaration {
+                    Some(declaration) => table.declare(
+                        ChannelKey::Local(index, declaration.start_byte(), name.clone()),
+                        usage(
+                            name,
+                            ChannelScope::Local,
+                            enclosing_function_name(file, node),
+                            channel_type,
+                            line_of(node),
+                        ),
+                    ),
+                    None => table.declare(
+                        ChannelKey::Package(package.clone(), name.clone()),
+                        usage(
+                            name,
+                            ChannelScope::Package,
+                            None,
+                            channel_type,
+                            line_of(node),
+                        ),
+                    ),
+                };
+                if let Some(make) = make {
+                    table.set_make(slot, make);
+                }
+            }
+        }
+        "short_var_declaration" => {
+            let (Some(left), Some(right), Some(declaration)) = (
+                node.child_by_field_name("left"),
+                node.child_by_field_name("right"),
+                enclosing_declaration(node),
+            ) else {
+                return;
+            };
+            let values: Vec<Node> = right.named_children(&mut right.walk()).collect();
+            for (i, name) in left.named_children(&mut left.walk()).enumerate() {
+                let Some(value) = values.get(i) else {
+                    continue;
+                };
+                let make = make_call(file, *value);
+                if make.is_none() && !is_receive(*value) {
+                    continue;
+                }
+                // A receive only declares a channel when it reads from a
+                // channel of channels, which is checked once sites resolve
+                let name = file.text(name).to_string();
+                let key = ChannelKey::Local(index, declaration.start_byte(), name.clone());
+                match make {
+                    Some(make) => {
+                        let slot = table.declare(
+                            key,
+                            usage(
+                                name,
+                                ChannelScope::Local,
+                                enclosing_function_name(file, node),
+                                None,
+                                line_of(node),
+                            ),
+                        );
+                        table.set_make(slot, make);
+                    }
+                    None => {
+                        let Some(source) = value.child_by_field_name("operand") else {
+                            continue;
+                        };
+                        let nested = resolve_channel(table, index, file, source)
+                            .and_then(|slot| table.usages[slot].channel_type.clone())
+                            .and_then(|t| element_type(&t).map(str::to_string))
+                            .filter(|element| element.contains("chan"));
+                        if let Some(nested) = nested {
+                            table.declare(
+                                key,
+                                usage(
+                                    name,
+                                    ChannelScope::Local,
+                                    enclosing_function_name(file, node),
+                                    Some(nested),
+                                    line_of(node),
+                                ),
+                            );
+                        }
+                    }
+                }
+            }
+        }
+        _ => {}
+    });
+}
+
+/// Buffering of field and variable channels made outside their declaration
+fn record_makes(table: &mut ChannelTable, index: usize, file: &GoSourceFile) {
+    let package = file.package_name().unwrap_or_default().to_string();
+    walk_tree(file.root(), &mut |node| match node.kind() {
+        // `&WorkerPool{jobQueue: make(chan Job, n)}`
+        "keyed_element" => {
+            let (Some(key), Some(value)) = (
+                node.child_by_field_name("key"),
+                node.child_by_field_name("value"),
+            ) else {
+                return;
+            };
+            let Some(make) = make_call(file, unwrap_literal_element(value)) else {
+                return;
+            };
+            let Some(owner) = node
+                .parent()
+                .and_then(|body| body.parent())
+                .filter(|literal| literal.kind() == "composite_literal")
+                .and_then(|literal| literal.child_by_field_name("type"))
+                .and_then(|t| base_type_name(file, t))
+            else {
+                return;
+            };
+            let field = file.text(unwrap_literal_element(key));
+            let key = ChannelKey::Field(package.clone(), owner.to_string(), field.to_string());
+            if let Some(slot) = table.get(&key) {
+                table.set_make(slot, make);
+            }
+        }
+        // `wp.quit = make(chan bool)` or `done = make(chan struct{})`
+        "assignment_statement" => {
+            let (Some(left), Some(right)) = (
+                node.child_by_field_name("left"),
+                node.child_by_field_name("right"),
+            ) else {
+                return;
+            };
+            let values: Vec<Node> = right.named_children(&mut right.walk()).collect();
+            for (i, target) in left.named_children(&mut left.walk()).enumerate() {
+                let Some(make) = values.get(i).and_then(|v| make_call(file, *v)) else {
+                    continue;
+                };
+                if let Some(slot) = resolve_channel(table, index, file, target) {
+                    table.set_make(slot, make);
+                }
+            }
+        }
+        _ => {}
+    });
+}
+
+/// Value or key of a keyed element, without its `literal_element` wrapper
+fn unwrap_literal_element(node: Node) -> Node {
+    if node.kind() == "literal_element" {
+        node.named_child(0).unwrap_or(node)
+    } else {
+        node
+    }
+}
+
+fn record_sites(table: &mut ChannelTable, index: usize, file: &GoSourceFile) {
+    walk_tree(file.root(), &mut |node| {
+        let (operation, channel) = match node.kind() {
+            "send_statement" => (ChannelOperation::Send, node.child_by_field_name("channel")),
+            "unary_expression" if is_receive(node) => (
+                ChannelOperation::Receive,
+                node.child_by_field_name("operand"),
+            ),
+            "range_clause" => (ChannelOperation::Receive, node.child_by_field_name("right")),
+            "call_expression" => {
+                let is_close = node
+                    .child_by_field_name("function")
+                    .is_some_and(|f| f.kind() == "identifier" && file.text(f) == "close");
+                if !is_close {
+                    return;
+                }
+                (
+                    ChannelOperation::Close,
+                    node.child_by_field_name("arguments")
+                        .and_then(|a| a.named_child(0)),
+                )
+            }
+            _ => return,
+        };
+        let Some(channel) = channel else {
+            return;
+        };
+        let Some(slot) = resolve_channel(table, index, file, channel) else {
+            return;
+        };
+        let element = table.usages[slot]
+            .channel_type
+            .as_deref()
+            .and_then(element_type)
+            .map(str::to_string);
+        let (value, value_type) = match operation {
+            ChannelOperation::Send => {
+                let sent = node.child_by_field_name("value");
+                (
+                    sent.map(|v| file.text(v).to_string()),
+                    sent.and_then(|v| evident_type(file, v)).or(element),
+                )
+            }
+            ChannelOperation::Receive => (receive_binding(file, node), element),
+            ChannelOperation::Close => (None, None),
+        };
+        let position = node.start_position();
+        table.usages[slot].sites.push(ChannelSite {
+            operation,
+            function: enclosing_function_name(file, node),
+            file: file.display_path(),
+            line: line_of(node),
+            column: position.column as u32 + 1,
+            value,
+            value_type,
+        });
+    });
+}
+
+/// Variable a receive binds its value to: `job` in `job := <-ch`,
+/// `case job := <-ch:` and `for job := range ch`
+///
+/// `None` when the value is discarded or used in place.
+fn receive_binding(file: &GoSourceFile, receive: Node) -> Option<String> {
+    let (left, position) = if receive.kind() == "range_clause" {
+        (receive.child_by_field_name("left")?, 0)
+    } else {
+        let mut value = receive;
+        while let Some(parent) = value
+            .parent()
+            .filter(|p| p.kind() == "parenthesized_expression")
+        {
+            value = parent;
+        }
+        let parent = value.parent()?;
+        match parent.kind() {
+            "receive_statement" => (parent.child_by_field_name("left")?, 0),
+            "expression_list" => {
+                let statement = parent
+                    .parent()
+                    .filter(|s| {
+                        matches!(s.kind(), "short_var_declaration" | "assignment_statement")
+                    })
+                    .filter(|s| s.child_by_field_name("right") == Some(parent))?;
+                let position = parent
+                    .named_children(&mut parent.walk())
+                    .position(|n| n == value)?;
+                (statement.child_by_field_name("left")?, position)
+            }
+            _ => return None,
+        }
+    };
+    left.named_child(position)
+        .map(|target| file.text(target))
+        .filter(|name| *name != "_")
+        .map(str::to_string)
+}
+
+/// Type of a sent expression, when evident: a composite literal, the
+/// address of one, or a variable declared with a type or from a literal
+fn evident_type(file: &GoSourceFile, expression: Node) -> Option<String> {
+    match expression.kind() {
+        "composite_literal" => Some(
+            file.text(expression.child_by_field_name("type")?)
+                .to_string(),
+        ),
+        "parenthesized_expression" => evident_type(file, expression.named_child(0)?),
+        "unary_expression" => {
+            let operator = expression.child_by_field_name("operator")?;
+            let operand = expression.child_by_field_name("operand")?;
+            if operator.kind() != "&" || operand.kind() != "composite_literal" {
+                return None;
+            }
+            evident_type(file, operand).map(|t| format!("*{t}"))
+        }
+        "identifier" => declared_type(file, expression),
+        _ => None,
+    }
+}
+
+/// Type of the latest declaration of an identifier before its use, in the
+/// enclosing function: a typed parameter or variable, or a variable
+/// initialized from a literal
+fn declared_type(file: &GoSourceFile, identifier: Node) -> Option<String> {
+    let name = file.text(identifier);
+    let declaration = enclosing_declaration(identifier)?;
+    let mut latest = None;
+    walk_tree(declaration, &mut |node| {
+        if node.start_byte() >= identifier.start_byte() {
+            return;
+        }
+        let (names, values): (Vec<Node>, Vec<Node>) = match node.kind() {
+            "parameter_declaration" | "var_spec" => (
+                node.children_by_field_name("name", &mut node.walk())
+                    .collect(),
+                node.child_by_field_name("value")
+                    .map(|v| v.named_children(&mut v.walk()).collect())
+                    .unwrap_or_default(),
+            ),
+            "short_var_declaration" => {
+                let (Some(left), Some(right)) = (
+                    node.child_by_field_name("left"),
+                    node.child_by_field_name("right"),
+                ) else {
+                    return;
+                };
+                (
+                    left.named_children(&mut left.walk()).collect(),
+                    right.named_children(&mut right.walk()).collect(),
+                )
+            }
+            _ => return,
+        };
+        let Some(position) = names.iter().position(|n| file.text(*n) == name) else {
+            return;
+        };
+        let declared = node
+            .child_by_field_name("type")
+            .map(|t| file.text(t).to_string())
+            .or_else(|| values.get(position).and_then(|v| evident_type(file, *v)));
+        latest = Some(declared);
+    });
+    latest.flatten()
+}
+
+/// `<-expr`
+fn is_receive(node: Node) -> bool {
+    node.kind() == "unary_expression"
+        && node
+            .child_by_field_name("operator")
+            .is_some_and(|op| op.kind() == "<-")
+}
+
+/// Element type of a channel type: `chan chan Job` gives `chan Job`
+pub(super) fn element_type(channel_type: &str) -> Option<&str> {
+    let rest = channel_type.trim();
+    let rest = rest.strip_prefix("<-").unwrap_or(rest).trim_start();
+    let rest = rest.strip_prefix("chan")?.trim_start();
+    Some(rest.strip_prefix("<-").unwrap_or(rest).trim_start())
+}
+
+/// The declared channel an expression refers to
+fn resolve_channel(
+    table: &ChannelTable,
+    index: usize,
+    file: &GoSourceFile,
+    expression: Node,
+) -> Option<usize> {
+    let package = file.package_name().unwrap_or_default();
+    match expression.kind() {
+        "parenthesized_expression" => {
+            resolve_channel(table, index, file, expression.named_child(0)?)
+        }
+        "identifier" => {
+            let name = file.text(expression).to_string();
+            enclosing_declaration(expression)
+                .and_then(|declaration| {
+                    table.get(&ChannelKey::Local(
+                        index,
+                        declaration.start_byte(),
+                        name.clone(),
+                    ))
+                })
+                .or_else(|| table.get(&ChannelKey::Package(package.to_string(), name)))
+        }
+        "selector_expression" => {
+            let operand = expression.child_by_field_name("operand")?;
+            let field = file.text(expression.child_by_field_name("field")?);
+            let owners = table.field_owners(package, field);
+            let owner = match owners.as_slice() {
+                [] => return None,
+                [owner] => owner.to_string(),
+                _ => {
+                    let owner = operand_type(file, operand)?;
+                    owners.contains(&owner).then(|| owner.to_string())?
+                }
+            };
+            table.get(&ChannelKey::Field(
+                package.to_string(),
+                owner,
+                field.to_string(),
+            ))
+        }
+        _ => None,
+    }
+}
+
+/// Type of a selector operand, when evident: the receiver or a parameter
+/// of the enclosing function, or an embedded field named after its type
+/// (`a.WorkerPool`)
+pub(super) fn operand_type<'s>(file: &'s GoSourceFile, operand: Node) -> Option<&'s str> {
+    match operand.kind() {
+        "selector_expression" => Some(file.text(operand.child_by_field_name("field")?)),
+        "identifier" => {
+            let name = file.text(operand);
+            let declaration = enclosing_declaration(operand)?;
+            let mut found = None;
+            for list in ["receiver", "parameters"] {
+                let Some(list) = declaration.child_by_field_name(list) else {
+                    continue;
+                };
+                for parameter in list.named_children(&mut list.walk()) {
+                    let declares = parameter
+                        .children_by_field_name("name", &mut parameter.walk())
+                        .any(|n| file.text(n) == name);
+                    if declares && found.is_none() {
+                        found = parameter
+                            .child_by_field_name("type")
+                            .and_then(|t| base_type_name(file, t));
+                    }
+                }
+            }
+            found
+        }
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_channels_with_sites_and_buffering() {
+        let code = r#"
+package pool
+
+type WorkerPool struct {
+    workers  chan chan Job
+    jobQueue chan Job
+    quit     chan bool
+}
+
+type Worker struct {
+    workerPool chan chan Job
+    jobChannel chan Job
+    quit       chan bool
+}
+
+func NewWorkerPool(n int) *WorkerPool {
+    return &WorkerPool{
+        workers:  make(chan chan Job, n),
+        jobQueue: make(chan Job, n*2),
+        quit:     make(chan bool),
+    }
+}
+
+func (wp *WorkerPool) Stop() {
+    wp.quit <- true
+}
+
+func (wp *WorkerPool) dispatch() {
+    for {
+        select {
+        case job := <-wp.jobQueue:
+            jobChannel := <-wp.workers
+            jobChannel <- job
+        case <-wp.quit:
+            return
+        }
+    }
+}
+
+func (w Worker) Start() {
+    w.workerPool <- w.jobChannel
+    <-w.quit
+}
+
+func Drain(results <-chan error) {
+    for err := range results {
+        println(err)
+    }
+    done := make(chan struct{})
+    close(done)
+}
+"#;
+        let file = GoSourceFile::parse("pool/pool.go", code.to_string()).unwrap();
+        let channels = find_channels(&[file]);
+        let summary: Vec<_> = channels
+            .iter()
+            .map(|c| {
+                let sites: Vec<_> = c.sites.iter().map(|s| (s.operation, s.line)).collect();
+                (c.name.as_str(), c.buffered, sites)
+            })
+            .collect();
+        use ChannelOperation::*;
+        assert_eq!(
+            summary,
+            vec![
+                ("WorkerPool.workers", Some(true), vec![(Receive, 32)]),
+                ("WorkerPool.jobQueue", Some(true), vec![(Receive, 31)]),
+                (
+                    "WorkerPool.quit",
+                    Some(false),
+                    vec![(Send, 25), (Receive, 34)]
+                ),
+                ("Worker.workerPool", None, vec![(Send, 41)]),
+                ("Worker.jobChannel", None, vec![]),
+                ("Worker.quit", None, vec![(Receive, 42)]),
+                ("jobChannel", None, vec![(Send, 33)]),
+                ("results", None, vec![(Receive, 46)]),
+                ("done", Some(false), vec![(Close, 50)]),
+            ]
+        );
+
+        let job_queue = &channels[1];
+        assert_eq!(job_queue.capacity.as_deref(), Some("n*2"));
+        assert_eq!(job_queue.channel_type.as_deref(), Some("chan Job"));
+        assert_eq!(channels[6].channel_type.as_deref(), Some("chan Job"));
+        assert_eq!(channels[6].function.as_deref(), Some("WorkerPool.dispatch"));
+        assert_eq!(
+            channels[1].to_string(),
+            "WorkerPool.jobQueue chan Job, buffered (n*2) at pool/pool.go:6\n  receive WorkerPool.dispatch at pool/pool.go:31:21"
+        );
+    }
+}
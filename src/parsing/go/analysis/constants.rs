@@ -0,0 +1,151 @@
+//! Package-level string constants and constant-expression folding
+//!
+//! Collects `const` declarations across files and folds simple string
+//! expressions: literals, references to other constants (local or
+//! package-qualified) and `+` concatenation. Anything else is left
+//! unresolved so callers can fall back to reporting the raw expression.
+
+use super::{GoSourceFile, string_literal_value};
+use std::collections::HashMap;
+use tree_sitter::Node;
+
+/// Rounds of folding before giving up on constants defined in terms of others
+const MAX_FOLD_ROUNDS: usize = 8;
+
+/// String constants keyed by (package, name)
+#[derive(Debug, Default)]
+pub struct ConstantTable {
+    values: HashMap<(String, String), String>,
+}
+
+impl ConstantTable {
+    /// Collect and fold the string constants declared in `files`
+    pub fn build(files: &[GoSourceFile]) -> Self {
+        let mut pending: Vec<(usize, String, String, Node)> = Vec::new();
+
+        for (index, file) in files.iter().enumerate() {
+            let package = file.package_name().unwrap_or_default().to_string();
+            super::walk_tree(file.root(), &mut |node| {
+                if node.kind() != "const_spec" {
+                    return;
+                }
+                let Some(values) = node.child_by_field_name("value") else {
+                    return;
+                };
+                let names = node
+                    .children(&mut node.walk())
+                    .filter(|n| n.kind() == "identifier")
+                    .collect::<Vec<_>>();
+                let exprs = values
+                    .named_children(&mut values.walk())
+                    .collect::<Vec<_>>();
+                for (name, expr) in names.into_iter().zip(exprs) {
+                    pending.push((index, package.clone(), file.text(name).to_string(), expr));
+                }
+            });
+        }
+
+        let mut table = Self::default();
+        for _ in 0..MAX_FOLD_ROUNDS {
+            let before = pending.len();
+            pending.retain(|(index, package, name, expr)| {
+                match table.eval_string(&files[*index], *expr) {
+                    Some(value) => {
+                        table.values.insert((package.clone(), name.clone()), value);
+                        false
+                    }
+                    None => true,
+                }
+            });
+            if pending.is_empty() || pending.len() == before {
+                break;
+            }
+        }
+
+        table
+    }
+
+    /// Value of the string constant `name` in `package`
+    pub fn get(&self, package: &str, name: &str) -> Option<&str> {
+        self.values
+            .get(&(package.to_string(), name.to_string()))
+            .map(|v| v.as_str())
+    }
+
+    pub fn len(&self) -> usize {
+        self.values.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.values.is_empty()
+    }
+
+    /// Fold a string expression appearing in `file`
+    pub fn eval_string(&self, file: &GoSourceFile, node: Node) -> Option<String> {
+        match node.kind() {
+            "interpreted_string_literal" | "raw_string_literal" => string_literal_value(file, node),
+            "identifier" => {
+                let package = file.package_name()?;
+                self.get(package, file.text(node)).map(str::to_string)
+            }
+            "selector_expression" => {
+                let operand = node.child_by_field_name("operand")?;
+                let field = node.child_by_field_name("field")?;
+                if operand.kind() != "identifier" {
+                    return None;
+                }
+                let aliases = file.import_aliases();
+                let path = aliases.get(file.text(operand))?;
+                let package = path.rsplit('/').next().unwrap_or(path.as_str());
+                self.get(package, file.text(field)).map(str::to_string)
+            }
+            "binary_expression" => {
+                let operator = node.child_by_field_name("operator")?;
+                if file.text(operator) != "+" {
+                    return None;
+                }
+                let left = self.eval_string(file, node.child_by_field_name("left")?)?;
+                let right = self.eval_string(file, node.child_by_field_name("right")?)?;
+                Some(left + &right)
+            }
+            "parenthesized_expression" => self.eval_string(file, node.named_child(0)?),
+            _ => None,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_constants_fold_across_packages() {
+        let config = r#"
+package config
+
+const (
+    EnvPrefix = "APP_"
+    PortKey   = EnvPrefix + "PORT"
+    Port      = 8080
+)
+"#;
+        let main = r#"
+package main
+
+import cfg "app/config"
+
+const DebugKey = cfg.EnvPrefix + ("DEBUG")
+"#;
+        let files = vec![
+            GoSourceFile::parse("config/settings.go", config.to_string()).unwrap(),
+            GoSourceFile::parse("main.go", main.to_string()).unwrap(),
+        ];
+
+        let table = ConstantTable::build(&files);
+        assert_eq!(table.get("config", "EnvPrefix"), Some("APP_"));
+        assert_eq!(table.get("config", "PortKey"), Some("APP_PORT"));
+        assert_eq!(table.get("main", "DebugKey"), Some("APP_DEBUG"));
+        // Non-string constants are not folded
+        assert_eq!(table.get("config", "Port"), None);
+    }
+}
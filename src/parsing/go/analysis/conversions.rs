@@ -0,0 +1,641 @@
+//! Conversions producing or consuming a type
+//!
+//! Each conversion is a place a value crosses into or out of a type, which
+//! is where representation bugs hide:
+//!
+//! ```go
+//! token := auth.AuthToken(header) // string -> auth.AuthToken
+//! raw := string(token)            // auth.AuthToken -> string
+//! body := []byte(raw)             // string -> []byte
+//! ```
+//!
+//! A call is a conversion when its callee names a type: a predeclared type,
+//! a type declared in the package or in an indexed package it imports, or a
+//! type literal such as `[]byte` or `(*Config)`. Calls to functions are
+//! told apart the way the parser does, after removing the parentheses and
+//! stars around the callee.
+//!
+//! The source type is worked out from the operand when evident: literals
+//! (as `untyped string`, `untyped int`, ...), parameters and variables
+//! declared with a type or initialized from a conversion or composite
+//! literal, and calls to functions of the package with a single result.
+
+use super::{GoSourceFile, enclosing_function_name, line_of, walk_tree};
+use crate::parsing::go::GoParser;
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use std::path::Path;
+use tree_sitter::Node;
+
+/// Types of the universe block, which convert without being declared
+const PREDECLARED_TYPES: &[&str] = &[
+    "any",
+    "bool",
+    "byte",
+    "complex64",
+    "complex128",
+    "error",
+    "float32",
+    "float64",
+    "int",
+    "int8",
+    "int16",
+    "int32",
+    "int64",
+    "rune",
+    "string",
+    "uint",
+    "uint8",
+    "uint16",
+    "uint32",
+    "uint64",
+    "uintptr",
+];
+
+/// Operands followed through variables before giving up on a source type
+const MAX_INFERENCE_DEPTH: u32 = 4;
+
+/// Which side of a conversion the queried type is on
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum ConversionDirection {
+    /// The conversion produces the type
+    To,
+    /// The conversion consumes a value of the type
+    From,
+}
+
+impl fmt::Display for ConversionDirection {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.pad(match self {
+            Self::To => "to",
+            Self::From => "from",
+        })
+    }
+}
+
+/// A conversion expression into or out of a type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeConversion {
+    pub direction: ConversionDirection,
+    /// Type converted to, named types qualified by package
+    pub target_type: String,
+    /// Type of the operand, when evident
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub source_type: Option<String>,
+    /// The conversion as written, on one line
+    pub expression: String,
+    /// Function or method containing the conversion
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the conversion
+    pub line: u32,
+    /// 1-based column of the conversion
+    pub column: u32,
+}
+
+impl fmt::Display for TypeConversion {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{:<4} {} -> {}  {}",
+            self.direction,
+            self.source_type.as_deref().unwrap_or("?"),
+            self.target_type,
+            self.expression
+        )?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}:{}", self.file, self.line, self.column)
+    }
+}
+
+/// Find the conversions to or from `type_name`, sorted by file and position
+///
+/// The type is given as Go writes it, such as `AuthToken`, `auth.AuthToken`
+/// or `[]byte`. Without a package qualifier, named types of any package
+/// match.
+pub fn find_type_conversions(files: &[GoSourceFile], type_name: &str) -> Vec<TypeConversion> {
+    let query: String = type_name.split_whitespace().collect();
+    let packages = PackageDeclarations::collect(files);
+
+    let mut conversions = Vec::new();
+    for (index, file) in files.iter().enumerate() {
+        let context = FileContext::new(files, index, &packages);
+        walk_tree(file.root(), &mut |node| {
+            let Some((target, operand)) = context.conversion(node) else {
+                return;
+            };
+            let target_type = context.written_type(target);
+            let source_type = context.operand_type(operand, 0);
+            let direction = if type_matches(&target_type, &query) {
+                ConversionDirection::To
+            } else if source_type
+                .as_deref()
+                .is_some_and(|source| type_matches(source, &query))
+            {
+                ConversionDirection::From
+            } else {
+                return;
+            };
+            conversions.push(TypeConversion {
+                direction,
+                target_type,
+                source_type,
+                expression: one_line(file.text(node)),
+                function: enclosing_function_name(file, node),
+                file: file.display_path(),
+                line: line_of(node),
+                column: node.start_position().column as u32 + 1,
+            });
+        });
+    }
+    conversions.sort_by(|a, b| (&a.file, a.line, a.column).cmp(&(&b.file, b.line, b.column)));
+    conversions
+}
+
+/// Types and single-result functions declared by each package, keyed by
+/// (directory, package name)
+struct PackageDeclarations<'f> {
+    types: HashMap<(&'f Path, &'f str), HashSet<&'f str>>,
+    /// Function name to (file index, result type)
+    results: HashMap<(&'f Path, &'f str), HashMap<&'f str, (usize, Node<'f>)>>,
+}
+
+impl<'f> PackageDeclarations<'f> {
+    fn collect(files: &'f [GoSourceFile]) -> Self {
+        let mut types: HashMap<_, HashSet<_>> = HashMap::new();
+        let mut results: HashMap<_, HashMap<_, _>> = HashMap::new();
+        for (index, file) in files.iter().enumerate() {
+            let key = package_key(file);
+            let root = file.root();
+            for decl in root.named_children(&mut root.walk()) {
+                match decl.kind() {
+                    "type_declaration" => {
+                        for spec in decl.named_children(&mut decl.walk()) {
+                            if let Some(name) = spec.child_by_field_name("name") {
+                                types.entry(key).or_default().insert(file.text(name));
+                            }
+                        }
+                    }
+                    "function_declaration" => {
+                        let (Some(name), Some(result)) = (
+                            decl.child_by_field_name("name"),
+                            decl.child_by_field_name("result").and_then(single_result),
+                        ) else {
+                            continue;
+                        };
+                        results
+                            .entry(key)
+                            .or_default()
+                            .insert(file.text(name), (index, result));
+                    }
+                    _ => {}
+                }
+            }
+        }
+        Self { types, results }
+    }
+
+    /// Package declaring the type `name` that an import path refers to
+    fn imported_type(&self, import_path: &str, name: &str) -> Option<&'f str> {
+        let last = import_path.rsplit('/').next().unwrap_or(import_path);
+        self.types.iter().find_map(|((dir, package), names)| {
+            (dir.file_name().and_then(|d| d.to_str()) == Some(last) && names.contains(name))
+                .then_some(*package)
+        })
+    }
+}
+
+/// A file being searched, with what it can see of the other files
+struct FileContext<'f, 'p> {
+    files: &'f [GoSourceFile],
+    file: &'f GoSourceFile,
+    index: usize,
+    aliases: HashMap<String, String>,
+    packages: &'p PackageDeclarations<'f>,
+}
+
+impl<'f, 'p> FileContext<'f, 'p> {
+    fn new(files: &'f [GoSourceFile], index: usize, packages: &'p PackageDeclarations<'f>) -> Self {
+        let file = &files[index];
+        Self {
+            files,
+            file,
+            index,
+            aliases: file.import_aliases(),
+            packages,
+        }
+    }
+
+    fn for_file(&self, index: usize) -> Self {
+        Self::new(self.files, index, self.packages)
+    }
+
+    /// The type and operand of a conversion expression
+    fn conversion(&self, node: Node<'f>) -> Option<(Node<'f>, Node<'f>)> {
+        match node.kind() {
+            "type_conversion_expression" => Some((
+                node.child_by_field_name("type")?,
+                node.child_by_field_name("operand")?,
+            )),
+            "call_expression" => {
+                if node.child_by_field_name("type_arguments").is_some() {
+                    return None;
+                }
+                let arguments = node.child_by_field_name("arguments")?;
+                let mut operands = arguments
+                    .named_children(&mut arguments.walk())
+                    .filter(|n| n.kind() != "comment")
+                    .collect::<Vec<_>>()
+                    .into_iter();
+                let (Some(operand), None) = (operands.next(), operands.next()) else {
+                    return None;
+                };
+                let function = node.child_by_field_name("function")?;
+                self.names_type(GoParser::conversion_base(function))
+                    .then_some((function, operand))
+            }
+            _ => None,
+        }
+    }
+
+    /// Whether a callee, with parentheses and stars removed, is a type
+    fn names_type(&self, callee: Node) -> bool {
+        match callee.kind() {
+            "identifier" | "type_identifier" => {
+                let name = self.file.text(callee);
+                PREDECLARED_TYPES.contains(&name) || self.declares_type(name)
+            }
+            "selector_expression" => {
+                let (Some(package), Some(name)) = (
+                    callee.child_by_field_name("operand"),
+                    callee.child_by_field_name("field"),
+                ) else {
+                    return false;
+                };
+                self.aliases
+                    .get(self.file.text(package))
+                    .is_some_and(|path| {
+                        self.packages
+                            .imported_type(path, self.file.text(name))
+                            .is_some()
+                    })
+            }
+            "slice_type" | "array_type" | "map_type" | "channel_type" | "function_type"
+            | "struct_type" | "interface_type" | "qualified_type" | "generic_type" => true,
+            _ => false,
+        }
+    }
+
+    fn declares_type(&self, name: &str) -> bool {
+        self.packages
+            .types
+            .get(&package_key(self.file))
+            .is_some_and(|names| names.contains(name))
+    }
+
+    /// A type expression as written, without surrounding parentheses, with
+    /// named types qualified by package: `(*Token)` in package `auth` gives
+    /// `*auth.Token`, `m.User` importing `models` as `m` gives `models.User`
+    fn written_type(&self, node: Node) -> String {
+        let mut text = one_line(&self.qualified(node));
+        while text.starts_with('(') && text.ends_with(')') && balanced(&text[1..text.len() - 1]) {
+            text = text[1..text.len() - 1].to_string();
+        }
+        text
+    }
+
+    fn qualified(&self, node: Node) -> String {
+        let file = self.file;
+        match node.kind() {
+            "identifier" | "type_identifier" => {
+                let name = file.text(node);
+                match file.package_name() {
+                    Some(package) if self.declares_type(name) => format!("{package}.{name}"),
+                    _ => name.to_string(),
+                }
+            }
+            "qualified_type" | "selector_expression" => {
+                let package = node
+                    .child_by_field_name("package")
+                    .or_else(|| node.child_by_field_name("operand"));
+                let name = node
+                    .child_by_field_name("name")
+                    .or_else(|| node.child_by_field_name("field"));
+                match (package, name) {
+                    (Some(package), Some(name)) => {
+                        let local = file.text(package);
+                        let package = self
+                            .aliases
+                            .get(local)
+                            .map(|path| path.rsplit('/').next().unwrap_or(path))
+                            .unwrap_or(local);
+                        format!("{package}.{}", file.text(name))
+                    }
+                    _ => file.text(node).to_string(),
+                }
+            }
+            _ if node.named_child_count() == 0 => file.text(node).to_string(),
+            _ => {
+                // Keep the text between children, qualifying the children
+                let mut text = String::new();
+                let mut end = node.start_byte();
+                for child in node.children(&mut node.walk()) {
+                    text.push_str(&file.source[end..child.start_byte()]);
+                    text.push_str(&self.qualified(child));
+                    end = child.end_byte();
+                }
+                text.push_str(&file.source[end..node.end_byte()]);
+                text
+            }
+        }
+    }
+
+    /// Type of an operand, when evident from the expression or from the
+    /// declaration of the variable it names
+    fn operand_type(&self, node: Node<'f>, depth: u32) -> Option<String> {
+        if depth > MAX_INFERENCE_DEPTH {
+            return None;
+        }
+        match node.kind() {
+            "interpreted_string_literal" | "raw_string_literal" => {
+                Some("untyped string".to_string())
+            }
+            "int_literal" => Some("untyped int".to_string()),
+            "float_literal" => Some("untyped float".to_string()),
+            "imaginary_literal" => Some("untyped complex".to_string()),
+            "rune_literal" => Some("untyped rune".to_string()),
+            "true" | "false" => Some("untyped bool".to_string()),
+            "parenthesized_expression" => self.operand_type(node.named_child(0)?, depth),
+            "composite_literal" => Some(self.written_type(node.child_by_field_name("type")?)),
+            "unary_expression" => {
+                let operator = node.child_by_field_name("operator")?;
+                let operand = node.child_by_field_name("operand")?;
+                (operator.kind() == "&" && operand.kind() == "composite_literal")
+                    .then(|| self.operand_type(operand, depth))
+                    .flatten()
+                    .map(|base| format!("*{base}"))
+            }
+            "call_expression" | "type_conversion_expression" => {
+                if let Some((target, _)) = self.conversion(node) {
+                    return Some(self.written_type(target));
+                }
+                let function = node.child_by_field_name("function")?;
+                if function.kind() != "identifier" {
+                    return None;
+                }
+                let (index, result) = *self
+                    .packages
+                    .results
+                    .get(&package_key(self.file))?
+                    .get(self.file.text(function))?;
+                if index == self.index {
+                    return Some(self.written_type(result));
+                }
+                // Qualify the result type with the imports of its own file
+                Some(self.for_file(index).written_type(result))
+            }
+            "identifier" => self.variable_type(node, depth),
+            _ => None,
+        }
+    }
+
+    /// Type of the variable `node` names, from its nearest declaration above
+    /// it in the enclosing function, or else from a package-level `var`
+    fn variable_type(&self, node: Node<'f>, depth: u32) -> Option<String> {
+        let name = self.file.text(node);
+        let mut function = node.parent();
+        while let Some(n) = function {
+            if matches!(
+                n.kind(),
+                "function_declaration" | "method_declaration" | "func_literal"
+            ) {
+                break;
+            }
+            function = n.parent();
+        }
+
+        let mut found = None;
+        let scope = function.unwrap_or_else(|| self.file.root());
+        walk_tree(scope, &mut |decl| {
+            if decl.start_byte() >= node.start_byte() {
+                return;
+            }
+            if let Some(declared) = self.declared_type(decl, name, depth) {
+                found = Some(declared);
+            }
+        });
+        if found.is_some() || function.is_none() {
+            return found;
+        }
+
+        // Package-level variables of this file
+        let root = self.file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if decl.kind() != "var_declaration" {
+                continue;
+            }
+            walk_tree(decl, &mut |spec| {
+                if let Some(declared) = self.declared_type(spec, name, depth) {
+                    found = Some(declared);
+                }
+            });
+        }
+        found
+    }
+
+    /// Type `decl` gives the variable `name`, when it declares it
+    fn declared_type(&self, decl: Node<'f>, name: &str, depth: u32) -> Option<String> {
+        match decl.kind() {
+            "parameter_declaration" | "variadic_parameter_declaration" | "var_spec" => {
+                let names: Vec<_> = decl
+                    .children_by_field_name("name", &mut decl.walk())
+                    .collect();
+                let position = names.iter().position(|n| self.file.text(*n) == name)?;
+                if let Some(declared) = decl.child_by_field_name("type") {
+                    return (decl.kind() != "variadic_parameter_declaration")
+                        .then(|| self.written_type(declared));
+                }
+                let values = decl.child_by_field_name("value")?;
+                let value = values.named_children(&mut values.walk()).nth(position)?;
+                self.operand_type(value, depth + 1)
+            }
+            "short_var_declaration" => {
+                let (left, right) = (
+                    decl.child_by_field_name("left")?,
+                    decl.child_by_field_name("right")?,
+                );
+                let position = left
+                    .named_children(&mut left.walk())
+                    .position(|n| self.file.text(n) == name)?;
+                let values: Vec<_> = right.named_children(&mut right.walk()).collect();
+                if values.len() != left.named_child_count() {
+                    return None;
+                }
+                self.operand_type(values[position], depth + 1)
+            }
+            _ => None,
+        }
+    }
+}
+
+/// Package of a file, keyed by directory since package names repeat
+fn package_key(file: &GoSourceFile) -> (&Path, &str) {
+    (
+        file.path.parent().unwrap_or(Path::new("")),
+        file.package_name().unwrap_or_default(),
+    )
+}
+
+/// Type of a result list holding a single type: `(T)`, `(v T)` or `T`
+fn single_result(result: Node) -> Option<Node> {
+    if result.kind() != "parameter_list" {
+        return Some(result);
+    }
+    let mut declarations = result
+        .named_children(&mut result.walk())
+        .filter(|n| n.kind() == "parameter_declaration")
+        .collect::<Vec<_>>()
+        .into_iter();
+    let (Some(declaration), None) = (declarations.next(), declarations.next()) else {
+        return None;
+    };
+    if declaration
+        .children_by_field_name("name", &mut declaration.walk())
+        .count()
+        > 1
+    {
+        return None;
+    }
+    declaration.child_by_field_name("type")
+}
+
+/// Whether a written type matches a query without whitespace, as given or
+/// with the package qualifiers dropped
+fn type_matches(written: &str, query: &str) -> bool {
+    let written: String = written.split_whitespace().collect();
+    written == query || unqualified(&written) == query
+}
+
+/// Drop the package qualifiers of named types: `[]auth.Token` gives `[]Token`
+fn unqualified(text: &str) -> String {
+    let mut out = String::with_capacity(text.len());
+    for c in text.chars() {
+        if c == '.' {
+            let kept = out
+                .trim_end_matches(|c: char| c.is_alphanumeric() || c == '_')
+                .len();
+            out.truncate(kept);
+        } else {
+            out.push(c);
+        }
+    }
+    out
+}
+
+/// Whether the parentheses in `text` pair up
+fn balanced(text: &str) -> bool {
+    let mut depth = 0i32;
+    for c in text.chars() {
+        match c {
+            '(' => depth += 1,
+            ')' => depth -= 1,
+            _ => {}
+        }
+        if depth < 0 {
+            return false;
+        }
+    }
+    depth == 0
+}
+
+/// Text with runs of whitespace collapsed to single spaces
+fn one_line(text: &str) -> String {
+    text.split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_conversions_to_and_from_a_type() {
+        let files = [
+            parse(
+                "app/auth/token.go",
+                r#"package auth
+
+type AuthToken string
+
+func Issue(user string) AuthToken {
+    return AuthToken(user)
+}
+
+func (t AuthToken) Bytes() []byte {
+    return []byte(t)
+}
+
+func refresh() string {
+    t := Issue("x")
+    return string(t)
+}
+"#,
+            ),
+            parse(
+                "app/main.go",
+                r#"package main
+
+import "example.com/app/auth"
+
+func login(header string) string {
+    token := auth.AuthToken(header)
+    raw := string(token)
+    return raw + string(len(raw))
+}
+"#,
+            ),
+        ];
+
+        let summary = |query: &str| -> Vec<(ConversionDirection, Option<String>, String, u32)> {
+            find_type_conversions(&files, query)
+                .into_iter()
+                .map(|c| (c.direction, c.source_type, c.target_type, c.line))
+                .collect()
+        };
+        use ConversionDirection::{From, To};
+        let token = "auth.AuthToken".to_string();
+        let string = Some("string".to_string());
+        assert_eq!(
+            summary("AuthToken"),
+            vec![
+                (To, string.clone(), token.clone(), 6),
+                (From, Some(token.clone()), "[]byte".to_string(), 10),
+                (From, Some(token.clone()), "string".to_string(), 15),
+                (To, string.clone(), token.clone(), 6),
+                (From, Some(token.clone()), "string".to_string(), 7),
+            ]
+        );
+        // Qualified, and through a type literal; calls such as Issue(...)
+        // and len(...) are not conversions
+        assert_eq!(summary("auth.AuthToken").len(), 5);
+        assert_eq!(
+            summary("[]byte"),
+            vec![(To, Some(token.clone()), "[]byte".to_string(), 10)]
+        );
+        assert!(summary("other.AuthToken").is_empty());
+
+        let first = &find_type_conversions(&files, "AuthToken")[0];
+        assert_eq!(
+            first.to_string(),
+            "to   string -> auth.AuthToken  AuthToken(user) in Issue at app/auth/token.go:6:12"
+        );
+    }
+}
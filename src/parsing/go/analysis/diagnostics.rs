@@ -0,0 +1,734 @@
+//! Lightweight diagnostics for editors: unresolved references, unused
+//! imports, shadowed variables and imports, methods declared on aliases of other
+//! packages' types, duplicate top-level declarations, methods promoted
+//! ambiguously from embedded types and loop variables captured by goroutines
+//! before Go 1.22
+//!
+//! These are syntax-level approximations of what the Go compiler and `go vet`
+//! report, cheap enough to recompute after every incremental re-index.
+//! [`DiagnosticsTracker`] keeps the last reported state so that only changes
+//! are emitted.
+
+use super::{
+    GoSourceFile, LocalDeclaration, find_ambiguous_selectors, find_duplicate_definitions,
+    find_loop_var_captures, local_declarations, walk_tree,
+};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+use std::fmt;
+use std::path::Path;
+use tree_sitter::Node;
+
+/// Predeclared functions and types that can be called without a declaration
+const PREDECLARED: &[&str] = &[
+    "append",
+    "cap",
+    "clear",
+    "close",
+    "complex",
+    "copy",
+    "delete",
+    "imag",
+    "len",
+    "make",
+    "max",
+    "min",
+    "new",
+    "panic",
+    "print",
+    "println",
+    "real",
+    "recover",
+    "any",
+    "bool",
+    "byte",
+    "complex64",
+    "complex128",
+    "error",
+    "float32",
+    "float64",
+    "int",
+    "int8",
+    "int16",
+    "int32",
+    "int64",
+    "rune",
+    "string",
+    "uint",
+    "uint8",
+    "uint16",
+    "uint32",
+    "uint64",
+    "uintptr",
+];
+
+/// Kinds of diagnostics
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum DiagnosticKind {
+    /// Call to a name that is not declared in scope or in the package
+    UnresolvedRef,
+    /// Import whose package name is never used
+    UnusedImport,
+    /// Local declaration hiding one from an enclosing scope or an import
+    Shadowing,
+    /// Method declared on an alias of a type from another package
+    InvalidReceiver,
+    /// Top-level identifier declared again in the same package
+    DuplicateDefinition,
+    /// Loop variable shared across iterations captured by an escaping closure
+    LoopVarCapture,
+    /// Method promoted from two embedded types at the same depth
+    AmbiguousSelector,
+}
+
+impl fmt::Display for DiagnosticKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Self::UnresolvedRef => write!(f, "unresolved-ref"),
+            Self::UnusedImport => write!(f, "unused-import"),
+            Self::Shadowing => write!(f, "shadowing"),
+            Self::InvalidReceiver => write!(f, "invalid-receiver"),
+            Self::DuplicateDefinition => write!(f, "duplicate-definition"),
+            Self::LoopVarCapture => write!(f, "loop-var-capture"),
+            Self::AmbiguousSelector => write!(f, "ambiguous-selector"),
+        }
+    }
+}
+
+/// A single diagnostic
+#[derive(Debug, Clone, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+pub struct Diagnostic {
+    pub file: String,
+    /// 1-based line number
+    pub line: u32,
+    /// 1-based column number
+    pub column: u32,
+    pub kind: DiagnosticKind,
+    pub message: String,
+}
+
+impl fmt::Display for Diagnostic {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}:{}:{}: {}: {}",
+            self.file, self.line, self.column, self.kind, self.message
+        )
+    }
+}
+
+impl Diagnostic {
+    fn at(file: &GoSourceFile, node: Node, kind: DiagnosticKind, message: String) -> Self {
+        let position = node.start_position();
+        Self {
+            file: file.display_path(),
+            line: position.row as u32 + 1,
+            column: position.column as u32 + 1,
+            kind,
+            message,
+        }
+    }
+}
+
+/// A change to the reported diagnostics, serialized as one NDJSON line
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "event", rename_all = "kebab-case")]
+pub enum DiagnosticEvent {
+    /// A diagnostic that was not reported before
+    Added(Diagnostic),
+    /// A previously reported diagnostic that no longer applies
+    Resolved(Diagnostic),
+    /// Every diagnostic of `file` is gone
+    Cleared { file: String },
+}
+
+/// Last reported diagnostics, used to compute deltas
+#[derive(Debug, Default)]
+pub struct DiagnosticsTracker {
+    current: BTreeMap<String, BTreeSet<Diagnostic>>,
+}
+
+impl DiagnosticsTracker {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Replace the reported state with `diagnostics` and return what changed
+    ///
+    /// Files that become clean produce a single `Cleared` event instead of
+    /// one `Resolved` event per diagnostic.
+    pub fn update(&mut self, diagnostics: Vec<Diagnostic>) -> Vec<DiagnosticEvent> {
+        let mut next: BTreeMap<String, BTreeSet<Diagnostic>> = BTreeMap::new();
+        for diagnostic in diagnostics {
+            next.entry(diagnostic.file.clone())
+                .or_default()
+                .insert(diagnostic);
+        }
+
+        let files: BTreeSet<String> = self.current.keys().chain(next.keys()).cloned().collect();
+        let empty = BTreeSet::new();
+        let mut events = Vec::new();
+
+        for file in files {
+            let before = self.current.get(&file).unwrap_or(&empty);
+            let after = next.get(&file).unwrap_or(&empty);
+            if after.is_empty() {
+                if !before.is_empty() {
+                    events.push(DiagnosticEvent::Cleared { file });
+                }
+                continue;
+            }
+            events.extend(
+                before
+                    .difference(after)
+                    .cloned()
+                    .map(DiagnosticEvent::Resolved),
+            );
+            events.extend(
+                after
+                    .difference(before)
+                    .cloned()
+                    .map(DiagnosticEvent::Added),
+            );
+        }
+
+        self.current = next;
+        events
+    }
+
+    /// Number of diagnostics currently reported
+    pub fn len(&self) -> usize {
+        self.current.values().map(BTreeSet::len).sum()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.len() == 0
+    }
+}
+
+/// Compute diagnostics for `files`, sorted by position
+///
+/// Files are grouped into packages by directory and package clause so that
+/// references to declarations in sibling files resolve.
+pub fn find_diagnostics(files: &[GoSourceFile]) -> Vec<Diagnostic> {
+    let mut packages: HashMap<(&Path, &str), Vec<&GoSourceFile>> = HashMap::new();
+    for file in files {
+        let dir = file.path.parent().unwrap_or(Path::new(""));
+        let package = file.package_name().unwrap_or_default();
+        packages.entry((dir, package)).or_default().push(file);
+    }
+
+    let mut diagnostics = Vec::new();
+    for package_files in packages.values() {
+        let declarations = package_declarations(package_files);
+        let aliases = non_local_aliases(package_files);
+        for file in package_files {
+            diagnostics.extend(diagnostics_in_file(file, &declarations));
+            diagnostics.extend(alias_receivers(file, &aliases));
+        }
+    }
+    diagnostics.extend(
+        find_duplicate_definitions(files)
+            .into_iter()
+            .map(|d| Diagnostic {
+                message: format!(
+                    "{} redeclared in this block (other declaration at {}:{})",
+                    d.name, d.other_file, d.other_line
+                ),
+                file: d.file,
+                line: d.line,
+                column: d.column,
+                kind: DiagnosticKind::DuplicateDefinition,
+            }),
+    );
+    diagnostics.extend(find_loop_var_captures(files).into_iter().map(|c| Diagnostic {
+        message: format!(
+            "loop variable {} captured by func literal ({}); it is shared by all iterations of the loop at line {}",
+            c.variable, c.escape, c.loop_line
+        ),
+        file: c.file,
+        line: c.line,
+        column: c.column,
+        kind: DiagnosticKind::LoopVarCapture,
+    }));
+    diagnostics.extend(
+        find_ambiguous_selectors(files)
+            .into_iter()
+            .map(|s| Diagnostic {
+                message: format!(
+                    "ambiguous selector {}.{}: promoted from both {} at depth {}",
+                    s.type_name,
+                    s.member,
+                    s.providers.join(" and "),
+                    s.depth
+                ),
+                file: s.file,
+                line: s.line,
+                column: s.column,
+                kind: DiagnosticKind::AmbiguousSelector,
+            }),
+    );
+    diagnostics.sort();
+    diagnostics
+}
+
+/// Names declared at package level across `files`
+pub fn package_declarations(files: &[&GoSourceFile]) -> HashSet<String> {
+    let mut names = HashSet::new();
+    for file in files {
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            match decl.kind() {
+                "function_declaration" => {
+                    if let Some(name) = decl.child_by_field_name("name") {
+                        names.insert(file.text(name).to_string());
+                    }
+                }
+                "type_declaration" | "var_declaration" | "const_declaration" => {
+                    walk_tree(decl, &mut |node| {
+                        if matches!(
+                            node.kind(),
+                            "type_spec" | "type_alias" | "var_spec" | "const_spec"
+                        ) {
+                            for child in node.children_by_field_name("name", &mut node.walk()) {
+                                names.insert(file.text(child).to_string());
+                            }
+                        }
+                    });
+                }
+                _ => {}
+            }
+        }
+    }
+    names
+}
+
+/// Diagnostics for one file given the package-level declarations
+pub fn diagnostics_in_file(
+    file: &GoSourceFile,
+    package_declarations: &HashSet<String>,
+) -> Vec<Diagnostic> {
+    let mut diagnostics = unused_imports(file);
+    let has_dot_import = has_dot_import(file);
+    let imports = file.import_aliases();
+
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let declarations = local_declarations(decl, &file.source);
+        diagnostics.extend(shadowed_declarations(file, &declarations));
+        diagnostics.extend(shadowed_imports(file, &declarations, &imports));
+        if !has_dot_import {
+            diagnostics.extend(unresolved_calls(
+                file,
+                decl,
+                &declarations,
+                package_declarations,
+            ));
+        }
+    }
+
+    diagnostics.sort();
+    diagnostics
+}
+
+/// Imports whose package name never appears as a qualifier
+fn unused_imports(file: &GoSourceFile) -> Vec<Diagnostic> {
+    let mut qualifiers = HashSet::new();
+    walk_tree(file.root(), &mut |node| match node.kind() {
+        "selector_expression" => {
+            if let Some(operand) = node.child_by_field_name("operand") {
+                if operand.kind() == "identifier" {
+                    qualifiers.insert(file.text(operand));
+                }
+            }
+        }
+        "qualified_type" => {
+            if let Some(package) = node.child_by_field_name("package") {
+                qualifiers.insert(file.text(package));
+            }
+        }
+        _ => {}
+    });
+
+    let mut diagnostics = Vec::new();
+    walk_tree(file.root(), &mut |node| {
+        if node.kind() != "import_spec" {
+            return;
+        }
+        let Some(path_node) = node.child_by_field_name("path") else {
+            return;
+        };
+        let path = file.text(path_node).trim_matches(|c| c == '"' || c == '`');
+        let local = match node.child_by_field_name("name") {
+            Some(name) if name.kind() == "package_identifier" => file.text(name),
+            // Blank and dot imports are used for side effects or unqualified access
+            Some(_) => return,
+            None => {
+                // Only trust the last path segment when it is a plain identifier;
+                // `gopkg.in/yaml.v3` or `go-redis` declare a different name
+                let last = path.rsplit('/').next().unwrap_or(path);
+                let plain = last.chars().all(|c| c.is_alphanumeric() || c == '_');
+                let is_major_version = last.starts_with('v') && last[1..].parse::<u32>().is_ok();
+                if !plain || is_major_version || path == "C" {
+                    return;
+                }
+                last
+            }
+        };
+        if !qualifiers.contains(local) {
+            diagnostics.push(Diagnostic::at(
+                file,
+                node,
+                DiagnosticKind::UnusedImport,
+                format!("\"{path}\" imported and not used"),
+            ));
+        }
+    });
+    diagnostics
+}
+
+fn has_dot_import(file: &GoSourceFile) -> bool {
+    let mut found = false;
+    walk_tree(file.root(), &mut |node| {
+        if node.kind() == "import_spec"
+            && node
+                .child_by_field_name("name")
+                .is_some_and(|n| n.kind() == "dot")
+        {
+            found = true;
+        }
+    });
+    found
+}
+
+/// Whether `inner` is nested inside (or is) `outer`
+fn within(inner: Node, outer: Node) -> bool {
+    inner.start_byte() >= outer.start_byte() && inner.end_byte() <= outer.end_byte()
+}
+
+/// Declarations that hide an earlier declaration from an enclosing scope
+fn shadowed_declarations(
+    file: &GoSourceFile,
+    declarations: &[LocalDeclaration],
+) -> Vec<Diagnostic> {
+    let mut diagnostics = Vec::new();
+    for inner in declarations {
+        let name = file.text(inner.name);
+        let outer = declarations.iter().find(|outer| {
+            file.text(outer.name) == name
+                && outer.name.start_byte() < inner.name.start_byte()
+                && outer.scope.id() != inner.scope.id()
+                && within(inner.scope, outer.scope)
+        });
+        if let Some(outer) = outer {
+            diagnostics.push(Diagnostic::at(
+                file,
+                inner.name,
+                DiagnosticKind::Shadowing,
+                format!(
+                    "declaration of \"{name}\" shadows declaration at line {}",
+                    outer.name.start_position().row + 1
+                ),
+            ));
+        }
+    }
+    diagnostics
+}
+
+/// Local declarations named like an imported package, which hide the
+/// package for the rest of their scope
+fn shadowed_imports(
+    file: &GoSourceFile,
+    declarations: &[LocalDeclaration],
+    imports: &HashMap<String, String>,
+) -> Vec<Diagnostic> {
+    declarations
+        .iter()
+        .filter_map(|declaration| {
+            let name = file.text(declaration.name);
+            let path = imports.get(name)?;
+            Some(Diagnostic::at(
+                file,
+                declaration.name,
+                DiagnosticKind::Shadowing,
+                format!("declaration of \"{name}\" shadows import \"{path}\""),
+            ))
+        })
+        .collect()
+}
+
+/// Calls of bare identifiers that are neither local, package-level nor predeclared
+fn unresolved_calls(
+    file: &GoSourceFile,
+    function: Node,
+    declarations: &[LocalDeclaration],
+    package_declarations: &HashSet<String>,
+) -> Vec<Diagnostic> {
+    let locals: HashSet<&str> = declarations.iter().map(|d| file.text(d.name)).collect();
+    let type_params = type_parameter_names(file, function);
+
+    let mut diagnostics = Vec::new();
+    walk_tree(function, &mut |node| {
+        if node.kind() != "call_expression" {
+            return;
+        }
+        let Some(callee) = node.child_by_field_name("function") else {
+            return;
+        };
+        if callee.kind() != "identifier" {
+            return;
+        }
+        let name = file.text(callee);
+        if locals.contains(name)
+            || type_params.contains(name)
+            || package_declarations.contains(name)
+            || PREDECLARED.contains(&name)
+        {
+            return;
+        }
+        diagnostics.push(Diagnostic::at(
+            file,
+            callee,
+            DiagnosticKind::UnresolvedRef,
+            format!("undefined: {name}"),
+        ));
+    });
+    diagnostics
+}
+
+/// Package-level aliases of types from other packages, `type User = models.User`,
+/// mapped to the aliased type
+fn non_local_aliases(files: &[&GoSourceFile]) -> HashMap<String, String> {
+    let mut aliases = HashMap::new();
+    for file in files {
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "type_alias" {
+                return;
+            }
+            let (Some(name), Some(mut target)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type"),
+            ) else {
+                return;
+            };
+            while target.kind() == "pointer_type" {
+                match target.named_child(0) {
+                    Some(inner) => target = inner,
+                    None => return,
+                }
+            }
+            if target.kind() == "qualified_type" {
+                aliases.insert(file.text(name).to_string(), file.text(target).to_string());
+            }
+        });
+    }
+    aliases
+}
+
+/// Methods whose receiver is an alias of another package's type
+///
+/// Go only allows methods on types of the declaring package; the compiler
+/// rejects them with "cannot define new methods on non-local type".
+fn alias_receivers(file: &GoSourceFile, aliases: &HashMap<String, String>) -> Vec<Diagnostic> {
+    let mut diagnostics = Vec::new();
+    if aliases.is_empty() {
+        return diagnostics;
+    }
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        if decl.kind() != "method_declaration" {
+            continue;
+        }
+        let Some(receiver_type) = super::receiver_type_node(decl) else {
+            continue;
+        };
+        let Some(target) = super::base_type_name(file, receiver_type).and_then(|n| aliases.get(n))
+        else {
+            continue;
+        };
+        diagnostics.push(Diagnostic::at(
+            file,
+            receiver_type,
+            DiagnosticKind::InvalidReceiver,
+            format!("cannot define new methods on non-local type {target}"),
+        ));
+    }
+    diagnostics
+}
+
+/// Type parameter names of a generic function or of a generic receiver
+fn type_parameter_names<'s>(file: &'s GoSourceFile, function: Node) -> HashSet<&'s str> {
+    let mut names = HashSet::new();
+    let mut collect = |node: Node| {
+        walk_tree(node, &mut |n| {
+            if n.kind() == "type_parameter_declaration" {
+                for name in n.children_by_field_name("name", &mut n.walk()) {
+                    names.insert(file.text(name));
+                }
+            }
+        });
+    };
+    if let Some(params) = function.child_by_field_name("type_parameters") {
+        collect(params);
+    }
+    if let Some(receiver) = function.child_by_field_name("receiver") {
+        // `func (s *Stack[T]) Push(v T)` declares T on the receiver
+        walk_tree(receiver, &mut |n| {
+            if n.kind() == "type_arguments" {
+                for arg in n.named_children(&mut n.walk()) {
+                    names.insert(file.text(arg));
+                }
+            }
+        });
+    }
+    names
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_diagnostics_and_deltas() {
+        let helpers = r#"
+package app
+
+func helper() int { return 1 }
+"#;
+        let main = r#"
+package app
+
+import (
+    "fmt"
+    "os"
+    "strings"
+)
+
+func Run(name string) error {
+    count := helper()
+    if count > 0 {
+        name := strings.ToUpper(name)
+        fmt.Println(name)
+    }
+    missing()
+    return nil
+}
+"#;
+        let files = vec![parse("app/helpers.go", helpers), parse("app/main.go", main)];
+        let diagnostics = find_diagnostics(&files);
+        let summary: Vec<_> = diagnostics
+            .iter()
+            .map(|d| (d.kind, d.line, d.message.as_str()))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (
+                    DiagnosticKind::UnusedImport,
+                    6,
+                    "\"os\" imported and not used"
+                ),
+                (
+                    DiagnosticKind::Shadowing,
+                    13,
+                    "declaration of \"name\" shadows declaration at line 10"
+                ),
+                (DiagnosticKind::UnresolvedRef, 16, "undefined: missing"),
+            ]
+        );
+
+        let mut tracker = DiagnosticsTracker::new();
+        assert_eq!(tracker.update(diagnostics.clone()).len(), 3);
+        // Nothing changed
+        assert!(tracker.update(diagnostics.clone()).is_empty());
+
+        // One diagnostic fixed
+        let fixed: Vec<_> = diagnostics
+            .iter()
+            .filter(|d| d.kind != DiagnosticKind::UnusedImport)
+            .cloned()
+            .collect();
+        let events = tracker.update(fixed);
+        assert_eq!(
+            events,
+            vec![DiagnosticEvent::Resolved(diagnostics[0].clone())]
+        );
+
+        // File became clean
+        let events = tracker.update(Vec::new());
+        assert_eq!(
+            events,
+            vec![DiagnosticEvent::Cleared {
+                file: "app/main.go".to_string()
+            }]
+        );
+        assert!(tracker.is_empty());
+
+        let json = serde_json::to_string(&DiagnosticEvent::Added(diagnostics[2].clone())).unwrap();
+        assert!(json.starts_with(r#"{"event":"added","file":"app/main.go""#));
+    }
+
+    #[test]
+    fn test_locals_shadowing_imports() {
+        let file = GoSourceFile::read(Path::new("tests/fixtures/go/scoping.go")).unwrap();
+        let line = |needle: &str| {
+            file.source
+                .lines()
+                .position(|l| l.contains(needle))
+                .unwrap() as u32
+                + 1
+        };
+        let shadows: Vec<_> = diagnostics_in_file(&file, &HashSet::new())
+            .into_iter()
+            .filter(|d| d.message.contains("shadows import"))
+            .map(|d| (d.line, d.message))
+            .collect();
+        let message = "declaration of \"config\" shadows import \"example.com/app/config\"";
+        // The parameter of ProcessData and the := in LoadSettings
+        assert!(shadows.contains(&(line("func ProcessData("), message.to_string())));
+        assert!(shadows.contains(&(line("config := Config{Name: path"), message.to_string())));
+    }
+
+    #[test]
+    fn test_methods_on_non_local_aliases() {
+        let code = r#"
+package handlers
+
+import "example.com/app/models"
+
+type User = models.User
+
+type InnerStruct struct{}
+
+type PublicInnerStruct = InnerStruct
+
+func (u *User) Greeting() string { return "hi" }
+
+func (p PublicInnerStruct) Name() string { return "inner" }
+"#;
+        let files = vec![parse("handlers/user.go", code)];
+        let diagnostics = find_diagnostics(&files);
+        let summary: Vec<_> = diagnostics
+            .iter()
+            .map(|d| (d.kind, d.line, d.column, d.message.as_str()))
+            .collect();
+        // Aliases of local types may have methods
+        assert_eq!(
+            summary,
+            vec![(
+                DiagnosticKind::InvalidReceiver,
+                12,
+                9,
+                "cannot define new methods on non-local type models.User"
+            )]
+        );
+    }
+}
@@ -0,0 +1,282 @@
+//! Top-level identifiers declared twice in one package
+//!
+//! Two files of a package declaring the same function, type, variable or
+//! constant don't compile (`X redeclared in this block`), and the index
+//! would otherwise keep only one of them. Both locations are reported:
+//!
+//! ```go
+//! // models/user.go
+//! func Validate(u *User) error
+//!
+//! // models/order.go
+//! func Validate(o *Order) error // duplicate of models/user.go
+//! ```
+//!
+//! Methods only collide with methods of the same receiver type, so
+//! `User.String` and `Order.String` are fine. `init` functions and blank
+//! identifiers may repeat. Files with build constraints (a `//go:build` line
+//! or a `_linux.go`-style name) are compiled for different targets and often
+//! declare the same names per platform, so they are left out.
+
+use super::{GoSourceFile, line_of, receiver_type_name, walk_tree};
+use crate::parsing::go::build_constraints::{KNOWN_ARCH, KNOWN_OS};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use std::path::Path;
+use tree_sitter::Node;
+
+/// A top-level identifier declared again in the same package
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct DuplicateDefinition {
+    /// Declared name; methods are qualified by their receiver type
+    pub name: String,
+    /// `func`, `method`, `type`, `var` or `const`
+    pub kind: &'static str,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the redeclaration
+    pub line: u32,
+    /// 1-based column of the redeclared name
+    pub column: u32,
+    /// Declaration that came first, in path order
+    pub other_file: String,
+    pub other_line: u32,
+}
+
+impl fmt::Display for DuplicateDefinition {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{} {} redeclared in package {} at {}:{} (other declaration at {}:{})",
+            self.kind,
+            self.name,
+            self.package,
+            self.file,
+            self.line,
+            self.other_file,
+            self.other_line
+        )
+    }
+}
+
+/// Find identifiers declared more than once per package, sorted by file and
+/// line
+///
+/// Files are grouped into packages by directory and package clause, as
+/// [`super::find_diagnostics`] does, so `models` and `models_test` are kept
+/// apart. Within a package the first declaration in path order is taken as
+/// the original.
+pub fn find_duplicate_definitions(files: &[GoSourceFile]) -> Vec<DuplicateDefinition> {
+    let mut packages: HashMap<(&Path, &str), Vec<&GoSourceFile>> = HashMap::new();
+    for file in files {
+        if has_build_constraint(file) {
+            continue;
+        }
+        let dir = file.path.parent().unwrap_or(Path::new(""));
+        let package = file.package_name().unwrap_or_default();
+        packages.entry((dir, package)).or_default().push(file);
+    }
+
+    let mut findings = Vec::new();
+    for ((_, package), mut package_files) in packages {
+        package_files.sort_by(|a, b| a.path.cmp(&b.path));
+        let mut first: HashMap<String, (String, u32)> = HashMap::new();
+        for file in package_files {
+            for (name, kind, node) in top_level_declarations(file) {
+                let line = line_of(node);
+                let Some((other_file, other_line)) = first.get(&name) else {
+                    first.insert(name, (file.display_path(), line));
+                    continue;
+                };
+                findings.push(DuplicateDefinition {
+                    name,
+                    kind,
+                    package: package.to_string(),
+                    file: file.display_path(),
+                    line,
+                    column: node.start_position().column as u32 + 1,
+                    other_file: other_file.clone(),
+                    other_line: *other_line,
+                });
+            }
+        }
+    }
+    findings.sort_by(|a, b| (&a.file, a.line, a.column).cmp(&(&b.file, b.line, b.column)));
+    findings
+}
+
+/// Names declared at package level as (name, kind, name node)
+///
+/// Methods are named `Receiver.Method`, which keeps them out of the
+/// namespace of functions and types.
+fn top_level_declarations<'t>(file: &'t GoSourceFile) -> Vec<(String, &'static str, Node<'t>)> {
+    let mut declarations = Vec::new();
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        match decl.kind() {
+            "function_declaration" => {
+                if let Some(name) = decl.child_by_field_name("name") {
+                    if file.text(name) != "init" {
+                        declarations.push((file.text(name).to_string(), "func", name));
+                    }
+                }
+            }
+            "method_declaration" => {
+                if let (Some(name), Some(receiver)) = (
+                    decl.child_by_field_name("name"),
+                    receiver_type_name(file, decl),
+                ) {
+                    declarations.push((format!("{receiver}.{}", file.text(name)), "method", name));
+                }
+            }
+            "type_declaration" | "var_declaration" | "const_declaration" => {
+                let kind = match decl.kind() {
+                    "type_declaration" => "type",
+                    "var_declaration" => "var",
+                    _ => "const",
+                };
+                walk_tree(decl, &mut |node| {
+                    if matches!(
+                        node.kind(),
+                        "type_spec" | "type_alias" | "var_spec" | "const_spec"
+                    ) {
+                        for name in node.children_by_field_name("name", &mut node.walk()) {
+                            declarations.push((file.text(name).to_string(), kind, name));
+                        }
+                    }
+                });
+            }
+            _ => {}
+        }
+    }
+    declarations.retain(|(name, _, _)| name != "_");
+    declarations
+}
+
+/// Whether `file` is only compiled for some targets
+///
+/// Looks for `//go:build` and `// +build` lines before the package clause
+/// and for `_GOOS`, `_GOARCH` or `_GOOS_GOARCH` file name suffixes.
+fn has_build_constraint(file: &GoSourceFile) -> bool {
+    let root = file.root();
+    for node in root.children(&mut root.walk()) {
+        match node.kind() {
+            "comment" => {
+                let text = file.text(node);
+                if text.starts_with("//go:build") || text.starts_with("// +build") {
+                    return true;
+                }
+            }
+            _ => break,
+        }
+    }
+
+    let stem = file
+        .path
+        .file_stem()
+        .and_then(|s| s.to_str())
+        .unwrap_or_default();
+    let stem = stem.strip_suffix("_test").unwrap_or(stem);
+    let mut parts = stem.rsplit('_');
+    let (Some(last), Some(_)) = (parts.next(), parts.clone().next()) else {
+        return false;
+    };
+    KNOWN_OS.contains(&last) || KNOWN_ARCH.contains(&last)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_duplicates_across_package_files() {
+        let user = r#"
+package models
+
+type User struct{}
+
+func (u User) String() string { return "user" }
+
+func Validate(u *User) error { return nil }
+
+func init() {}
+
+var _ = Validate
+"#;
+        let order = r#"
+package models
+
+type Order struct{}
+
+func (o Order) String() string { return "order" }
+
+func Validate(o *Order) error { return nil }
+
+func init() {}
+
+var _ = Validate
+
+const User = "user"
+"#;
+        let tests = r#"
+package models_test
+
+func Validate() {}
+"#;
+        let linux = r#"
+package models
+
+func Validate(u *User) error { return nil }
+"#;
+        let windows = r#"
+//go:build windows
+
+package models
+
+func Validate(u *User) error { return nil }
+"#;
+        let files = vec![
+            parse("models/user.go", user),
+            parse("models/order.go", order),
+            parse("models/models_test.go", tests),
+            parse("models/validate_linux.go", linux),
+            parse("models/validate.go", windows),
+        ];
+        let findings = find_duplicate_definitions(&files);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|d| {
+                (
+                    d.kind,
+                    d.name.as_str(),
+                    d.file.as_str(),
+                    d.line,
+                    d.other_file.as_str(),
+                    d.other_line,
+                )
+            })
+            .collect();
+        // order.go sorts first, so user.go holds the redeclarations
+        assert_eq!(
+            summary,
+            vec![
+                ("type", "User", "models/user.go", 4, "models/order.go", 14),
+                (
+                    "func",
+                    "Validate",
+                    "models/user.go",
+                    8,
+                    "models/order.go",
+                    8
+                ),
+            ]
+        );
+        assert_eq!(findings[1].column, 6);
+    }
+}
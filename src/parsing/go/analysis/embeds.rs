@@ -0,0 +1,169 @@
+//! Type embedding: the composition hierarchy of structs and interfaces
+//!
+//! ```go
+//! type Application struct {
+//!     *WorkerPool
+//!     config *Config
+//! }
+//!
+//! type ReadWriteCloser interface {
+//!     Reader
+//!     Writer
+//!     io.Closer
+//! }
+//! ```
+//!
+//! gives the edges `Application -> *WorkerPool` and `ReadWriteCloser ->
+//! Reader`, `Writer`, `io.Closer`. Embedded types are keyed as
+//! [`type_key`] keys them, so edges chain across files of a package and
+//! into imported packages. Type-set constraints such as `~int | ~string`
+//! are not embeddings.
+
+use super::{GoSourceFile, line_of, type_key, walk_tree};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// A type embedded in a struct or interface
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EmbedEdge {
+    /// Embedding type, as `package.Name`
+    pub owner: String,
+    /// Embedded type, as `package.Name`
+    pub embedded: String,
+    /// Embedded type as written, e.g. `*WorkerPool` or `*Map[string, T]`
+    pub written: String,
+    /// Whether the embedding type is an interface
+    pub interface: bool,
+    pub file: String,
+    /// 1-based line of the embedded field or element
+    pub line: u32,
+}
+
+impl fmt::Display for EmbedEdge {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{} embeds {} at {}:{}",
+            self.owner, self.written, self.file, self.line
+        )
+    }
+}
+
+/// Find every embedding in `files`, sorted by file and line
+pub fn find_embeds(files: &[GoSourceFile]) -> Vec<EmbedEdge> {
+    let mut edges = Vec::new();
+    for file in files {
+        let Some(package) = file.package_name() else {
+            continue;
+        };
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "type_spec" {
+                return;
+            }
+            let (Some(name), Some(body)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type"),
+            ) else {
+                return;
+            };
+            let owner = format!("{package}.{}", file.text(name));
+            for (embedded, written) in embedded_types(file, body, &aliases) {
+                edges.push(EmbedEdge {
+                    owner: owner.clone(),
+                    embedded,
+                    written: file.text(written).to_string(),
+                    interface: body.kind() == "interface_type",
+                    file: file.display_path(),
+                    line: line_of(written),
+                });
+            }
+        });
+    }
+    edges.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    edges
+}
+
+/// Keys and nodes of the types a struct or interface type embeds
+///
+/// `io.Writer`, `*Base` and `Base[T]` key as `io.Writer` and
+/// `package.Base`. Only direct fields count: a nested anonymous struct's
+/// embeddings are its own.
+pub(super) fn embedded_types<'t>(
+    file: &GoSourceFile,
+    body: Node<'t>,
+    aliases: &HashMap<String, String>,
+) -> Vec<(String, Node<'t>)> {
+    embedded_types_keyed(body, |t| type_key(file, t, aliases))
+}
+
+/// [`embedded_types`], keying each embedded type with `key_of`
+pub(super) fn embedded_types_keyed<'t>(
+    body: Node<'t>,
+    key_of: impl Fn(Node) -> Option<String>,
+) -> Vec<(String, Node<'t>)> {
+    let candidates: Vec<Node<'t>> = match body.kind() {
+        "struct_type" => body
+            .named_children(&mut body.walk())
+            .filter(|n| n.kind() == "field_declaration_list")
+            .flat_map(|list| list.named_children(&mut list.walk()).collect::<Vec<_>>())
+            .filter(|field| {
+                field.kind() == "field_declaration" && field.child_by_field_name("name").is_none()
+            })
+            .filter_map(|field| field.child_by_field_name("type"))
+            .collect(),
+        "interface_type" => body
+            .named_children(&mut body.walk())
+            .filter(|e| e.kind() == "type_elem" && e.named_child_count() == 1)
+            .filter_map(|e| e.named_child(0))
+            .collect(),
+        _ => Vec::new(),
+    };
+
+    let mut embedded = Vec::new();
+    for written in candidates {
+        let mut typ = Some(written);
+        while let Some(inner) = typ {
+            typ = match inner.kind() {
+                "pointer_type" => inner.named_child(0),
+                "generic_type" => inner.child_by_field_name("type"),
+                _ => break,
+            };
+        }
+        if let Some(key) = typ.and_then(&key_of) {
+            embedded.push((key, written));
+        }
+    }
+    embedded
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::path::Path;
+
+    #[test]
+    fn test_embeds_in_fixtures() {
+        let files: Vec<_> = ["structs.go", "complex.go", "generics.go", "interfaces.go"]
+            .iter()
+            .map(|name| GoSourceFile::read(&Path::new("tests/fixtures/go").join(name)).unwrap())
+            .collect();
+        let edges: Vec<_> = find_embeds(&files)
+            .iter()
+            .map(|e| format!("{} -> {}", e.owner, e.written))
+            .collect();
+        for expected in [
+            "structs.Person -> User",
+            "complex.Application -> *WorkerPool",
+            "generics.Repository -> *Map[string, T]",
+            "interfaces.ReadWriteCloser -> io.Closer",
+        ] {
+            assert!(
+                edges.iter().any(|e| e == expected),
+                "missing {expected}: {edges:?}"
+            );
+        }
+    }
+}
@@ -0,0 +1,369 @@
+//! Integer literals compared against enum-typed values
+//!
+//! Flags magic numbers standing in for enum constants:
+//!
+//! ```go
+//! switch userErr.Code {
+//! case 3: // models.ErrUserNotFound
+//! ```
+//!
+//! The compared expression is typed from the enclosing function's parameters
+//! and variables, following struct fields (`userErr.Code`) declared in any
+//! analyzed package. Each literal is matched against the constant values of
+//! the enum (see [`EnumTable`]); the matching constant is reported as the
+//! reference the literal stands for, and literals no constant has are
+//! reported as mismatches. Indexing stores each match as a reference from
+//! the enclosing function to the constant.
+
+use super::enums::{EnumTable, parse_int_literal};
+use super::{
+    GoSourceFile, base_type_name, enclosing_function_name, is_suppressed, line_of, walk_tree,
+};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "enum-literals";
+
+/// An integer literal compared with a value of an enum type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EnumLiteralComparison {
+    /// Compared expression, as written
+    pub expression: String,
+    pub literal: i64,
+    /// Enum type of the expression, qualified by package
+    pub enum_type: String,
+    /// Constant with the literal's value, qualified by package; `None` when
+    /// no constant of the enum has that value
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub constant: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the literal
+    pub line: u32,
+}
+
+impl fmt::Display for EnumLiteralComparison {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match &self.constant {
+            Some(constant) => write!(
+                f,
+                "{} compared with {}, use {constant}",
+                self.expression, self.literal
+            )?,
+            None => write!(
+                f,
+                "{} compared with {}, no {} constant has this value",
+                self.expression, self.literal, self.enum_type
+            )?,
+        }
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// A type as (package, name)
+pub(super) type TypeRef = (String, String);
+
+/// Find enum comparisons against integer literals, sorted by file and line
+pub fn find_enum_literal_comparisons(
+    files: &[GoSourceFile],
+    enums: &EnumTable,
+) -> Vec<EnumLiteralComparison> {
+    let fields = struct_field_types(files);
+    let mut findings: Vec<_> = files
+        .iter()
+        .flat_map(|file| enum_literals_in_file(file, enums, &fields))
+        .collect();
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+fn enum_literals_in_file(
+    file: &GoSourceFile,
+    enums: &EnumTable,
+    fields: &HashMap<TypeRef, HashMap<String, TypeRef>>,
+) -> Vec<EnumLiteralComparison> {
+    let mut findings = Vec::new();
+    let aliases = file.import_aliases();
+
+    walk_tree(file.root(), &mut |node| {
+        if !matches!(node.kind(), "function_declaration" | "method_declaration") {
+            return;
+        }
+        let variables = variable_types(file, node, &aliases);
+
+        let mut comparisons: Vec<(Node, Node)> = Vec::new();
+        walk_tree(node, &mut |inner| match inner.kind() {
+            "binary_expression" => {
+                let is_comparison = inner.child_by_field_name("operator").is_some_and(|op| {
+                    matches!(file.text(op), "==" | "!=" | "<" | "<=" | ">" | ">=")
+                });
+                if let (true, Some(left), Some(right)) = (
+                    is_comparison,
+                    inner.child_by_field_name("left"),
+                    inner.child_by_field_name("right"),
+                ) {
+                    comparisons.push((left, right));
+                    comparisons.push((right, left));
+                }
+            }
+            "expression_switch_statement" => {
+                let Some(value) = inner.child_by_field_name("value") else {
+                    return;
+                };
+                for case in inner.named_children(&mut inner.walk()) {
+                    if case.kind() != "expression_case" {
+                        continue;
+                    }
+                    if let Some(values) = case.child_by_field_name("value") {
+                        for literal in values.named_children(&mut values.walk()) {
+                            comparisons.push((value, literal));
+                        }
+                    }
+                }
+            }
+            _ => {}
+        });
+
+        for (expr, literal) in comparisons {
+            if literal.kind() != "int_literal" {
+                continue;
+            }
+            let enum_type = expression_type(file, expr, &variables, fields)
+                .and_then(|(package, name)| enums.get(&package, &name));
+            let (Some(enum_type), Some(value)) = (enum_type, parse_int_literal(file.text(literal)))
+            else {
+                continue;
+            };
+            let line = line_of(literal);
+            if is_suppressed(file, line, ANALYSIS_NAME) {
+                continue;
+            }
+            findings.push(EnumLiteralComparison {
+                expression: file.text(expr).to_string(),
+                literal: value,
+                enum_type: format!("{}.{}", enum_type.package, enum_type.name),
+                constant: enum_type
+                    .constant_with_value(value)
+                    .map(|c| format!("{}.{}", enum_type.package, c.name)),
+                function: enclosing_function_name(file, literal),
+                file: file.display_path(),
+                line,
+            });
+        }
+    });
+
+    findings
+}
+
+/// Field types of every struct, keyed by the struct's (package, name)
+pub(super) fn struct_field_types(
+    files: &[GoSourceFile],
+) -> HashMap<TypeRef, HashMap<String, TypeRef>> {
+    let mut structs: HashMap<TypeRef, HashMap<String, TypeRef>> = HashMap::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "type_spec" {
+                return;
+            }
+            let (Some(name), Some(body)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type"),
+            ) else {
+                return;
+            };
+            if body.kind() != "struct_type" {
+                return;
+            }
+            let entry = structs
+                .entry((package.to_string(), file.text(name).to_string()))
+                .or_default();
+            walk_tree(body, &mut |field| {
+                if field.kind() != "field_declaration" {
+                    return;
+                }
+                let Some(field_type) = field
+                    .child_by_field_name("type")
+                    .and_then(|t| type_ref(file, t, &aliases))
+                else {
+                    return;
+                };
+                for field_name in field.children_by_field_name("name", &mut field.walk()) {
+                    entry.insert(file.text(field_name).to_string(), field_type.clone());
+                }
+            });
+        });
+    }
+    structs
+}
+
+/// Types of the parameters and typed variables of a function
+pub(super) fn variable_types(
+    file: &GoSourceFile,
+    function: Node,
+    aliases: &HashMap<String, String>,
+) -> HashMap<String, TypeRef> {
+    let mut variables = HashMap::new();
+    walk_tree(function, &mut |node| match node.kind() {
+        "parameter_declaration" | "var_spec" => {
+            let Some(typ) = node
+                .child_by_field_name("type")
+                .and_then(|t| type_ref(file, t, aliases))
+            else {
+                return;
+            };
+            for name in node.children_by_field_name("name", &mut node.walk()) {
+                variables.insert(file.text(name).to_string(), typ.clone());
+            }
+        }
+        "short_var_declaration" => {
+            let (Some(left), Some(right)) = (
+                node.child_by_field_name("left"),
+                node.child_by_field_name("right"),
+            ) else {
+                return;
+            };
+            let names = left.named_children(&mut left.walk()).collect::<Vec<_>>();
+            let values = right.named_children(&mut right.walk()).collect::<Vec<_>>();
+            for (name, value) in names.into_iter().zip(values) {
+                let value = match value.kind() {
+                    "unary_expression" => value.child_by_field_name("operand").unwrap_or(value),
+                    _ => value,
+                };
+                if value.kind() != "composite_literal" {
+                    continue;
+                }
+                if let Some(typ) = value
+                    .child_by_field_name("type")
+                    .and_then(|t| type_ref(file, t, aliases))
+                {
+                    variables.insert(file.text(name).to_string(), typ);
+                }
+            }
+        }
+        _ => {}
+    });
+    variables
+}
+
+/// Package and name of a type expression; pointers are looked through
+fn type_ref(file: &GoSourceFile, node: Node, aliases: &HashMap<String, String>) -> Option<TypeRef> {
+    match node.kind() {
+        "pointer_type" | "parenthesized_type" => type_ref(file, node.named_child(0)?, aliases),
+        "qualified_type" => {
+            let local = file.text(node.child_by_field_name("package")?);
+            let path = aliases.get(local)?;
+            let package = path.rsplit('/').next().unwrap_or(path);
+            let name = file.text(node.child_by_field_name("name")?);
+            Some((package.to_string(), name.to_string()))
+        }
+        _ => {
+            let name = base_type_name(file, node)?;
+            Some((file.package_name()?.to_string(), name.to_string()))
+        }
+    }
+}
+
+/// Type of a variable or a field selected from one
+pub(super) fn expression_type(
+    file: &GoSourceFile,
+    expr: Node,
+    variables: &HashMap<String, TypeRef>,
+    fields: &HashMap<TypeRef, HashMap<String, TypeRef>>,
+) -> Option<TypeRef> {
+    match expr.kind() {
+        "identifier" => variables.get(file.text(expr)).cloned(),
+        "parenthesized_expression" => {
+            expression_type(file, expr.named_child(0)?, variables, fields)
+        }
+        "selector_expression" => {
+            let owner = expression_type(
+                file,
+                expr.child_by_field_name("operand")?,
+                variables,
+                fields,
+            )?;
+            let field = file.text(expr.child_by_field_name("field")?);
+            fields.get(&owner)?.get(field).cloned()
+        }
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_enum_literals_resolve_to_constants() {
+        let models = r#"
+package models
+
+type UserErrorCode int
+
+const (
+    ErrInvalidName UserErrorCode = iota
+    ErrInvalidEmail
+    ErrInvalidRole
+    ErrUserNotFound
+    ErrDuplicateEmail
+)
+
+type UserError struct {
+    Code    UserErrorCode
+    Message string
+}
+"#;
+        let auth = r#"
+package services
+
+import "example.com/app/models"
+
+func (e *AuthError) FromUserError(userErr *models.UserError) *AuthError {
+    switch userErr.Code {
+    case 3: // models.ErrUserNotFound
+        return nil
+    case models.ErrDuplicateEmail:
+        return nil
+    }
+    if userErr.Code == 9 {
+        return nil
+    }
+    count := 3
+    if count == 3 {
+        return nil
+    }
+    return e
+}
+"#;
+        let files = vec![
+            GoSourceFile::parse("models/user.go", models.to_string()).unwrap(),
+            GoSourceFile::parse("services/auth.go", auth.to_string()).unwrap(),
+        ];
+        let enums = EnumTable::build(&files);
+        let findings = find_enum_literal_comparisons(&files, &enums);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.literal, f.constant.as_deref(), f.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![(3, Some("models.ErrUserNotFound"), 8), (9, None, 13)]
+        );
+        assert_eq!(findings[0].expression, "userErr.Code");
+        assert_eq!(findings[0].enum_type, "models.UserErrorCode");
+        assert_eq!(
+            findings[0].function.as_deref(),
+            Some("AuthError.FromUserError")
+        );
+    }
+}
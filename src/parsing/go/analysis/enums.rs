@@ -0,0 +1,413 @@
+//! Enum-like constant groups
+//!
+//! Go has no enum declaration; the idiom is a defined type plus a block of
+//! typed constants, usually numbered with `iota`:
+//!
+//! ```go
+//! type UserRole int
+//!
+//! const (
+//!     RoleGuest UserRole = iota
+//!     RoleUser
+//!     RoleAdmin
+//! )
+//! ```
+//!
+//! [`EnumTable`] groups such constants by (package, type) and computes their
+//! integer values where the expressions are simple: `iota`, integer literals,
+//! arithmetic and shifts, conversions and earlier constants of the block.
+//! Only types declared in the same package count, so `const Port int = 80`
+//! doesn't make `int` an enum.
+
+use super::{GoSourceFile, base_type_name, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// A constant of an enum type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EnumConstant {
+    pub name: String,
+    /// Integer value, when it could be computed
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub value: Option<i64>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+/// A defined type together with the constants declared with it
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EnumType {
+    pub package: String,
+    pub name: String,
+    /// Constants in value order; constants of unknown value come last
+    pub constants: Vec<EnumConstant>,
+}
+
+impl EnumType {
+    /// The first constant whose value is `value`
+    pub fn constant_with_value(&self, value: i64) -> Option<&EnumConstant> {
+        self.constants.iter().find(|c| c.value == Some(value))
+    }
+
+    /// Whether `name` is one of the constants
+    pub fn has_constant(&self, name: &str) -> bool {
+        self.constants.iter().any(|c| c.name == name)
+    }
+}
+
+impl fmt::Display for EnumType {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}.{}", self.package, self.name)?;
+        for constant in &self.constants {
+            match constant.value {
+                Some(value) => write!(f, "\n  {} = {value}", constant.name)?,
+                None => write!(f, "\n  {}", constant.name)?,
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Enum types keyed by (package, type)
+#[derive(Debug, Default)]
+pub struct EnumTable {
+    enums: BTreeMap<(String, String), EnumType>,
+}
+
+impl EnumTable {
+    /// Collect the enum types declared in `files`
+    pub fn build(files: &[GoSourceFile]) -> Self {
+        let mut defined: HashSet<(String, String)> = HashSet::new();
+        for file in files {
+            let package = file.package_name().unwrap_or_default();
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() == "type_spec" {
+                    if let Some(name) = node.child_by_field_name("name") {
+                        defined.insert((package.to_string(), file.text(name).to_string()));
+                    }
+                }
+            });
+        }
+
+        let mut table = Self::default();
+        for file in files {
+            let package = file.package_name().unwrap_or_default().to_string();
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() != "const_declaration" {
+                    return;
+                }
+                for (type_name, constant) in typed_constants(file, node) {
+                    let key = (package.clone(), type_name);
+                    if !defined.contains(&key) {
+                        continue;
+                    }
+                    table
+                        .enums
+                        .entry(key.clone())
+                        .or_insert_with(|| EnumType {
+                            package: key.0,
+                            name: key.1,
+                            constants: Vec::new(),
+                        })
+                        .constants
+                        .push(constant);
+                }
+            });
+        }
+
+        for enum_type in table.enums.values_mut() {
+            // Stable sort keeps declaration order among equal or unknown values
+            enum_type
+                .constants
+                .sort_by_key(|c| (c.value.is_none(), c.value));
+        }
+        table
+    }
+
+    /// The enum `type_name` declared in `package`
+    pub fn get(&self, package: &str, type_name: &str) -> Option<&EnumType> {
+        self.enums
+            .get(&(package.to_string(), type_name.to_string()))
+    }
+
+    /// Enums named `type_name` in any package
+    pub fn find(&self, type_name: &str) -> Vec<&EnumType> {
+        self.enums
+            .values()
+            .filter(|e| e.name == type_name)
+            .collect()
+    }
+
+    /// The enum a constant belongs to
+    pub fn enum_of_constant(&self, package: &str, constant: &str) -> Option<&EnumType> {
+        self.enums
+            .values()
+            .find(|e| e.package == package && e.has_constant(constant))
+    }
+
+    pub fn iter(&self) -> impl Iterator<Item = &EnumType> {
+        self.enums.values()
+    }
+
+    pub fn len(&self) -> usize {
+        self.enums.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.enums.is_empty()
+    }
+}
+
+/// A constant of a `const` declaration with the type and value it gets
+#[derive(Debug, Clone, Copy)]
+pub struct ConstValue<'t> {
+    pub name: Node<'t>,
+    /// Declared type, repeated from an earlier spec; `None` when untyped
+    pub type_node: Option<Node<'t>>,
+    /// Integer value, when it could be computed
+    pub value: Option<i64>,
+}
+
+/// Constants of a `const` declaration in order, blank ones included
+///
+/// Specs without a value repeat the type and expressions of the previous
+/// spec, with `iota` advanced to their position in the block: after
+/// `RoleGuest UserRole = iota`, a bare `RoleUser` is a `UserRole` of value
+/// 1. A spec with a value but no type starts an untyped run.
+pub fn const_values<'t>(declaration: Node<'t>, code: &str) -> Vec<ConstValue<'t>> {
+    let mut constants = Vec::new();
+    let mut known: HashMap<&str, i64> = HashMap::new();
+    let mut type_node: Option<Node<'t>> = None;
+    let mut exprs: Vec<Node<'t>> = Vec::new();
+
+    let specs = declaration
+        .named_children(&mut declaration.walk())
+        .filter(|n| n.kind() == "const_spec")
+        .collect::<Vec<_>>();
+    for (iota, spec) in specs.into_iter().enumerate() {
+        if let Some(values) = spec.child_by_field_name("value") {
+            type_node = spec.child_by_field_name("type");
+            exprs = values.named_children(&mut values.walk()).collect();
+        }
+        for (index, name) in spec
+            .children_by_field_name("name", &mut spec.walk())
+            .enumerate()
+        {
+            let value = exprs
+                .get(index)
+                .and_then(|expr| eval_int(code, *expr, iota as i64, &known));
+            if let Some(value) = value {
+                known.insert(&code[name.byte_range()], value);
+            }
+            constants.push(ConstValue {
+                name,
+                type_node,
+                value,
+            });
+        }
+    }
+    constants
+}
+
+/// Typed constants of a `const` declaration with their type names
+fn typed_constants(file: &GoSourceFile, declaration: Node) -> Vec<(String, EnumConstant)> {
+    const_values(declaration, &file.source)
+        .into_iter()
+        .filter_map(|constant| {
+            let name = file.text(constant.name);
+            let type_name = base_type_name(file, constant.type_node?)?;
+            (name != "_").then(|| {
+                (
+                    type_name.to_string(),
+                    EnumConstant {
+                        name: name.to_string(),
+                        value: constant.value,
+                        file: file.display_path(),
+                        line: line_of(constant.name),
+                    },
+                )
+            })
+        })
+        .collect()
+}
+
+/// Integer value of a constant expression
+fn eval_int(code: &str, node: Node, iota: i64, known: &HashMap<&str, i64>) -> Option<i64> {
+    let text = |node: Node| &code[node.byte_range()];
+    match node.kind() {
+        "int_literal" => parse_int_literal(text(node)),
+        "identifier" => match text(node) {
+            "iota" => Some(iota),
+            name => known.get(name).copied(),
+        },
+        "parenthesized_expression" => eval_int(code, node.named_child(0)?, iota, known),
+        "unary_expression" => {
+            let operand = eval_int(code, node.child_by_field_name("operand")?, iota, known)?;
+            match text(node.child_by_field_name("operator")?) {
+                "-" => operand.checked_neg(),
+                "+" => Some(operand),
+                "^" => Some(!operand),
+                _ => None,
+            }
+        }
+        "binary_expression" => {
+            let left = eval_int(code, node.child_by_field_name("left")?, iota, known)?;
+            let right = eval_int(code, node.child_by_field_name("right")?, iota, known)?;
+            match text(node.child_by_field_name("operator")?) {
+                "+" => left.checked_add(right),
+                "-" => left.checked_sub(right),
+                "*" => left.checked_mul(right),
+                "/" => left.checked_div(right),
+                "%" => left.checked_rem(right),
+                "<<" => u32::try_from(right).ok().and_then(|r| left.checked_shl(r)),
+                ">>" => u32::try_from(right).ok().and_then(|r| left.checked_shr(r)),
+                "&" => Some(left & right),
+                "|" => Some(left | right),
+                "^" => Some(left ^ right),
+                "&^" => Some(left & !right),
+                _ => None,
+            }
+        }
+        // Conversions such as `UserRole(2)`
+        "call_expression" => {
+            let arguments = node.child_by_field_name("arguments")?;
+            if arguments.named_child_count() != 1 {
+                return None;
+            }
+            eval_int(code, arguments.named_child(0)?, iota, known)
+        }
+        _ => None,
+    }
+}
+
+/// Value of a Go integer literal: decimal, `0x`, `0o`, `0b` or legacy octal
+pub fn parse_int_literal(text: &str) -> Option<i64> {
+    let digits = text.replace('_', "");
+    let lower = digits.to_ascii_lowercase();
+    if let Some(hex) = lower.strip_prefix("0x") {
+        i64::from_str_radix(hex, 16).ok()
+    } else if let Some(octal) = lower.strip_prefix("0o") {
+        i64::from_str_radix(octal, 8).ok()
+    } else if let Some(binary) = lower.strip_prefix("0b") {
+        i64::from_str_radix(binary, 2).ok()
+    } else if lower.len() > 1 && lower.starts_with('0') {
+        i64::from_str_radix(&lower[1..], 8).ok()
+    } else {
+        lower.parse().ok()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_enum_constants_and_iota_values() {
+        let code = r#"
+package models
+
+type UserRole int
+
+const (
+    RoleGuest UserRole = iota
+    RoleUser
+    RoleAdmin
+)
+
+type Flag uint
+
+const (
+    _ Flag = 1 << iota
+    FlagRead
+    FlagWrite
+    FlagAll = FlagRead | FlagWrite
+)
+
+const (
+    Fallback = UserRole(-1)
+    Port int = 8080
+)
+"#;
+        let files = vec![GoSourceFile::parse("models/user.go", code.to_string()).unwrap()];
+        let table = EnumTable::build(&files);
+
+        let values = |type_name: &str| {
+            table
+                .get("models", type_name)
+                .unwrap()
+                .constants
+                .iter()
+                .map(|c| (c.name.as_str(), c.value))
+                .collect::<Vec<_>>()
+        };
+        assert_eq!(
+            values("UserRole"),
+            vec![
+                ("RoleGuest", Some(0)),
+                ("RoleUser", Some(1)),
+                ("RoleAdmin", Some(2))
+            ]
+        );
+        // The blank constant is skipped but still advances iota; FlagAll is
+        // untyped, so it is not part of the enum
+        assert_eq!(
+            values("Flag"),
+            vec![("FlagRead", Some(2)), ("FlagWrite", Some(4))]
+        );
+        // Builtin types are never enums
+        assert!(table.get("models", "int").is_none());
+        assert_eq!(
+            table
+                .enum_of_constant("models", "RoleUser")
+                .map(|e| e.name.as_str()),
+            Some("UserRole")
+        );
+        assert_eq!(parse_int_literal("0x1F"), Some(31));
+        assert_eq!(parse_int_literal("0755"), Some(493));
+        assert_eq!(parse_int_literal("1_000"), Some(1000));
+    }
+
+    #[test]
+    fn test_typed_run_in_mixed_iota_block() {
+        let code = r#"
+package fs
+
+type Kind int
+
+const (
+    KindNone = iota
+    KindFile Kind = iota + 1
+    KindDir
+    _
+    KindLink
+    Limit = 10
+    Ceiling
+)
+"#;
+        let files = vec![GoSourceFile::parse("fs/kind.go", code.to_string()).unwrap()];
+        let table = EnumTable::build(&files);
+
+        // The untyped KindNone, Limit and Ceiling are not Kinds
+        let kind = table.get("fs", "Kind").unwrap();
+        let values: Vec<_> = kind
+            .constants
+            .iter()
+            .map(|c| (c.name.as_str(), c.value))
+            .collect();
+        assert_eq!(
+            values,
+            vec![
+                ("KindFile", Some(2)),
+                ("KindDir", Some(3)),
+                ("KindLink", Some(5))
+            ]
+        );
+        assert_eq!(
+            kind.to_string(),
+            "fs.Kind\n  KindFile = 2\n  KindDir = 3\n  KindLink = 5"
+        );
+    }
+}
@@ -0,0 +1,142 @@
+//! Environment variables read through `os.Getenv` and `os.LookupEnv`
+//!
+//! Keys built from string constants (`os.Getenv(EnvPrefix + "PORT")`) are
+//! folded to their final value using the [`ConstantTable`]. Keys that can't be
+//! folded, such as function parameters, are reported as dynamic with the key
+//! expression as written.
+
+use super::{ConstantTable, GoSourceFile, enclosing_function_name, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+
+/// Functions of the `os` package that read an environment variable
+const ENV_ACCESSORS: &[&str] = &["Getenv", "LookupEnv"];
+
+/// A single environment variable read
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EnvVarRead {
+    /// Variable name, or the key expression when `dynamic`
+    pub name: String,
+    /// The key couldn't be resolved to a constant string
+    pub dynamic: bool,
+    /// Accessor used, e.g. `os.Getenv`
+    pub accessor: String,
+    /// Enclosing function, if any
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line number
+    pub line: u32,
+}
+
+impl fmt::Display for EnvVarRead {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        if self.dynamic {
+            write!(f, "<dynamic: {}>", self.name)?;
+        } else {
+            write!(f, "{}", self.name)?;
+        }
+        write!(f, "  {}", self.accessor)?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find every environment variable read in `files`, sorted by name
+pub fn find_env_var_reads(files: &[GoSourceFile]) -> Vec<EnvVarRead> {
+    let constants = ConstantTable::build(files);
+    let mut reads: Vec<EnvVarRead> = files
+        .iter()
+        .flat_map(|file| env_var_reads_in_file(file, &constants))
+        .collect();
+    reads.sort_by(|a, b| {
+        (a.dynamic, &a.name, &a.file, a.line).cmp(&(b.dynamic, &b.name, &b.file, b.line))
+    });
+    reads
+}
+
+/// Environment variable reads in a single file, in source order
+pub fn env_var_reads_in_file(file: &GoSourceFile, constants: &ConstantTable) -> Vec<EnvVarRead> {
+    let aliases = file.import_aliases();
+    let mut reads = Vec::new();
+
+    walk_tree(file.root(), &mut |node| {
+        let Some((package, function)) = super::selector_call_parts(file, node) else {
+            return;
+        };
+        if aliases.get(package).map(String::as_str) != Some("os")
+            || !ENV_ACCESSORS.contains(&function)
+        {
+            return;
+        }
+        let Some(key) = node
+            .child_by_field_name("arguments")
+            .and_then(|args| args.named_child(0))
+        else {
+            return;
+        };
+
+        let (name, dynamic) = match constants.eval_string(file, key) {
+            Some(value) => (value, false),
+            None => (file.text(key).to_string(), true),
+        };
+        reads.push(EnvVarRead {
+            name,
+            dynamic,
+            accessor: format!("os.{function}"),
+            function: enclosing_function_name(file, node),
+            file: file.display_path(),
+            line: line_of(node),
+        });
+    });
+
+    reads
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_env_var_reads_with_constant_prefix() {
+        let code = r#"
+package config
+
+import "os"
+
+const EnvPrefix = "APP_"
+
+func Load() {
+    host := os.Getenv(EnvPrefix + "SERVER_HOST")
+    _, ok := os.LookupEnv("HOME")
+    _ = host
+    _ = ok
+}
+
+func getEnvOrDefault(key, fallback string) string {
+    if value := os.Getenv(key); value != "" {
+        return value
+    }
+    return fallback
+}
+"#;
+        let files = vec![GoSourceFile::parse("settings.go", code.to_string()).unwrap()];
+        let reads = find_env_var_reads(&files);
+
+        let summary: Vec<_> = reads
+            .iter()
+            .map(|r| (r.name.as_str(), r.dynamic, r.accessor.as_str(), r.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("APP_SERVER_HOST", false, "os.Getenv", 9),
+                ("HOME", false, "os.LookupEnv", 10),
+                ("key", true, "os.Getenv", 16),
+            ]
+        );
+        assert_eq!(reads[2].function.as_deref(), Some("getEnvOrDefault"));
+    }
+}
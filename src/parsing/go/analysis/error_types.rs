@@ -0,0 +1,218 @@
+//! Types implementing the built-in `error` interface
+//!
+//! A type is an error type when its method set contains `Error() string`.
+//! Method sets are registered with [`GoInheritanceResolver`] and checked
+//! against `error` from its table of standard library interfaces, as the
+//! indexer does for `implements` relationships.
+//!
+//! Error types in this codebase usually carry a `Code` field of an enum type
+//! (`ConfigErrorCode`, `UserErrorCode`, ...); when present, that enum and its
+//! constants are reported with the type.
+
+use super::enums::EnumTable;
+use super::{GoSourceFile, base_type_name, line_of, receiver_type_name, walk_tree};
+use crate::parsing::InheritanceResolver;
+use crate::parsing::go::GoInheritanceResolver;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// The error code enum carried by an error type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ErrorCodeEnum {
+    /// Field holding the code, usually `Code`
+    pub field: String,
+    /// Enum type of the field
+    pub name: String,
+    /// Constants of the enum in value order
+    pub constants: Vec<String>,
+}
+
+/// A type satisfying `error`
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ErrorType {
+    pub name: String,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the type declaration
+    pub line: u32,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub code_enum: Option<ErrorCodeEnum>,
+}
+
+impl fmt::Display for ErrorType {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} at {}:{}",
+            self.package, self.name, self.file, self.line
+        )?;
+        if let Some(codes) = &self.code_enum {
+            write!(
+                f,
+                "\n  {} {}: {}",
+                codes.field,
+                codes.name,
+                codes.constants.join(", ")
+            )?;
+        }
+        Ok(())
+    }
+}
+
+/// Find the error types declared in `files`, sorted by package and name
+pub fn find_error_types(files: &[GoSourceFile], enums: &EnumTable) -> Vec<ErrorType> {
+    // Method names per (package, receiver type). `Error` only counts with
+    // the `Error() string` signature of the interface.
+    let mut method_sets: BTreeMap<(String, String), Vec<String>> = BTreeMap::new();
+    let mut declarations: BTreeMap<(String, String), (&GoSourceFile, Node)> = BTreeMap::new();
+
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        walk_tree(file.root(), &mut |node| match node.kind() {
+            "method_declaration" => {
+                let (Some(receiver), Some(name)) = (
+                    receiver_type_name(file, node),
+                    node.child_by_field_name("name"),
+                ) else {
+                    return;
+                };
+                let name = file.text(name);
+                if name == "Error" && !is_error_signature(file, node) {
+                    return;
+                }
+                method_sets
+                    .entry((package.to_string(), receiver.to_string()))
+                    .or_default()
+                    .push(name.to_string());
+            }
+            "type_spec" => {
+                if let Some(name) = node.child_by_field_name("name") {
+                    declarations.insert(
+                        (package.to_string(), file.text(name).to_string()),
+                        (file, node),
+                    );
+                }
+            }
+            _ => {}
+        });
+    }
+
+    let mut resolver = GoInheritanceResolver::new();
+    for ((package, type_name), methods) in &method_sets {
+        resolver.add_type_methods(format!("{package}.{type_name}"), methods.clone());
+    }
+
+    method_sets
+        .keys()
+        .filter(|(package, type_name)| {
+            resolver.check_struct_implements_interface(&format!("{package}.{type_name}"), "error")
+        })
+        .filter_map(|(package, type_name)| {
+            let (file, spec) = declarations.get(&(package.clone(), type_name.clone()))?;
+            Some(ErrorType {
+                name: type_name.clone(),
+                package: package.clone(),
+                file: file.display_path(),
+                line: line_of(*spec),
+                code_enum: code_enum(file, *spec, package, enums),
+            })
+        })
+        .collect()
+}
+
+/// Whether a method declaration has the signature `Error() string`
+fn is_error_signature(file: &GoSourceFile, method: Node) -> bool {
+    let no_params = method
+        .child_by_field_name("parameters")
+        .is_some_and(|p| p.named_child_count() == 0);
+    let returns_string = method
+        .child_by_field_name("result")
+        .is_some_and(|r| file.text(r) == "string");
+    no_params && returns_string
+}
+
+/// First struct field of the type whose type is an enum of the same package
+fn code_enum(
+    file: &GoSourceFile,
+    spec: Node,
+    package: &str,
+    enums: &EnumTable,
+) -> Option<ErrorCodeEnum> {
+    let body = spec.child_by_field_name("type")?;
+    if body.kind() != "struct_type" {
+        return None;
+    }
+    let fields = body
+        .named_children(&mut body.walk())
+        .find(|n| n.kind() == "field_declaration_list")?;
+    fields
+        .named_children(&mut fields.walk())
+        .filter(|n| n.kind() == "field_declaration")
+        .find_map(|field| {
+            let type_name = base_type_name(file, field.child_by_field_name("type")?)?;
+            let enum_type = enums.get(package, type_name)?;
+            let name = field.child_by_field_name("name")?;
+            Some(ErrorCodeEnum {
+                field: file.text(name).to_string(),
+                name: enum_type.name.clone(),
+                constants: enum_type.constants.iter().map(|c| c.name.clone()).collect(),
+            })
+        })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_error_types_with_code_enums() {
+        let models = r#"
+package models
+
+type UserErrorCode int
+
+const (
+    ErrInvalidName UserErrorCode = iota
+    ErrUserNotFound
+)
+
+type UserError struct {
+    Code    UserErrorCode
+    Message string
+}
+
+func (e *UserError) Error() string { return e.Message }
+
+type NotAnError struct{}
+
+func (n NotAnError) Error(code int) string { return "" }
+"#;
+        let app = r#"
+package main
+
+type CustomError struct {
+    Message string
+}
+
+func (e *CustomError) Error() string { return e.Message }
+"#;
+        let files = vec![
+            GoSourceFile::parse("models/user.go", models.to_string()).unwrap(),
+            GoSourceFile::parse("main.go", app.to_string()).unwrap(),
+        ];
+        let enums = EnumTable::build(&files);
+        let errors = find_error_types(&files, &enums);
+
+        let names: Vec<_> = errors.iter().map(|e| e.name.as_str()).collect();
+        assert_eq!(names, vec!["CustomError", "UserError"]);
+
+        assert_eq!(errors[0].code_enum, None);
+        let codes = errors[1].code_enum.as_ref().unwrap();
+        assert_eq!(codes.field, "Code");
+        assert_eq!(codes.name, "UserErrorCode");
+        assert_eq!(codes.constants, vec!["ErrInvalidName", "ErrUserNotFound"]);
+        assert_eq!(errors[1].line, 11);
+    }
+}
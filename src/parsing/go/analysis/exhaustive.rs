@@ -0,0 +1,226 @@
+//! Switches over enum types that miss constants
+//!
+//! A `switch` on an enum-typed value is expected to handle every constant of
+//! the enum (see [`EnumTable`]):
+//!
+//! ```go
+//! switch r {
+//! case RoleGuest:
+//!     return "guest"
+//! case RoleUser:
+//!     return "user"
+//! } // RoleAdmin is not handled
+//! ```
+//!
+//! The switched expression is typed as in [`super::enum_literals`]. Cases
+//! handle a constant by name (`RoleAdmin`, `models.RoleAdmin`) or by value
+//! (`2`, or another constant with the same value). Switches with a `default`
+//! case are exhaustive by definition and are skipped.
+
+use super::enum_literals::{TypeRef, expression_type, struct_field_types, variable_types};
+use super::enums::{EnumTable, EnumType, parse_int_literal};
+use super::{GoSourceFile, enclosing_function_name, is_suppressed, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "exhaustive";
+
+/// A switch over an enum type that doesn't handle every constant
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct NonExhaustiveSwitch {
+    /// Switched expression, as written
+    pub expression: String,
+    /// Enum type of the expression, qualified by package
+    pub enum_type: String,
+    /// Constants no case handles, in value order
+    pub missing: Vec<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the switch statement
+    pub line: u32,
+}
+
+impl fmt::Display for NonExhaustiveSwitch {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "switch on {} ({}) misses {}",
+            self.expression,
+            self.enum_type,
+            self.missing.join(", ")
+        )?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find enum switches missing constants, sorted by file and line
+pub fn find_non_exhaustive_switches(
+    files: &[GoSourceFile],
+    enums: &EnumTable,
+) -> Vec<NonExhaustiveSwitch> {
+    let fields = struct_field_types(files);
+    let mut findings: Vec<_> = files
+        .iter()
+        .flat_map(|file| switches_in_file(file, enums, &fields))
+        .collect();
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+fn switches_in_file(
+    file: &GoSourceFile,
+    enums: &EnumTable,
+    fields: &HashMap<TypeRef, HashMap<String, TypeRef>>,
+) -> Vec<NonExhaustiveSwitch> {
+    let mut findings = Vec::new();
+    let aliases = file.import_aliases();
+
+    walk_tree(file.root(), &mut |node| {
+        if !matches!(node.kind(), "function_declaration" | "method_declaration") {
+            return;
+        }
+        let variables = variable_types(file, node, &aliases);
+
+        walk_tree(node, &mut |switch| {
+            if switch.kind() != "expression_switch_statement" {
+                return;
+            }
+            let Some(value) = switch.child_by_field_name("value") else {
+                return;
+            };
+            let Some(enum_type) = expression_type(file, value, &variables, fields)
+                .and_then(|(package, name)| enums.get(&package, &name))
+            else {
+                return;
+            };
+            let cases: Vec<Node> = switch.named_children(&mut switch.walk()).collect();
+            if cases.iter().any(|case| case.kind() == "default_case") {
+                return;
+            }
+
+            let missing = missing_constants(file, enum_type, &cases, &aliases);
+            if missing.is_empty() {
+                return;
+            }
+            let line = line_of(switch);
+            if is_suppressed(file, line, ANALYSIS_NAME) {
+                return;
+            }
+            findings.push(NonExhaustiveSwitch {
+                expression: file.text(value).to_string(),
+                enum_type: format!("{}.{}", enum_type.package, enum_type.name),
+                missing,
+                function: enclosing_function_name(file, switch),
+                file: file.display_path(),
+                line,
+            });
+        });
+    });
+
+    findings
+}
+
+/// Constants of `enum_type` that none of the `cases` handles
+///
+/// A name handles a constant only when it refers to the enum's package:
+/// unqualified in that package, or qualified by an import of it.
+fn missing_constants(
+    file: &GoSourceFile,
+    enum_type: &EnumType,
+    cases: &[Node],
+    aliases: &HashMap<String, String>,
+) -> Vec<String> {
+    let in_enum_package = file.package_name() == Some(enum_type.package.as_str());
+    let mut names: HashSet<&str> = HashSet::new();
+    let mut values: HashSet<i64> = HashSet::new();
+    for case in cases.iter().filter(|case| case.kind() == "expression_case") {
+        let Some(list) = case.child_by_field_name("value") else {
+            continue;
+        };
+        for expr in list.named_children(&mut list.walk()) {
+            match expr.kind() {
+                "int_literal" => values.extend(parse_int_literal(file.text(expr))),
+                "identifier" if in_enum_package => {
+                    names.insert(file.text(expr));
+                }
+                // `models.RoleAdmin` from another package
+                "selector_expression" => {
+                    let qualifier = expr
+                        .child_by_field_name("operand")
+                        .map(|operand| file.text(operand));
+                    if let (Some(qualifier), Some(field)) =
+                        (qualifier, expr.child_by_field_name("field"))
+                    {
+                        let imports_enum_package = aliases.get(qualifier).is_some_and(|path| {
+                            path.rsplit('/').next() == Some(enum_type.package.as_str())
+                        });
+                        if imports_enum_package {
+                            names.insert(file.text(field));
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+    }
+    // Constants sharing a value are aliases of the handled one
+    values.extend(
+        enum_type
+            .constants
+            .iter()
+            .filter(|c| names.contains(c.name.as_str()))
+            .filter_map(|c| c.value),
+    );
+
+    enum_type
+        .constants
+        .iter()
+        .filter(|c| !names.contains(c.name.as_str()))
+        .filter(|c| !c.value.is_some_and(|value| values.contains(&value)))
+        .map(|c| c.name.clone())
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::path::Path;
+
+    #[test]
+    fn test_switches_missing_enum_constants() {
+        let fixture = Path::new("tests/fixtures/go/enum_switches");
+        let files: Vec<_> = ["handlers/user.go", "legacy/role.go", "models/role.go"]
+            .into_iter()
+            .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+            .collect();
+        let enums = EnumTable::build(&files);
+        let findings = find_non_exhaustive_switches(&files, &enums);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.function.as_deref(), f.missing.clone(), f.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (Some("CanDelete"), vec!["RoleGuest".to_string()], 15),
+                // legacy.RoleUser shares a name, not a package, with models.RoleUser
+                (
+                    Some("CanEdit"),
+                    vec!["RoleGuest".to_string(), "RoleUser".to_string()],
+                    34
+                ),
+                (Some("UserRole.Label"), vec!["RoleAdmin".to_string()], 27),
+            ]
+        );
+        assert_eq!(findings[0].expression, "req.Role");
+        assert_eq!(findings[0].enum_type, "models.UserRole");
+    }
+}
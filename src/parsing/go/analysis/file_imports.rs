@@ -0,0 +1,206 @@
+//! Imports of a file, with side-effect imports kept apart
+//!
+//! A blank import binds no name; the file only wants the package's `init`
+//! functions to run, typically to register a driver or a codec:
+//!
+//! ```go
+//! import (
+//!     "database/sql"
+//!
+//!     _ "example.com/plugins/drivers/postgres" // registers "postgres"
+//! )
+//! ```
+//!
+//! Nothing in the file refers to such a package, so it never shows up as a
+//! call or reference target. Listing it as a side-effect import, together
+//! with the `init` functions of the package when it is indexed, keeps those
+//! functions reachable from the file. Packages are matched through the
+//! [`PackageGraph`], so only imports into the module name their `init`
+//! functions.
+
+use super::packages::PackageGraph;
+use super::{GoSourceFile, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+
+/// An import declared by a file
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct FileImport {
+    pub import_path: String,
+    /// `named`, `aliased`, `dot` or `blank`
+    pub kind: &'static str,
+    /// Name the import binds; `None` for dot and blank imports
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub name: Option<String>,
+    pub file: String,
+    /// 1-based line of the import spec
+    pub line: u32,
+    /// `init` functions a blank import runs, when its package is indexed
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub init_functions: Vec<InitFunction>,
+}
+
+/// A package-level `func init()`
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct InitFunction {
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl fmt::Display for FileImport {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match (self.kind, &self.name) {
+            ("blank", _) => write!(f, "side-effect import \"{}\"", self.import_path)?,
+            ("dot", _) => write!(f, "import . \"{}\"", self.import_path)?,
+            ("aliased", Some(name)) => write!(f, "import {name} \"{}\"", self.import_path)?,
+            _ => write!(f, "import \"{}\"", self.import_path)?,
+        }
+        write!(f, " at {}:{}", self.file, self.line)?;
+        for init in &self.init_functions {
+            write!(
+                f,
+                "\n  runs {}.init at {}:{}",
+                init.package, init.file, init.line
+            )?;
+        }
+        Ok(())
+    }
+}
+
+/// Find the imports of the files at `target`, a path or path suffix
+///
+/// Imports binding a name come first, then the blank ones, each in source
+/// order. Returns `None` when no file matches.
+pub fn find_file_imports(files: &[GoSourceFile], target: &str) -> Option<Vec<FileImport>> {
+    let graph = PackageGraph::build(files);
+    let mut matched = false;
+    let mut imports = Vec::new();
+    for file in files {
+        let path = file.display_path();
+        if !(path == target || path.ends_with(&format!("/{target}"))) {
+            continue;
+        }
+        matched = true;
+        walk_tree(file.root(), &mut |spec| {
+            if spec.kind() != "import_spec" {
+                return;
+            }
+            let Some(import_path) = spec.child_by_field_name("path") else {
+                return;
+            };
+            let import_path = file
+                .text(import_path)
+                .trim_matches(|c| c == '"' || c == '`')
+                .to_string();
+            let (kind, name) = match spec.child_by_field_name("name") {
+                Some(name) if name.kind() == "blank_identifier" => ("blank", None),
+                Some(name) if name.kind() == "dot" => ("dot", None),
+                Some(name) => ("aliased", Some(file.text(name).to_string())),
+                None => ("named", import_path.rsplit('/').next().map(str::to_string)),
+            };
+            let line = line_of(spec);
+            let init_functions = if kind == "blank" {
+                graph
+                    .imports
+                    .iter()
+                    .find(|i| i.file == path && i.line == line && i.import_path == import_path)
+                    .and_then(|i| i.to.as_deref())
+                    .map(|package| init_functions(files, &graph, package))
+                    .unwrap_or_default()
+            } else {
+                Vec::new()
+            };
+            imports.push(FileImport {
+                import_path,
+                kind,
+                name,
+                file: path.clone(),
+                line,
+                init_functions,
+            });
+        });
+    }
+    imports.sort_by_key(|import| (import.kind == "blank", import.file.clone(), import.line));
+    matched.then_some(imports)
+}
+
+/// `init` functions of the files of `package`, sorted by file and line
+fn init_functions(
+    files: &[GoSourceFile],
+    graph: &PackageGraph,
+    package: &str,
+) -> Vec<InitFunction> {
+    let mut functions = Vec::new();
+    for file in files {
+        let path = file.display_path();
+        if graph.files.get(&path).map(String::as_str) != Some(package) {
+            continue;
+        }
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if decl.kind() == "function_declaration"
+                && decl
+                    .child_by_field_name("name")
+                    .is_some_and(|name| file.text(name) == "init")
+            {
+                functions.push(InitFunction {
+                    package: package.to_string(),
+                    file: path.clone(),
+                    line: line_of(decl),
+                });
+            }
+        }
+    }
+    functions.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    functions
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_blank_imports_listed_with_init_functions() {
+        let fixture = std::path::Path::new("tests/fixtures/go/side_effects");
+        let files: Vec<_> = ["main.go", "drivers/postgres/postgres.go"]
+            .iter()
+            .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+            .collect();
+
+        let imports = find_file_imports(&files, "main.go").unwrap();
+        let kinds: Vec<_> = imports
+            .iter()
+            .map(|i| (i.import_path.as_str(), i.kind, i.name.as_deref()))
+            .collect();
+        assert_eq!(
+            kinds,
+            vec![
+                ("database/sql", "named", Some("sql")),
+                ("fmt", "named", Some("fmt")),
+                ("example.com/plugins/drivers/postgres", "blank", None),
+                ("net/http/pprof", "blank", None),
+            ]
+        );
+
+        // The driver's init functions run through the blank import
+        let postgres = &imports[2];
+        let inits: Vec<_> = postgres.init_functions.iter().map(|i| i.line).collect();
+        assert_eq!(inits, vec![7, 11]);
+        assert!(
+            postgres
+                .to_string()
+                .starts_with("side-effect import \"example.com/plugins/drivers/postgres\" at ")
+        );
+        assert!(
+            postgres
+                .to_string()
+                .contains("\n  runs example.com/plugins/drivers/postgres.init at ")
+        );
+        // pprof is not indexed
+        assert!(imports[3].init_functions.is_empty());
+
+        assert!(find_file_imports(&files, "missing.go").is_none());
+    }
+}
@@ -0,0 +1,194 @@
+//! `go:generate` directives, the commands `go generate` runs
+//!
+//! A directive is a line comment starting at the beginning of a line:
+//!
+//! ```go
+//! //go:generate stringer -type=Color
+//!
+//! type Color int
+//! ```
+//!
+//! Each directive is reported with its command and the declaration it sits
+//! above, so build tooling can tell what needs regenerating and where. A
+//! directive inside a function body belongs to that function. Commands are
+//! kept as written: `go generate` expands `$GOFILE` and the like itself.
+
+use super::todos::symbol_name;
+use super::{GoSourceFile, enclosing_function_name, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Prefix of a directive; a space after `//` makes it a plain comment
+const DIRECTIVE: &str = "//go:generate";
+
+/// A `go:generate` directive
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GenerateDirective {
+    /// Command and arguments, as written after the directive
+    pub command: String,
+    /// Declaration below the directive, or the function enclosing it;
+    /// methods are qualified by their type
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub symbol: Option<String>,
+    /// 1-based line of the code below the directive
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub above_line: Option<u32>,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the directive
+    pub line: u32,
+}
+
+impl fmt::Display for GenerateDirective {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.command)?;
+        if let Some(symbol) = &self.symbol {
+            write!(f, " for {symbol}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find the `go:generate` directives of `files`, or of the file matching
+/// `target` by its full path or trailing segments, sorted by file and line
+///
+/// Returns `None` when `target` matches no file.
+pub fn find_generate_directives(
+    files: &[GoSourceFile],
+    target: Option<&str>,
+) -> Option<Vec<GenerateDirective>> {
+    let mut matched = false;
+    let mut directives = Vec::new();
+    for file in files {
+        let path = file.display_path();
+        if target.is_some_and(|target| !(path == target || path.ends_with(&format!("/{target}")))) {
+            continue;
+        }
+        matched = true;
+        let package = file.package_name().unwrap_or_default();
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "comment" || node.start_position().column != 0 {
+                return;
+            }
+            let Some(command) = file
+                .text(node)
+                .strip_prefix(DIRECTIVE)
+                .filter(|rest| rest.starts_with([' ', '\t']))
+                .map(str::trim)
+                .filter(|command| !command.is_empty())
+            else {
+                return;
+            };
+            let below = code_below(node);
+            let symbol = below
+                .and_then(|below| symbol_name(file, below))
+                .or_else(|| enclosing_function_name(file, node));
+            directives.push(GenerateDirective {
+                command: command.to_string(),
+                symbol,
+                above_line: below.map(line_of),
+                package: package.to_string(),
+                file: path.clone(),
+                line: line_of(node),
+            });
+        });
+    }
+    if !matched {
+        return None;
+    }
+    directives.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    Some(directives)
+}
+
+/// First declaration or statement after a comment, past other comments and
+/// blank lines
+fn code_below(comment: Node) -> Option<Node> {
+    let mut next = comment.next_named_sibling();
+    while let Some(node) = next {
+        if node.kind() != "comment" {
+            return Some(node);
+        }
+        next = node.next_named_sibling();
+    }
+    None
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_directives_attach_to_symbols() {
+        let code = r#"package colors
+
+//go:generate stringer -type=Color
+//go:generate	mockgen -source=$GOFILE -destination=mock_colors.go
+
+// Color of a pixel
+type Color int
+
+// go:generate with a space is a comment
+func Parse(name string) Color {
+//go:generate echo inside
+	return 0
+}
+
+	//go:generate indented lines are not directives
+//go:generate
+func (c Color) String() string { return "" }
+"#;
+        let files = vec![GoSourceFile::parse("colors/colors.go", code.to_string()).unwrap()];
+        let directives = find_generate_directives(&files, None).unwrap();
+
+        let found: Vec<_> = directives
+            .iter()
+            .map(|d| {
+                (
+                    d.command.as_str(),
+                    d.symbol.as_deref(),
+                    d.line,
+                    d.above_line,
+                )
+            })
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                ("stringer -type=Color", Some("Color"), 3, Some(7)),
+                (
+                    "mockgen -source=$GOFILE -destination=mock_colors.go",
+                    Some("Color"),
+                    4,
+                    Some(7)
+                ),
+                ("echo inside", Some("Parse"), 11, Some(12)),
+            ]
+        );
+        assert_eq!(directives[0].package, "colors");
+        assert_eq!(
+            directives[0].to_string(),
+            "stringer -type=Color for Color at colors/colors.go:3"
+        );
+
+        assert_eq!(
+            find_generate_directives(&files, Some("colors.go")).map(|d| d.len()),
+            Some(3)
+        );
+        assert_eq!(find_generate_directives(&files, Some("other.go")), None);
+    }
+
+    #[test]
+    fn test_directive_in_comprehensive_example() {
+        let file =
+            GoSourceFile::read(std::path::Path::new("examples/go/comprehensive.go")).unwrap();
+        let directives = find_generate_directives(&[file], Some("comprehensive.go")).unwrap();
+
+        assert_eq!(directives.len(), 1);
+        assert_eq!(directives[0].command, "go run generate_functions.go");
+        assert_eq!(directives[0].line, 297);
+        // The blank line and doc comment in between don't detach it
+        assert_eq!(directives[0].symbol.as_deref(), Some("GeneratedFunc"));
+        assert_eq!(directives[0].above_line, Some(300));
+    }
+}
@@ -0,0 +1,523 @@
+//! Functions passed to higher-order functions
+//!
+//! A call such as
+//!
+//! ```go
+//! even := Filter(numbers, func(n int) bool { return n%2 == 0 })
+//! upper := processItems(items, strings.ToUpper)
+//! ```
+//!
+//! binds a concrete function to a function-typed parameter of the callee
+//! (`predicate`, `processor`). Each binding is reported with the argument:
+//! a function name, a method value, a literal or a variable holding a
+//! function. Parameters are function-typed when declared as `func(...)` or
+//! with a named function type (`type Predicate func(int) bool`).
+//!
+//! Callees are matched by package, receiver and name, so the declaration of
+//! the higher-order function must be among the analyzed files. A method call
+//! is matched by name when a single higher-order method has it, otherwise by
+//! the receiver or parameter type of its operand.
+
+use super::channels::operand_type;
+use super::{
+    GoSourceFile, declaration_name, enclosing_function_name, line_of, receiver_type_name, walk_tree,
+};
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// How a function argument is written
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum ArgumentKind {
+    /// A declared function, `isPositive` or `strings.ToUpper`
+    Function,
+    /// A method value, `user.Verify`
+    Method,
+    /// A function literal
+    Literal,
+    /// A variable holding a function
+    Variable,
+}
+
+impl fmt::Display for ArgumentKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Self::Function => write!(f, "function"),
+            Self::Method => write!(f, "method"),
+            Self::Literal => write!(f, "literal"),
+            Self::Variable => write!(f, "variable"),
+        }
+    }
+}
+
+/// A function bound to a function-typed parameter at a call site
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct HigherOrderArg {
+    /// Called higher-order function; methods are qualified by receiver
+    pub function: String,
+    /// Parameter receiving the argument
+    pub parameter: String,
+    /// Argument as written; literals are shown up to their body
+    pub argument: String,
+    pub kind: ArgumentKind,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub caller: Option<String>,
+    pub file: String,
+    /// 1-based line of the argument
+    pub line: u32,
+}
+
+impl fmt::Display for HigherOrderArg {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}({}) <- {} {}",
+            self.function, self.parameter, self.kind, self.argument
+        )?;
+        if let Some(caller) = &self.caller {
+            write!(f, " in {caller}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// A parameter slot of a declaration
+#[derive(Debug, Clone)]
+struct Parameter {
+    name: String,
+    is_function: bool,
+    is_variadic: bool,
+}
+
+/// Package, receiver type and name identifying a function or method
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+struct CalleeKey {
+    package: String,
+    receiver: Option<String>,
+    name: String,
+}
+
+/// A declaration with at least one function-typed parameter
+#[derive(Debug, Clone)]
+struct HigherOrderFunction {
+    name: String,
+    parameters: Vec<Parameter>,
+}
+
+impl HigherOrderFunction {
+    /// Parameter bound to the argument at `index`
+    fn parameter_at(&self, index: usize) -> Option<&Parameter> {
+        self.parameters.get(index).or_else(|| {
+            self.parameters
+                .last()
+                .filter(|p| p.is_variadic && index >= self.parameters.len())
+        })
+    }
+}
+
+/// Find functions passed to higher-order functions, sorted by file and line
+///
+/// With `function`, only calls to the function or method of that name are
+/// reported (`Filter`, `Process` or `FileProcessor.Process`).
+pub fn find_higher_order_args(
+    files: &[GoSourceFile],
+    function: Option<&str>,
+) -> Vec<HigherOrderArg> {
+    let callees = higher_order_functions(files);
+    let declared = declared_functions(files);
+
+    let mut args = Vec::new();
+    for file in files {
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "call_expression" {
+                return;
+            }
+            let (Some((callee, target)), Some(arguments)) = (
+                node.child_by_field_name("function")
+                    .and_then(|f| resolve_callee(&callees, file, f, &aliases)),
+                node.child_by_field_name("arguments"),
+            ) else {
+                return;
+            };
+            if function.is_some_and(|wanted| wanted != callee.name && wanted != target.name) {
+                return;
+            }
+            for (index, argument) in arguments.named_children(&mut arguments.walk()).enumerate() {
+                let Some(parameter) = target.parameter_at(index).filter(|p| p.is_function) else {
+                    continue;
+                };
+                let Some((kind, text)) = classify_argument(file, argument, &declared, &aliases)
+                else {
+                    continue;
+                };
+                args.push(HigherOrderArg {
+                    function: target.name.clone(),
+                    parameter: parameter.name.clone(),
+                    argument: text,
+                    kind,
+                    caller: enclosing_function_name(file, argument),
+                    file: file.display_path(),
+                    line: line_of(argument),
+                });
+            }
+        });
+    }
+    args.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    args
+}
+
+/// Declarations with function-typed parameters, keyed by package, receiver
+/// and name
+fn higher_order_functions(files: &[GoSourceFile]) -> HashMap<CalleeKey, HigherOrderFunction> {
+    let func_types = named_function_types(files);
+    let mut functions = HashMap::new();
+    for file in files {
+        walk_tree(file.root(), &mut |node| {
+            if !matches!(node.kind(), "function_declaration" | "method_declaration") {
+                return;
+            }
+            let (Some(name), Some(params)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("parameters"),
+            ) else {
+                return;
+            };
+            let mut parameters = Vec::new();
+            for param in params.named_children(&mut params.walk()) {
+                let Some(param_type) = param.child_by_field_name("type") else {
+                    continue;
+                };
+                let is_function = match param_type.kind() {
+                    "function_type" => true,
+                    "type_identifier" => func_types.contains(file.text(param_type)),
+                    "qualified_type" => param_type
+                        .child_by_field_name("name")
+                        .is_some_and(|n| func_types.contains(file.text(n))),
+                    _ => false,
+                };
+                let is_variadic = param.kind() == "variadic_parameter_declaration";
+                let names: Vec<_> = param
+                    .children_by_field_name("name", &mut param.walk())
+                    .map(|n| file.text(n).to_string())
+                    .collect();
+                // Unnamed parameters still take a slot
+                let names = if names.is_empty() {
+                    vec!["_".to_string()]
+                } else {
+                    names
+                };
+                for name in names {
+                    parameters.push(Parameter {
+                        name,
+                        is_function,
+                        is_variadic,
+                    });
+                }
+            }
+            if !parameters.iter().any(|p| p.is_function) {
+                return;
+            }
+            let (Some(package), Some(qualified)) =
+                (file.package_name(), declaration_name(file, node))
+            else {
+                return;
+            };
+            let key = CalleeKey {
+                package: package.to_string(),
+                receiver: receiver_type_name(file, node).map(str::to_string),
+                name: file.text(name).to_string(),
+            };
+            functions.entry(key).or_insert(HigherOrderFunction {
+                name: qualified,
+                parameters,
+            });
+        });
+    }
+    functions
+}
+
+/// Names of types declared as function types: `type Predicate func(int) bool`
+fn named_function_types(files: &[GoSourceFile]) -> HashSet<&str> {
+    let mut names = HashSet::new();
+    for file in files {
+        walk_tree(file.root(), &mut |node| {
+            if !matches!(node.kind(), "type_spec" | "type_alias") {
+                return;
+            }
+            if let (Some(name), Some(body)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type"),
+            ) {
+                if body.kind() == "function_type" {
+                    names.insert(file.text(name));
+                }
+            }
+        });
+    }
+    names
+}
+
+/// Names of the package-level functions declared in `files`
+fn declared_functions(files: &[GoSourceFile]) -> HashSet<&str> {
+    let mut names = HashSet::new();
+    for file in files {
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if decl.kind() == "function_declaration" {
+                if let Some(name) = decl.child_by_field_name("name") {
+                    names.insert(file.text(name));
+                }
+            }
+        }
+    }
+    names
+}
+
+/// Declaration called by `function`: `Filter` and `Filter[int]` in the
+/// calling package, `pkg.Filter` in the imported one, `p.Process` a method
+/// picked by name or by the type of `p`
+fn resolve_callee<'c>(
+    callees: &'c HashMap<CalleeKey, HigherOrderFunction>,
+    file: &GoSourceFile,
+    function: Node,
+    aliases: &HashMap<String, String>,
+) -> Option<(&'c CalleeKey, &'c HigherOrderFunction)> {
+    match function.kind() {
+        "identifier" => callees.get_key_value(&CalleeKey {
+            package: file.package_name()?.to_string(),
+            receiver: None,
+            name: file.text(function).to_string(),
+        }),
+        "selector_expression" => {
+            let operand = function.child_by_field_name("operand")?;
+            let name = file.text(function.child_by_field_name("field")?);
+            let qualifier = file.text(operand);
+            if operand.kind() == "identifier" && !is_shadowed(file, operand, qualifier) {
+                if let Some(path) = aliases.get(qualifier) {
+                    return callees.get_key_value(&CalleeKey {
+                        package: path.rsplit('/').next().unwrap_or(path).to_string(),
+                        receiver: None,
+                        name: name.to_string(),
+                    });
+                }
+            }
+            let methods: Vec<_> = callees
+                .iter()
+                .filter(|(key, _)| key.receiver.is_some() && key.name == name)
+                .collect();
+            match methods.as_slice() {
+                [method] => Some(*method),
+                _ => {
+                    let receiver = operand_type(file, operand)?;
+                    let mut typed = methods
+                        .into_iter()
+                        .filter(|(key, _)| key.receiver.as_deref() == Some(receiver));
+                    match (typed.next(), typed.next()) {
+                        (Some(method), None) => Some(method),
+                        _ => None,
+                    }
+                }
+            }
+        }
+        "index_expression" => resolve_callee(
+            callees,
+            file,
+            function.child_by_field_name("operand")?,
+            aliases,
+        ),
+        "generic_type" => resolve_callee(
+            callees,
+            file,
+            function.child_by_field_name("type")?,
+            aliases,
+        ),
+        "parenthesized_expression" => {
+            resolve_callee(callees, file, function.named_child(0)?, aliases)
+        }
+        _ => None,
+    }
+}
+
+/// Kind and display text of a function argument
+fn classify_argument(
+    file: &GoSourceFile,
+    argument: Node,
+    declared: &HashSet<&str>,
+    aliases: &HashMap<String, String>,
+) -> Option<(ArgumentKind, String)> {
+    match argument.kind() {
+        "func_literal" => {
+            let end = argument
+                .child_by_field_name("body")
+                .map_or(argument.end_byte(), |body| body.start_byte());
+            let signature = file.source[argument.start_byte()..end].trim();
+            Some((ArgumentKind::Literal, signature.to_string()))
+        }
+        "identifier" => {
+            let name = file.text(argument);
+            let kind = if declared.contains(name) && !is_shadowed(file, argument, name) {
+                ArgumentKind::Function
+            } else {
+                ArgumentKind::Variable
+            };
+            Some((kind, name.to_string()))
+        }
+        "selector_expression" => {
+            let operand = argument.child_by_field_name("operand")?;
+            // `strings.ToUpper` names a package function, `u.Verify` a method
+            let kind = if operand.kind() == "identifier" && aliases.contains_key(file.text(operand))
+            {
+                ArgumentKind::Function
+            } else {
+                ArgumentKind::Method
+            };
+            Some((kind, file.text(argument).to_string()))
+        }
+        "parenthesized_expression" => {
+            classify_argument(file, argument.named_child(0)?, declared, aliases)
+        }
+        _ => None,
+    }
+}
+
+/// Whether `name` is declared locally in the function enclosing `node`
+fn is_shadowed(file: &GoSourceFile, node: Node, name: &str) -> bool {
+    let mut function = node.parent();
+    while let Some(n) = function {
+        if matches!(n.kind(), "function_declaration" | "method_declaration") {
+            break;
+        }
+        function = n.parent();
+    }
+    let Some(function) = function else {
+        return false;
+    };
+    let mut shadowed = false;
+    walk_tree(function, &mut |n| {
+        let declares = match n.kind() {
+            "parameter_declaration" | "var_spec" => n
+                .children_by_field_name("name", &mut n.walk())
+                .any(|id| file.text(id) == name),
+            "short_var_declaration" => n.child_by_field_name("left").is_some_and(|left| {
+                left.named_children(&mut left.walk())
+                    .any(|id| file.text(id) == name)
+            }),
+            _ => false,
+        };
+        shadowed |= declares;
+    });
+    shadowed
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_functions_bound_to_higher_order_parameters() {
+        let code = r#"
+package main
+
+import "strings"
+
+type Predicate func(int) bool
+
+func Filter[T any](slice []T, predicate func(T) bool) []T { return nil }
+
+func processItems(items []string, processor func(string) string) []string { return nil }
+
+func Count(values []int, keep Predicate) int { return 0 }
+
+func isPositive(n int) bool { return n > 0 }
+
+func ExampleUsage() {
+    numbers := []int{1, 2, 3}
+    even := Filter(numbers, func(n int) bool { return n%2 == 0 })
+    upper := processItems([]string{"a"}, strings.ToUpper)
+    square := func(s string) string { return s }
+    same := processItems(nil, square)
+    positive := Count(numbers, isPositive)
+    _, _, _, _ = even, upper, same, positive
+}
+"#;
+        let files = vec![GoSourceFile::parse("main.go", code.to_string()).unwrap()];
+
+        let all = find_higher_order_args(&files, None);
+        let summary: Vec<_> = all
+            .iter()
+            .map(|a| (a.function.as_str(), a.parameter.as_str(), a.kind, a.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("Filter", "predicate", ArgumentKind::Literal, 18),
+                ("processItems", "processor", ArgumentKind::Function, 19),
+                ("processItems", "processor", ArgumentKind::Variable, 21),
+                ("Count", "keep", ArgumentKind::Function, 22),
+            ]
+        );
+
+        let filter = find_higher_order_args(&files, Some("Filter"));
+        assert_eq!(filter.len(), 1);
+        assert_eq!(filter[0].argument, "func(n int) bool");
+        assert_eq!(filter[0].caller.as_deref(), Some("ExampleUsage"));
+        assert_eq!(all[1].argument, "strings.ToUpper");
+    }
+    #[test]
+    fn test_callees_are_keyed_by_package_and_receiver() {
+        let util = r#"
+package util
+
+func Apply(values []int, transform func(int) int) []int { return values }
+"#;
+        let code = r#"
+package main
+
+import "example.com/app/util"
+
+type Printer struct{}
+
+func (p Printer) Apply(values []int, prefix string) {}
+
+type Mapper struct{}
+
+func (m Mapper) Each(values []int, visit func(int)) {}
+
+type Walker struct{}
+
+func (w Walker) Each(values []int, visit func(int)) {}
+
+func Apply(prefix string, values []int) {}
+
+func double(n int) int { return n * 2 }
+
+func Run(m Mapper, w Walker, p Printer) {
+    values := util.Apply(nil, double)
+    Apply("x", values)
+    p.Apply(values, "y")
+    m.Each(values, func(int) {})
+    w.Each(values, func(int) {})
+}
+"#;
+        let files = vec![
+            GoSourceFile::parse("util/util.go", util.to_string()).unwrap(),
+            GoSourceFile::parse("main.go", code.to_string()).unwrap(),
+        ];
+
+        let summary: Vec<_> = find_higher_order_args(&files, None)
+            .into_iter()
+            .map(|a| (a.function, a.argument, a.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("Apply".to_string(), "double".to_string(), 23),
+                ("Mapper.Each".to_string(), "func(int)".to_string(), 26),
+                ("Walker.Each".to_string(), "func(int)".to_string(), 27),
+            ]
+        );
+    }
+}
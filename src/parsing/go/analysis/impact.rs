@@ -0,0 +1,546 @@
+//! Everything a change to a type's definition reaches
+//!
+//! `codanna analyze impact models.User` lists the methods declared on the
+//! type, the functions taking or returning it, the struct fields holding it
+//! and, transitively, the functions calling any of those functions:
+//!
+//! ```text
+//! models.User (models/user.go:8): 2 methods, 3 parameters, 1 result, 1 field, 4 callers in 3 files
+//! ```
+//!
+//! References are matched by type, so `*User`, `[]models.User` and
+//! `map[string]*User` all count. Callers are found by name: plain and
+//! package-qualified calls always, method calls `x.M()` only when a single
+//! type in the analyzed files declares a method `M`, since the type of `x`
+//! isn't known here.
+
+use super::{GoSourceFile, declaration_name, line_of, receiver_type_name, type_key, walk_tree};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet, VecDeque};
+use std::fmt;
+use tree_sitter::Node;
+
+/// Call levels followed from the direct references when none is given
+pub const DEFAULT_DEPTH: u32 = 2;
+
+/// How a symbol depends on the type
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum ImpactRole {
+    /// Method declared on the type
+    Method,
+    /// Function with a parameter of the type
+    Parameter,
+    /// Function returning the type
+    Result,
+    /// Struct field holding the type
+    Field,
+    /// Function calling a method or function above, directly or through
+    /// other callers
+    Caller,
+}
+
+impl fmt::Display for ImpactRole {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let name = match self {
+            Self::Method => "method",
+            Self::Parameter => "parameter",
+            Self::Result => "result",
+            Self::Field => "field",
+            Self::Caller => "caller",
+        };
+        f.pad(name)
+    }
+}
+
+/// One symbol the type change reaches
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ImpactEntry {
+    pub role: ImpactRole,
+    /// Function, method as `Type.Method` or field as `Struct.field`
+    pub symbol: String,
+    /// Parameter, result or field as written; the function called for callers
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub detail: Option<String>,
+    /// Calls between a caller and the nearest direct reference; 0 otherwise
+    pub depth: u32,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl fmt::Display for ImpactEntry {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{:<9} {}", self.role, self.symbol)?;
+        match (self.role, &self.detail) {
+            (ImpactRole::Parameter, Some(detail)) => write!(f, "({detail})")?,
+            (ImpactRole::Caller, Some(detail)) => write!(f, " calls {detail}")?,
+            (_, Some(detail)) => write!(f, " {detail}")?,
+            (_, None) => {}
+        }
+        if self.depth > 1 {
+            write!(f, " (depth {})", self.depth)?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Impact of changing one type definition
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeImpact {
+    /// Type as `package.Type`
+    pub type_name: String,
+    pub file: String,
+    /// 1-based line of the type declaration
+    pub line: u32,
+    /// Number of entries per role
+    pub counts: BTreeMap<ImpactRole, usize>,
+    /// Files containing an entry, sorted
+    pub files: Vec<String>,
+    /// Entries grouped by role, then sorted by file and line
+    pub entries: Vec<ImpactEntry>,
+}
+
+impl fmt::Display for TypeImpact {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{} ({}:{}): ", self.type_name, self.file, self.line)?;
+        if self.counts.is_empty() {
+            write!(f, "no references")?;
+        } else {
+            let counts: Vec<String> = self
+                .counts
+                .iter()
+                .map(|(role, n)| format!("{n} {role}{}", if *n == 1 { "" } else { "s" }))
+                .collect();
+            let plural = if self.files.len() == 1 { "" } else { "s" };
+            write!(
+                f,
+                "{} in {} file{plural}",
+                counts.join(", "),
+                self.files.len()
+            )?;
+        }
+        for entry in &self.entries {
+            write!(f, "\n  {entry}")?;
+        }
+        Ok(())
+    }
+}
+
+/// A function or method declaration, keyed as `package.Name` or
+/// `package.Type.Method`
+struct FunctionDecl {
+    /// Name as displayed: `Name` or `Type.Method`
+    name: String,
+    file: String,
+    line: u32,
+}
+
+/// Find what changing the type `name` affects, one report per matching
+/// declaration
+///
+/// `name` is `Type` or `package.Type`; a full import path such as
+/// `example.com/app/models.User` is reduced to its package name. Callers are
+/// followed `max_depth` calls up from the direct references.
+pub fn find_type_impact(files: &[GoSourceFile], name: &str, max_depth: u32) -> Vec<TypeImpact> {
+    let (package, type_name) = match name.rsplit_once('.') {
+        Some((path, type_name)) => (Some(path.rsplit('/').next().unwrap_or(path)), type_name),
+        None => (None, name),
+    };
+
+    let mut impacts = Vec::new();
+    for file in files {
+        let file_package = file.package_name().unwrap_or_default();
+        if package.is_some_and(|p| p != file_package) {
+            continue;
+        }
+        walk_tree(file.root(), &mut |node| {
+            if !matches!(node.kind(), "type_spec" | "type_alias") {
+                return;
+            }
+            let Some(declared) = node.child_by_field_name("name") else {
+                return;
+            };
+            if file.text(declared) != type_name {
+                return;
+            }
+            let key = format!("{file_package}.{type_name}");
+            let entries = impact_entries(files, &key, max_depth);
+            let mut counts = BTreeMap::new();
+            for entry in &entries {
+                *counts.entry(entry.role).or_insert(0) += 1;
+            }
+            let file_set: BTreeSet<&str> = entries.iter().map(|e| e.file.as_str()).collect();
+            impacts.push(TypeImpact {
+                type_name: key,
+                file: file.display_path(),
+                line: line_of(node),
+                counts,
+                files: file_set.into_iter().map(str::to_string).collect(),
+                entries,
+            });
+        });
+    }
+    impacts.sort_by(|a, b| (&a.type_name, &a.file).cmp(&(&b.type_name, &b.file)));
+    impacts.dedup_by(|a, b| a.type_name == b.type_name);
+    impacts
+}
+
+/// Direct references to the type keyed `key`, then their callers
+fn impact_entries(files: &[GoSourceFile], key: &str, max_depth: u32) -> Vec<ImpactEntry> {
+    let (package, type_name) = key.split_once('.').unwrap_or(("", key));
+    let mut entries = Vec::new();
+    // Functions and methods referencing the type, the roots of the call walk
+    let mut direct: Vec<String> = Vec::new();
+
+    for file in files {
+        let file_package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        let mentions = |node: Node| mentions_type(file, node, &aliases, key);
+
+        walk_tree(file.root(), &mut |node| match node.kind() {
+            "function_declaration" | "method_declaration" => {
+                let Some(symbol) = declaration_name(file, node) else {
+                    return;
+                };
+                let entry = |role, detail: Option<&str>| ImpactEntry {
+                    role,
+                    symbol: symbol.clone(),
+                    detail: detail.map(str::to_string),
+                    depth: 0,
+                    file: file.display_path(),
+                    line: line_of(node),
+                };
+                let mut referenced = false;
+                if node.kind() == "method_declaration"
+                    && file_package == package
+                    && receiver_type_name(file, node) == Some(type_name)
+                {
+                    entries.push(entry(ImpactRole::Method, None));
+                    referenced = true;
+                }
+                if let Some(params) = node.child_by_field_name("parameters") {
+                    for param in params.named_children(&mut params.walk()) {
+                        if param.kind() != "comment" && mentions(param) {
+                            entries.push(entry(ImpactRole::Parameter, Some(file.text(param))));
+                            referenced = true;
+                        }
+                    }
+                }
+                if let Some(result) = node.child_by_field_name("result") {
+                    if mentions(result) {
+                        entries.push(entry(ImpactRole::Result, Some(file.text(result))));
+                        referenced = true;
+                    }
+                }
+                if referenced {
+                    direct.push(format!("{file_package}.{symbol}"));
+                }
+            }
+            "field_declaration" => {
+                let Some(field_type) = node.child_by_field_name("type") else {
+                    return;
+                };
+                let Some(owner) = enclosing_type_name(file, node) else {
+                    return;
+                };
+                if !mentions(field_type) {
+                    return;
+                }
+                let names: Vec<&str> = node
+                    .children_by_field_name("name", &mut node.walk())
+                    .map(|n| file.text(n))
+                    .collect();
+                let entry = |name: &str| ImpactEntry {
+                    role: ImpactRole::Field,
+                    symbol: format!("{owner}.{name}"),
+                    detail: Some(file.text(field_type).to_string()),
+                    depth: 0,
+                    file: file.display_path(),
+                    line: line_of(node),
+                };
+                if names.is_empty() {
+                    // Embedded: the field is named after the type
+                    entries.push(entry(type_name));
+                }
+                entries.extend(names.into_iter().map(entry));
+            }
+            _ => {}
+        });
+    }
+
+    entries.extend(transitive_callers(files, &direct, max_depth));
+    entries.sort_by(|a, b| (a.role, &a.file, a.line).cmp(&(b.role, &b.file, b.line)));
+    entries.dedup();
+    entries
+}
+
+/// Whether the type expression `node` refers to the type keyed `key`
+fn mentions_type(
+    file: &GoSourceFile,
+    node: Node,
+    aliases: &HashMap<String, String>,
+    key: &str,
+) -> bool {
+    let mut found = false;
+    walk_tree(node, &mut |n| {
+        let is_type = match n.kind() {
+            "qualified_type" => true,
+            // The name inside `models.User` is matched with its package
+            "type_identifier" => n.parent().is_none_or(|p| p.kind() != "qualified_type"),
+            _ => false,
+        };
+        if is_type && type_key(file, n, aliases).as_deref() == Some(key) {
+            found = true;
+        }
+    });
+    found
+}
+
+/// Name of the type declaration a struct field belongs to
+fn enclosing_type_name<'s>(file: &'s GoSourceFile, field: Node) -> Option<&'s str> {
+    let mut current = field.parent();
+    while let Some(node) = current {
+        if node.kind() == "type_spec" {
+            return node.child_by_field_name("name").map(|n| file.text(n));
+        }
+        if matches!(node.kind(), "function_declaration" | "method_declaration") {
+            return None;
+        }
+        current = node.parent();
+    }
+    None
+}
+
+/// Functions calling any of `roots`, up to `max_depth` calls away
+fn transitive_callers(
+    files: &[GoSourceFile],
+    roots: &[String],
+    max_depth: u32,
+) -> Vec<ImpactEntry> {
+    let mut declarations: HashMap<String, FunctionDecl> = HashMap::new();
+    // Method name -> keys of the methods declaring it
+    let mut methods: HashMap<String, Vec<String>> = HashMap::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let Some(name) = declaration_name(file, decl) else {
+                continue;
+            };
+            let key = format!("{package}.{name}");
+            if decl.kind() == "method_declaration" {
+                if let Some(method) = decl.child_by_field_name("name") {
+                    methods
+                        .entry(file.text(method).to_string())
+                        .or_default()
+                        .push(key.clone());
+                }
+            }
+            declarations.insert(
+                key,
+                FunctionDecl {
+                    name,
+                    file: file.display_path(),
+                    line: line_of(decl),
+                },
+            );
+        }
+    }
+
+    // Callee key -> caller keys
+    let mut callers: HashMap<String, BTreeSet<String>> = HashMap::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let Some(caller) = declaration_name(file, decl).map(|n| format!("{package}.{n}"))
+            else {
+                continue;
+            };
+            walk_tree(decl, &mut |node| {
+                if node.kind() != "call_expression" {
+                    return;
+                }
+                let Some(function) = node.child_by_field_name("function") else {
+                    return;
+                };
+                let callee = match function.kind() {
+                    "identifier" => Some(format!("{package}.{}", file.text(function))),
+                    "selector_expression" => {
+                        let operand = function.child_by_field_name("operand");
+                        let field = function.child_by_field_name("field");
+                        match (operand, field) {
+                            (Some(operand), Some(field)) => {
+                                let field = file.text(field);
+                                match aliases.get(file.text(operand)) {
+                                    Some(path) if operand.kind() == "identifier" => Some(format!(
+                                        "{}.{field}",
+                                        path.rsplit('/').next().unwrap_or(path)
+                                    )),
+                                    _ => match methods.get(field).map(Vec::as_slice) {
+                                        Some([only]) => Some(only.clone()),
+                                        _ => None,
+                                    },
+                                }
+                            }
+                            _ => None,
+                        }
+                    }
+                    _ => None,
+                };
+                if let Some(callee) = callee {
+                    if callee != caller {
+                        callers.entry(callee).or_default().insert(caller.clone());
+                    }
+                }
+            });
+        }
+    }
+
+    let mut visited: HashSet<&str> = roots.iter().map(String::as_str).collect();
+    let mut queue: VecDeque<(&str, u32)> = roots.iter().map(|r| (r.as_str(), 0)).collect();
+    let mut entries = Vec::new();
+    while let Some((callee, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        let Some(callee_callers) = callers.get(callee) else {
+            continue;
+        };
+        for caller in callee_callers {
+            if !visited.insert(caller.as_str()) {
+                continue;
+            }
+            queue.push_back((caller.as_str(), depth + 1));
+            let (Some(decl), Some(callee_decl)) =
+                (declarations.get(caller), declarations.get(callee))
+            else {
+                continue;
+            };
+            entries.push(ImpactEntry {
+                role: ImpactRole::Caller,
+                symbol: decl.name.clone(),
+                detail: Some(callee_decl.name.clone()),
+                depth: depth + 1,
+                file: decl.file.clone(),
+                line: decl.line,
+            });
+        }
+    }
+    entries
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_type_impact_spans_packages() {
+        let models = r#"
+package models
+
+type User struct {
+    Name string
+}
+
+func (u *User) Verify() bool { return u.Name != "" }
+
+func NewUser(name string) *User { return &User{Name: name} }
+
+type Team struct {
+    Owner   *User
+    Members []User
+}
+"#;
+        let auth = r#"
+package auth
+
+import "example.com/app/models"
+
+type Session struct {
+    user *models.User
+}
+
+func Authenticate(user *models.User, token string) bool {
+    return user.Verify()
+}
+
+func Login(name string) bool {
+    return Authenticate(models.NewUser(name), "t")
+}
+"#;
+        let main = r#"
+package main
+
+import "example.com/app/auth"
+
+func main() {
+    run()
+}
+
+func run() {
+    auth.Login("admin")
+}
+"#;
+        let files = vec![
+            parse("models/user.go", models),
+            parse("auth/auth.go", auth),
+            parse("main.go", main),
+        ];
+
+        let impacts = find_type_impact(&files, "models.User", DEFAULT_DEPTH);
+        assert_eq!(impacts.len(), 1);
+        let impact = &impacts[0];
+        assert_eq!(impact.type_name, "models.User");
+        assert_eq!(impact.line, 4);
+
+        let summary: Vec<_> = impact
+            .entries
+            .iter()
+            .map(|e| (e.role, e.symbol.as_str(), e.depth))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (ImpactRole::Method, "User.Verify", 0),
+                (ImpactRole::Parameter, "Authenticate", 0),
+                (ImpactRole::Result, "NewUser", 0),
+                (ImpactRole::Field, "Session.user", 0),
+                (ImpactRole::Field, "Team.Owner", 0),
+                (ImpactRole::Field, "Team.Members", 0),
+                (ImpactRole::Caller, "Login", 1),
+                (ImpactRole::Caller, "run", 2),
+            ]
+        );
+        assert_eq!(impact.counts[&ImpactRole::Field], 3);
+        assert_eq!(
+            impact.files,
+            vec!["auth/auth.go", "main.go", "models/user.go"]
+        );
+        assert_eq!(
+            impact.entries[1].to_string(),
+            "parameter Authenticate(user *models.User) at auth/auth.go:10"
+        );
+
+        // One more level reaches main
+        let deeper = find_type_impact(&files, "User", 3);
+        assert!(
+            deeper[0]
+                .entries
+                .iter()
+                .any(|e| e.symbol == "main" && e.depth == 3)
+        );
+    }
+}
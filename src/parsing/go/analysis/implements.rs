@@ -0,0 +1,760 @@
+//! Interfaces satisfied by the declared types, across packages
+//!
+//! Go types implement interfaces implicitly, by having their methods. Method
+//! sets of every declared type and interface are registered with
+//! [`GoInheritanceResolver`] under `import/path.Name` keys, with embedded
+//! interfaces flattened, and each type is checked against each interface:
+//!
+//! ```go
+//! // models/store.go
+//! type Store interface {
+//!     Save(u *User) error
+//!     Load(id string) (*User, error)
+//! }
+//!
+//! // services/user_store.go
+//! type UserStore struct{ ... }
+//!
+//! func (s *UserStore) Save(u *models.User) error { ... }
+//! func (s *UserStore) Load(id string) (*models.User, error) { ... }
+//! ```
+//!
+//! `services.UserStore` implements `models.Store`. An interface with
+//! unexported methods is only satisfied by types of its own package. Method
+//! names are matched as the resolver does; when both the interface and the
+//! type declare a method, its shape must match too: the number of
+//! parameters, whether the last one is variadic, and the number of results.
+//! `Log(level, format string, args ...interface{})` is not implemented by a
+//! `Log` taking `args []interface{}`. Parameter types are not compared.
+//!
+//! A struct's method set is the union of the methods declared on it and the
+//! ones promoted from its embedded fields, so `type LogWriter struct{
+//! io.Writer }` with a `Close() error` of its own implements
+//! `io.WriteCloser`. The well-known standard library interfaces are checked
+//! too; they have no file or line.
+//!
+//! Method sets differ between a type and a pointer to it: methods with a
+//! pointer receiver only belong to `*T`. When an interface needs one of
+//! them, only `*T` implements it and the implementation is marked so:
+//!
+//! ```go
+//! func (j *JSONProcessor) Process(input []byte) ([]byte, error) { ... }
+//!
+//! var p DataProcessor = &JSONProcessor{} // ok
+//! var q DataProcessor = JSONProcessor{}  // Process has a pointer receiver
+//! ```
+//!
+//! Methods promoted from a `*Embedded` field belong to both; the ones with
+//! a pointer receiver promoted from an `Embedded` field only to `*T`.
+//!
+//! Methods declared in `_test.go` files on a type declared elsewhere only
+//! exist under `go test`, so they are left out of its method set unless
+//! `implements_include_tests` is set in `codanna.toml`. Types declared in
+//! test files, such as mocks, keep all their methods.
+
+use super::embeds::embedded_types_keyed;
+use super::packages::PackageKeys;
+use super::{GoSourceFile, InterfaceTable, line_of, receiver_type_name, walk_tree};
+use crate::parsing::InheritanceResolver;
+use crate::parsing::go::GoInheritanceResolver;
+use crate::parsing::go::resolution::stdlib_interface_names;
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// A declared type satisfying a declared interface
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Implementation {
+    pub type_name: String,
+    pub type_package: String,
+    pub type_file: String,
+    /// 1-based line of the type declaration
+    pub type_line: u32,
+    pub interface: String,
+    /// Empty for the predeclared `error`
+    pub interface_package: String,
+    /// Declaring file; `None` for standard library interfaces
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub interface_file: Option<String>,
+    /// 1-based line of the interface declaration
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub interface_line: Option<u32>,
+    /// Only `*Type` implements the interface: a method it requires has a
+    /// pointer receiver
+    #[serde(skip_serializing_if = "std::ops::Not::not")]
+    pub pointer_receiver: bool,
+    /// `import/path.Name` key of the interface in [`MethodSets`]
+    #[serde(skip)]
+    pub(super) interface_key: String,
+}
+
+impl fmt::Display for Implementation {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let pointer = if self.pointer_receiver { "*" } else { "" };
+        write!(
+            f,
+            "{pointer}{}.{} implements ",
+            self.type_package, self.type_name
+        )?;
+        if !self.interface_package.is_empty() {
+            write!(f, "{}.", self.interface_package)?;
+        }
+        write!(
+            f,
+            "{} ({}:{})",
+            self.interface, self.type_file, self.type_line
+        )
+    }
+}
+
+/// A type declaration: file index, name line and whether it's an interface
+struct Declaration {
+    file: usize,
+    line: u32,
+    interface: bool,
+}
+
+/// Find the declared types implementing declared interfaces, sorted by
+/// interface and type
+///
+/// Types are keyed by the import path of their package (see
+/// [`PackageKeys`]), so two packages of the same name keep their
+/// declarations apart. With `stdlib` on, well-known standard library
+/// interfaces are implemented too. With `include_tests`, methods declared in
+/// test files count for types declared outside them.
+pub fn find_implementations(
+    files: &[GoSourceFile],
+    include_tests: bool,
+    stdlib: StdlibResolution,
+) -> Vec<Implementation> {
+    MethodSets::build(files, include_tests, stdlib).implementations(files)
+}
+
+/// Method sets of the types and interfaces declared in a set of files,
+/// keyed by `import/path.Name`
+pub(super) struct MethodSets {
+    declarations: BTreeMap<String, Declaration>,
+    /// Shapes of the methods declared on each type
+    method_shapes: HashMap<(String, String), MethodShape>,
+    /// Shapes of the methods each interface declares itself
+    interface_shapes: HashMap<String, Vec<(String, MethodShape)>>,
+    /// Interfaces embedded by each interface
+    interface_embeds: HashMap<String, Vec<String>>,
+    /// Methods declared with a pointer receiver, by type
+    pointer_methods: HashSet<(String, String)>,
+    /// Types embedded by each struct, with whether through a pointer
+    struct_embeds: HashMap<String, Vec<(String, bool)>>,
+    resolver: GoInheritanceResolver,
+}
+
+impl MethodSets {
+    /// Register the method sets of the declarations of `files`
+    ///
+    /// With `include_tests`, methods declared in test files count for types
+    /// declared outside them.
+    pub(super) fn build(
+        files: &[GoSourceFile],
+        include_tests: bool,
+        stdlib: StdlibResolution,
+    ) -> Self {
+        let mut declarations: BTreeMap<String, Declaration> = BTreeMap::new();
+        // Method names by type, with whether they're declared in a test file
+        let mut methods: HashMap<String, Vec<(String, bool)>> = HashMap::new();
+        // Shapes of the methods declared on each type, and of the methods each
+        // interface declares itself
+        let mut method_shapes: HashMap<(String, String), MethodShape> = HashMap::new();
+        let mut interface_shapes: HashMap<String, Vec<(String, MethodShape)>> = HashMap::new();
+        // Interfaces embedded by each interface
+        let mut interface_embeds: HashMap<String, Vec<String>> = HashMap::new();
+        let mut pointer_methods: HashSet<(String, String)> = HashSet::new();
+        let mut struct_embeds: HashMap<String, Vec<(String, bool)>> = HashMap::new();
+        let mut resolver = GoInheritanceResolver::new();
+        resolver.set_stdlib_resolution(stdlib);
+        let packages = PackageKeys::build(files);
+        let interfaces = InterfaceTable::build_keyed(
+            files,
+            stdlib,
+            |file| packages.package_of(file),
+            |file, t, aliases| packages.type_key(file, t, aliases),
+        );
+
+        for (index, file) in files.iter().enumerate() {
+            let package = packages.package_of(file);
+            let aliases = file.import_aliases();
+            let key_of = |t: Node| packages.type_key(file, t, &aliases);
+            walk_tree(file.root(), &mut |node| match node.kind() {
+                "method_declaration" => {
+                    let (Some(receiver), Some(name)) = (
+                        receiver_type_name(file, node),
+                        node.child_by_field_name("name"),
+                    ) else {
+                        return;
+                    };
+                    let key = format!("{package}.{receiver}");
+                    let name = file.text(name).to_string();
+                    if let Some(shape) = MethodShape::of(node) {
+                        method_shapes.insert((key.clone(), name.clone()), shape);
+                    }
+                    if has_pointer_receiver(node) {
+                        pointer_methods.insert((key.clone(), name.clone()));
+                    }
+                    methods.entry(key).or_default().push((name, file.is_test()));
+                }
+                "type_spec" => {
+                    let (Some(name), Some(body)) = (
+                        node.child_by_field_name("name"),
+                        node.child_by_field_name("type"),
+                    ) else {
+                        return;
+                    };
+                    let key = format!("{package}.{}", file.text(name));
+                    let interface = body.kind() == "interface_type";
+                    if body.kind() == "struct_type" {
+                        let embedded: Vec<_> = embedded_types_keyed(body, key_of)
+                            .into_iter()
+                            .map(|(key, written)| (key, written.kind() == "pointer_type"))
+                            .collect();
+                        if !embedded.is_empty() {
+                            resolver.add_struct_embeds(
+                                key.clone(),
+                                embedded.iter().map(|(key, _)| key.clone()).collect(),
+                            );
+                            struct_embeds.insert(key.clone(), embedded);
+                        }
+                    }
+                    if let Some(decl) = interfaces.get(&key).filter(|_| interface) {
+                        for method in &decl.methods {
+                            if let Some(shape) = MethodShape::of(method.element) {
+                                interface_shapes
+                                    .entry(key.clone())
+                                    .or_default()
+                                    .push((method.name.to_string(), shape));
+                            }
+                        }
+                        methods.entry(key.clone()).or_default().extend(
+                            decl.methods
+                                .iter()
+                                .map(|m| (m.name.to_string(), file.is_test())),
+                        );
+                        if !decl.embeds.is_empty() {
+                            let embedded: Vec<_> =
+                                decl.embeds.iter().map(|(key, _)| key.clone()).collect();
+                            interface_embeds.insert(key.clone(), embedded.clone());
+                            resolver.add_interface_embeds(key.clone(), embedded);
+                        }
+                    }
+                    declarations.insert(
+                        key,
+                        Declaration {
+                            file: index,
+                            line: line_of(name),
+                            interface,
+                        },
+                    );
+                }
+                _ => {}
+            });
+        }
+        for (key, type_methods) in methods {
+            let declared_in_test = declarations
+                .get(&key)
+                .is_none_or(|d| files[d.file].is_test());
+            let type_methods = type_methods
+                .into_iter()
+                .filter(|(_, in_test)| include_tests || declared_in_test || !in_test)
+                .map(|(name, _)| name)
+                .collect();
+            resolver.add_type_methods(key, type_methods);
+        }
+
+        Self {
+            declarations,
+            method_shapes,
+            interface_shapes,
+            interface_embeds,
+            pointer_methods,
+            struct_embeds,
+            resolver,
+        }
+    }
+
+    /// Whether values of the type have `method`, not only pointers to it
+    ///
+    /// Its own methods count unless they have a pointer receiver; promoted
+    /// ones count when embedded through a pointer, or in the value method
+    /// set of a struct embedded by value. Interfaces and types declared
+    /// outside the files provide all their methods.
+    fn in_value_method_set(&self, type_key: &str, method: &str) -> bool {
+        let mut types = vec![type_key];
+        let mut visited = HashSet::new();
+        while let Some(current) = types.pop() {
+            if !visited.insert(current) {
+                continue;
+            }
+            if self.resolver.type_has_method(current, method) {
+                if !self
+                    .pointer_methods
+                    .contains(&(current.to_string(), method.to_string()))
+                {
+                    return true;
+                }
+                continue;
+            }
+            for (embedded, pointer) in self.struct_embeds.get(current).into_iter().flatten() {
+                let declared_type = self
+                    .declarations
+                    .get(embedded)
+                    .is_some_and(|d| !d.interface);
+                if *pointer || !declared_type {
+                    // Interfaces and types declared elsewhere: all methods
+                    if self
+                        .resolver
+                        .get_all_methods(embedded)
+                        .iter()
+                        .any(|m| m == method)
+                    {
+                        return true;
+                    }
+                } else {
+                    types.push(embedded);
+                }
+            }
+        }
+        false
+    }
+
+    /// Methods an interface requires, its embedded interfaces' included
+    pub(super) fn interface_methods(&self, interface_key: &str) -> Vec<String> {
+        self.resolver.get_all_methods(interface_key)
+    }
+
+    /// Every declared type satisfying a declared or well-known interface,
+    /// sorted by interface and type
+    pub(super) fn implementations(&self, files: &[GoSourceFile]) -> Vec<Implementation> {
+        let Self {
+            declarations,
+            method_shapes,
+            interface_shapes,
+            interface_embeds,
+            resolver,
+            ..
+        } = self;
+        let interfaces = declarations
+            .iter()
+            .filter(|(_, d)| d.interface)
+            .map(|(key, d)| (key.as_str(), Some(d)))
+            .chain(stdlib_interface_names(resolver.stdlib_resolution()).map(|name| (name, None)));
+
+        let mut implementations = Vec::new();
+        for (interface_key, interface) in interfaces {
+            for (type_key, declared) in declarations.iter().filter(|(_, d)| !d.interface) {
+                if !resolver.check_struct_implements_interface(type_key, interface_key)
+                    || !shapes_match(
+                        method_shapes,
+                        interface_shapes,
+                        interface_embeds,
+                        type_key,
+                        interface_key,
+                    )
+                {
+                    continue;
+                }
+                let Some((_, type_name)) = type_key.rsplit_once('.') else {
+                    continue;
+                };
+                let (interface_path, interface_name) = interface_key
+                    .rsplit_once('.')
+                    .unwrap_or(("", interface_key));
+                // Shown by package name; standard library import paths end with it
+                let interface_package = match interface {
+                    Some(i) => files[i.file].package_name().unwrap_or_default(),
+                    None => interface_path.rsplit('/').next().unwrap_or(interface_path),
+                };
+                let pointer_receiver = resolver
+                    .get_all_methods(interface_key)
+                    .iter()
+                    .any(|method| !self.in_value_method_set(type_key, method));
+                implementations.push(Implementation {
+                    type_name: type_name.to_string(),
+                    type_package: files[declared.file]
+                        .package_name()
+                        .unwrap_or_default()
+                        .to_string(),
+                    type_file: files[declared.file].display_path(),
+                    type_line: declared.line,
+                    interface: interface_name.to_string(),
+                    interface_package: interface_package.to_string(),
+                    interface_file: interface.map(|i| files[i.file].display_path()),
+                    interface_line: interface.map(|i| i.line),
+                    pointer_receiver,
+                    interface_key: interface_key.to_string(),
+                });
+            }
+        }
+        implementations
+    }
+}
+
+/// Parameter and result counts of a method, as declared
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+struct MethodShape {
+    parameters: usize,
+    /// Whether the last parameter is `...T`
+    variadic: bool,
+    results: usize,
+}
+
+impl MethodShape {
+    /// Shape of a method declaration or interface method element
+    fn of(method: Node) -> Option<Self> {
+        let mut shape = Self {
+            parameters: 0,
+            variadic: false,
+            results: 0,
+        };
+        let parameters = method.child_by_field_name("parameters")?;
+        for parameter in parameters.named_children(&mut parameters.walk()) {
+            match parameter.kind() {
+                "parameter_declaration" => shape.parameters += names(parameter),
+                "variadic_parameter_declaration" => {
+                    shape.parameters += 1;
+                    shape.variadic = true;
+                }
+                _ => {}
+            }
+        }
+        shape.results = match method.child_by_field_name("result") {
+            None => 0,
+            Some(result) if result.kind() == "parameter_list" => result
+                .named_children(&mut result.walk())
+                .filter(|r| r.kind() == "parameter_declaration")
+                .map(names)
+                .sum(),
+            Some(_) => 1,
+        };
+        Some(shape)
+    }
+}
+
+/// Whether a method declaration's receiver is a pointer: `func (s *Stack[T])`
+fn has_pointer_receiver(method: Node) -> bool {
+    let mut receiver_type = super::receiver_type_node(method);
+    while let Some(node) = receiver_type.filter(|n| n.kind() == "parenthesized_type") {
+        receiver_type = node.named_child(0);
+    }
+    receiver_type.is_some_and(|n| n.kind() == "pointer_type")
+}
+
+/// Number of parameters a declaration introduces: `a, b int` is two
+fn names(parameter: Node) -> usize {
+    parameter
+        .children_by_field_name("name", &mut parameter.walk())
+        .count()
+        .max(1)
+}
+
+/// Whether the methods an interface and its embedded interfaces declare have
+/// the shape of the type's own methods of the same name
+///
+/// Promoted methods and standard library interfaces have no recorded shape
+/// and always match.
+fn shapes_match(
+    method_shapes: &HashMap<(String, String), MethodShape>,
+    interface_shapes: &HashMap<String, Vec<(String, MethodShape)>>,
+    interface_embeds: &HashMap<String, Vec<String>>,
+    type_key: &str,
+    interface_key: &str,
+) -> bool {
+    let mut interfaces = vec![interface_key];
+    let mut visited = HashSet::new();
+    while let Some(interface) = interfaces.pop() {
+        if !visited.insert(interface) {
+            continue;
+        }
+        interfaces.extend(
+            interface_embeds
+                .get(interface)
+                .into_iter()
+                .flatten()
+                .map(String::as_str),
+        );
+        for (method, required) in interface_shapes.get(interface).into_iter().flatten() {
+            let declared = method_shapes.get(&(type_key.to_string(), method.clone()));
+            if declared.is_some_and(|declared| declared != required) {
+                return false;
+            }
+        }
+    }
+    true
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_implementations_across_packages() {
+        let models = r#"
+package models
+
+type User struct{ ID string }
+
+type Store interface {
+    Save(u *User) error
+    Load(id string) (*User, error)
+}
+
+type auditedStore interface {
+    Store
+    audit()
+}
+
+type memoryStore struct{}
+
+func (m *memoryStore) Save(u *User) error { return nil }
+func (m *memoryStore) Load(id string) (*User, error) { return nil, nil }
+func (m *memoryStore) audit() {}
+"#;
+        let services = r#"
+package services
+
+import "example.com/app/models"
+
+type UserStore struct{}
+
+func (s *UserStore) Save(u *models.User) error { return nil }
+func (s *UserStore) Load(id string) (*models.User, error) { return nil, nil }
+func (s *UserStore) audit() {}
+"#;
+        let files = vec![
+            parse("app/models/store.go", models),
+            parse("app/services/user_store.go", services),
+        ];
+        let found: Vec<_> = find_implementations(&files, false, StdlibResolution::Builtin)
+            .iter()
+            .map(|i| {
+                format!(
+                    "{}.{} -> {}.{}",
+                    i.type_package, i.type_name, i.interface_package, i.interface
+                )
+            })
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                "models.memoryStore -> models.Store",
+                "services.UserStore -> models.Store",
+                "models.memoryStore -> models.auditedStore",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_packages_sharing_a_name_keep_their_interfaces_apart() {
+        let models = "package models\n\ntype Store interface {\n\tSave() error\n}\n";
+        let legacy =
+            "package models\n\ntype Store interface {\n\tSave() error\n\tClose() error\n}\n";
+        let services = "package services\n\ntype MemStore struct{}\n\nfunc (m *MemStore) Save() error { return nil }\n";
+        let files = vec![
+            parse("app/models/store.go", models),
+            parse("app/legacy/models/store.go", legacy),
+            parse("app/services/mem_store.go", services),
+        ];
+        let found: Vec<_> = find_implementations(&files, false, StdlibResolution::Builtin)
+            .into_iter()
+            .filter(|i| i.type_name == "MemStore" && i.interface == "Store")
+            .filter_map(|i| i.interface_file)
+            .collect();
+        assert_eq!(found, vec!["app/models/store.go"]);
+    }
+
+    #[test]
+    fn test_test_file_methods_only_count_when_included() {
+        let fixture = std::path::Path::new("tests/fixtures/go/module_project");
+        let files: Vec<_> = [
+            "models/store.go",
+            "services/user_store.go",
+            "services/user_store_test.go",
+        ]
+        .iter()
+        .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+        .collect();
+        let found = |include_tests| -> Vec<String> {
+            find_implementations(&files, include_tests, StdlibResolution::Builtin)
+                .iter()
+                .filter(|i| i.interface_file.is_some())
+                .map(|i| format!("{} -> {}", i.type_name, i.interface))
+                .collect()
+        };
+        assert_eq!(
+            found(false),
+            vec![
+                "fakeStore -> ResettableStore",
+                "UserStore -> Store",
+                "fakeStore -> Store",
+            ]
+        );
+        assert_eq!(
+            found(true),
+            vec![
+                "UserStore -> ResettableStore",
+                "fakeStore -> ResettableStore",
+                "UserStore -> Store",
+                "fakeStore -> Store",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_promoted_and_declared_methods_satisfy_interface() {
+        let file =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/interfaces.go")).unwrap();
+        let found: Vec<_> = find_implementations(
+            std::slice::from_ref(&file),
+            false,
+            StdlibResolution::Builtin,
+        )
+        .iter()
+        .filter(|i| i.type_name == "ClosingWriter")
+        .map(|i| i.to_string())
+        .collect();
+        assert!(
+            found
+                .iter()
+                .any(|i| i.starts_with("interfaces.ClosingWriter implements io.WriteCloser")),
+            "{found:?}"
+        );
+        // io.Writer alone doesn't provide Read
+        assert!(!found.iter().any(|i| i.contains("io.ReadWriteCloser")));
+    }
+
+    #[test]
+    fn test_variadic_methods_must_match_in_shape() {
+        let code = r#"
+package logging
+
+type Logger interface {
+    Log(level string, format string, args ...interface{})
+}
+
+type LevelLogger interface {
+    Logger
+    SetLevel(level string)
+}
+
+type SimpleLogger struct{}
+
+func (l *SimpleLogger) Log(level string, format string, args ...interface{}) {}
+
+type GroupedLogger struct{}
+
+func (l *GroupedLogger) Log(level, format string, args ...interface{}) {}
+func (l *GroupedLogger) SetLevel(level string)                         {}
+
+type SliceLogger struct{}
+
+func (l *SliceLogger) Log(level string, format string, args []interface{}) {}
+func (l *SliceLogger) SetLevel(level string)                               {}
+"#;
+        let files = [parse("logging/logger.go", code)];
+        let found: Vec<_> = find_implementations(&files, false, StdlibResolution::Builtin)
+            .iter()
+            .filter(|i| i.interface_file.is_some())
+            .map(|i| format!("{} -> {}", i.type_name, i.interface))
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                "GroupedLogger -> LevelLogger",
+                "GroupedLogger -> Logger",
+                "SimpleLogger -> Logger",
+            ]
+        );
+
+        let fixture =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/interfaces.go")).unwrap();
+        assert!(
+            find_implementations(
+                std::slice::from_ref(&fixture),
+                false,
+                StdlibResolution::Builtin
+            )
+            .iter()
+            .any(|i| i.type_name == "SimpleLogger" && i.interface == "Logger")
+        );
+    }
+
+    #[test]
+    fn test_pointer_receiver_only_satisfaction() {
+        let fixture =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/interfaces.go")).unwrap();
+        let found = find_implementations(
+            std::slice::from_ref(&fixture),
+            false,
+            StdlibResolution::Builtin,
+        );
+        for processor in ["FileProcessor", "JSONProcessor"] {
+            let implementation = found
+                .iter()
+                .find(|i| i.type_name == processor && i.interface == "DataProcessor")
+                .unwrap_or_else(|| panic!("{processor} should implement DataProcessor"));
+            assert!(implementation.pointer_receiver);
+            assert!(
+                implementation
+                    .to_string()
+                    .starts_with(&format!("*interfaces.{processor} implements"))
+            );
+        }
+        // io.Writer is embedded by value and provides all its methods
+        let closing = found
+            .iter()
+            .find(|i| i.type_name == "ClosingWriter" && i.interface == "WriteCloser")
+            .unwrap();
+        assert!(!closing.pointer_receiver);
+
+        let code = r#"
+package cache
+
+type Closer interface {
+    Close() error
+}
+
+type handle struct{}
+
+func (h *handle) Close() error { return nil }
+
+type valueEntry struct {
+    handle
+}
+
+type pointerEntry struct {
+    *handle
+}
+
+type plainEntry struct{}
+
+func (p plainEntry) Close() error { return nil }
+"#;
+        let files = [parse("cache/entry.go", code)];
+        let found: Vec<_> = find_implementations(&files, false, StdlibResolution::Builtin)
+            .iter()
+            .filter(|i| i.interface == "Closer" && i.interface_file.is_some())
+            .map(|i| format!("{} {}", i.type_name, i.pointer_receiver))
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                "handle true",
+                "plainEntry false",
+                "pointerEntry false",
+                "valueEntry true",
+            ]
+        );
+    }
+}
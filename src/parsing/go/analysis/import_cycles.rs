@@ -0,0 +1,152 @@
+//! Cycles in the import graph of the indexed packages
+//!
+//! Go rejects a package that imports itself, directly or through other
+//! packages:
+//!
+//! ```text
+//! example.com/app/orders imports example.com/app/billing
+//! example.com/app/billing imports example.com/app/orders
+//! ```
+//!
+//! Such code still shows up mid-refactor, and a cycle in the graph the
+//! indexer builds can also point at imports it resolves wrongly. Cycles are
+//! found on the [`PackageGraph`], starting from each package in order and
+//! following the shortest path back to it; a package already on a reported
+//! cycle doesn't start another. Only resolved imports take part, so an
+//! import the graph can't follow never closes a cycle; those imports are
+//! reported separately by [`PackageGraph::unresolved`].
+
+use super::packages::{PackageGraph, PackageImport};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet, HashSet, VecDeque};
+use std::fmt;
+
+/// Packages importing each other in a circle
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ImportCycle {
+    /// Packages in import order, each importing the next and the last the
+    /// first
+    pub packages: Vec<String>,
+    /// The import closing each step, in the same order
+    pub imports: Vec<PackageImport>,
+}
+
+impl fmt::Display for ImportCycle {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "import cycle: {}", self.packages.join(" -> "))?;
+        if let Some(first) = self.packages.first() {
+            write!(f, " -> {first}")?;
+        }
+        for import in &self.imports {
+            write!(f, "\n  {import}")?;
+        }
+        Ok(())
+    }
+}
+
+/// Find the import cycles of a package graph, sorted by their first package
+pub fn find_import_cycles(graph: &PackageGraph) -> Vec<ImportCycle> {
+    let dependencies = graph.dependencies();
+    let mut covered: HashSet<&str> = HashSet::new();
+    let mut cycles = Vec::new();
+    for &start in dependencies.keys() {
+        if covered.contains(start) {
+            continue;
+        }
+        let Some(packages) = shortest_cycle(&dependencies, start) else {
+            continue;
+        };
+        covered.extend(packages.iter().copied());
+        let imports = packages
+            .iter()
+            .zip(packages.iter().cycle().skip(1))
+            .filter_map(|(from, to)| {
+                graph
+                    .imports
+                    .iter()
+                    .find(|i| i.from == *from && i.to.as_deref() == Some(*to))
+                    .cloned()
+            })
+            .collect();
+        cycles.push(ImportCycle {
+            packages: packages.into_iter().map(str::to_string).collect(),
+            imports,
+        });
+    }
+    cycles
+}
+
+/// Packages of the shortest path from `start` back to itself, starting with
+/// `start`
+fn shortest_cycle<'g>(
+    dependencies: &BTreeMap<&'g str, BTreeSet<&'g str>>,
+    start: &'g str,
+) -> Option<Vec<&'g str>> {
+    let mut previous: BTreeMap<&str, &str> = BTreeMap::new();
+    let mut queue = VecDeque::from([start]);
+    while let Some(package) = queue.pop_front() {
+        for &next in dependencies.get(package).into_iter().flatten() {
+            if next == start {
+                let mut path = vec![package];
+                while let Some(&before) = previous.get(path[path.len() - 1]) {
+                    path.push(before);
+                }
+                path.reverse();
+                return Some(path);
+            }
+            if !previous.contains_key(next) {
+                previous.insert(next, package);
+                queue.push_back(next);
+            }
+        }
+    }
+    None
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::parsing::go::analysis::GoSourceFile;
+
+    #[test]
+    fn test_cycles_and_unresolved_imports() {
+        let fixture = std::path::Path::new("tests/fixtures/go/cycle_project");
+        let files: Vec<_> = [
+            "orders/orders.go",
+            "billing/billing.go",
+            "customers/customers.go",
+            "reports/reports.go",
+        ]
+        .iter()
+        .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+        .collect();
+        let graph = PackageGraph::build(&files);
+
+        let cycles = find_import_cycles(&graph);
+        assert_eq!(cycles.len(), 1);
+        assert_eq!(
+            cycles[0].packages,
+            vec![
+                "example.com/shop/billing",
+                "example.com/shop/customers",
+                "example.com/shop/orders",
+            ]
+        );
+        assert_eq!(cycles[0].imports.len(), 3);
+        assert!(cycles[0].to_string().starts_with(
+            "import cycle: example.com/shop/billing -> example.com/shop/customers -> \
+             example.com/shop/orders -> example.com/shop/billing\n  \
+             example.com/shop/billing imports example.com/shop/customers at "
+        ));
+
+        // reports imports a package that isn't there: not a cycle
+        let unresolved: Vec<_> = graph
+            .unresolved()
+            .map(|i| (i.from.as_str(), i.import_path.as_str()))
+            .collect();
+        assert_eq!(
+            unresolved,
+            vec![("example.com/shop/reports", "example.com/shop/archive")]
+        );
+    }
+}
@@ -0,0 +1,214 @@
+//! Functions using the symbols of an imported package
+//!
+//! An import statement only says that a file depends on a package. How deeply
+//! it does shows in the selectors through the package name:
+//!
+//! ```go
+//! import "example.com/app/models"
+//!
+//! func Login(name string) (*models.User, error) {
+//!     user := models.NewUser(name)
+//!     ...
+//! }
+//! ```
+//!
+//! `Login` uses `models.User` and `models.NewUser`. Both expression
+//! selectors and qualified types count, under the package's local name
+//! (its alias when renamed). Uses outside any function, in package-level
+//! declarations, are reported without a function. The import path matches
+//! when it equals the query or ends with `/` and the query, so `app/models`
+//! finds `example.com/app/models`.
+
+use super::{GoSourceFile, enclosing_function_name, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet};
+use std::fmt;
+
+/// A function (or the package level of a file) using an imported package
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ImportUser {
+    /// Import path as written in the import statement
+    pub import_path: String,
+    /// Function or method using the package; `None` at package level
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the first use
+    pub line: u32,
+    /// Referenced symbols, e.g. `models.User`, sorted
+    pub symbols: Vec<String>,
+    /// Number of references, counting repeats
+    pub references: usize,
+}
+
+impl fmt::Display for ImportUser {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match &self.function {
+            Some(function) => write!(f, "{function}")?,
+            None => write!(f, "package level")?,
+        }
+        write!(
+            f,
+            " uses {} ({} {}) at {}:{}",
+            self.symbols.join(", "),
+            self.references,
+            if self.references == 1 {
+                "reference"
+            } else {
+                "references"
+            },
+            self.file,
+            self.line
+        )
+    }
+}
+
+/// Find the functions using the package imported as `import_path`, sorted by
+/// file and line
+///
+/// Returns `None` when no file imports the path.
+pub fn find_import_users(files: &[GoSourceFile], import_path: &str) -> Option<Vec<ImportUser>> {
+    let mut imported = false;
+    let mut users = Vec::new();
+    for file in files {
+        let locals: BTreeMap<String, String> = file
+            .import_aliases()
+            .into_iter()
+            .filter(|(_, path)| matches_import(path, import_path))
+            .collect();
+        if locals.is_empty() {
+            continue;
+        }
+        imported = true;
+
+        // (function, first line, symbols, references), keyed by function
+        let mut by_function: BTreeMap<Option<String>, (String, u32, BTreeSet<String>, usize)> =
+            BTreeMap::new();
+        walk_tree(file.root(), &mut |node| {
+            let (package, name) = match node.kind() {
+                "selector_expression" => (
+                    node.child_by_field_name("operand"),
+                    node.child_by_field_name("field"),
+                ),
+                "qualified_type" => (
+                    node.child_by_field_name("package"),
+                    node.child_by_field_name("name"),
+                ),
+                _ => return,
+            };
+            let (Some(package), Some(name)) = (package, name) else {
+                return;
+            };
+            if !matches!(package.kind(), "identifier" | "package_identifier") {
+                return;
+            }
+            let local = file.text(package);
+            let Some(path) = locals.get(local) else {
+                return;
+            };
+            let entry = by_function
+                .entry(enclosing_function_name(file, node))
+                .or_insert_with(|| (path.clone(), line_of(node), BTreeSet::new(), 0));
+            entry.1 = entry.1.min(line_of(node));
+            entry.2.insert(format!("{local}.{}", file.text(name)));
+            entry.3 += 1;
+        });
+
+        users.extend(by_function.into_iter().map(
+            |(function, (path, line, symbols, references))| ImportUser {
+                import_path: path,
+                function,
+                file: file.display_path(),
+                line,
+                symbols: symbols.into_iter().collect(),
+                references,
+            },
+        ));
+    }
+    if !imported {
+        return None;
+    }
+    users.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    Some(users)
+}
+
+/// Whether an import path is the queried one, possibly under a module prefix
+fn matches_import(path: &str, query: &str) -> bool {
+    let query = query.trim_matches('/');
+    path == query
+        || path
+            .strip_suffix(query)
+            .is_some_and(|prefix| prefix.ends_with('/'))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_import_users_by_function() {
+        let code = r#"
+package auth
+
+import (
+    "fmt"
+
+    m "example.com/app/models"
+)
+
+var defaultRole = m.RoleUser
+
+func Login(name string) (*m.User, error) {
+    user := m.NewUser(name)
+    user.Role = m.RoleUser
+    return user, nil
+}
+
+func Logout() {
+    fmt.Println("bye")
+}
+
+func Validate(u *m.User) error {
+    return m.Validate(u)
+}
+"#;
+        let file = GoSourceFile::parse("app/auth/auth.go", code.to_string()).unwrap();
+        let other = GoSourceFile::parse(
+            "app/cmd/main.go",
+            "package main\n\nimport \"example.com/other/models\"\n\nfunc main() { models.Run() }\n"
+                .to_string(),
+        )
+        .unwrap();
+        let files = [file, other];
+        let users = find_import_users(&files, "app/models").unwrap();
+        let summary: Vec<_> = users
+            .iter()
+            .map(|u| {
+                (
+                    u.function.as_deref(),
+                    u.line,
+                    u.symbols.join(" "),
+                    u.references,
+                )
+            })
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (None, 10, "m.RoleUser".to_string(), 1),
+                (
+                    Some("Login"),
+                    12,
+                    "m.NewUser m.RoleUser m.User".to_string(),
+                    3
+                ),
+                (Some("Validate"), 22, "m.User m.Validate".to_string(), 2),
+            ]
+        );
+        assert_eq!(
+            users[2].to_string(),
+            "Validate uses m.User, m.Validate (2 references) at app/auth/auth.go:22"
+        );
+        assert!(find_import_users(&files, "app/billing").is_none());
+    }
+}
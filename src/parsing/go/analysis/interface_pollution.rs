@@ -0,0 +1,170 @@
+//! Interfaces with a single implementor (advisory)
+//!
+//! An interface that only one type satisfies is often an abstraction
+//! introduced ahead of need:
+//!
+//! ```go
+//! type UserRepository interface {
+//!     Find(id string) (*User, error)
+//! }
+//!
+//! type postgresUserRepository struct{ db *sql.DB } // the only implementor
+//! ```
+//!
+//! Implementors are the ones [`find_implementations`] finds among the
+//! indexed types. Interfaces nothing implements are left out, as are the
+//! standard library ones, which are not declared in the project. A test
+//! double counts as an implementor, so an interface kept for mocking is not
+//! reported once the mock is indexed. A `// codanna:ignore
+//! interface-pollution` comment on the interface line or the line above
+//! suppresses the finding.
+
+use super::{GoSourceFile, find_implementations, is_suppressed};
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::fmt;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "interface-pollution";
+
+/// A declared interface with exactly one implementor
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SoleImplementor {
+    pub interface: String,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the interface declaration
+    pub line: u32,
+    /// The implementing type, as `package.Type`
+    pub implementor: String,
+    pub implementor_file: String,
+    /// 1-based line of the implementing type's declaration
+    pub implementor_line: u32,
+}
+
+impl fmt::Display for SoleImplementor {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} at {}:{} is only implemented by {} ({}:{})",
+            self.package,
+            self.interface,
+            self.file,
+            self.line,
+            self.implementor,
+            self.implementor_file,
+            self.implementor_line
+        )
+    }
+}
+
+/// Find the declared interfaces with exactly one implementor, sorted by file
+/// and line
+pub fn find_sole_implementors(
+    files: &[GoSourceFile],
+    include_tests: bool,
+    stdlib: StdlibResolution,
+) -> Vec<SoleImplementor> {
+    let mut by_interface: BTreeMap<(String, u32), Vec<_>> = BTreeMap::new();
+    for implementation in find_implementations(files, include_tests, stdlib) {
+        let (Some(file), Some(line)) = (
+            implementation.interface_file.clone(),
+            implementation.interface_line,
+        ) else {
+            continue;
+        };
+        by_interface
+            .entry((file, line))
+            .or_default()
+            .push(implementation);
+    }
+
+    let mut findings = Vec::new();
+    for ((file, line), implementations) in by_interface {
+        let [implementation] = implementations.as_slice() else {
+            continue;
+        };
+        let suppressed = files
+            .iter()
+            .find(|f| f.display_path() == file)
+            .is_some_and(|f| is_suppressed(f, line, ANALYSIS_NAME));
+        if suppressed {
+            continue;
+        }
+        findings.push(SoleImplementor {
+            interface: implementation.interface.clone(),
+            package: implementation.interface_package.clone(),
+            file,
+            line,
+            implementor: format!(
+                "{}.{}",
+                implementation.type_package, implementation.type_name
+            ),
+            implementor_file: implementation.type_file.clone(),
+            implementor_line: implementation.type_line,
+        });
+    }
+    findings
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_interfaces_with_one_implementor() {
+        let code = r#"
+package store
+
+type UserRepository interface {
+    Find(id string) (*User, error)
+}
+
+type Cache interface {
+    Get(key string) string
+}
+
+type Closer interface {
+    Close() error
+}
+
+// codanna:ignore interface-pollution
+type Clock interface {
+    Now() int64
+}
+
+type postgresUserRepository struct{}
+
+func (r *postgresUserRepository) Find(id string) (*User, error) { return nil, nil }
+func (r *postgresUserRepository) Close() error { return nil }
+
+type memoryCache struct{}
+type redisCache struct{}
+
+func (c *memoryCache) Get(key string) string { return "" }
+func (c *redisCache) Get(key string) string { return "" }
+
+type systemClock struct{}
+
+func (systemClock) Now() int64 { return 0 }
+"#;
+        let file = GoSourceFile::parse("store/store.go", code.to_string()).unwrap();
+        let findings = find_sole_implementors(&[file], false, StdlibResolution::Builtin);
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.interface.as_str(), f.implementor.as_str()))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("UserRepository", "store.postgresUserRepository"),
+                ("Closer", "store.postgresUserRepository"),
+            ]
+        );
+        assert_eq!(
+            findings[0].to_string(),
+            "store.UserRepository at store/store.go:4 is only implemented by store.postgresUserRepository (store/store.go:21)"
+        );
+    }
+}
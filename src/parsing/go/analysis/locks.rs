@@ -0,0 +1,407 @@
+//! Locks acquired without a release on every path (advisory)
+//!
+//! A `sync.Mutex` or `sync.RWMutex` locked without `defer` must be unlocked
+//! explicitly before each return, or callers block forever:
+//!
+//! ```go
+//! func (c *Cache) Set(key, value string) error {
+//!     c.mu.Lock()
+//!     if key == "" {
+//!         return ErrEmptyKey // c.mu is still locked
+//!     }
+//!     c.data[key] = value
+//!     c.mu.Unlock()
+//!     return nil
+//! }
+//! ```
+//!
+//! Each `X.Lock()` or `X.RLock()` statement is followed through the rest of
+//! its function: a return reached before `X.Unlock()` (`X.RUnlock()`), or the
+//! end of the function without one, is reported. A deferred release anywhere
+//! in the function, `defer X.Unlock()` or a deferred closure calling it,
+//! balances every acquisition. Returning the release (`return X.Unlock`)
+//! hands it to the caller and is fine. Function literals are checked as
+//! functions of their own, and methods named `Lock` or `RLock` are wrappers
+//! meant to hold the lock. A `// codanna:ignore lock-imbalance` comment on
+//! the acquiring line or the line above suppresses the finding.
+
+use super::{
+    GoSourceFile, declaration_name, enclosing_function_name, is_suppressed, line_of, walk_tree,
+};
+use serde::Serialize;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "lock-imbalance";
+
+/// Node kinds holding a sequence of statements
+const STATEMENT_CONTAINERS: &[&str] = &[
+    "block",
+    "statement_list",
+    "expression_case",
+    "default_case",
+    "type_case",
+    "communication_case",
+];
+
+/// How an acquired lock escapes its release
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum LockProblem {
+    /// The function never releases the lock
+    NeverReleased,
+    /// Some path reaches the end of the function without a release
+    NotReleasedOnAllPaths,
+    /// A return is reached before the release
+    ReturnWhileHeld,
+}
+
+/// A lock acquisition that is not released on every path
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct LockImbalance {
+    /// Function or method acquiring the lock; function literals are named
+    /// after their enclosing declaration
+    pub function: String,
+    /// Locked expression, such as `s.mu`
+    pub lock: String,
+    /// `Lock` or `RLock`
+    pub acquire: String,
+    pub problem: LockProblem,
+    /// 1-based line of the return reached while holding the lock
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub return_line: Option<u32>,
+    pub file: String,
+    /// 1-based line of the acquisition
+    pub line: u32,
+}
+
+impl fmt::Display for LockImbalance {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}: {}.{}() ", self.function, self.lock, self.acquire)?;
+        match (self.problem, self.return_line) {
+            (LockProblem::ReturnWhileHeld, Some(line)) => {
+                write!(f, "is still held at the return on line {line}")?
+            }
+            (LockProblem::ReturnWhileHeld, None) => write!(f, "is still held at a return")?,
+            (LockProblem::NeverReleased, _) => write!(f, "is never released")?,
+            (LockProblem::NotReleasedOnAllPaths, _) => write!(f, "is not released on all paths")?,
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find lock acquisitions without a release on every path, sorted by file
+/// and line
+pub fn find_lock_imbalances(files: &[GoSourceFile]) -> Vec<LockImbalance> {
+    let mut findings = Vec::new();
+    for file in files {
+        walk_tree(file.root(), &mut |node| {
+            let function = match node.kind() {
+                "function_declaration" | "method_declaration" => declaration_name(file, node),
+                "func_literal" => Some(match enclosing_function_name(file, node) {
+                    Some(outer) => format!("{outer} (func literal)"),
+                    None => "func literal".to_string(),
+                }),
+                _ => None,
+            };
+            let (Some(function), Some(body)) = (function, node.child_by_field_name("body")) else {
+                return;
+            };
+            let short_name = function.rsplit('.').next().unwrap_or(&function);
+            if matches!(short_name, "Lock" | "RLock") {
+                return;
+            }
+            findings.extend(imbalances_in_body(file, &function, body));
+        });
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Unreleased acquisitions in one function body
+fn imbalances_in_body(file: &GoSourceFile, function: &str, body: Node) -> Vec<LockImbalance> {
+    let mut acquisitions = Vec::new();
+    walk_function(body, &mut |node| {
+        if node.kind() != "expression_statement" {
+            return;
+        }
+        let Some(call) = node.named_child(0) else {
+            return;
+        };
+        if let Some((lock, method)) = lock_call(file, call) {
+            if matches!(method, "Lock" | "RLock") {
+                acquisitions.push((node, lock, method));
+            }
+        }
+    });
+
+    let mut findings = Vec::new();
+    for (statement, lock, acquire) in acquisitions {
+        let release = if acquire == "RLock" {
+            "RUnlock"
+        } else {
+            "Unlock"
+        };
+        let lock_ops = LockOps {
+            file,
+            lock,
+            release,
+        };
+        if lock_ops.has_deferred_release(body) {
+            continue;
+        }
+        let line = line_of(statement);
+        if is_suppressed(file, line, ANALYSIS_NAME) {
+            continue;
+        }
+        if let Some((problem, return_line)) = lock_ops.release_problem(body, statement) {
+            findings.push(LockImbalance {
+                function: function.to_string(),
+                lock: lock.to_string(),
+                acquire: acquire.to_string(),
+                problem,
+                return_line,
+                file: file.display_path(),
+                line,
+            });
+        }
+    }
+    findings
+}
+
+/// Split a call like `s.mu.Lock()` into (`s.mu`, `Lock`)
+fn lock_call<'s>(file: &'s GoSourceFile, call: Node) -> Option<(&'s str, &'s str)> {
+    if call.kind() != "call_expression" {
+        return None;
+    }
+    let arguments = call.child_by_field_name("arguments")?;
+    if arguments.named_child_count() != 0 {
+        return None;
+    }
+    let function = call.child_by_field_name("function")?;
+    if function.kind() != "selector_expression" {
+        return None;
+    }
+    let operand = function.child_by_field_name("operand")?;
+    let field = function.child_by_field_name("field")?;
+    Some((file.text(operand), file.text(field)))
+}
+
+/// Visit the nodes of a function body, without entering function literals
+fn walk_function<'t>(node: Node<'t>, visit: &mut dyn FnMut(Node<'t>)) {
+    visit(node);
+    for child in node.children(&mut node.walk()) {
+        if child.kind() != "func_literal" {
+            walk_function(child, visit);
+        }
+    }
+}
+
+/// Release checks for one lock expression
+struct LockOps<'a> {
+    file: &'a GoSourceFile,
+    lock: &'a str,
+    release: &'a str,
+}
+
+impl LockOps<'_> {
+    fn is_release_call(&self, node: Node) -> bool {
+        lock_call(self.file, node) == Some((self.lock, self.release))
+    }
+
+    /// `X.Unlock()` as a statement of its own
+    fn is_release_statement(&self, node: Node) -> bool {
+        node.kind() == "expression_statement"
+            && node.named_child(0).is_some_and(|c| self.is_release_call(c))
+    }
+
+    /// Whether `node` calls the release anywhere, outside function literals
+    fn contains_release(&self, node: Node) -> bool {
+        let mut found = false;
+        walk_function(node, &mut |n| found |= self.is_release_call(n));
+        found
+    }
+
+    /// `defer X.Unlock()` or `defer func() { ... X.Unlock() ... }()`
+    fn has_deferred_release(&self, body: Node) -> bool {
+        let mut found = false;
+        walk_function(body, &mut |node| {
+            if node.kind() == "defer_statement" {
+                walk_tree(node, &mut |n| found |= self.is_release_call(n));
+            }
+        });
+        found
+    }
+
+    /// Follow the statements after the acquisition to the end of the body
+    ///
+    /// Leaving a block continues after the statement containing it, so the
+    /// other branch of an `if` is never mistaken for what follows.
+    fn release_problem(&self, body: Node, acquisition: Node) -> Option<(LockProblem, Option<u32>)> {
+        let mut current = acquisition;
+        loop {
+            let mut sibling = current.next_named_sibling();
+            while let Some(statement) = sibling {
+                if self.is_release_statement(statement) {
+                    return None;
+                }
+                if let Some(ret) = self.unreleased_return(statement) {
+                    return Some((LockProblem::ReturnWhileHeld, Some(line_of(ret))));
+                }
+                sibling = statement.next_named_sibling();
+            }
+
+            let Some(parent) = current.parent() else {
+                break;
+            };
+            if parent.id() == body.id() {
+                break;
+            }
+            current = parent;
+            while let Some(up) = current.parent() {
+                if up.id() == body.id() || STATEMENT_CONTAINERS.contains(&up.kind()) {
+                    break;
+                }
+                current = up;
+            }
+        }
+
+        let problem = if self.contains_release(body) {
+            LockProblem::NotReleasedOnAllPaths
+        } else {
+            LockProblem::NeverReleased
+        };
+        Some((problem, None))
+    }
+
+    /// A return inside `statement` not preceded by a release on its way
+    fn unreleased_return<'t>(&self, statement: Node<'t>) -> Option<Node<'t>> {
+        let handoff = format!("{}.{}", self.lock, self.release);
+        let mut found = None;
+        walk_function(statement, &mut |node| {
+            if found.is_some() || node.kind() != "return_statement" {
+                return;
+            }
+            if self.file.text(node).contains(&handoff) || self.released_before(node, statement) {
+                return;
+            }
+            found = Some(node);
+        });
+        found
+    }
+
+    /// Whether a release statement precedes `node` in one of its enclosing
+    /// statement sequences, up to `top`
+    fn released_before(&self, node: Node, top: Node) -> bool {
+        let mut current = node;
+        while current.id() != top.id() {
+            let mut sibling = current.prev_named_sibling();
+            while let Some(previous) = sibling {
+                if self.is_release_statement(previous) {
+                    return true;
+                }
+                sibling = previous.prev_named_sibling();
+            }
+            match current.parent() {
+                Some(parent) => current = parent,
+                None => break,
+            }
+        }
+        false
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_unreleased_locks() {
+        let code = r#"
+package cache
+
+type Cache struct {
+    mu   sync.RWMutex
+    data map[string]string
+}
+
+func (c *Cache) Get(k string) string {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.data[k]
+}
+
+func (c *Cache) Set(k, v string) error {
+    c.mu.Lock()
+    if k == "" {
+        return errEmpty
+    }
+    c.data[k] = v
+    c.mu.Unlock()
+    return nil
+}
+
+func (c *Cache) Delete(k string) {
+    c.mu.Lock()
+    if k == "" {
+        c.mu.Unlock()
+        return
+    }
+    delete(c.data, k)
+    c.mu.Unlock()
+}
+
+func (c *Cache) Reset() {
+    c.mu.Lock()
+    c.data = nil
+}
+
+func (c *Cache) Swap(k, v string) {
+    c.mu.Lock()
+    if k != "" {
+        c.data[k] = v
+        c.mu.Unlock()
+    }
+}
+
+func (c *Cache) Lock() {
+    c.mu.Lock()
+}
+
+func (c *Cache) Each(f func(string)) {
+    go func() {
+        c.mu.RLock()
+        for _, v := range c.data {
+            f(v)
+        }
+    }()
+}
+"#;
+        let file = GoSourceFile::parse("cache/cache.go", code.to_string()).unwrap();
+        let findings = find_lock_imbalances(&[file]);
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.function.as_str(), f.problem, f.line, f.return_line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("Cache.Set", LockProblem::ReturnWhileHeld, 16, Some(18)),
+                ("Cache.Reset", LockProblem::NeverReleased, 36, None),
+                ("Cache.Swap", LockProblem::NotReleasedOnAllPaths, 41, None),
+                (
+                    "Cache.Each (func literal)",
+                    LockProblem::NeverReleased,
+                    54,
+                    None
+                ),
+            ]
+        );
+        assert_eq!(findings[0].lock, "c.mu");
+        assert_eq!(
+            findings[0].to_string(),
+            "Cache.Set: c.mu.Lock() is still held at the return on line 18 at cache/cache.go:16"
+        );
+    }
+}
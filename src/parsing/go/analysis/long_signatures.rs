@@ -0,0 +1,222 @@
+//! Functions and methods taking many parameters (advisory)
+//!
+//! A long parameter list is a refactoring smell: the arguments often belong
+//! together in a struct, or the function does more than one thing.
+//!
+//! ```go
+//! func ComplexFunction[T any, U fmt.Stringer](
+//!     reference string,
+//!     mutable *[]T,
+//!     owned string,
+//!     generic U,
+//!     closure func() T,
+//! ) (string, error)
+//! ```
+//!
+//! takes five value parameters and two type parameters, counted apart. A
+//! declaration is reported when its value parameters exceed the threshold
+//! for its kind, or its type parameters exceed the type parameter
+//! threshold, when one is set. `a, b int` is two parameters, a variadic
+//! `args ...string` one, and the receiver of a method none. A
+//! `// codanna:ignore long-signatures` comment on the declaration line or
+//! the line above suppresses the finding.
+
+use super::{GoSourceFile, declaration_name, is_suppressed, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "long-signatures";
+
+/// Value parameters allowed when no threshold is given
+pub const DEFAULT_THRESHOLD: usize = 5;
+
+/// Parameter counts allowed before a declaration is reported
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct SignatureThresholds {
+    /// Value parameters of a function
+    pub function: usize,
+    /// Value parameters of a method, its receiver left out
+    pub method: usize,
+    /// Type parameters of a generic function; not checked when `None`
+    pub type_parameters: Option<usize>,
+}
+
+impl SignatureThresholds {
+    /// The same value parameter threshold for functions and methods
+    pub fn uniform(threshold: usize) -> Self {
+        Self {
+            function: threshold,
+            method: threshold,
+            type_parameters: None,
+        }
+    }
+}
+
+impl Default for SignatureThresholds {
+    fn default() -> Self {
+        Self::uniform(DEFAULT_THRESHOLD)
+    }
+}
+
+/// A function or method whose parameters exceed a threshold
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct LongSignature {
+    /// Function name; methods as `Type.Method`
+    pub function: String,
+    /// `function` or `method`
+    pub kind: &'static str,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+    /// Value parameters, without the receiver
+    pub parameters: usize,
+    pub type_parameters: usize,
+    /// Value parameter threshold for the declaration's kind
+    pub threshold: usize,
+}
+
+impl fmt::Display for LongSignature {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{} {}.{} takes {} parameters",
+            self.kind, self.package, self.function, self.parameters
+        )?;
+        if self.type_parameters > 0 {
+            write!(f, " and {} type parameters", self.type_parameters)?;
+        }
+        write!(
+            f,
+            " (threshold {}) at {}:{}",
+            self.threshold, self.file, self.line
+        )
+    }
+}
+
+/// Find the declarations with more parameters than `thresholds` allow,
+/// sorted by file and line
+pub fn find_long_signatures(
+    files: &[GoSourceFile],
+    thresholds: SignatureThresholds,
+) -> Vec<LongSignature> {
+    let mut findings = Vec::new();
+    for file in files {
+        let Some(package) = file.package_name() else {
+            continue;
+        };
+        walk_tree(file.root(), &mut |node| {
+            let (kind, threshold) = match node.kind() {
+                "function_declaration" => ("function", thresholds.function),
+                "method_declaration" => ("method", thresholds.method),
+                _ => return,
+            };
+            let Some(function) = declaration_name(file, node) else {
+                return;
+            };
+            let parameters = node
+                .child_by_field_name("parameters")
+                .map_or(0, parameter_count);
+            let type_parameters = node
+                .child_by_field_name("type_parameters")
+                .map_or(0, parameter_count);
+            let too_generic = thresholds
+                .type_parameters
+                .is_some_and(|limit| type_parameters > limit);
+            let line = line_of(node);
+            if (parameters <= threshold && !too_generic) || is_suppressed(file, line, ANALYSIS_NAME)
+            {
+                return;
+            }
+            findings.push(LongSignature {
+                function,
+                kind,
+                package: package.to_string(),
+                file: file.display_path(),
+                line,
+                parameters,
+                type_parameters,
+                threshold,
+            });
+        });
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Parameters a parameter or type parameter list declares: `a, b int` is
+/// two, an unnamed `int` one
+fn parameter_count(list: Node) -> usize {
+    list.named_children(&mut list.walk())
+        .filter(|declaration| {
+            matches!(
+                declaration.kind(),
+                "parameter_declaration"
+                    | "variadic_parameter_declaration"
+                    | "type_parameter_declaration"
+            )
+        })
+        .map(|declaration| {
+            declaration
+                .children_by_field_name("name", &mut declaration.walk())
+                .count()
+                .max(1)
+        })
+        .sum()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parameters_and_type_parameters_counted_apart() {
+        let comprehensive =
+            GoSourceFile::read(std::path::Path::new("examples/go/comprehensive.go")).unwrap();
+        let complex =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/complex.go")).unwrap();
+        let files = [comprehensive, complex];
+
+        let found = find_long_signatures(&files, SignatureThresholds::uniform(3));
+        let complex_function = found
+            .iter()
+            .find(|f| f.function == "ComplexFunction")
+            .expect("ComplexFunction takes five parameters");
+        assert_eq!(
+            (
+                complex_function.parameters,
+                complex_function.type_parameters
+            ),
+            (5, 2)
+        );
+        assert!(
+            complex_function
+                .to_string()
+                .contains("function main.ComplexFunction takes 5 parameters and 2 type parameters")
+        );
+        let process_batch = found
+            .iter()
+            .find(|f| f.function == "ProcessBatch")
+            .expect("ProcessBatch takes four parameters");
+        assert_eq!(process_batch.parameters, 4);
+
+        // Per-kind thresholds: functions may take up to five, so only its
+        // two type parameters report ComplexFunction
+        let thresholds = SignatureThresholds {
+            function: 5,
+            method: 3,
+            type_parameters: Some(1),
+        };
+        let found = find_long_signatures(&files, thresholds);
+        assert!(found.iter().any(|f| f.function == "ComplexFunction"));
+        assert!(!found.iter().any(|f| f.function == "ProcessBatch"));
+        assert!(
+            found
+                .iter()
+                .filter(|f| f.kind == "method")
+                .all(|f| f.parameters > 3 && f.threshold == 3)
+        );
+    }
+}
@@ -0,0 +1,380 @@
+//! Closures capturing loop variables that outlive the iteration
+//!
+//! Before Go 1.22 a `for` loop declared its variables once for the whole
+//! loop, so a goroutine or deferred closure referring to one sees whatever
+//! value it holds when the closure runs, usually the last:
+//!
+//! ```go
+//! for _, job := range jobs {
+//!     go func() {
+//!         process(job) // every goroutine may process the last job
+//!     }()
+//! }
+//! ```
+//!
+//! Go 1.22 gives each iteration fresh variables, so files of a module whose
+//! `go.mod` declares `go 1.22` or later are not reported. Files with no
+//! `go.mod` above them build with the old semantics and are checked.
+//!
+//! A closure outlives the iteration when it runs in a `go` or `defer`
+//! statement, is passed to a `Go` method (errgroup and similar), is appended
+//! to a slice or is sent on a channel. A copy such as `job := job` or a
+//! closure parameter of the same name shadows the loop variable and is fine.
+
+use super::{GoSourceFile, enclosing_function_name, line_of, walk_tree};
+use crate::parsing::go::resolution::GoModInfo;
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use std::path::{Path, PathBuf};
+use tree_sitter::Node;
+
+/// A loop variable referenced from a closure that outlives the iteration
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct LoopVarCapture {
+    pub variable: String,
+    /// How the closure escapes: `go`, `defer`, `Go`, `append` or `send`
+    pub escape: &'static str,
+    /// Function or method containing the loop
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    /// `go` directive of the module's go.mod, when there is one
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub go_version: Option<String>,
+    pub file: String,
+    /// 1-based line of the captured reference
+    pub line: u32,
+    /// 1-based column of the captured reference
+    pub column: u32,
+    /// 1-based line of the loop
+    pub loop_line: u32,
+}
+
+impl fmt::Display for LoopVarCapture {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        if let Some(function) = &self.function {
+            write!(f, "{function}: ")?;
+        }
+        write!(
+            f,
+            "loop variable {} captured by closure ({}) of loop at line {} at {}:{}:{}",
+            self.variable, self.escape, self.loop_line, self.file, self.line, self.column
+        )
+    }
+}
+
+/// Whether a `go` directive gives each loop iteration its own variables
+///
+/// True from Go 1.22 on; `1.22`, `1.22.3` and `1.23rc1` are understood.
+pub fn loop_vars_per_iteration(go_version: &str) -> bool {
+    let mut parts = go_version.trim().split('.');
+    let major = parts.next().and_then(|p| p.parse::<u32>().ok());
+    let minor = parts.next().map(|p| {
+        let digits: String = p.chars().take_while(char::is_ascii_digit).collect();
+        digits.parse::<u32>().unwrap_or(0)
+    });
+    match (major, minor) {
+        (Some(major), Some(minor)) => (major, minor) >= (1, 22),
+        (Some(major), None) => major > 1,
+        _ => false,
+    }
+}
+
+/// `go` directive of the nearest go.mod in `dir` or its parents
+pub fn module_go_version(dir: &Path) -> Option<String> {
+    let go_mod = dir
+        .ancestors()
+        .map(|ancestor| ancestor.join("go.mod"))
+        .find(|candidate| candidate.is_file())?;
+    GoModInfo::read(&go_mod)?.go_version
+}
+
+/// Find loop variables captured by escaping closures, sorted by file and line
+///
+/// Files of modules on Go 1.22 or later are skipped.
+pub fn find_loop_var_captures(files: &[GoSourceFile]) -> Vec<LoopVarCapture> {
+    let mut versions: HashMap<PathBuf, Option<String>> = HashMap::new();
+    let mut findings = Vec::new();
+    for file in files {
+        let dir = file.path.parent().unwrap_or(Path::new("")).to_path_buf();
+        let version = versions
+            .entry(dir)
+            .or_insert_with_key(|dir| module_go_version(dir))
+            .clone();
+        if version.as_deref().is_some_and(loop_vars_per_iteration) {
+            continue;
+        }
+        findings.extend(
+            captures_in_file(file)
+                .into_iter()
+                .map(|capture| LoopVarCapture {
+                    go_version: version.clone(),
+                    ..capture
+                }),
+        );
+    }
+    findings.sort_by(|a, b| (&a.file, a.line, a.column).cmp(&(&b.file, b.line, b.column)));
+    findings
+}
+
+/// Captures in one file, whatever the Go version
+fn captures_in_file(file: &GoSourceFile) -> Vec<LoopVarCapture> {
+    let mut findings = Vec::new();
+    walk_tree(file.root(), &mut |node| {
+        if node.kind() != "for_statement" {
+            return;
+        }
+        let Some(body) = node.child_by_field_name("body") else {
+            return;
+        };
+        let variables = loop_variables(file, node);
+        if variables.is_empty() {
+            return;
+        }
+        walk_tree(body, &mut |closure| {
+            if closure.kind() != "func_literal" {
+                return;
+            }
+            let Some(escape) = escape_kind(file, closure) else {
+                return;
+            };
+            for variable in &variables {
+                if declared_in(file, closure, variable)
+                    || copied_before(file, body, closure, variable)
+                {
+                    continue;
+                }
+                let Some(reference) = first_reference(file, closure, variable) else {
+                    continue;
+                };
+                let position = reference.start_position();
+                findings.push(LoopVarCapture {
+                    variable: variable.to_string(),
+                    escape,
+                    function: enclosing_function_name(file, node),
+                    go_version: None,
+                    file: file.display_path(),
+                    line: line_of(reference),
+                    column: position.column as u32 + 1,
+                    loop_line: line_of(node),
+                });
+            }
+        });
+    });
+    findings.sort_by_key(|c| (c.line, c.column));
+    findings
+}
+
+/// Variables a `for` statement declares with `:=`
+fn loop_variables<'s>(file: &'s GoSourceFile, for_statement: Node) -> Vec<&'s str> {
+    let mut names = Vec::new();
+    for clause in for_statement.named_children(&mut for_statement.walk()) {
+        let left = match clause.kind() {
+            "for_clause" => clause
+                .child_by_field_name("initializer")
+                .filter(|init| init.kind() == "short_var_declaration")
+                .and_then(|init| init.child_by_field_name("left")),
+            "range_clause" => {
+                let declares = clause
+                    .children(&mut clause.walk())
+                    .any(|child| child.kind() == ":=");
+                clause.child_by_field_name("left").filter(|_| declares)
+            }
+            _ => None,
+        };
+        if let Some(left) = left {
+            names.extend(
+                left.named_children(&mut left.walk())
+                    .filter(|name| name.kind() == "identifier")
+                    .map(|name| file.text(name))
+                    .filter(|name| *name != "_"),
+            );
+        }
+    }
+    names
+}
+
+/// How `closure` outlives the loop iteration, if it does
+fn escape_kind(file: &GoSourceFile, closure: Node) -> Option<&'static str> {
+    let parent = closure.parent()?;
+    match parent.kind() {
+        "call_expression" => {
+            // `go func() {...}()` and `defer func() {...}()`
+            let called = parent
+                .child_by_field_name("function")
+                .is_some_and(|f| f.id() == closure.id());
+            match parent.parent()?.kind() {
+                "go_statement" if called => Some("go"),
+                "defer_statement" if called => Some("defer"),
+                _ => None,
+            }
+        }
+        "argument_list" => {
+            let function = parent.parent()?.child_by_field_name("function")?;
+            let name = match function.kind() {
+                "identifier" => file.text(function),
+                "selector_expression" => file.text(function.child_by_field_name("field")?),
+                _ => return None,
+            };
+            match name {
+                "append" => Some("append"),
+                "Go" => Some("Go"),
+                _ => None,
+            }
+        }
+        "send_statement" => parent
+            .child_by_field_name("value")
+            .is_some_and(|v| v.id() == closure.id())
+            .then_some("send"),
+        _ => None,
+    }
+}
+
+/// Whether `closure` declares its own `name`, as a parameter or local
+fn declared_in(file: &GoSourceFile, closure: Node, name: &str) -> bool {
+    let mut declared = false;
+    walk_tree(closure, &mut |node| {
+        let names: Vec<Node> = match node.kind() {
+            "parameter_declaration" | "variadic_parameter_declaration" | "var_spec" => node
+                .children_by_field_name("name", &mut node.walk())
+                .collect(),
+            "short_var_declaration" => node
+                .child_by_field_name("left")
+                .map(|left| left.named_children(&mut left.walk()).collect())
+                .unwrap_or_default(),
+            _ => Vec::new(),
+        };
+        if names.iter().any(|n| file.text(*n) == name) {
+            declared = true;
+        }
+    });
+    declared
+}
+
+/// Whether the loop body redeclares `name` (`job := job`) before `closure`,
+/// in a block enclosing it
+fn copied_before(file: &GoSourceFile, body: Node, closure: Node, name: &str) -> bool {
+    let mut copied = false;
+    walk_tree(body, &mut |node| {
+        if node.end_byte() > closure.start_byte() {
+            return;
+        }
+        let declares = match node.kind() {
+            "short_var_declaration" => node.child_by_field_name("left").is_some_and(|left| {
+                left.named_children(&mut left.walk())
+                    .any(|n| file.text(n) == name)
+            }),
+            "var_spec" => node
+                .children_by_field_name("name", &mut node.walk())
+                .any(|n| file.text(n) == name),
+            _ => false,
+        };
+        let encloses = node.parent().is_some_and(|scope| {
+            scope.start_byte() <= closure.start_byte() && closure.end_byte() <= scope.end_byte()
+        });
+        if declares && encloses {
+            copied = true;
+        }
+    });
+    copied
+}
+
+/// First use of `name` inside the body of `closure`
+fn first_reference<'t>(file: &GoSourceFile, closure: Node<'t>, name: &str) -> Option<Node<'t>> {
+    let body = closure.child_by_field_name("body")?;
+    let mut found = None;
+    walk_tree(body, &mut |node| {
+        if found.is_none() && node.kind() == "identifier" && file.text(node) == name {
+            found = Some(node);
+        }
+    });
+    found
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_escaping_closures_capture_loop_variables() {
+        let code = r#"
+package jobs
+
+func Run(jobs []Job, results chan func()) {
+    for i, job := range jobs {
+        go func() {
+            process(job, i)
+        }()
+        go func(job Job) {
+            process(job, 0)
+        }(job)
+        job := job
+        defer func() { process(job, 0) }()
+        func() { process(jobs[i], i) }()
+    }
+
+    var handlers []func()
+    for n := 0; n < 3; n++ {
+        handlers = append(handlers, func() { println(n) })
+        results <- func() { println(n) }
+    }
+}
+"#;
+        let file = GoSourceFile::parse("jobs/run.go", code.to_string()).unwrap();
+        let findings = captures_in_file(&file);
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|c| (c.variable.as_str(), c.escape, c.line, c.loop_line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("job", "go", 7, 5),
+                ("i", "go", 7, 5),
+                ("n", "append", 19, 18),
+                ("n", "send", 20, 18),
+            ]
+        );
+        assert_eq!(findings[0].function.as_deref(), Some("Run"));
+        assert_eq!(findings[0].column, 21);
+
+        assert!(!loop_vars_per_iteration("1.21"));
+        assert!(!loop_vars_per_iteration("1.21.5"));
+        assert!(loop_vars_per_iteration("1.22"));
+        assert!(loop_vars_per_iteration("1.23rc1"));
+        assert!(loop_vars_per_iteration(" 1.22.0 "));
+    }
+
+    #[test]
+    fn test_findings_follow_module_go_version() {
+        let code = r#"
+package jobs
+
+func Run(jobs []Job) {
+    for _, job := range jobs {
+        go func() { process(job) }()
+    }
+}
+"#;
+        let captures_under = |go_version: &str| {
+            let temp_dir = tempfile::TempDir::new().unwrap();
+            std::fs::write(
+                temp_dir.path().join("go.mod"),
+                format!("module example.com/jobs\n\ngo {go_version}\n"),
+            )
+            .unwrap();
+            let file =
+                GoSourceFile::parse(temp_dir.path().join("run.go"), code.to_string()).unwrap();
+            find_loop_var_captures(&[file])
+                .into_iter()
+                .map(|c| (c.variable, c.go_version))
+                .collect::<Vec<_>>()
+        };
+
+        assert_eq!(
+            captures_under("1.21"),
+            vec![("job".to_string(), Some("1.21".to_string()))]
+        );
+        assert!(captures_under("1.22").is_empty());
+    }
+}
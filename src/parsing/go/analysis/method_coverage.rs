@@ -0,0 +1,242 @@
+//! Interfaces each method of a type helps satisfy
+//!
+//! Built on the implements check: for every interface a type satisfies,
+//! each method the interface requires is attributed to it.
+//!
+//! ```go
+//! func (f *FileProcessor) Read(data []byte) (int, error) { ... }  // Reader, io.Reader, ...
+//! func (f *FileProcessor) Close() error { ... }                   // io.Closer, ...
+//! func (f *FileProcessor) Process(input []byte) ([]byte, error) { ... } // DataProcessor
+//! func (f *FileProcessor) reset() { ... }                         // none
+//! ```
+//!
+//! A method no satisfied interface requires is listed with no interfaces,
+//! which tells the methods a type has for its interfaces from the ones it
+//! has for its own callers. Only the methods declared on the type are
+//! listed; promoted methods still count when checking the interfaces.
+
+use super::implements::MethodSets;
+use super::{GoSourceFile, line_of, receiver_type_name, walk_tree};
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::fmt;
+
+/// The methods of a type and the interfaces they contribute to
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MethodCoverage {
+    pub type_name: String,
+    pub type_package: String,
+    pub file: String,
+    /// 1-based line of the type declaration
+    pub line: u32,
+    /// Declared methods in source order
+    pub methods: Vec<MethodInterfaces>,
+}
+
+/// A method with the satisfied interfaces requiring it
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MethodInterfaces {
+    pub method: String,
+    /// Interfaces as `package.Name`, sorted; empty when none requires it
+    pub interfaces: Vec<String>,
+    pub file: String,
+    /// 1-based line of the method declaration
+    pub line: u32,
+}
+
+impl fmt::Display for MethodCoverage {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} ({}:{})",
+            self.type_package, self.type_name, self.file, self.line
+        )?;
+        let width = self
+            .methods
+            .iter()
+            .map(|m| m.method.len())
+            .max()
+            .unwrap_or(0);
+        for method in &self.methods {
+            if method.interfaces.is_empty() {
+                write!(f, "\n  {:width$}  satisfies no interface", method.method)?;
+            } else {
+                write!(
+                    f,
+                    "\n  {:width$}  -> {}",
+                    method.method,
+                    method.interfaces.join(", ")
+                )?;
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Map the methods of the types named `target`, `Name` or `package.Name`,
+/// to the interfaces they help satisfy, one entry per matching type sorted
+/// by package
+pub fn find_method_coverage(
+    files: &[GoSourceFile],
+    target: &str,
+    include_tests: bool,
+    stdlib: StdlibResolution,
+) -> Vec<MethodCoverage> {
+    let (package, name) = match target.rsplit_once('.') {
+        Some((package, name)) => (Some(package), name),
+        None => (None, target),
+    };
+    let method_sets = MethodSets::build(files, include_tests, stdlib);
+    let implementations = method_sets.implementations(files);
+
+    let mut coverage: Vec<MethodCoverage> = Vec::new();
+    for file in files {
+        let Some(file_package) = file.package_name() else {
+            continue;
+        };
+        if package.is_some_and(|package| package != file_package) {
+            continue;
+        }
+        walk_tree(file.root(), &mut |spec| {
+            if spec.kind() != "type_spec"
+                || spec
+                    .child_by_field_name("type")
+                    .is_some_and(|t| t.kind() == "interface_type")
+            {
+                return;
+            }
+            let Some(type_name) = spec.child_by_field_name("name") else {
+                return;
+            };
+            if file.text(type_name) != name
+                || coverage.iter().any(|c| c.type_package == file_package)
+            {
+                return;
+            }
+            coverage.push(MethodCoverage {
+                type_name: name.to_string(),
+                type_package: file_package.to_string(),
+                file: file.display_path(),
+                line: line_of(type_name),
+                methods: Vec::new(),
+            });
+        });
+    }
+
+    for entry in &mut coverage {
+        let declared_in_test = entry.file.ends_with("_test.go");
+        let satisfied: Vec<(String, Vec<String>)> = implementations
+            .iter()
+            .filter(|i| i.type_name == entry.type_name && i.type_package == entry.type_package)
+            .map(|i| {
+                let interface = if i.interface_package.is_empty() {
+                    i.interface.clone()
+                } else {
+                    format!("{}.{}", i.interface_package, i.interface)
+                };
+                let required = method_sets.interface_methods(&i.interface_key);
+                (interface, required)
+            })
+            .collect();
+        for file in files {
+            if file.package_name() != Some(entry.type_package.as_str())
+                || (file.is_test() && !declared_in_test && !include_tests)
+            {
+                continue;
+            }
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() != "method_declaration"
+                    || receiver_type_name(file, node) != Some(entry.type_name.as_str())
+                {
+                    return;
+                }
+                let Some(method) = node.child_by_field_name("name") else {
+                    return;
+                };
+                let method = file.text(method).to_string();
+                let mut interfaces: Vec<String> = satisfied
+                    .iter()
+                    .filter(|(_, required)| required.contains(&method))
+                    .map(|(interface, _)| interface.clone())
+                    .collect();
+                interfaces.sort();
+                entry.methods.push(MethodInterfaces {
+                    method,
+                    interfaces,
+                    file: file.display_path(),
+                    line: line_of(node),
+                });
+            });
+        }
+    }
+    coverage.sort_by(|a, b| a.type_package.cmp(&b.type_package));
+    coverage
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_methods_mapped_to_satisfied_interfaces() {
+        let file =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/interfaces.go")).unwrap();
+        let files = [file];
+
+        let coverage =
+            find_method_coverage(&files, "FileProcessor", false, StdlibResolution::Builtin);
+        assert_eq!(coverage.len(), 1);
+        let processor = &coverage[0];
+        assert_eq!(processor.type_package, "interfaces");
+        let interfaces = |method: &str| {
+            processor
+                .methods
+                .iter()
+                .find(|m| m.method == method)
+                .map(|m| m.interfaces.clone())
+                .unwrap_or_else(|| panic!("{method} not listed"))
+        };
+
+        let read = interfaces("Read");
+        assert!(read.contains(&"interfaces.Reader".to_string()));
+        assert!(read.contains(&"interfaces.ReadWriteCloser".to_string()));
+        assert!(!read.contains(&"interfaces.Writer".to_string()));
+        assert!(interfaces("Write").contains(&"interfaces.Writer".to_string()));
+        assert!(interfaces("Close").contains(&"io.Closer".to_string()));
+        for method in ["Process", "Validate", "GetMetadata"] {
+            assert_eq!(
+                interfaces(method),
+                vec!["interfaces.DataProcessor".to_string()]
+            );
+        }
+        assert!(
+            processor
+                .to_string()
+                .contains("\n  Process      -> interfaces.DataProcessor")
+        );
+
+        // Qualified by package, and unknown types
+        assert_eq!(
+            find_method_coverage(
+                &files,
+                "interfaces.FileProcessor",
+                false,
+                StdlibResolution::Builtin
+            )
+            .len(),
+            1
+        );
+        assert!(
+            find_method_coverage(
+                &files,
+                "other.FileProcessor",
+                false,
+                StdlibResolution::Builtin
+            )
+            .is_empty()
+        );
+        assert!(
+            find_method_coverage(&files, "Missing", false, StdlibResolution::Builtin).is_empty()
+        );
+    }
+}
@@ -0,0 +1,307 @@
+//! Exported identifiers without a doc comment (advisory)
+//!
+//! Go convention is that every exported identifier is documented by a
+//! comment directly above its declaration:
+//!
+//! ```go
+//! // Store persists users.
+//! type Store interface { ... }
+//!
+//! func NewStore() Store { ... } // flagged: no comment above
+//! ```
+//!
+//! Types, functions, methods, constants and variables are checked. A
+//! comment counts when it sits on the lines right above the declaration,
+//! without a blank line in between, and has some text; a constant,
+//! variable or type in a grouped `const ( ... )` block is also covered by
+//! the block's comment. Methods of unexported types and `_test.go` files
+//! are not part of the documented API and are skipped. Methods satisfying
+//! an interface, like `String()` or `Error()`, are documented by the
+//! interface and skipped too unless asked for. A `// codanna:ignore
+//! missing-docs` comment on the declaration line or the line above
+//! suppresses the finding.
+
+use super::implements::MethodSets;
+use super::{GoSourceFile, is_exported, is_suppressed, line_of, receiver_type_name, walk_tree};
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::HashSet;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "missing-docs";
+
+/// An exported identifier declared without a doc comment
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MissingDoc {
+    /// Identifier; methods as `Type.Method`
+    pub symbol: String,
+    /// `type`, `function`, `method`, `const` or `var`
+    pub kind: &'static str,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl fmt::Display for MissingDoc {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{} {}.{} has no doc comment at {}:{}",
+            self.kind, self.package, self.symbol, self.file, self.line
+        )
+    }
+}
+
+/// Find the exported identifiers without a doc comment, sorted by file and
+/// line
+///
+/// With `include_interface_methods`, methods satisfying an interface are
+/// reported too.
+pub fn find_missing_docs(
+    files: &[GoSourceFile],
+    include_interface_methods: bool,
+    include_tests: bool,
+    stdlib: StdlibResolution,
+) -> Vec<MissingDoc> {
+    let satisfying = if include_interface_methods {
+        HashSet::new()
+    } else {
+        interface_satisfying_methods(files, include_tests, stdlib)
+    };
+
+    let mut findings = Vec::new();
+    for file in files.iter().filter(|f| !f.is_test()) {
+        let Some(package) = file.package_name() else {
+            continue;
+        };
+        let mut report = |symbol: String, kind: &'static str, node: Node| {
+            let line = line_of(node);
+            if is_suppressed(file, line, ANALYSIS_NAME) {
+                return;
+            }
+            findings.push(MissingDoc {
+                symbol,
+                kind,
+                package: package.to_string(),
+                file: file.display_path(),
+                line,
+            });
+        };
+
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            match decl.kind() {
+                "function_declaration" => {
+                    let Some(name) = decl.child_by_field_name("name").map(|n| file.text(n)) else {
+                        continue;
+                    };
+                    if is_exported(name) && !documented(file, decl) {
+                        report(name.to_string(), "function", decl);
+                    }
+                }
+                "method_declaration" => {
+                    let (Some(receiver), Some(name)) = (
+                        receiver_type_name(file, decl),
+                        decl.child_by_field_name("name").map(|n| file.text(n)),
+                    ) else {
+                        continue;
+                    };
+                    let skipped = !is_exported(receiver)
+                        || satisfying
+                            .contains(&(format!("{package}.{receiver}"), name.to_string()));
+                    if is_exported(name) && !skipped && !documented(file, decl) {
+                        report(format!("{receiver}.{name}"), "method", decl);
+                    }
+                }
+                "type_declaration" | "const_declaration" | "var_declaration" => {
+                    walk_tree(decl, &mut |spec| {
+                        let (names, kind): (Vec<Node>, _) = match spec.kind() {
+                            "type_spec" | "type_alias" => (
+                                spec.child_by_field_name("name").into_iter().collect(),
+                                "type",
+                            ),
+                            "const_spec" => (
+                                spec.children_by_field_name("name", &mut spec.walk())
+                                    .collect(),
+                                "const",
+                            ),
+                            "var_spec" => (
+                                spec.children_by_field_name("name", &mut spec.walk())
+                                    .collect(),
+                                "var",
+                            ),
+                            _ => return,
+                        };
+                        if spec_documented(file, spec) {
+                            return;
+                        }
+                        for name in names {
+                            if is_exported(file.text(name)) {
+                                report(file.text(name).to_string(), kind, spec);
+                            }
+                        }
+                    });
+                }
+                _ => {}
+            }
+        }
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Whether a spec, or the group or declaration around it, is documented
+fn spec_documented(file: &GoSourceFile, spec: Node) -> bool {
+    let mut current = Some(spec);
+    while let Some(node) = current {
+        if documented(file, node) {
+            return true;
+        }
+        if node.parent().is_some_and(|p| p.kind() == "source_file") {
+            return false;
+        }
+        current = node.parent();
+    }
+    false
+}
+
+/// Whether comment lines with some text sit directly above `node`
+///
+/// A comment trailing the code of the line above is not a doc comment.
+fn documented(file: &GoSourceFile, node: Node) -> bool {
+    let mut start = node;
+    let mut text = false;
+    while let Some(previous) = start.prev_sibling() {
+        if previous.kind() != "comment"
+            || previous.end_position().row + 1 != start.start_position().row
+            || previous
+                .prev_sibling()
+                .is_some_and(|p| p.end_position().row == previous.start_position().row)
+        {
+            break;
+        }
+        let comment = file.text(previous);
+        text |= comment
+            .trim_start_matches("//")
+            .trim_start_matches("/*")
+            .trim_end_matches("*/")
+            .trim()
+            .chars()
+            .any(|c| c != '/' && c != '*' && !c.is_whitespace());
+        start = previous;
+    }
+    text
+}
+
+/// (`package.Type`, method) pairs where the method is one of an interface
+/// the type implements
+fn interface_satisfying_methods(
+    files: &[GoSourceFile],
+    include_tests: bool,
+    stdlib: StdlibResolution,
+) -> HashSet<(String, String)> {
+    let method_sets = MethodSets::build(files, include_tests, stdlib);
+    let mut satisfying = HashSet::new();
+    for implementation in method_sets.implementations(files) {
+        let owner = format!(
+            "{}.{}",
+            implementation.type_package, implementation.type_name
+        );
+        for method in method_sets.interface_methods(&implementation.interface_key) {
+            satisfying.insert((owner.clone(), method));
+        }
+    }
+    satisfying
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_exported_identifiers_without_docs() {
+        let code = r#"
+package store
+
+// Store persists users.
+type Store interface {
+    Save(id string) error
+}
+
+type Record struct{}
+
+//
+type Blank struct{}
+
+// Limits of a store
+const (
+    MaxUsers = 100
+    MinUsers = 1
+)
+
+const Version = "1" // trailing comment
+
+var (
+    // Default is the default store.
+    Default Store
+    Fallback Store
+)
+
+func NewStore() Store { return nil }
+
+// codanna:ignore missing-docs
+func Legacy() {}
+
+func (r *Record) Save(id string) error { return nil }
+
+func (r *Record) String() string { return "" }
+
+func (r *Record) Flush() {}
+
+type memory struct{}
+
+func (m *memory) Reset() {}
+
+func helper() {}
+"#;
+        let file = GoSourceFile::parse("store/store.go", code.to_string()).unwrap();
+        let files = [file];
+
+        let symbols = |findings: Vec<MissingDoc>| -> Vec<String> {
+            findings.into_iter().map(|f| f.symbol).collect()
+        };
+        assert_eq!(
+            symbols(find_missing_docs(
+                &files,
+                false,
+                false,
+                StdlibResolution::Builtin
+            )),
+            vec![
+                "Record",
+                "Blank",
+                "Version",
+                "Fallback",
+                "NewStore",
+                "Record.Flush"
+            ]
+        );
+        let with_interface_methods = symbols(find_missing_docs(
+            &files,
+            true,
+            false,
+            StdlibResolution::Builtin,
+        ));
+        assert!(with_interface_methods.contains(&"Record.Save".to_string()));
+        assert!(with_interface_methods.contains(&"Record.String".to_string()));
+
+        let finding = &find_missing_docs(&files, false, false, StdlibResolution::Builtin)[0];
+        assert_eq!(
+            finding.to_string(),
+            "type store.Record has no doc comment at store/store.go:9"
+        );
+    }
+}
@@ -0,0 +1,913 @@
+//! Source-level analyses over Go syntax trees
+//!
+//! Some questions can't be answered from the symbol index alone: which
+//! environment variables a program reads, whether a lock is released on every
+//! path, which errors are silently dropped. The analyses in this module work
+//! directly on the tree-sitter tree of each Go file and return plain findings
+//! that the CLI renders as text or JSON.
+//!
+//! All analyses share [`GoSourceFile`] and the small tree helpers below.
+
+pub mod anon_funcs;
+pub mod broad_interfaces;
+pub mod channel_flow;
+pub mod channels;
+pub mod constants;
+pub mod conversions;
+pub mod diagnostics;
+pub mod duplicates;
+pub mod embeds;
+pub mod enum_literals;
+pub mod enums;
+pub mod env_vars;
+pub mod error_types;
+pub mod exhaustive;
+pub mod file_imports;
+pub mod generators;
+pub mod higher_order;
+pub mod impact;
+pub mod implements;
+pub mod import_cycles;
+pub mod import_users;
+pub mod interface_pollution;
+pub mod locks;
+pub mod long_signatures;
+pub mod loop_captures;
+pub mod method_coverage;
+pub mod missing_docs;
+pub mod mutations;
+pub mod nil_receivers;
+pub mod noreturn;
+pub mod packages;
+pub mod printf_args;
+pub mod promotions;
+pub mod signatures;
+pub mod stubs;
+pub mod symbol_changes;
+pub mod test_coverage;
+pub mod todos;
+pub mod unchecked_errors;
+pub mod unwrapped_errors;
+
+pub use anon_funcs::{AnonymousFunction, find_anonymous_functions};
+pub use broad_interfaces::{BroadInterfaceParam, find_broad_interface_params};
+pub use channel_flow::{ChannelFlow, find_channel_flow};
+pub use channels::{ChannelOperation, ChannelScope, ChannelSite, ChannelUsage, find_channels};
+pub use constants::ConstantTable;
+pub use conversions::{ConversionDirection, TypeConversion, find_type_conversions};
+pub use diagnostics::{Diagnostic, DiagnosticEvent, DiagnosticsTracker, find_diagnostics};
+pub use duplicates::{DuplicateDefinition, find_duplicate_definitions};
+pub use embeds::{EmbedEdge, find_embeds};
+pub use enum_literals::{EnumLiteralComparison, find_enum_literal_comparisons};
+pub use enums::{ConstValue, EnumConstant, EnumTable, EnumType, const_values};
+pub use env_vars::{EnvVarRead, find_env_var_reads};
+pub use error_types::{ErrorCodeEnum, ErrorType, find_error_types};
+pub use exhaustive::{NonExhaustiveSwitch, find_non_exhaustive_switches};
+pub use file_imports::{FileImport, InitFunction, find_file_imports};
+pub use generators::{GenerateDirective, find_generate_directives};
+pub use higher_order::{ArgumentKind, HigherOrderArg, find_higher_order_args};
+pub use impact::{ImpactEntry, ImpactRole, TypeImpact, find_type_impact};
+pub use implements::{Implementation, find_implementations};
+pub use import_cycles::{ImportCycle, find_import_cycles};
+pub use import_users::{ImportUser, find_import_users};
+pub use interface_pollution::{SoleImplementor, find_sole_implementors};
+pub use locks::{LockImbalance, LockProblem, find_lock_imbalances};
+pub use long_signatures::{LongSignature, SignatureThresholds, find_long_signatures};
+pub use loop_captures::{LoopVarCapture, find_loop_var_captures};
+pub use method_coverage::{MethodCoverage, MethodInterfaces, find_method_coverage};
+pub use missing_docs::{MissingDoc, find_missing_docs};
+pub use mutations::{VariableMutation, find_variable_mutations};
+pub use nil_receivers::{NilReceiverCall, find_nil_receiver_calls};
+pub use noreturn::{NoReturnFunction, find_noreturn_functions};
+pub use packages::{PackageGraph, PackageImport};
+pub use printf_args::{PrintfMismatch, find_printf_mismatches};
+pub use promotions::{AmbiguousSelector, find_ambiguous_selectors};
+pub use signatures::{
+    SignatureMatch, TypeQuery, find_functions_accepting, find_functions_returning,
+};
+pub use stubs::{MethodStub, StubError, generate_method_stubs};
+pub use symbol_changes::{SymbolChange, SymbolChangeKind, diff_symbols};
+pub use test_coverage::{PackageTestCoverage, SymbolCoverage, find_test_coverage};
+pub use todos::{TodoComment, find_todos};
+pub use unchecked_errors::{UncheckedError, UncheckedErrorKind, find_unchecked_errors};
+pub use unwrapped_errors::{UnwrappedErrorReturn, find_unwrapped_error_returns};
+
+use crate::parsing::go::GoParser;
+use crate::parsing::go::resolution::stdlib_interface_methods;
+use crate::project_config::StdlibResolution;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::path::{Path, PathBuf};
+use tree_sitter::{Node, Parser, Tree};
+
+/// A Go source file together with its syntax tree
+pub struct GoSourceFile {
+    pub path: PathBuf,
+    pub source: String,
+    pub tree: Tree,
+}
+
+impl GoSourceFile {
+    /// Parse Go source code that belongs to `path`
+    pub fn parse(path: impl Into<PathBuf>, source: String) -> Result<Self, String> {
+        let mut parser = Parser::new();
+        parser
+            .set_language(&tree_sitter_go::LANGUAGE.into())
+            .map_err(|e| format!("Failed to set Go language: {e}"))?;
+        let tree = parser
+            .parse(&source, None)
+            .ok_or_else(|| "Failed to parse Go source".to_string())?;
+
+        Ok(Self {
+            path: path.into(),
+            source,
+            tree,
+        })
+    }
+
+    /// Read and parse a Go file from disk
+    pub fn read(path: &Path) -> Result<Self, String> {
+        let source = std::fs::read_to_string(path)
+            .map_err(|e| format!("Failed to read {}: {e}", path.display()))?;
+        Self::parse(path, source)
+    }
+
+    pub fn root(&self) -> Node<'_> {
+        self.tree.root_node()
+    }
+
+    /// Source text covered by `node`
+    pub fn text(&self, node: Node) -> &str {
+        &self.source[node.byte_range()]
+    }
+
+    /// Package name from the `package` clause
+    pub fn package_name(&self) -> Option<&str> {
+        let root = self.root();
+        let clause = root
+            .children(&mut root.walk())
+            .find(|n| n.kind() == "package_clause")?;
+        let name = clause
+            .children(&mut clause.walk())
+            .find(|n| n.kind() == "package_identifier")?;
+        Some(self.text(name))
+    }
+
+    /// Display path used in findings
+    pub fn display_path(&self) -> String {
+        self.path.display().to_string()
+    }
+
+    /// Whether this is a `_test.go` file, only compiled by `go test`
+    pub fn is_test(&self) -> bool {
+        self.path
+            .file_name()
+            .and_then(|name| name.to_str())
+            .is_some_and(|name| name.ends_with("_test.go"))
+    }
+
+    /// Map of local package names to import paths
+    ///
+    /// The local name is the alias when given, otherwise the last path
+    /// segment. Dot and blank imports introduce no name and are skipped.
+    pub fn import_aliases(&self) -> HashMap<String, String> {
+        let mut aliases = HashMap::new();
+        walk_tree(self.root(), &mut |node| {
+            if node.kind() != "import_spec" {
+                return;
+            }
+            let Some(path) = node.child_by_field_name("path") else {
+                return;
+            };
+            let path = self.text(path).trim_matches(|c| c == '"' || c == '`');
+            let local = match node.child_by_field_name("name") {
+                Some(name) if name.kind() == "package_identifier" => self.text(name),
+                Some(_) => return,
+                None => path.rsplit('/').next().unwrap_or(path),
+            };
+            aliases.insert(local.to_string(), path.to_string());
+        });
+        aliases
+    }
+}
+
+/// 1-based line of the start of `node`
+pub fn line_of(node: Node) -> u32 {
+    node.start_position().row as u32 + 1
+}
+
+/// Whether an identifier is exported: it starts with an upper-case letter
+pub fn is_exported(name: &str) -> bool {
+    name.chars().next().is_some_and(char::is_uppercase)
+}
+
+/// Visit `node` and all of its descendants in source order
+pub fn walk_tree<'t>(node: Node<'t>, visit: &mut dyn FnMut(Node<'t>)) {
+    visit(node);
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        walk_tree(child, visit);
+    }
+}
+
+/// Named type at the base of a type expression: the `type_identifier` of
+/// `*Stack[T]`, or the `qualified_type` of `*models.User`
+pub fn base_type_node(node: Node) -> Option<Node> {
+    match node.kind() {
+        "type_identifier" | "qualified_type" => Some(node),
+        "pointer_type" | "parenthesized_type" => base_type_node(node.named_child(0)?),
+        "generic_type" => base_type_node(node.child_by_field_name("type")?),
+        _ => None,
+    }
+}
+
+/// Base type name of a type expression: `*Stack[T]` and `Stack[T]` give `Stack`
+pub fn base_type_name<'s>(file: &'s GoSourceFile, node: Node) -> Option<&'s str> {
+    let base = base_type_node(node)?;
+    match base.kind() {
+        "qualified_type" => base.child_by_field_name("name").map(|n| file.text(n)),
+        _ => Some(file.text(base)),
+    }
+}
+
+/// `package.Type` key of a named type; `error` stays unqualified
+///
+/// Imported types are keyed by the last segment of their import path, so
+/// `io.Reader` keys as `io.Reader` whatever the local alias.
+pub fn type_key(
+    file: &GoSourceFile,
+    node: Node,
+    aliases: &HashMap<String, String>,
+) -> Option<String> {
+    match node.kind() {
+        "type_identifier" if file.text(node) == "error" => Some("error".to_string()),
+        "type_identifier" => Some(format!("{}.{}", file.package_name()?, file.text(node))),
+        "qualified_type" => {
+            let path = aliases.get(file.text(node.child_by_field_name("package")?))?;
+            let name = file.text(node.child_by_field_name("name")?);
+            Some(format!(
+                "{}.{name}",
+                path.rsplit('/').next().unwrap_or(path)
+            ))
+        }
+        _ => None,
+    }
+}
+
+/// A method an interface declares itself
+pub struct InterfaceMethod<'f> {
+    pub name: &'f str,
+    /// The `method_elem` node, whose text is the method's signature
+    pub element: Node<'f>,
+}
+
+/// An interface declared in the analyzed files
+pub struct InterfaceDecl<'f> {
+    /// Index of the declaring file
+    pub file: usize,
+    /// Name node of the `type_spec`
+    pub name: Node<'f>,
+    /// Methods of the interface body, in source order
+    pub methods: Vec<InterfaceMethod<'f>>,
+    /// Embedded interfaces, keyed as the table is, with the embedded type
+    pub embeds: Vec<(String, Node<'f>)>,
+    /// Embeds something other than a named interface (a type constraint)
+    pub constraint: bool,
+}
+
+/// Interfaces declared in a set of files, with the standard library ones
+/// [`stdlib_interface_methods`] knows
+///
+/// [`InterfaceTable::build`] keys interfaces as [`type_key`] does;
+/// [`InterfaceTable::build_keyed`] takes other keys, such as the import
+/// path keys of [`packages::PackageKeys`]. Embeds are the ones
+/// [`GoParser::interface_embeds`] finds.
+pub struct InterfaceTable<'f> {
+    files: &'f [GoSourceFile],
+    declared: BTreeMap<String, InterfaceDecl<'f>>,
+    stdlib: StdlibResolution,
+}
+
+impl<'f> InterfaceTable<'f> {
+    /// Interfaces of `files`, keyed `package.Name`
+    pub fn build(files: &'f [GoSourceFile], stdlib: StdlibResolution) -> Self {
+        Self::build_keyed(
+            files,
+            stdlib,
+            |file| file.package_name().unwrap_or_default().to_string(),
+            type_key,
+        )
+    }
+
+    /// Interfaces of `files`, keyed `{package_of(file)}.Name`, with embedded
+    /// types keyed by `key_of`
+    pub fn build_keyed(
+        files: &'f [GoSourceFile],
+        stdlib: StdlibResolution,
+        package_of: impl Fn(&GoSourceFile) -> String,
+        key_of: impl Fn(&GoSourceFile, Node, &HashMap<String, String>) -> Option<String>,
+    ) -> Self {
+        let mut declared = BTreeMap::new();
+        for (index, file) in files.iter().enumerate() {
+            let package = package_of(file);
+            let aliases = file.import_aliases();
+            let interface_embeds = GoParser::interface_embeds(file.root());
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() != "type_spec" {
+                    return;
+                }
+                let (Some(name), Some(body)) = (
+                    node.child_by_field_name("name"),
+                    node.child_by_field_name("type"),
+                ) else {
+                    return;
+                };
+                if body.kind() != "interface_type" {
+                    return;
+                }
+                let embeds: Vec<(String, Node)> = interface_embeds
+                    .iter()
+                    .filter(|(interface, _)| *interface == name)
+                    .filter_map(|(_, embedded)| {
+                        Some((key_of(file, *embedded, &aliases)?, *embedded))
+                    })
+                    .collect();
+                let mut methods = Vec::new();
+                let mut type_elems = 0;
+                let mut constraint = false;
+                for element in body.named_children(&mut body.walk()) {
+                    match element.kind() {
+                        "method_elem" => {
+                            if let Some(method) = element.child_by_field_name("name") {
+                                methods.push(InterfaceMethod {
+                                    name: file.text(method),
+                                    element,
+                                });
+                            }
+                        }
+                        "type_elem" => type_elems += 1,
+                        "comment" => {}
+                        _ => constraint = true,
+                    }
+                }
+                // A union or an unkeyed type is a constraint, not an embed
+                constraint |= type_elems != embeds.len();
+                declared.insert(
+                    format!("{package}.{}", file.text(name)),
+                    InterfaceDecl {
+                        file: index,
+                        name,
+                        methods,
+                        embeds,
+                        constraint,
+                    },
+                );
+            });
+        }
+        Self {
+            files,
+            declared,
+            stdlib,
+        }
+    }
+
+    /// The declared interface keyed `key`
+    pub fn get(&self, key: &str) -> Option<&InterfaceDecl<'f>> {
+        self.declared.get(key)
+    }
+
+    /// Declared interfaces, sorted by key
+    pub fn declared(&self) -> impl Iterator<Item = (&str, &InterfaceDecl<'f>)> {
+        self.declared.iter().map(|(key, decl)| (key.as_str(), decl))
+    }
+
+    /// File declaring `decl`
+    pub fn file_of(&self, decl: &InterfaceDecl) -> &'f GoSourceFile {
+        &self.files[decl.file]
+    }
+
+    pub fn stdlib_resolution(&self) -> StdlibResolution {
+        self.stdlib
+    }
+
+    /// Whether `key` is a declared or known standard library interface
+    pub fn is_interface(&self, key: &str) -> bool {
+        self.declared.contains_key(key) || stdlib_interface_methods(key, self.stdlib).is_some()
+    }
+
+    /// Full method set of an interface as (name, signature), sorted by name,
+    /// or `None` when some of it is unknown
+    pub fn method_set(&self, key: &str) -> Option<Vec<(String, String)>> {
+        let mut methods = Vec::new();
+        self.collect_methods(key, &mut HashSet::new(), &mut methods)?;
+        methods.sort();
+        methods.dedup_by(|a, b| a.0 == b.0);
+        Some(methods)
+    }
+
+    fn collect_methods<'k>(
+        &'k self,
+        key: &'k str,
+        seen: &mut HashSet<&'k str>,
+        methods: &mut Vec<(String, String)>,
+    ) -> Option<()> {
+        if !seen.insert(key) {
+            return Some(());
+        }
+        if let Some(decl) = self.declared.get(key) {
+            if decl.constraint {
+                return None;
+            }
+            let file = self.file_of(decl);
+            methods.extend(
+                decl.methods
+                    .iter()
+                    .map(|m| (m.name.to_string(), file.text(m.element).to_string())),
+            );
+            for (embedded, _) in &decl.embeds {
+                self.collect_methods(embedded, seen, methods)?;
+            }
+            return Some(());
+        }
+        let stdlib = stdlib_interface_methods(key, self.stdlib)?;
+        methods.extend(stdlib.iter().map(|signature| {
+            let name = signature.split('(').next().unwrap_or(signature);
+            (name.to_string(), signature.to_string())
+        }));
+        Some(())
+    }
+
+    /// Methods of a declared interface and of the declared interfaces it
+    /// embeds, in source order, the first declaration of each name winning,
+    /// with the key and file of the interface declaring each
+    pub fn declared_methods(
+        &self,
+        key: &str,
+    ) -> Vec<(&str, &'f GoSourceFile, &InterfaceMethod<'f>)> {
+        let mut methods = Vec::new();
+        self.collect_declared_methods(key, &mut HashSet::new(), &mut methods);
+        methods
+    }
+
+    fn collect_declared_methods<'k>(
+        &'k self,
+        key: &'k str,
+        seen: &mut HashSet<&'k str>,
+        methods: &mut Vec<(&'k str, &'f GoSourceFile, &'k InterfaceMethod<'f>)>,
+    ) {
+        /// A method or an embed of the interface body
+        enum Element<'a, 'f> {
+            Method(&'a InterfaceMethod<'f>),
+            Embed(&'a str),
+        }
+
+        let Some((key, decl)) = self.declared.get_key_value(key) else {
+            return;
+        };
+        let key = key.as_str();
+        if !seen.insert(key) {
+            return;
+        }
+        let mut elements: Vec<(usize, Element)> = decl
+            .methods
+            .iter()
+            .map(|m| (m.element.start_byte(), Element::Method(m)))
+            .chain(
+                decl.embeds
+                    .iter()
+                    .map(|(embedded, node)| (node.start_byte(), Element::Embed(embedded))),
+            )
+            .collect();
+        elements.sort_by_key(|(start, _)| *start);
+        for (_, element) in elements {
+            match element {
+                Element::Method(method) => {
+                    if !methods.iter().any(|(_, _, m)| m.name == method.name) {
+                        methods.push((key, self.file_of(decl), method));
+                    }
+                }
+                Element::Embed(embedded) => self.collect_declared_methods(embedded, seen, methods),
+            }
+        }
+    }
+}
+
+/// Receiver type of a method declaration, as written: `*Stack[T]`
+pub fn receiver_type_node(method: Node) -> Option<Node> {
+    let receiver = method.child_by_field_name("receiver")?;
+    let param = receiver
+        .named_children(&mut receiver.walk())
+        .find(|n| n.kind() == "parameter_declaration")?;
+    param.child_by_field_name("type")
+}
+
+/// Receiver type name of a method declaration
+pub fn receiver_type_name<'s>(file: &'s GoSourceFile, method: Node) -> Option<&'s str> {
+    base_type_name(file, receiver_type_node(method)?)
+}
+
+/// Name of a function or method declaration; methods are qualified by receiver
+pub fn declaration_name(file: &GoSourceFile, decl: Node) -> Option<String> {
+    let name = file.text(decl.child_by_field_name("name")?);
+    match decl.kind() {
+        "method_declaration" => match receiver_type_name(file, decl) {
+            Some(receiver) => Some(format!("{receiver}.{name}")),
+            None => Some(name.to_string()),
+        },
+        _ => Some(name.to_string()),
+    }
+}
+
+/// Name of the function or method declaration that encloses `node`
+pub fn enclosing_function_name(file: &GoSourceFile, node: Node) -> Option<String> {
+    let mut current = Some(node);
+    while let Some(n) = current {
+        if matches!(n.kind(), "function_declaration" | "method_declaration") {
+            return declaration_name(file, n);
+        }
+        current = n.parent();
+    }
+    None
+}
+
+/// Value of a string literal, without quotes
+///
+/// Escape sequences in interpreted literals are kept verbatim.
+pub fn string_literal_value(file: &GoSourceFile, node: Node) -> Option<String> {
+    match node.kind() {
+        "interpreted_string_literal" => Some(file.text(node).trim_matches('"').to_string()),
+        "raw_string_literal" => Some(file.text(node).trim_matches('`').to_string()),
+        _ => None,
+    }
+}
+
+/// Byte span of the declaration around 0-based `row`, with its doc comment
+///
+/// Functions and methods span from `func` to the closing brace. A type,
+/// variable or constant declared on its own spans the whole declaration
+/// from its keyword; one in a grouped `type ( ... )` block spans just its
+/// spec. Comment lines directly above, without a blank line in between,
+/// are included.
+pub fn declaration_span(file: &GoSourceFile, row: usize) -> Option<std::ops::Range<usize>> {
+    let root = file.root();
+    let decl = root.named_children(&mut root.walk()).find(|decl| {
+        decl.kind() != "comment"
+            && decl.start_position().row <= row
+            && row <= decl.end_position().row
+    })?;
+    let mut node = decl;
+    if matches!(
+        decl.kind(),
+        "type_declaration" | "var_declaration" | "const_declaration"
+    ) && decl.children(&mut decl.walk()).any(|c| c.kind() == "(")
+    {
+        walk_tree(decl, &mut |n| {
+            if matches!(
+                n.kind(),
+                "type_spec" | "type_alias" | "var_spec" | "const_spec"
+            ) && n.start_position().row <= row
+                && row <= n.end_position().row
+            {
+                node = n;
+            }
+        });
+    }
+
+    let mut start = node;
+    while let Some(previous) = start.prev_sibling() {
+        if previous.kind() != "comment"
+            || previous.end_position().row + 1 != start.start_position().row
+        {
+            break;
+        }
+        start = previous;
+    }
+    Some(start.start_byte()..node.end_byte())
+}
+
+/// Marker comment that suppresses findings: `// codanna:ignore [analysis...]`
+pub const SUPPRESS_MARKER: &str = "codanna:ignore";
+
+/// Whether a finding of `analysis` at 1-based `line` is suppressed
+///
+/// The marker may sit on the line itself or on the line above. Without
+/// analysis names it suppresses every analysis; otherwise only the listed ones.
+pub fn is_suppressed(file: &GoSourceFile, line: u32, analysis: &str) -> bool {
+    let index = line as usize;
+    let lines: Vec<&str> = file.source.lines().collect();
+    [index.checked_sub(1), index.checked_sub(2)]
+        .into_iter()
+        .flatten()
+        .filter_map(|i| lines.get(i))
+        .any(|text| {
+            let Some(comment) = text.find("//").map(|start| &text[start + 2..]) else {
+                return false;
+            };
+            let Some(rest) = comment.trim_start().strip_prefix(SUPPRESS_MARKER) else {
+                return false;
+            };
+            let mut names = rest
+                .split(|c: char| c == ',' || c.is_whitespace())
+                .filter(|n| !n.is_empty())
+                .peekable();
+            names.peek().is_none() || names.any(|n| n == analysis)
+        })
+}
+
+/// Split a selector call like `os.Getenv(...)` into (`os`, `Getenv`)
+pub fn selector_call_parts<'s>(file: &'s GoSourceFile, call: Node) -> Option<(&'s str, &'s str)> {
+    if call.kind() != "call_expression" {
+        return None;
+    }
+    let function = call.child_by_field_name("function")?;
+    if function.kind() != "selector_expression" {
+        return None;
+    }
+    let operand = function.child_by_field_name("operand")?;
+    let field = function.child_by_field_name("field")?;
+    Some((file.text(operand), file.text(field)))
+}
+
+/// A name declared inside a function
+#[derive(Debug, Clone, Copy)]
+pub struct LocalDeclaration<'t> {
+    /// The declared identifier
+    pub name: Node<'t>,
+    /// Block, statement, clause or function the name is visible in; a
+    /// function body shares its scope with the parameters
+    pub scope: Node<'t>,
+    /// Byte from which the name is visible: the end of its declaration, so
+    /// `x := x + 1` reads the outer `x`
+    pub visible_from: usize,
+}
+
+impl LocalDeclaration<'_> {
+    /// Whether the declaration is a local type rather than a value
+    pub fn is_type(&self) -> bool {
+        self.name
+            .parent()
+            .is_some_and(|n| matches!(n.kind(), "type_spec" | "type_alias"))
+    }
+}
+
+/// Local declarations under `top`, typically a function declaration
+///
+/// Parameters and receivers, `var`, `const` and `type` specs, `:=`
+/// declarations, range and receive variables and type switch aliases. Plain
+/// `=` range and receive assignments declare nothing, and neither does `_`.
+pub fn local_declarations<'t>(top: Node<'t>, source: &str) -> Vec<LocalDeclaration<'t>> {
+    let mut declarations = Vec::new();
+    walk_tree(top, &mut |node| {
+        let defines = || node.children(&mut node.walk()).any(|c| c.kind() == ":=");
+        let (names, scope, visible_from): (Vec<_>, _, _) = match node.kind() {
+            // Parameters of a function type, `func(a int)`, declare nothing
+            "parameter_declaration" | "variadic_parameter_declaration" => (
+                node.children_by_field_name("name", &mut node.walk())
+                    .collect(),
+                node.parent()
+                    .and_then(|list| list.parent())
+                    .filter(|n| is_function_scope(*n)),
+                node.end_byte(),
+            ),
+            "var_spec" | "const_spec" | "type_spec" | "type_alias" => (
+                node.children_by_field_name("name", &mut node.walk())
+                    .collect(),
+                enclosing_scope(node),
+                node.end_byte(),
+            ),
+            "short_var_declaration" | "range_clause" | "receive_statement"
+                if node.kind() == "short_var_declaration" || defines() =>
+            {
+                (
+                    node.child_by_field_name("left")
+                        .map(|left| left.named_children(&mut left.walk()).collect())
+                        .unwrap_or_default(),
+                    enclosing_scope(node),
+                    node.end_byte(),
+                )
+            }
+            // `switch v := x.(type)` declares `v` in each case
+            "type_switch_statement" => (
+                node.child_by_field_name("alias")
+                    .map(|alias| alias.named_children(&mut alias.walk()).collect())
+                    .unwrap_or_default(),
+                Some(node),
+                node.child_by_field_name("value")
+                    .map_or(node.end_byte(), |value| value.end_byte()),
+            ),
+            _ => return,
+        };
+        let Some(scope) = scope else {
+            return;
+        };
+        declarations.extend(
+            names
+                .into_iter()
+                .filter(|name| &source[name.byte_range()] != "_")
+                .map(|name| LocalDeclaration {
+                    name,
+                    scope,
+                    visible_from,
+                }),
+        );
+    });
+    declarations
+}
+
+fn is_function_scope(node: Node) -> bool {
+    matches!(
+        node.kind(),
+        "function_declaration" | "method_declaration" | "func_literal"
+    )
+}
+
+/// Nearest node that opens a scope around `node`, `None` outside functions
+///
+/// A function body shares its scope with the parameters, so the body block
+/// maps to the function itself.
+fn enclosing_scope(node: Node) -> Option<Node> {
+    let mut current = node.parent();
+    while let Some(n) = current {
+        match n.kind() {
+            "block" => {
+                return match n.parent() {
+                    Some(parent) if is_function_scope(parent) => Some(parent),
+                    _ => Some(n),
+                };
+            }
+            "if_statement"
+            | "for_statement"
+            | "expression_switch_statement"
+            | "type_switch_statement"
+            | "select_statement"
+            | "expression_case"
+            | "type_case"
+            | "default_case"
+            | "communication_case" => return Some(n),
+            _ if is_function_scope(n) => return Some(n),
+            _ => {}
+        }
+        current = n.parent();
+    }
+    None
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_enclosing_function_name_qualifies_methods() {
+        let code = r#"
+package models
+
+func (u *User) Role() UserRole {
+    return u.role
+}
+
+func NewUser() *User {
+    return &User{}
+}
+"#;
+        let file = GoSourceFile::parse("user.go", code.to_string()).unwrap();
+        assert_eq!(file.package_name(), Some("models"));
+
+        let mut names = Vec::new();
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() == "return_statement" {
+                names.push(enclosing_function_name(&file, node));
+            }
+        });
+
+        assert_eq!(
+            names,
+            vec![Some("User.Role".to_string()), Some("NewUser".to_string())]
+        );
+    }
+
+    #[test]
+    fn test_local_declarations_scopes() {
+        let code = r#"
+package app
+
+func Handle(req Request, _ int) {
+    data, _ := load(req)
+    var handler func(w Writer)
+    type result struct{}
+    switch v := data.(type) {
+    case string:
+        use(v)
+    }
+    select {
+    case msg := <-events:
+        use(msg)
+    }
+}
+"#;
+        let file = GoSourceFile::parse("app.go", code.to_string()).unwrap();
+        let root = file.root();
+        let function = root.named_child(1).unwrap();
+        let declarations: Vec<_> = local_declarations(function, &file.source)
+            .into_iter()
+            .map(|d| (file.text(d.name), d.scope.kind()))
+            .collect();
+
+        // The body shares the function's scope, `_` and the parameters of
+        // the function type declare nothing
+        assert_eq!(
+            declarations,
+            vec![
+                ("req", "function_declaration"),
+                ("data", "function_declaration"),
+                ("handler", "function_declaration"),
+                ("result", "function_declaration"),
+                ("v", "type_switch_statement"),
+                ("msg", "communication_case"),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_declaration_span_includes_doc_comment() {
+        let code = r#"package models
+
+// Role returns the user's role.
+//
+// The zero value is RoleGuest.
+func (u *User) Role() UserRole {
+    return u.role
+}
+
+const (
+    // RoleAdmin can do anything
+    RoleAdmin UserRole = iota
+    RoleGuest
+)
+
+type User struct {
+    role UserRole
+}
+"#;
+        let file = GoSourceFile::parse("user.go", code.to_string()).unwrap();
+        let span = |row| &code[declaration_span(&file, row).unwrap()];
+
+        assert!(span(5).starts_with("// Role returns"));
+        assert!(span(6).ends_with("return u.role\n}"));
+        assert_eq!(
+            span(11),
+            "// RoleAdmin can do anything\n    RoleAdmin UserRole = iota"
+        );
+        assert_eq!(span(17), "type User struct {\n    role UserRole\n}");
+        assert!(declaration_span(&file, 8).is_none());
+    }
+    #[test]
+    fn test_interface_table_flattens_embeds() {
+        let code = r#"package storage
+
+import "io"
+
+type Closer interface {
+    Close() error
+}
+
+type Store interface {
+    Load(id string) error
+    Closer
+    io.Reader
+}
+
+type Number interface {
+    ~int | ~float64
+}
+"#;
+        let files = vec![GoSourceFile::parse("storage.go", code.to_string()).unwrap()];
+        let table = InterfaceTable::build(&files, StdlibResolution::Builtin);
+
+        let names = |methods: Vec<(String, String)>| -> Vec<String> {
+            methods.into_iter().map(|(name, _)| name).collect()
+        };
+        assert_eq!(
+            table.method_set("storage.Store").map(names),
+            Some(vec![
+                "Close".to_string(),
+                "Load".to_string(),
+                "Read".to_string()
+            ])
+        );
+        assert!(table.method_set("storage.Number").is_none());
+        assert!(table.is_interface("io.Reader"));
+
+        // Declared methods only, in source order, with their interface
+        let declared: Vec<_> = table
+            .declared_methods("storage.Store")
+            .into_iter()
+            .map(|(interface, _, method)| (interface, method.name))
+            .collect();
+        assert_eq!(
+            declared,
+            vec![("storage.Store", "Load"), ("storage.Closer", "Close")]
+        );
+
+        let off = InterfaceTable::build(&files, StdlibResolution::Off);
+        assert!(off.method_set("storage.Store").is_none());
+    }
+}
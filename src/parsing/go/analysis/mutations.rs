@@ -0,0 +1,405 @@
+//! Writes to package-level variables
+//!
+//! Package-level variables are shared by every goroutine, so each place one
+//! is written is a candidate for a data race:
+//!
+//! ```go
+//! var userCounter int
+//!
+//! func NewUser(name string) *User {
+//!     userCounter++ // mutation
+//!     ...
+//! }
+//! ```
+//!
+//! Plain and compound assignments and `++`/`--` statements count, including
+//! writes to an element or field of the variable (`cache[key] = v`,
+//! `config.Debug = true`). Exported variables are also followed into the
+//! packages importing them (`models.DefaultRole = ...`). Writes through a
+//! local of the same name, such as a parameter or `:=` declaration, are not
+//! the package variable and are left out.
+
+use super::{GoSourceFile, enclosing_function_name, walk_tree};
+use serde::Serialize;
+use std::collections::HashSet;
+use std::fmt;
+use std::path::Path;
+use tree_sitter::Node;
+
+/// A statement writing to a package-level variable
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct VariableMutation {
+    pub variable: String,
+    /// Package declaring the variable
+    pub package: String,
+    /// `=`, a compound operator like `+=`, `++` or `--`
+    pub operator: String,
+    /// Written expression: the variable itself or an element or field of it
+    pub target: String,
+    /// Function or method containing the write
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the write
+    pub line: u32,
+    /// 1-based column of the write
+    pub column: u32,
+}
+
+impl fmt::Display for VariableMutation {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        if let Some(function) = &self.function {
+            write!(f, "{function}: ")?;
+        }
+        write!(
+            f,
+            "writes {} with {} at {}:{}:{}",
+            self.target, self.operator, self.file, self.line, self.column
+        )
+    }
+}
+
+/// Find the writes to the package-level variable `name`, sorted by file and
+/// line
+///
+/// A `package.name` query restricts the lookup to one package. Returns `None`
+/// when no indexed package declares the variable.
+pub fn find_variable_mutations(
+    files: &[GoSourceFile],
+    name: &str,
+) -> Option<Vec<VariableMutation>> {
+    let (package_filter, variable) = match name.rsplit_once('.') {
+        Some((package, variable)) => (Some(package), variable),
+        None => (None, name),
+    };
+
+    // (directory, package) of each package declaring the variable
+    let mut declaring: HashSet<(&Path, &str)> = HashSet::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        if package_filter.is_some_and(|p| p != package) {
+            continue;
+        }
+        if declares_variable(file, variable) {
+            declaring.insert((file.path.parent().unwrap_or(Path::new("")), package));
+        }
+    }
+    if declaring.is_empty() {
+        return None;
+    }
+
+    let exported = variable.starts_with(|c: char| c.is_uppercase());
+    let mut findings = Vec::new();
+    for file in files {
+        let dir = file.path.parent().unwrap_or(Path::new(""));
+        let package = file.package_name().unwrap_or_default();
+        if declaring.contains(&(dir, package)) {
+            findings.extend(mutations_in_file(file, variable, None, package));
+        }
+        if !exported {
+            continue;
+        }
+        // Imports of a declaring package, by local name
+        for (local, path) in file.import_aliases() {
+            let last = path.rsplit('/').next().unwrap_or(&path);
+            let imported = declaring
+                .iter()
+                .find(|(dir, _)| dir.file_name().and_then(|d| d.to_str()) == Some(last));
+            if let Some((_, package)) = imported {
+                findings.extend(mutations_in_file(
+                    file,
+                    variable,
+                    Some(local.as_str()),
+                    package,
+                ));
+            }
+        }
+    }
+    findings.sort_by(|a, b| (&a.file, a.line, a.column).cmp(&(&b.file, b.line, b.column)));
+    Some(findings)
+}
+
+/// Whether `file` declares `name` in a top-level `var` declaration
+fn declares_variable(file: &GoSourceFile, name: &str) -> bool {
+    let root = file.root();
+    let mut declared = false;
+    for decl in root.named_children(&mut root.walk()) {
+        if decl.kind() != "var_declaration" {
+            continue;
+        }
+        walk_tree(decl, &mut |node| {
+            if node.kind() == "var_spec"
+                && node
+                    .children_by_field_name("name", &mut node.walk())
+                    .any(|n| file.text(n) == name)
+            {
+                declared = true;
+            }
+        });
+    }
+    declared
+}
+
+/// Writes to `name` in `file`, referred to as `qualifier.name` when given
+fn mutations_in_file(
+    file: &GoSourceFile,
+    name: &str,
+    qualifier: Option<&str>,
+    package: &str,
+) -> Vec<VariableMutation> {
+    let mut findings = Vec::new();
+    walk_tree(file.root(), &mut |node| {
+        let (targets, operator): (Vec<Node>, String) = match node.kind() {
+            "assignment_statement" => {
+                let Some(left) = node.child_by_field_name("left") else {
+                    return;
+                };
+                let operator = node
+                    .child_by_field_name("operator")
+                    .map(|op| file.text(op).to_string())
+                    .unwrap_or_else(|| "=".to_string());
+                (left.named_children(&mut left.walk()).collect(), operator)
+            }
+            "inc_statement" | "dec_statement" => {
+                let operator = if node.kind() == "inc_statement" {
+                    "++"
+                } else {
+                    "--"
+                };
+                (
+                    node.named_children(&mut node.walk()).collect(),
+                    operator.to_string(),
+                )
+            }
+            _ => return,
+        };
+        for target in targets {
+            if !writes_variable(file, target, name, qualifier) {
+                continue;
+            }
+            if qualifier.is_none() && shadowed(file, target, name) {
+                continue;
+            }
+            let position = target.start_position();
+            findings.push(VariableMutation {
+                variable: name.to_string(),
+                package: package.to_string(),
+                operator: operator.clone(),
+                target: file.text(target).to_string(),
+                function: enclosing_function_name(file, node),
+                file: file.display_path(),
+                line: position.row as u32 + 1,
+                column: position.column as u32 + 1,
+            });
+        }
+    });
+    findings
+}
+
+/// Whether assigning to `target` writes the variable or part of it
+///
+/// Element, field, dereference and parenthesized forms are unwrapped down to
+/// the variable: `cache[key]`, `config.Debug`, `*counter`, `(total)`.
+fn writes_variable(file: &GoSourceFile, target: Node, name: &str, qualifier: Option<&str>) -> bool {
+    let mut current = target;
+    loop {
+        match current.kind() {
+            "identifier" => return qualifier.is_none() && file.text(current) == name,
+            "selector_expression" => {
+                let (Some(operand), Some(field)) = (
+                    current.child_by_field_name("operand"),
+                    current.child_by_field_name("field"),
+                ) else {
+                    return false;
+                };
+                if let Some(qualifier) = qualifier {
+                    if operand.kind() == "identifier"
+                        && file.text(operand) == qualifier
+                        && file.text(field) == name
+                    {
+                        return true;
+                    }
+                }
+                current = operand;
+            }
+            "index_expression" => match current.child_by_field_name("operand") {
+                Some(operand) => current = operand,
+                None => return false,
+            },
+            "unary_expression" | "parenthesized_expression" => {
+                match current
+                    .child_by_field_name("operand")
+                    .or(current.named_child(0))
+                {
+                    Some(operand) => current = operand,
+                    None => return false,
+                }
+            }
+            _ => return false,
+        }
+    }
+}
+
+/// Whether a local declaration of `name` is in scope at `site`
+///
+/// Walks outwards from `site` up to the enclosing top-level declaration,
+/// looking at the declarations preceding it in each scope and at function
+/// parameters.
+fn shadowed(file: &GoSourceFile, site: Node, name: &str) -> bool {
+    let mut current = site;
+    while let Some(scope) = current.parent() {
+        if scope.kind() == "source_file" {
+            break;
+        }
+        let declared = scope
+            .named_children(&mut scope.walk())
+            .filter(|child| child.end_byte() <= site.start_byte() || child.id() == current.id())
+            .any(|child| declared_names(file, child).contains(&name));
+        if declared {
+            return true;
+        }
+        current = scope;
+    }
+    false
+}
+
+/// Names declared by a statement or parameter list, without descending into
+/// nested scopes
+fn declared_names<'s>(file: &'s GoSourceFile, node: Node) -> Vec<&'s str> {
+    let identifiers = |list: Option<Node>| -> Vec<&'s str> {
+        list.map(|list| {
+            list.named_children(&mut list.walk())
+                .filter(|n| n.kind() == "identifier")
+                .map(|n| file.text(n))
+                .collect()
+        })
+        .unwrap_or_default()
+    };
+    match node.kind() {
+        "short_var_declaration" => identifiers(node.child_by_field_name("left")),
+        "range_clause" => {
+            let declares = node
+                .children(&mut node.walk())
+                .any(|child| child.kind() == ":=");
+            if declares {
+                identifiers(node.child_by_field_name("left"))
+            } else {
+                Vec::new()
+            }
+        }
+        "for_clause" => node
+            .child_by_field_name("initializer")
+            .map(|init| declared_names(file, init))
+            .unwrap_or_default(),
+        "var_declaration" | "parameter_list" => {
+            let mut names = Vec::new();
+            walk_tree(node, &mut |spec| {
+                if matches!(
+                    spec.kind(),
+                    "var_spec" | "parameter_declaration" | "variadic_parameter_declaration"
+                ) {
+                    names.extend(
+                        spec.children_by_field_name("name", &mut spec.walk())
+                            .map(|n| file.text(n)),
+                    );
+                }
+            });
+            names
+        }
+        _ => Vec::new(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_package_variable_mutations_across_files() {
+        let user = r#"
+package models
+
+var (
+    DefaultRole = "user"
+    userCounter int
+    cache       = map[string]int{}
+)
+
+func NewUser(name string) {
+    userCounter++
+    cache[name] += 1
+}
+
+func Reset(userCounter int) {
+    userCounter = 0
+}
+"#;
+        let admin = r#"
+package models
+
+func RemoveUser() {
+    if userCounter := 3; userCounter > 0 {
+        userCounter--
+    }
+    userCounter -= 1
+}
+"#;
+        let handler = r#"
+package handlers
+
+import m "example.com/app/models"
+
+func Promote() {
+    m.DefaultRole = "admin"
+    role := m.DefaultRole
+    role = "guest"
+}
+"#;
+        let files = vec![
+            parse("app/models/user.go", user),
+            parse("app/models/admin.go", admin),
+            parse("app/handlers/promote.go", handler),
+        ];
+
+        let counter = find_variable_mutations(&files, "userCounter").unwrap();
+        let summary: Vec<_> = counter
+            .iter()
+            .map(|m| {
+                (
+                    m.file.as_str(),
+                    m.line,
+                    m.operator.as_str(),
+                    m.function.as_deref(),
+                )
+            })
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("app/models/admin.go", 8, "-=", Some("RemoveUser")),
+                ("app/models/user.go", 11, "++", Some("NewUser")),
+            ]
+        );
+
+        let cache = find_variable_mutations(&files, "models.cache").unwrap();
+        assert_eq!(cache.len(), 1);
+        assert_eq!(cache[0].target, "cache[name]");
+        assert_eq!(cache[0].operator, "+=");
+
+        let role = find_variable_mutations(&files, "DefaultRole").unwrap();
+        assert_eq!(role.len(), 1);
+        assert_eq!(role[0].file, "app/handlers/promote.go");
+        assert_eq!(role[0].target, "m.DefaultRole");
+        assert_eq!(role[0].package, "models");
+        assert_eq!(
+            role[0].to_string(),
+            "Promote: writes m.DefaultRole with = at app/handlers/promote.go:7:5"
+        );
+
+        assert!(find_variable_mutations(&files, "handlers.DefaultRole").is_none());
+    }
+}
@@ -0,0 +1,397 @@
+//! Method calls on results that may be nil (advisory)
+//!
+//! Some constructors return nil for inputs they don't handle:
+//!
+//! ```go
+//! func CreateProcessor(processorType string) DataProcessor {
+//!     switch processorType {
+//!     case "file":
+//!         return &FileProcessor{}
+//!     default:
+//!         return nil
+//!     }
+//! }
+//! ```
+//!
+//! A method call on such a result without a nil check in between may panic.
+//! The analysis is deliberately conservative: a function is a nil source only
+//! when it has a `return nil` and no `error` result (`return nil, err` is the
+//! error convention, covered by checking `err`), calls are matched by name
+//! within one function body, and only the first method call after each
+//! assignment is reported. A `// codanna:ignore nil-receivers` comment on the
+//! call or the line above suppresses the finding.
+
+use super::{GoSourceFile, enclosing_function_name, is_suppressed, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "nil-receivers";
+
+/// A method call on a value that may be nil
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct NilReceiverCall {
+    /// Receiver as written: a variable, or the call producing it
+    pub receiver: String,
+    pub method: String,
+    /// Function that may return nil
+    pub source: String,
+    /// Location of the source's `return nil`
+    pub source_file: String,
+    pub source_line: u32,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the method call
+    pub line: u32,
+}
+
+impl fmt::Display for NilReceiverCall {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{}() may be called on nil from {}() ({}:{})",
+            self.receiver, self.method, self.source, self.source_file, self.source_line
+        )?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// A function with a `return nil` path
+#[derive(Debug, Clone)]
+struct NilSource {
+    name: String,
+    file: String,
+    line: u32,
+}
+
+/// Find method calls on possibly-nil results, sorted by file and line
+pub fn find_nil_receiver_calls(files: &[GoSourceFile]) -> Vec<NilReceiverCall> {
+    let sources = nil_returning_functions(files);
+    if sources.is_empty() {
+        return Vec::new();
+    }
+    let mut findings: Vec<_> = files
+        .iter()
+        .flat_map(|file| nil_receiver_calls_in_file(file, &sources))
+        .collect();
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Package-level functions returning nil on some path, keyed by name
+fn nil_returning_functions(files: &[GoSourceFile]) -> HashMap<String, NilSource> {
+    let mut sources = HashMap::new();
+    for file in files {
+        let root = file.root();
+        for decl in root.named_children(&mut root.walk()) {
+            if decl.kind() != "function_declaration" || has_error_result(file, decl) {
+                continue;
+            }
+            let (Some(name), Some(body)) = (
+                decl.child_by_field_name("name"),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+            let mut nil_return = None;
+            walk_tree(body, &mut |node| {
+                if nil_return.is_none()
+                    && node.kind() == "return_statement"
+                    && !in_function_literal(node, decl)
+                    && first_returned(node).is_some_and(|value| value.kind() == "nil")
+                {
+                    nil_return = Some(line_of(node));
+                }
+            });
+            if let Some(line) = nil_return {
+                let name = file.text(name).to_string();
+                sources.entry(name.clone()).or_insert(NilSource {
+                    name,
+                    file: file.display_path(),
+                    line,
+                });
+            }
+        }
+    }
+    sources
+}
+
+/// Whether a declaration has an `error` among its results
+fn has_error_result(file: &GoSourceFile, decl: Node) -> bool {
+    let Some(result) = decl.child_by_field_name("result") else {
+        return false;
+    };
+    if result.kind() != "parameter_list" {
+        return file.text(result) == "error";
+    }
+    result
+        .named_children(&mut result.walk())
+        .filter_map(|param| param.child_by_field_name("type"))
+        .any(|t| file.text(t) == "error")
+}
+
+/// Whether `node` sits in a function literal nested in `decl`
+fn in_function_literal(node: Node, decl: Node) -> bool {
+    let mut current = node.parent();
+    while let Some(n) = current {
+        if n.id() == decl.id() {
+            return false;
+        }
+        if n.kind() == "func_literal" {
+            return true;
+        }
+        current = n.parent();
+    }
+    false
+}
+
+/// First value of a return statement
+fn first_returned(ret: Node) -> Option<Node> {
+    let values = ret.named_child(0)?;
+    match values.kind() {
+        "expression_list" => values.named_child(0),
+        _ => Some(values),
+    }
+}
+
+/// What happens to a variable at a position in a function body
+enum Event<'t> {
+    /// Assignment, with the nil source of the value if any
+    Assign(Option<&'t NilSource>),
+    NilCheck,
+    MethodCall(Node<'t>, &'t str),
+}
+
+fn nil_receiver_calls_in_file(
+    file: &GoSourceFile,
+    sources: &HashMap<String, NilSource>,
+) -> Vec<NilReceiverCall> {
+    let aliases = file.import_aliases();
+    let source_of = |value: Node| nil_source(file, value, &aliases, sources);
+
+    let mut findings = Vec::new();
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+
+        // Events per variable, in source order
+        let mut events: Vec<(&str, Event)> = Vec::new();
+        walk_tree(decl, &mut |node| match node.kind() {
+            "short_var_declaration" | "assignment_statement" | "var_spec" => {
+                let (names, values) = match node.kind() {
+                    "var_spec" => (
+                        node.children_by_field_name("name", &mut node.walk())
+                            .collect::<Vec<_>>(),
+                        node.child_by_field_name("value")
+                            .map(|v| v.named_children(&mut v.walk()).collect::<Vec<_>>())
+                            .unwrap_or_default(),
+                    ),
+                    _ => {
+                        let (Some(left), Some(right)) = (
+                            node.child_by_field_name("left"),
+                            node.child_by_field_name("right"),
+                        ) else {
+                            return;
+                        };
+                        (
+                            left.named_children(&mut left.walk()).collect(),
+                            right.named_children(&mut right.walk()).collect(),
+                        )
+                    }
+                };
+                for (i, name) in names.iter().enumerate() {
+                    if name.kind() != "identifier" {
+                        continue;
+                    }
+                    let source = values.get(i).and_then(|value| source_of(*value));
+                    events.push((file.text(*name), Event::Assign(source)));
+                }
+            }
+            "binary_expression" => {
+                let is_equality = node
+                    .child_by_field_name("operator")
+                    .is_some_and(|op| matches!(file.text(op), "==" | "!="));
+                let (Some(left), Some(right)) = (
+                    node.child_by_field_name("left"),
+                    node.child_by_field_name("right"),
+                ) else {
+                    return;
+                };
+                for (checked, other) in [(left, right), (right, left)] {
+                    if is_equality && checked.kind() == "identifier" && other.kind() == "nil" {
+                        events.push((file.text(checked), Event::NilCheck));
+                    }
+                }
+            }
+            "call_expression" => {
+                let Some(selector) = node
+                    .child_by_field_name("function")
+                    .filter(|f| f.kind() == "selector_expression")
+                else {
+                    return;
+                };
+                let (Some(operand), Some(method)) = (
+                    selector.child_by_field_name("operand"),
+                    selector.child_by_field_name("field"),
+                ) else {
+                    return;
+                };
+                match operand.kind() {
+                    "identifier" => events.push((
+                        file.text(operand),
+                        Event::MethodCall(node, file.text(method)),
+                    )),
+                    // `CreateProcessor("csv").Process(data)`
+                    "call_expression" => {
+                        if let Some(source) = source_of(operand) {
+                            findings.push(finding(file, node, operand, file.text(method), source));
+                        }
+                    }
+                    _ => {}
+                }
+            }
+            _ => {}
+        });
+
+        // Latest nil source per variable, cleared by a check, a reassignment
+        // or the first reported call
+        let mut pending: HashMap<&str, &NilSource> = HashMap::new();
+        for (var, event) in events {
+            match event {
+                Event::Assign(Some(source)) => {
+                    pending.insert(var, source);
+                }
+                Event::Assign(None) | Event::NilCheck => {
+                    pending.remove(var);
+                }
+                Event::MethodCall(call, method) => {
+                    if let Some(source) = pending.remove(var) {
+                        let operand = call
+                            .child_by_field_name("function")
+                            .and_then(|f| f.child_by_field_name("operand"))
+                            .unwrap_or(call);
+                        findings.push(finding(file, call, operand, method, source));
+                    }
+                }
+            }
+        }
+    }
+
+    findings.retain(|f: &NilReceiverCall| !is_suppressed(file, f.line, ANALYSIS_NAME));
+    findings
+}
+
+/// Nil source called to produce `value`, if any
+fn nil_source<'s>(
+    file: &GoSourceFile,
+    value: Node,
+    aliases: &HashMap<String, String>,
+    sources: &'s HashMap<String, NilSource>,
+) -> Option<&'s NilSource> {
+    if value.kind() != "call_expression" {
+        return None;
+    }
+    let function = value.child_by_field_name("function")?;
+    let name = match function.kind() {
+        "identifier" => file.text(function),
+        // `pkg.CreateProcessor(...)` from an imported package
+        "selector_expression" => {
+            let operand = function.child_by_field_name("operand")?;
+            if !aliases.contains_key(file.text(operand)) {
+                return None;
+            }
+            file.text(function.child_by_field_name("field")?)
+        }
+        _ => return None,
+    };
+    sources.get(name)
+}
+
+fn finding(
+    file: &GoSourceFile,
+    call: Node,
+    receiver: Node,
+    method: &str,
+    source: &NilSource,
+) -> NilReceiverCall {
+    NilReceiverCall {
+        receiver: file.text(receiver).to_string(),
+        method: method.to_string(),
+        source: source.name.clone(),
+        source_file: source.file.clone(),
+        source_line: source.line,
+        function: enclosing_function_name(file, call),
+        file: file.display_path(),
+        line: line_of(call),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_method_calls_on_nil_constructor_results() {
+        let code = r#"
+package main
+
+func CreateProcessor(kind string) DataProcessor {
+    switch kind {
+    case "file":
+        return &FileProcessor{}
+    default:
+        return nil
+    }
+}
+
+func LoadProcessor(path string) (DataProcessor, error) {
+    return nil, nil
+}
+
+func Unchecked(data []byte) {
+    p := CreateProcessor("csv")
+    p.Process(data)
+    p.Validate(data)
+    CreateProcessor("json").Process(data)
+}
+
+func Checked(data []byte) {
+    p := CreateProcessor("csv")
+    if p == nil {
+        return
+    }
+    p.Process(data)
+    q, _ := LoadProcessor("x")
+    q.Process(data)
+    p = CreateProcessor("file")
+    // codanna:ignore nil-receivers
+    p.Process(data)
+}
+"#;
+        let files = vec![GoSourceFile::parse("main.go", code.to_string()).unwrap()];
+        let findings = find_nil_receiver_calls(&files);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.receiver.as_str(), f.method.as_str(), f.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("p", "Process", 19),
+                ("CreateProcessor(\"json\")", "Process", 21)
+            ]
+        );
+        assert_eq!(findings[0].source, "CreateProcessor");
+        assert_eq!(findings[0].source_line, 9);
+        assert_eq!(findings[0].function.as_deref(), Some("Unchecked"));
+    }
+}
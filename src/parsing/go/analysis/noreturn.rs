@@ -0,0 +1,354 @@
+//! Functions that never return
+//!
+//! ```go
+//! func fatalf(format string, args ...interface{}) {
+//!     log.Fatalf(format, args...)
+//! }
+//!
+//! func exitWith(err error) {
+//!     if err != nil {
+//!         fatalf("fatal: %v", err)
+//!     } else {
+//!         os.Exit(0)
+//!     }
+//! }
+//! ```
+//!
+//! Every path through both functions ends the program, so code after a call
+//! to them is dead. A function never returns when its body has no `return`
+//! and ends in a terminating statement, as the Go spec defines them, whose
+//! calls are all to `panic`, `os.Exit`, `log.Fatal*`, `log.Panic*` or a
+//! function of the same package already found not to return. An `if` needs
+//! both branches to terminate; a `switch` also needs a `default` and no
+//! `break`. Infinite `for` loops never return either, but end in no call and
+//! are not reported.
+
+use super::{GoSourceFile, declaration_name, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// A function whose every path ends in a terminating call
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct NoReturnFunction {
+    /// Function name; methods as `Type.Method`
+    pub function: String,
+    pub package: String,
+    pub file: String,
+    /// 1-based line of the function declaration
+    pub line: u32,
+    /// Call ending the first path, as written: `panic`, `log.Fatalf`, ...
+    pub terminator: String,
+    /// 1-based line of that call
+    pub terminator_line: u32,
+}
+
+impl fmt::Display for NoReturnFunction {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} never returns, ending in {} (line {}) at {}:{}",
+            self.package,
+            self.function,
+            self.terminator,
+            self.terminator_line,
+            self.file,
+            self.line
+        )
+    }
+}
+
+/// Find the functions that never return, sorted by file and line
+pub fn find_noreturn_functions(files: &[GoSourceFile]) -> Vec<NoReturnFunction> {
+    // (package, function) pairs known not to return; calls to them terminate
+    // too, so passes repeat until no new function is found
+    let mut known: HashSet<(String, String)> = HashSet::new();
+    let mut found = Vec::new();
+    loop {
+        let mut new = Vec::new();
+        for file in files {
+            let Some(package) = file.package_name() else {
+                continue;
+            };
+            let terminators = Terminators {
+                file,
+                package,
+                aliases: file.import_aliases(),
+                known: &known,
+            };
+            let root = file.root();
+            for decl in root.named_children(&mut root.walk()) {
+                if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                    continue;
+                }
+                let (Some(function), Some(body)) = (
+                    declaration_name(file, decl),
+                    decl.child_by_field_name("body"),
+                ) else {
+                    continue;
+                };
+                if known.contains(&(package.to_string(), function.clone())) || has_return(body) {
+                    continue;
+                }
+                let Some(call) = terminators.terminating(body) else {
+                    continue;
+                };
+                let terminator = call
+                    .child_by_field_name("function")
+                    .map_or("", |f| file.text(f));
+                new.push(NoReturnFunction {
+                    function,
+                    package: package.to_string(),
+                    file: file.display_path(),
+                    line: line_of(decl),
+                    terminator: terminator.to_string(),
+                    terminator_line: line_of(call),
+                });
+            }
+        }
+        if new.is_empty() {
+            break;
+        }
+        known.extend(new.iter().map(|f| (f.package.clone(), f.function.clone())));
+        found.extend(new);
+    }
+    found.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    found
+}
+
+/// Terminating statements of one file
+struct Terminators<'a> {
+    file: &'a GoSourceFile,
+    package: &'a str,
+    aliases: HashMap<String, String>,
+    known: &'a HashSet<(String, String)>,
+}
+
+impl Terminators<'_> {
+    /// The call ending the first path through a terminating statement
+    fn terminating<'t>(&self, node: Node<'t>) -> Option<Node<'t>> {
+        match node.kind() {
+            "expression_statement" => node
+                .named_child(0)
+                .filter(|call| self.is_terminating_call(*call)),
+            "block" | "labeled_statement" => node
+                .named_children(&mut node.walk())
+                .filter(|n| !matches!(n.kind(), "label_name" | "comment"))
+                .last()
+                .and_then(|inner| self.terminating(inner)),
+            "statement_list" => last_statement(node).and_then(|s| self.terminating(s)),
+            "if_statement" => {
+                let consequence = self.terminating(node.child_by_field_name("consequence")?)?;
+                self.terminating(node.child_by_field_name("alternative")?)?;
+                Some(consequence)
+            }
+            "expression_switch_statement" | "type_switch_statement" | "select_statement" => {
+                let cases: Vec<_> = node
+                    .named_children(&mut node.walk())
+                    .filter(|n| n.kind().ends_with("_case"))
+                    .collect();
+                let defaulted = node.kind() == "select_statement"
+                    || cases.iter().any(|c| c.kind() == "default_case");
+                if cases.is_empty() || !defaulted || breaks_out_of(node) {
+                    return None;
+                }
+                let mut first = None;
+                for case in cases {
+                    let statements = case
+                        .named_children(&mut case.walk())
+                        .find(|n| n.kind() == "statement_list")?;
+                    let last = last_statement(statements)?;
+                    if last.kind() == "fallthrough_statement" {
+                        continue;
+                    }
+                    let call = self.terminating(last)?;
+                    first.get_or_insert(call);
+                }
+                first
+            }
+            _ => None,
+        }
+    }
+
+    /// Whether a call never returns: a built-in terminator or a known
+    /// function of the same package
+    fn is_terminating_call(&self, call: Node) -> bool {
+        if call.kind() != "call_expression" {
+            return false;
+        }
+        let Some(function) = call.child_by_field_name("function") else {
+            return false;
+        };
+        match function.kind() {
+            "identifier" => {
+                let name = self.file.text(function);
+                name == "panic"
+                    || self
+                        .known
+                        .contains(&(self.package.to_string(), name.to_string()))
+            }
+            "selector_expression" => {
+                let (Some(operand), Some(field)) = (
+                    function.child_by_field_name("operand"),
+                    function.child_by_field_name("field"),
+                ) else {
+                    return false;
+                };
+                let path = self
+                    .aliases
+                    .get(self.file.text(operand))
+                    .map(String::as_str);
+                matches!(
+                    (path, self.file.text(field)),
+                    (Some("os"), "Exit")
+                        | (
+                            Some("log"),
+                            "Fatal" | "Fatalf" | "Fatalln" | "Panic" | "Panicf" | "Panicln"
+                        )
+                )
+            }
+            _ => false,
+        }
+    }
+}
+
+/// Last statement of a list, skipping comments and empty statements
+fn last_statement(list: Node) -> Option<Node> {
+    list.named_children(&mut list.walk())
+        .filter(|n| !matches!(n.kind(), "comment" | "empty_statement"))
+        .last()
+}
+
+/// Whether a function body has a `return` of its own, outside closures
+fn has_return(body: Node) -> bool {
+    let mut found = false;
+    walk_tree(body, &mut |node| {
+        found |= node.kind() == "return_statement" && !inside_closure(node, body);
+    });
+    found
+}
+
+/// Whether `node` is inside a function literal within `outer`
+fn inside_closure(node: Node, outer: Node) -> bool {
+    let mut current = node.parent();
+    while let Some(parent) = current {
+        if parent.id() == outer.id() {
+            return false;
+        }
+        if parent.kind() == "func_literal" {
+            return true;
+        }
+        current = parent.parent();
+    }
+    false
+}
+
+/// Whether a `break` leaves `statement`: an unlabeled one not inside a
+/// nested loop, switch or select, or any labeled one
+fn breaks_out_of(statement: Node) -> bool {
+    let mut found = false;
+    walk_tree(statement, &mut |node| {
+        if node.kind() != "break_statement" || inside_closure(node, statement) {
+            return;
+        }
+        if node.named_child_count() > 0 {
+            found = true;
+            return;
+        }
+        let mut current = node.parent();
+        while let Some(parent) = current {
+            if parent.id() == statement.id() {
+                found = true;
+                return;
+            }
+            if matches!(
+                parent.kind(),
+                "for_statement"
+                    | "expression_switch_statement"
+                    | "type_switch_statement"
+                    | "select_statement"
+            ) {
+                return;
+            }
+            current = parent.parent();
+        }
+    });
+    found
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_functions_that_never_return() {
+        let file = GoSourceFile::read(std::path::Path::new("tests/fixtures/go/basic.go")).unwrap();
+        let code = r#"
+package worker
+
+import "os"
+
+func mustRun(cmd string) {
+    switch cmd {
+    case "start":
+        panic("already running")
+    default:
+        os.Exit(2)
+    }
+}
+
+func maybeExit(code int) {
+    if code != 0 {
+        os.Exit(code)
+    }
+}
+
+func loop(jobs chan int) {
+    for job := range jobs {
+        if job < 0 {
+            panic("negative job")
+        }
+    }
+    panic("jobs closed")
+}
+
+func finish(code int) {
+    switch code {
+    case 1:
+        break
+    default:
+        panic("unknown")
+    }
+    os.Exit(code)
+}
+
+func guarded(code int) {
+    if code == 0 {
+        return
+    }
+    os.Exit(code)
+}
+"#;
+        let worker = GoSourceFile::parse("worker/worker.go", code.to_string()).unwrap();
+        let found = find_noreturn_functions(&[file, worker]);
+        let summary: Vec<_> = found
+            .iter()
+            .map(|f| format!("{} {}", f.function, f.terminator))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                "fatalf mylog.Fatalf",
+                "exitWith fatalf",
+                "mustRun panic",
+                "loop panic",
+                "finish os.Exit",
+            ]
+        );
+        assert_eq!(
+            found[0].to_string(),
+            "main.fatalf never returns, ending in mylog.Fatalf (line 109) at tests/fixtures/go/basic.go:108"
+        );
+    }
+}
@@ -0,0 +1,464 @@
+//! Import graph of the indexed Go packages
+//!
+//! A package is a directory of indexed Go files. Its import path is the
+//! module path of the nearest `go.mod` followed by the directory relative to
+//! the module root:
+//!
+//! ```text
+//! go.mod              module example.com/app
+//! models/user.go      example.com/app/models
+//! services/auth.go    example.com/app/services, imports "example.com/app/models"
+//! ```
+//!
+//! Each import of a path inside the module, or of a path a `replace`
+//! directive maps to a local directory, is an edge of the graph. When no
+//! indexed package sits in the imported directory the import is
+//! unresolved: it points into the module, but the graph can't follow it.
+//! Standard library and third-party imports are not part of the graph.
+//! Files outside any module are keyed by their directory, and their imports
+//! resolve to the package whose directory the import path ends with.
+//! External `_test` packages may import the package they test and are left
+//! out. A blank import (`import _ "pkg"`) is an edge too, marked as a
+//! side-effect import: it binds no name but still runs the package's `init`
+//! functions.
+
+use super::{GoSourceFile, line_of, walk_tree};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::fmt;
+use std::path::{Component, Path, PathBuf};
+use tree_sitter::Node;
+
+/// An import of one package by another
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PackageImport {
+    /// Import path of the importing package
+    pub from: String,
+    /// Import path as written
+    pub import_path: String,
+    /// Import path of the imported package; `None` when unresolved
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub to: Option<String>,
+    pub file: String,
+    /// 1-based line of the import spec
+    pub line: u32,
+    /// Blank import, kept only for the package's side effects
+    #[serde(skip_serializing_if = "std::ops::Not::not")]
+    pub side_effect: bool,
+}
+
+impl fmt::Display for PackageImport {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match &self.to {
+            Some(to) => write!(f, "{} imports {to}", self.from)?,
+            None => write!(f, "{} imports unresolved {}", self.from, self.import_path)?,
+        }
+        if self.side_effect {
+            write!(f, " for side effects")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Packages of the indexed files and the imports between them
+#[derive(Debug, Clone, Default)]
+pub struct PackageGraph {
+    /// Import path of every package
+    pub packages: BTreeSet<String>,
+    /// Imports into the module, sorted by file and line
+    pub imports: Vec<PackageImport>,
+    /// Import path of the package of each file, keyed by display path
+    pub files: BTreeMap<String, String>,
+}
+
+impl PackageGraph {
+    /// Build the graph of the packages of `files`
+    pub fn build(files: &[GoSourceFile]) -> Self {
+        let mut modules = ModuleCache::default();
+        // Package directory to import path
+        let mut directories: HashMap<PathBuf, String> = HashMap::new();
+        let packaged = |file: &&GoSourceFile| {
+            file.package_name()
+                .is_some_and(|name| !name.ends_with("_test"))
+        };
+        let mut package_files = BTreeMap::new();
+        for file in files.iter().filter(packaged) {
+            let directory = package_directory(file);
+            let import_path = modules.import_path(&directory);
+            package_files.insert(file.display_path(), import_path.clone());
+            directories.insert(directory, import_path);
+        }
+
+        let mut imports = Vec::new();
+        for file in files.iter().filter(packaged) {
+            let directory = package_directory(file);
+            let Some(from) = directories.get(&directory) else {
+                continue;
+            };
+            let module = modules.module_of(&directory);
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() != "import_spec" {
+                    return;
+                }
+                let Some(path) = node.child_by_field_name("path") else {
+                    return;
+                };
+                let import_path = file.text(path).trim_matches(|c| c == '"' || c == '`');
+                let to = match &module {
+                    Some(module) => {
+                        let Some(target) = module.directory_of(import_path) else {
+                            return;
+                        };
+                        directories.get(&target).cloned()
+                    }
+                    None => directories
+                        .values()
+                        .filter(|package| {
+                            import_path == package.as_str()
+                                || import_path.ends_with(&format!("/{package}"))
+                        })
+                        .max_by_key(|package| package.len())
+                        .cloned(),
+                };
+                if module.is_none() && to.is_none() {
+                    return;
+                }
+                imports.push(PackageImport {
+                    from: from.clone(),
+                    import_path: import_path.to_string(),
+                    to,
+                    file: file.display_path(),
+                    line: line_of(node),
+                    side_effect: node
+                        .child_by_field_name("name")
+                        .is_some_and(|name| name.kind() == "blank_identifier"),
+                });
+            });
+        }
+        imports.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+
+        Self {
+            packages: directories.into_values().collect(),
+            imports,
+            files: package_files,
+        }
+    }
+
+    /// Packages each package imports, each listed once and sorted
+    pub fn dependencies(&self) -> BTreeMap<&str, BTreeSet<&str>> {
+        let mut dependencies: BTreeMap<&str, BTreeSet<&str>> = self
+            .packages
+            .iter()
+            .map(|package| (package.as_str(), BTreeSet::new()))
+            .collect();
+        for import in &self.imports {
+            if let Some(to) = &import.to {
+                dependencies
+                    .entry(import.from.as_str())
+                    .or_default()
+                    .insert(to.as_str());
+            }
+        }
+        dependencies
+    }
+
+    /// Packages in build order: each after every package it imports, ties
+    /// broken by import path
+    ///
+    /// Returns `None` when imports form a cycle, which leaves no valid
+    /// order; [`super::find_import_cycles`] names the packages involved.
+    pub fn topological_order(&self) -> Option<Vec<&str>> {
+        let dependencies = self.dependencies();
+        let mut waiting: BTreeMap<&str, usize> = BTreeMap::new();
+        let mut dependents: BTreeMap<&str, Vec<&str>> = BTreeMap::new();
+        for (&package, imports) in &dependencies {
+            waiting.insert(package, imports.len());
+            for &import in imports {
+                dependents.entry(import).or_default().push(package);
+            }
+        }
+
+        let mut ready: BTreeSet<&str> = waiting
+            .iter()
+            .filter(|(_, count)| **count == 0)
+            .map(|(package, _)| *package)
+            .collect();
+        let mut order = Vec::with_capacity(dependencies.len());
+        while let Some(package) = ready.pop_first() {
+            order.push(package);
+            for &dependent in dependents.get(package).into_iter().flatten() {
+                let count = waiting.get_mut(dependent)?;
+                *count -= 1;
+                if *count == 0 {
+                    ready.insert(dependent);
+                }
+            }
+        }
+        (order.len() == dependencies.len()).then_some(order)
+    }
+
+    /// Imports into the module that match no indexed package
+    pub fn unresolved(&self) -> impl Iterator<Item = &PackageImport> {
+        self.imports.iter().filter(|import| import.to.is_none())
+    }
+}
+
+/// Import paths of the packages of a set of files and of the packages
+/// their imports refer to
+///
+/// Two packages may share a name, never an import path, so types keyed as
+/// `import/path.Name` don't collide the way [`super::type_key`] keys do.
+#[derive(Debug, Clone, Default)]
+pub struct PackageKeys {
+    /// Import path of the package of each file, keyed by display path
+    files: BTreeMap<String, String>,
+    /// Package each import resolves to, by importing file and import path
+    imports: HashMap<(String, String), String>,
+}
+
+impl PackageKeys {
+    pub fn build(files: &[GoSourceFile]) -> Self {
+        let graph = PackageGraph::build(files);
+        let imports = graph
+            .imports
+            .into_iter()
+            .filter_map(|import| Some(((import.file, import.import_path), import.to?)))
+            .collect();
+        Self {
+            files: graph.files,
+            imports,
+        }
+    }
+
+    /// Import path of the package of `file`; its package name for external
+    /// `_test` packages, which the graph leaves out
+    pub fn package_of(&self, file: &GoSourceFile) -> String {
+        self.files
+            .get(&file.display_path())
+            .cloned()
+            .unwrap_or_else(|| file.package_name().unwrap_or_default().to_string())
+    }
+
+    /// Import path of the package `import_path`, imported by `file`, refers
+    /// to: the indexed package it resolves to, or the path as written
+    pub fn resolve_import(&self, file: &GoSourceFile, import_path: &str) -> String {
+        self.imports
+            .get(&(file.display_path(), import_path.to_string()))
+            .cloned()
+            .unwrap_or_else(|| import_path.to_string())
+    }
+
+    /// `import/path.Name` key of a named type; `error` stays unqualified
+    ///
+    /// Standard library types key as their import path does: `io.Reader`,
+    /// `encoding/json.Marshaler`.
+    pub fn type_key(
+        &self,
+        file: &GoSourceFile,
+        node: Node,
+        aliases: &HashMap<String, String>,
+    ) -> Option<String> {
+        match node.kind() {
+            "type_identifier" if file.text(node) == "error" => Some("error".to_string()),
+            "type_identifier" => Some(format!("{}.{}", self.package_of(file), file.text(node))),
+            "qualified_type" => {
+                let path = aliases.get(file.text(node.child_by_field_name("package")?))?;
+                let name = file.text(node.child_by_field_name("name")?);
+                Some(format!("{}.{name}", self.resolve_import(file, path)))
+            }
+            _ => None,
+        }
+    }
+}
+
+/// Directory of the package a file belongs to
+fn package_directory(file: &GoSourceFile) -> PathBuf {
+    normalize(file.path.parent().unwrap_or(Path::new("")))
+}
+
+/// Lexically resolve `.` and `..` components
+fn normalize(path: &Path) -> PathBuf {
+    let mut normalized = PathBuf::new();
+    for component in path.components() {
+        match component {
+            Component::CurDir => {}
+            Component::ParentDir => {
+                normalized.pop();
+            }
+            other => normalized.push(other),
+        }
+    }
+    normalized
+}
+
+/// A Go module declared by a `go.mod` file
+#[derive(Debug, Clone)]
+struct GoModule {
+    root: PathBuf,
+    path: String,
+    /// `replace` directives pointing at local directories
+    replacements: Vec<(String, PathBuf)>,
+}
+
+impl GoModule {
+    /// Read the `go.mod` file of the module rooted at `root`
+    fn read(root: &Path) -> Option<Self> {
+        let content = std::fs::read_to_string(root.join("go.mod")).ok()?;
+        let mut path = None;
+        let mut replacements = Vec::new();
+        for line in content.lines().map(str::trim) {
+            if let Some(module) = line.strip_prefix("module ") {
+                path = Some(module.trim().trim_matches('"').to_string());
+            } else if let Some((from, to)) = line
+                .strip_prefix("replace ")
+                .and_then(|directive| directive.split_once("=>"))
+            {
+                let to = to.trim();
+                if to.starts_with("./") || to.starts_with("../") {
+                    let from = from.split_whitespace().next().unwrap_or_default();
+                    replacements.push((from.to_string(), normalize(&root.join(to))));
+                }
+            }
+        }
+        Some(Self {
+            root: root.to_path_buf(),
+            path: path?,
+            replacements,
+        })
+    }
+
+    /// Local directory an import path refers to, when it is in this module
+    /// or replaced by a local directory
+    fn directory_of(&self, import_path: &str) -> Option<PathBuf> {
+        let relative = |prefix: &str| {
+            import_path
+                .strip_prefix(prefix)
+                .filter(|rest| rest.is_empty() || rest.starts_with('/'))
+                .map(|rest| rest.trim_start_matches('/'))
+        };
+        if let Some((rest, directory)) = self
+            .replacements
+            .iter()
+            .find_map(|(from, directory)| relative(from).map(|rest| (rest, directory)))
+        {
+            return Some(normalize(&directory.join(rest)));
+        }
+        relative(&self.path).map(|rest| normalize(&self.root.join(rest)))
+    }
+}
+
+/// Modules of package directories, each `go.mod` read once
+#[derive(Default)]
+struct ModuleCache {
+    modules: HashMap<PathBuf, Option<GoModule>>,
+}
+
+impl ModuleCache {
+    /// Module of the nearest `go.mod` at or above `directory`
+    fn module_of(&mut self, directory: &Path) -> Option<GoModule> {
+        for ancestor in directory.ancestors() {
+            let module = self
+                .modules
+                .entry(ancestor.to_path_buf())
+                .or_insert_with(|| GoModule::read(ancestor));
+            if module.is_some() {
+                return module.clone();
+            }
+        }
+        None
+    }
+
+    /// Import path of the package in `directory`, or the directory itself
+    /// outside any module
+    fn import_path(&mut self, directory: &Path) -> String {
+        let slashed = |path: &Path| {
+            path.components()
+                .map(|c| c.as_os_str().to_string_lossy())
+                .collect::<Vec<_>>()
+                .join("/")
+        };
+        match self.module_of(directory) {
+            Some(module) => match directory.strip_prefix(&module.root) {
+                Ok(relative) if relative.as_os_str().is_empty() => module.path,
+                Ok(relative) => format!("{}/{}", module.path, slashed(relative)),
+                Err(_) => module.path,
+            },
+            None => slashed(directory),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_module_package_graph() {
+        let fixture = Path::new("tests/fixtures/go/module_project");
+        let files: Vec<_> = ["main.go", "models/store.go", "services/user_store.go"]
+            .iter()
+            .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+            .collect();
+        let graph = PackageGraph::build(&files);
+
+        assert!(graph.packages.contains("example.com/myproject"));
+        let dependencies = graph.dependencies();
+        assert_eq!(
+            dependencies["example.com/myproject/services"],
+            BTreeSet::from(["example.com/myproject/models"])
+        );
+        // main imports packages of the module that aren't indexed here
+        assert!(
+            graph
+                .unresolved()
+                .all(|import| import.from == "example.com/myproject")
+        );
+        assert!(graph.unresolved().next().is_some());
+    }
+
+    #[test]
+    fn test_topological_order() {
+        let fixture = Path::new("examples/go/app");
+        let files: Vec<_> = [
+            "main.go",
+            "config/settings.go",
+            "models/user.go",
+            "services/auth.go",
+            "services/database.go",
+            "utils/helper.go",
+        ]
+        .iter()
+        .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+        .collect();
+        let graph = PackageGraph::build(&files);
+
+        // Dependencies first, main last
+        assert_eq!(
+            graph.topological_order().unwrap(),
+            vec![
+                "app/config",
+                "app/models",
+                "app/services",
+                "app/utils",
+                "app"
+            ]
+        );
+
+        let cyclic = PackageGraph {
+            packages: BTreeSet::from(["a".to_string(), "b".to_string(), "c".to_string()]),
+            imports: [("a", "b"), ("b", "a")]
+                .iter()
+                .map(|(from, to)| PackageImport {
+                    from: from.to_string(),
+                    import_path: to.to_string(),
+                    to: Some(to.to_string()),
+                    file: format!("{from}/{from}.go"),
+                    line: 3,
+                    side_effect: false,
+                })
+                .collect(),
+            files: BTreeMap::new(),
+        };
+        assert_eq!(cyclic.topological_order(), None);
+    }
+}
@@ -0,0 +1,198 @@
+//! Printf-style calls whose verbs and arguments don't add up (advisory)
+//!
+//! `go vet` catches these, but only when it runs:
+//!
+//! ```go
+//! fmt.Printf("user %s has %d roles\n", user.Name) // 2 verbs, 1 argument
+//! return fmt.Errorf("load %s", id, err)           // 1 verb, 2 arguments
+//! ```
+//!
+//! Calls to `fmt.Printf`, `Sprintf`, `Errorf`, `Fprintf` and `Appendf` are
+//! checked when the format is a string literal. `%%` is no verb, and a `*`
+//! width or precision takes an argument of its own. Formats with explicit
+//! argument indexes (`%[1]s`) and calls spreading a slice (`args...`) can't
+//! be counted this way and are skipped. A `// codanna:ignore printf-args`
+//! comment on the call line or the line above suppresses the finding.
+
+use super::{
+    GoSourceFile, enclosing_function_name, is_suppressed, line_of, selector_call_parts,
+    string_literal_value, walk_tree,
+};
+use serde::Serialize;
+use std::fmt;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "printf-args";
+
+/// `fmt` functions taking a format, with the index of the format argument
+const PRINTF_FUNCTIONS: &[(&str, usize)] = &[
+    ("Printf", 0),
+    ("Sprintf", 0),
+    ("Errorf", 0),
+    ("Fprintf", 1),
+    ("Appendf", 1),
+];
+
+/// A Printf-style call with more or fewer arguments than its format uses
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PrintfMismatch {
+    /// Called function as written, e.g. `fmt.Errorf`
+    pub call: String,
+    /// Format literal as written, with quotes
+    pub format: String,
+    /// Arguments the format consumes
+    pub verbs: usize,
+    /// Arguments passed after the format
+    pub arguments: usize,
+    /// Enclosing function, if any
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the call
+    pub line: u32,
+}
+
+impl fmt::Display for PrintfMismatch {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}({}) has {} {} but {} {}",
+            self.call,
+            self.format,
+            self.verbs,
+            if self.verbs == 1 { "verb" } else { "verbs" },
+            self.arguments,
+            if self.arguments == 1 {
+                "argument"
+            } else {
+                "arguments"
+            },
+        )?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find Printf-style calls whose argument count doesn't match their format,
+/// sorted by file and line
+pub fn find_printf_mismatches(files: &[GoSourceFile]) -> Vec<PrintfMismatch> {
+    let mut findings = Vec::new();
+    for file in files {
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            let Some((package, function)) = selector_call_parts(file, node) else {
+                return;
+            };
+            if aliases.get(package).map(String::as_str) != Some("fmt") {
+                return;
+            }
+            let Some(&(_, format_index)) = PRINTF_FUNCTIONS.iter().find(|(f, _)| *f == function)
+            else {
+                return;
+            };
+            let Some(arguments) = node.child_by_field_name("arguments") else {
+                return;
+            };
+            let values: Vec<_> = arguments.named_children(&mut arguments.walk()).collect();
+            let spread = values.iter().any(|v| v.kind() == "variadic_argument");
+            let Some(format) = values.get(format_index) else {
+                return;
+            };
+            let Some(verbs) = string_literal_value(file, *format).and_then(|f| count_verbs(&f))
+            else {
+                return;
+            };
+            let passed = values.len() - format_index - 1;
+            let line = line_of(node);
+            if spread || verbs == passed || is_suppressed(file, line, ANALYSIS_NAME) {
+                return;
+            }
+            findings.push(PrintfMismatch {
+                call: format!("{package}.{function}"),
+                format: file.text(*format).to_string(),
+                verbs,
+                arguments: passed,
+                function: enclosing_function_name(file, node),
+                file: file.display_path(),
+                line,
+            });
+        });
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Arguments consumed by a format string; `None` with explicit indexes
+fn count_verbs(format: &str) -> Option<usize> {
+    let mut count = 0;
+    let mut chars = format.chars().peekable();
+    while let Some(c) = chars.next() {
+        if c != '%' {
+            continue;
+        }
+        if chars.peek() == Some(&'%') {
+            chars.next();
+            continue;
+        }
+        // Flags, width and precision; `*` takes an argument
+        while let Some(&next) = chars.peek() {
+            match next {
+                '+' | '-' | '#' | ' ' | '0'..='9' | '.' => {}
+                '*' => count += 1,
+                '[' => return None,
+                _ => break,
+            }
+            chars.next();
+        }
+        // The verb itself; a trailing `%` is reported by fmt, not counted
+        if chars.next().is_some() {
+            count += 1;
+        }
+    }
+    Some(count)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_printf_argument_mismatches() {
+        let code = r#"
+package users
+
+import (
+    "fmt"
+    "os"
+)
+
+func Describe(u *User, err error) error {
+    fmt.Printf("user %s has %d roles\n", u.Name)
+    fmt.Printf("%-10s|%6.2f%%\n", u.Name, u.Score)
+    fmt.Fprintf(os.Stderr, "%*d\n", 8, u.ID)
+    fmt.Println("done %s")
+    msg := fmt.Sprintf("%[1]s %[1]q", u.Name)
+    fmt.Printf("%s %s\n", u.Names()...)
+    // codanna:ignore printf-args
+    fmt.Printf("%s\n")
+    return fmt.Errorf("load %s", u.ID, err)
+}
+"#;
+        let file = GoSourceFile::parse("users/describe.go", code.to_string()).unwrap();
+        let findings = find_printf_mismatches(&[file]);
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.call.as_str(), f.verbs, f.arguments, f.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![("fmt.Printf", 2, 1, 10), ("fmt.Errorf", 1, 2, 18)]
+        );
+        assert_eq!(
+            findings[1].to_string(),
+            "fmt.Errorf(\"load %s\") has 1 verb but 2 arguments in Describe at users/describe.go:18"
+        );
+    }
+}
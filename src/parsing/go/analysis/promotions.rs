@@ -0,0 +1,319 @@
+//! Fields and methods promoted from two embedded types at the same depth
+//!
+//! A struct gets the fields and methods of the types it embeds, but only
+//! when exactly one of them provides a name at the shallowest depth it is
+//! found:
+//!
+//! ```go
+//! func (f *File) Close() error { ... }
+//! func (c *Conn) Close() error { ... }
+//!
+//! type Session struct {
+//!     *File
+//!     Conn
+//! }
+//!
+//! s.Close() // ambiguous selector s.Close
+//! ```
+//!
+//! `Session` has no `Close` method at all, and the index must not pick one
+//! of the two; the same goes for a field both embedded types declare. A
+//! method or field declared on the struct itself, or found at a shallower
+//! depth, settles the name. Embedded types are matched within the package
+//! and into other indexed packages by their `package.Name` key.
+
+use super::embeds::embedded_types;
+use super::{GoSourceFile, receiver_type_name, walk_tree};
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+
+/// A field or method name promoted by more than one embedded type at the
+/// same depth
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct AmbiguousSelector {
+    /// Embedding struct
+    pub type_name: String,
+    pub package: String,
+    /// Field or method name
+    pub member: String,
+    /// `method`, `field`, or `member` when a field and a method conflict
+    pub kind: &'static str,
+    /// Embedding depth at which the conflict occurs, 1 for direct embeds
+    pub depth: u32,
+    /// Embedded types providing the method, as `package.Name`, sorted
+    pub providers: Vec<String>,
+    pub file: String,
+    /// 1-based line of the struct's name
+    pub line: u32,
+    /// 1-based column of the struct's name
+    pub column: u32,
+}
+
+impl fmt::Display for AmbiguousSelector {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} is ambiguous: promoted from {} at depth {} ({}:{})",
+            self.type_name,
+            self.member,
+            self.providers.join(" and "),
+            self.depth,
+            self.file,
+            self.line
+        )
+    }
+}
+
+/// Fields and methods of a declared type, and what it embeds
+#[derive(Default)]
+struct TypeMembers {
+    /// Named and embedded fields
+    fields: HashSet<String>,
+    /// Declared methods, or the methods of an interface
+    methods: HashSet<String>,
+    /// Embedded types as `package.Name`
+    embeds: Vec<String>,
+}
+
+/// Find the ambiguous promoted fields and methods of every struct in
+/// `files`, sorted by file, line and member
+pub fn find_ambiguous_selectors(files: &[GoSourceFile]) -> Vec<AmbiguousSelector> {
+    let mut types: HashMap<String, TypeMembers> = HashMap::new();
+    // (key, name, package, file, line, column) of each struct
+    let mut structs = Vec::new();
+    for file in files {
+        let Some(package) = file.package_name() else {
+            continue;
+        };
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| match node.kind() {
+            "type_spec" => {
+                let (Some(name), Some(body)) = (
+                    node.child_by_field_name("name"),
+                    node.child_by_field_name("type"),
+                ) else {
+                    return;
+                };
+                let key = format!("{package}.{}", file.text(name));
+                let members = types.entry(key.clone()).or_default();
+                for (embedded, _) in embedded_types(file, body, &aliases) {
+                    if body.kind() == "struct_type" {
+                        // An embedded field is named after its type
+                        members.fields.insert(embedded_field_name(&embedded));
+                    }
+                    members.embeds.push(embedded);
+                }
+                // Direct members only: a nested anonymous struct's are its own
+                let elements = body
+                    .named_children(&mut body.walk())
+                    .flat_map(|child| match child.kind() {
+                        "field_declaration_list" => {
+                            child.named_children(&mut child.walk()).collect::<Vec<_>>()
+                        }
+                        _ => vec![child],
+                    })
+                    .collect::<Vec<_>>();
+                for member in elements {
+                    match member.kind() {
+                        "field_declaration" => {
+                            for field in member.children_by_field_name("name", &mut member.walk()) {
+                                members.fields.insert(file.text(field).to_string());
+                            }
+                        }
+                        "method_elem" => {
+                            if let Some(method) = member.child_by_field_name("name") {
+                                members.methods.insert(file.text(method).to_string());
+                            }
+                        }
+                        _ => {}
+                    }
+                }
+                if body.kind() == "struct_type" {
+                    let position = name.start_position();
+                    structs.push((
+                        key,
+                        file.text(name).to_string(),
+                        package.to_string(),
+                        file.display_path(),
+                        position.row as u32 + 1,
+                        position.column as u32 + 1,
+                    ));
+                }
+            }
+            "method_declaration" => {
+                let (Some(receiver), Some(method)) = (
+                    receiver_type_name(file, node),
+                    node.child_by_field_name("name"),
+                ) else {
+                    return;
+                };
+                types
+                    .entry(format!("{package}.{receiver}"))
+                    .or_default()
+                    .methods
+                    .insert(file.text(method).to_string());
+            }
+            _ => {}
+        });
+    }
+
+    let mut selectors = Vec::new();
+    for (key, type_name, package, file, line, column) in structs {
+        let Some(outer) = types.get(&key) else {
+            continue;
+        };
+        // Names settled at a shallower depth, including the struct's own
+        let mut settled: HashSet<&str> = outer
+            .fields
+            .iter()
+            .chain(&outer.methods)
+            .map(String::as_str)
+            .collect();
+        let mut visited: HashSet<&str> = HashSet::from([key.as_str()]);
+        let mut level: Vec<&str> = outer.embeds.iter().map(String::as_str).collect();
+        let mut depth = 1;
+        while !level.is_empty() {
+            // Name -> (providers, how many of them declare a method)
+            let mut candidates: HashMap<&str, (Vec<&str>, usize)> = HashMap::new();
+            let mut next = Vec::new();
+            for inner in level {
+                if !visited.insert(inner) {
+                    continue;
+                }
+                let Some(members) = types.get(inner) else {
+                    continue;
+                };
+                let names = members
+                    .fields
+                    .iter()
+                    .map(|name| (name, false))
+                    .chain(members.methods.iter().map(|name| (name, true)));
+                for (name, is_method) in names {
+                    if settled.contains(name.as_str()) {
+                        continue;
+                    }
+                    let entry = candidates.entry(name.as_str()).or_default();
+                    entry.0.push(inner);
+                    entry.1 += usize::from(is_method);
+                }
+                next.extend(members.embeds.iter().map(String::as_str));
+            }
+            let mut conflicts = Vec::new();
+            for (name, (providers, methods)) in candidates {
+                settled.insert(name);
+                if providers.len() > 1 {
+                    let kind = match methods {
+                        0 => "field",
+                        n if n == providers.len() => "method",
+                        _ => "member",
+                    };
+                    conflicts.push((name, kind, providers));
+                }
+            }
+            conflicts.sort();
+            for (member, kind, mut providers) in conflicts {
+                providers.sort();
+                selectors.push(AmbiguousSelector {
+                    type_name: type_name.clone(),
+                    package: package.clone(),
+                    member: member.to_string(),
+                    kind,
+                    depth,
+                    providers: providers.into_iter().map(str::to_string).collect(),
+                    file: file.clone(),
+                    line,
+                    column,
+                });
+            }
+            level = next;
+            depth += 1;
+        }
+    }
+    selectors.sort_by(|a, b| (&a.file, a.line, &a.member).cmp(&(&b.file, b.line, &b.member)));
+    selectors
+}
+
+/// Name of the field an embedded `package.Name` declares: `Name`
+fn embedded_field_name(key: &str) -> String {
+    key.rsplit('.').next().unwrap_or(key).to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_method_promoted_twice_is_ambiguous() {
+        let file = GoSourceFile::read(std::path::Path::new(
+            "tests/fixtures/go/promotion_conflicts.go",
+        ))
+        .unwrap();
+        let selectors = find_ambiguous_selectors(&[file]);
+
+        // Only Session: ManagedSession declares Close and Pool's Close comes
+        // from Conn at depth 1
+        assert_eq!(selectors.len(), 1, "{selectors:?}");
+        let close = &selectors[0];
+        assert_eq!(close.type_name, "Session");
+        assert_eq!(close.member, "Close");
+        assert_eq!(close.kind, "method");
+        assert_eq!(close.depth, 1);
+        assert_eq!(
+            close.providers,
+            vec!["promotion.Conn".to_string(), "promotion.File".to_string()]
+        );
+        assert_eq!((close.line, close.column), (29, 6));
+        assert!(close.to_string().starts_with(
+            "Session.Close is ambiguous: promoted from promotion.Conn and promotion.File"
+        ));
+    }
+
+    #[test]
+    fn test_field_promoted_twice_is_ambiguous() {
+        let code = r#"package net
+
+type Local struct {
+	Addr string
+	Port int
+}
+
+type Remote struct {
+	Addr string
+}
+
+func (r Remote) Port() int { return 0 }
+
+type Link struct {
+	Local
+	Remote
+}
+
+type NamedLink struct {
+	Local
+	Remote
+	Addr string
+}
+"#;
+        let file = GoSourceFile::parse("net/link.go", code.to_string()).unwrap();
+        let selectors = find_ambiguous_selectors(&[file]);
+
+        let found: Vec<_> = selectors
+            .iter()
+            .map(|s| (s.type_name.as_str(), s.member.as_str(), s.kind))
+            .collect();
+        // NamedLink's own Addr settles the field conflict, not the Port one
+        assert_eq!(
+            found,
+            vec![
+                ("Link", "Addr", "field"),
+                ("Link", "Port", "member"),
+                ("NamedLink", "Port", "member"),
+            ]
+        );
+        assert_eq!(
+            selectors[0].providers,
+            vec!["net.Local".to_string(), "net.Remote".to_string()]
+        );
+    }
+}
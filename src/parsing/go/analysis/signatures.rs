@@ -0,0 +1,567 @@
+//! Functions looked up by the types in their signatures
+//!
+//! Finds the functions and methods producing a type, such as the
+//! constructors and factories returning `*User`,
+//!
+//! ```go
+//! func NewUser(name, email string, role UserRole) *User
+//! func CreateValidatedUser(name, email string, role UserRole) (*User, error)
+//! ```
+//!
+//! and the functions and methods consuming it through a parameter.
+//!
+//! By default a [`TypeQuery`] matches the base type through pointer, slice,
+//! array, map and channel wrappers, so `User` matches `*User`, `[]User` and
+//! `map[string]*models.User`. An exact query matches the type as written,
+//! qualified or not: `*models.User` matches `*User` in package `models`.
+//!
+//! Parameters also accept a type through the interfaces it satisfies:
+//! `Save(s Storer)` accepts a `User` whose method set covers `Storer`.
+//! Method sets are checked with [`GoInheritanceResolver`], including the
+//! standard library interfaces it knows (`fmt.Stringer`, `io.Reader`, ...).
+
+use super::{
+    GoSourceFile, InterfaceTable, declaration_name, line_of, receiver_type_name, type_key,
+    walk_tree,
+};
+use crate::parsing::InheritanceResolver;
+use crate::parsing::go::GoInheritanceResolver;
+use crate::parsing::go::resolution::stdlib_interface_methods;
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// A type to look for in signatures
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct TypeQuery {
+    /// Query without whitespace, for exact matches
+    text: String,
+    /// Package qualifier of the base type, if given
+    package: Option<String>,
+    /// Base type name
+    name: String,
+    exact: bool,
+}
+
+impl TypeQuery {
+    /// Parse a type such as `User`, `*models.User` or `context.Context`
+    pub fn parse(query: &str, exact: bool) -> Self {
+        let text: String = query.split_whitespace().collect();
+        let base = strip_type_wrappers(query.trim());
+        let base = base.split('[').next().unwrap_or(base);
+        let (package, name) = match base.rsplit_once('.') {
+            Some((package, name)) => (Some(package.to_string()), name.to_string()),
+            None => (None, base.to_string()),
+        };
+        Self {
+            text,
+            package,
+            name,
+            exact,
+        }
+    }
+
+    /// Whether the type expression `node` in `file` matches
+    pub fn matches(
+        &self,
+        file: &GoSourceFile,
+        node: Node,
+        aliases: &HashMap<String, String>,
+    ) -> bool {
+        if self.exact {
+            return self.matches_exactly(file, node, aliases);
+        }
+        let Some(base) = base_type(node) else {
+            return false;
+        };
+        match base.kind() {
+            "type_identifier" => {
+                file.text(base) == self.name
+                    && self
+                        .package
+                        .as_deref()
+                        .is_none_or(|package| file.package_name() == Some(package))
+            }
+            "qualified_type" => {
+                let (Some(package), Some(name)) = (
+                    base.child_by_field_name("package"),
+                    base.child_by_field_name("name"),
+                ) else {
+                    return false;
+                };
+                let local = file.text(package);
+                file.text(name) == self.name
+                    && self.package.as_deref().is_none_or(|query| {
+                        // The alias as written, or the imported package's name
+                        local == query
+                            || aliases.get(local).and_then(|path| path.rsplit('/').next())
+                                == Some(query)
+                    })
+            }
+            _ => false,
+        }
+    }
+
+    /// Match the type as written, or with its base type qualified by package
+    /// name: `*User` in package `models` and `*m.User` importing `models` as
+    /// `m` both match `*models.User`
+    fn matches_exactly(
+        &self,
+        file: &GoSourceFile,
+        node: Node,
+        aliases: &HashMap<String, String>,
+    ) -> bool {
+        let written: String = file.text(node).split_whitespace().collect();
+        if written == self.text {
+            return true;
+        }
+        let Some(base) = base_type(node) else {
+            return false;
+        };
+        let qualified_base = match base.kind() {
+            "type_identifier" => file
+                .package_name()
+                .map(|package| format!("{package}.{}", file.text(base))),
+            "qualified_type" => base.child_by_field_name("package").and_then(|package| {
+                let path = aliases.get(file.text(package))?;
+                let name = base.child_by_field_name("name")?;
+                Some(format!(
+                    "{}.{}",
+                    path.rsplit('/').next().unwrap_or(path),
+                    file.text(name)
+                ))
+            }),
+            _ => None,
+        };
+        let Some(qualified_base) = qualified_base else {
+            return false;
+        };
+        let text = file.text(node);
+        let start = base.start_byte() - node.start_byte();
+        let end = base.end_byte() - node.start_byte();
+        let qualified: String = format!("{}{qualified_base}{}", &text[..start], &text[end..])
+            .split_whitespace()
+            .collect();
+        qualified == self.text
+    }
+}
+
+/// Drop the leading `*`, `[]`, `[N]`, `map[K]` and `chan` of a type
+fn strip_type_wrappers(mut text: &str) -> &str {
+    loop {
+        let before = text;
+        text = text.trim_start_matches(['*', ' ']);
+        if let Some(rest) = text.strip_prefix("<-") {
+            text = rest.trim_start();
+        }
+        if let Some(rest) = text.strip_prefix("chan") {
+            if rest.starts_with([' ', '<']) {
+                text = rest.trim_start_matches(['<', '-', ' ']);
+            }
+        }
+        let bracketed = text.strip_prefix("map").unwrap_or(text);
+        if bracketed.starts_with('[') {
+            let mut depth = 0;
+            for (i, c) in bracketed.char_indices() {
+                match c {
+                    '[' => depth += 1,
+                    ']' => depth -= 1,
+                    _ => {}
+                }
+                if depth == 0 {
+                    text = &bracketed[i + 1..];
+                    break;
+                }
+            }
+        }
+        if text == before {
+            return text;
+        }
+    }
+}
+
+/// Base type of a type expression, through pointers, slices, arrays, maps
+/// (the value type), channels and type arguments
+fn base_type(node: Node) -> Option<Node> {
+    match node.kind() {
+        "type_identifier" | "qualified_type" => Some(node),
+        "pointer_type" | "parenthesized_type" => base_type(node.named_child(0)?),
+        "slice_type" | "array_type" | "implicit_length_array_type" => {
+            base_type(node.child_by_field_name("element")?)
+        }
+        "map_type" | "channel_type" => base_type(node.child_by_field_name("value")?),
+        "generic_type" => base_type(node.child_by_field_name("type")?),
+        _ => None,
+    }
+}
+
+/// A function or method whose signature has a matching type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SignatureMatch {
+    /// Function name; methods are qualified by receiver type
+    pub name: String,
+    pub package: String,
+    /// Declaration up to the body
+    pub signature: String,
+    /// Matching type, as written
+    pub matched: String,
+    /// Interface through which a parameter accepts the type
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub interface: Option<String>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl fmt::Display for SignatureMatch {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}.{} at {}:{}",
+            self.package, self.name, self.file, self.line
+        )?;
+        if let Some(interface) = &self.interface {
+            write!(f, " (via {interface})")?;
+        }
+        write!(f, "\n  {}", self.signature)
+    }
+}
+
+/// Functions and methods with `query` among their results, sorted by package
+/// and name
+pub fn find_functions_returning(files: &[GoSourceFile], query: &TypeQuery) -> Vec<SignatureMatch> {
+    find_functions(files, result_types, &|file, node, aliases| {
+        query.matches(file, node, aliases).then_some(None)
+    })
+}
+
+/// Functions and methods with a parameter accepting `query`, sorted by
+/// package and name
+///
+/// Unless the query is exact, interface parameters satisfied by the type
+/// match too, well-known standard library ones with `stdlib` on.
+pub fn find_functions_accepting(
+    files: &[GoSourceFile],
+    query: &TypeQuery,
+    stdlib: StdlibResolution,
+) -> Vec<SignatureMatch> {
+    let interfaces = if query.exact {
+        InterfaceMatcher::default()
+    } else {
+        InterfaceMatcher::build(files, query, stdlib)
+    };
+    find_functions(files, parameter_types, &|file, node, aliases| {
+        if query.matches(file, node, aliases) {
+            return Some(None);
+        }
+        interfaces.satisfied(file, node, aliases).map(Some)
+    })
+}
+
+/// Types of the parameters of a declaration, in order; `...T` gives `T`
+fn parameter_types(declaration: Node) -> Vec<Node> {
+    let Some(params) = declaration.child_by_field_name("parameters") else {
+        return Vec::new();
+    };
+    params
+        .named_children(&mut params.walk())
+        .filter_map(|param| param.child_by_field_name("type"))
+        .collect()
+}
+
+/// Interfaces satisfied by the types a query names
+#[derive(Default)]
+struct InterfaceMatcher {
+    resolver: GoInheritanceResolver,
+    /// Queried types as `package.Type`
+    types: Vec<String>,
+    /// Interfaces declared in the analyzed files, as `package.Interface`
+    interfaces: HashSet<String>,
+}
+
+impl InterfaceMatcher {
+    fn build(files: &[GoSourceFile], query: &TypeQuery, stdlib: StdlibResolution) -> Self {
+        let mut matcher = Self::default();
+        matcher.resolver.set_stdlib_resolution(stdlib);
+        let mut methods: HashMap<String, Vec<String>> = HashMap::new();
+        for file in files {
+            let package = file.package_name().unwrap_or_default();
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() == "method_declaration" {
+                    let (Some(receiver), Some(name)) = (
+                        receiver_type_name(file, node),
+                        node.child_by_field_name("name"),
+                    ) else {
+                        return;
+                    };
+                    methods
+                        .entry(format!("{package}.{receiver}"))
+                        .or_default()
+                        .push(file.text(name).to_string());
+                    let is_queried = receiver == query.name
+                        && query.package.as_deref().is_none_or(|p| p == package);
+                    let key = format!("{package}.{receiver}");
+                    if is_queried && !matcher.types.contains(&key) {
+                        matcher.types.push(key);
+                    }
+                }
+            });
+        }
+        for (key, decl) in InterfaceTable::build(files, stdlib).declared() {
+            methods
+                .entry(key.to_string())
+                .or_default()
+                .extend(decl.methods.iter().map(|m| m.name.to_string()));
+            if !decl.embeds.is_empty() {
+                let embedded = decl.embeds.iter().map(|(key, _)| key.clone()).collect();
+                matcher
+                    .resolver
+                    .add_interface_embeds(key.to_string(), embedded);
+            }
+            matcher.interfaces.insert(key.to_string());
+        }
+        for (type_key, type_methods) in methods {
+            matcher.resolver.add_type_methods(type_key, type_methods);
+        }
+        matcher
+    }
+
+    /// The interface a parameter type names, if a queried type satisfies it
+    fn satisfied(
+        &self,
+        file: &GoSourceFile,
+        node: Node,
+        aliases: &HashMap<String, String>,
+    ) -> Option<String> {
+        let key = type_key(file, base_type(node)?, aliases)?;
+        let is_interface = self.interfaces.contains(&key)
+            || stdlib_interface_methods(&key, self.resolver.stdlib_resolution()).is_some();
+        (is_interface
+            && self
+                .types
+                .iter()
+                .any(|t| *t != key && self.resolver.check_struct_implements_interface(t, &key)))
+        .then_some(key)
+    }
+}
+
+/// Types of the results of a declaration, in order
+fn result_types(declaration: Node) -> Vec<Node> {
+    let Some(result) = declaration.child_by_field_name("result") else {
+        return Vec::new();
+    };
+    if result.kind() != "parameter_list" {
+        return vec![result];
+    }
+    result
+        .named_children(&mut result.walk())
+        .filter_map(|param| param.child_by_field_name("type"))
+        .collect()
+}
+
+/// Functions with a signature type accepted by `matches`, which returns the
+/// interface the match went through, if any
+fn find_functions(
+    files: &[GoSourceFile],
+    types_of: fn(Node) -> Vec<Node>,
+    matches: &dyn Fn(&GoSourceFile, Node, &HashMap<String, String>) -> Option<Option<String>>,
+) -> Vec<SignatureMatch> {
+    let mut matches = Vec::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            if !matches!(node.kind(), "function_declaration" | "method_declaration") {
+                return;
+            }
+            let Some((matched, interface)) = types_of(node)
+                .into_iter()
+                .find_map(|t| Some((t, matches(file, t, &aliases)?)))
+            else {
+                return;
+            };
+            let Some(name) = declaration_name(file, node) else {
+                return;
+            };
+            matches.push(SignatureMatch {
+                name,
+                package: package.to_string(),
+                signature: signature_text(file, node),
+                matched: file.text(matched).to_string(),
+                interface,
+                file: file.display_path(),
+                line: line_of(node),
+            });
+        });
+    }
+    matches.sort_by(|a, b| (&a.package, &a.name, &a.file).cmp(&(&b.package, &b.name, &b.file)));
+    matches
+}
+
+/// Declaration text up to the body
+fn signature_text(file: &GoSourceFile, declaration: Node) -> String {
+    let end = declaration
+        .child_by_field_name("body")
+        .map_or(declaration.end_byte(), |body| body.start_byte());
+    file.source[declaration.start_byte()..end]
+        .trim()
+        .to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_functions_returning_a_type() {
+        let models = r#"
+package models
+
+type User struct{}
+
+func NewUser(name string) *User { return &User{} }
+
+func CreateValidatedUser(name string) (*User, error) { return nil, nil }
+
+func AllUsers() []User { return nil }
+
+func (u *User) Name() string { return "" }
+"#;
+        let handlers = r#"
+package handlers
+
+import m "example.com/app/models"
+
+func LoadUser(id int) (*m.User, error) { return nil, nil }
+"#;
+        let files = vec![
+            GoSourceFile::parse("handlers/user.go", handlers.to_string()).unwrap(),
+            GoSourceFile::parse("models/user.go", models.to_string()).unwrap(),
+        ];
+        let names = |query: &str, exact: bool| {
+            find_functions_returning(&files, &TypeQuery::parse(query, exact))
+                .into_iter()
+                .map(|m| format!("{}.{}", m.package, m.name))
+                .collect::<Vec<_>>()
+        };
+
+        assert_eq!(
+            names("User", false),
+            vec![
+                "handlers.LoadUser",
+                "models.AllUsers",
+                "models.CreateValidatedUser",
+                "models.NewUser"
+            ]
+        );
+        // Exact matches keep the pointer and accept the qualified form
+        assert_eq!(
+            names("*models.User", true),
+            vec![
+                "handlers.LoadUser",
+                "models.CreateValidatedUser",
+                "models.NewUser"
+            ]
+        );
+        assert_eq!(names("[]User", true), vec!["models.AllUsers"]);
+        assert!(names("*m.User", true).contains(&"handlers.LoadUser".to_string()));
+
+        let matches = find_functions_returning(&files, &TypeQuery::parse("*User", false));
+        let validated = matches
+            .iter()
+            .find(|m| m.name == "CreateValidatedUser")
+            .unwrap();
+        assert_eq!(validated.matched, "*User");
+        assert_eq!(
+            validated.signature,
+            "func CreateValidatedUser(name string) (*User, error)"
+        );
+    }
+
+    #[test]
+    fn test_functions_accepting_a_type() {
+        let models = r#"
+package models
+
+type User struct{}
+
+func (u *User) String() string { return "" }
+func (u *User) Save() error { return nil }
+
+type Storer interface {
+    Save() error
+}
+
+type Deleter interface {
+    Delete() error
+}
+
+func Persist(s Storer) error { return s.Save() }
+
+func Purge(d Deleter) error { return d.Delete() }
+
+func (u *User) Merge(other *User) {}
+"#;
+        let handlers = r#"
+package handlers
+
+import (
+    "fmt"
+
+    "example.com/app/models"
+)
+
+func Render(users ...*models.User) {}
+
+func Print(s fmt.Stringer) { fmt.Println(s) }
+
+func Batch(users []models.User) {}
+"#;
+        let files = vec![
+            GoSourceFile::parse("handlers/user.go", handlers.to_string()).unwrap(),
+            GoSourceFile::parse("models/user.go", models.to_string()).unwrap(),
+        ];
+        let accepting = |query: &str, exact: bool| {
+            find_functions_accepting(
+                &files,
+                &TypeQuery::parse(query, exact),
+                StdlibResolution::Builtin,
+            )
+            .into_iter()
+            .map(|m| (format!("{}.{}", m.package, m.name), m.interface))
+            .collect::<Vec<_>>()
+        };
+
+        // Variadic, slice and pointer forms, and interfaces User satisfies;
+        // the receiver of Merge is not a parameter, `other` is
+        assert_eq!(
+            accepting("User", false),
+            vec![
+                ("handlers.Batch".to_string(), None),
+                (
+                    "handlers.Print".to_string(),
+                    Some("fmt.Stringer".to_string())
+                ),
+                ("handlers.Render".to_string(), None),
+                (
+                    "models.Persist".to_string(),
+                    Some("models.Storer".to_string())
+                ),
+                ("models.User.Merge".to_string(), None),
+            ]
+        );
+        assert_eq!(
+            accepting("*models.User", true),
+            vec![
+                ("handlers.Render".to_string(), None),
+                ("models.User.Merge".to_string(), None),
+            ]
+        );
+        assert_eq!(
+            accepting("fmt.Stringer", false),
+            vec![("handlers.Print".to_string(), None)]
+        );
+    }
+}
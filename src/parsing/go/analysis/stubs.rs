@@ -0,0 +1,514 @@
+//! Method stubs completing an interface implementation
+//!
+//! Given a declared type and a declared interface, the methods the type is
+//! missing are rendered as Go source ready to paste next to the type:
+//!
+//! ```go
+//! // Load implements models.Store.
+//! func (s *UserStore) Load(id string) (*models.User, error) {
+//!     panic("not implemented")
+//! }
+//! ```
+//!
+//! The required method set includes the methods of embedded interfaces, as
+//! the conformance check in [`super::implements`] flattens them, and methods
+//! are matched by name. Receivers follow the type's existing methods, or the
+//! first letter of the type with a pointer for structs. Unnamed parameters
+//! are named after their type. Types local to the interface's package are
+//! qualified when the stub lands in another package.
+
+use super::{GoSourceFile, InterfaceTable, receiver_type_name, walk_tree};
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use tree_sitter::Node;
+
+/// Types and constraints every package sees unqualified
+const PREDECLARED_TYPES: &[&str] = &[
+    "any",
+    "bool",
+    "byte",
+    "comparable",
+    "complex64",
+    "complex128",
+    "error",
+    "float32",
+    "float64",
+    "int",
+    "int8",
+    "int16",
+    "int32",
+    "int64",
+    "rune",
+    "string",
+    "uint",
+    "uint8",
+    "uint16",
+    "uint32",
+    "uint64",
+    "uintptr",
+];
+
+/// Go keywords that can't name a parameter
+const KEYWORDS: &[&str] = &[
+    "break",
+    "case",
+    "chan",
+    "const",
+    "continue",
+    "default",
+    "defer",
+    "else",
+    "fallthrough",
+    "for",
+    "func",
+    "go",
+    "goto",
+    "if",
+    "import",
+    "interface",
+    "map",
+    "package",
+    "range",
+    "return",
+    "select",
+    "struct",
+    "switch",
+    "type",
+    "var",
+];
+
+/// A generated method missing from a type
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MethodStub {
+    pub method: String,
+    /// Interface declaring the method, qualified when it is embedded from
+    /// or declared in another package
+    pub interface: String,
+    /// Go source of the stub, doc comment included
+    pub source: String,
+}
+
+impl fmt::Display for MethodStub {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.source)
+    }
+}
+
+/// Why no stubs could be generated
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum StubError {
+    TypeNotFound(String),
+    InterfaceNotFound(String),
+    /// The named type is itself an interface
+    NotAConcreteType(String),
+}
+
+impl fmt::Display for StubError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            StubError::TypeNotFound(name) => write!(f, "type '{name}' not found"),
+            StubError::InterfaceNotFound(name) => write!(f, "interface '{name}' not found"),
+            StubError::NotAConcreteType(name) => {
+                write!(f, "'{name}' is an interface, not a concrete type")
+            }
+        }
+    }
+}
+
+/// Generate stubs for the methods `type_name` is missing to implement
+/// `interface`, in the interface's declaration order
+///
+/// Both names may be qualified by package (`services.UserStore`). An empty
+/// result means the type already has every method.
+pub fn generate_method_stubs(
+    files: &[GoSourceFile],
+    type_name: &str,
+    interface: &str,
+) -> Result<Vec<MethodStub>, StubError> {
+    let (target_file, target_spec) =
+        find_type_spec(files, type_name, |body| body.kind() != "interface_type").ok_or_else(
+            || {
+                if find_type_spec(files, type_name, |body| body.kind() == "interface_type")
+                    .is_some()
+                {
+                    StubError::NotAConcreteType(type_name.to_string())
+                } else {
+                    StubError::TypeNotFound(type_name.to_string())
+                }
+            },
+        )?;
+    let (interface_file, interface_spec) =
+        find_type_spec(files, interface, |body| body.kind() == "interface_type")
+            .ok_or_else(|| StubError::InterfaceNotFound(interface.to_string()))?;
+
+    let target_package = target_file.package_name().unwrap_or_default();
+    let target_name = target_file.text(target_spec.child_by_field_name("name").unwrap());
+
+    // Methods the type already has, and the receiver they use
+    let mut existing = HashSet::new();
+    let mut receiver = None;
+    for file in files
+        .iter()
+        .filter(|f| f.package_name().unwrap_or_default() == target_package)
+    {
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "method_declaration"
+                || receiver_type_name(file, node) != Some(target_name)
+            {
+                return;
+            }
+            if let Some(name) = node.child_by_field_name("name") {
+                existing.insert(file.text(name).to_string());
+            }
+            if receiver.is_none() {
+                receiver = node
+                    .child_by_field_name("receiver")
+                    .and_then(|r| {
+                        r.named_children(&mut r.walk())
+                            .find(|p| p.kind() == "parameter_declaration")
+                    })
+                    .map(|p| file.text(p).to_string());
+            }
+        });
+    }
+    let receiver = receiver.unwrap_or_else(|| default_receiver(target_file, target_spec));
+
+    // Only declared methods are stubbed, standard library ones aren't needed
+    let interfaces = InterfaceTable::build(files, StdlibResolution::Off);
+    let interface_key = format!(
+        "{}.{}",
+        interface_file.package_name().unwrap_or_default(),
+        interface_file.text(interface_spec.child_by_field_name("name").unwrap())
+    );
+
+    let receiver_name = receiver.split_whitespace().next().unwrap_or_default();
+    let mut stubs = Vec::new();
+    for (declaring, method_file, method) in interfaces.declared_methods(&interface_key) {
+        let name = method.name;
+        if existing.contains(name) {
+            continue;
+        }
+        let qualifier = TypeQualifier::new(method_file, target_file);
+        let parameters = method
+            .element
+            .child_by_field_name("parameters")
+            .map(|list| render_parameters(&qualifier, list, receiver_name))
+            .unwrap_or_default();
+        let result = match method.element.child_by_field_name("result") {
+            Some(result) => format!(" {}", qualifier.render(result)),
+            None => String::new(),
+        };
+        let interface = match declaring.split_once('.') {
+            Some((package, interface)) if package == target_package => interface.to_string(),
+            _ => declaring.to_string(),
+        };
+        let source = format!(
+            "// {name} implements {interface}.\nfunc ({receiver}) {name}({parameters}){result} {{\n\tpanic(\"not implemented\")\n}}"
+        );
+        stubs.push(MethodStub {
+            method: name.to_string(),
+            interface,
+            source,
+        });
+    }
+    Ok(stubs)
+}
+
+/// The type spec named by `query` (`Name` or `package.Name`) whose type
+/// satisfies `accept`
+fn find_type_spec<'a>(
+    files: &'a [GoSourceFile],
+    query: &str,
+    accept: impl Fn(Node) -> bool,
+) -> Option<(&'a GoSourceFile, Node<'a>)> {
+    let (package, name) = match query.rsplit_once('.') {
+        Some((package, name)) => (Some(package), name),
+        None => (None, query),
+    };
+    for file in files {
+        if package.is_some_and(|p| Some(p) != file.package_name()) {
+            continue;
+        }
+        let mut found = None;
+        walk_tree(file.root(), &mut |node| {
+            if found.is_some() || node.kind() != "type_spec" {
+                return;
+            }
+            let matches = node
+                .child_by_field_name("name")
+                .is_some_and(|n| file.text(n) == name);
+            if matches && node.child_by_field_name("type").is_some_and(&accept) {
+                found = Some(node);
+            }
+        });
+        if let Some(spec) = found {
+            return Some((file, spec));
+        }
+    }
+    None
+}
+
+/// Receiver for a type without methods: `s *Stack[T]` for structs, `r Role`
+/// otherwise
+fn default_receiver(file: &GoSourceFile, spec: Node) -> String {
+    let name = spec
+        .child_by_field_name("name")
+        .map(|n| file.text(n))
+        .unwrap_or_default();
+    let short: String = name
+        .chars()
+        .next()
+        .map(|c| c.to_lowercase().collect())
+        .unwrap_or_else(|| "t".to_string());
+    let parameters = spec
+        .child_by_field_name("type_parameters")
+        .map(|list| {
+            let names: Vec<&str> = list
+                .named_children(&mut list.walk())
+                .flat_map(|decl| {
+                    decl.children_by_field_name("name", &mut decl.walk())
+                        .map(|n| file.text(n))
+                        .collect::<Vec<_>>()
+                })
+                .collect();
+            format!("[{}]", names.join(", "))
+        })
+        .unwrap_or_default();
+    let pointer = spec
+        .child_by_field_name("type")
+        .is_some_and(|body| body.kind() == "struct_type");
+    let star = if pointer { "*" } else { "" };
+    format!("{short} {star}{name}{parameters}")
+}
+
+/// Rewrites type expressions from the interface's file for the target's
+/// file
+struct TypeQualifier<'a> {
+    source: &'a GoSourceFile,
+    /// Package name to qualify the source package's own types with, `None`
+    /// when both files share the package
+    own_package: Option<String>,
+    source_aliases: HashMap<String, String>,
+    /// Local names of the target's imports, by import path
+    target_imports: HashMap<String, String>,
+}
+
+impl<'a> TypeQualifier<'a> {
+    fn new(source: &'a GoSourceFile, target: &GoSourceFile) -> Self {
+        let source_package = source.package_name().unwrap_or_default();
+        let target_imports: HashMap<String, String> = target
+            .import_aliases()
+            .into_iter()
+            .map(|(local, path)| (path, local))
+            .collect();
+        let own_package =
+            (source_package != target.package_name().unwrap_or_default()).then(|| {
+                // The local name of an import of the source package, if any
+                target_imports
+                    .iter()
+                    .find(|(path, _)| path.rsplit('/').next() == Some(source_package))
+                    .map(|(_, local)| local.clone())
+                    .unwrap_or_else(|| source_package.to_string())
+            });
+        Self {
+            source,
+            own_package,
+            source_aliases: source.import_aliases(),
+            target_imports,
+        }
+    }
+
+    /// Source text of a type expression, qualified for the target file
+    fn render(&self, node: Node) -> String {
+        let mut replacements = Vec::new();
+        walk_tree(node, &mut |n| {
+            let in_qualified = n.parent().is_some_and(|p| p.kind() == "qualified_type");
+            match n.kind() {
+                "type_identifier" if !in_qualified => {
+                    let name = self.source.text(n);
+                    if let Some(package) = &self.own_package {
+                        if !PREDECLARED_TYPES.contains(&name) {
+                            replacements.push((n.byte_range(), format!("{package}.{name}")));
+                        }
+                    }
+                }
+                "package_identifier" if in_qualified => {
+                    let alias = self.source.text(n);
+                    if let Some(path) = self.source_aliases.get(alias) {
+                        let local = self
+                            .target_imports
+                            .get(path)
+                            .map(String::as_str)
+                            .unwrap_or_else(|| path.rsplit('/').next().unwrap_or(path));
+                        if local != alias {
+                            replacements.push((n.byte_range(), local.to_string()));
+                        }
+                    }
+                }
+                _ => {}
+            }
+        });
+
+        let mut text = String::new();
+        let mut position = node.start_byte();
+        for (range, replacement) in replacements {
+            text.push_str(&self.source.source[position..range.start]);
+            text.push_str(&replacement);
+            position = range.end;
+        }
+        text.push_str(&self.source.source[position..node.end_byte()]);
+        text
+    }
+}
+
+/// Parameters of an interface method, with unnamed ones named after their
+/// type: `(context.Context, *User)` gives `ctx context.Context, user *User`
+fn render_parameters(qualifier: &TypeQualifier, list: Node, receiver: &str) -> String {
+    let mut taken: HashSet<String> = HashSet::from([receiver.to_string()]);
+    let mut rendered = Vec::new();
+    for declaration in list.named_children(&mut list.walk()) {
+        let variadic = declaration.kind() == "variadic_parameter_declaration";
+        if !variadic && declaration.kind() != "parameter_declaration" {
+            continue;
+        }
+        let Some(type_node) = declaration.child_by_field_name("type") else {
+            continue;
+        };
+        let type_text = qualifier.render(type_node);
+        let dots = if variadic { "..." } else { "" };
+        let names: Vec<&str> = declaration
+            .children_by_field_name("name", &mut declaration.walk())
+            .map(|n| qualifier.source.text(n))
+            .collect();
+        let names: Vec<String> = if names.is_empty() {
+            vec![unique_name(
+                parameter_name(&type_text, variadic),
+                &mut taken,
+            )]
+        } else {
+            names
+                .into_iter()
+                .map(|name| unique_name(name.to_string(), &mut taken))
+                .collect()
+        };
+        rendered.push(format!("{} {dots}{type_text}", names.join(", ")));
+    }
+    rendered.join(", ")
+}
+
+/// Parameter name suggested by a type: `context.Context` gives `ctx`,
+/// `*User` gives `user`, `[]byte` gives `b`
+fn parameter_name(type_text: &str, variadic: bool) -> String {
+    let base = type_text
+        .trim_start_matches(|c: char| c == '*' || c == '[' || c == ']' || c == '.')
+        .trim_start_matches("<-")
+        .trim_start_matches("chan ")
+        .trim_start_matches('*');
+    let base = base.split('[').next().unwrap_or(base);
+    let base = base.rsplit('.').next().unwrap_or(base);
+    let name = match base {
+        "Context" => "ctx".to_string(),
+        "error" => "err".to_string(),
+        _ if base.starts_with("func") || base.starts_with("map") => base[..1].to_string(),
+        _ if PREDECLARED_TYPES.contains(&base) => base[..1].to_string(),
+        _ => {
+            let mut chars = base.chars();
+            match chars.next() {
+                Some(first) if first.is_alphabetic() => {
+                    first.to_lowercase().chain(chars).collect::<String>()
+                }
+                _ => "arg".to_string(),
+            }
+        }
+    };
+    let name = if KEYWORDS.contains(&name.as_str()) {
+        name[..1].to_string()
+    } else {
+        name
+    };
+    if variadic || type_text.starts_with("[]") {
+        if name.len() > 1 && !name.ends_with('s') {
+            return format!("{name}s");
+        }
+    }
+    name
+}
+
+/// `name`, or `name2`, `name3`... when taken
+fn unique_name(name: String, taken: &mut HashSet<String>) -> String {
+    let mut candidate = name.clone();
+    let mut counter = 2;
+    while taken.contains(&candidate) {
+        candidate = format!("{name}{counter}");
+        counter += 1;
+    }
+    taken.insert(candidate.clone());
+    candidate
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse(path: &str, code: &str) -> GoSourceFile {
+        GoSourceFile::parse(path, code.to_string()).unwrap()
+    }
+
+    #[test]
+    fn test_stubs_for_missing_methods_across_packages() {
+        let models = r#"
+package models
+
+import "context"
+
+type User struct{ ID string }
+
+type Closer interface {
+    Close() error
+}
+
+type Store interface {
+    Closer
+    Save(context.Context, *User) error
+    Load(id string) (*User, error)
+    Find(...string) []User
+}
+"#;
+        let services = r#"
+package services
+
+import (
+    m "example.com/app/models"
+)
+
+type UserStore struct{}
+
+func (us *UserStore) Load(id string) (*m.User, error) { return nil, nil }
+"#;
+        let files = vec![
+            parse("app/models/store.go", models),
+            parse("app/services/user_store.go", services),
+        ];
+
+        let stubs = generate_method_stubs(&files, "UserStore", "models.Store").unwrap();
+        let methods: Vec<_> = stubs.iter().map(|s| s.method.as_str()).collect();
+        assert_eq!(methods, vec!["Close", "Save", "Find"]);
+        assert_eq!(stubs[0].interface, "models.Closer");
+        assert_eq!(
+            stubs[1].source,
+            "// Save implements models.Store.\nfunc (us *UserStore) Save(ctx context.Context, user *m.User) error {\n\tpanic(\"not implemented\")\n}"
+        );
+        assert!(stubs[2].source.contains("Find(s ...string) []m.User"));
+
+        assert_eq!(
+            generate_method_stubs(&files, "Missing", "Store"),
+            Err(StubError::TypeNotFound("Missing".to_string()))
+        );
+        assert_eq!(
+            generate_method_stubs(&files, "Store", "Closer"),
+            Err(StubError::NotAConcreteType("Store".to_string()))
+        );
+    }
+}
@@ -0,0 +1,362 @@
+//! Symbol-level changes between two versions of a Go file
+//!
+//! A line diff says which lines moved; reviewers usually want to know which
+//! declarations changed. Each package-level declaration of the old and new
+//! file is matched by name and compared:
+//!
+//! ```text
+//! added (1):
+//!   func NewSession at auth/session.go:12
+//! removed (1):
+//!   method User.LegacyID at models/user.go:40
+//! signature-changed (1):
+//!   func Load at store/store.go:8 (func Load(id string) error -> func Load(ctx context.Context, id string) error)
+//! body-changed (1):
+//!   method User.Validate at models/user.go:27
+//! ```
+//!
+//! The signature of a function or method is everything before its body; of
+//! a type, its type parameters and whether it is a struct, an interface or
+//! which type it is defined as; of a variable or constant, its declared
+//! type. Differences in whitespace alone are not changes, so reformatting
+//! and declarations that only moved are not reported. Methods are named
+//! `Receiver.Method`; repeated names such as `init` are matched in order.
+
+use super::{GoSourceFile, line_of, receiver_type_name, walk_tree};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// How a declaration differs between the two versions
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum SymbolChangeKind {
+    Added,
+    Removed,
+    SignatureChanged,
+    BodyChanged,
+}
+
+impl fmt::Display for SymbolChangeKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(match self {
+            SymbolChangeKind::Added => "added",
+            SymbolChangeKind::Removed => "removed",
+            SymbolChangeKind::SignatureChanged => "signature-changed",
+            SymbolChangeKind::BodyChanged => "body-changed",
+        })
+    }
+}
+
+/// A package-level declaration that was added, removed or changed
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SymbolChange {
+    pub change: SymbolChangeKind,
+    /// Declared name; methods are qualified by their receiver type
+    pub name: String,
+    /// `func`, `method`, `type`, `var` or `const`
+    pub kind: &'static str,
+    /// File in the new version, or in the old one for removals
+    pub file: String,
+    /// 1-based line of the name in that file
+    pub line: u32,
+    /// Old signature, for signature changes
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub old_signature: Option<String>,
+    /// New signature, for signature changes
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub new_signature: Option<String>,
+}
+
+impl fmt::Display for SymbolChange {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{} {} at {}:{}",
+            self.kind, self.name, self.file, self.line
+        )?;
+        if let (Some(old), Some(new)) = (&self.old_signature, &self.new_signature) {
+            write!(f, " ({old} -> {new})")?;
+        }
+        Ok(())
+    }
+}
+
+/// A declaration with the parts that are compared
+struct Declaration {
+    name: String,
+    kind: &'static str,
+    line: u32,
+    signature: String,
+    body: String,
+}
+
+/// Compare the declarations of two versions of a file, sorted by change,
+/// file and line
+///
+/// `None` stands for a file that doesn't exist in that version, so every
+/// declaration of the other one is added or removed.
+pub fn diff_symbols(old: Option<&GoSourceFile>, new: Option<&GoSourceFile>) -> Vec<SymbolChange> {
+    let mut old_declarations: HashMap<(String, usize), Declaration> = HashMap::new();
+    if let Some(file) = old {
+        for (key, declaration) in keyed(declarations(file)) {
+            old_declarations.insert(key, declaration);
+        }
+    }
+
+    let mut changes = Vec::new();
+    let new_path = new.map(GoSourceFile::display_path).unwrap_or_default();
+    if let Some(file) = new {
+        for (key, declaration) in keyed(declarations(file)) {
+            let change = match old_declarations.remove(&key) {
+                None => Some((SymbolChangeKind::Added, None)),
+                Some(previous) if previous.signature != declaration.signature => {
+                    Some((SymbolChangeKind::SignatureChanged, Some(previous.signature)))
+                }
+                Some(previous) if previous.body != declaration.body => {
+                    Some((SymbolChangeKind::BodyChanged, None))
+                }
+                Some(_) => None,
+            };
+            let Some((change, old_signature)) = change else {
+                continue;
+            };
+            let new_signature = old_signature
+                .is_some()
+                .then(|| declaration.signature.clone());
+            changes.push(SymbolChange {
+                change,
+                name: declaration.name,
+                kind: declaration.kind,
+                file: new_path.clone(),
+                line: declaration.line,
+                old_signature,
+                new_signature,
+            });
+        }
+    }
+
+    let old_path = old.map(GoSourceFile::display_path).unwrap_or_default();
+    changes.extend(
+        old_declarations
+            .into_values()
+            .map(|declaration| SymbolChange {
+                change: SymbolChangeKind::Removed,
+                name: declaration.name,
+                kind: declaration.kind,
+                file: old_path.clone(),
+                line: declaration.line,
+                old_signature: None,
+                new_signature: None,
+            }),
+    );
+    changes.sort_by(|a, b| (a.change, &a.file, a.line).cmp(&(b.change, &b.file, b.line)));
+    changes
+}
+
+/// Key declarations by name and occurrence, so repeated names such as
+/// `init` are matched in order
+fn keyed(declarations: Vec<Declaration>) -> Vec<((String, usize), Declaration)> {
+    let mut seen: HashMap<String, usize> = HashMap::new();
+    declarations
+        .into_iter()
+        .map(|declaration| {
+            let count = seen.entry(declaration.name.clone()).or_default();
+            let key = (declaration.name.clone(), *count);
+            *count += 1;
+            (key, declaration)
+        })
+        .collect()
+}
+
+/// Package-level declarations of `file` in source order
+fn declarations(file: &GoSourceFile) -> Vec<Declaration> {
+    let mut declarations = Vec::new();
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        match decl.kind() {
+            "function_declaration" | "method_declaration" => {
+                let Some(name) = decl.child_by_field_name("name") else {
+                    continue;
+                };
+                let (qualified, kind) = match receiver_type_name(file, decl) {
+                    Some(receiver) if decl.kind() == "method_declaration" => {
+                        (format!("{receiver}.{}", file.text(name)), "method")
+                    }
+                    _ => (file.text(name).to_string(), "func"),
+                };
+                let body = decl.child_by_field_name("body");
+                let end = body.map_or(decl.end_byte(), |b| b.start_byte());
+                declarations.push(Declaration {
+                    name: qualified,
+                    kind,
+                    line: line_of(name),
+                    signature: normalize(&file.source[decl.start_byte()..end]),
+                    body: body.map(|b| normalize(file.text(b))).unwrap_or_default(),
+                });
+            }
+            "type_declaration" => walk_tree(decl, &mut |node| {
+                if matches!(node.kind(), "type_spec" | "type_alias") {
+                    declarations.extend(type_declaration(file, node));
+                }
+            }),
+            "var_declaration" | "const_declaration" => {
+                let kind = if decl.kind() == "var_declaration" {
+                    "var"
+                } else {
+                    "const"
+                };
+                walk_tree(decl, &mut |node| {
+                    if matches!(node.kind(), "var_spec" | "const_spec") {
+                        declarations.extend(value_declarations(file, node, kind));
+                    }
+                });
+            }
+            _ => {}
+        }
+    }
+    declarations.retain(|declaration| declaration.name != "_");
+    declarations
+}
+
+/// A `type_spec` or `type_alias`
+fn type_declaration(file: &GoSourceFile, spec: Node) -> Option<Declaration> {
+    let name = spec.child_by_field_name("name")?;
+    let typ = spec.child_by_field_name("type")?;
+    let parameters = spec
+        .child_by_field_name("type_parameters")
+        .map(|p| file.text(p))
+        .unwrap_or_default();
+    let shape = match typ.kind() {
+        "struct_type" => "struct",
+        "interface_type" => "interface",
+        _ => file.text(typ),
+    };
+    let assign = if spec.kind() == "type_alias" {
+        " ="
+    } else {
+        ""
+    };
+    Some(Declaration {
+        name: file.text(name).to_string(),
+        kind: "type",
+        line: line_of(name),
+        signature: normalize(&format!(
+            "type {}{parameters}{assign} {shape}",
+            file.text(name)
+        )),
+        body: normalize(file.text(typ)),
+    })
+}
+
+/// One declaration per name of a `var_spec` or `const_spec`
+fn value_declarations(file: &GoSourceFile, spec: Node, kind: &'static str) -> Vec<Declaration> {
+    let typ = spec
+        .child_by_field_name("type")
+        .map(|t| file.text(t))
+        .unwrap_or_default();
+    let values: Vec<_> = spec
+        .child_by_field_name("value")
+        .map(|v| v.named_children(&mut v.walk()).collect())
+        .unwrap_or_default();
+    let names: Vec<_> = spec
+        .children_by_field_name("name", &mut spec.walk())
+        .collect();
+    names
+        .iter()
+        .enumerate()
+        .map(|(i, name)| Declaration {
+            name: file.text(*name).to_string(),
+            kind,
+            line: line_of(*name),
+            signature: normalize(&format!("{kind} {} {typ}", file.text(*name))),
+            body: values
+                .get(i)
+                .map(|v| normalize(file.text(*v)))
+                .unwrap_or_default(),
+        })
+        .collect()
+}
+
+/// Collapse runs of whitespace so formatting alone is no change
+fn normalize(text: &str) -> String {
+    text.split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_symbol_changes_between_versions() {
+        let old = r#"
+package models
+
+const MaxUsers = 100
+
+type User struct {
+    Name string
+}
+
+func (u *User) Validate() error {
+    return nil
+}
+
+func Load(id string) (*User, error) {
+    return nil, nil
+}
+
+func LegacyID(u *User) int { return 0 }
+"#;
+        let new = r#"
+package models
+
+const MaxUsers   =   100
+
+type User struct {
+    Name  string
+    Email string
+}
+
+func (u *User) Validate() error {
+    if u.Name == "" {
+        return errEmpty
+    }
+    return nil
+}
+
+func Load(ctx context.Context, id string) (*User, error) {
+    return nil, nil
+}
+
+func NewUser(name string) *User { return &User{Name: name} }
+"#;
+        let old = GoSourceFile::parse("models/user.go", old.to_string()).unwrap();
+        let new = GoSourceFile::parse("models/user.go", new.to_string()).unwrap();
+        let changes = diff_symbols(Some(&old), Some(&new));
+        let summary: Vec<_> = changes
+            .iter()
+            .map(|c| (c.change, c.name.as_str(), c.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (SymbolChangeKind::Added, "NewUser", 22),
+                (SymbolChangeKind::Removed, "LegacyID", 18),
+                (SymbolChangeKind::SignatureChanged, "Load", 18),
+                (SymbolChangeKind::BodyChanged, "User", 6),
+                (SymbolChangeKind::BodyChanged, "User.Validate", 11),
+            ]
+        );
+        assert_eq!(
+            changes[2].to_string(),
+            "func Load at models/user.go:18 (func Load(id string) (*User, error) -> func Load(ctx context.Context, id string) (*User, error))"
+        );
+
+        // A new file adds everything it declares
+        let added = diff_symbols(None, Some(&new));
+        assert!(added.iter().all(|c| c.change == SymbolChangeKind::Added));
+        assert_eq!(added.len(), 5);
+    }
+}
@@ -0,0 +1,366 @@
+//! Exported symbols referenced by tests
+//!
+//! A static "is it tested at all" heuristic, not line coverage: an exported
+//! function, type, constant, variable or method counts as tested when test
+//! code refers to it.
+//!
+//! ```go
+//! // models/user_test.go
+//! func TestNewUser(t *testing.T) {
+//!     u := NewUser("1", "Ada") // NewUser and User are tested
+//!     ...
+//! }
+//! ```
+//!
+//! Test code is every `_test.go` file, plus the `Test`, `Benchmark`,
+//! `Example` and `Fuzz` functions of other files, such as the pseudo-tests
+//! of fixtures. Plain identifiers refer to the symbols of the test's own
+//! package (an external `models_test` package counts as `models`),
+//! `pkg.Name` selectors and qualified types to those of the imported
+//! package. Methods are matched by name alone: a test calling `x.Rename()`
+//! covers every exported `Rename` method. Packages are keyed by name.
+
+use super::{
+    GoSourceFile, declaration_name, enclosing_function_name, is_exported, line_of, walk_tree,
+};
+use serde::Serialize;
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::fmt;
+use tree_sitter::Node;
+
+/// Exported symbols of a package and whether tests refer to them
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PackageTestCoverage {
+    pub package: String,
+    /// Number of symbols referenced by at least one test
+    pub tested: usize,
+    pub untested: usize,
+    /// Every exported symbol, sorted by file and line
+    pub symbols: Vec<SymbolCoverage>,
+}
+
+/// An exported symbol with the tests referring to it
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SymbolCoverage {
+    /// Identifier; methods as `Type.Method`
+    pub symbol: String,
+    /// `type`, `function`, `method`, `const` or `var`
+    pub kind: &'static str,
+    pub tested: bool,
+    /// Test functions referring to the symbol, or the test file for
+    /// references outside a function, sorted
+    pub tests: Vec<String>,
+    pub file: String,
+    /// 1-based line of the declaration
+    pub line: u32,
+}
+
+impl fmt::Display for PackageTestCoverage {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let total = self.tested + self.untested;
+        write!(
+            f,
+            "{}: {}/{total} exported symbols referenced by tests",
+            self.package, self.tested
+        )?;
+        if total > 0 {
+            write!(f, " ({}%)", self.tested * 100 / total)?;
+        }
+        for symbol in &self.symbols {
+            if symbol.tested {
+                write!(
+                    f,
+                    "\n  tested   {} {} by {}",
+                    symbol.kind,
+                    symbol.symbol,
+                    symbol.tests.join(", ")
+                )?;
+            } else {
+                write!(
+                    f,
+                    "\n  untested {} {} at {}:{}",
+                    symbol.kind, symbol.symbol, symbol.file, symbol.line
+                )?;
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Find which exported symbols tests refer to, one entry per package sorted
+/// by package name
+///
+/// With `package`, only that package is reported.
+pub fn find_test_coverage(
+    files: &[GoSourceFile],
+    package: Option<&str>,
+) -> Vec<PackageTestCoverage> {
+    let references = TestReferences::collect(files);
+
+    let mut packages: BTreeMap<String, Vec<SymbolCoverage>> = BTreeMap::new();
+    for file in files.iter().filter(|f| !f.is_test()) {
+        let Some(name) = file.package_name() else {
+            continue;
+        };
+        if package.is_some_and(|wanted| wanted != name) {
+            continue;
+        }
+        let symbols = packages.entry(name.to_string()).or_default();
+        for (symbol, kind, node) in exported_symbols(file) {
+            let tests = match kind {
+                "method" => symbol
+                    .rsplit_once('.')
+                    .and_then(|(_, method)| references.methods.get(method)),
+                _ => references.names.get(&(name.to_string(), symbol.clone())),
+            };
+            let tests: Vec<String> = tests.into_iter().flatten().cloned().collect();
+            symbols.push(SymbolCoverage {
+                symbol,
+                kind,
+                tested: !tests.is_empty(),
+                tests,
+                file: file.display_path(),
+                line: line_of(node),
+            });
+        }
+    }
+
+    packages
+        .into_iter()
+        .filter(|(_, symbols)| !symbols.is_empty())
+        .map(|(package, mut symbols)| {
+            symbols.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+            let tested = symbols.iter().filter(|s| s.tested).count();
+            PackageTestCoverage {
+                package,
+                tested,
+                untested: symbols.len() - tested,
+                symbols,
+            }
+        })
+        .collect()
+}
+
+/// Whether a function is one `go test` runs: `TestX`, `BenchmarkX`,
+/// `ExampleX` or `FuzzX`, or just the prefix
+fn is_test_function(name: &str) -> bool {
+    ["Test", "Benchmark", "Example", "Fuzz"]
+        .iter()
+        .any(|prefix| {
+            name.strip_prefix(prefix)
+                .is_some_and(|rest| rest.is_empty() || !rest.starts_with(char::is_lowercase))
+        })
+}
+
+/// Exported package-level symbols of a file with their kind and declaration
+///
+/// Methods count when their receiver type is exported too; test functions
+/// are test code, not API.
+fn exported_symbols<'t>(file: &'t GoSourceFile) -> Vec<(String, &'static str, Node<'t>)> {
+    let mut symbols = Vec::new();
+    let root = file.root();
+    for decl in root.named_children(&mut root.walk()) {
+        match decl.kind() {
+            "function_declaration" => {
+                if let Some(name) = declaration_name(file, decl)
+                    .filter(|name| is_exported(name) && !is_test_function(name))
+                {
+                    symbols.push((name, "function", decl));
+                }
+            }
+            "method_declaration" => {
+                if let Some(name) = declaration_name(file, decl).filter(|name| {
+                    name.split_once('.').is_some_and(|(receiver, method)| {
+                        is_exported(receiver) && is_exported(method)
+                    })
+                }) {
+                    symbols.push((name, "method", decl));
+                }
+            }
+            "type_declaration" | "const_declaration" | "var_declaration" => {
+                walk_tree(decl, &mut |spec| {
+                    let (names, kind): (Vec<Node>, _) = match spec.kind() {
+                        "type_spec" | "type_alias" => (
+                            spec.child_by_field_name("name").into_iter().collect(),
+                            "type",
+                        ),
+                        "const_spec" => (
+                            spec.children_by_field_name("name", &mut spec.walk())
+                                .collect(),
+                            "const",
+                        ),
+                        "var_spec" => (
+                            spec.children_by_field_name("name", &mut spec.walk())
+                                .collect(),
+                            "var",
+                        ),
+                        _ => return,
+                    };
+                    for name in names {
+                        if is_exported(file.text(name)) {
+                            symbols.push((file.text(name).to_string(), kind, spec));
+                        }
+                    }
+                });
+            }
+            _ => {}
+        }
+    }
+    symbols
+}
+
+/// What test code refers to, with the tests referring to it
+#[derive(Default)]
+struct TestReferences {
+    /// (package, name) to tests
+    names: HashMap<(String, String), BTreeSet<String>>,
+    /// Method name to tests
+    methods: HashMap<String, BTreeSet<String>>,
+}
+
+impl TestReferences {
+    fn collect(files: &[GoSourceFile]) -> Self {
+        let mut references = Self::default();
+        for file in files {
+            let Some(package) = file.package_name() else {
+                continue;
+            };
+            let package = package.strip_suffix("_test").unwrap_or(package);
+            let aliases = file.import_aliases();
+            let root = file.root();
+            for decl in root.named_children(&mut root.walk()) {
+                let pseudo_test = decl.kind() == "function_declaration"
+                    && decl
+                        .child_by_field_name("name")
+                        .is_some_and(|n| is_test_function(file.text(n)));
+                if !file.is_test() && !pseudo_test {
+                    continue;
+                }
+                walk_tree(decl, &mut |node| {
+                    let (target_package, name) = match node.kind() {
+                        "identifier" | "type_identifier" => (package, file.text(node)),
+                        "selector_expression" => {
+                            let (Some(operand), Some(field)) = (
+                                node.child_by_field_name("operand"),
+                                node.child_by_field_name("field"),
+                            ) else {
+                                return;
+                            };
+                            let field = file.text(field);
+                            match aliases.get(file.text(operand)) {
+                                Some(path) if operand.kind() == "identifier" => {
+                                    (path.rsplit('/').next().unwrap_or(path), field)
+                                }
+                                _ => {
+                                    references.add_method(file, node, field);
+                                    return;
+                                }
+                            }
+                        }
+                        "qualified_type" => {
+                            let (Some(qualifier), Some(name)) = (
+                                node.child_by_field_name("package"),
+                                node.child_by_field_name("name"),
+                            ) else {
+                                return;
+                            };
+                            let Some(path) = aliases.get(file.text(qualifier)) else {
+                                return;
+                            };
+                            (path.rsplit('/').next().unwrap_or(path), file.text(name))
+                        }
+                        _ => return,
+                    };
+                    if is_exported(name) {
+                        references
+                            .names
+                            .entry((target_package.to_string(), name.to_string()))
+                            .or_default()
+                            .insert(test_name(file, node));
+                    }
+                });
+            }
+        }
+        references
+    }
+
+    fn add_method(&mut self, file: &GoSourceFile, node: Node, method: &str) {
+        if is_exported(method) {
+            self.methods
+                .entry(method.to_string())
+                .or_default()
+                .insert(test_name(file, node));
+        }
+    }
+}
+
+/// Test function around a reference, or the test file outside functions
+fn test_name(file: &GoSourceFile, node: Node) -> String {
+    enclosing_function_name(file, node).unwrap_or_else(|| file.display_path())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_exported_symbols_referenced_by_tests() {
+        let fixture = std::path::Path::new("tests/fixtures/go/module_project");
+        let files: Vec<_> = [
+            "models/store.go",
+            "models/user.go",
+            "models/user_test.go",
+            "services/user_store.go",
+            "services/user_store_test.go",
+        ]
+        .iter()
+        .map(|name| GoSourceFile::read(&fixture.join(name)).unwrap())
+        .collect();
+
+        let coverage = find_test_coverage(&files, None);
+        assert_eq!(
+            coverage
+                .iter()
+                .map(|c| c.package.as_str())
+                .collect::<Vec<_>>(),
+            vec!["models", "services"]
+        );
+        let models = &coverage[0];
+        let status = |name: &str| {
+            models
+                .symbols
+                .iter()
+                .find(|s| s.symbol == name)
+                .map(|s| (s.tested, s.tests.clone()))
+                .unwrap_or_else(|| panic!("{name} not listed"))
+        };
+        assert_eq!(status("NewUser"), (true, vec!["TestNewUser".to_string()]));
+        assert!(status("User").0);
+        assert!(status("User.Rename").0);
+        assert!(!status("User.Email").0);
+        assert!(!status("ResettableStore").0);
+        assert_eq!(models.tested + models.untested, models.symbols.len());
+
+        // services' test file only declares helpers on UserStore
+        let services = find_test_coverage(&files, Some("services"));
+        assert_eq!(services.len(), 1);
+        let tested: Vec<_> = services[0]
+            .symbols
+            .iter()
+            .map(|s| (s.symbol.as_str(), s.tested))
+            .collect();
+        assert_eq!(
+            tested,
+            vec![
+                ("UserStore", true),
+                ("UserStore.Save", false),
+                ("UserStore.Load", false),
+            ]
+        );
+        assert!(
+            models
+                .to_string()
+                .contains("\n  tested   function NewUser by TestNewUser")
+        );
+    }
+}
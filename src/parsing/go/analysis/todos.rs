@@ -0,0 +1,252 @@
+//! TODO-style markers in comments, tied to the symbols they annotate
+//!
+//! Finds `TODO`, `FIXME`, `XXX` and `HACK` (or configured) markers in Go
+//! comments:
+//!
+//! ```go
+//! // TODO(alice): cache the parsed config
+//! func LoadConfig(path string) (*Config, error) {
+//! ```
+//!
+//! Each marker is attached to the declaration its comment documents, or
+//! else to the function, method or type that encloses the comment, so the
+//! list reads as debt per symbol rather than per line. An author written in
+//! parentheses right after the marker is reported separately from the text.
+
+use super::{GoSourceFile, declaration_name, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Markers looked for when none are configured
+pub const DEFAULT_MARKERS: &[&str] = &["TODO", "FIXME", "XXX", "HACK"];
+
+/// A marker found in a comment
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TodoComment {
+    pub marker: String,
+    /// Name in parentheses after the marker, as in `TODO(alice)`
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub author: Option<String>,
+    /// Text following the marker
+    pub text: String,
+    /// Symbol the comment belongs to; methods and fields are qualified by
+    /// their type
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub symbol: Option<String>,
+    pub file: String,
+    /// 1-based line of the marker
+    pub line: u32,
+}
+
+impl fmt::Display for TodoComment {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.marker)?;
+        if let Some(author) = &self.author {
+            write!(f, "({author})")?;
+        }
+        if !self.text.is_empty() {
+            write!(f, ": {}", self.text)?;
+        }
+        if let Some(symbol) = &self.symbol {
+            write!(f, " in {symbol}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find markers in the comments of `files`, sorted by file and line
+///
+/// Markers match as whole words and are case-sensitive, so `todo` in prose
+/// and `TODOS` are ignored.
+pub fn find_todos<S: AsRef<str>>(files: &[GoSourceFile], markers: &[S]) -> Vec<TodoComment> {
+    let mut todos = Vec::new();
+    for file in files {
+        walk_tree(file.root(), &mut |node| {
+            if node.kind() != "comment" {
+                return;
+            }
+            let start = line_of(node);
+            for (offset, text) in file.text(node).lines().enumerate() {
+                let Some((marker, author, text)) = parse_marker(text, markers) else {
+                    continue;
+                };
+                todos.push(TodoComment {
+                    marker: marker.to_string(),
+                    author: author.map(str::to_string),
+                    text: text.to_string(),
+                    symbol: comment_symbol(file, node),
+                    file: file.display_path(),
+                    line: start + offset as u32,
+                });
+            }
+        });
+    }
+    todos.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    todos
+}
+
+/// First marker of a comment line, with its author and text
+fn parse_marker<'l, S: AsRef<str>>(
+    line: &'l str,
+    markers: &[S],
+) -> Option<(&'l str, Option<&'l str>, &'l str)> {
+    let is_word = |c: char| c.is_alphanumeric() || c == '_';
+    let (start, marker) = markers
+        .iter()
+        .map(AsRef::as_ref)
+        .filter(|m| !m.is_empty())
+        .flat_map(|m| line.match_indices(m).collect::<Vec<_>>())
+        .filter(|(i, m)| {
+            !line[..*i].ends_with(is_word) && !line[i + m.len()..].starts_with(is_word)
+        })
+        .min_by_key(|(i, m)| (*i, std::cmp::Reverse(m.len())))?;
+
+    let mut rest = &line[start + marker.len()..];
+    let mut author = None;
+    if let Some(inner) = rest.strip_prefix('(') {
+        if let Some((name, after)) = inner.split_once(')') {
+            author = Some(name.trim()).filter(|n| !n.is_empty());
+            rest = after;
+        }
+    }
+    let text = rest
+        .trim_end()
+        .trim_end_matches("*/")
+        .trim_start_matches([':', '-', ' ', '\t'])
+        .trim();
+    Some((marker, author, text))
+}
+
+/// Symbol a comment belongs to
+///
+/// A comment directly above a declaration documents it; any other comment
+/// belongs to the enclosing function, method or type.
+fn comment_symbol(file: &GoSourceFile, comment: Node) -> Option<String> {
+    documented_declaration(comment)
+        .and_then(|decl| symbol_name(file, decl))
+        .or_else(|| {
+            let mut current = comment.parent();
+            while let Some(node) = current {
+                if matches!(
+                    node.kind(),
+                    "function_declaration" | "method_declaration" | "type_spec" | "type_alias"
+                ) {
+                    return symbol_name(file, node);
+                }
+                current = node.parent();
+            }
+            None
+        })
+}
+
+/// Declaration following a comment block with no blank line in between
+///
+/// Comments trailing code on the same line document nothing below them.
+fn documented_declaration(comment: Node) -> Option<Node> {
+    if comment
+        .prev_named_sibling()
+        .is_some_and(|prev| prev.end_position().row == comment.start_position().row)
+    {
+        return None;
+    }
+    let mut row = comment.end_position().row;
+    let mut next = comment.next_named_sibling();
+    while let Some(node) = next {
+        if node.start_position().row != row + 1 {
+            return None;
+        }
+        if node.kind() != "comment" {
+            return Some(node);
+        }
+        row = node.end_position().row;
+        next = node.next_named_sibling();
+    }
+    None
+}
+
+/// Name of a declaration; members are qualified by their type
+pub(super) fn symbol_name(file: &GoSourceFile, node: Node) -> Option<String> {
+    match node.kind() {
+        "function_declaration" | "method_declaration" => declaration_name(file, node),
+        "type_declaration" | "const_declaration" | "var_declaration" | "var_spec_list" => node
+            .named_children(&mut node.walk())
+            .find_map(|child| symbol_name(file, child)),
+        "type_spec" | "type_alias" | "const_spec" | "var_spec" => {
+            Some(file.text(node.child_by_field_name("name")?).to_string())
+        }
+        "field_declaration" | "method_elem" => {
+            let name = file.text(node.child_by_field_name("name")?);
+            let mut owner = node.parent();
+            while let Some(n) = owner {
+                if n.kind() == "type_spec" {
+                    if let Some(owner) = symbol_name(file, n) {
+                        return Some(format!("{owner}.{name}"));
+                    }
+                }
+                owner = n.parent();
+            }
+            Some(name.to_string())
+        }
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_todos_attach_to_symbols() {
+        let code = r#"
+package config
+
+// TODO(alice): cache the parsed config
+func LoadConfig(path string) (*Config, error) {
+    data := read(path) // FIXME handle missing files
+    /* XXX: the parser allocates
+       HACK - reuse buffers */
+    return parse(data), nil
+}
+
+type Config struct {
+    // TODO split by environment
+    Values map[string]string
+}
+
+// NOTE(bob): not a default marker; TODOS neither
+var defaults = Config{}
+
+// TODO: package-wide cleanup
+
+func helper() {}
+"#;
+        let files = vec![GoSourceFile::parse("config/config.go", code.to_string()).unwrap()];
+        let todos = find_todos(&files, DEFAULT_MARKERS);
+
+        let summary: Vec<_> = todos
+            .iter()
+            .map(|t| (t.marker.as_str(), t.symbol.as_deref(), t.line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("TODO", Some("LoadConfig"), 4),
+                ("FIXME", Some("LoadConfig"), 6),
+                ("XXX", Some("LoadConfig"), 7),
+                ("HACK", Some("LoadConfig"), 8),
+                ("TODO", Some("Config.Values"), 13),
+                ("TODO", None, 20),
+            ]
+        );
+        assert_eq!(todos[0].author.as_deref(), Some("alice"));
+        assert_eq!(todos[0].text, "cache the parsed config");
+        assert_eq!(todos[1].text, "handle missing files");
+        assert_eq!(todos[3].text, "reuse buffers");
+
+        let custom = find_todos(&files, &["NOTE"]);
+        assert_eq!(custom.len(), 1);
+        assert_eq!(custom[0].author.as_deref(), Some("bob"));
+        assert_eq!(custom[0].symbol.as_deref(), Some("defaults"));
+    }
+}
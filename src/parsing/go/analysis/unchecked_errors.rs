@@ -0,0 +1,324 @@
+//! Errors that are never checked (advisory)
+//!
+//! Flags calls whose error result is thrown away, either assigned to the
+//! blank identifier or dropped with the whole call in an expression
+//! statement:
+//!
+//! ```go
+//! cwd, _ := os.Getwd()
+//! data, _ := json.Marshal(payload)
+//! db.Close()
+//! ```
+//!
+//! A call produces an error when its last result is of type `error`: for
+//! functions and methods declared in the indexed files, as their signature
+//! says, and for common standard library functions from a built-in table.
+//! A method call counts when every indexed method of that name returns an
+//! error. `go` and `defer` statements are left alone. Blanked errors are
+//! deliberate more often than not, so `unchecked_errors_allow_blank = true`
+//! in `codanna.toml` leaves them out; a `// codanna:ignore unchecked-errors`
+//! comment on the call line or the line above suppresses any finding.
+
+use super::{GoSourceFile, enclosing_function_name, is_suppressed, line_of, walk_tree};
+use crate::parsing::go::resolution::stdlib_error_results;
+use crate::project_config::StdlibResolution;
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "unchecked-errors";
+
+/// How an error result is thrown away
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum UncheckedErrorKind {
+    /// Assigned to `_`
+    Blank,
+    /// Call used as a statement, discarding every result
+    Ignored,
+}
+
+/// A call whose error result is not checked
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UncheckedError {
+    /// Callee as written, e.g. `os.Getwd`
+    pub call: String,
+    pub kind: UncheckedErrorKind,
+    /// Enclosing function, if any
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the call
+    pub line: u32,
+}
+
+impl fmt::Display for UncheckedError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let how = match self.kind {
+            UncheckedErrorKind::Blank => "assigned to _",
+            UncheckedErrorKind::Ignored => "ignored",
+        };
+        write!(f, "error of {}() {how}", self.call)?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find unchecked errors in `files`, sorted by file and line
+///
+/// With `allow_blank`, errors assigned to `_` are not reported.
+pub fn find_unchecked_errors(
+    files: &[GoSourceFile],
+    allow_blank: bool,
+    stdlib: StdlibResolution,
+) -> Vec<UncheckedError> {
+    let results = ErrorResults::new(files, stdlib);
+    let mut findings = Vec::new();
+    for file in files {
+        let package = file.package_name().unwrap_or_default();
+        let aliases = file.import_aliases();
+        walk_tree(file.root(), &mut |node| {
+            let (call, kind) = match node.kind() {
+                "expression_statement" => {
+                    let Some(call) = node
+                        .named_child(0)
+                        .filter(|c| c.kind() == "call_expression")
+                    else {
+                        return;
+                    };
+                    if results.of(file, package, &aliases, call).is_none() {
+                        return;
+                    }
+                    (call, UncheckedErrorKind::Ignored)
+                }
+                "short_var_declaration" | "assignment_statement" | "var_spec" => {
+                    let Some(call) = blanked_error_call(file, package, &aliases, &results, node)
+                    else {
+                        return;
+                    };
+                    if allow_blank {
+                        return;
+                    }
+                    (call, UncheckedErrorKind::Blank)
+                }
+                _ => return,
+            };
+            if is_suppressed(file, line_of(call), ANALYSIS_NAME) {
+                return;
+            }
+            let Some(callee) = call.child_by_field_name("function") else {
+                return;
+            };
+            findings.push(UncheckedError {
+                call: file.text(callee).to_string(),
+                kind,
+                function: enclosing_function_name(file, node),
+                file: file.display_path(),
+                line: line_of(call),
+            });
+        });
+    }
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// The call of `x, _ := f()` whose error result lands in `_`
+fn blanked_error_call<'t>(
+    file: &GoSourceFile,
+    package: &str,
+    aliases: &HashMap<String, String>,
+    results: &ErrorResults,
+    node: Node<'t>,
+) -> Option<Node<'t>> {
+    let (targets, values): (Vec<Node>, Node) = if node.kind() == "var_spec" {
+        (
+            node.children_by_field_name("name", &mut node.walk())
+                .collect(),
+            node.child_by_field_name("value")?,
+        )
+    } else {
+        let left = node.child_by_field_name("left")?;
+        (
+            left.named_children(&mut left.walk()).collect(),
+            node.child_by_field_name("right")?,
+        )
+    };
+    if values.named_child_count() != 1 {
+        return None;
+    }
+    let call = values
+        .named_child(0)
+        .filter(|c| c.kind() == "call_expression")?;
+    let count = results.of(file, package, aliases, call)?;
+    let blanked = targets.len() == count && targets.last().is_some_and(|t| file.text(*t) == "_");
+    blanked.then_some(call)
+}
+
+/// Result counts of the declared functions and methods whose last result is
+/// an error
+struct ErrorResults {
+    /// (package, function) to result count, `None` when no error is returned
+    functions: HashMap<(String, String), Option<usize>>,
+    /// Method name to the result counts of every method of that name
+    methods: HashMap<String, Vec<Option<usize>>>,
+    /// Whether the standard library table is consulted
+    stdlib: StdlibResolution,
+}
+
+impl ErrorResults {
+    fn new(files: &[GoSourceFile], stdlib: StdlibResolution) -> Self {
+        let mut functions = HashMap::new();
+        let mut methods: HashMap<String, Vec<Option<usize>>> = HashMap::new();
+        for file in files {
+            let package = file.package_name().unwrap_or_default();
+            let root = file.root();
+            for decl in root.named_children(&mut root.walk()) {
+                let Some(name) = decl.child_by_field_name("name") else {
+                    continue;
+                };
+                let name = file.text(name).to_string();
+                let count = error_result_count(file, decl);
+                match decl.kind() {
+                    "function_declaration" => {
+                        functions.insert((package.to_string(), name), count);
+                    }
+                    "method_declaration" => methods.entry(name).or_default().push(count),
+                    _ => {}
+                }
+            }
+        }
+        Self {
+            functions,
+            methods,
+            stdlib,
+        }
+    }
+
+    /// Number of results of a call whose last result is an error
+    fn of(
+        &self,
+        file: &GoSourceFile,
+        package: &str,
+        aliases: &HashMap<String, String>,
+        call: Node,
+    ) -> Option<usize> {
+        let function = call.child_by_field_name("function")?;
+        match function.kind() {
+            "identifier" => *self
+                .functions
+                .get(&(package.to_string(), file.text(function).to_string()))?,
+            "selector_expression" => {
+                let operand = function.child_by_field_name("operand")?;
+                let field = file.text(function.child_by_field_name("field")?);
+                if let Some(path) = aliases.get(file.text(operand)) {
+                    let imported = path.rsplit('/').next().unwrap_or(path);
+                    return stdlib_error_results(path, field, self.stdlib).or_else(|| {
+                        self.functions
+                            .get(&(imported.to_string(), field.to_string()))
+                            .copied()
+                            .flatten()
+                    });
+                }
+                let counts = self.methods.get(field)?;
+                let first = counts.first().copied().flatten()?;
+                counts
+                    .iter()
+                    .all(|count| *count == Some(first))
+                    .then_some(first)
+            }
+            _ => None,
+        }
+    }
+}
+
+/// Number of results of a declaration whose last result is `error`
+fn error_result_count(file: &GoSourceFile, decl: Node) -> Option<usize> {
+    let result = decl.child_by_field_name("result")?;
+    if result.kind() != "parameter_list" {
+        return (file.text(result) == "error").then_some(1);
+    }
+    let mut count = 0;
+    let mut last_type = None;
+    for parameter in result.named_children(&mut result.walk()) {
+        if parameter.kind() != "parameter_declaration" {
+            continue;
+        }
+        count += parameter
+            .children_by_field_name("name", &mut parameter.walk())
+            .count()
+            .max(1);
+        last_type = parameter.child_by_field_name("type");
+    }
+    last_type
+        .is_some_and(|t| file.text(t) == "error")
+        .then_some(count)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_blanked_and_ignored_errors() {
+        let imports =
+            GoSourceFile::read(std::path::Path::new("tests/fixtures/go/imports.go")).unwrap();
+        let code = r#"
+package store
+
+import "os"
+
+type Store struct{}
+
+func (s *Store) Save(key string) error { return nil }
+func (s *Store) Load(key string) (string, error) { return "", nil }
+
+func open(path string) (f *os.File, size, mode int, err error) { return nil, 0, 0, nil }
+
+func run(s *Store) {
+    s.Save("a")
+    value, _ := s.Load("a")
+    _ = s.Save("b")
+    _, _, _, _ = open("db")
+    os.Remove("lock")
+    // codanna:ignore unchecked-errors
+    s.Save("c")
+    if err := s.Save("d"); err != nil {
+        return
+    }
+    defer s.Save("e")
+    println(value)
+}
+"#;
+        let store = GoSourceFile::parse("store/store.go", code.to_string()).unwrap();
+        let files = [imports, store];
+
+        let summary: Vec<_> = find_unchecked_errors(&files, false, StdlibResolution::Builtin)
+            .iter()
+            .map(|f| (f.call.as_str(), f.kind, f.line))
+            .collect();
+        use UncheckedErrorKind::*;
+        assert_eq!(
+            summary,
+            vec![
+                ("s.Save", Ignored, 14),
+                ("s.Load", Blank, 15),
+                ("s.Save", Blank, 16),
+                ("open", Blank, 17),
+                ("os.Remove", Ignored, 18),
+                ("os.Getwd", Blank, 49),
+                ("json.Marshal", Blank, 67),
+            ]
+        );
+
+        let allowed = find_unchecked_errors(&files, true, StdlibResolution::Builtin);
+        assert_eq!(allowed.len(), 2);
+        assert_eq!(
+            allowed[0].to_string(),
+            "error of s.Save() ignored in run at store/store.go:14"
+        );
+    }
+}
@@ -0,0 +1,225 @@
+//! Errors returned without added context (advisory)
+//!
+//! Flags the common pattern
+//!
+//! ```go
+//! if err := db.checkConnection(); err != nil {
+//!     return err
+//! }
+//! ```
+//!
+//! where the error of an immediate call is passed straight back to the caller
+//! instead of being wrapped with `fmt.Errorf("...: %w", err)`. Returning an
+//! error unchanged is often fine, so findings are candidates to review rather
+//! than defects. A `// codanna:ignore unwrapped-errors` comment on the return
+//! or the line above it suppresses the finding.
+
+use super::{GoSourceFile, enclosing_function_name, is_suppressed, line_of, walk_tree};
+use serde::Serialize;
+use std::fmt;
+use tree_sitter::Node;
+
+/// Name used to suppress findings of this analysis
+pub const ANALYSIS_NAME: &str = "unwrapped-errors";
+
+/// A `return err` that passes a call's error through unchanged
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UnwrappedErrorReturn {
+    /// Error variable returned, usually `err`
+    pub error_var: String,
+    /// Callee whose error is returned, as written
+    pub call: String,
+    /// Enclosing function, if any
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub function: Option<String>,
+    pub file: String,
+    /// 1-based line of the return statement
+    pub line: u32,
+    /// 1-based line of the call that produced the error
+    pub call_line: u32,
+}
+
+impl fmt::Display for UnwrappedErrorReturn {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "return {} from {}()", self.error_var, self.call)?;
+        if let Some(function) = &self.function {
+            write!(f, " in {function}")?;
+        }
+        write!(f, " at {}:{}", self.file, self.line)
+    }
+}
+
+/// Find unwrapped error returns in `files`, sorted by file and line
+pub fn find_unwrapped_error_returns(files: &[GoSourceFile]) -> Vec<UnwrappedErrorReturn> {
+    let mut findings: Vec<_> = files
+        .iter()
+        .flat_map(unwrapped_error_returns_in_file)
+        .collect();
+    findings.sort_by(|a, b| (&a.file, a.line).cmp(&(&b.file, b.line)));
+    findings
+}
+
+/// Unwrapped error returns in a single file, in source order
+pub fn unwrapped_error_returns_in_file(file: &GoSourceFile) -> Vec<UnwrappedErrorReturn> {
+    let mut findings = Vec::new();
+
+    walk_tree(file.root(), &mut |node| {
+        if node.kind() != "if_statement" {
+            return;
+        }
+        let Some(error_var) = nil_check_variable(file, node) else {
+            return;
+        };
+        let Some(call) = error_source_call(file, node, error_var) else {
+            return;
+        };
+        let Some(consequence) = node.child_by_field_name("consequence") else {
+            return;
+        };
+        let Some(callee) = call.child_by_field_name("function") else {
+            return;
+        };
+
+        for ret in returns_in_block(consequence) {
+            let returns_var = ret
+                .named_child(0)
+                .map(|list| {
+                    list.named_children(&mut list.walk())
+                        .any(|e| e.kind() == "identifier" && file.text(e) == error_var)
+                })
+                .unwrap_or(false);
+            if !returns_var || is_suppressed(file, line_of(ret), ANALYSIS_NAME) {
+                continue;
+            }
+            findings.push(UnwrappedErrorReturn {
+                error_var: error_var.to_string(),
+                call: file.text(callee).to_string(),
+                function: enclosing_function_name(file, ret),
+                file: file.display_path(),
+                line: line_of(ret),
+                call_line: line_of(call),
+            });
+        }
+    });
+
+    findings
+}
+
+/// Variable tested by an `if x != nil` condition
+fn nil_check_variable<'s>(file: &'s GoSourceFile, if_stmt: Node) -> Option<&'s str> {
+    let condition = if_stmt.child_by_field_name("condition")?;
+    if condition.kind() != "binary_expression" {
+        return None;
+    }
+    let operator = condition.child_by_field_name("operator")?;
+    let left = condition.child_by_field_name("left")?;
+    let right = condition.child_by_field_name("right")?;
+    (file.text(operator) == "!=" && left.kind() == "identifier" && right.kind() == "nil")
+        .then(|| file.text(left))
+}
+
+/// Call whose result was assigned to `error_var` right before the nil check
+///
+/// Looks at the `if` initializer first, then at the statement preceding the
+/// `if` (skipping comments).
+fn error_source_call<'t>(
+    file: &GoSourceFile,
+    if_stmt: Node<'t>,
+    error_var: &str,
+) -> Option<Node<'t>> {
+    if let Some(init) = if_stmt.child_by_field_name("initializer") {
+        return assigned_call(file, init, error_var);
+    }
+    let mut previous = if_stmt.prev_named_sibling();
+    while let Some(node) = previous {
+        if node.kind() != "comment" {
+            return assigned_call(file, node, error_var);
+        }
+        previous = node.prev_named_sibling();
+    }
+    None
+}
+
+/// The call on the right of `stmt` when `stmt` assigns its result to `var`
+fn assigned_call<'t>(file: &GoSourceFile, stmt: Node<'t>, var: &str) -> Option<Node<'t>> {
+    if !matches!(
+        stmt.kind(),
+        "short_var_declaration" | "assignment_statement"
+    ) {
+        return None;
+    }
+    let left = stmt.child_by_field_name("left")?;
+    let right = stmt.child_by_field_name("right")?;
+    let assigns_var = left
+        .named_children(&mut left.walk())
+        .any(|n| file.text(n) == var);
+    let call = right.named_child(0)?;
+    (assigns_var && right.named_child_count() == 1 && call.kind() == "call_expression")
+        .then_some(call)
+}
+
+/// Return statements in `block`, not descending into closures
+fn returns_in_block(block: Node) -> Vec<Node> {
+    let mut returns = Vec::new();
+    let mut stack = vec![block];
+    while let Some(node) = stack.pop() {
+        match node.kind() {
+            "return_statement" => returns.push(node),
+            "func_literal" => {}
+            _ => {
+                let children: Vec<_> = node.named_children(&mut node.walk()).collect();
+                stack.extend(children.into_iter().rev());
+            }
+        }
+    }
+    returns
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_unwrapped_error_returns() {
+        let code = r#"
+package services
+
+import "fmt"
+
+func (db *DatabaseConnection) Execute(query string) error {
+    if err := db.checkConnection(); err != nil {
+        return err
+    }
+    conn, err := db.pool.Acquire()
+    // Pool exhausted
+    if err != nil {
+        return fmt.Errorf("acquire connection: %w", err)
+    }
+    rows, err := conn.Query(query)
+    if err != nil {
+        return nil, err // codanna:ignore unwrapped-errors
+    }
+    result, err := parse(rows)
+    if err != nil {
+        return result, err
+    }
+    return nil
+}
+"#;
+        let files = vec![GoSourceFile::parse("database.go", code.to_string()).unwrap()];
+        let findings = find_unwrapped_error_returns(&files);
+
+        let summary: Vec<_> = findings
+            .iter()
+            .map(|f| (f.call.as_str(), f.line, f.call_line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![("db.checkConnection", 8, 7), ("parse", 21, 19)]
+        );
+        assert_eq!(
+            findings[0].function.as_deref(),
+            Some("DatabaseConnection.Execute")
+        );
+    }
+}
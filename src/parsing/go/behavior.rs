@@ -9,7 +9,37 @@ use crate::{SymbolId, Visibility};
 use std::path::{Path, PathBuf};
 use tree_sitter::Language;
 
-use super::resolution::{GoInheritanceResolver, GoResolutionContext};
+use super::resolution::{
+    GoInheritanceResolver, GoResolutionContext, RelativeImport, package_matches_import,
+    qualifier_import_path, relative_import_target,
+};
+
+/// A `Type.member` field or method name with its symbol and scope level
+type Member = (String, SymbolId, crate::parsing::ScopeLevel);
+
+/// A `type Alias = Target` declaration and where its target is declared
+struct AliasTarget {
+    alias: String,
+    /// Target base name, `User` for `models.User`
+    target: String,
+    /// Package of the alias, which declares an unqualified target
+    package: Option<String>,
+    /// Import path of the package a qualified target is declared in
+    import: Option<String>,
+}
+
+impl AliasTarget {
+    /// Whether a type of the package at `module_path` is the target
+    fn declared_in(&self, module_path: Option<&str>) -> bool {
+        match &self.import {
+            Some(import) => module_path.is_some_and(|module_path| {
+                self.package.as_deref() != Some(module_path)
+                    && package_matches_import(module_path, import)
+            }),
+            None => self.package.as_deref() == module_path,
+        }
+    }
+}
 
 /// Go language behavior implementation
 #[derive(Clone)]
@@ -215,7 +245,10 @@ impl LanguageBehavior for GoBehavior {
 
         // 1. Add imported symbols (using behavior's tracked imports)
         let imports = self.get_imports_for_file(file_id);
-        for import in imports {
+        for import in imports
+            .into_iter()
+            .filter(|import| import.alias.as_deref() != Some("_"))
+        {
             if let Some(symbol_id) = self.resolve_import(&import, document_index) {
                 // Use alias if provided, otherwise use the last segment of the path
                 let name = if let Some(alias) = &import.alias {
@@ -243,7 +276,35 @@ impl LanguageBehavior for GoBehavior {
                     cause: e.to_string(),
                 })?;
 
+        // Methods and aliases seen so far, to make alias methods reachable below
+        let mut methods: Vec<(String, crate::SymbolId, crate::parsing::ScopeLevel)> = Vec::new();
+        let mut method_packages: std::collections::HashMap<crate::SymbolId, Option<String>> =
+            std::collections::HashMap::new();
+        let mut aliases: Vec<AliasTarget> = Vec::new();
+        // Struct fields and (struct, embedded type) pairs, for field promotion
+        let mut fields: Vec<(String, crate::SymbolId, crate::parsing::ScopeLevel)> = Vec::new();
+        let mut embeds: Vec<(String, String)> = Vec::new();
+
+        context.add_function_locals(&file_symbols);
         for symbol in file_symbols {
+            if let Some(alias) = self.alias_and_target(&symbol) {
+                aliases.push(alias);
+            }
+            // Fields are named `Type.field`; unexported ones are still
+            // accessible within the package
+            if symbol.kind == crate::SymbolKind::Field {
+                context.add_symbol(
+                    symbol.name.to_string(),
+                    symbol.id,
+                    crate::parsing::ScopeLevel::Module,
+                );
+                Self::collect_field(
+                    &symbol,
+                    crate::parsing::ScopeLevel::Module,
+                    &mut fields,
+                    &mut embeds,
+                );
+            }
             if self.is_resolvable_symbol(&symbol) {
                 // Use the new method that respects scope_context for hoisting
                 context.add_symbol_with_context(
@@ -251,6 +312,18 @@ impl LanguageBehavior for GoBehavior {
                     symbol.id,
                     symbol.scope_context.as_ref(),
                 );
+                // Methods are also reachable as `Type.Method` so that calls on a
+                // receiver of known type pick the right method among same-named ones
+                if let Some(qualified) = Self::qualified_method_name(&symbol) {
+                    context.add_symbol(
+                        qualified.clone(),
+                        symbol.id,
+                        crate::parsing::ScopeLevel::Module,
+                    );
+                    methods.push((qualified, symbol.id, crate::parsing::ScopeLevel::Module));
+                    method_packages
+                        .insert(symbol.id, symbol.module_path.as_deref().map(str::to_string));
+                }
             }
         }
 
@@ -264,22 +337,84 @@ impl LanguageBehavior for GoBehavior {
                     cause: e.to_string(),
                 })?;
 
+        let own_package = self.get_module_path_for_file(file_id);
         for symbol in all_symbols {
             // Only add if visible from this file
             if symbol.file_id != file_id && self.is_symbol_visible_from_file(&symbol, file_id) {
-                // Global symbols go to global scope, others to module scope
-                let scope_level = match symbol.visibility {
-                    Visibility::Public => crate::parsing::ScopeLevel::Global,
-                    _ => crate::parsing::ScopeLevel::Module,
-                };
+                let scope_level = Self::visible_scope_level(&symbol, own_package.as_deref());
 
                 context.add_symbol(symbol.name.to_string(), symbol.id, scope_level);
+                if let Some(qualified) = Self::qualified_method_name(&symbol) {
+                    context.add_symbol(qualified.clone(), symbol.id, scope_level);
+                    methods.push((qualified, symbol.id, scope_level));
+                    method_packages
+                        .insert(symbol.id, symbol.module_path.as_deref().map(str::to_string));
+                }
+                if let Some(alias) = self.alias_and_target(&symbol) {
+                    aliases.push(alias);
+                }
+                if symbol.kind == crate::SymbolKind::Field {
+                    Self::collect_field(&symbol, scope_level, &mut fields, &mut embeds);
+                }
+            }
+        }
+
+        // An alias denotes the same type as its target, so `Alias.Method`
+        // resolves to the target's method, declared in the target's package
+        for (qualified, symbol_id, scope_level) in &methods {
+            let Some((receiver, method)) = qualified.split_once('.') else {
+                continue;
+            };
+            let package = method_packages.get(symbol_id).and_then(Option::as_deref);
+            for alias in &aliases {
+                if alias.target == receiver && alias.declared_in(package) {
+                    context.add_symbol(
+                        format!("{}.{method}", alias.alias),
+                        *symbol_id,
+                        *scope_level,
+                    );
+                }
             }
         }
 
+        Self::add_promoted_members(&mut context, &fields, &methods, &embeds);
+
+        self.add_dot_imported_symbols(&mut context, file_id, document_index)?;
+        self.add_relative_imported_symbols(&mut context, file_id, document_index)?;
+        self.add_imported_package_symbols(&mut context, file_id, document_index)?;
+
         Ok(Box::new(context))
     }
 
+    /// The generic cached context, plus the names of dot-imported packages
+    fn build_resolution_context_with_cache(
+        &self,
+        file_id: FileId,
+        cache: &crate::storage::symbol_cache::ConcurrentSymbolCache,
+        document_index: &DocumentIndex,
+    ) -> crate::error::IndexResult<Box<dyn ResolutionScope>> {
+        let mut context = crate::parsing::language_behavior::build_cached_resolution_context(
+            self,
+            file_id,
+            cache,
+            document_index,
+        )?;
+        if let Some(go_context) = context.as_any_mut().downcast_mut::<GoResolutionContext>() {
+            let file_symbols = document_index.find_symbols_by_file(file_id).map_err(|e| {
+                crate::error::IndexError::TantivyError {
+                    operation: "find_symbols_by_file".to_string(),
+                    cause: e.to_string(),
+                }
+            })?;
+            go_context.add_function_locals(&file_symbols);
+            self.add_type_members(go_context, file_id, document_index)?;
+            self.add_dot_imported_symbols(go_context, file_id, document_index)?;
+            self.add_relative_imported_symbols(go_context, file_id, document_index)?;
+            self.add_imported_package_symbols(go_context, file_id, document_index)?;
+        }
+        Ok(context)
+    }
+
     // Go-specific: Symbol resolution rules
     fn is_resolvable_symbol(&self, symbol: &crate::Symbol) -> bool {
         use crate::SymbolKind;
@@ -342,13 +477,14 @@ impl LanguageBehavior for GoBehavior {
         if import.path.starts_with("./") || import.path.starts_with("../") {
             // Get current package path from behavior state
             if let Some(current_package) = self.get_current_package_path_for_file(import.file_id) {
-                if let Some(resolved_path) =
-                    context.resolve_relative_import(&import.path, &current_package)
-                {
-                    return self.resolve_import_path(&resolved_path, document_index);
-                }
+                // None: the import leaves the project, nothing indexed is there
+                return context
+                    .resolve_relative_import(&import.path, &current_package)
+                    .and_then(|resolved_path| {
+                        self.resolve_import_path(&resolved_path, document_index)
+                    });
             }
-            // Fall back to basic resolution if relative resolution fails
+            // Fall back to basic resolution if the package is unknown
             return self.resolve_import_path(&import.path, document_index);
         }
 
@@ -381,6 +517,24 @@ impl LanguageBehavior for GoBehavior {
         self.state.get_module_path(file_id)
     }
 
+    /// Exported symbols are visible from every file; unexported package-level
+    /// ones from every file of their package, since a package's scope spans
+    /// all of its files
+    fn is_symbol_visible_from_file(&self, symbol: &crate::Symbol, from_file: FileId) -> bool {
+        use crate::symbol::ScopeContext;
+
+        if symbol.file_id == from_file || matches!(symbol.visibility, Visibility::Public) {
+            return true;
+        }
+        let package_level = !matches!(
+            symbol.scope_context,
+            Some(ScopeContext::Local { .. } | ScopeContext::Parameter)
+        );
+        package_level
+            && symbol.module_path.is_some()
+            && symbol.module_path.as_deref() == self.get_module_path_for_file(from_file).as_deref()
+    }
+
     fn configure_symbol(&self, symbol: &mut crate::Symbol, module_path: Option<&str>) {
         // Apply Go-specific module path formatting
         if let Some(path) = module_path {
@@ -406,52 +560,6 @@ impl LanguageBehavior for GoBehavior {
         symbol_module_path: &str,
         importing_module: Option<&str>,
     ) -> bool {
-        // Helper function to resolve relative path to absolute module path for Go
-        fn resolve_relative_path(import_path: &str, importing_mod: &str) -> String {
-            if import_path.starts_with("./") {
-                // Same directory import
-                let relative = import_path.trim_start_matches("./");
-
-                if importing_mod.is_empty() || importing_mod == "." {
-                    relative.to_string()
-                } else {
-                    format!("{importing_mod}/{relative}")
-                }
-            } else if import_path.starts_with("../") {
-                // Parent directory import
-                // Start with the importing module parts as owned strings
-                let mut module_parts: Vec<String> =
-                    importing_mod.split('/').map(|s| s.to_string()).collect();
-
-                let mut path_remaining: &str = import_path;
-
-                // Navigate up for each '../'
-                while path_remaining.starts_with("../") {
-                    if !module_parts.is_empty() {
-                        module_parts.pop();
-                    }
-                    // If we've gone above the module root, we just continue
-                    // This handles cases like ../../../some/path from a shallow module
-                    path_remaining = &path_remaining[3..];
-                }
-
-                // Add the remaining path
-                if !path_remaining.is_empty() {
-                    module_parts.extend(
-                        path_remaining
-                            .split('/')
-                            .filter(|s| !s.is_empty())
-                            .map(|s| s.to_string()),
-                    );
-                }
-
-                module_parts.join("/")
-            } else {
-                // Not a relative path, return as-is
-                import_path.to_string()
-            }
-        }
-
         // Case 1: Exact match (most common case, check first for performance)
         if import_path == symbol_module_path {
             return true;
@@ -463,11 +571,8 @@ impl LanguageBehavior for GoBehavior {
             // - Relative imports start with './' or '../'
             // - Absolute imports are package paths like "fmt", "github.com/user/repo/package"
 
-            if import_path.starts_with("./") || import_path.starts_with("../") {
-                // Resolve relative path to absolute module path
-                let resolved = resolve_relative_path(import_path, importing_mod);
-
-                // Check if it matches exactly
+            if let Some(resolved) = relative_import_target(import_path, importing_mod) {
+                // Resolved relative path must match exactly
                 if resolved == symbol_module_path {
                     return true;
                 }
@@ -481,6 +586,566 @@ impl LanguageBehavior for GoBehavior {
 }
 
 impl GoBehavior {
+    /// Bring the exported package-level names of `import . "pkg"` packages
+    /// into the file scope
+    ///
+    /// Declarations of the file's own package still take precedence. A name
+    /// several dot-imported packages export doesn't compile in Go; it is left
+    /// unresolved and reported rather than bound to one of them.
+    fn add_dot_imported_symbols(
+        &self,
+        context: &mut GoResolutionContext,
+        file_id: FileId,
+        document_index: &DocumentIndex,
+    ) -> crate::error::IndexResult<()> {
+        use crate::SymbolKind;
+        use crate::error::IndexError;
+        use std::collections::{BTreeMap, HashSet};
+
+        let dot_imports: Vec<String> = self
+            .get_imports_for_file(file_id)
+            .into_iter()
+            .filter(|import| import.alias.as_deref() == Some("."))
+            .map(|import| import.path)
+            .collect();
+        if dot_imports.is_empty() {
+            return Ok(());
+        }
+
+        let own_package = self.get_module_path_for_file(file_id);
+        let all_symbols =
+            document_index
+                .get_all_symbols(10000)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_all_symbols".to_string(),
+                    cause: e.to_string(),
+                })?;
+        let package_level = |symbol: &crate::Symbol| {
+            !matches!(symbol.kind, SymbolKind::Method | SymbolKind::Field)
+                && self.is_resolvable_symbol(symbol)
+        };
+
+        let mut own_names: HashSet<&str> = HashSet::new();
+        // Name to the (import path, symbol) pairs exporting it
+        let mut exported: BTreeMap<&str, Vec<(&str, SymbolId)>> = BTreeMap::new();
+        for symbol in all_symbols.iter().filter(|s| package_level(s)) {
+            let Some(module_path) = symbol.module_path.as_deref() else {
+                continue;
+            };
+            if Some(module_path) == own_package.as_deref() {
+                own_names.insert(symbol.name.as_ref());
+                continue;
+            }
+            if symbol.visibility != Visibility::Public {
+                continue;
+            }
+            for path in &dot_imports {
+                if !super::resolution::package_matches_import(module_path, path) {
+                    continue;
+                }
+                let providers = exported.entry(symbol.name.as_ref()).or_default();
+                if !providers.iter().any(|(p, _)| *p == path.as_str()) {
+                    providers.push((path.as_str(), symbol.id));
+                }
+            }
+        }
+
+        for (name, providers) in exported {
+            if own_names.contains(name) {
+                continue;
+            }
+            match providers.as_slice() {
+                [(_, symbol_id)] => {
+                    context.add_symbol(
+                        name.to_string(),
+                        *symbol_id,
+                        crate::parsing::ScopeLevel::Module,
+                    );
+                }
+                _ => {
+                    let paths: Vec<String> =
+                        providers.iter().map(|(path, _)| path.to_string()).collect();
+                    let file = document_index
+                        .get_file_path(file_id)
+                        .ok()
+                        .flatten()
+                        .unwrap_or_default();
+                    tracing::debug!(
+                        "{file}: {name} is exported by several dot imports (\"{}\"); references to it are left unresolved",
+                        paths.join("\", \"")
+                    );
+                    context.add_ambiguous_dot_import(name.to_string(), paths);
+                }
+            }
+        }
+        Ok(())
+    }
+
+    /// Resolve the relative imports (`./pkg`, `../pkg`) of a file against
+    /// its package directory and bring the exported names of the indexed
+    /// packages they lead to into scope as `pkg.Name`
+    ///
+    /// An import climbing out of the project, or leading to a directory with
+    /// no indexed package, is recorded as external-relative and its
+    /// references stay unresolved.
+    fn add_relative_imported_symbols(
+        &self,
+        context: &mut GoResolutionContext,
+        file_id: FileId,
+        document_index: &DocumentIndex,
+    ) -> crate::error::IndexResult<()> {
+        use crate::SymbolKind;
+        use crate::error::IndexError;
+        use crate::parsing::resolution::{ImportBinding, ImportOrigin};
+
+        let relative: Vec<crate::parsing::Import> = self
+            .get_imports_for_file(file_id)
+            .into_iter()
+            .filter(|import| {
+                (import.path.starts_with("./") || import.path.starts_with("../"))
+                    && !matches!(import.alias.as_deref(), Some("." | "_"))
+            })
+            .collect();
+        if relative.is_empty() {
+            return Ok(());
+        }
+
+        let own_package = self
+            .get_module_path_for_file(file_id)
+            .unwrap_or_else(|| ".".to_string());
+        let all_symbols =
+            document_index
+                .get_all_symbols(10000)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_all_symbols".to_string(),
+                    cause: e.to_string(),
+                })?;
+
+        for import in relative {
+            let target = relative_import_target(&import.path, &own_package);
+            let members: Vec<&crate::Symbol> = all_symbols
+                .iter()
+                .filter(|s| target.is_some() && s.module_path.as_deref() == target.as_deref())
+                .collect();
+            let Some(package) = target.filter(|_| !members.is_empty()) else {
+                context.add_relative_import(import.path.clone(), RelativeImport::ExternalRelative);
+                continue;
+            };
+
+            let visible = import.alias.clone().unwrap_or_else(|| {
+                import
+                    .path
+                    .rsplit('/')
+                    .next()
+                    .unwrap_or(&import.path)
+                    .to_string()
+            });
+            for symbol in members {
+                if symbol.visibility == Visibility::Public
+                    && !matches!(symbol.kind, SymbolKind::Method | SymbolKind::Field)
+                    && self.is_resolvable_symbol(symbol)
+                {
+                    context.add_symbol(
+                        format!("{visible}.{}", symbol.name),
+                        symbol.id,
+                        crate::parsing::ScopeLevel::Package,
+                    );
+                }
+            }
+            context.register_import_binding(ImportBinding {
+                import: import.clone(),
+                exposed_name: visible,
+                origin: ImportOrigin::Internal,
+                resolved_symbol: None,
+            });
+            context.add_relative_import(import.path, RelativeImport::Package(package));
+        }
+        Ok(())
+    }
+
+    /// Bring the exported names of the indexed packages a file imports by
+    /// path into scope as `name.Name`, `name` being the import alias when
+    /// there is one
+    ///
+    /// `userModel "app/models"` makes `userModel.NewUser` resolve to the same
+    /// symbol `models.NewUser` does through a plain import. Standard library
+    /// paths are skipped so a project package sharing their last segment
+    /// isn't taken for them.
+    fn add_imported_package_symbols(
+        &self,
+        context: &mut GoResolutionContext,
+        file_id: FileId,
+        document_index: &DocumentIndex,
+    ) -> crate::error::IndexResult<()> {
+        use crate::SymbolKind;
+        use crate::error::IndexError;
+        use crate::parsing::resolution::{ImportBinding, ImportOrigin};
+        use std::collections::BTreeSet;
+
+        let imports: Vec<crate::parsing::Import> = self
+            .get_imports_for_file(file_id)
+            .into_iter()
+            .filter(|import| {
+                !matches!(import.alias.as_deref(), Some("." | "_"))
+                    && !import.path.starts_with("./")
+                    && !import.path.starts_with("../")
+                    && !context.is_standard_library_package(&import.path)
+            })
+            .collect();
+        if imports.is_empty() {
+            return Ok(());
+        }
+
+        let own_package = self.get_module_path_for_file(file_id);
+        let all_symbols =
+            document_index
+                .get_all_symbols(10000)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_all_symbols".to_string(),
+                    cause: e.to_string(),
+                })?;
+
+        for import in imports {
+            let candidates: BTreeSet<&str> = all_symbols
+                .iter()
+                .filter_map(|s| s.module_path.as_deref())
+                .filter(|package| {
+                    Some(*package) != own_package.as_deref()
+                        && super::resolution::package_matches_import(package, &import.path)
+                })
+                .collect();
+            // Prefer the package the whole path ends with: `app/models` over
+            // another `models` directory
+            let package = candidates
+                .iter()
+                .find(|package| {
+                    import.path == **package || import.path.ends_with(&format!("/{package}"))
+                })
+                .or_else(|| candidates.first().filter(|_| candidates.len() == 1));
+            let Some(package) = package else {
+                continue;
+            };
+
+            let visible = import.alias.clone().unwrap_or_else(|| {
+                import
+                    .path
+                    .rsplit('/')
+                    .next()
+                    .unwrap_or(&import.path)
+                    .to_string()
+            });
+            for symbol in &all_symbols {
+                if symbol.module_path.as_deref() == Some(*package)
+                    && symbol.visibility == Visibility::Public
+                    && !matches!(symbol.kind, SymbolKind::Method | SymbolKind::Field)
+                    && self.is_resolvable_symbol(symbol)
+                {
+                    context.add_symbol(
+                        format!("{visible}.{}", symbol.name),
+                        symbol.id,
+                        crate::parsing::ScopeLevel::Package,
+                    );
+                }
+            }
+            if context.import_binding(&visible).is_none() {
+                context.register_import_binding(ImportBinding {
+                    import: import.clone(),
+                    exposed_name: visible,
+                    origin: ImportOrigin::Internal,
+                    resolved_symbol: None,
+                });
+            }
+            context.add_imported_package(import.path, package.to_string());
+        }
+        Ok(())
+    }
+
+    /// Scope a symbol of another file is visible in
+    ///
+    /// Symbols of other packages are imported and come after the package's
+    /// own: a bare `InitGlobalDirs()` calls the package's function even when
+    /// an imported package exports one of that name.
+    fn visible_scope_level(
+        symbol: &crate::Symbol,
+        own_package: Option<&str>,
+    ) -> crate::parsing::ScopeLevel {
+        if own_package.is_some() && symbol.module_path.as_deref() != own_package {
+            crate::parsing::ScopeLevel::Package
+        } else if symbol.visibility == Visibility::Public {
+            crate::parsing::ScopeLevel::Global
+        } else {
+            crate::parsing::ScopeLevel::Module
+        }
+    }
+
+    /// `Type.Method` name of a method symbol, from its receiver in the signature
+    fn qualified_method_name(symbol: &crate::Symbol) -> Option<String> {
+        if symbol.kind != crate::SymbolKind::Method {
+            return None;
+        }
+        let receiver = super::receiver_type_from_signature(symbol.signature.as_deref()?)?;
+        Some(format!("{receiver}.{}", symbol.name))
+    }
+
+    /// `Type.Method` names and promoted members of the types visible from
+    /// the file, which the cached context leaves out
+    ///
+    /// The cached context also lets names of other packages replace the
+    /// package's own; its declarations are put back on top.
+    fn add_type_members(
+        &self,
+        context: &mut GoResolutionContext,
+        file_id: FileId,
+        document_index: &DocumentIndex,
+    ) -> crate::error::IndexResult<()> {
+        use crate::error::IndexError;
+        use crate::parsing::ScopeLevel;
+
+        let all_symbols =
+            document_index
+                .get_all_symbols(10000)
+                .map_err(|e| IndexError::TantivyError {
+                    operation: "get_all_symbols".to_string(),
+                    cause: e.to_string(),
+                })?;
+        let own_package = self.get_module_path_for_file(file_id);
+        let mut methods = Vec::new();
+        let mut fields = Vec::new();
+        let mut embeds = Vec::new();
+        for symbol in &all_symbols {
+            let scope_level = if symbol.file_id == file_id {
+                ScopeLevel::Module
+            } else if self.is_symbol_visible_from_file(symbol, file_id) {
+                Self::visible_scope_level(symbol, own_package.as_deref())
+            } else {
+                continue;
+            };
+            if scope_level != ScopeLevel::Package
+                && !matches!(
+                    symbol.kind,
+                    crate::SymbolKind::Method | crate::SymbolKind::Field
+                )
+                && self.is_resolvable_symbol(symbol)
+            {
+                context.add_symbol(symbol.name.to_string(), symbol.id, scope_level);
+            }
+            if let Some(qualified) = Self::qualified_method_name(symbol) {
+                context.add_symbol(qualified.clone(), symbol.id, scope_level);
+                methods.push((qualified, symbol.id, scope_level));
+            }
+            if symbol.kind == crate::SymbolKind::Field {
+                Self::collect_field(symbol, scope_level, &mut fields, &mut embeds);
+            }
+        }
+        Self::add_promoted_members(context, &fields, &methods, &embeds);
+        Ok(())
+    }
+
+    /// Add the promoted fields and methods, and the ambiguous selectors
+    ///
+    /// Fields and methods of embedded structs (or pointers to them) are
+    /// promoted: `Application.jobQueue` resolves to `WorkerPool.jobQueue`
+    /// and `Person.SetAge` to `User.SetAge`. They share a selector
+    /// namespace: one promoted by two embedded types at the same depth
+    /// resolves to neither.
+    fn add_promoted_members(
+        context: &mut GoResolutionContext,
+        fields: &[Member],
+        methods: &[Member],
+        embeds: &[(String, String)],
+    ) {
+        let members: Vec<_> = fields.iter().chain(methods).cloned().collect();
+        let (promoted, ambiguous) = Self::promoted_members(&members, embeds);
+        for ((promoted, symbol_id, scope_level), _) in promoted {
+            context.add_symbol(promoted, symbol_id, scope_level);
+        }
+        for (selector, providers) in ambiguous {
+            context.add_ambiguous_selector(selector, providers);
+        }
+    }
+
+    /// Record a field symbol, and the embedding if the field is embedded
+    fn collect_field(
+        symbol: &crate::Symbol,
+        scope_level: crate::parsing::ScopeLevel,
+        fields: &mut Vec<(String, crate::SymbolId, crate::parsing::ScopeLevel)>,
+        embeds: &mut Vec<(String, String)>,
+    ) {
+        let Some((owner, _)) = symbol.name.split_once('.') else {
+            return;
+        };
+        if let Some(embedded) = symbol
+            .signature
+            .as_deref()
+            .and_then(|sig| super::embedded_field_type(&symbol.name, sig))
+        {
+            embeds.push((owner.to_string(), embedded.to_string()));
+        }
+        fields.push((symbol.name.to_string(), symbol.id, scope_level));
+    }
+
+    /// `Outer.member` names of the members promoted into each embedding
+    /// struct with the embedded type declaring them, and the ambiguous ones
+    /// with the embedded types providing them
+    ///
+    /// Members are `Type.member` fields or methods. Embedded structs are
+    /// searched breadth-first. A member declared on the struct itself hides
+    /// promoted ones, the shallowest promoted member wins, and two at the
+    /// same depth are ambiguous and not promoted.
+    fn promoted_members(
+        members: &[Member],
+        embeds: &[(String, String)],
+    ) -> (Vec<(Member, String)>, Vec<(String, Vec<String>)>) {
+        use std::collections::{HashMap, HashSet};
+
+        let mut by_owner: HashMap<&str, Vec<(&str, crate::SymbolId, crate::parsing::ScopeLevel)>> =
+            HashMap::new();
+        for (qualified, id, scope) in members {
+            if let Some((owner, field)) = qualified.split_once('.') {
+                by_owner
+                    .entry(owner)
+                    .or_default()
+                    .push((field, *id, *scope));
+            }
+        }
+        let embedded_in = |owner: &str| -> Vec<&str> {
+            embeds
+                .iter()
+                .filter(|(outer, _)| outer == owner)
+                .map(|(_, inner)| inner.as_str())
+                .collect()
+        };
+
+        let mut promoted = Vec::new();
+        let mut ambiguous = Vec::new();
+        let outers: HashSet<&str> = embeds.iter().map(|(outer, _)| outer.as_str()).collect();
+        for outer in outers {
+            // Names settled at a shallower depth, including the struct's own
+            let mut settled: HashSet<&str> = by_owner
+                .get(outer)
+                .map(|own| own.iter().map(|(field, _, _)| *field).collect())
+                .unwrap_or_default();
+            let mut visited: HashSet<&str> = HashSet::from([outer]);
+            let mut level = embedded_in(outer);
+            while !level.is_empty() {
+                let mut candidates: HashMap<
+                    &str,
+                    Vec<(crate::SymbolId, crate::parsing::ScopeLevel, &str)>,
+                > = HashMap::new();
+                let mut next = Vec::new();
+                for inner in level {
+                    if !visited.insert(inner) {
+                        continue;
+                    }
+                    for &(field, id, scope) in by_owner.get(inner).into_iter().flatten() {
+                        if !settled.contains(field) {
+                            candidates
+                                .entry(field)
+                                .or_default()
+                                .push((id, scope, inner));
+                        }
+                    }
+                    next.extend(embedded_in(inner));
+                }
+                for (field, found) in candidates {
+                    settled.insert(field);
+                    match found.as_slice() {
+                        [(id, scope, inner)] => {
+                            promoted.push((
+                                (format!("{outer}.{field}"), *id, *scope),
+                                inner.to_string(),
+                            ));
+                        }
+                        _ => {
+                            let mut providers: Vec<String> = found
+                                .iter()
+                                .map(|(_, _, inner)| inner.to_string())
+                                .collect();
+                            providers.sort();
+                            providers.dedup();
+                            ambiguous.push((format!("{outer}.{field}"), providers));
+                        }
+                    }
+                }
+                level = next;
+            }
+        }
+        (promoted, ambiguous)
+    }
+
+    /// Methods promoted into the struct `type_name` from the structs it
+    /// embeds, among `symbols` of one package
+    ///
+    /// Promotion follows the resolution context's rules: the struct's own
+    /// fields and methods hide promoted ones, and a name promoted by two
+    /// embedded types at the same depth is not promoted.
+    pub fn promoted_methods(
+        symbols: &[crate::Symbol],
+        type_name: &str,
+    ) -> Vec<crate::symbol::context::PromotedMethod> {
+        use std::collections::HashMap;
+
+        let mut members = Vec::new();
+        let mut embeds = Vec::new();
+        let mut methods: HashMap<SymbolId, &crate::Symbol> = HashMap::new();
+        for symbol in symbols {
+            if symbol.kind == crate::SymbolKind::Field {
+                Self::collect_field(
+                    symbol,
+                    crate::parsing::ScopeLevel::Module,
+                    &mut members,
+                    &mut embeds,
+                );
+            } else if let Some(qualified) = Self::qualified_method_name(symbol) {
+                members.push((qualified, symbol.id, crate::parsing::ScopeLevel::Module));
+                methods.insert(symbol.id, symbol);
+            }
+        }
+
+        let (promoted, _) = Self::promoted_members(&members, &embeds);
+        let mut promoted: Vec<_> = promoted
+            .into_iter()
+            .filter(|((qualified, _, _), _)| {
+                qualified
+                    .split_once('.')
+                    .is_some_and(|(outer, _)| outer == type_name)
+            })
+            .filter_map(|((_, id, _), origin)| {
+                Some(crate::symbol::context::PromotedMethod {
+                    method: (*methods.get(&id)?).clone(),
+                    origin,
+                })
+            })
+            .collect();
+        promoted.sort_by(|a, b| a.method.name.cmp(&b.method.name));
+        promoted
+    }
+
+    /// Alias and target of a `type Alias = Target` symbol
+    ///
+    /// A qualified target, `models.User`, is looked up through the imports of
+    /// the alias's file.
+    fn alias_and_target(&self, symbol: &crate::Symbol) -> Option<AliasTarget> {
+        if symbol.kind != crate::SymbolKind::TypeAlias {
+            return None;
+        }
+        let target = super::alias_target_from_signature(symbol.signature.as_deref()?)?;
+        let (import, target) = match target.rsplit_once('.') {
+            Some((qualifier, target)) => {
+                let imports = self.get_imports_for_file(symbol.file_id);
+                let import = qualifier_import_path(&imports, qualifier).unwrap_or(qualifier);
+                (Some(import.to_string()), target)
+            }
+            None => (None, target),
+        };
+        Some(AliasTarget {
+            alias: symbol.name.to_string(),
+            target: target.to_string(),
+            package: symbol.module_path.as_deref().map(str::to_string),
+            import,
+        })
+    }
+
     /// Get the current package path for relative import resolution
     ///
     /// This method extracts the package path from the current context.
@@ -832,4 +1497,46 @@ mod tests {
         assert_eq!(symbol.module_path.as_ref().map(|s| s.as_ref()), Some(".")); // Default to current package
         assert_eq!(symbol.visibility, Visibility::Private); // Should be private due to lowercase
     }
+
+    #[test]
+    fn test_promoted_fields() {
+        use crate::SymbolId;
+        use crate::parsing::ScopeLevel;
+
+        let field = |name: &str, id: u32| {
+            (
+                name.to_string(),
+                SymbolId::new(id).unwrap(),
+                ScopeLevel::Module,
+            )
+        };
+        let fields = vec![
+            field("WorkerPool.jobQueue", 1),
+            field("WorkerPool.workers", 2),
+            field("Application.WorkerPool", 3),
+            field("Application.workers", 4),
+            field("Reader.Close", 5),
+            field("Writer.Close", 6),
+        ];
+        let embeds = vec![
+            ("Application".to_string(), "WorkerPool".to_string()),
+            ("File".to_string(), "Reader".to_string()),
+            ("File".to_string(), "Writer".to_string()),
+        ];
+
+        let (promoted, ambiguous) = GoBehavior::promoted_members(&fields, &embeds);
+        let names: Vec<_> = promoted
+            .iter()
+            .map(|((name, id, _), origin)| (name.as_str(), id.value(), origin.as_str()))
+            .collect();
+        // Own fields hide promoted ones; same-depth duplicates are ambiguous
+        assert_eq!(names, vec![("Application.jobQueue", 1, "WorkerPool")]);
+        assert_eq!(
+            ambiguous,
+            vec![(
+                "File.Close".to_string(),
+                vec!["Reader".to_string(), "Writer".to_string()]
+            )]
+        );
+    }
 }
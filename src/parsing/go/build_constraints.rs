@@ -0,0 +1,460 @@
+//! Go build constraints, deciding which files a build includes
+//!
+//! A file is only compiled when the constraint at its top holds for the
+//! build's tags:
+//!
+//! ```go
+//! //go:build linux && (amd64 || arm64) && !cgo
+//!
+//! // +build linux,amd64 linux,arm64
+//! // +build !cgo
+//!
+//! package sys
+//! ```
+//!
+//! `//go:build` lines take boolean expressions over tags. In the legacy
+//! `// +build` form, space-separated options are alternatives, comma-separated
+//! terms must all hold and several lines must all hold. When a file has both,
+//! the `//go:build` line decides, as with the Go toolchain. Constraints are
+//! only read from the comments and blank lines before the package clause;
+//! `/* */` comments there are skipped.
+//!
+//! As with `go/build`, the configured tags imply others: `gc` for the
+//! compiler, every release tag from `go1.1` up, `unix` for Unix-like
+//! systems, and `linux` for `android`, `darwin` for `ios` and `solaris` for
+//! `illumos`.
+
+use std::collections::HashSet;
+use std::io::BufRead;
+use std::path::Path;
+
+/// `GOOS` values
+pub const KNOWN_OS: &[&str] = &[
+    "aix",
+    "android",
+    "darwin",
+    "dragonfly",
+    "freebsd",
+    "hurd",
+    "illumos",
+    "ios",
+    "js",
+    "linux",
+    "nacl",
+    "netbsd",
+    "openbsd",
+    "plan9",
+    "solaris",
+    "wasip1",
+    "windows",
+    "zos",
+];
+
+/// `GOARCH` values
+pub const KNOWN_ARCH: &[&str] = &[
+    "386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le", "mipsle", "ppc64",
+    "ppc64le", "riscv64", "s390x", "wasm",
+];
+
+/// `GOOS` values satisfying the `unix` tag
+const UNIX_OS: &[&str] = &[
+    "aix",
+    "android",
+    "darwin",
+    "dragonfly",
+    "freebsd",
+    "hurd",
+    "illumos",
+    "ios",
+    "linux",
+    "netbsd",
+    "openbsd",
+    "solaris",
+];
+
+/// `GOOS` values implying another one
+const IMPLIED_OS: &[(&str, &str)] = &[
+    ("android", "linux"),
+    ("illumos", "solaris"),
+    ("ios", "darwin"),
+];
+
+/// Latest Go release whose `go1.N` tag builds satisfy
+const LATEST_GO_MINOR: u32 = 25;
+
+/// A build constraint expression
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum BuildConstraint {
+    Tag(String),
+    Not(Box<BuildConstraint>),
+    And(Box<BuildConstraint>, Box<BuildConstraint>),
+    Or(Box<BuildConstraint>, Box<BuildConstraint>),
+}
+
+impl BuildConstraint {
+    /// Parse a `//go:build` expression such as `linux && !cgo`
+    pub fn parse(expression: &str) -> Result<Self, String> {
+        let tokens = tokenize(expression)?;
+        let mut parser = ExpressionParser {
+            tokens: &tokens,
+            position: 0,
+        };
+        let constraint = parser.or()?;
+        match parser.tokens.get(parser.position) {
+            None => Ok(constraint),
+            Some(token) => Err(format!("unexpected '{token}' in build constraint")),
+        }
+    }
+
+    /// Parse the options of one legacy `// +build` line such as
+    /// `linux,amd64 darwin,!cgo`
+    pub fn parse_legacy(line: &str) -> Result<Self, String> {
+        let term = |term: &str| -> Result<Self, String> {
+            let (negated, tag) = match term.strip_prefix('!') {
+                Some(tag) => (true, tag),
+                None => (false, term),
+            };
+            if tag.is_empty() || !tag.chars().all(is_tag_char) {
+                return Err(format!("invalid build tag '{term}'"));
+            }
+            let tag = Self::Tag(tag.to_string());
+            Ok(if negated {
+                Self::Not(Box::new(tag))
+            } else {
+                tag
+            })
+        };
+        let option = |option: &str| -> Result<Self, String> {
+            option
+                .split(',')
+                .map(term)
+                .reduce(|a, b| Ok(Self::And(Box::new(a?), Box::new(b?))))
+                .unwrap_or_else(|| Err("empty build option".to_string()))
+        };
+        line.split_whitespace()
+            .map(option)
+            .reduce(|a, b| Ok(Self::Or(Box::new(a?), Box::new(b?))))
+            .unwrap_or_else(|| Err("empty +build line".to_string()))
+    }
+
+    /// Whether the constraint holds when exactly `tags` are set
+    pub fn eval(&self, tags: &HashSet<&str>) -> bool {
+        match self {
+            Self::Tag(tag) => tags.contains(tag.as_str()),
+            Self::Not(inner) => !inner.eval(tags),
+            Self::And(a, b) => a.eval(tags) && b.eval(tags),
+            Self::Or(a, b) => a.eval(tags) || b.eval(tags),
+        }
+    }
+}
+
+/// A line of the header of a Go file, before its package clause
+enum HeaderLine<'l> {
+    /// Blank, or only `/* */` comment text
+    Blank,
+    /// A `//` comment, without the slashes
+    Comment(&'l str),
+    /// The package clause or anything else ending the header
+    End,
+}
+
+/// Classifies header lines in order, following `/* */` comments across lines
+#[derive(Default)]
+struct HeaderScanner {
+    in_block: bool,
+}
+
+impl HeaderScanner {
+    fn line<'l>(&mut self, line: &'l str) -> HeaderLine<'l> {
+        let mut line = line.trim();
+        loop {
+            if self.in_block {
+                match line.find("*/") {
+                    Some(end) => {
+                        self.in_block = false;
+                        line = line[end + 2..].trim_start();
+                    }
+                    None => return HeaderLine::Blank,
+                }
+            }
+            match line.strip_prefix("/*") {
+                Some(rest) => {
+                    self.in_block = true;
+                    line = rest;
+                }
+                None => break,
+            }
+        }
+        if line.is_empty() {
+            HeaderLine::Blank
+        } else if let Some(comment) = line.strip_prefix("//") {
+            HeaderLine::Comment(comment)
+        } else {
+            HeaderLine::End
+        }
+    }
+}
+
+/// Read the header of a Go file, the lines up to its package clause, which
+/// is all [`file_build_constraint`] looks at
+pub fn read_file_header(path: &Path) -> std::io::Result<String> {
+    let reader = std::io::BufReader::new(std::fs::File::open(path)?);
+    let mut scanner = HeaderScanner::default();
+    let mut header = String::new();
+    for line in reader.lines() {
+        let line = line?;
+        if matches!(scanner.line(&line), HeaderLine::End) {
+            break;
+        }
+        header.push_str(&line);
+        header.push('\n');
+    }
+    Ok(header)
+}
+
+/// Build constraint of a Go file, `None` when it has none
+///
+/// A malformed constraint is reported as an error rather than guessed at.
+pub fn file_build_constraint(source: &str) -> Result<Option<BuildConstraint>, String> {
+    let mut legacy: Option<BuildConstraint> = None;
+    let mut scanner = HeaderScanner::default();
+    for line in source.lines() {
+        let comment = match scanner.line(line) {
+            HeaderLine::Blank => continue,
+            HeaderLine::Comment(comment) => comment,
+            HeaderLine::End => break,
+        };
+        if let Some(expression) = comment.strip_prefix("go:build") {
+            if expression.starts_with([' ', '\t']) {
+                return BuildConstraint::parse(expression).map(Some);
+            }
+        } else if let Some(options) = comment.trim_start().strip_prefix("+build") {
+            if options.is_empty() || options.starts_with([' ', '\t']) {
+                let options = BuildConstraint::parse_legacy(options)?;
+                legacy = Some(match legacy {
+                    Some(previous) => BuildConstraint::And(Box::new(previous), Box::new(options)),
+                    None => options,
+                });
+            }
+        }
+    }
+    Ok(legacy)
+}
+
+/// Whether a Go file is part of a build with `tags`, and the tags they imply
+///
+/// Files without a constraint are always included, and so are files whose
+/// constraint can't be parsed: indexing too much beats silently losing a file.
+pub fn satisfies_build_tags<S: AsRef<str>>(source: &str, tags: &[S]) -> bool {
+    let tags = implied_build_tags(tags);
+    let tags: HashSet<&str> = tags.iter().map(String::as_str).collect();
+    match file_build_constraint(source) {
+        Ok(Some(constraint)) => constraint.eval(&tags),
+        Ok(None) | Err(_) => true,
+    }
+}
+
+/// `tags` with the tags `go/build` sets alongside them: the compiler,
+/// release tags, `unix` and the systems a `GOOS` implies
+pub fn implied_build_tags<S: AsRef<str>>(tags: &[S]) -> HashSet<String> {
+    let mut implied: HashSet<String> = tags.iter().map(|t| t.as_ref().to_string()).collect();
+    if !implied.contains("gccgo") {
+        implied.insert("gc".to_string());
+    }
+    implied.extend((1..=LATEST_GO_MINOR).map(|minor| format!("go1.{minor}")));
+    for &(os, implies) in IMPLIED_OS {
+        if implied.contains(os) {
+            implied.insert(implies.to_string());
+        }
+    }
+    if UNIX_OS.iter().any(|os| implied.contains(*os)) {
+        implied.insert("unix".to_string());
+    }
+    implied
+}
+
+/// Tags of a `--build-tags` value, `linux,amd64` or the legacy `linux amd64`
+pub fn parse_build_tags(value: &str) -> Vec<String> {
+    value
+        .split([',', ' '])
+        .map(str::trim)
+        .filter(|tag| !tag.is_empty())
+        .map(str::to_string)
+        .collect()
+}
+
+fn is_tag_char(c: char) -> bool {
+    c.is_alphanumeric() || c == '_' || c == '.'
+}
+
+/// Split a `//go:build` expression into tags, operators and parentheses
+fn tokenize(expression: &str) -> Result<Vec<String>, String> {
+    let mut tokens = Vec::new();
+    let mut chars = expression.chars().peekable();
+    while let Some(c) = chars.next() {
+        match c {
+            ' ' | '\t' => {}
+            '!' | '(' | ')' => tokens.push(c.to_string()),
+            '&' | '|' => {
+                if chars.next() != Some(c) {
+                    return Err(format!("expected '{c}{c}' in build constraint"));
+                }
+                tokens.push(format!("{c}{c}"));
+            }
+            c if is_tag_char(c) => {
+                let mut tag = c.to_string();
+                while let Some(&next) = chars.peek().filter(|&&next| is_tag_char(next)) {
+                    tag.push(next);
+                    chars.next();
+                }
+                tokens.push(tag);
+            }
+            _ => return Err(format!("unexpected '{c}' in build constraint")),
+        }
+    }
+    Ok(tokens)
+}
+
+/// Recursive descent over `||`, then `&&`, then `!` and parentheses
+struct ExpressionParser<'t> {
+    tokens: &'t [String],
+    position: usize,
+}
+
+impl ExpressionParser<'_> {
+    fn next_is(&self, token: &str) -> bool {
+        self.tokens.get(self.position).is_some_and(|t| t == token)
+    }
+
+    fn or(&mut self) -> Result<BuildConstraint, String> {
+        let mut constraint = self.and()?;
+        while self.next_is("||") {
+            self.position += 1;
+            constraint = BuildConstraint::Or(Box::new(constraint), Box::new(self.and()?));
+        }
+        Ok(constraint)
+    }
+
+    fn and(&mut self) -> Result<BuildConstraint, String> {
+        let mut constraint = self.not()?;
+        while self.next_is("&&") {
+            self.position += 1;
+            constraint = BuildConstraint::And(Box::new(constraint), Box::new(self.not()?));
+        }
+        Ok(constraint)
+    }
+
+    fn not(&mut self) -> Result<BuildConstraint, String> {
+        let Some(token) = self.tokens.get(self.position) else {
+            return Err("unexpected end of build constraint".to_string());
+        };
+        self.position += 1;
+        match token.as_str() {
+            "!" => Ok(BuildConstraint::Not(Box::new(self.not()?))),
+            "(" => {
+                let constraint = self.or()?;
+                if !self.next_is(")") {
+                    return Err("missing ')' in build constraint".to_string());
+                }
+                self.position += 1;
+                Ok(constraint)
+            }
+            tag if tag.chars().all(is_tag_char) => Ok(BuildConstraint::Tag(tag.to_string())),
+            other => Err(format!("unexpected '{other}' in build constraint")),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_go_build_expressions() {
+        let constraint = BuildConstraint::parse("linux && (amd64 || arm64) && !cgo").unwrap();
+        let eval = |tags: &[&str]| constraint.eval(&tags.iter().copied().collect());
+        assert!(eval(&["linux", "amd64"]));
+        assert!(eval(&["linux", "arm64"]));
+        assert!(!eval(&["linux", "amd64", "cgo"]));
+        assert!(!eval(&["darwin", "amd64"]));
+
+        assert!(BuildConstraint::parse("linux &&").is_err());
+        assert!(BuildConstraint::parse("(linux").is_err());
+        assert!(BuildConstraint::parse("linux & amd64").is_err());
+    }
+
+    #[test]
+    fn test_legacy_build_lines() {
+        let source =
+            "// Copyright notice\n\n// +build linux,amd64 darwin\n// +build !cgo\n\npackage sys\n";
+        assert!(satisfies_build_tags(source, &["linux", "amd64"]));
+        assert!(satisfies_build_tags(source, &["darwin"]));
+        assert!(!satisfies_build_tags(source, &["linux"]));
+        assert!(!satisfies_build_tags(source, &["darwin", "cgo"]));
+
+        // The go:build line wins over the legacy lines
+        let both = "//go:build windows\n// +build linux\n\npackage sys\n";
+        assert!(satisfies_build_tags(both, &["windows"]));
+        assert!(!satisfies_build_tags(both, &["linux"]));
+    }
+
+    #[test]
+    fn test_implied_tags() {
+        let tags = parse_build_tags("linux,amd64");
+        assert!(satisfies_build_tags(
+            "//go:build unix && gc\n\npackage sys\n",
+            &tags
+        ));
+        assert!(satisfies_build_tags(
+            "//go:build go1.18\n\npackage sys\n",
+            &tags
+        ));
+        assert!(!satisfies_build_tags(
+            "//go:build go1.999\n\npackage sys\n",
+            &tags
+        ));
+        assert!(!satisfies_build_tags(
+            "//go:build unix\n\npackage sys\n",
+            &["windows"]
+        ));
+        assert!(satisfies_build_tags(
+            "//go:build linux\n\npackage sys\n",
+            &["android"]
+        ));
+        assert!(!satisfies_build_tags(
+            "//go:build gc\n\npackage sys\n",
+            &["gccgo"]
+        ));
+    }
+
+    #[test]
+    fn test_block_comments_before_constraint() {
+        let tags = ["linux"];
+        let source = "/*\n * Copyright notice\n */\n\n//go:build windows\n\npackage sys\n";
+        assert!(!satisfies_build_tags(source, &tags));
+        let inline = "/* generated */\n//go:build windows\n\npackage sys\n";
+        assert!(!satisfies_build_tags(inline, &tags));
+        // A constraint inside a block comment is not one
+        let commented = "/*\n//go:build windows\n*/\n\npackage sys\n";
+        assert!(satisfies_build_tags(commented, &tags));
+    }
+
+    #[test]
+    fn test_files_included_and_excluded_by_tags() {
+        let tags = parse_build_tags("linux,amd64");
+        assert_eq!(tags, vec!["linux", "amd64"]);
+
+        let included = "//go:build linux || darwin\n\npackage sys\n";
+        let excluded = "// +build ignore\n\npackage main\n";
+        let unconstrained = "// Package sys talks to the kernel\npackage sys\n";
+        assert!(satisfies_build_tags(included, &tags));
+        assert!(!satisfies_build_tags(excluded, &tags));
+        assert!(satisfies_build_tags(unconstrained, &tags));
+
+        // Only the header counts, and a space after // makes a plain comment
+        let late = "package sys\n\n//go:build windows\n";
+        let spaced = "// go:build windows\n\npackage sys\n";
+        assert!(satisfies_build_tags(late, &tags));
+        assert!(satisfies_build_tags(spaced, &tags));
+    }
+}
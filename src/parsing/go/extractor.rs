@@ -0,0 +1,188 @@
+//! Pluggable relationship extractors for Go
+//!
+//! The built-in relationship kinds (calls, uses, implements, ...) cover what the
+//! indexer understands natively. Extractors let users add their own kinds
+//! without forking: an [`Extractor`] runs over a parsed [`GoSourceFile`] and
+//! emits [`CustomRelationship`]s tagged with a free-form kind such as
+//! `reads-env-var`.
+//!
+//! The indexer runs the built-in extractors, and any added with
+//! `SimpleIndexer::register_extractor`, over every Go file it indexes. Their
+//! relationships are stored by target name, tagged with the extractor's kind
+//! (see `RelationshipMetadata::custom_kind`), and can be queried back with
+//! `SimpleIndexer::get_custom_related_symbols`.
+//!
+//! ```rust,no_run
+//! use codanna::parsing::go::analysis::GoSourceFile;
+//! use codanna::parsing::go::extractor::ExtractorRegistry;
+//!
+//! let registry = ExtractorRegistry::with_builtin();
+//! let file = GoSourceFile::read(std::path::Path::new("config/settings.go")).unwrap();
+//! for rel in registry.extract_all(&file) {
+//!     println!("{} -[{}]-> {}", rel.from, rel.kind, rel.to);
+//! }
+//! ```
+
+use super::analysis::env_vars::env_var_reads_in_file;
+use super::analysis::{ConstantTable, GoSourceFile};
+use serde::Serialize;
+
+/// A relationship produced by a custom extractor
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct CustomRelationship {
+    /// Free-form relationship kind, e.g. `reads-env-var`
+    pub kind: String,
+    /// Enclosing function, or `<package>` for package-level code
+    pub from: String,
+    /// Target of the relationship as understood by the extractor
+    pub to: String,
+    pub file: String,
+    /// 1-based line number
+    pub line: u32,
+}
+
+/// A custom relationship extractor over a Go syntax tree
+pub trait Extractor: Send + Sync {
+    /// Unique extractor name
+    fn name(&self) -> &str;
+
+    /// Extract relationships from one parsed file
+    fn extract(&self, file: &GoSourceFile) -> Vec<CustomRelationship>;
+}
+
+/// Ordered collection of registered extractors
+#[derive(Default)]
+pub struct ExtractorRegistry {
+    extractors: Vec<Box<dyn Extractor>>,
+}
+
+impl ExtractorRegistry {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Registry with the extractors shipped with codanna
+    pub fn with_builtin() -> Self {
+        let mut registry = Self::new();
+        registry.register(Box::new(EnvVarExtractor));
+        registry
+    }
+
+    /// Register an extractor, replacing any previous one with the same name
+    pub fn register(&mut self, extractor: Box<dyn Extractor>) {
+        self.extractors.retain(|e| e.name() != extractor.name());
+        self.extractors.push(extractor);
+    }
+
+    /// Names of the registered extractors, in registration order
+    pub fn names(&self) -> Vec<&str> {
+        self.extractors.iter().map(|e| e.name()).collect()
+    }
+
+    /// Run every registered extractor over `file`
+    pub fn extract_all(&self, file: &GoSourceFile) -> Vec<CustomRelationship> {
+        self.extractors
+            .iter()
+            .flat_map(|e| e.extract(file))
+            .collect()
+    }
+}
+
+/// Example extractor: records `os.Getenv`/`os.LookupEnv` reads as `reads-env-var`
+///
+/// Keys built from constants declared in the same file are folded.
+pub struct EnvVarExtractor;
+
+impl Extractor for EnvVarExtractor {
+    fn name(&self) -> &str {
+        "env-vars"
+    }
+
+    fn extract(&self, file: &GoSourceFile) -> Vec<CustomRelationship> {
+        let constants = ConstantTable::build(std::slice::from_ref(file));
+        env_var_reads_in_file(file, &constants)
+            .into_iter()
+            .map(|read| CustomRelationship {
+                kind: "reads-env-var".to_string(),
+                from: read.function.unwrap_or_else(|| "<package>".to_string()),
+                to: read.name,
+                file: read.file,
+                line: read.line,
+            })
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::parsing::go::analysis::{enclosing_function_name, line_of, walk_tree};
+
+    struct PanicExtractor;
+
+    impl Extractor for PanicExtractor {
+        fn name(&self) -> &str {
+            "panics"
+        }
+
+        fn extract(&self, file: &GoSourceFile) -> Vec<CustomRelationship> {
+            let mut relationships = Vec::new();
+            walk_tree(file.root(), &mut |node| {
+                if node.kind() == "call_expression"
+                    && node
+                        .child_by_field_name("function")
+                        .is_some_and(|f| file.text(f) == "panic")
+                {
+                    relationships.push(CustomRelationship {
+                        kind: "panics".to_string(),
+                        from: enclosing_function_name(file, node).unwrap_or_default(),
+                        to: "panic".to_string(),
+                        file: file.display_path(),
+                        line: line_of(node),
+                    });
+                }
+            });
+            relationships
+        }
+    }
+
+    #[test]
+    fn test_registry_runs_builtin_and_custom_extractors() {
+        let code = r#"
+package config
+
+import "os"
+
+func Load() string {
+    if os.Getenv("APP_DEBUG") == "" {
+        panic("missing")
+    }
+    return os.Getenv(EnvPrefix + "PORT")
+}
+"#;
+        let file = GoSourceFile::parse("settings.go", code.to_string()).unwrap();
+
+        let mut registry = ExtractorRegistry::with_builtin();
+        registry.register(Box::new(PanicExtractor));
+        assert_eq!(registry.names(), vec!["env-vars", "panics"]);
+
+        let relationships = registry.extract_all(&file);
+        let env: Vec<_> = relationships
+            .iter()
+            .filter(|r| r.kind == "reads-env-var")
+            .map(|r| (r.from.as_str(), r.to.as_str(), r.line))
+            .collect();
+        assert_eq!(
+            env,
+            vec![
+                ("Load", "APP_DEBUG", 7),
+                ("Load", "EnvPrefix + \"PORT\"", 10),
+            ]
+        );
+        assert!(
+            relationships
+                .iter()
+                .any(|r| r.kind == "panics" && r.from == "Load" && r.line == 8)
+        );
+    }
+}
@@ -38,6 +38,8 @@
 //! - [`behavior`]: Go-specific language behaviors and formatting rules
 //! - [`definition`]: Language registration and Tree-sitter node mappings
 //! - [`resolution`]: Symbol resolution, scope management, and type system integration
+//! - [`analysis`]: Source-level analyses over Go syntax trees
+//! - [`extractor`]: Pluggable extractors for custom relationship kinds
 //!
 //! ## Integration
 //!
@@ -69,16 +71,26 @@
 //! - `tests/fixtures/go/` for comprehensive code examples
 //! - [`parser`] module for symbol extraction implementation details
 
+pub mod analysis;
 pub mod audit;
 pub mod behavior;
+pub mod build_constraints;
 pub mod definition;
+pub mod extractor;
 pub mod parser;
 pub mod resolution;
 
 pub use behavior::GoBehavior;
+pub use build_constraints::{
+    BuildConstraint, parse_build_tags, read_file_header, satisfies_build_tags,
+};
 pub use definition::GoLanguage;
-pub use parser::GoParser;
-pub use resolution::{GoInheritanceResolver, GoResolutionContext};
+pub use parser::{
+    GoParser, GoVariableBinding, alias_instantiation, alias_target_from_signature,
+    embedded_field_type, method_receiver, receiver_type_from_signature, receiver_type_parameters,
+    type_parameters_from_signature,
+};
+pub use resolution::{GoInheritanceResolver, GoResolutionContext, RelativeImport};
 
 // Re-export for registry registration
 pub(crate) use definition::register;
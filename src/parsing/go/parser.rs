@@ -11,12 +11,429 @@ use crate::parsing::{
     HandledNode, LanguageParser, MethodCall, NodeTracker, NodeTrackingState, ParserContext,
     ScopeType,
 };
+use crate::symbol::{MethodReceiver, ReceiverKind};
 use crate::types::SymbolCounter;
 use crate::{FileId, Range, Symbol, SymbolKind, Visibility};
 use std::any::Any;
-use tree_sitter::{Node, Parser};
+use tree_sitter::{Node, Parser, Point};
+
+use super::analysis::{LocalDeclaration, local_declarations};
+use super::resolution::{GoResolutionContext, stdlib_constructor_type};
+
+/// Receiver base type of a Go method signature
+///
+/// `func (r UserRole) String() string` gives `UserRole`, and pointer or
+/// generic receivers such as `func (s *Stack[T]) Push(v T)` give `Stack`.
+/// Returns `None` for functions without a receiver.
+pub fn receiver_type_from_signature(signature: &str) -> Option<&str> {
+    let rest = signature.trim_start().strip_prefix("func")?.trim_start();
+    let rest = rest.strip_prefix('(')?;
+    let receiver = &rest[..rest.find(')')?];
+    let type_text = receiver.split_whitespace().last()?;
+    let type_text = type_text.trim_start_matches('*');
+    let base = type_text.split('[').next().unwrap_or(type_text);
+    (!base.is_empty()).then_some(base)
+}
+
+/// Receiver of a Go method signature: its variable, whether it is a pointer,
+/// and its base type
+///
+/// `func (u *User) SetAge(age int)` gives `u`, pointer, `User`;
+/// `func (User) Name() string` gives an unnamed value receiver. Returns
+/// `None` for functions without a receiver.
+pub fn method_receiver(signature: &str) -> Option<MethodReceiver> {
+    let rest = signature.trim_start().strip_prefix("func")?.trim_start();
+    let rest = rest.strip_prefix('(')?;
+    let receiver = &rest[..rest.find(')')?];
+    let mut words = receiver.split_whitespace();
+    let (name, type_text) = match (words.next()?, words.next()) {
+        // `(Map[K, V])` splits inside the type arguments
+        (type_text, _) if type_text.contains('[') => (None, type_text),
+        (type_text, None) => (None, type_text),
+        (name, Some(type_text)) => ((name != "_").then(|| name.to_string()), type_text),
+    };
+    let kind = if type_text.starts_with('*') {
+        ReceiverKind::Pointer
+    } else {
+        ReceiverKind::Value
+    };
+    Some(MethodReceiver {
+        name,
+        kind,
+        type_name: receiver_type_from_signature(signature)?.to_string(),
+    })
+}
+
+/// Type parameters of the receiver of a Go method signature
+///
+/// `func (m *Map[K, V]) Set(key K, value V)` gives `["K", "V"]`. Returns
+/// nothing for non-generic receivers and functions without a receiver.
+pub fn receiver_type_parameters(signature: &str) -> Vec<&str> {
+    let receiver = signature
+        .trim_start()
+        .strip_prefix("func")
+        .map(str::trim_start)
+        .and_then(|rest| rest.strip_prefix('('))
+        .and_then(|rest| rest.find(')').map(|end| &rest[..end]));
+    let Some(parameters) = receiver
+        .and_then(|receiver| receiver.find('[').map(|start| &receiver[start..]))
+        .and_then(|list| balanced_contents(list, '[', ']'))
+    else {
+        return Vec::new();
+    };
+    parameters
+        .split(',')
+        .filter_map(|parameter| parameter.split_whitespace().next())
+        .collect()
+}
+
+/// Type parameters declared by a Go function or type signature, as
+/// (name, constraint) pairs
+///
+/// `func Max[T Comparable](a, b T) T` gives `[("T", "Comparable")]` and
+/// `Map[K comparable, V any] struct` gives `[("K", "comparable"), ("V",
+/// "any")]`. Constraints are kept as written, so a union constraint gives
+/// `~int | ~float64`. For a method only its own list counts:
+/// `func (r *Repository[T]) Transform[U any](...)` gives `[("U", "any")]`,
+/// the receiver's `T` being [`receiver_type_parameters`].
+pub fn type_parameters_from_signature(signature: &str) -> Vec<(&str, &str)> {
+    let rest = signature.trim_start();
+    let rest = ["func", "type"]
+        .iter()
+        .find_map(|keyword| {
+            rest.strip_prefix(keyword)
+                .filter(|after| after.starts_with(char::is_whitespace))
+        })
+        .map_or(rest, str::trim_start);
+    let rest = match balanced_contents(rest, '(', ')') {
+        Some(receiver) => rest[receiver.len() + 2..].trim_start(),
+        None => rest,
+    };
+    let name_end = rest
+        .find(|c: char| !(c.is_alphanumeric() || c == '_'))
+        .unwrap_or(rest.len());
+    if name_end == 0 {
+        return Vec::new();
+    }
+    balanced_contents(&rest[name_end..], '[', ']')
+        .map(type_parameter_list)
+        .unwrap_or_default()
+}
+
+/// (name, constraint) pairs of a type parameter list without its
+/// brackets: in `T, U any` both names share the constraint
+pub(super) fn type_parameter_list(list: &str) -> Vec<(&str, &str)> {
+    let mut parameters = Vec::new();
+    let mut pending = Vec::new();
+    for item in split_top_level(list) {
+        match item.split_once(char::is_whitespace) {
+            Some((name, constraint)) => {
+                let constraint = constraint.trim();
+                parameters.extend(pending.drain(..).map(|pending| (pending, constraint)));
+                parameters.push((name, constraint));
+            }
+            None => pending.push(item),
+        }
+    }
+    parameters
+}
+
+/// Target base type of a Go type alias signature
+///
+/// `PublicInnerStruct = InnerStruct` gives `InnerStruct`; pointer and generic
+/// targets lose their `*` and type arguments but keep their package, so
+/// `Ptr = *models.Stack[int]` gives `models.Stack`. Returns `None` for
+/// defined types (`type Named InnerStruct`), which don't share the methods
+/// of their underlying type.
+pub fn alias_target_from_signature(signature: &str) -> Option<&str> {
+    let (_, target) = signature.split_once('=')?;
+    let target = target.trim().trim_start_matches('*');
+    let base = target.split('[').next().unwrap_or(target).trim();
+    (!base.is_empty()).then_some(base)
+}
+
+/// Target of a Go type alias signature with `arguments` bound to the
+/// alias's type parameters
+///
+/// `StringMap[V any] = Map[string, V]` with `int` gives `Map[string, int]`,
+/// and `IntSet = Map[int, struct{}]` with no arguments its target as
+/// written. Returns `None` for defined types and when the number of
+/// arguments differs from the alias's type parameters.
+pub fn alias_instantiation(signature: &str, arguments: &str) -> Option<String> {
+    let (head, target) = signature.split_once('=')?;
+    let parameters = type_parameters_from_signature(head);
+    let arguments = split_top_level(arguments);
+    if parameters.len() != arguments.len() {
+        return None;
+    }
+    let target = target.trim();
+    let mut instantiated = String::with_capacity(target.len());
+    let mut word = String::new();
+    for c in target.chars().chain(std::iter::once(' ')) {
+        if c.is_alphanumeric() || c == '_' {
+            word.push(c);
+            continue;
+        }
+        match parameters.iter().position(|(name, _)| *name == word) {
+            Some(index) => instantiated.push_str(arguments[index]),
+            None => instantiated.push_str(&word),
+        }
+        word.clear();
+        instantiated.push(c);
+    }
+    instantiated.pop();
+    Some(instantiated)
+}
+
+/// Embedded type of a Go struct field symbol
+///
+/// Embedded fields are named after their type and carry the type alone as
+/// signature: field `Application.WorkerPool` with signature `*WorkerPool`
+/// gives `WorkerPool`. Returns `None` for named fields (`jobQueue chan Job`).
+pub fn embedded_field_type<'s>(name: &str, signature: &'s str) -> Option<&'s str> {
+    let type_text = signature.trim();
+    let head = type_text.split('[').next().unwrap_or(type_text);
+    if head.contains(char::is_whitespace) {
+        return None;
+    }
+    let base = head.trim_start_matches('*');
+    let base = base.rsplit('.').next().unwrap_or(base);
+    let field = name.rsplit('.').next().unwrap_or(name);
+    (!base.is_empty() && base == field).then_some(base)
+}
+
+/// Fields of the structs declared in one file, keyed by struct name
+type StructLayout<'a> = std::collections::HashMap<&'a str, Vec<StructField<'a>>>;
+
+/// A struct field with the base type of its values, and of their elements
+/// for a container field
+#[derive(Debug, Clone, Copy)]
+struct StructField<'a> {
+    name: &'a str,
+    type_name: Option<&'a str>,
+    element_type: Option<&'a str>,
+    embedded: bool,
+}
+
+/// A variable with the types known for it
+///
+/// `declared_type` is the type the variable was declared with and
+/// `concrete_type` the type of the value assigned to it; they differ when a
+/// concrete value is assigned to an interface variable.
+///
+/// Types are base names, so `&Stack[string]{}` gives `Stack`; the type
+/// arguments of an instantiated generic type are kept in `type_arguments`
+/// as written between the brackets (`string`, `int, string`).
+///
+/// Slices, arrays, maps and channels keep their type as written
+/// (`[]User`), and the base type of their elements in `element_type`
+/// (`User`), which types index expressions and `range` loops over them.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct GoVariableBinding<'a> {
+    pub name: &'a str,
+    pub declared_type: Option<&'a str>,
+    pub concrete_type: Option<&'a str>,
+    pub type_arguments: Option<&'a str>,
+    pub element_type: Option<&'a str>,
+    pub range: Range,
+}
+
+impl<'a> GoVariableBinding<'a> {
+    /// Most specific type known: the concrete type, else the declared one
+    pub fn narrowed_type(&self) -> Option<&'a str> {
+        self.concrete_type.or(self.declared_type)
+    }
+
+    /// Type arguments of the variable's generic type, one per parameter
+    pub fn type_argument_list(&self) -> Vec<&'a str> {
+        self.type_arguments.map(split_top_level).unwrap_or_default()
+    }
+
+    /// Type parameters of a method bound by a call on this variable
+    ///
+    /// With `s := &Stack[string]{}`, calling `func (s *Stack[T]) Push(item T)`
+    /// binds `T` to `string`. Returns nothing when the variable's type is not
+    /// an instantiation or the receiver takes a different number of
+    /// parameters.
+    pub fn receiver_type_bindings<'s>(&self, method_signature: &'s str) -> Vec<(&'s str, &'a str)> {
+        let parameters = receiver_type_parameters(method_signature);
+        let arguments = self.type_argument_list();
+        if parameters.len() != arguments.len() {
+            return Vec::new();
+        }
+        parameters.into_iter().zip(arguments).collect()
+    }
+}
+
+/// Integer types that can be ranged over (Go 1.22)
+const GO_INTEGER_TYPES: &[&str] = &[
+    "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+    "uintptr", "byte", "rune",
+];
+
+/// Types passed to `yield` by an iterator function type
+///
+/// Handles `func(yield func(K, V) bool)` (with or without the parameter name)
+/// and the `iter.Seq[V]` / `iter.Seq2[K, V]` aliases. Returns `None` when
+/// the type is not an iterator.
+fn iterator_yield_types(type_text: &str) -> Option<Vec<String>> {
+    let type_text = type_text.trim();
+
+    if let Some(rest) = type_text.strip_prefix("func") {
+        let params = balanced_contents(rest.trim_start(), '(', ')')?;
+        let yield_param = params.trim();
+        // Drop the parameter name, if any
+        let yield_type = match yield_param.split_once(char::is_whitespace) {
+            Some((name, rest)) if !name.starts_with("func") => rest.trim_start(),
+            _ => yield_param,
+        };
+        let after_func = yield_type.strip_prefix("func")?.trim_start();
+        let yielded = balanced_contents(after_func, '(', ')')?;
+        return Some(split_type_list(yielded));
+    }
+
+    let generic = type_text.strip_prefix("iter.").unwrap_or(type_text);
+    let args = generic
+        .strip_prefix("Seq2")
+        .or_else(|| generic.strip_prefix("Seq"))?;
+    let args = balanced_contents(args, '[', ']')?;
+    Some(split_type_list(args))
+}
+
+/// Element type bound by ranging over a channel type: `<-chan Message` and
+/// `chan *Message` give the element type alone
+fn channel_element_types(type_text: &str) -> Option<Vec<String>> {
+    let text = type_text.trim();
+    let text = text.strip_prefix("<-").unwrap_or(text).trim_start();
+    let element = text.strip_prefix("chan")?;
+    if !element.starts_with(char::is_whitespace) && !element.starts_with('<') {
+        return None;
+    }
+    let element = element.trim_start();
+    let element = element.strip_prefix("<-").unwrap_or(element).trim();
+    (!element.is_empty()).then(|| vec![element.to_string()])
+}
+
+/// Contents of the bracketed group that `text` starts with
+fn balanced_contents(text: &str, open: char, close: char) -> Option<&str> {
+    let inner = text.strip_prefix(open)?;
+    let mut depth = 0usize;
+    for (i, c) in inner.char_indices() {
+        if c == open {
+            depth += 1;
+        } else if c == close {
+            if depth == 0 {
+                return Some(&inner[..i]);
+            }
+            depth -= 1;
+        }
+    }
+    None
+}
+
+/// Split a comma-separated list at the top level, outside brackets
+fn split_top_level(list: &str) -> Vec<&str> {
+    let mut items = Vec::new();
+    let mut depth = 0i32;
+    let mut start = 0;
+    for (i, c) in list.char_indices() {
+        match c {
+            '(' | '[' | '{' => depth += 1,
+            ')' | ']' | '}' => depth -= 1,
+            ',' if depth == 0 => {
+                items.push(list[start..i].trim());
+                start = i + 1;
+            }
+            _ => {}
+        }
+    }
+    items.push(list[start..].trim());
+    items.retain(|item| !item.is_empty());
+    items
+}
+
+/// Split a comma-separated type list at the top level, dropping parameter names
+fn split_type_list(list: &str) -> Vec<String> {
+    split_top_level(list)
+        .into_iter()
+        .map(|t| match t.split_once(' ') {
+            Some((name, typ))
+                if name.chars().all(|c| c.is_alphanumeric() || c == '_')
+                    && !matches!(name, "func" | "chan" | "map" | "struct" | "interface") =>
+            {
+                typ.trim().to_string()
+            }
+            _ => t.to_string(),
+        })
+        .collect()
+}
+
+/// Functions a cgo preamble declares, as (name, C signature, start, end)
+/// with byte offsets into `preamble`
+///
+/// The preamble is the C code in the comment right before `import "C"`.
+/// Top-level definitions and prototypes are found, `int twice(int x) { ... }`
+/// giving `twice` and `int twice(int x)`; typedefs, variables and struct
+/// definitions are skipped. Preprocessor lines must be blanked beforehand.
+fn cgo_functions(preamble: &str) -> Vec<(&str, String, usize, usize)> {
+    let mut functions: Vec<(&str, String, usize, usize)> = Vec::new();
+    let mut depth = 0usize;
+    let mut start = 0;
+    // Whether the last function found is a definition whose body is open
+    let mut in_definition = false;
+    for (i, c) in preamble.char_indices() {
+        match c {
+            '{' | ';' if depth == 0 => {
+                let head = &preamble[start..i];
+                in_definition = false;
+                if let Some((name, signature)) = cgo_function_head(head) {
+                    let offset = start + (head.len() - head.trim_start().len());
+                    functions.push((name, signature, offset, i + 1));
+                    in_definition = c == '{';
+                }
+                if c == '{' {
+                    depth = 1;
+                }
+                start = i + 1;
+            }
+            '{' => depth += 1,
+            '}' if depth > 0 => {
+                depth -= 1;
+                if depth == 0 {
+                    if in_definition {
+                        if let Some(function) = functions.last_mut() {
+                            function.3 = i + 1;
+                        }
+                    }
+                    start = i + 1;
+                }
+            }
+            _ => {}
+        }
+    }
+    functions
+}
 
-use super::resolution::GoResolutionContext;
+/// Name and signature of the C function declared by `head`, the text before
+/// a body or `;`; `None` unless it is `<type> name(<parameters>)`
+fn cgo_function_head(head: &str) -> Option<(&str, String)> {
+    let head = head.trim();
+    let open = head.find('(')?;
+    if !head.ends_with(')') || head.starts_with("typedef") {
+        return None;
+    }
+    let before = head[..open].trim_end();
+    let name_start = before
+        .rfind(|c: char| !(c.is_alphanumeric() || c == '_'))
+        .map_or(0, |i| i + 1);
+    let name = &before[name_start..];
+    // `int (*handler)(int)` declares a variable, and every function has a
+    // return type before its name
+    if name.is_empty() || before[..name_start].trim().is_empty() {
+        return None;
+    }
+    Some((name, head.split_whitespace().collect::<Vec<_>>().join(" ")))
+}
 
 /// Go language parser
 pub struct GoParser {
@@ -354,6 +771,7 @@ impl GoParser {
                 self.register_handled_node(node.kind(), node.kind_id());
                 // Enter block scope for switch case
                 self.context.enter_scope(ScopeType::Block);
+                self.process_type_case_binding(node, code, file_id, counter, symbols, module_path);
 
                 // Process case body
                 for child in node.children(&mut node.walk()) {
@@ -402,6 +820,54 @@ impl GoParser {
                     module_path,
                 );
             }
+            "import_declaration" => {
+                self.register_handled_node("import_declaration", node.kind_id());
+                if Self::is_cgo_import(node, code) {
+                    self.process_cgo_preamble(node, code, file_id, counter, symbols, module_path);
+                }
+            }
+            "labeled_statement" => {
+                self.register_handled_node("labeled_statement", node.kind_id());
+                if let Some(label) = node.child_by_field_name("label") {
+                    let name = &code[label.byte_range()];
+                    let mut symbol = self.create_symbol(
+                        counter.next_id(),
+                        name.to_string(),
+                        SymbolKind::Label,
+                        file_id,
+                        Range::new(
+                            node.start_position().row as u32,
+                            node.start_position().column as u16,
+                            node.end_position().row as u32,
+                            node.end_position().column as u16,
+                        ),
+                        Some(format!("{name}:")),
+                        None,
+                        module_path,
+                        Visibility::Private,
+                    );
+                    // Labels live in their own per-function namespace
+                    symbol.scope_context = Some(crate::symbol::ScopeContext::Local {
+                        hoisted: false,
+                        parent_name: self.context.current_function().map(|s| s.into()),
+                        parent_kind: Some(SymbolKind::Function),
+                    });
+                    symbols.push(symbol);
+                }
+
+                // The labeled statement itself declares the usual locals
+                for child in node.children(&mut node.walk()) {
+                    self.extract_symbols_from_node(
+                        child,
+                        code,
+                        file_id,
+                        counter,
+                        symbols,
+                        module_path,
+                        depth + 1,
+                    );
+                }
+            }
             _ => {
                 // For unhandled node types, recursively process children
                 let mut cursor = node.walk();
@@ -464,15 +930,122 @@ impl GoParser {
         symbols: &mut Vec<Symbol>,
         module_path: &str,
     ) {
-        // type_declaration contains type_spec nodes
+        // type_declaration contains type_spec nodes, and type_alias nodes for
+        // `type A = B`; both have the same name and type fields
         for child in node.children(&mut node.walk()) {
-            if child.kind() == "type_spec" {
-                self.register_handled_node("type_spec", child.kind_id());
+            if matches!(child.kind(), "type_spec" | "type_alias") {
+                self.register_handled_node(child.kind(), child.kind_id());
                 self.process_type_spec(child, code, file_id, counter, symbols, module_path);
             }
         }
     }
 
+    /// Whether an import declaration is cgo's `import "C"`
+    fn is_cgo_import(declaration: Node, code: &str) -> bool {
+        declaration
+            .named_children(&mut declaration.walk())
+            .filter(|spec| spec.kind() == "import_spec")
+            .filter_map(|spec| spec.child_by_field_name("path"))
+            .any(|path| &code[path.byte_range()] == "\"C\"")
+    }
+
+    /// Whether a Go file uses cgo, so that `C.name` selects from its preamble
+    fn imports_cgo(root: Node, code: &str) -> bool {
+        root.named_children(&mut root.walk())
+            .any(|decl| decl.kind() == "import_declaration" && Self::is_cgo_import(decl, code))
+    }
+
+    /// Extract the C functions of the cgo preamble above `import "C"`
+    ///
+    /// The preamble is the comment immediately preceding the import, with no
+    /// blank line between them: a `/* */` block or consecutive `//` lines.
+    /// Its functions are named as Go code calls them, `C.external_function`,
+    /// with their C prototype as signature; like any cgo name they are
+    /// private to the file.
+    fn process_cgo_preamble(
+        &mut self,
+        import: Node,
+        code: &str,
+        file_id: FileId,
+        counter: &mut SymbolCounter,
+        symbols: &mut Vec<Symbol>,
+        module_path: &str,
+    ) {
+        // A `/* */` block alone, or a run of `//` lines; a line comment
+        // above a block introduces it in Go, it is no C
+        let mut comments: Vec<Node> = Vec::new();
+        let mut next = import;
+        while let Some(comment) = next
+            .prev_named_sibling()
+            .filter(|sibling| sibling.kind() == "comment")
+            .filter(|sibling| sibling.end_position().row + 1 >= next.start_position().row)
+        {
+            let is_line = code[comment.byte_range()].starts_with("//");
+            if comments
+                .first()
+                .is_some_and(|first| !is_line || !code[first.byte_range()].starts_with("//"))
+            {
+                break;
+            }
+            comments.push(comment);
+            next = comment;
+        }
+        // Collected bottom-up
+        let (Some(first), Some(last)) = (comments.last(), comments.first()) else {
+            return;
+        };
+        let offset = first.start_byte();
+
+        // Blank the comment markers and preprocessor lines, keeping offsets
+        let mut preamble = code.as_bytes()[offset..last.end_byte()].to_vec();
+        for comment in &comments {
+            let (start, end) = (comment.start_byte() - offset, comment.end_byte() - offset);
+            preamble[start..start + 2].fill(b' ');
+            if preamble[start..end].ends_with(b"*/") {
+                preamble[end - 2..end].fill(b' ');
+            }
+        }
+        let mut continued = false;
+        let mut line_start = 0;
+        while line_start < preamble.len() {
+            let line_end = preamble[line_start..]
+                .iter()
+                .position(|&b| b == b'\n')
+                .map_or(preamble.len(), |i| line_start + i);
+            let line = &preamble[line_start..line_end];
+            if continued || line.trim_ascii_start().starts_with(b"#") {
+                continued = line.trim_ascii_end().ends_with(b"\\");
+                preamble[line_start..line_end].fill(b' ');
+            }
+            line_start = line_end + 1;
+        }
+        let Ok(preamble) = String::from_utf8(preamble) else {
+            return;
+        };
+
+        let position = |byte: usize| {
+            let before = &code[..offset + byte];
+            let row = before.matches('\n').count() as u32;
+            let column = before.len() - before.rfind('\n').map_or(0, |i| i + 1);
+            (row, column as u16)
+        };
+        for (name, signature, start, end) in cgo_functions(&preamble) {
+            let (start_line, start_column) = position(start);
+            let (end_line, end_column) = position(end);
+            symbols.push(self.create_symbol(
+                counter.next_id(),
+                format!("C.{name}"),
+                SymbolKind::Function,
+                file_id,
+                Range::new(start_line, start_column, end_line, end_column),
+                Some(signature),
+                None,
+                module_path,
+                Visibility::Private,
+            ));
+        }
+    }
+
     /// Process a type_spec node (individual type definition)
     /// Process a Go type specification node
     ///
@@ -721,10 +1294,29 @@ impl GoParser {
             }
         }
 
+        // An embedded field is named after its type: `*WorkerPool` declares
+        // the field `WorkerPool`, with the type alone as signature
+        let mut embedded = false;
+        if field_names.is_empty() {
+            if let Some(type_node) = field_node.child_by_field_name("type") {
+                // `*models.User` declares the field `User`
+                let base = super::analysis::base_type_node(type_node).map(|base| {
+                    let name = base.child_by_field_name("name").unwrap_or(base);
+                    &code[name.byte_range()]
+                });
+                if let Some(base) = base {
+                    field_names.push(base);
+                    field_type = Some(&code[type_node.byte_range()]);
+                    embedded = true;
+                }
+            }
+        }
+
         // Create symbols for each field name
         for field_name in field_names {
             let visibility = self.determine_go_visibility(field_name);
             let signature = match field_type {
+                Some(typ) if embedded => typ.to_string(),
                 Some(typ) => format!("{field_name} {typ}"),
                 None => field_name.to_string(),
             };
@@ -752,6 +1344,40 @@ impl GoParser {
         }
     }
 
+    /// Type parameters declared by the type spec of a struct type node, as
+    /// (name, constraint) pairs: `Pair[K comparable, V any]` gives
+    /// `[("K", "comparable"), ("V", "any")]`
+    fn struct_type_parameters<'a>(struct_node: Node, code: &'a str) -> Vec<(&'a str, &'a str)> {
+        let Some(list) = struct_node
+            .parent()
+            .filter(|spec| spec.kind() == "type_spec")
+            .and_then(|spec| spec.child_by_field_name("type_parameters"))
+        else {
+            return Vec::new();
+        };
+        let mut parameters = Vec::new();
+        for declaration in list.named_children(&mut list.walk()) {
+            if declaration.kind() != "type_parameter_declaration" {
+                continue;
+            }
+            let constraint = declaration
+                .child_by_field_name("type")
+                .map(|c| &code[c.byte_range()])
+                .unwrap_or("any");
+            for name in declaration.children_by_field_name("name", &mut declaration.walk()) {
+                parameters.push((&code[name.byte_range()], constraint));
+            }
+        }
+        parameters
+    }
+
+    /// Whether `name` appears as a whole identifier in a type expression
+    fn mentions_identifier(type_text: &str, name: &str) -> bool {
+        type_text
+            .split(|c: char| !(c.is_alphanumeric() || c == '_'))
+            .any(|word| word == name)
+    }
+
     /// Extract interface methods from an interface_type node
     fn extract_interface_methods(
         &mut self,
@@ -939,48 +1565,61 @@ impl GoParser {
         symbols: &mut Vec<Symbol>,
         module_path: &str,
     ) {
+        // Types and values carry over between the specs of a block
+        let constants = super::analysis::const_values(node, code);
+
         // const_declaration contains const_spec nodes
         for child in node.children(&mut node.walk()) {
             if child.kind() == "const_spec" {
                 self.register_handled_node("const_spec", child.kind_id());
-                self.process_const_spec(child, code, file_id, counter, symbols, module_path);
+                self.process_const_spec(
+                    child,
+                    code,
+                    &constants,
+                    file_id,
+                    counter,
+                    symbols,
+                    module_path,
+                );
             }
         }
     }
 
     /// Process a single constant specification
+    ///
+    /// The signature gives the constant's type and integer value when they
+    /// are known, also when the spec repeats the previous one implicitly:
+    /// `const RoleUser UserRole = 1` for the bare `RoleUser` of an iota run.
+    #[allow(clippy::too_many_arguments)]
     fn process_const_spec(
         &mut self,
         node: Node,
         code: &str,
+        constants: &[super::analysis::ConstValue],
         file_id: FileId,
         counter: &mut SymbolCounter,
         symbols: &mut Vec<Symbol>,
         module_path: &str,
     ) {
-        let mut const_names = Vec::new();
-        let mut const_type = None;
-
-        for child in node.children(&mut node.walk()) {
-            match child.kind() {
-                "identifier" => {
-                    const_names.push(&code[child.byte_range()]);
-                }
-                "type_identifier" | "pointer_type" | "array_type" | "slice_type" | "map_type"
-                | "channel_type" => {
-                    const_type = Some(&code[child.byte_range()]);
-                }
-                _ => {}
-            }
-        }
+        let const_names = node
+            .children_by_field_name("name", &mut node.walk())
+            .collect::<Vec<_>>();
 
         // Create symbols for each constant name
-        for const_name in const_names {
+        for name in const_names {
+            let const_name = &code[name.byte_range()];
             let visibility = self.determine_go_visibility(const_name);
-            let signature = match const_type {
+            let constant = constants.iter().find(|c| c.name == name);
+            let const_type = constant
+                .and_then(|c| c.type_node)
+                .map(|t| &code[t.byte_range()]);
+            let mut signature = match const_type {
                 Some(typ) => format!("const {const_name} {typ}"),
                 None => format!("const {const_name}"),
             };
+            if let Some(value) = constant.and_then(|c| c.value) {
+                signature.push_str(&format!(" = {value}"));
+            }
 
             let symbol = self.create_symbol(
                 counter.next_id(),
@@ -1068,6 +1707,87 @@ impl GoParser {
         }
     }
 
+    /// Process the variable a type switch binds in one of its cases
+    ///
+    /// `switch v := data.(type)` declares a distinct `v` in every case:
+    /// `case string:` gets `v := data.(string)`, while a case listing several
+    /// types, `case nil:` and `default:` keep the type of `data`, written
+    /// `v := data.(type)`.
+    fn process_type_case_binding(
+        &mut self,
+        case: Node,
+        code: &str,
+        file_id: FileId,
+        counter: &mut SymbolCounter,
+        symbols: &mut Vec<Symbol>,
+        module_path: &str,
+    ) {
+        let Some((alias, value)) = Self::type_switch_alias(case) else {
+            return;
+        };
+        let var_name = &code[alias.byte_range()];
+        if var_name == "_" {
+            return;
+        }
+        let case_type = Self::type_case_type(case, code).map_or("type", |t| &code[t.byte_range()]);
+        let signature = format!("{var_name} := {}.({case_type})", &code[value.byte_range()]);
+
+        let visibility = self.determine_go_visibility(var_name);
+        let mut symbol = self.create_symbol(
+            counter.next_id(),
+            var_name.to_string(),
+            SymbolKind::Variable,
+            file_id,
+            Range::new(
+                case.start_position().row as u32,
+                case.start_position().column as u16,
+                case.end_position().row as u32,
+                case.end_position().column as u16,
+            ),
+            Some(signature),
+            None,
+            module_path,
+            visibility,
+        );
+
+        // Local to the case clause
+        symbol.scope_context = Some(crate::symbol::ScopeContext::Local {
+            hoisted: false,
+            parent_name: self.context.current_function().map(|s| s.into()),
+            parent_kind: Some(SymbolKind::Function),
+        });
+
+        symbols.push(symbol);
+    }
+
+    /// Alias and asserted value of the type switch a case belongs to:
+    /// `v` and `data` for `switch v := data.(type)`
+    fn type_switch_alias<'t>(case: Node<'t>) -> Option<(Node<'t>, Node<'t>)> {
+        if !matches!(case.kind(), "type_case" | "default_case") {
+            return None;
+        }
+        let switch = case.parent()?;
+        if switch.kind() != "type_switch_statement" {
+            return None;
+        }
+        let alias = switch.child_by_field_name("alias")?.named_child(0)?;
+        Some((alias, switch.child_by_field_name("value")?))
+    }
+
+    /// Type a type switch case asserts, when it lists a single one
+    fn type_case_type<'t>(case: Node<'t>, code: &str) -> Option<Node<'t>> {
+        if case.kind() != "type_case" {
+            return None;
+        }
+        // The field also holds the commas between types
+        let mut cursor = case.walk();
+        let mut types = case
+            .children_by_field_name("type", &mut cursor)
+            .filter(|t| t.is_named());
+        let case_type = types.next()?;
+        (types.next().is_none() && &code[case_type.byte_range()] != "nil").then_some(case_type)
+    }
+
     /// Process method receiver to track receiver scope
     fn process_method_receiver(
         &mut self,
@@ -1212,14 +1932,15 @@ impl GoParser {
         depth: usize,
     ) {
         // Range clause format: index, value := range items
-        // Extract the variable names from the left side
+        // Use the field names so an identifier on the right-hand side
+        // (`for i := range n`) is never mistaken for a loop variable
         let mut range_vars = Vec::new();
 
-        for child in range_node.children(&mut range_node.walk()) {
-            match child.kind() {
+        if let Some(left) = range_node.child_by_field_name("left") {
+            match left.kind() {
                 "expression_list" => {
                     // Multiple variables: index, value
-                    for expr_child in child.children(&mut child.walk()) {
+                    for expr_child in left.children(&mut left.walk()) {
                         if expr_child.kind() == "identifier" {
                             range_vars.push(&code[expr_child.byte_range()]);
                         }
@@ -1227,27 +1948,36 @@ impl GoParser {
                 }
                 "identifier" => {
                     // Single variable: index
-                    range_vars.push(&code[child.byte_range()]);
-                }
-                _ => {
-                    // Also process non-range parts (e.g., the iterable expression)
-                    self.extract_symbols_from_node(
-                        child,
-                        code,
-                        file_id,
-                        counter,
-                        symbols,
-                        module_path,
-                        depth + 1,
-                    );
+                    range_vars.push(&code[left.byte_range()]);
                 }
+                _ => {}
             }
         }
 
+        // Also process the iterable expression (it may contain closures)
+        let bound_types = match range_node.child_by_field_name("right") {
+            Some(right) => {
+                let bound_types = Self::range_bound_types(right, code);
+                self.extract_symbols_from_node(
+                    right,
+                    code,
+                    file_id,
+                    counter,
+                    symbols,
+                    module_path,
+                    depth + 1,
+                );
+                bound_types
+            }
+            None => None,
+        };
+
         // Create symbols for range variables (these are in for loop block scope)
         for (i, var_name) in range_vars.iter().enumerate() {
             let visibility = self.determine_go_visibility(var_name);
-            let signature = if i == 0 {
+            let signature = if let Some(typ) = bound_types.as_ref().and_then(|t| t.get(i)) {
+                format!("{var_name} := range ({typ})")
+            } else if i == 0 {
                 format!("{var_name} := range (index)")
             } else {
                 format!("{var_name} := range (value)")
@@ -1281,33 +2011,270 @@ impl GoParser {
         }
     }
 
-    /// Determine Go visibility based on capitalization
-    fn determine_go_visibility(&self, name: &str) -> Visibility {
-        if let Some(first_char) = name.chars().next() {
-            if first_char.is_uppercase() {
-                Visibility::Public
-            } else {
-                Visibility::Private
-            }
-        } else {
-            Visibility::Private
+    /// Name of a named interface type: `type Reader interface { ... }` gives `Reader`
+    fn interface_type_name<'a>(interface_node: &Node, code: &'a str) -> Option<&'a str> {
+        let type_spec = interface_node.parent()?;
+        if type_spec.kind() != "type_spec" {
+            return None;
         }
+        let name = type_spec.child_by_field_name("name")?;
+        Some(&code[name.byte_range()])
     }
 
-    /// Extract signature for struct types
-    fn extract_struct_signature(&self, node: Node, code: &str) -> String {
-        let start = node.start_byte();
-        let mut end = node.end_byte();
+    /// Find interfaces embedded in named interface types
+    ///
+    /// Returns (interface, embedded interface as written, range) tuples, e.g.
+    /// `("ReadWriteCloser", "io.Closer", ...)`. Type-set constraints such as
+    /// `~int | ~string` are not embeddings and are skipped.
+    pub fn find_interface_embeds<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
 
-        // Find the struct body and exclude it, keeping only the header
-        if let Some(type_node) = node.child_by_field_name("type") {
-            if let Some(body) = type_node
-                .children(&mut type_node.walk())
-                .find(|n| n.kind() == "field_declaration_list")
-            {
-                end = body.start_byte();
-            }
-        }
+        Self::interface_embeds(tree.root_node())
+            .into_iter()
+            .map(|(interface, embedded)| {
+                let range = Range::new(
+                    embedded.start_position().row as u32,
+                    embedded.start_position().column as u16,
+                    embedded.end_position().row as u32,
+                    embedded.end_position().column as u16,
+                );
+                (
+                    &code[interface.byte_range()],
+                    &code[embedded.byte_range()],
+                    range,
+                )
+            })
+            .collect()
+    }
+
+    /// Interfaces embedded in the named interface types under `root`, as
+    /// (interface name, embedded type) nodes in source order
+    ///
+    /// See [`Self::find_interface_embeds`]; the method set analyses register
+    /// these with [`GoInheritanceResolver`](super::GoInheritanceResolver).
+    pub fn interface_embeds<'t>(root: Node<'t>) -> Vec<(Node<'t>, Node<'t>)> {
+        let mut embeds = Vec::new();
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            if node.kind() == "interface_type" {
+                let name = node
+                    .parent()
+                    .filter(|spec| spec.kind() == "type_spec")
+                    .and_then(|spec| spec.child_by_field_name("name"));
+                if let Some(name) = name {
+                    for elem in node.named_children(&mut node.walk()) {
+                        if elem.kind() != "type_elem" || elem.named_child_count() != 1 {
+                            continue;
+                        }
+                        let Some(embedded) = elem.named_child(0) else {
+                            continue;
+                        };
+                        if matches!(
+                            embedded.kind(),
+                            "type_identifier" | "qualified_type" | "generic_type"
+                        ) {
+                            embeds.push((name, embedded));
+                        }
+                    }
+                }
+            }
+            let children: Vec<_> = node.named_children(&mut node.walk()).collect();
+            stack.extend(children.into_iter().rev());
+        }
+
+        embeds
+    }
+
+    /// Types bound by the loop variables of `range <iterable>`, when known
+    ///
+    /// Ranging over an integer (Go 1.22) binds a single variable of type
+    /// `int` and no value variable, as ranging over a channel binds the
+    /// element. Ranging over an iterator function (Go 1.23) binds the types
+    /// passed to `yield`.
+    fn range_bound_types(iterable: Node, code: &str) -> Option<Vec<String>> {
+        match iterable.kind() {
+            "int_literal" => Some(vec!["int".to_string()]),
+            "parenthesized_expression" => Self::range_bound_types(iterable.named_child(0)?, code),
+            "func_literal" => {
+                let params = iterable.child_by_field_name("parameters")?;
+                iterator_yield_types(&format!("func{}", &code[params.byte_range()]))
+            }
+            "identifier" => {
+                let type_text = Self::local_value_type(iterable, code)?;
+                if GO_INTEGER_TYPES.contains(&type_text.as_str()) {
+                    return Some(vec![type_text]);
+                }
+                channel_element_types(&type_text).or_else(|| iterator_yield_types(&type_text))
+            }
+            "call_expression" => {
+                // len(x) and cap(x) are always ints
+                let function = iterable.child_by_field_name("function")?;
+                if matches!(&code[function.byte_range()], "len" | "cap") {
+                    return Some(vec!["int".to_string()]);
+                }
+                let result = Self::call_result_type(iterable, code)?;
+                channel_element_types(&result).or_else(|| iterator_yield_types(&result))
+            }
+            _ => None,
+        }
+    }
+
+    /// Type of the identifier `node` as declared before its use
+    ///
+    /// Looks at the parameters and local declarations of the enclosing
+    /// functions, then at package-level functions used as values.
+    fn local_value_type(node: Node, code: &str) -> Option<String> {
+        let name = &code[node.byte_range()];
+        let mut current = node.parent();
+
+        while let Some(scope) = current {
+            if matches!(
+                scope.kind(),
+                "function_declaration" | "method_declaration" | "func_literal"
+            ) {
+                if let Some(found) = Self::declared_type_in(scope, name, node, code) {
+                    return Some(found);
+                }
+            }
+            if scope.parent().is_none() {
+                // Package level: a top-level function used as an iterator
+                let function = Self::find_function_declaration(scope, name, code)?;
+                let params = function.child_by_field_name("parameters")?;
+                return Some(format!("func{}", &code[params.byte_range()]));
+            }
+            current = scope.parent();
+        }
+        None
+    }
+
+    /// Type given to `name` by the parameters or body of `scope` before `usage`
+    fn declared_type_in(scope: Node, name: &str, usage: Node, code: &str) -> Option<String> {
+        let mut found = None;
+        let mut stack = vec![scope];
+
+        while let Some(node) = stack.pop() {
+            if node.start_byte() >= usage.start_byte() {
+                continue;
+            }
+            match node.kind() {
+                "parameter_declaration" | "var_spec" => {
+                    let declares = node
+                        .children(&mut node.walk())
+                        .any(|c| c.kind() == "identifier" && &code[c.byte_range()] == name);
+                    if let (true, Some(typ)) = (declares, node.child_by_field_name("type")) {
+                        found = Some(code[typ.byte_range()].to_string());
+                    }
+                }
+                "short_var_declaration" => {
+                    let left = node.child_by_field_name("left");
+                    let right = node
+                        .child_by_field_name("right")
+                        .and_then(|r| r.named_child(0));
+                    let declares = left.is_some_and(|l| {
+                        l.named_child(0)
+                            .is_some_and(|first| &code[first.byte_range()] == name)
+                    });
+                    if let (true, Some(value)) = (declares, right) {
+                        found = match value.kind() {
+                            "func_literal" => value
+                                .child_by_field_name("parameters")
+                                .map(|p| format!("func{}", &code[p.byte_range()])),
+                            "call_expression" => Self::call_result_type(value, code),
+                            _ => None,
+                        };
+                    }
+                }
+                // Nested closures have their own scope
+                "func_literal" if node.id() != scope.id() => continue,
+                _ => {}
+            }
+            // Push in reverse so declarations are visited in source order
+            let children: Vec<_> = node.named_children(&mut node.walk()).collect();
+            stack.extend(children.into_iter().rev());
+        }
+
+        found
+    }
+
+    /// Declared result type of the function or method called by `call`
+    fn call_result_type(call: Node, code: &str) -> Option<String> {
+        let function = call.child_by_field_name("function")?;
+        let name = match function.kind() {
+            "identifier" => &code[function.byte_range()],
+            "selector_expression" => &code[function.child_by_field_name("field")?.byte_range()],
+            _ => return None,
+        };
+
+        let mut root = call;
+        while let Some(parent) = root.parent() {
+            root = parent;
+        }
+        let declaration = Self::find_function_declaration(root, name, code)
+            .or_else(|| Self::find_interface_method(root, name, code))?;
+        let result = declaration.child_by_field_name("result")?;
+        Some(code[result.byte_range()].to_string())
+    }
+
+    /// Method element named `name` of an interface type declared at the top
+    /// level, for calls through an interface value
+    fn find_interface_method<'t>(root: Node<'t>, name: &str, code: &str) -> Option<Node<'t>> {
+        let mut stack: Vec<_> = root
+            .named_children(&mut root.walk())
+            .filter(|decl| decl.kind() == "type_declaration")
+            .collect();
+        while let Some(node) = stack.pop() {
+            if node.kind() == "method_elem"
+                && node
+                    .child_by_field_name("name")
+                    .is_some_and(|n| &code[n.byte_range()] == name)
+            {
+                return Some(node);
+            }
+            stack.extend(node.named_children(&mut node.walk()));
+        }
+        None
+    }
+
+    /// Top-level function or method declaration named `name`
+    fn find_function_declaration<'t>(root: Node<'t>, name: &str, code: &str) -> Option<Node<'t>> {
+        root.children(&mut root.walk()).find(|decl| {
+            matches!(decl.kind(), "function_declaration" | "method_declaration")
+                && decl
+                    .child_by_field_name("name")
+                    .is_some_and(|n| &code[n.byte_range()] == name)
+        })
+    }
+
+    /// Determine Go visibility based on capitalization
+    fn determine_go_visibility(&self, name: &str) -> Visibility {
+        if let Some(first_char) = name.chars().next() {
+            if first_char.is_uppercase() {
+                Visibility::Public
+            } else {
+                Visibility::Private
+            }
+        } else {
+            Visibility::Private
+        }
+    }
+
+    /// Extract signature for struct types
+    fn extract_struct_signature(&self, node: Node, code: &str) -> String {
+        let start = node.start_byte();
+        let mut end = node.end_byte();
+
+        // Find the struct body and exclude it, keeping only the header
+        if let Some(type_node) = node.child_by_field_name("type") {
+            if let Some(body) = type_node
+                .children(&mut type_node.walk())
+                .find(|n| n.kind() == "field_declaration_list")
+            {
+                end = body.start_byte();
+            }
+        }
 
         code[start..end].trim().to_string()
     }
@@ -1458,6 +2425,7 @@ impl GoParser {
                 alias: if is_dot_import {
                     Some(".".to_string())
                 } else if is_blank_import {
+                    // Binds no name; kept only for the package's side effects
                     Some("_".to_string())
                 } else {
                     import_alias
@@ -1483,6 +2451,7 @@ impl GoParser {
         node: &tree_sitter::Node,
         code: &'a str,
         current_function: Option<&'a str>,
+        cgo: bool,
         calls: &mut Vec<(&'a str, &'a str, Range)>,
     ) {
         // Handle function context - track which function we're inside
@@ -1503,21 +2472,25 @@ impl GoParser {
 
         // Check if this is a call expression
         if node.kind() == "call_expression" {
-            // Skip if it's a method call (handled by find_method_calls)
+            // Skip if it's a method call (handled by find_method_calls), but
+            // not cgo's `C.external_function()`, a call of a preamble function
             if let Some(function_node) = node.child_by_field_name("function") {
-                if function_node.kind() != "selector_expression" {
+                let fn_name = if function_node.kind() != "selector_expression" {
                     // It's a regular function call
-                    if let Some(fn_name) = Self::extract_function_name(&function_node, code) {
-                        if let Some(context) = function_context {
-                            let range = Range {
-                                start_line: (node.start_position().row + 1) as u32,
-                                start_column: node.start_position().column as u16,
-                                end_line: (node.end_position().row + 1) as u32,
-                                end_column: node.end_position().column as u16,
-                            };
-                            calls.push((context, fn_name, range));
-                        }
-                    }
+                    Self::extract_function_name(&function_node, code)
+                } else if cgo && Self::is_cgo_selector(&function_node, code) {
+                    Some(&code[function_node.byte_range()])
+                } else {
+                    None
+                };
+                if let (Some(fn_name), Some(context)) = (fn_name, function_context) {
+                    let range = Range {
+                        start_line: node.start_position().row as u32,
+                        start_column: node.start_position().column as u16,
+                        end_line: node.end_position().row as u32,
+                        end_column: node.end_position().column as u16,
+                    };
+                    calls.push((context, fn_name, range));
                 }
             }
         }
@@ -1525,7 +2498,310 @@ impl GoParser {
         // Recurse to children
         let mut cursor = node.walk();
         for child in node.children(&mut cursor) {
-            self.extract_calls_recursive(&child, code, function_context, calls);
+            self.extract_calls_recursive(&child, code, function_context, cgo, calls);
+        }
+    }
+
+    /// Whether a selector is `C.name`, naming a cgo preamble declaration
+    fn is_cgo_selector(selector: &tree_sitter::Node, code: &str) -> bool {
+        selector
+            .child_by_field_name("operand")
+            .is_some_and(|operand| {
+                operand.kind() == "identifier" && &code[operand.byte_range()] == "C"
+            })
+    }
+
+    /// Find `break`, `continue` and `goto` statements naming a label
+    ///
+    /// Returns (function, label, range) tuples, where the function is the
+    /// enclosing declaration. A label is only visible in the
+    /// function declaring it, so the pair identifies the [`SymbolKind::Label`]
+    /// symbol jumped to.
+    pub fn find_label_jumps_in<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        let root = tree.root_node();
+
+        let mut jumps = Vec::new();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let (Some(name), Some(body)) = (
+                decl.child_by_field_name("name"),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+            Self::collect_label_jumps(body, code, &code[name.byte_range()], &mut jumps);
+        }
+        jumps
+    }
+
+    /// Find the variables closures capture from the functions around them
+    ///
+    /// Returns (function, closure, variable, declaration, mutated) tuples.
+    /// Closures are named as in Go stack traces: `CreateCounter.func1`,
+    /// `CreateRetryFunc.func1.1` for the first literal nested in it, and
+    /// `Worker.Start.func1` within a method. A closure captures the
+    /// parameters, receivers and locals declared outside it that it or a
+    /// literal nested in it refers to, each use resolved to the innermost
+    /// declaration in scope; the range is that of the declaring identifier.
+    /// Go captures by reference, so a capture the closure assigns,
+    /// increments or takes the address of is `mutated`: state shared with
+    /// the function, as `count` in
+    ///
+    /// ```go
+    /// count := start
+    /// return func() int {
+    ///     count++
+    ///     return count
+    /// }
+    /// ```
+    pub fn find_captures_in<'a>(
+        &mut self,
+        code: &'a str,
+    ) -> Vec<(&'a str, String, &'a str, Range, bool)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        let root = tree.root_node();
+
+        let mut captures = Vec::new();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let (Some(name), Some(body)) = (
+                decl.child_by_field_name("name"),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+            let function = &code[name.byte_range()];
+            let prefix =
+                match receiver_type_from_signature(&code[decl.start_byte()..body.start_byte()]) {
+                    Some(receiver) => format!("{receiver}.{function}"),
+                    None => function.to_string(),
+                };
+            let declarations = local_declarations(decl, code);
+            Self::collect_captures(body, code, &declarations, function, &prefix, &mut captures);
+        }
+        captures
+    }
+
+    /// Record the captures of the literals directly within `scope`, then of
+    /// those nested in each
+    ///
+    /// Top-level literals are `prefix.funcN`; nested ones `prefix.N`.
+    fn collect_captures<'a>(
+        scope: Node,
+        code: &'a str,
+        declarations: &[LocalDeclaration],
+        function: &'a str,
+        prefix: &str,
+        captures: &mut Vec<(&'a str, String, &'a str, Range, bool)>,
+    ) {
+        let mut literals = Vec::new();
+        let mut stack = vec![scope];
+        while let Some(node) = stack.pop() {
+            for child in node.named_children(&mut node.walk()) {
+                if child.kind() == "func_literal" {
+                    literals.push(child);
+                } else {
+                    stack.push(child);
+                }
+            }
+        }
+        literals.sort_by_key(|n| n.start_byte());
+
+        let nested = scope.kind() == "func_literal";
+        for (index, literal) in literals.into_iter().enumerate() {
+            let closure = if nested {
+                format!("{prefix}.{}", index + 1)
+            } else {
+                format!("{prefix}.func{}", index + 1)
+            };
+            let inside = |node: Node| {
+                literal.start_byte() <= node.start_byte() && node.end_byte() <= literal.end_byte()
+            };
+
+            // Declarations outside the literal, with whether any use writes them
+            let mut captured: Vec<(Node, bool)> = Vec::new();
+            let mut stack = vec![literal];
+            while let Some(node) = stack.pop() {
+                stack.extend(node.named_children(&mut node.walk()));
+                if node.kind() != "identifier"
+                    || Self::is_composite_key(node)
+                    || declarations.iter().any(|d| d.name == node)
+                {
+                    continue;
+                }
+                let Some(declaration) = Self::innermost_declaration(declarations, node, code)
+                    .filter(|d| !d.is_type())
+                    .map(|d| d.name)
+                    .filter(|&name| !inside(name))
+                else {
+                    continue;
+                };
+                let mutated = Self::is_written(node);
+                match captured.iter_mut().find(|(name, _)| *name == declaration) {
+                    Some((_, written)) => *written |= mutated,
+                    None => captured.push((declaration, mutated)),
+                }
+            }
+            captured.sort_by_key(|(name, _)| name.start_byte());
+            for (name, mutated) in captured {
+                captures.push((
+                    function,
+                    closure.clone(),
+                    &code[name.byte_range()],
+                    Self::node_range(name),
+                    mutated,
+                ));
+            }
+            Self::collect_captures(literal, code, declarations, function, &closure, captures);
+        }
+    }
+
+    /// Whether the identifier `node` is written rather than read: assigned,
+    /// incremented, decremented or having its address taken
+    fn is_written(node: Node) -> bool {
+        let Some(parent) = node.parent() else {
+            return false;
+        };
+        match parent.kind() {
+            "inc_statement" | "dec_statement" => true,
+            "unary_expression" => parent
+                .child_by_field_name("operator")
+                .is_some_and(|operator| operator.kind() == "&"),
+            "expression_list" => parent.parent().is_some_and(|statement| {
+                statement.kind() == "assignment_statement"
+                    && statement.child_by_field_name("left") == Some(parent)
+            }),
+            _ => false,
+        }
+    }
+
+    /// Whether the identifier `node` is the key of a keyed composite literal
+    /// element: `Config{Name: x}` keys are fields, not values
+    fn is_composite_key(node: Node) -> bool {
+        node.parent().is_some_and(|p| {
+            p.kind() == "literal_element"
+                && p.parent().is_some_and(|k| {
+                    k.kind() == "keyed_element" && k.child_by_field_name("key") == Some(p)
+                })
+        })
+    }
+
+    /// Find instantiations of generic functions and types
+    ///
+    /// Returns (context, generic, type arguments, range) tuples, where the
+    /// context is the enclosing declaration:
+    /// `NewMap[int, string]()` and `Map[int, struct{}]` give their arguments
+    /// as written. A call without type arguments to a generic function of
+    /// this file, `Sum(numbers)`, has them inferred and gives `None`.
+    /// Arguments naming a type parameter in scope, as `*Repository[T]` in a
+    /// method of `Repository`, instantiate nothing concrete and are skipped.
+    pub fn find_instantiations_in<'a>(
+        &mut self,
+        code: &'a str,
+    ) -> Vec<(&'a str, &'a str, Option<&'a str>, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        let root = tree.root_node();
+
+        let generic_functions: std::collections::HashSet<&str> = root
+            .named_children(&mut root.walk())
+            .filter(|decl| {
+                decl.kind() == "function_declaration"
+                    && decl.child_by_field_name("type_parameters").is_some()
+            })
+            .filter_map(|decl| decl.child_by_field_name("name"))
+            .map(|name| &code[name.byte_range()])
+            .collect();
+
+        let mut instantiations = Vec::new();
+        super::analysis::walk_tree(root, &mut |node| {
+            let (generic, arguments) = match node.kind() {
+                "call_expression" => {
+                    let Some(function) = node
+                        .child_by_field_name("function")
+                        .filter(|f| matches!(f.kind(), "identifier" | "selector_expression"))
+                    else {
+                        return;
+                    };
+                    let arguments = node.child_by_field_name("type_arguments");
+                    let name = &code[function.byte_range()];
+                    if arguments.is_none()
+                        && (function.kind() != "identifier" || !generic_functions.contains(name))
+                    {
+                        return;
+                    }
+                    (name, arguments)
+                }
+                "generic_type" => {
+                    let (Some(base), Some(arguments)) = (
+                        node.child_by_field_name("type"),
+                        node.child_by_field_name("type_arguments"),
+                    ) else {
+                        return;
+                    };
+                    (&code[base.byte_range()], Some(arguments))
+                }
+                _ => return,
+            };
+            let arguments = arguments.map(|arguments| {
+                code[arguments.byte_range()]
+                    .trim_start_matches('[')
+                    .trim_end_matches(']')
+                    .trim()
+            });
+            if let Some(arguments) = arguments {
+                let parameters = Self::type_parameters_in_scope(node, code);
+                if split_top_level(arguments).iter().any(|argument| {
+                    parameters
+                        .iter()
+                        .any(|parameter| Self::mentions_identifier(argument, parameter))
+                }) {
+                    return;
+                }
+            }
+            instantiations.push((
+                Self::type_use_context(node, code),
+                generic,
+                arguments,
+                Self::node_range(node),
+            ));
+        });
+        instantiations
+    }
+
+    fn collect_label_jumps<'a>(
+        node: Node,
+        code: &'a str,
+        function: &'a str,
+        jumps: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        if matches!(
+            node.kind(),
+            "break_statement" | "continue_statement" | "goto_statement"
+        ) {
+            if let Some(label) = node
+                .named_children(&mut node.walk())
+                .find(|child| child.kind() == "label_name")
+            {
+                jumps.push((function, &code[label.byte_range()], Self::node_range(node)));
+            }
+            return;
+        }
+        for child in node.named_children(&mut node.walk()) {
+            Self::collect_label_jumps(child, code, function, jumps);
         }
     }
 
@@ -1544,6 +2820,7 @@ impl GoParser {
                     .unwrap_or("anonymous");
 
                 // Check parameters
+                let start = uses.len();
                 if let Some(params) = node.child_by_field_name("parameters") {
                     self.extract_go_parameter_types(params, code, context_name, uses);
                 }
@@ -1552,20 +2829,64 @@ impl GoParser {
                 if let Some(result) = node.child_by_field_name("result") {
                     self.extract_go_type_reference(&result, code, context_name, uses);
                 }
+
+                // Type parameters of the declaration or its receiver are
+                // no declared types
+                let mut parameters = Self::declared_type_parameters(*node, code);
+                if node.kind() == "method_declaration" {
+                    parameters.extend(receiver_type_parameters(&code[node.byte_range()]));
+                }
+                if !parameters.is_empty() {
+                    let signature_uses = uses.split_off(start);
+                    uses.extend(
+                        signature_uses
+                            .into_iter()
+                            .filter(|(_, type_name, _)| !parameters.contains(type_name)),
+                    );
+                }
             }
 
             // Go struct types
             "struct_type" => {
-                // Extract field types from struct
+                // Extract field types from struct, used by the declared type
+                // or the function holding an anonymous struct
+                let context_name = Self::type_use_context(*node, code);
+                let start = uses.len();
                 for child in node.children(&mut node.walk()) {
                     if child.kind() == "field_declaration_list" {
                         for field_child in child.children(&mut child.walk()) {
                             if field_child.kind() == "field_declaration" {
-                                self.extract_go_field_types(&field_child, code, "struct", uses);
+                                self.extract_go_field_types(&field_child, code, context_name, uses);
                             }
                         }
                     }
                 }
+                // Fields typed by the struct's own type parameters use no
+                // declared type
+                let parameters = Self::struct_type_parameters(*node, code);
+                if !parameters.is_empty() {
+                    let field_uses = uses.split_off(start);
+                    uses.extend(field_uses.into_iter().filter(|(_, type_name, _)| {
+                        !parameters.iter().any(|(name, _)| name == type_name)
+                    }));
+                }
+            }
+
+            // Constraints of type parameters: `Max[T Comparable]` uses
+            // `Comparable`. The members of a union are each a type, and
+            // approximations such as `~int` name no declared type
+            "type_parameter_declaration" => {
+                if let Some(constraint) = node.child_by_field_name("type") {
+                    let members: Vec<_> = if self.extract_go_type_name(&constraint, code).is_some()
+                    {
+                        vec![constraint]
+                    } else {
+                        constraint.named_children(&mut constraint.walk()).collect()
+                    };
+                    for member in members {
+                        self.extract_go_scoped_type_use(&member, code, uses);
+                    }
+                }
             }
 
             // Go variable declarations
@@ -1586,6 +2907,7 @@ impl GoParser {
                                 | "slice_type"
                                 | "map_type"
                                 | "channel_type"
+                                | "generic_type"
                         ) {
                             self.extract_go_type_reference(&child, code, var_name, uses);
                         }
@@ -1593,33 +2915,48 @@ impl GoParser {
                 }
             }
 
-            // Go function calls - look for calls to generic functions
-            "call_expression" => {
-                // Track generic function calls with type arguments
-                if let Some(function_node) = node.child_by_field_name("function") {
-                    let func_name = &code[function_node.byte_range()];
+            // Type arguments of generic function calls and instantiated
+            // generic types: `NewCache[string, *Document](10)` and
+            // `*Cache[string, *Document]` both use `Document`
+            "call_expression" | "generic_type" => {
+                if let Some(arguments) = node.child_by_field_name("type_arguments") {
+                    self.extract_go_type_argument_uses(&arguments, code, uses);
+                }
+                // `make([]Job, n)` and `new(*Job)` take a type as their first
+                // argument; the `Job` of a bare `new(Job)` parses as an
+                // identifier, a package value use
+                let builtin = node
+                    .child_by_field_name("function")
+                    .is_some_and(|f| matches!(&code[f.byte_range()], "make" | "new"));
+                if let Some(argument) = node
+                    .child_by_field_name("arguments")
+                    .and_then(|arguments| arguments.named_child(0))
+                    .filter(|argument| builtin && argument.kind() != "identifier")
+                {
+                    self.extract_go_scoped_type_use(&argument, code, uses);
+                }
+            }
 
-                    // Look for type arguments in generic function calls
-                    // Go 1.18+ supports syntax like: MakeSlice[int](10) or MyFunc[T, U](args)
-                    for child in node.children(&mut node.walk()) {
-                        if child.kind() == "type_arguments" {
-                            // Extract each type argument
-                            for type_arg in child.children(&mut child.walk()) {
-                                if matches!(
-                                    type_arg.kind(),
-                                    "type_identifier"
-                                        | "pointer_type"
-                                        | "array_type"
-                                        | "slice_type"
-                                        | "map_type"
-                                ) {
-                                    self.extract_go_type_reference(
-                                        &type_arg, code, func_name, uses,
-                                    );
-                                }
-                            }
-                        }
-                    }
+            // Types named inside expressions: `data.(DataProcessor)`,
+            // `Config{...}`, `[]Job(batch)` and the cases of a type switch.
+            // Conversions written as calls, `Celsius(f)`, are recorded as
+            // calls and resolved to the type by the indexer
+            "type_assertion_expression" | "composite_literal" | "type_conversion_expression" => {
+                if let Some(type_node) = node.child_by_field_name("type") {
+                    self.extract_go_scoped_type_use(&type_node, code, uses);
+                }
+            }
+            "type_case" => {
+                for type_node in node.children_by_field_name("type", &mut node.walk()) {
+                    self.extract_go_scoped_type_use(&type_node, code, uses);
+                }
+            }
+
+            // The target of `type IntSet = Map[int, struct{}]` is used by the
+            // alias
+            "type_alias" => {
+                if let Some(type_node) = node.child_by_field_name("type") {
+                    self.extract_go_scoped_type_use(&type_node, code, uses);
                 }
             }
 
@@ -1632,51 +2969,565 @@ impl GoParser {
         }
     }
 
-    /// Extract type references from Go parameter list
-    fn extract_go_parameter_types<'a>(
+    /// Collect the names under which packages are visible in this file
+    ///
+    /// Uses the explicit alias when present, otherwise the last path segment.
+    /// Dot and blank imports don't introduce a package name and are skipped.
+    fn collect_imported_package_names<'a>(
         &self,
-        params_node: tree_sitter::Node,
+        node: &tree_sitter::Node,
         code: &'a str,
-        context_name: &'a str,
-        uses: &mut Vec<(&'a str, &'a str, Range)>,
+        names: &mut std::collections::HashSet<&'a str>,
     ) {
-        for param in params_node.children(&mut params_node.walk()) {
-            if param.kind() == "parameter_declaration" {
-                // Go parameters have type after name
-                for child in param.children(&mut param.walk()) {
-                    if matches!(
-                        child.kind(),
-                        "type_identifier"
-                            | "pointer_type"
-                            | "array_type"
-                            | "slice_type"
-                            | "map_type"
-                            | "channel_type"
-                    ) {
-                        self.extract_go_type_reference(&child, code, context_name, uses);
+        if node.kind() == "import_spec" {
+            let path = node
+                .child_by_field_name("path")
+                .map(|p| code[p.byte_range()].trim_matches(|c| c == '"' || c == '`'));
+            match node.child_by_field_name("name") {
+                Some(name) if name.kind() == "package_identifier" => {
+                    names.insert(&code[name.byte_range()]);
+                }
+                Some(_) => {}
+                None => {
+                    if let Some(last) = path.and_then(|p| p.rsplit('/').next()) {
+                        names.insert(last);
                     }
                 }
             }
+            return;
+        }
+
+        for child in node.children(&mut node.walk()) {
+            self.collect_imported_package_names(&child, code, names);
         }
     }
 
-    /// Extract type references from Go field declarations
-    fn extract_go_field_types<'a>(
-        &self,
-        field_node: &tree_sitter::Node,
-        code: &'a str,
-        context_name: &'a str,
-        uses: &mut Vec<(&'a str, &'a str, Range)>,
-    ) {
+    /// Whether the identifier `usage` names a local declaration rather than
+    /// an imported package of the same name
+    ///
+    /// A parameter, receiver or local `var`, `const`, `:=` or range variable
+    /// declared before the use, in a scope containing it, shadows the import:
+    /// after `config := Config{}`, `config.Port` is the variable's field. The
+    /// right-hand side of `config := config.Load()` still sees the package.
+    ///
+    /// `declarations` are the [`local_declarations`] of the top-level
+    /// declaration containing `usage`, computed once per function by the
+    /// caller.
+    fn shadows_package(usage: Node, declarations: &[LocalDeclaration], code: &str) -> bool {
+        Self::innermost_declaration(declarations, usage, code).is_some()
+    }
+
+    /// Top-level declaration of the file containing `node`
+    fn top_level_declaration(node: Node) -> Node {
+        let mut top = node;
+        while let Some(parent) = top.parent() {
+            if parent.parent().is_none() {
+                break;
+            }
+            top = parent;
+        }
+        top
+    }
+
+    /// Declaration `usage` refers to among `declarations`, `None` when it
+    /// names no local and so refers to the package or universe
+    ///
+    /// Scopes nest, so of the declarations visible at the use the innermost
+    /// scope wins, then the latest declaration in it: in
+    ///
+    /// ```go
+    /// x := 1
+    /// {
+    ///     x := 2
+    ///     use(x) // the second x
+    /// }
+    /// use(x) // the first x
+    /// ```
+    fn innermost_declaration<'d, 't>(
+        declarations: &'d [LocalDeclaration<'t>],
+        usage: Node,
+        code: &str,
+    ) -> Option<&'d LocalDeclaration<'t>> {
+        let name = &code[usage.byte_range()];
+        declarations
+            .iter()
+            .filter(|d| {
+                d.visible_from <= usage.start_byte()
+                    && d.scope.start_byte() <= usage.start_byte()
+                    && usage.end_byte() <= d.scope.end_byte()
+                    && &code[d.name.byte_range()] == name
+            })
+            .max_by_key(|d| (d.scope.start_byte(), d.visible_from))
+    }
+
+    /// Import path of the package visible as `name` in this file
+    ///
+    /// `name` is the import alias or, without one, the last path segment.
+    fn imported_package_path<'a>(
+        root: tree_sitter::Node,
+        name: &str,
+        code: &'a str,
+    ) -> Option<&'a str> {
+        let mut found = None;
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            match node.kind() {
+                "source_file" | "import_declaration" | "import_spec_list" => {
+                    stack.extend(node.named_children(&mut node.walk()));
+                }
+                "import_spec" => {
+                    let Some(path) = node
+                        .child_by_field_name("path")
+                        .map(|p| code[p.byte_range()].trim_matches(|c| c == '"' || c == '`'))
+                    else {
+                        continue;
+                    };
+                    let visible = match node.child_by_field_name("name") {
+                        Some(alias) if alias.kind() == "package_identifier" => {
+                            &code[alias.byte_range()]
+                        }
+                        Some(_) => continue,
+                        None => path.rsplit('/').next().unwrap_or(path),
+                    };
+                    if visible == name {
+                        found = Some(path);
+                    }
+                }
+                _ => {}
+            }
+        }
+        found
+    }
+
+    /// Extract package-qualified value references used in comparisons and switch cases
+    ///
+    /// Records `pkg.Name` operands of comparison operators and `case` values,
+    /// e.g. `user.Role() != models.RoleUser` or `case models.RoleAdmin:`, as
+    /// references from the enclosing function to `Name`.
+    fn extract_qualified_value_uses_recursive<'a, 't>(
+        &self,
+        node: &tree_sitter::Node<'t>,
+        code: &'a str,
+        packages: &std::collections::HashSet<&'a str>,
+        current_function: Option<&'a str>,
+        declarations: &[LocalDeclaration<'t>],
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        // Locals of each top-level declaration, to tell packages from
+        // variables shadowing them
+        let top_level_declarations;
+        let declarations = if node.parent().is_some_and(|p| p.parent().is_none()) {
+            top_level_declarations = local_declarations(*node, code);
+            &top_level_declarations
+        } else {
+            declarations
+        };
+        let function_context =
+            if matches!(node.kind(), "function_declaration" | "method_declaration") {
+                node.child_by_field_name("name")
+                    .map(|n| &code[n.byte_range()])
+                    .or(current_function)
+            } else {
+                current_function
+            };
+
+        if let Some(context) = function_context {
+            match node.kind() {
+                "binary_expression" => {
+                    let is_comparison = node.child_by_field_name("operator").is_some_and(|op| {
+                        matches!(
+                            &code[op.byte_range()],
+                            "==" | "!=" | "<" | "<=" | ">" | ">="
+                        )
+                    });
+                    if is_comparison {
+                        for field in ["left", "right"] {
+                            if let Some(operand) = node.child_by_field_name(field) {
+                                self.push_qualified_value_use(
+                                    &operand,
+                                    code,
+                                    packages,
+                                    context,
+                                    declarations,
+                                    uses,
+                                );
+                            }
+                        }
+                    }
+                }
+                "expression_case" => {
+                    if let Some(values) = node.child_by_field_name("value") {
+                        for value in values.named_children(&mut values.walk()) {
+                            self.push_qualified_value_use(
+                                &value,
+                                code,
+                                packages,
+                                context,
+                                declarations,
+                                uses,
+                            );
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+
+        for child in node.children(&mut node.walk()) {
+            self.extract_qualified_value_uses_recursive(
+                &child,
+                code,
+                packages,
+                function_context,
+                declarations,
+                uses,
+            );
+        }
+    }
+
+    /// Record `pkg.Name` as a use of `Name` when `pkg` is an imported package
+    ///
+    /// Two-level selectors `pkg.Type.Member` record `Type.Member`. Whether the
+    /// leading identifier is a package or a value is decided by the import
+    /// table, so value chains like `cfg.server.Port` are left alone.
+    fn push_qualified_value_use<'a>(
+        &self,
+        node: &tree_sitter::Node,
+        code: &'a str,
+        packages: &std::collections::HashSet<&'a str>,
+        context: &'a str,
+        declarations: &[LocalDeclaration],
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        if node.kind() != "selector_expression" {
+            return;
+        }
+        let (Some(operand), Some(field)) = (
+            node.child_by_field_name("operand"),
+            node.child_by_field_name("field"),
+        ) else {
+            return;
+        };
+        let is_package = |n: tree_sitter::Node| {
+            n.kind() == "identifier"
+                && packages.contains(&code[n.byte_range()])
+                && !Self::shadows_package(n, declarations, code)
+        };
+        let target = if is_package(operand) {
+            field.byte_range()
+        } else if operand.kind() == "selector_expression" {
+            match (
+                operand.child_by_field_name("operand"),
+                operand.child_by_field_name("field"),
+            ) {
+                (Some(package), Some(type_name)) if is_package(package) => {
+                    type_name.start_byte()..field.end_byte()
+                }
+                _ => return,
+            }
+        } else {
+            return;
+        };
+        let range = Range::new(
+            node.start_position().row as u32,
+            node.start_position().column as u16,
+            node.end_position().row as u32,
+            node.end_position().column as u16,
+        );
+        uses.push((context, &code[target], range));
+    }
+
+    /// Package-level variables with the expressions that initialize them
+    ///
+    /// Covers `var X = f()` at package level and `X = f()` assignments in
+    /// `init()` where `X` is not a local of `init`. Calls and references in
+    /// these expressions are recorded with the variable as their context, so
+    /// `DefaultProcessor = CreateDefaultProcessor()` makes `DefaultProcessor`
+    /// a caller of `CreateDefaultProcessor` next to `init` itself.
+    fn package_var_initializers<'a, 't>(
+        root: tree_sitter::Node<'t>,
+        code: &'a str,
+    ) -> Vec<(&'a str, tree_sitter::Node<'t>)> {
+        let mut initializers = Vec::new();
+        let mut push_pairs = |names: Vec<tree_sitter::Node>, values: Vec<tree_sitter::Node<'t>>| {
+            for (i, name) in names.iter().enumerate() {
+                // `var a, b = pair()` initializes both from the one call
+                let value = if values.len() == 1 {
+                    values.first()
+                } else {
+                    values.get(i)
+                };
+                if let (Some(value), "identifier") = (value, name.kind()) {
+                    let name = &code[name.byte_range()];
+                    if name != "_" {
+                        initializers.push((name, *value));
+                    }
+                }
+            }
+        };
+
+        for decl in root.named_children(&mut root.walk()) {
+            match decl.kind() {
+                "var_declaration" => {
+                    let mut specs = Vec::new();
+                    for child in decl.named_children(&mut decl.walk()) {
+                        match child.kind() {
+                            "var_spec" => specs.push(child),
+                            "var_spec_list" => specs.extend(
+                                child
+                                    .named_children(&mut child.walk())
+                                    .filter(|c| c.kind() == "var_spec"),
+                            ),
+                            _ => {}
+                        }
+                    }
+                    for spec in specs {
+                        let Some(value) = spec.child_by_field_name("value") else {
+                            continue;
+                        };
+                        push_pairs(
+                            spec.children_by_field_name("name", &mut spec.walk())
+                                .collect(),
+                            value.named_children(&mut value.walk()).collect(),
+                        );
+                    }
+                }
+                "function_declaration" => {
+                    let is_init = decl
+                        .child_by_field_name("name")
+                        .is_some_and(|n| &code[n.byte_range()] == "init");
+                    let Some(body) = decl.child_by_field_name("body").filter(|_| is_init) else {
+                        continue;
+                    };
+                    let mut locals = std::collections::HashSet::new();
+                    let mut assignments = Vec::new();
+                    Self::collect_init_assignments(body, code, &mut locals, &mut assignments);
+                    for (left, right) in assignments {
+                        let names: Vec<_> = left
+                            .named_children(&mut left.walk())
+                            .filter(|n| !locals.contains(&code[n.byte_range()]))
+                            .collect();
+                        if names.len() == left.named_child_count() {
+                            push_pairs(names, right.named_children(&mut right.walk()).collect());
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+        initializers
+    }
+
+    /// Locals and `=` assignments of an `init()` body, function literals excluded
+    fn collect_init_assignments<'a, 't>(
+        node: tree_sitter::Node<'t>,
+        code: &'a str,
+        locals: &mut std::collections::HashSet<&'a str>,
+        assignments: &mut Vec<(tree_sitter::Node<'t>, tree_sitter::Node<'t>)>,
+    ) {
+        match node.kind() {
+            "func_literal" => return,
+            "short_var_declaration" => {
+                if let Some(left) = node.child_by_field_name("left") {
+                    for name in left.named_children(&mut left.walk()) {
+                        locals.insert(&code[name.byte_range()]);
+                    }
+                }
+            }
+            "var_spec" | "const_spec" => {
+                for name in node.children_by_field_name("name", &mut node.walk()) {
+                    locals.insert(&code[name.byte_range()]);
+                }
+            }
+            "assignment_statement" => {
+                let is_plain = node
+                    .child_by_field_name("operator")
+                    .is_some_and(|op| op.kind() == "=");
+                if let (true, Some(left), Some(right)) = (
+                    is_plain,
+                    node.child_by_field_name("left"),
+                    node.child_by_field_name("right"),
+                ) {
+                    assignments.push((left, right));
+                }
+            }
+            _ => {}
+        }
+        for child in node.named_children(&mut node.walk()) {
+            Self::collect_init_assignments(child, code, locals, assignments);
+        }
+    }
+
+    /// Values other package-level values depend on through their initializer
+    ///
+    /// Identifiers of the initializer (outside calls and function literals)
+    /// become `(variable, name)` uses; `pkg.Name` selectors are handled by
+    /// [`Self::push_qualified_value_use`].
+    fn extract_initializer_value_uses<'a>(
+        &self,
+        node: tree_sitter::Node,
+        code: &'a str,
+        packages: &std::collections::HashSet<&'a str>,
+        variable: &'a str,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        match node.kind() {
+            "func_literal" => return,
+            "identifier" => {
+                let name = &code[node.byte_range()];
+                let is_callee = node.parent().is_some_and(|p| {
+                    p.kind() == "call_expression" && p.child_by_field_name("function") == Some(node)
+                });
+                // `Config{Name: x}` keys are fields, not values
+                let is_key = node.parent().is_some_and(|p| {
+                    p.kind() == "literal_element"
+                        && p.parent().is_some_and(|k| {
+                            k.kind() == "keyed_element" && k.child_by_field_name("key") == Some(p)
+                        })
+                });
+                if !is_callee && !is_key && name != variable && name != "_" {
+                    uses.push((variable, name, Self::node_range(node)));
+                }
+                return;
+            }
+            "selector_expression" => {
+                let is_callee = node.parent().is_some_and(|p| {
+                    p.kind() == "call_expression" && p.child_by_field_name("function") == Some(node)
+                });
+                if !is_callee {
+                    // Outside function literals a package-level initializer
+                    // declares no locals
+                    self.push_qualified_value_use(&node, code, packages, variable, &[], uses);
+                }
+                // The operand may itself be a value, as in `defaults.Port`
+                if let Some(operand) = node.child_by_field_name("operand") {
+                    if operand.kind() != "identifier"
+                        || !packages.contains(&code[operand.byte_range()])
+                    {
+                        self.extract_initializer_value_uses(
+                            operand, code, packages, variable, uses,
+                        );
+                    }
+                }
+                return;
+            }
+            _ => {}
+        }
+        for child in node.named_children(&mut node.walk()) {
+            self.extract_initializer_value_uses(child, code, packages, variable, uses);
+        }
+    }
+
+    /// Unqualified package-level values referenced in function bodies
+    ///
+    /// An identifier that no parameter, receiver or local in scope at the use
+    /// declares names a value of the package, possibly declared in another
+    /// of its files: `if len(pool) >= MaxConnections` in auth.go uses the
+    /// constant of database.go. Each name is recorded once per function;
+    /// callees, composite literal keys and imported package names are left
+    /// out, and names that are not package symbols simply don't resolve.
+    fn extract_package_value_uses<'a>(
+        root: Node,
+        code: &'a str,
+        packages: &std::collections::HashSet<&'a str>,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let (Some(function), Some(body)) = (
+                decl.child_by_field_name("name")
+                    .map(|n| &code[n.byte_range()]),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+
+            let declarations = local_declarations(decl, code);
+            let mut seen = std::collections::HashSet::new();
+            let mut stack = vec![body];
+            while let Some(node) = stack.pop() {
+                stack.extend(node.named_children(&mut node.walk()));
+                if node.kind() != "identifier" {
+                    continue;
+                }
+                let name = &code[node.byte_range()];
+                let is_callee = node.parent().is_some_and(|p| {
+                    p.kind() == "call_expression" && p.child_by_field_name("function") == Some(node)
+                });
+                let skipped = is_callee
+                    || Self::is_composite_key(node)
+                    || matches!(name, "_" | "nil" | "true" | "false" | "iota")
+                    || packages.contains(name)
+                    || declarations.iter().any(|d| d.name == node)
+                    || Self::innermost_declaration(&declarations, node, code).is_some();
+                if !skipped && seen.insert(name) {
+                    uses.push((function, name, Self::node_range(node)));
+                }
+            }
+        }
+    }
+
+    /// Range of a node, with the parser's 0-based rows as symbols have
+    fn node_range(node: tree_sitter::Node) -> Range {
+        Range::new(
+            node.start_position().row as u32,
+            node.start_position().column as u16,
+            node.end_position().row as u32,
+            node.end_position().column as u16,
+        )
+    }
+
+    /// Extract type references from Go parameter list
+    fn extract_go_parameter_types<'a>(
+        &self,
+        params_node: tree_sitter::Node,
+        code: &'a str,
+        context_name: &'a str,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        for param in params_node.children(&mut params_node.walk()) {
+            if param.kind() == "parameter_declaration" {
+                // Go parameters have type after name
+                for child in param.children(&mut param.walk()) {
+                    if matches!(
+                        child.kind(),
+                        "type_identifier"
+                            | "qualified_type"
+                            | "pointer_type"
+                            | "array_type"
+                            | "slice_type"
+                            | "map_type"
+                            | "channel_type"
+                            | "generic_type"
+                    ) {
+                        self.extract_go_type_reference(&child, code, context_name, uses);
+                    }
+                }
+            }
+        }
+    }
+
+    /// Extract type references from Go field declarations
+    fn extract_go_field_types<'a>(
+        &self,
+        field_node: &tree_sitter::Node,
+        code: &'a str,
+        context_name: &'a str,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
         for child in field_node.children(&mut field_node.walk()) {
             if matches!(
                 child.kind(),
                 "type_identifier"
+                    | "qualified_type"
                     | "pointer_type"
                     | "array_type"
                     | "slice_type"
                     | "map_type"
                     | "channel_type"
+                    | "generic_type"
             ) {
                 self.extract_go_type_reference(&child, code, context_name, uses);
             }
@@ -1702,6 +3553,129 @@ impl GoParser {
         }
     }
 
+    /// Record the types passed in a `type_arguments` node as uses by the
+    /// enclosing declaration
+    ///
+    /// Type parameters in scope are not types: the `K, V` of `*Cache[K, V]`
+    /// in `func NewCache[K comparable, V any]()` or in a method on
+    /// `*Cache[K, V]` are skipped. Nested instantiations are visited on their
+    /// own, so `Cache[string, List[User]]` uses `List` here and `User` there.
+    fn extract_go_type_argument_uses<'a>(
+        &self,
+        arguments: &tree_sitter::Node,
+        code: &'a str,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        let parameters = Self::type_parameters_in_scope(*arguments, code);
+        let context_name = Self::type_use_context(*arguments, code);
+        for element in arguments.named_children(&mut arguments.walk()) {
+            let types: Vec<_> = if element.kind() == "type_elem" {
+                element.named_children(&mut element.walk()).collect()
+            } else {
+                vec![element]
+            };
+            for argument in types {
+                let is_parameter = self
+                    .extract_go_type_name(&argument, code)
+                    .is_some_and(|name| parameters.contains(&name));
+                if !is_parameter {
+                    self.extract_go_type_reference(&argument, code, context_name, uses);
+                }
+            }
+        }
+    }
+
+    /// Record the type written at `type_node` as a use by the enclosing
+    /// declaration, unless it is a type parameter in scope
+    fn extract_go_scoped_type_use<'a>(
+        &self,
+        type_node: &tree_sitter::Node,
+        code: &'a str,
+        uses: &mut Vec<(&'a str, &'a str, Range)>,
+    ) {
+        let is_parameter = self
+            .extract_go_type_name(type_node, code)
+            .is_some_and(|name| Self::type_parameters_in_scope(*type_node, code).contains(&name));
+        if !is_parameter {
+            let context_name = Self::type_use_context(*type_node, code);
+            self.extract_go_type_reference(type_node, code, context_name, uses);
+        }
+    }
+
+    /// Names of the type parameters visible at `node`: those of the
+    /// enclosing generic function or type, or of a method and its receiver
+    /// type
+    fn type_parameters_in_scope<'a>(node: Node, code: &'a str) -> Vec<&'a str> {
+        let mut parameters = Vec::new();
+        let mut current = node.parent();
+        while let Some(declaration) = current {
+            parameters.extend(Self::declared_type_parameters(declaration, code));
+            if declaration.kind() == "method_declaration" {
+                parameters.extend(receiver_type_parameters(&code[declaration.byte_range()]));
+            }
+            current = declaration.parent();
+        }
+        parameters
+    }
+
+    /// Names of the type parameters a function, type or method declares
+    /// itself; a method's receiver parameters are not its own
+    ///
+    /// Go has no method type parameters, so the grammar leaves the list of
+    /// `func (r *Repository[T]) Transform[U any](...)` unparsed and it is
+    /// read from the declaration's text up to its parameters.
+    fn declared_type_parameters<'a>(declaration: Node, code: &'a str) -> Vec<&'a str> {
+        match declaration.kind() {
+            "function_declaration" | "type_spec" | "type_alias" => {
+                let Some(list) = declaration.child_by_field_name("type_parameters") else {
+                    return Vec::new();
+                };
+                list.named_children(&mut list.walk())
+                    .flat_map(|parameter| {
+                        parameter
+                            .children_by_field_name("name", &mut parameter.walk())
+                            .map(|name| &code[name.byte_range()])
+                            .collect::<Vec<_>>()
+                    })
+                    .collect()
+            }
+            "method_declaration" => {
+                let end = declaration
+                    .child_by_field_name("parameters")
+                    .map_or(declaration.end_byte(), |parameters| parameters.start_byte());
+                type_parameters_from_signature(&code[declaration.start_byte()..end])
+                    .into_iter()
+                    .map(|(name, _)| name)
+                    .collect()
+            }
+            _ => Vec::new(),
+        }
+    }
+
+    /// Name of the declaration a type use inside `node` is attributed to:
+    /// the enclosing function or method, else the type or variable declared
+    fn type_use_context<'a>(node: Node, code: &'a str) -> &'a str {
+        let mut declared = None;
+        let mut current = node.parent();
+        while let Some(declaration) = current {
+            match declaration.kind() {
+                "function_declaration" | "method_declaration" => {
+                    if let Some(name) = declaration.child_by_field_name("name") {
+                        return &code[name.byte_range()];
+                    }
+                }
+                "type_spec" | "type_alias" | "var_spec" | "const_spec" if declared.is_none() => {
+                    declared = declaration
+                        .child_by_field_name("name")
+                        .map(|name| &code[name.byte_range()]);
+                }
+                _ => {}
+            }
+            current = declaration.parent();
+        }
+        declared.unwrap_or("package")
+    }
+
     /// Extract type name from Go type node
     #[allow(clippy::only_used_in_recursion)]
     fn extract_go_type_name<'a>(&self, node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
@@ -1711,6 +3685,12 @@ impl GoParser {
                 // For qualified types like pkg.Type, get the full name
                 Some(&code[node.byte_range()])
             }
+            "generic_type" => {
+                // For instantiated types like Cache[string, User], get the
+                // generic type; its arguments are uses of their own
+                let base = node.child_by_field_name("type")?;
+                self.extract_go_type_name(&base, code)
+            }
             "pointer_type" => {
                 // For pointer types like *User, get the underlying type
                 if let Some(child) = node.children(&mut node.walk()).nth(1) {
@@ -1757,8 +3737,9 @@ impl GoParser {
         match node.kind() {
             // Go interface types with method elements
             "interface_type" => {
-                // We need to get the interface name from the parent type_spec
-                let interface_name = "interface"; // Default name, could be improved
+                // Interface name from the parent type_spec; anonymous interfaces
+                // (e.g. in parameter types) keep a generic name
+                let interface_name = Self::interface_type_name(node, code).unwrap_or("interface");
 
                 for child in node.children(&mut node.walk()) {
                     if child.kind() == "method_elem" {
@@ -1785,18 +3766,11 @@ impl GoParser {
                 if let Some(name_node) = node.child_by_field_name("name") {
                     let method_name = &code[name_node.byte_range()];
 
-                    // Extract receiver type for context
-                    let receiver_type = if let Some(receiver) = node.child_by_field_name("receiver")
-                    {
-                        // Get the receiver type from the parameter list
-                        receiver
-                            .children(&mut receiver.walk())
-                            .find(|n| matches!(n.kind(), "type_identifier" | "pointer_type"))
-                            .map(|n| &code[n.byte_range()])
-                            .unwrap_or("unknown")
-                    } else {
-                        "unknown"
-                    };
+                    // Extract receiver type for context: the base type name, so that
+                    // `(u *User)` and `(s *Stack[T])` attach to `User` and `Stack`
+                    let receiver_type = super::analysis::receiver_type_node(node)
+                        .and_then(|type_node| Self::receiver_base_type_name(&type_node, code))
+                        .unwrap_or("unknown");
 
                     let range = Range::new(
                         node.start_position().row as u32,
@@ -1817,806 +3791,4687 @@ impl GoParser {
         }
     }
 
-    #[allow(clippy::only_used_in_recursion)]
-    fn extract_method_calls_recursive(
-        &self,
-        node: &tree_sitter::Node,
-        code: &str,
-        current_function: Option<&str>,
-        calls: &mut Vec<MethodCall>,
-    ) {
-        // Track function context for Go
-        let function_context = if node.kind() == "function_declaration"
-            || node.kind() == "method_declaration"
-            || node.kind() == "func_literal"
-        {
-            // Extract function name
-            if let Some(name_node) = node.child_by_field_name("name") {
-                Some(&code[name_node.byte_range()])
-            } else {
-                current_function
-            }
-        } else {
-            current_function
-        };
+    /// Base type name of a type declared in this package: `*User` -> `User`,
+    /// `*Stack[T]` -> `Stack`; imported types such as `*models.User` give `None`
+    fn receiver_base_type_name<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        super::analysis::base_type_node(*node)
+            .filter(|base| base.kind() == "type_identifier")
+            .map(|base| &code[base.byte_range()])
+    }
 
-        // Check for method calls (Go uses selector_expression)
-        if node.kind() == "call_expression" {
-            if let Some(function_node) = node.child_by_field_name("function") {
-                if function_node.kind() == "selector_expression" {
-                    // It's a method call!
-                    if let Some((receiver, method_name, is_static)) =
-                        self.extract_go_method_signature(&function_node, code)
-                    {
-                        if let Some(context) = function_context {
-                            let range = Range {
-                                start_line: (node.start_position().row + 1) as u32,
-                                start_column: node.start_position().column as u16,
-                                end_line: (node.end_position().row + 1) as u32,
-                                end_column: node.end_position().column as u16,
-                            };
+    /// Element type node of a container type: the `*User` of `[]*User`,
+    /// `[4]*User`, `map[string]*User` or `chan *User`
+    fn element_type_node<'t>(node: &Node<'t>) -> Option<Node<'t>> {
+        match node.kind() {
+            "slice_type" | "array_type" => node.child_by_field_name("element"),
+            "map_type" | "channel_type" => node.child_by_field_name("value"),
+            "parenthesized_type" => Self::element_type_node(&node.named_child(0)?),
+            _ => None,
+        }
+    }
 
-                            let method_call = MethodCall {
-                                caller: context.to_string(),
-                                method_name: method_name.to_string(),
-                                receiver: receiver.map(|r| r.to_string()),
-                                is_static,
-                                range,
-                            };
+    /// Base type name of the elements of a container type: `[]User`,
+    /// `[4]*User`, `map[string]User` and `chan User` -> `User`
+    fn element_base_type_name<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        Self::binding_type_name(&Self::element_type_node(node)?, code)
+    }
 
-                            calls.push(method_call);
+    /// Type recorded for a variable declared with type `node`
+    ///
+    /// The base type of a named type, and the type as written for a slice,
+    /// array, map or channel: `[]User` has no methods of `User`, which are
+    /// called through an element (`users[i].Verify()`, see
+    /// [`Self::element_base_type_name`]).
+    fn binding_type_name<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        if let Some(base) = Self::receiver_base_type_name(node, code) {
+            return Some(base);
+        }
+        let mut container = *node;
+        while container.kind() == "parenthesized_type" {
+            container = container.named_child(0)?;
+        }
+        Self::element_type_node(&container).map(|_| &code[container.byte_range()])
+    }
+
+    /// Variable bindings with their declared and concrete types
+    ///
+    /// Records parameters, receivers and variables whose type is evident from
+    /// the declaration or the assigned value: `p *Person`, `var u User`,
+    /// `u := &User{...}`, `u := new(User)`, `u := NewUser(...)`, type
+    /// assertions `fp := x.(*FileProcessor)` and plain assignments
+    /// `u = &User{...}`. Pointer and value types map to the same
+    /// base type because Go adjusts receivers automatically (`(&u).Verify()`
+    /// and `(*p).GetFullName()` use the same method sets).
+    ///
+    /// `var p DataProcessor = &FileProcessor{}` keeps both types, so dispatch
+    /// can narrow an interface variable to the value it holds. Assertions
+    /// narrow the same way in both forms: `fp := x.(*FileProcessor)`, which
+    /// panics on failure, and `fp, ok := x.(*FileProcessor)`, which binds
+    /// `fp` only. A type switch binds its variable once per case, with the
+    /// case's type. Bindings are in source order; a later assignment
+    /// supersedes earlier ones.
+    ///
+    /// Containers are recorded with their type as written and the base type
+    /// of their elements (see [`Self::binding_type_name`]), including
+    /// `make([]User, n)` results and `users = append(users, ...)`, which
+    /// keeps the types of `users`.
+    ///
+    /// Instantiated generic types keep their type arguments, from
+    /// `&Stack[string]{}`, `var s Stack[string]` or a constructor call such
+    /// as `NewMap[int, string]()` (see [`Self::value_type_arguments`]).
+    ///
+    /// Elements read from a container field, by `range a.processors` or
+    /// `p := a.processors[name]`, are declared with the field's element type
+    /// and narrowed to the one concrete type stored in the field, if any
+    /// (see [`Self::stored_field_types`]).
+    pub fn find_variable_bindings<'a>(&mut self, code: &'a str) -> Vec<GoVariableBinding<'a>> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        Self::variable_bindings(tree.root_node(), code)
+            .into_iter()
+            .map(|(binding, _)| binding)
+            .collect()
+    }
+
+    /// Variable bindings under `root`, each with the identifier it binds
+    /// (see [`Self::find_variable_bindings`])
+    fn variable_bindings<'a, 't>(
+        root: Node<'t>,
+        code: &'a str,
+    ) -> Vec<(GoVariableBinding<'a>, Node<'t>)> {
+        let layout = Self::struct_layout(root, code);
+        let mut bindings = Vec::new();
+        let mut bound_names = Vec::new();
+        // Bindings of elements read from a field, as (index, owner, field)
+        let mut field_elements = Vec::new();
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            let range = Range::new(
+                node.start_position().row as u32,
+                node.start_position().column as u16,
+                node.end_position().row as u32,
+                node.end_position().column as u16,
+            );
+            match node.kind() {
+                "parameter_declaration" | "var_spec" => {
+                    let type_node = node.child_by_field_name("type");
+                    let declared_type = type_node.and_then(|t| Self::binding_type_name(&t, code));
+                    let values: Vec<_> = node
+                        .child_by_field_name("value")
+                        .map(|v| v.named_children(&mut v.walk()).collect())
+                        .unwrap_or_default();
+                    let names: Vec<_> = node
+                        .children_by_field_name("name", &mut node.walk())
+                        .collect();
+                    for (i, name) in names.iter().enumerate() {
+                        let value = values
+                            .get(i)
+                            .and_then(|value| Self::value_binding(value, root, &bindings, code));
+                        let concrete_type = value.map(|(concrete, _)| concrete);
+                        let type_arguments = match value {
+                            Some((_, type_arguments)) => type_arguments,
+                            None => type_node.and_then(|t| Self::type_arguments_of(&t, code)),
+                        };
+                        let element_type = values
+                            .get(i)
+                            .and_then(|value| {
+                                Self::value_element_type(value, root, &bindings, code)
+                            })
+                            .or_else(|| {
+                                type_node.and_then(|t| Self::element_base_type_name(&t, code))
+                            });
+                        if declared_type.is_some() || concrete_type.is_some() {
+                            bound_names.push(*name);
+                            bindings.push(GoVariableBinding {
+                                name: &code[name.byte_range()],
+                                declared_type,
+                                concrete_type,
+                                type_arguments,
+                                element_type,
+                                range,
+                            });
+                        }
+                    }
+                }
+                "short_var_declaration" | "assignment_statement" => {
+                    let is_plain_assignment = node.kind() == "short_var_declaration"
+                        || node
+                            .child_by_field_name("operator")
+                            .is_some_and(|op| &code[op.byte_range()] == "=");
+                    if let (true, Some(left), Some(right)) = (
+                        is_plain_assignment,
+                        node.child_by_field_name("left"),
+                        node.child_by_field_name("right"),
+                    ) {
+                        let names: Vec<_> = left.named_children(&mut left.walk()).collect();
+                        let values: Vec<_> = right.named_children(&mut right.walk()).collect();
+                        let value_types: Vec<_> = match values.as_slice() {
+                            // `a, b := Pair(1, "x")` binds each result
+                            [call] if names.len() > 1 => {
+                                match Self::generic_call_result_types(call, root, &bindings, code) {
+                                    Some(results) => results
+                                        .into_iter()
+                                        .map(|result| result.map(|t| (t, None)))
+                                        .collect(),
+                                    None => vec![Self::value_binding(call, root, &bindings, code)],
+                                }
+                            }
+                            _ => values
+                                .iter()
+                                .map(|value| Self::value_binding(value, root, &bindings, code))
+                                .collect(),
+                        };
+                        for (i, (name, value_type)) in names.iter().zip(value_types).enumerate() {
+                            if name.kind() != "identifier" {
+                                continue;
+                            }
+                            // `p := a.processors[name]` or `p, ok := ...`
+                            let element = values
+                                .get(i)
+                                .filter(|_| value_type.is_none())
+                                .filter(|_| node.kind() == "short_var_declaration")
+                                .filter(|value| value.kind() == "index_expression")
+                                .and_then(|value| value.child_by_field_name("operand"))
+                                .and_then(|operand| {
+                                    Self::container_field(&operand, code, &bindings, &layout)
+                                });
+                            if let Some((owner, field, element)) = element {
+                                field_elements.push((bindings.len(), owner, field));
+                                bound_names.push(*name);
+                                bindings.push(GoVariableBinding {
+                                    name: &code[name.byte_range()],
+                                    declared_type: Some(element),
+                                    concrete_type: None,
+                                    type_arguments: None,
+                                    element_type: None,
+                                    range,
+                                });
+                            }
+                            if let Some((concrete, type_arguments)) = value_type {
+                                // `:=` declares the variable with the value's type;
+                                // `=` keeps the declared type from elsewhere
+                                let declared_type =
+                                    (node.kind() == "short_var_declaration").then_some(concrete);
+                                let element_type = values.get(i).and_then(|value| {
+                                    Self::value_element_type(value, root, &bindings, code)
+                                });
+                                bound_names.push(*name);
+                                bindings.push(GoVariableBinding {
+                                    name: &code[name.byte_range()],
+                                    declared_type,
+                                    concrete_type: Some(concrete),
+                                    type_arguments,
+                                    element_type,
+                                    range,
+                                });
+                            }
+                        }
+                    }
+                }
+                // `case string:` of `switch v := x.(type)` binds `v` as a string
+                "type_case" => {
+                    let alias = Self::type_switch_alias(node).map(|(alias, _)| alias);
+                    let case_node = Self::type_case_type(node, code);
+                    let case_type = case_node.and_then(|t| Self::binding_type_name(&t, code));
+                    if let (Some(alias), Some(case_type)) = (alias, case_type) {
+                        bound_names.push(alias);
+                        bindings.push(GoVariableBinding {
+                            name: &code[alias.byte_range()],
+                            declared_type: Some(case_type),
+                            concrete_type: Some(case_type),
+                            type_arguments: None,
+                            element_type: case_node
+                                .and_then(|t| Self::element_base_type_name(&t, code)),
+                            range,
+                        });
+                    }
+                }
+                "range_clause" => {
+                    let right = node.child_by_field_name("right");
+                    let element =
+                        right.and_then(|right| Self::range_element(&right, root, &bindings, code));
+                    let names: Vec<_> = node
+                        .child_by_field_name("left")
+                        .map(|left| left.named_children(&mut left.walk()).collect())
+                        .unwrap_or_default();
+                    let field = right
+                        .and_then(|right| Self::container_field(&right, code, &bindings, &layout));
+                    if let (None, Some((owner, field, element))) = (element, field) {
+                        // `range a.processors`: the value is the second name.
+                        // A single name is a key or index, or a channel's
+                        // value, which the field's type doesn't tell apart
+                        if let Some(name) = names
+                            .get(1)
+                            .filter(|n| n.kind() == "identifier" && &code[n.byte_range()] != "_")
+                        {
+                            field_elements.push((bindings.len(), owner, field));
+                            bound_names.push(*name);
+                            bindings.push(GoVariableBinding {
+                                name: &code[name.byte_range()],
+                                declared_type: Some(element),
+                                concrete_type: None,
+                                type_arguments: None,
+                                element_type: None,
+                                range,
+                            });
+                        }
+                    } else if let Some((index, element)) = element {
+                        if let Some(name) = names
+                            .get(index)
+                            .filter(|n| n.kind() == "identifier" && &code[n.byte_range()] != "_")
+                        {
+                            bound_names.push(*name);
+                            bindings.push(GoVariableBinding {
+                                name: &code[name.byte_range()],
+                                declared_type: Some(element),
+                                concrete_type: None,
+                                type_arguments: None,
+                                element_type: None,
+                                range,
+                            });
                         }
                     }
                 }
+                _ => {}
             }
+            let children: Vec<_> = node.named_children(&mut node.walk()).collect();
+            stack.extend(children.into_iter().rev());
         }
 
-        // Recurse
-        let mut cursor = node.walk();
-        for child in node.children(&mut cursor) {
-            self.extract_method_calls_recursive(&child, code, function_context, calls);
+        let stored = Self::stored_field_types(root, code, &bindings, &layout);
+        for (index, owner, field) in field_elements {
+            if let Some(Some(concrete)) = stored.get(&(owner, field)) {
+                bindings[index].concrete_type = Some(concrete);
+            }
         }
+        bindings.into_iter().zip(bound_names).collect()
     }
 
-    fn extract_go_method_signature<'a>(
-        &self,
-        selector_expr: &tree_sitter::Node,
+    /// Type of the receiver variable of each method call, as (receiver,
+    /// type, call range)
+    ///
+    /// A receiver has the type of the latest binding, before the call, of
+    /// the local it refers to (see [`Self::innermost_declaration`]), so
+    /// `q = &JSONProcessor{}` narrows `q` for the calls after it in its
+    /// function, and a `q` declared elsewhere keeps its own type. Locals of
+    /// unknown type map to `None`; receivers that are no local, such as
+    /// package variables, are left out.
+    pub fn find_receiver_types_in<'a>(
+        &mut self,
         code: &'a str,
-    ) -> Option<(Option<&'a str>, &'a str, bool)> {
-        // selector_expression has 'operand' and 'field' fields
-        let operand = selector_expr.child_by_field_name("operand");
-        let field = selector_expr.child_by_field_name("field");
-
-        match (operand, field) {
-            (Some(obj), Some(prop)) => {
-                let receiver = &code[obj.byte_range()];
-                let method_name = &code[prop.byte_range()];
+    ) -> Vec<(&'a str, Option<&'a str>, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        let root = tree.root_node();
+        let bindings = Self::variable_bindings(root, code);
 
-                // In Go, we can't easily distinguish between static and instance calls
-                // without type information, so we'll assume instance calls
-                let is_static = false;
+        let mut receivers = Vec::new();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let declarations = local_declarations(decl, code);
+            // The bindings within the declaration, with the local each binds
+            // (itself for a declaration, the one in scope for an assignment)
+            // and the positions it holds between: after its statement, or
+            // within its case for a type switch
+            let bound: Vec<_> = bindings
+                .iter()
+                .filter(|(_, name)| decl.byte_range().contains(&name.start_byte()))
+                .filter_map(|(binding, name)| {
+                    let local = match declarations.iter().find(|d| d.name == *name) {
+                        Some(declaration) => declaration,
+                        None => Self::innermost_declaration(&declarations, *name, code)?,
+                    };
+                    let start = Point::new(
+                        binding.range.start_line as usize,
+                        binding.range.start_column as usize,
+                    );
+                    let end = Point::new(
+                        binding.range.end_line as usize,
+                        binding.range.end_column as usize,
+                    );
+                    let is_case = name
+                        .parent()
+                        .and_then(|list| list.parent())
+                        .is_some_and(|n| n.kind() == "type_switch_statement");
+                    let (from, until) = if is_case {
+                        (start, Some(end))
+                    } else {
+                        (end, None)
+                    };
+                    Some((from, until, local.name, binding.narrowed_type()))
+                })
+                .collect();
 
-                Some((Some(receiver), method_name, is_static))
+            let mut stack = vec![decl];
+            while let Some(node) = stack.pop() {
+                stack.extend(node.named_children(&mut node.walk()));
+                if node.kind() != "call_expression" {
+                    continue;
+                }
+                let Some(operand) = node
+                    .child_by_field_name("function")
+                    .filter(|f| f.kind() == "selector_expression")
+                    .and_then(|f| f.child_by_field_name("operand"))
+                else {
+                    continue;
+                };
+                let receiver = Self::strip_receiver_indirection(operand);
+                if receiver.kind() != "identifier" {
+                    continue;
+                }
+                let Some(local) = Self::innermost_declaration(&declarations, receiver, code)
+                    .filter(|d| !d.is_type())
+                else {
+                    continue;
+                };
+                let at = receiver.start_position();
+                let receiver_type = bound
+                    .iter()
+                    .rfind(|(from, until, name, _)| {
+                        *name == local.name && *from <= at && until.is_none_or(|end| at < end)
+                    })
+                    .and_then(|(_, _, _, typ)| *typ);
+                let range = Range::new(
+                    node.start_position().row as u32,
+                    node.start_position().column as u16,
+                    node.end_position().row as u32,
+                    node.end_position().column as u16,
+                );
+                receivers.push((&code[receiver.byte_range()], receiver_type, range));
             }
-            _ => None,
         }
+        receivers
     }
 
-    fn extract_function_name<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
-        match node.kind() {
-            "identifier" => Some(&code[node.byte_range()]),
-            "selector_expression" => {
-                // For qualified function calls like pkg.Function()
-                Some(&code[node.byte_range()])
+    /// Struct field a container expression such as `a.processors` selects,
+    /// as (declaring type, field, element type)
+    fn container_field<'a>(
+        node: &Node,
+        code: &'a str,
+        bindings: &[GoVariableBinding<'a>],
+        layout: &StructLayout<'a>,
+    ) -> Option<(&'a str, &'a str, &'a str)> {
+        if node.kind() != "selector_expression" {
+            return None;
+        }
+        let owner =
+            Self::expression_type(node.child_by_field_name("operand")?, code, bindings, layout)?;
+        let field = &code[node.child_by_field_name("field")?.byte_range()];
+        let (declaring, field) = Self::promoted_field(layout, owner, field)?;
+        Some((declaring, field.name, field.element_type?))
+    }
+
+    /// Concrete types of the values stored in container fields, keyed by
+    /// (declaring type, field)
+    ///
+    /// Values are stored by `a.processors[name] = &DefaultProcessor{}` and
+    /// `a.fallbacks = append(a.fallbacks, ...)`. A field maps to a type only
+    /// when every value stored at these sites is of that one evident type;
+    /// a value of unknown type, or replacing the container with anything
+    /// but `make(...)`, maps it to `None`. Stores in other files are not
+    /// seen, so this is conservative within a file only.
+    fn stored_field_types<'a>(
+        root: Node,
+        code: &'a str,
+        bindings: &[GoVariableBinding<'a>],
+        layout: &StructLayout<'a>,
+    ) -> std::collections::HashMap<(&'a str, &'a str), Option<&'a str>> {
+        let mut stored: std::collections::HashMap<_, Option<&'a str>> =
+            std::collections::HashMap::new();
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            stack.extend(node.named_children(&mut node.walk()));
+            if node.kind() != "assignment_statement"
+                || node
+                    .child_by_field_name("operator")
+                    .is_none_or(|op| &code[op.byte_range()] != "=")
+            {
+                continue;
+            }
+            let (Some(left), Some(right)) = (
+                node.child_by_field_name("left"),
+                node.child_by_field_name("right"),
+            ) else {
+                continue;
+            };
+            let targets: Vec<_> = left.named_children(&mut left.walk()).collect();
+            let values: Vec<_> = right.named_children(&mut right.walk()).collect();
+            for (target, value) in targets.iter().zip(&values) {
+                let (container, elements) = match target.kind() {
+                    "index_expression" => match target.child_by_field_name("operand") {
+                        Some(operand) => (operand, vec![*value]),
+                        None => continue,
+                    },
+                    "selector_expression" => {
+                        let arguments = Self::appended_values(value, target, code);
+                        let is_make = value.kind() == "call_expression"
+                            && value
+                                .child_by_field_name("function")
+                                .is_some_and(|f| &code[f.byte_range()] == "make");
+                        match arguments {
+                            Some(arguments) => (*target, arguments),
+                            None if is_make => continue,
+                            None => (*target, vec![*value]),
+                        }
+                    }
+                    _ => continue,
+                };
+                let Some((owner, field, _)) =
+                    Self::container_field(&container, code, bindings, layout)
+                else {
+                    continue;
+                };
+                let Some(first) = elements.first() else {
+                    continue;
+                };
+                let entry = stored
+                    .entry((owner, field))
+                    .or_insert_with(|| Self::value_base_type_name(first, root, code));
+                for element in elements {
+                    let concrete = Self::value_base_type_name(&element, root, code);
+                    if concrete.is_none() || concrete != *entry {
+                        *entry = None;
+                    }
+                }
             }
-            _ => None,
         }
+        stored
     }
 
-    /// Extract generic type parameters from a signature
-    /// Returns a list of type parameter names like ["T", "K", "V"]
-    fn extract_generic_params_from_signature(&self, signature: &str) -> Vec<String> {
-        let mut params = Vec::new();
+    /// Values appended by `target = append(target, values...)`
+    ///
+    /// A spread `append(target, others...)` gives the spread argument, whose
+    /// type is not a value's type.
+    fn appended_values<'t>(value: &Node<'t>, target: &Node, code: &str) -> Option<Vec<Node<'t>>> {
+        if value.kind() != "call_expression" {
+            return None;
+        }
+        let function = value.child_by_field_name("function")?;
+        if &code[function.byte_range()] != "append" {
+            return None;
+        }
+        let arguments = value.child_by_field_name("arguments")?;
+        let mut arguments: Vec<_> = arguments.named_children(&mut arguments.walk()).collect();
+        if arguments.is_empty() || code[arguments[0].byte_range()] != code[target.byte_range()] {
+            return None;
+        }
+        arguments.remove(0);
+        Some(arguments)
+    }
 
-        // Look for generic parameter section like [T any, K comparable, V SomeInterface]
-        if let Some(start) = signature.find('[') {
-            if let Some(end) = signature[start..].find(']') {
-                let generic_section = &signature[start + 1..start + end];
+    /// Field accesses `x.field` on values of known type
+    ///
+    /// Returns (function, `Type.field`, range) tuples. The operand is typed
+    /// from the variable bindings of the enclosing function and, for chains
+    /// such as `a.WorkerPool.jobQueue`, from the fields of the structs in
+    /// this file. A field promoted from an embedded struct declared here is
+    /// reported on the declaring type, so `a.jobQueue` with `Application`
+    /// embedding `*WorkerPool` gives `WorkerPool.jobQueue`; otherwise the
+    /// operand's type is kept and promotion is left to resolution. Whether
+    /// an embedded pointer is nil at run time does not affect the access.
+    pub fn find_field_accesses_in<'a>(&mut self, code: &'a str) -> Vec<(&'a str, String, Range)> {
+        let bindings = self.find_variable_bindings(code);
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+        let root = tree.root_node();
+        let layout = Self::struct_layout(root, code);
 
-                // Parse parameters separated by commas
-                for param in generic_section.split(',') {
-                    let param = param.trim();
-                    if param.is_empty() {
-                        continue;
+        let mut accesses = Vec::new();
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let (Some(name), Some(body)) = (
+                decl.child_by_field_name("name"),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+            let context = &code[name.byte_range()];
+            let (first_row, last_row) = (
+                decl.start_position().row as u32,
+                decl.end_position().row as u32,
+            );
+            let scope: Vec<_> = bindings
+                .iter()
+                .filter(|b| (first_row..=last_row).contains(&b.range.start_line))
+                .copied()
+                .collect();
+
+            let mut stack = vec![body];
+            while let Some(node) = stack.pop() {
+                if node.kind() == "selector_expression" {
+                    let is_callee = node.parent().is_some_and(|p| {
+                        p.kind() == "call_expression"
+                            && p.child_by_field_name("function") == Some(node)
+                    });
+                    if let (false, Some(operand), Some(field)) = (
+                        is_callee,
+                        node.child_by_field_name("operand"),
+                        node.child_by_field_name("field"),
+                    ) {
+                        let field = &code[field.byte_range()];
+                        if let Some(owner) = Self::expression_type(operand, code, &scope, &layout) {
+                            let declaring = Self::promoted_field(&layout, owner, field)
+                                .map_or(owner, |(declaring, _)| declaring);
+                            accesses.push((
+                                context,
+                                format!("{declaring}.{field}"),
+                                Self::node_range(node),
+                            ));
+                        }
                     }
+                }
+                stack.extend(node.named_children(&mut node.walk()));
+            }
+        }
+        accesses.sort_by_key(|(_, _, range)| (range.start_line, range.start_column));
+        accesses
+    }
 
-                    // Extract just the parameter name (first word)
-                    if let Some(param_name) = param.split_whitespace().next() {
-                        params.push(param_name.to_string());
+    /// Fields of the structs declared in a file
+    fn struct_layout<'a>(root: Node, code: &'a str) -> StructLayout<'a> {
+        let mut layout = StructLayout::new();
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            if node.kind() == "type_spec" {
+                if let (Some(name), Some(body)) = (
+                    node.child_by_field_name("name"),
+                    node.child_by_field_name("type"),
+                ) {
+                    if body.kind() == "struct_type" {
+                        let fields = layout.entry(&code[name.byte_range()]).or_default();
+                        let mut field_stack = vec![body];
+                        while let Some(field) = field_stack.pop() {
+                            if field.kind() != "field_declaration" {
+                                field_stack.extend(field.named_children(&mut field.walk()));
+                                continue;
+                            }
+                            let Some(type_node) = field.child_by_field_name("type") else {
+                                continue;
+                            };
+                            let type_name = Self::binding_type_name(&type_node, code);
+                            let element_type = Self::element_base_type_name(&type_node, code);
+                            let names: Vec<_> = field
+                                .children_by_field_name("name", &mut field.walk())
+                                .map(|n| &code[n.byte_range()])
+                                .collect();
+                            if names.is_empty() {
+                                if let Some(embedded) = type_name {
+                                    fields.push(StructField {
+                                        name: embedded,
+                                        type_name,
+                                        element_type,
+                                        embedded: true,
+                                    });
+                                }
+                            }
+                            for name in names {
+                                fields.push(StructField {
+                                    name,
+                                    type_name,
+                                    element_type,
+                                    embedded: false,
+                                });
+                            }
+                        }
                     }
                 }
             }
+            stack.extend(node.named_children(&mut node.walk()));
         }
+        layout
+    }
 
-        params
+    /// Declaring type and field of `field` selected on `type_name`
+    ///
+    /// Searches the type itself, then its embedded structs breadth-first:
+    /// the shallowest declaration wins, and two at the same depth make the
+    /// selector ambiguous (`None`).
+    fn promoted_field<'a>(
+        layout: &StructLayout<'a>,
+        type_name: &'a str,
+        field: &str,
+    ) -> Option<(&'a str, StructField<'a>)> {
+        let mut level = vec![type_name];
+        let mut visited = std::collections::HashSet::new();
+        while !level.is_empty() {
+            let mut found = None;
+            let mut next = Vec::new();
+            for owner in level {
+                if !visited.insert(owner) {
+                    continue;
+                }
+                let Some(fields) = layout.get(owner) else {
+                    continue;
+                };
+                for candidate in fields {
+                    if candidate.name == field {
+                        if found.is_some() {
+                            return None;
+                        }
+                        found = Some((owner, *candidate));
+                    }
+                    if candidate.embedded {
+                        next.extend(candidate.type_name);
+                    }
+                }
+            }
+            if found.is_some() {
+                return found;
+            }
+            level = next;
+        }
+        None
     }
-}
 
-impl LanguageParser for GoParser {
-    fn parse(
-        &mut self,
-        code: &str,
-        file_id: FileId,
-        symbol_counter: &mut SymbolCounter,
-    ) -> Vec<Symbol> {
-        self.parse(code, file_id, symbol_counter)
+    /// Base type of a field-access operand
+    fn expression_type<'a>(
+        node: Node,
+        code: &'a str,
+        scope: &[GoVariableBinding<'a>],
+        layout: &StructLayout<'a>,
+    ) -> Option<&'a str> {
+        let node = Self::strip_receiver_indirection(node);
+        match node.kind() {
+            "identifier" => Self::scoped_binding(node, code, scope)?.narrowed_type(),
+            // `users[i].Name` selects on an element
+            "index_expression" => Self::expression_element_type(
+                node.child_by_field_name("operand")?,
+                code,
+                scope,
+                layout,
+            ),
+            "selector_expression" => {
+                let owner = Self::expression_type(
+                    node.child_by_field_name("operand")?,
+                    code,
+                    scope,
+                    layout,
+                )?;
+                let field = &code[node.child_by_field_name("field")?.byte_range()];
+                Self::promoted_field(layout, owner, field)?.1.type_name
+            }
+            // `(Config(raw)).Host` or `(&Config{}).Host`
+            "composite_literal" | "call_expression" => {
+                let mut root = node;
+                while let Some(parent) = root.parent() {
+                    root = parent;
+                }
+                Self::value_base_type_name(&node, root, code)
+            }
+            _ => None,
+        }
     }
 
-    fn as_any(&self) -> &dyn Any {
-        self
+    /// Latest binding of the identifier `node` declared at or before its row
+    fn scoped_binding<'a, 'b>(
+        node: Node,
+        code: &str,
+        scope: &'b [GoVariableBinding<'a>],
+    ) -> Option<&'b GoVariableBinding<'a>> {
+        let name = &code[node.byte_range()];
+        let row = node.start_position().row as u32;
+        scope
+            .iter()
+            .rev()
+            .find(|b| b.name == name && b.range.start_line <= row)
     }
 
-    fn extract_doc_comment(&self, node: &Node, code: &str) -> Option<String> {
-        // Extract Go-style documentation comments (//)
-        // For type_spec nodes, check the parent type_declaration for comments
-        // For other nodes, check the current node's previous siblings
+    /// Base type of the elements of a container expression: a variable or
+    /// a field of a slice, array, map or channel type
+    fn expression_element_type<'a>(
+        node: Node,
+        code: &'a str,
+        scope: &[GoVariableBinding<'a>],
+        layout: &StructLayout<'a>,
+    ) -> Option<&'a str> {
+        let node = Self::strip_receiver_indirection(node);
+        match node.kind() {
+            "identifier" => Self::scoped_binding(node, code, scope)?.element_type,
+            "selector_expression" => {
+                let owner = Self::expression_type(
+                    node.child_by_field_name("operand")?,
+                    code,
+                    scope,
+                    layout,
+                )?;
+                let field = &code[node.child_by_field_name("field")?.byte_range()];
+                Self::promoted_field(layout, owner, field)?.1.element_type
+            }
+            _ => None,
+        }
+    }
 
-        let comment_node = if node.kind() == "type_spec" {
-            // For type_spec, check parent's (type_declaration) previous siblings
-            node.parent()
-        } else {
-            // For other nodes, check current node's previous siblings
-            Some(*node)
-        };
+    /// Base type of the elements of the value assigned to a variable, when
+    /// the value is a slice, array, map or channel
+    ///
+    /// Covers composite literals `[]User{...}`, `make([]User, n)`, calls
+    /// whose first result is a container, and copies of an earlier
+    /// container binding (`b := a`, `users = append(users, u)`).
+    fn value_element_type<'a>(
+        value: &Node,
+        root: Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<&'a str> {
+        match value.kind() {
+            "composite_literal" | "type_assertion_expression" => {
+                Self::element_base_type_name(&value.child_by_field_name("type")?, code)
+            }
+            "call_expression" => {
+                let function = value.child_by_field_name("function")?;
+                if &code[function.byte_range()] == "make" {
+                    let arg = value.child_by_field_name("arguments")?.named_child(0)?;
+                    return Self::element_base_type_name(&arg, code);
+                }
+                if let Some(result) = Self::call_result_type_node(value, root, bindings, code) {
+                    return Self::element_base_type_name(&result, code);
+                }
+                Self::aliased_binding(value, bindings, code)?.element_type
+            }
+            "identifier" => Self::aliased_binding(value, bindings, code)?.element_type,
+            _ => None,
+        }
+    }
 
-        let search_node = comment_node?;
+    /// Type and type arguments of the value assigned to a variable
+    ///
+    /// The value's own type when evident (see [`Self::value_base_type_name`]),
+    /// else the instantiated result of a generic call, else the type of the
+    /// earlier binding it copies.
+    fn value_binding<'a>(
+        value: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<(&'a str, Option<&'a str>)> {
+        if let Some(base) = Self::value_base_type_name(value, root, code) {
+            // A generic function returning `T` gives the type inferred for it
+            let inferred = Self::generic_call_result_types(value, root, bindings, code)
+                .and_then(|results| results.first().copied().flatten())
+                .filter(|inferred| *inferred != base);
+            return Some(match inferred {
+                Some(inferred) => (inferred, None),
+                None => (base, Self::value_type_arguments(value, root, code)),
+            });
+        }
+        // `cache.Get(key)` on an instantiated generic type
+        if let Some(Some(result)) = Self::generic_call_result_types(value, root, bindings, code)
+            .map(|results| results.first().copied().flatten())
+        {
+            return Some((result, None));
+        }
+        // `ch := broker.Subscribe(topic)` on a variable of known type
+        if let Some(result) = Self::call_result_type_node(value, root, bindings, code) {
+            if let Some(base) = Self::binding_type_name(&result, code) {
+                return Some((base, None));
+            }
+        }
+        // `u := users[i]` reads an element of a container variable
+        if value.kind() == "index_expression" {
+            let container = value.child_by_field_name("operand")?;
+            let element = Self::aliased_binding(&container, bindings, code)?.element_type?;
+            return Some((element, None));
+        }
+        let aliased = Self::aliased_binding(value, bindings, code)?;
+        Some((aliased.narrowed_type()?, aliased.type_arguments))
+    }
 
-        let mut doc_lines = Vec::new();
-        let mut current = search_node.prev_sibling();
+    /// Which loop variable of `range <iterable>` holds an element, and the
+    /// element's base type
+    ///
+    /// Ranging over a channel binds the element to the first variable; over
+    /// a slice, array or map, to the second.
+    fn range_element<'a>(
+        iterable: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<(usize, &'a str)> {
+        if let Some(container) = Self::iterable_type_node(iterable, root, bindings, code) {
+            let index = if container.kind() == "channel_type" {
+                0
+            } else {
+                1
+            };
+            return Some((index, Self::element_base_type_name(&container, code)?));
+        }
+        // Container variables keep their element type
+        let binding = Self::aliased_binding(iterable, bindings, code)?;
+        let is_channel = binding
+            .narrowed_type()
+            .is_some_and(|t| t.starts_with("chan") || t.starts_with("<-chan"));
+        Some((if is_channel { 0 } else { 1 }, binding.element_type?))
+    }
+
+    /// Container type of a ranged expression: `make(chan T)`, the result of
+    /// a called function or method, or the declared type of a variable
+    fn iterable_type_node<'t, 'a>(
+        iterable: &Node<'t>,
+        root: Node<'t>,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<Node<'t>> {
+        match iterable.kind() {
+            "parenthesized_expression" => {
+                Self::iterable_type_node(&iterable.named_child(0)?, root, bindings, code)
+            }
+            "call_expression" => {
+                let function = iterable.child_by_field_name("function")?;
+                if &code[function.byte_range()] == "make" {
+                    return iterable.child_by_field_name("arguments")?.named_child(0);
+                }
+                Self::call_result_type_node(iterable, root, bindings, code)
+            }
+            "identifier" => {
+                let name = &code[iterable.byte_range()];
+                let mut scope = *iterable;
+                while let Some(parent) = scope.parent() {
+                    if parent.parent().is_none() {
+                        break;
+                    }
+                    scope = parent;
+                }
+                // Last declaration of `name` before the loop, in source order
+                let mut found = None;
+                let mut stack = vec![scope];
+                while let Some(node) = stack.pop() {
+                    if node.start_byte() >= iterable.start_byte() {
+                        continue;
+                    }
+                    match node.kind() {
+                        "parameter_declaration" | "var_spec" => {
+                            let declares = node
+                                .children_by_field_name("name", &mut node.walk())
+                                .any(|n| &code[n.byte_range()] == name);
+                            if declares && node.child_by_field_name("type").is_some() {
+                                found = node.child_by_field_name("type");
+                            }
+                        }
+                        // Declarations enclosing the loop don't type it
+                        "short_var_declaration" if node.end_byte() <= iterable.start_byte() => {
+                            let (Some(left), Some(right)) = (
+                                node.child_by_field_name("left"),
+                                node.child_by_field_name("right"),
+                            ) else {
+                                continue;
+                            };
+                            let position = left
+                                .named_children(&mut left.walk())
+                                .position(|n| &code[n.byte_range()] == name);
+                            if let Some(value) = position.and_then(|i| right.named_child(i)) {
+                                found = Self::iterable_type_node(&value, root, bindings, code);
+                            }
+                        }
+                        _ => {}
+                    }
+                    let children: Vec<_> = node.named_children(&mut node.walk()).collect();
+                    stack.extend(children.into_iter().rev());
+                }
+                found
+            }
+            _ => None,
+        }
+    }
+
+    /// First result type of the function or method `call` invokes
+    ///
+    /// Functions are looked up by name in this file. Methods are looked up
+    /// on the type of the receiver variable, among the methods declared on
+    /// it and, for an interface, its method elements, so
+    /// `broker.Subscribe(topic)` with `broker MessageBroker` gives
+    /// `<-chan Message`.
+    fn call_result_type_node<'t, 'a>(
+        call: &Node<'t>,
+        root: Node<'t>,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<Node<'t>> {
+        if call.kind() != "call_expression" {
+            return None;
+        }
+        let function = call.child_by_field_name("function")?;
+        match function.kind() {
+            "identifier" => Self::first_result_type(Self::find_function_declaration(
+                root,
+                &code[function.byte_range()],
+                code,
+            )?),
+            "selector_expression" => {
+                let operand = function.child_by_field_name("operand")?;
+                let method = &code[function.child_by_field_name("field")?.byte_range()];
+                let receiver = Self::aliased_binding(&operand, bindings, code)?;
+                [receiver.concrete_type, receiver.declared_type]
+                    .into_iter()
+                    .flatten()
+                    .find_map(|typ| Self::method_result_type_node(root, typ, method, code))
+            }
+            _ => None,
+        }
+    }
+
+    /// First result type of `type_name.method`, declared on the type or as
+    /// a method element of an interface type
+    fn method_result_type_node<'t>(
+        root: Node<'t>,
+        type_name: &str,
+        method: &str,
+        code: &str,
+    ) -> Option<Node<'t>> {
+        let named = |node: Node| {
+            node.child_by_field_name("name")
+                .is_some_and(|n| &code[n.byte_range()] == method)
+        };
+        for decl in root.named_children(&mut root.walk()) {
+            match decl.kind() {
+                "method_declaration" if named(decl) => {
+                    let receiver_type = super::analysis::receiver_type_node(decl);
+                    if receiver_type.and_then(|t| Self::receiver_base_type_name(&t, code))
+                        == Some(type_name)
+                    {
+                        return Self::first_result_type(decl);
+                    }
+                }
+                "type_declaration" => {
+                    for spec in decl.named_children(&mut decl.walk()) {
+                        let (Some(name), Some(body)) = (
+                            spec.child_by_field_name("name"),
+                            spec.child_by_field_name("type"),
+                        ) else {
+                            continue;
+                        };
+                        if &code[name.byte_range()] != type_name || body.kind() != "interface_type"
+                        {
+                            continue;
+                        }
+                        let element = body
+                            .named_children(&mut body.walk())
+                            .find(|e| e.kind() == "method_elem" && named(*e));
+                        if let Some(element) = element {
+                            return Self::first_result_type(element);
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+        None
+    }
+
+    /// Earlier binding that `value` copies: `b := a` or
+    /// `users = append(users, u)`
+    fn aliased_binding<'a, 'b>(
+        value: &tree_sitter::Node,
+        bindings: &'b [GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<&'b GoVariableBinding<'a>> {
+        let source = match value.kind() {
+            "identifier" => *value,
+            "call_expression" => {
+                let function = value.child_by_field_name("function")?;
+                if &code[function.byte_range()] != "append" {
+                    return None;
+                }
+                value.child_by_field_name("arguments")?.named_child(0)?
+            }
+            _ => return None,
+        };
+        if source.kind() != "identifier" {
+            return None;
+        }
+        let name = &code[source.byte_range()];
+        bindings.iter().rfind(|binding| binding.name == name)
+    }
+
+    /// Base type name of the value an expression evaluates to, when evident
+    ///
+    /// Handles `User{...}`, `&User{...}`, `new(User)`, `make([]User, n)`
+    /// (the container type, see [`Self::binding_type_name`]), assertions
+    /// `x.(*User)`, calls to functions
+    /// declared in the same file
+    /// (`NewUser(...)` returning `*User`) and well-known standard library
+    /// constructors such as `list.New()`, under any import alias.
+    fn value_base_type_name<'a>(
+        value: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        code: &'a str,
+    ) -> Option<&'a str> {
+        match value.kind() {
+            "composite_literal" | "type_assertion_expression" => value
+                .child_by_field_name("type")
+                .and_then(|t| Self::binding_type_name(&t, code)),
+            "unary_expression" => {
+                let operator = value.child_by_field_name("operator")?;
+                if operator.kind() != "&" {
+                    return None;
+                }
+                let operand = value.child_by_field_name("operand")?;
+                Self::value_base_type_name(&operand, root, code)
+            }
+            "call_expression" => {
+                let (function, _) = Self::instantiated_callee(*value, code)?;
+                if let Some(converted) = Self::conversion_type(function, root, code) {
+                    return Some(converted);
+                }
+                if function.kind() == "selector_expression" {
+                    // `set.New()` with `set "container/list"` gives
+                    // `container/list.List`
+                    let package = function.child_by_field_name("operand")?;
+                    let name = function.child_by_field_name("field")?;
+                    if package.kind() != "identifier" {
+                        return None;
+                    }
+                    let path =
+                        Self::imported_package_path(root, &code[package.byte_range()], code)?;
+                    let constructed = stdlib_constructor_type(path, &code[name.byte_range()])?;
+                    // Only known constructors get as far as scanning for locals
+                    let declarations =
+                        local_declarations(Self::top_level_declaration(package), code);
+                    if Self::shadows_package(package, &declarations, code) {
+                        return None;
+                    }
+                    return Some(constructed);
+                }
+                if function.kind() != "identifier" {
+                    return None;
+                }
+                let name = &code[function.byte_range()];
+                if name == "make" {
+                    let arg = value.child_by_field_name("arguments")?.named_child(0)?;
+                    return Self::binding_type_name(&arg, code);
+                }
+                if name == "new" {
+                    let arg = value.child_by_field_name("arguments")?.named_child(0)?;
+                    return match arg.kind() {
+                        "identifier" | "type_identifier" => Some(&code[arg.byte_range()]),
+                        _ => Self::receiver_base_type_name(&arg, code),
+                    };
+                }
+                let declaration = Self::find_function_declaration(root, name, code)?;
+                Self::receiver_base_type_name(&Self::first_result_type(declaration)?, code)
+            }
+            _ => None,
+        }
+    }
+
+    /// Type a call converts its argument to, when the callee names a type
+    /// declared in the file: `Config(raw)` or `(*Config)(p)`
+    fn conversion_type<'a>(function: Node, root: Node, code: &'a str) -> Option<&'a str> {
+        let target = Self::conversion_base(function);
+        if !matches!(target.kind(), "identifier" | "type_identifier") {
+            return None;
+        }
+        let name = &code[target.byte_range()];
+        let declared = root
+            .named_children(&mut root.walk())
+            .filter(|decl| decl.kind() == "type_declaration")
+            .any(|decl| {
+                decl.named_children(&mut decl.walk()).any(|spec| {
+                    spec.child_by_field_name("name")
+                        .is_some_and(|n| &code[n.byte_range()] == name)
+                })
+            });
+        declared.then_some(name)
+    }
+
+    /// Callee of a call with the parentheses and pointer stars around it
+    /// removed: `(*Config)` gives `Config`
+    ///
+    /// A call is a conversion when this names a type rather than a function.
+    pub fn conversion_base(function: Node) -> Node {
+        let mut target = function;
+        while let Some(inner) = match target.kind() {
+            "parenthesized_expression" | "parenthesized_type" | "pointer_type" => {
+                target.named_child(0)
+            }
+            "unary_expression" => target
+                .child_by_field_name("operator")
+                .filter(|op| op.kind() == "*")
+                .and_then(|_| target.child_by_field_name("operand")),
+            _ => None,
+        } {
+            target = inner;
+        }
+        target
+    }
+
+    /// Type arguments of the generic type a value instantiates
+    ///
+    /// `&Stack[string]{}` and `new(Stack[string])` give `string`. A call such
+    /// as `NewMap[int, string]()` gives `int, string` when the constructor
+    /// passes its type parameters through in order (`func NewMap[K, V]()
+    /// *Map[K, V]`); inferred type arguments are not worked out.
+    fn value_type_arguments<'a>(
+        value: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        code: &'a str,
+    ) -> Option<&'a str> {
+        match value.kind() {
+            "composite_literal" => {
+                Self::type_arguments_of(&value.child_by_field_name("type")?, code)
+            }
+            "unary_expression" => {
+                let operator = value.child_by_field_name("operator")?;
+                if operator.kind() != "&" {
+                    return None;
+                }
+                let operand = value.child_by_field_name("operand")?;
+                Self::value_type_arguments(&operand, root, code)
+            }
+            "call_expression" => {
+                let (function, type_arguments) = Self::instantiated_callee(*value, code)?;
+                if function.kind() != "identifier" {
+                    return None;
+                }
+                let name = &code[function.byte_range()];
+                if name == "new" {
+                    let arg = value.child_by_field_name("arguments")?.named_child(0)?;
+                    return Self::type_arguments_of(&arg, code);
+                }
+                let type_arguments = type_arguments?;
+                let declaration = Self::find_function_declaration(root, name, code)?;
+                let parameters: Vec<_> = declaration
+                    .child_by_field_name("type_parameters")
+                    .map(|list| {
+                        list.named_children(&mut list.walk())
+                            .flat_map(|decl| {
+                                decl.children_by_field_name("name", &mut decl.walk())
+                                    .map(|name| &code[name.byte_range()])
+                                    .collect::<Vec<_>>()
+                            })
+                            .collect()
+                    })
+                    .unwrap_or_default();
+                let result = Self::type_arguments_of(&Self::first_result_type(declaration)?, code)?;
+                (!parameters.is_empty() && split_type_list(result) == parameters)
+                    .then_some(type_arguments)
+            }
+            _ => None,
+        }
+    }
+
+    /// Result types of a call to a generic function declared in this file
+    ///
+    /// Type parameters are bound from explicit type arguments or inferred
+    /// from the arguments passed for parameters typed `T`, `[]T` or `...T`:
+    /// `Pair(1, "x")` calling `func Pair[T, U any](first T, second U) (T, U)`
+    /// gives `int` and `string`. Results are base type names, as for
+    /// bindings; a result whose type can't be told gives `None`. Returns
+    /// `None` for calls to non-generic functions.
+    ///
+    /// Method calls on a variable of an instantiated generic type bind the
+    /// receiver's type parameters instead (see
+    /// [`Self::generic_method_result_types`]).
+    fn generic_call_result_types<'a>(
+        call: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<Vec<Option<&'a str>>> {
+        if call.kind() != "call_expression" {
+            return None;
+        }
+        let (function, explicit) = Self::instantiated_callee(*call, code)?;
+        if function.kind() == "selector_expression" {
+            return Self::generic_method_result_types(function, root, bindings, code);
+        }
+        if function.kind() != "identifier" {
+            return None;
+        }
+        let declaration = Self::find_function_declaration(root, &code[function.byte_range()], code)
+            .filter(|decl| decl.kind() == "function_declaration")?;
+        let type_parameter_list = declaration.child_by_field_name("type_parameters")?;
+        let type_parameters: Vec<&str> = type_parameter_list
+            .named_children(&mut type_parameter_list.walk())
+            .flat_map(|decl| {
+                decl.children_by_field_name("name", &mut decl.walk())
+                    .map(|name| &code[name.byte_range()])
+                    .collect::<Vec<_>>()
+            })
+            .collect();
+        if type_parameters.is_empty() {
+            return None;
+        }
+
+        let mut bound: std::collections::HashMap<&str, &'a str> = Default::default();
+        if let Some(explicit) = explicit {
+            for (parameter, argument) in type_parameters.iter().zip(split_top_level(explicit)) {
+                if let Some(base) = Self::type_text_base(argument) {
+                    bound.insert(*parameter, base);
+                }
+            }
+        }
+
+        // Infer the rest from the arguments, in parameter order
+        let arguments: Vec<_> = call
+            .child_by_field_name("arguments")
+            .map(|args| {
+                args.named_children(&mut args.walk())
+                    .filter(|arg| arg.kind() != "comment")
+                    .collect()
+            })
+            .unwrap_or_default();
+        let mut index = 0;
+        if let Some(parameters) = declaration.child_by_field_name("parameters") {
+            for parameter in parameters.named_children(&mut parameters.walk()) {
+                let Some(type_node) = parameter.child_by_field_name("type") else {
+                    continue;
+                };
+                // `...T` takes the remaining arguments
+                let count = if parameter.kind() == "variadic_parameter_declaration" {
+                    arguments.len().saturating_sub(index)
+                } else {
+                    parameter
+                        .children_by_field_name("name", &mut parameter.walk())
+                        .count()
+                        .max(1)
+                };
+                for argument in arguments.iter().skip(index).take(count) {
+                    let (type_parameter, argument_type) = match type_node.kind() {
+                        "type_identifier" => (
+                            &code[type_node.byte_range()],
+                            match argument
+                                .named_child(0)
+                                .filter(|_| argument.kind() == "variadic_argument")
+                            {
+                                // `values...` spreads a slice of `T`
+                                Some(slice) => {
+                                    Self::value_element_type(&slice, root, bindings, code)
+                                }
+                                None => Self::argument_type(argument, root, bindings, code),
+                            },
+                        ),
+                        // Containers are typed by their elements
+                        "slice_type" => {
+                            let Some(element) = type_node
+                                .child_by_field_name("element")
+                                .filter(|e| e.kind() == "type_identifier")
+                            else {
+                                continue;
+                            };
+                            (
+                                &code[element.byte_range()],
+                                Self::value_element_type(argument, root, bindings, code),
+                            )
+                        }
+                        _ => continue,
+                    };
+                    if let (true, Some(argument_type)) =
+                        (type_parameters.contains(&type_parameter), argument_type)
+                    {
+                        bound.entry(type_parameter).or_insert(argument_type);
+                    }
+                }
+                index += count;
+            }
+        }
+
+        Self::instantiated_result_types(declaration, &type_parameters, &bound, code)
+    }
+
+    /// Result types of a method called on a variable of an instantiated
+    /// generic type, such as `cache.Get(key)`
+    ///
+    /// With `cache := NewCache[string, *Document](10)`, calling
+    /// `func (c *Cache[K, V]) Get(key K) (V, bool)` gives `Document` and
+    /// `bool`, so `doc, ok := cache.Get(key)` types `doc`. The method must be
+    /// declared in this file on the variable's type.
+    fn generic_method_result_types<'a>(
+        selector: Node,
+        root: tree_sitter::Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<Vec<Option<&'a str>>> {
+        let operand = selector.child_by_field_name("operand")?;
+        let method = &code[selector.child_by_field_name("field")?.byte_range()];
+        if operand.kind() != "identifier" {
+            return None;
+        }
+        let binding = Self::aliased_binding(&operand, bindings, code)?;
+        let receiver_type = binding.narrowed_type()?;
+
+        let declaration = root.children(&mut root.walk()).find(|decl| {
+            decl.kind() == "method_declaration"
+                && decl
+                    .child_by_field_name("name")
+                    .is_some_and(|n| &code[n.byte_range()] == method)
+                && super::analysis::receiver_type_node(decl)
+                    .and_then(|t| Self::receiver_base_type_name(&t, code))
+                    == Some(receiver_type)
+        })?;
+        let receiver_signature =
+            &code[declaration.start_byte()..declaration.child_by_field_name("name")?.end_byte()];
+        // `stringStack.Push` on a `Stack[string]` binds `T` to `string`
+        let receiver_bindings = binding.receiver_type_bindings(receiver_signature);
+        if receiver_bindings.is_empty() {
+            return None;
+        }
+        // The method's own type parameters shadow the receiver's and are
+        // not bound by the variable's type arguments
+        let own = Self::declared_type_parameters(declaration, code);
+        let mut type_parameters: Vec<&str> = receiver_bindings
+            .iter()
+            .map(|(parameter, _)| *parameter)
+            .collect();
+        let bound: std::collections::HashMap<&str, &'a str> = receiver_bindings
+            .into_iter()
+            .filter(|(parameter, _)| !own.contains(parameter))
+            .filter_map(|(parameter, argument)| Some((parameter, Self::type_text_base(argument)?)))
+            .collect();
+        type_parameters.extend(own);
+        Self::instantiated_result_types(declaration, &type_parameters, &bound, code)
+    }
+
+    /// Result types of a function or method declaration with its type
+    /// parameters replaced by the types bound to them
+    fn instantiated_result_types<'a>(
+        declaration: Node,
+        type_parameters: &[&str],
+        bound: &std::collections::HashMap<&str, &'a str>,
+        code: &'a str,
+    ) -> Option<Vec<Option<&'a str>>> {
+        let result = declaration.child_by_field_name("result")?;
+        let result_types: Vec<Node> = if result.kind() == "parameter_list" {
+            result
+                .named_children(&mut result.walk())
+                .filter_map(|param| {
+                    let type_node = param.child_by_field_name("type")?;
+                    let count = param
+                        .children_by_field_name("name", &mut param.walk())
+                        .count()
+                        .max(1);
+                    Some(std::iter::repeat_n(type_node, count))
+                })
+                .flatten()
+                .collect()
+        } else {
+            vec![result]
+        };
+        Some(
+            result_types
+                .into_iter()
+                .map(|type_node| {
+                    let mut node = type_node;
+                    while matches!(node.kind(), "pointer_type" | "slice_type") {
+                        node = match node.kind() {
+                            "pointer_type" => node.named_child(0)?,
+                            _ => node.child_by_field_name("element")?,
+                        };
+                    }
+                    let text = &code[node.byte_range()];
+                    if type_parameters.contains(&text) {
+                        return bound.get(text).copied();
+                    }
+                    Self::binding_type_name(&type_node, code)
+                })
+                .collect(),
+        )
+    }
+
+    /// Type of a value passed as an argument: the type of a literal, else
+    /// as for a binding
+    fn argument_type<'a>(
+        argument: &tree_sitter::Node,
+        root: tree_sitter::Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<&'a str> {
+        Some(match argument.kind() {
+            "int_literal" => "int",
+            "float_literal" => "float64",
+            "imaginary_literal" => "complex128",
+            "rune_literal" => "rune",
+            "interpreted_string_literal" | "raw_string_literal" => "string",
+            "true" | "false" => "bool",
+            _ => return Self::value_binding(argument, root, bindings, code).map(|(t, _)| t),
+        })
+    }
+
+    /// Base name of a type written as text: `*models.User` gives `User`
+    fn type_text_base(text: &str) -> Option<&str> {
+        let text = text.trim().trim_start_matches('*');
+        let text = text.split('[').next().unwrap_or(text);
+        let base = text.rsplit('.').next().unwrap_or(text);
+        (!base.is_empty()).then_some(base)
+    }
+
+    /// Type arguments of an instantiated generic type, as written between
+    /// the brackets: `*Stack[string]` gives `string`
+    fn type_arguments_of<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        match node.kind() {
+            "pointer_type" | "parenthesized_type" => node
+                .named_child(0)
+                .and_then(|inner| Self::type_arguments_of(&inner, code)),
+            "generic_type" => {
+                let arguments = node.child_by_field_name("type_arguments")?;
+                Self::bracket_contents(&arguments, code)
+            }
+            _ => None,
+        }
+    }
+
+    /// Text of a bracketed node without the brackets
+    fn bracket_contents<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        let text = &code[node.byte_range()];
+        let inner = text.strip_prefix('[')?.strip_suffix(']')?.trim();
+        (!inner.is_empty()).then_some(inner)
+    }
+
+    /// Function called by `call` and its explicit type arguments
+    ///
+    /// `NewMap[int, string]()` gives `NewMap` and `int, string`, however the
+    /// instantiation was parsed: as the call's type arguments, an index
+    /// expression (one argument) or a type instantiation.
+    fn instantiated_callee<'t, 'a>(
+        call: Node<'t>,
+        code: &'a str,
+    ) -> Option<(Node<'t>, Option<&'a str>)> {
+        let function = call.child_by_field_name("function")?;
+        if let Some(arguments) = call.child_by_field_name("type_arguments") {
+            return Some((function, Self::bracket_contents(&arguments, code)));
+        }
+        match function.kind() {
+            "index_expression" => {
+                let index = function.child_by_field_name("index")?;
+                Some((
+                    function.child_by_field_name("operand")?,
+                    Some(&code[index.byte_range()]),
+                ))
+            }
+            "type_instantiation_expression" => {
+                let callee = function.child_by_field_name("type")?;
+                let arguments: Vec<_> = function
+                    .named_children(&mut function.walk())
+                    .filter(|arg| arg.id() != callee.id())
+                    .collect();
+                let (first, last) = (arguments.first()?, arguments.last()?);
+                Some((callee, Some(&code[first.start_byte()..last.end_byte()])))
+            }
+            _ => Some((function, None)),
+        }
+    }
+
+    /// First result type of a function declaration; for `(*User, error)`
+    /// the first result is the value
+    fn first_result_type(declaration: Node) -> Option<Node> {
+        let result = declaration.child_by_field_name("result")?;
+        if result.kind() == "parameter_list" {
+            result
+                .named_children(&mut result.walk())
+                .find(|p| p.kind() == "parameter_declaration")?
+                .child_by_field_name("type")
+        } else {
+            Some(result)
+        }
+    }
+
+    #[allow(clippy::only_used_in_recursion)]
+    fn extract_method_calls_recursive<'a>(
+        &self,
+        node: &tree_sitter::Node,
+        code: &'a str,
+        current_function: Option<&str>,
+        bindings: &[GoVariableBinding<'a>],
+        calls: &mut Vec<MethodCall>,
+    ) {
+        // Track function context for Go
+        let function_context = if node.kind() == "function_declaration"
+            || node.kind() == "method_declaration"
+            || node.kind() == "func_literal"
+        {
+            // Extract function name
+            if let Some(name_node) = node.child_by_field_name("name") {
+                Some(&code[name_node.byte_range()])
+            } else {
+                current_function
+            }
+        } else {
+            current_function
+        };
+
+        // Check for method calls (Go uses selector_expression)
+        if node.kind() == "call_expression" {
+            if let Some(function_node) = node.child_by_field_name("function") {
+                if function_node.kind() == "selector_expression" {
+                    // It's a method call!
+                    // `users[i].Verify()` and `handlers()[name].Serve()` call
+                    // the method on an element, `(Config(raw)).Port()` on
+                    // the converted value: either type names the receiver
+                    let element =
+                        function_node
+                            .child_by_field_name("operand")
+                            .and_then(|operand| {
+                                Self::indexed_element_type(operand, bindings, code)
+                                    .or_else(|| Self::parenthesized_receiver_type(operand, code))
+                            });
+                    let signature = match element {
+                        Some(element) => function_node
+                            .child_by_field_name("field")
+                            .map(|field| (Some(element), &code[field.byte_range()], true)),
+                        None => self.extract_go_method_signature(&function_node, code),
+                    };
+                    if let Some((receiver, method_name, is_static)) = signature {
+                        if let Some(context) = function_context {
+                            let range = Range {
+                                start_line: node.start_position().row as u32,
+                                start_column: node.start_position().column as u16,
+                                end_line: node.end_position().row as u32,
+                                end_column: node.end_position().column as u16,
+                            };
+
+                            let method_call = MethodCall {
+                                caller: context.to_string(),
+                                method_name: method_name.to_string(),
+                                receiver: receiver.map(|r| r.to_string()),
+                                is_static,
+                                range,
+                            };
+
+                            calls.push(method_call);
+                        }
+                    }
+                }
+            }
+        }
+
+        // Recurse
+        let mut cursor = node.walk();
+        for child in node.children(&mut cursor) {
+            self.extract_method_calls_recursive(&child, code, function_context, bindings, calls);
+        }
+    }
+
+    /// Element type of an indexed container: the receiver of
+    /// `users[i].Verify()`, `a.handlers[name].Serve()`,
+    /// `HandlerRegistry()["default"].Execute()` or `p.Contacts()[0].Verify()`
+    ///
+    /// Variables and fields give their element type; a call's first result
+    /// (see [`Self::call_result_type_node`]) must be a map, slice or array.
+    /// Method calls on the call's own result are left alone.
+    fn indexed_element_type<'a>(
+        operand: Node,
+        bindings: &[GoVariableBinding<'a>],
+        code: &'a str,
+    ) -> Option<&'a str> {
+        let mut index = operand;
+        while let Some(inner) = match index.kind() {
+            "parenthesized_expression" => index.named_child(0),
+            "unary_expression" => index
+                .child_by_field_name("operator")
+                .filter(|op| matches!(op.kind(), "*" | "&"))
+                .and_then(|_| index.child_by_field_name("operand")),
+            _ => None,
+        } {
+            index = inner;
+        }
+        if index.kind() != "index_expression" {
+            return None;
+        }
+        let mut container = index.child_by_field_name("operand")?;
+        while container.kind() == "parenthesized_expression" {
+            container = container.named_child(0)?;
+        }
+
+        // Receivers of method calls are typed by the bindings of the
+        // enclosing declaration up to the call
+        let mut root = container;
+        let mut declaration = None;
+        while let Some(parent) = root.parent() {
+            if matches!(parent.kind(), "function_declaration" | "method_declaration") {
+                declaration = Some(parent);
+            }
+            root = parent;
+        }
+        let row = container.start_position().row as u32;
+        let scope: Vec<_> = declaration
+            .map(|decl| {
+                let first_row = decl.start_position().row as u32;
+                bindings
+                    .iter()
+                    .filter(|b| (first_row..=row).contains(&b.range.start_line))
+                    .copied()
+                    .collect()
+            })
+            .unwrap_or_default();
+        if container.kind() != "call_expression" {
+            let layout = Self::struct_layout(root, code);
+            return Self::expression_element_type(container, code, &scope, &layout);
+        }
+        let result = Self::call_result_type_node(&container, root, &scope, code)?;
+        Self::element_base_type_name(&result, code)
+    }
+
+    /// Type of a receiver that is a parenthesized value rather than a
+    /// variable: `(Config(raw)).Port()`, `(&Config{}).Port()`,
+    /// `(newConfig()).Port()`, or a conversion with the parentheses on the
+    /// type, `(*Config)(p).Address()`
+    ///
+    /// Parenthesized variables such as `(*p).Port()` are left to the
+    /// variable's binding.
+    fn parenthesized_receiver_type<'a>(operand: Node, code: &'a str) -> Option<&'a str> {
+        let mut root = operand;
+        while let Some(parent) = root.parent() {
+            root = parent;
+        }
+        match operand.kind() {
+            "parenthesized_expression" => {
+                let inner = Self::strip_receiver_indirection(operand);
+                Self::value_base_type_name(&inner, root, code)
+            }
+            "call_expression" => {
+                Self::conversion_type(operand.child_by_field_name("function")?, root, code)
+            }
+            _ => None,
+        }
+    }
+
+    /// Unwrap parentheses, dereference and address-of around a receiver operand
+    fn strip_receiver_indirection(operand: tree_sitter::Node) -> tree_sitter::Node {
+        let mut current = operand;
+        loop {
+            let inner = match current.kind() {
+                "parenthesized_expression" => current.named_child(0),
+                "unary_expression" => current
+                    .child_by_field_name("operator")
+                    .filter(|op| matches!(op.kind(), "*" | "&"))
+                    .and_then(|_| current.child_by_field_name("operand")),
+                _ => None,
+            };
+            match inner {
+                Some(inner) => current = inner,
+                None => return current,
+            }
+        }
+    }
+
+    /// Type and method named by a method expression such as `(*User).String`
+    ///
+    /// The operand must name a type declared in the file, which tells the
+    /// expression apart from a method value `u.String` on a variable (see
+    /// [`GoParser::method_value`]).
+    fn method_expression<'a>(
+        selector: tree_sitter::Node,
+        declared_types: &std::collections::HashSet<&str>,
+        code: &'a str,
+    ) -> Option<(&'a str, &'a str)> {
+        if selector.kind() != "selector_expression" {
+            return None;
+        }
+        let field = selector.child_by_field_name("field")?;
+        let mut type_node = selector.child_by_field_name("operand")?;
+        loop {
+            let inner = match type_node.kind() {
+                "parenthesized_expression" | "parenthesized_type" | "pointer_type" => {
+                    type_node.named_child(0)
+                }
+                "unary_expression" => type_node
+                    .child_by_field_name("operator")
+                    .filter(|op| op.kind() == "*")
+                    .and_then(|_| type_node.child_by_field_name("operand")),
+                _ => None,
+            };
+            match inner {
+                Some(inner) => type_node = inner,
+                None => break,
+            }
+        }
+        if !matches!(type_node.kind(), "identifier" | "type_identifier") {
+            return None;
+        }
+        let type_name = &code[type_node.byte_range()];
+        declared_types
+            .contains(type_name)
+            .then(|| (type_name, &code[field.byte_range()]))
+    }
+
+    /// Receiver variable and method of a method value such as `u.String`
+    ///
+    /// The operand must be a plain identifier that names neither a declared
+    /// type (a method expression) nor an imported package (a function).
+    fn method_value<'a>(
+        selector: tree_sitter::Node,
+        declared_types: &std::collections::HashSet<&str>,
+        packages: &std::collections::HashSet<&str>,
+        declarations: &[LocalDeclaration],
+        code: &'a str,
+    ) -> Option<(&'a str, &'a str)> {
+        if selector.kind() != "selector_expression" {
+            return None;
+        }
+        let field = selector.child_by_field_name("field")?;
+        let operand = selector.child_by_field_name("operand")?;
+        if operand.kind() != "identifier" {
+            return None;
+        }
+        let receiver = &code[operand.byte_range()];
+        if declared_types.contains(receiver)
+            || (packages.contains(receiver) && !Self::shadows_package(operand, declarations, code))
+        {
+            return None;
+        }
+        Some((receiver, &code[field.byte_range()]))
+    }
+
+    /// Calls through variables bound to method expressions and method values
+    ///
+    /// `f := (*User).String` binds `f` to `func(*User) string`, so `f(u)`
+    /// calls `User.String` with `u` as its receiver; the expression names the
+    /// type. `g := u.String` binds the receiver instead, so `g()` calls
+    /// `String` on the variable `u`. Bindings are tracked through the whole
+    /// declaration, closures included, so a method value captured by a
+    /// closure, or taken inside one, still resolves when the closure calls
+    /// it. Returns (caller, receiver, method, is_static, call range) in
+    /// source order; assigning the variable anything else ends the binding.
+    fn bound_method_calls<'a>(
+        root: tree_sitter::Node,
+        code: &'a str,
+    ) -> Vec<(&'a str, &'a str, &'a str, bool, Range)> {
+        let mut calls = Vec::new();
+        let mut declared_types = std::collections::HashSet::new();
+        let mut packages = std::collections::HashSet::new();
+        let mut stack = vec![root];
+        while let Some(node) = stack.pop() {
+            match node.kind() {
+                "type_spec" | "type_alias" => {
+                    if let Some(name) = node.child_by_field_name("name") {
+                        declared_types.insert(&code[name.byte_range()]);
+                    }
+                }
+                "import_spec" => {
+                    let local = match node.child_by_field_name("name") {
+                        Some(name) => Some(&code[name.byte_range()]),
+                        None => node.child_by_field_name("path").map(|path| {
+                            let path =
+                                code[path.byte_range()].trim_matches(|c| c == '"' || c == '`');
+                            path.rsplit('/').next().unwrap_or(path)
+                        }),
+                    };
+                    packages.extend(local);
+                }
+                _ => {}
+            }
+            stack.extend(node.named_children(&mut node.walk()));
+        }
+
+        for decl in root.named_children(&mut root.walk()) {
+            if !matches!(decl.kind(), "function_declaration" | "method_declaration") {
+                continue;
+            }
+            let (Some(name), Some(body)) = (
+                decl.child_by_field_name("name"),
+                decl.child_by_field_name("body"),
+            ) else {
+                continue;
+            };
+            let caller = &code[name.byte_range()];
+            let declarations = local_declarations(decl, code);
+            let mut bound = std::collections::HashMap::new();
+            let mut stack = vec![body];
+            while let Some(node) = stack.pop() {
+                let (targets, values): (Vec<_>, Vec<_>) = match node.kind() {
+                    "short_var_declaration" | "assignment_statement" => (
+                        node.child_by_field_name("left")
+                            .map(|l| l.named_children(&mut l.walk()).collect())
+                            .unwrap_or_default(),
+                        node.child_by_field_name("right")
+                            .map(|r| r.named_children(&mut r.walk()).collect())
+                            .unwrap_or_default(),
+                    ),
+                    "var_spec" => (
+                        node.children_by_field_name("name", &mut node.walk())
+                            .collect(),
+                        node.child_by_field_name("value")
+                            .map(|v| v.named_children(&mut v.walk()).collect())
+                            .unwrap_or_default(),
+                    ),
+                    _ => (Vec::new(), Vec::new()),
+                };
+                for (i, target) in targets.iter().enumerate() {
+                    if target.kind() != "identifier" {
+                        continue;
+                    }
+                    let variable = &code[target.byte_range()];
+                    let value = (targets.len() == values.len()).then(|| values[i]);
+                    let binding = value.and_then(|value| {
+                        Self::method_expression(value, &declared_types, code)
+                            .map(|(type_name, method)| (type_name, method, true))
+                            .or_else(|| {
+                                Self::method_value(
+                                    value,
+                                    &declared_types,
+                                    &packages,
+                                    &declarations,
+                                    code,
+                                )
+                                .map(|(receiver, method)| (receiver, method, false))
+                            })
+                    });
+                    match binding {
+                        Some(binding) => bound.insert(variable, binding),
+                        None => bound.remove(variable),
+                    };
+                }
+
+                if node.kind() == "call_expression" {
+                    if let Some((receiver, method, is_static)) = node
+                        .child_by_field_name("function")
+                        .filter(|function| function.kind() == "identifier")
+                        .and_then(|function| bound.get(&code[function.byte_range()]))
+                    {
+                        calls.push((
+                            caller,
+                            *receiver,
+                            *method,
+                            *is_static,
+                            Self::node_range(node),
+                        ));
+                    }
+                }
+
+                let mut children: Vec<_> = node.named_children(&mut node.walk()).collect();
+                children.reverse();
+                stack.extend(children);
+            }
+        }
+        calls
+    }
+
+    fn extract_go_method_signature<'a>(
+        &self,
+        selector_expr: &tree_sitter::Node,
+        code: &'a str,
+    ) -> Option<(Option<&'a str>, &'a str, bool)> {
+        // selector_expression has 'operand' and 'field' fields
+        let operand = selector_expr.child_by_field_name("operand");
+        let field = selector_expr.child_by_field_name("field");
+
+        match (operand, field) {
+            (Some(obj), Some(prop)) => {
+                // `(*p).M()` and `(&u).M()` call M on p and u: Go adjusts
+                // between pointer and value receivers automatically
+                let receiver = &code[Self::strip_receiver_indirection(obj).byte_range()];
+                let method_name = &code[prop.byte_range()];
+
+                // In Go, we can't easily distinguish between static and instance calls
+                // without type information, so we'll assume instance calls
+                let is_static = false;
+
+                Some((Some(receiver), method_name, is_static))
+            }
+            _ => None,
+        }
+    }
+
+    fn extract_function_name<'a>(node: &tree_sitter::Node, code: &'a str) -> Option<&'a str> {
+        match node.kind() {
+            "identifier" => Some(&code[node.byte_range()]),
+            "selector_expression" => {
+                // For qualified function calls like pkg.Function()
+                Some(&code[node.byte_range()])
+            }
+            _ => None,
+        }
+    }
+
+    /// Extract generic type parameters from a signature
+    /// Returns a list of type parameter names like ["T", "K", "V"]
+    fn extract_generic_params_from_signature(&self, signature: &str) -> Vec<String> {
+        let mut params = Vec::new();
+
+        // Look for generic parameter section like [T any, K comparable, V SomeInterface]
+        if let Some(start) = signature.find('[') {
+            if let Some(end) = signature[start..].find(']') {
+                let generic_section = &signature[start + 1..start + end];
+
+                // Parse parameters separated by commas
+                for param in generic_section.split(',') {
+                    let param = param.trim();
+                    if param.is_empty() {
+                        continue;
+                    }
+
+                    // Extract just the parameter name (first word)
+                    if let Some(param_name) = param.split_whitespace().next() {
+                        params.push(param_name.to_string());
+                    }
+                }
+            }
+        }
+
+        params
+    }
+}
+
+impl LanguageParser for GoParser {
+    fn parse(
+        &mut self,
+        code: &str,
+        file_id: FileId,
+        symbol_counter: &mut SymbolCounter,
+    ) -> Vec<Symbol> {
+        self.parse(code, file_id, symbol_counter)
+    }
+
+    fn as_any(&self) -> &dyn Any {
+        self
+    }
+
+    fn extract_doc_comment(&self, node: &Node, code: &str) -> Option<String> {
+        // Extract Go-style documentation comments (//)
+        // For type_spec nodes, check the parent type_declaration for comments
+        // For other nodes, check the current node's previous siblings
+
+        let comment_node = if node.kind() == "type_spec" {
+            // For type_spec, check parent's (type_declaration) previous siblings
+            node.parent()
+        } else {
+            // For other nodes, check current node's previous siblings
+            Some(*node)
+        };
+
+        let search_node = comment_node?;
+
+        let mut doc_lines = Vec::new();
+        let mut current = search_node.prev_sibling();
+
+        // Walk backwards through previous siblings to collect consecutive comment lines
+        while let Some(sibling) = current {
+            if sibling.kind() == "comment" {
+                let comment_text = &code[sibling.byte_range()];
+
+                // Check for Go-style line comments starting with //
+                if comment_text.starts_with("//") {
+                    // Extract the comment content (remove // and leading/trailing whitespace)
+                    let content = comment_text.trim_start_matches("//").trim();
+
+                    // Add to the beginning of doc_lines since we're walking backwards
+                    doc_lines.insert(0, content.to_string());
+
+                    // Continue to previous sibling to check for more comment lines
+                    current = sibling.prev_sibling();
+                } else {
+                    // Found a non-Go comment (like /* */), stop collecting
+                    break;
+                }
+            } else {
+                // Found a non-comment node, stop collecting
+                break;
+            }
+        }
+
+        // If we found any documentation comments, join them and return
+        if !doc_lines.is_empty() {
+            // Filter out empty lines and join with newlines
+            let filtered_lines: Vec<String> = doc_lines
+                .into_iter()
+                .filter(|line| !line.is_empty())
+                .collect();
+
+            if !filtered_lines.is_empty() {
+                let joined = filtered_lines.join("\n").trim().to_string();
+                if !joined.is_empty() {
+                    return Some(joined);
+                }
+            }
+        }
+
+        None
+    }
+
+    /// Extract function calls from Go source code
+    ///
+    /// Returns tuples of (caller_context, called_function, range) for all function calls
+    /// including method calls via dot notation and package-qualified calls.
+    fn find_calls<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+
+        let root = tree.root_node();
+        let mut calls = Vec::new();
+
+        // Track current function context
+        let cgo = Self::imports_cgo(root, code);
+        self.extract_calls_recursive(&root, code, None, cgo, &mut calls);
+
+        for (variable, value) in Self::package_var_initializers(root, code) {
+            self.extract_calls_recursive(&value, code, Some(variable), cgo, &mut calls);
+        }
+
+        // `f(u)` with `f := (*User).String` or `g()` with `g := u.String`
+        // calls the method, not `f` or `g` (see find_method_calls)
+        let bound_method_calls = Self::bound_method_calls(root, code);
+        calls.retain(|(caller, _, range)| {
+            !bound_method_calls
+                .iter()
+                .any(|(c, _, _, _, r)| c == caller && r == range)
+        });
+
+        calls
+    }
+
+    /// Variable types for method resolution
+    ///
+    /// Uses the concrete type when the assigned value reveals it and the
+    /// declared type otherwise (see [`GoParser::find_variable_bindings`]), so
+    /// `var p DataProcessor = &FileProcessor{}` dispatches `p.Process()` to
+    /// `FileProcessor`.
+    fn find_variable_types<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        self.find_variable_bindings(code)
+            .into_iter()
+            .filter_map(|b| Some((b.name, b.narrowed_type()?, b.range)))
+            .collect()
+    }
+
+    /// Receiver types at each call, see [`GoParser::find_receiver_types_in`]
+    fn find_receiver_types<'a>(&mut self, code: &'a str) -> Vec<(&'a str, Option<&'a str>, Range)> {
+        self.find_receiver_types_in(code)
+    }
+
+    /// Struct field accesses, see [`GoParser::find_field_accesses_in`]
+    fn find_label_jumps<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        self.find_label_jumps_in(code)
+    }
+
+    /// Generic instantiations, see [`GoParser::find_instantiations_in`]
+    fn find_instantiations<'a>(
+        &mut self,
+        code: &'a str,
+    ) -> Vec<(&'a str, &'a str, Option<&'a str>, Range)> {
+        self.find_instantiations_in(code)
+    }
+
+    /// Closure captures, see [`GoParser::find_captures_in`]
+    fn find_captures<'a>(&mut self, code: &'a str) -> Vec<(&'a str, String, &'a str, Range, bool)> {
+        self.find_captures_in(code)
+    }
+
+    fn find_field_accesses(&mut self, code: &str) -> Vec<(String, String, Range)> {
+        self.find_field_accesses_in(code)
+            .into_iter()
+            .map(|(context, field, range)| (context.to_string(), field, range))
+            .collect()
+    }
+
+    /// Extract method calls from Go source code
+    ///
+    /// Returns MethodCall structs containing caller, method name, and position information
+    /// for all method invocations including pointer receiver calls and chained calls.
+    fn find_method_calls(&mut self, code: &str) -> Vec<MethodCall> {
+        let bindings = self.find_variable_bindings(code);
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+
+        let root = tree.root_node();
+        let mut method_calls = Vec::new();
+
+        self.extract_method_calls_recursive(&root, code, None, &bindings, &mut method_calls);
+
+        for (variable, value) in Self::package_var_initializers(root, code) {
+            self.extract_method_calls_recursive(
+                &value,
+                code,
+                Some(variable),
+                &bindings,
+                &mut method_calls,
+            );
+        }
+
+        for (caller, receiver, method, is_static, range) in Self::bound_method_calls(root, code) {
+            method_calls.push(MethodCall {
+                caller: caller.to_string(),
+                method_name: method.to_string(),
+                receiver: Some(receiver.to_string()),
+                is_static,
+                range,
+            });
+        }
+
+        // `C.external_function()` is a call, see find_calls
+        if Self::imports_cgo(root, code) {
+            method_calls.retain(|call| call.receiver.as_deref() != Some("C"));
+        }
+
+        method_calls
+    }
+
+    /// Go uses implicit interface implementation (duck typing).
+    /// Types implement interfaces by having matching method signatures,
+    /// not through explicit declarations. This cannot be reliably detected
+    /// through AST parsing alone - it requires cross-file semantic analysis.
+    fn find_implementations<'a>(&mut self, _code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        // Return empty vector - Go has no explicit implementation declarations
+        Vec::new()
+    }
+
+    /// Go doesn't have class inheritance. Interfaces can embed other interfaces,
+    /// but this is composition, not inheritance. Struct types can embed other types,
+    /// but again, this is composition rather than inheritance.
+    fn find_extends<'a>(&mut self, _code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        // Return empty vector - Go has no class inheritance
+        Vec::new()
+    }
+
+    /// Extract import declarations from Go source code
+    ///
+    /// Handles all Go import styles:
+    /// - Standard imports: `import "fmt"`
+    /// - Grouped imports: `import ( "fmt"; "os" )`
+    /// - Aliased imports: `import f "fmt"`
+    /// - Dot imports: `import . "fmt"`
+    /// - Blank imports: `import _ "database/sql"`
+    fn find_imports(&mut self, code: &str, file_id: FileId) -> Vec<Import> {
+        let mut imports = Vec::new();
+
+        if let Some(tree) = self.parser.parse(code, None) {
+            let root = tree.root_node();
+            self.extract_imports_from_node(root, code, file_id, &mut imports);
+        }
+
+        imports
+    }
+
+    /// Extract type usage references from Go source code
+    ///
+    /// Returns tuples of (context, type_name, range) for all type references
+    /// including struct field types, function parameters, and return types.
+    fn find_uses<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+
+        let root = tree.root_node();
+        let mut uses = Vec::new();
+
+        self.extract_type_uses_recursive(&root, code, &mut uses);
+
+        let mut packages = std::collections::HashSet::new();
+        self.collect_imported_package_names(&root, code, &mut packages);
+        if !packages.is_empty() {
+            self.extract_qualified_value_uses_recursive(
+                &root,
+                code,
+                &packages,
+                None,
+                &[],
+                &mut uses,
+            );
+        }
+
+        for (variable, value) in Self::package_var_initializers(root, code) {
+            self.extract_initializer_value_uses(value, code, &packages, variable, &mut uses);
+        }
+        Self::extract_package_value_uses(root, code, &packages, &mut uses);
+
+        uses
+    }
+
+    /// Extract method definitions from Go source code
+    ///
+    /// Returns tuples of (receiver_type, method_name, range) for all method definitions
+    /// with explicit receivers, distinguishing them from standalone functions.
+    fn find_defines<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        let tree = match self.parser.parse(code, None) {
+            Some(tree) => tree,
+            None => return Vec::new(),
+        };
+
+        let root = tree.root_node();
+        let mut defines = Vec::new();
+
+        self.extract_method_defines_recursive(&root, code, &mut defines);
+
+        defines
+    }
+
+    fn language(&self) -> crate::parsing::Language {
+        crate::parsing::Language::Go
+    }
+}
+
+impl NodeTracker for GoParser {
+    fn get_handled_nodes(&self) -> &std::collections::HashSet<HandledNode> {
+        self.node_tracker.get_handled_nodes()
+    }
+
+    fn register_handled_node(&mut self, node_kind: &str, node_id: u16) {
+        self.node_tracker.register_handled_node(node_kind, node_id)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::types::FileId;
+
+    #[test]
+    fn test_go_import_extraction() {
+        println!("\n=== Go Import Extraction Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let file_id = FileId::new(1).unwrap();
+
+        let code = r#"
+package main
+
+import (
+    "fmt"
+    "strings"
+    "net/http"
+    utils "github.com/user/repo/utils"
+    . "encoding/json"
+    _ "database/sql"
+    "path/filepath"
+)
+"#;
+
+        println!("Test code:\n{code}");
+
+        let imports = parser.find_imports(code, file_id);
+
+        println!("\nExtracted {} imports:", imports.len());
+        for (i, import) in imports.iter().enumerate() {
+            println!(
+                "  {}. {} -> {:?} (type_only: {})",
+                i + 1,
+                import.path,
+                import.alias,
+                import.is_type_only
+            );
+        }
+
+        // Verify counts - Go should extract 7 imports
+        assert_eq!(imports.len(), 7, "Should extract 7 imports");
+
+        // Verify specific imports
+        // Standard library import
+        assert!(imports.iter().any(|i| i.path == "fmt" && i.alias.is_none()));
+
+        // Aliased import
+        assert!(imports.iter().any(
+            |i| i.path == "github.com/user/repo/utils" && i.alias == Some("utils".to_string())
+        ));
+
+        // Dot import (not implemented as alias, but should be present)
+        assert!(imports.iter().any(|i| i.path == "encoding/json"));
+
+        // Blank import: binds no name, kept for its side effects
+        assert!(
+            imports
+                .iter()
+                .any(|i| i.path == "database/sql" && i.alias.as_deref() == Some("_"))
+        );
+        assert_eq!(
+            imports
+                .iter()
+                .filter(|i| i.alias.as_deref() == Some("_"))
+                .count(),
+            1
+        );
+
+        println!("=== PASSED ===\n");
+    }
+
+    #[test]
+    fn test_go_generic_type_extraction() {
+        println!("\n=== Go Generic Type Extraction Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package main
+
+import "fmt"
+
+// Generic function with type constraint
+func Identity[T any](value T) T {
+    return value
+}
+
+// Generic function with multiple type parameters
+func Compare[T comparable](a, b T) bool {
+    return a == b
+}
+
+// Generic struct
+type Container[T any] struct {
+    items []T
+}
+
+// Generic interface
+type Processor[T any] interface {
+    Process(T) error
+}
+
+func main() {
+    // Instantiate generic types
+    intContainer := Container[int]{items: []int{1, 2, 3}}
+    stringContainer := Container[string]{items: []string{"a", "b"}}
+    
+    fmt.Println(Identity(42))
+    fmt.Println(Compare("hello", "world"))
+    fmt.Println(intContainer.items)
+    fmt.Println(stringContainer.items)
+}
+"#;
+
+        println!("Test code:\n{code}");
+
+        // Parse the code to extract symbols
+        let mut symbol_counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
+        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+
+        println!("\nExtracted {} symbols:", symbols.len());
+        for (i, symbol) in symbols.iter().enumerate() {
+            println!("  {}. {} ({:?})", i + 1, symbol.name, symbol.kind);
+        }
+
+        // Verify that generic functions are captured
+        let generic_functions: Vec<_> = symbols
+            .iter()
+            .filter(|s| {
+                matches!(s.kind, SymbolKind::Function)
+                    && s.signature.as_ref().is_some_and(|sig| sig.contains("["))
+            })
+            .collect();
+
+        assert!(
+            !generic_functions.is_empty(),
+            "Should find generic functions"
+        );
+
+        // Verify specific generic symbols
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "Identity"
+                && s.signature
+                    .as_ref()
+                    .is_some_and(|sig| sig.contains("[T any]"))),
+            "Should find Identity generic function"
+        );
+
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "Container"
+                && s.signature
+                    .as_ref()
+                    .is_some_and(|sig| sig.contains("[T any]"))),
+            "Should find Container generic struct"
+        );
+
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "Processor"
+                && s.signature
+                    .as_ref()
+                    .is_some_and(|sig| sig.contains("[T any]"))),
+            "Should find Processor generic interface"
+        );
+
+        println!("\n✅ Go generic type extraction test passed");
+    }
+
+    #[test]
+    fn test_go_generic_struct_field_type_parameters() {
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package main
+
+type GenericContainer[T any, U comparable] struct {
+    items    []T
+    metadata U
+    count    int
+}
+
+type Stack[T any] struct {
+    items []T
+    owner *User
+}
+"#;
+
+        let mut symbol_counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
+        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+        let signature = |name: &str, kind: SymbolKind| {
+            symbols
+                .iter()
+                .find(|s| s.name.as_ref() == name && s.kind == kind)
+                .and_then(|s| s.signature.as_deref())
+                .map(str::to_string)
+        };
+
+        // Fields keep their Go form; the struct declares the type parameters
+        let container = signature("GenericContainer", SymbolKind::Struct).unwrap();
+        assert_eq!(
+            type_parameters_from_signature(&container),
+            vec![("T", "any"), ("U", "comparable")]
+        );
+        assert_eq!(
+            signature("GenericContainer.items", SymbolKind::Field).as_deref(),
+            Some("items []T")
+        );
+        assert_eq!(
+            signature("GenericContainer.metadata", SymbolKind::Field).as_deref(),
+            Some("metadata U")
+        );
+        assert_eq!(
+            signature("GenericContainer.count", SymbolKind::Field).as_deref(),
+            Some("count int")
+        );
+        let stack = signature("Stack", SymbolKind::Struct).unwrap();
+        assert_eq!(type_parameters_from_signature(&stack), vec![("T", "any")]);
+        assert_eq!(
+            signature("Stack.items", SymbolKind::Field).as_deref(),
+            Some("items []T")
+        );
+
+        // Type parameters are not unresolved type uses
+        let uses = parser.find_uses(code);
+        assert!(!uses.iter().any(|(_, to, _)| *to == "T" || *to == "U"));
+        assert!(uses.iter().any(|(_, to, _)| *to == "User"));
+    }
+
+    #[test]
+    fn test_go_interface_implementation_behavior() {
+        println!("\n=== Go Interface Implementation Behavior Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package main
+
+import (
+    "fmt"
+    "io"
+)
+
+// Interface definitions
+type Writer interface {
+    Write([]byte) (int, error)
+}
+
+type Reader interface {
+    Read([]byte) (int, error)
+}
+
+// Struct that implements interfaces through method signatures
+type FileProcessor struct {
+    filename string
+    data     []byte
+}
+
+// These methods make FileProcessor implement Writer interface
+func (f *FileProcessor) Write(data []byte) (int, error) {
+    f.data = append(f.data, data...)
+    return len(data), nil
+}
+
+// This method makes FileProcessor implement Reader interface  
+func (f *FileProcessor) Read(data []byte) (int, error) {
+    copy(data, f.data)
+    return len(f.data), nil
+}
+
+// Interface embedding (composition, not inheritance)
+type ReadWriter interface {
+    Reader
+    Writer
+}
+
+// Type embedding (composition, not inheritance)
+type ExtendedProcessor struct {
+    FileProcessor
+    metadata map[string]string
+}
+"#;
+
+        println!("Test code:\n{code}");
+
+        // Go uses implicit interface implementation
+        // No explicit "implements" declarations exist
+        let implementations = parser.find_implementations(code);
+        println!("\nImplementations found ({}):", implementations.len());
+        assert_eq!(
+            implementations.len(),
+            0,
+            "Go should have no explicit implementations"
+        );
+
+        // Go has no class inheritance
+        // Interface/struct embedding is composition, not inheritance
+        let extends = parser.find_extends(code);
+        println!("Extends relationships found ({}):", extends.len());
+        assert_eq!(extends.len(), 0, "Go should have no extends relationships");
+
+        println!("\n✅ Go interface implementation behavior test passed");
+        println!("✅ Verified that Go returns empty results for explicit relationships");
+        println!("✅ Go's implicit interface implementation requires semantic analysis");
+    }
+
+    #[test]
+    fn test_go_complex_import_patterns() {
+        println!("\n=== Go Complex Import Patterns Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let file_id = FileId::new(1).unwrap();
+
+        let code = r#"
+package main
+
+import (
+    "fmt"
+    "net/http"
+    "path/filepath"
+    
+    // Aliased import
+    httputil "net/http/httputil"
+    
+    // Dot import
+    . "math"
+    
+    // Blank import
+    _ "net/http/pprof"
+    
+    // External module
+    "github.com/gorilla/mux"
+    "github.com/user/project/internal/config"
+)
+
+func main() {
+    fmt.Println("Hello, World!")
+}
+"#;
+
+        let imports = parser.find_imports(code, file_id);
+
+        println!("Found {} imports in Go complex patterns", imports.len());
+        for import in &imports {
+            println!(
+                "  - {} -> {:?} (type_only: {})",
+                import.path, import.alias, import.is_type_only
+            );
+        }
+
+        // Should have 8 imports: standard lib + external modules
+        assert!(imports.len() >= 6, "Should have at least 6 imports");
+
+        // Check for standard library imports
+        assert!(
+            imports.iter().any(|i| i.path == "fmt"),
+            "Should find fmt import"
+        );
+        assert!(
+            imports.iter().any(|i| i.path == "net/http"),
+            "Should find net/http import"
+        );
+
+        // Check for aliased import
+        assert!(
+            imports
+                .iter()
+                .any(|i| i.path == "net/http/httputil" && i.alias == Some("httputil".to_string())),
+            "Should find aliased httputil import"
+        );
+
+        // Check for dot import
+        assert!(
+            imports.iter().any(|i| i.path == "math"),
+            "Should find math import"
+        );
+
+        // Check for blank import
+        assert!(
+            imports.iter().any(|i| i.path == "net/http/pprof"),
+            "Should find pprof import"
+        );
+
+        // Check for external module
+        assert!(
+            imports.iter().any(|i| i.path == "github.com/gorilla/mux"),
+            "Should find external module import"
+        );
+
+        println!("✅ Go complex patterns handled correctly");
+    }
+
+    #[test]
+    fn test_go_import_path_formats() {
+        println!("\n=== Go Import Path Formats Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let file_id = FileId::new(1).unwrap();
+
+        let code = r#"
+package main
+
+import (
+    // Standard library imports
+    "fmt"
+    "strings"
+    "net/http"
+    "encoding/json"
+    
+    // External module imports
+    "github.com/gin-gonic/gin"
+    "github.com/user/repo/pkg/database"
+    "go.uber.org/zap"
+    
+    // Relative imports (rare in Go but valid)
+    "./internal/config"
+    "../shared/utils"
+)
+
+func main() {
+    fmt.Println("Hello, World!")
+}
+"#;
+
+        let imports = parser.find_imports(code, file_id);
+
+        println!("Go path formats found:");
+        for import in &imports {
+            println!("  - {}", import.path);
+        }
+
+        // Check standard library imports
+        assert!(imports.iter().any(|i| i.path == "fmt"));
+        assert!(imports.iter().any(|i| i.path == "strings"));
+        assert!(imports.iter().any(|i| i.path == "net/http"));
+        assert!(imports.iter().any(|i| i.path == "encoding/json"));
+
+        // Check external module imports
+        assert!(imports.iter().any(|i| i.path == "github.com/gin-gonic/gin"));
+        assert!(imports.iter().any(|i| i.path == "go.uber.org/zap"));
+
+        // Check relative imports
+        assert!(imports.iter().any(|i| i.path == "./internal/config"));
+        assert!(imports.iter().any(|i| i.path == "../shared/utils"));
+
+        println!("✅ Various Go path formats extracted correctly");
+    }
+
+    #[test]
+    fn test_go_visibility_variations() {
+        println!("\n=== Go Visibility Variations Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let file_id = FileId::new(1).unwrap();
+
+        let code = r#"
+package main
+
+import "fmt"
+
+// Exported (public) symbols - start with uppercase
+const PublicConstant = "public"
+var PublicVariable = 42
+
+type PublicStruct struct {
+    PublicField   string
+    privateField  int
+}
+
+func PublicFunction() string {
+    return "public function"
+}
+
+func (p *PublicStruct) PublicMethod() {
+    fmt.Println("public method")
+}
 
-        // Walk backwards through previous siblings to collect consecutive comment lines
-        while let Some(sibling) = current {
-            if sibling.kind() == "comment" {
-                let comment_text = &code[sibling.byte_range()];
+// Unexported (private) symbols - start with lowercase
+const privateConstant = "private"
+var privateVariable = 24
 
-                // Check for Go-style line comments starting with //
-                if comment_text.starts_with("//") {
-                    // Extract the comment content (remove // and leading/trailing whitespace)
-                    let content = comment_text.trim_start_matches("//").trim();
+type privateStruct struct {
+    field string
+}
+
+func privateFunction() string {
+    return "private function"
+}
+
+func (p *privateStruct) privateMethod() {
+    fmt.Println("private method")
+}
+"#;
+
+        let mut symbol_counter = SymbolCounter::new();
+        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+
+        println!("Go visibility symbols found:");
+        for symbol in &symbols {
+            let visibility_str = match symbol.visibility {
+                Visibility::Public => "public",
+                _ => "private",
+            };
+            println!(
+                "  - {} ({:?}) -> {}",
+                symbol.name, symbol.kind, visibility_str
+            );
+        }
+
+        // Check for exported symbols
+        let exported_symbols: Vec<_> = symbols
+            .iter()
+            .filter(|s| matches!(s.visibility, Visibility::Public))
+            .collect();
+
+        let unexported_symbols: Vec<_> = symbols
+            .iter()
+            .filter(|s| !matches!(s.visibility, Visibility::Public))
+            .collect();
+
+        assert!(!exported_symbols.is_empty(), "Should find exported symbols");
+        assert!(
+            !unexported_symbols.is_empty(),
+            "Should find unexported symbols"
+        );
+
+        // Check specific exported symbols
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "PublicFunction"
+                && matches!(s.visibility, Visibility::Public))
+        );
+        assert!(symbols.iter().any(
+            |s| s.name.as_ref() == "PublicStruct" && matches!(s.visibility, Visibility::Public)
+        ));
+
+        // Check specific unexported symbols
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "privateFunction"
+                && !matches!(s.visibility, Visibility::Public))
+        );
+        assert!(
+            symbols.iter().any(|s| s.name.as_ref() == "privateStruct"
+                && !matches!(s.visibility, Visibility::Public))
+        );
+
+        println!("✅ Go visibility variations handled correctly");
+    }
+
+    #[test]
+    fn test_go_iota_constant_values_in_signatures() {
+        let mut parser = GoParser::new().unwrap();
+        let code = r#"
+package fs
+
+type Kind int
+
+const (
+    KindNone = iota
+    KindFile Kind = iota + 1
+    KindDir
+    _
+    KindLink
+    Limit = 10
+    Ceiling
+)
+"#;
+        let mut symbol_counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
+        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+        let signature = |name: &str| {
+            symbols
+                .iter()
+                .find(|s| s.name.as_ref() == name && s.kind == SymbolKind::Constant)
+                .and_then(|s| s.signature.as_deref())
+                .map(str::to_string)
+                .unwrap_or_else(|| panic!("{name} not found"))
+        };
+
+        // Bare specs repeat the type and expression before them, the blank
+        // one still advancing iota; an untyped value ends the typed run
+        assert_eq!(signature("KindNone"), "const KindNone = 0");
+        assert_eq!(signature("KindFile"), "const KindFile Kind = 2");
+        assert_eq!(signature("KindDir"), "const KindDir Kind = 3");
+        assert_eq!(signature("KindLink"), "const KindLink Kind = 5");
+        assert_eq!(signature("Ceiling"), "const Ceiling = 10");
+
+        let code = std::fs::read_to_string("examples/go/app/models/user.go").unwrap();
+        let symbols = parser.parse(&code, file_id, &mut symbol_counter);
+        let roles: Vec<_> = symbols
+            .iter()
+            .filter(|s| s.kind == SymbolKind::Constant && s.name.starts_with("Role"))
+            .filter_map(|s| s.signature.as_deref())
+            .collect();
+        assert_eq!(
+            roles,
+            vec![
+                "const RoleGuest UserRole = 0",
+                "const RoleUser UserRole = 1",
+                "const RoleAdmin UserRole = 2",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_go_qualified_constant_uses_in_comparisons_and_cases() {
+        println!("\n=== Go Qualified Constant Uses Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package main
+
+import (
+    "app/models"
+    m "app/config"
+)
+
+func TestExportedTypes() bool {
+    user := models.NewUser("Test", "test@example.com", models.RoleUser)
+    return user.Role() != models.RoleUser
+}
+
+func Describe(role models.UserRole, env string) string {
+    switch role {
+    case models.RoleAdmin, models.RoleGuest:
+        return "special"
+    }
+    if env == m.EnvProduction {
+        return "prod"
+    }
+    return user.Name
+}
+"#;
+
+        let uses = parser.find_uses(code);
+        for (from, to, _) in &uses {
+            println!("  {from} -> {to}");
+        }
+
+        let has_use = |from: &str, to: &str| uses.iter().any(|(f, t, _)| *f == from && *t == to);
+
+        assert!(has_use("TestExportedTypes", "RoleUser"));
+        assert!(has_use("Describe", "RoleAdmin"));
+        assert!(has_use("Describe", "RoleGuest"));
+        assert!(has_use("Describe", "EnvProduction"));
+        // `user` is not an imported package, so `user.Name` is not a qualified constant
+        assert!(!has_use("Describe", "Name"));
+
+        println!("✅ Qualified constants in comparisons and switch cases recorded");
+    }
+
+    #[test]
+    fn test_go_unqualified_package_value_uses() {
+        let mut parser = GoParser::new().unwrap();
+        let code =
+            std::fs::read_to_string("tests/fixtures/go/module_project/services/auth.go").unwrap();
+
+        let uses = parser.find_uses(&code);
+        let used_by = |from: &str| -> Vec<&str> {
+            uses.iter()
+                .filter(|(f, _, _)| *f == from)
+                .map(|(_, to, _)| *to)
+                .collect()
+        };
+        // Constants declared in database.go, each recorded once
+        let new_session = used_by("NewSession");
+        assert_eq!(
+            new_session.iter().filter(|n| **n == "TokenExpiry").count(),
+            1
+        );
+        let can_connect = used_by("CanConnect");
+        assert!(can_connect.contains(&"MaxConnections"));
+        assert!(can_connect.contains(&"defaultPoolSize"));
+        // Parameters, locals, keys and imported packages are not package values
+        for name in ["active", "limit", "userID", "UserID", "time"] {
+            assert!(!uses.iter().any(|(_, to, _)| *to == name), "{name}");
+        }
+    }
+
+    #[test]
+    fn test_go_type_uses_in_every_position() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/type_references.go").unwrap();
+
+        let uses = parser.find_uses(&code);
+        let mut users: Vec<&str> = uses
+            .iter()
+            .filter(|(_, to, _)| *to == "Job")
+            .map(|(from, _, _)| *from)
+            .collect();
+        users.sort();
+        // Each position once; the conversion in Upgrade is a call
+        assert_eq!(
+            users,
+            vec![
+                "Allocate",
+                "Buffer",
+                "Describe",
+                "FirstJob",
+                "IsJob",
+                "NextJob",
+                "PriorityJob",
+                "Queue",
+                "Sample",
+                "Submit",
+            ]
+        );
+    }
+
+    #[test]
+    fn test_go_type_parameters_and_constraints() {
+        let mut parser = GoParser::new().unwrap();
+        let mut code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+        code.push_str("\nfunc Scale[T ~int | ~float64](v T, by T) T {\n\treturn v * by\n}\n");
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut SymbolCounter::new());
+
+        let parameters = |name: &str| {
+            let symbol = symbols
+                .iter()
+                .find(|s| s.name.as_ref() == name)
+                .unwrap_or_else(|| panic!("{name} not extracted"));
+            type_parameters_from_signature(symbol.signature.as_deref().unwrap())
+        };
+        // Single parameter
+        assert_eq!(parameters("Identity"), vec![("T", "any")]);
+        assert_eq!(parameters("Add"), vec![("T", "constraints.Ordered")]);
+        assert_eq!(parameters("Max"), vec![("T", "Comparable")]);
+        assert_eq!(parameters("Stack"), vec![("T", "any")]);
+        assert_eq!(parameters("Scale"), vec![("T", "~int | ~float64")]);
+        // Several, with names sharing a constraint
+        assert_eq!(parameters("Pair"), vec![("T", "any"), ("U", "any")]);
+        assert_eq!(parameters("Map"), vec![("K", "comparable"), ("V", "any")]);
+        assert_eq!(
+            parameters("NewMap"),
+            vec![("K", "comparable"), ("V", "any")]
+        );
+        // Methods use their receiver's parameters
+        assert!(parameters("Push").is_empty());
+
+        let uses = parser.find_uses(&code);
+        for (user, constraint) in [
+            ("Max", "Comparable"),
+            ("Sum", "Number"),
+            ("Add", "constraints.Ordered"),
+        ] {
+            assert!(
+                uses.iter()
+                    .any(|(from, to, _)| *from == user && *to == constraint),
+                "{user} should use {constraint}"
+            );
+        }
+        // Approximations and the parameters themselves are no type uses
+        assert!(
+            !uses
+                .iter()
+                .any(|(from, to, _)| *from == "Scale" && matches!(*to, "int" | "float64" | "T"))
+        );
+    }
+
+    #[test]
+    fn test_go_method_type_parameters_apart_from_receiver() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut SymbolCounter::new());
+
+        let transform = symbols
+            .iter()
+            .find(|s| s.name.as_ref() == "Transform" && s.kind == SymbolKind::Method)
+            .expect("Transform not extracted");
+        let signature = transform.signature.as_deref().unwrap();
+        assert_eq!(receiver_type_parameters(signature), vec!["T"]);
+        assert_eq!(
+            type_parameters_from_signature(signature),
+            vec![("U", "any")]
+        );
+
+        // transformer's func(T) U takes the receiver's T and returns the
+        // method's U; neither is a declared type
+        let transformer = symbols
+            .iter()
+            .find(|s| s.name.as_ref() == "transformer")
+            .expect("transformer parameter not extracted");
+        assert_eq!(
+            transformer.signature.as_deref(),
+            Some("transformer func(T) U")
+        );
+        let uses = parser.find_uses(&code);
+        assert!(
+            !uses
+                .iter()
+                .any(|(from, to, _)| *from == "Transform" && matches!(*to, "T" | "U")),
+            "{uses:?}"
+        );
+
+        // A call binds the receiver's T only: Transform's []U stays unknown
+        // instead of naming a type U, and a shadowing T is the method's own
+        let code = r#"package store
+
+type User struct{}
+
+type Repository[T any] struct {
+	items []T
+}
+
+func (r *Repository[T]) First() T { return r.items[0] }
+
+func (r *Repository[T]) Transform[U any](transformer func(T) U) []U { return nil }
+
+func (r *Repository[T]) Shadow[T any]() T { return nil }
+
+func Use() {
+	repo := &Repository[User]{}
+	first := repo.First()
+	names := repo.Transform(nil)
+	shadowed := repo.Shadow()
+}
+"#;
+        let types = parser.find_variable_types(code);
+        let type_of = |name: &str| {
+            types
+                .iter()
+                .find(|(variable, _, _)| *variable == name)
+                .map(|(_, type_name, _)| *type_name)
+        };
+        assert_eq!(type_of("first"), Some("User"));
+        assert_eq!(type_of("names"), None);
+        assert_eq!(type_of("shadowed"), None);
+    }
+
+    #[test]
+    fn test_go_generic_instantiations() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+
+        let instantiations: Vec<(&str, &str, Option<&str>)> = parser
+            .find_instantiations_in(&code)
+            .into_iter()
+            .map(|(context, generic, arguments, _)| (context, generic, arguments))
+            .collect();
+        for expected in [
+            ("ExampleUsage", "Stack", Some("string")),
+            ("ExampleUsage", "NewMap", Some("int, string")),
+            ("IntSet", "Map", Some("int, struct{}")),
+            // Inferred from the arguments
+            ("ExampleUsage", "Sum", None),
+            ("ExampleUsage", "Pair", None),
+            ("ProcessSerializableNumbers", "Sum", None),
+        ] {
+            assert!(
+                instantiations.contains(&expected),
+                "{expected:?} not in {instantiations:?}"
+            );
+        }
+        // Type parameters passed on, `Zero[T]()` in Deref, are no concrete
+        // instantiation
+        assert!(
+            !instantiations
+                .iter()
+                .any(|(context, _, _)| *context == "Deref")
+        );
+        assert!(
+            !instantiations
+                .iter()
+                .any(|(_, _, arguments)| matches!(arguments, Some("T" | "K, V" | "string, T")))
+        );
+    }
+
+    #[test]
+    fn test_go_generic_aliases() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut SymbolCounter::new());
+
+        for alias in ["StringMap", "IntSet"] {
+            let symbol = symbols
+                .iter()
+                .find(|s| s.name.as_ref() == alias)
+                .unwrap_or_else(|| panic!("{alias} should be extracted"));
+            assert_eq!(symbol.kind, SymbolKind::TypeAlias);
+            assert_eq!(
+                symbol
+                    .signature
+                    .as_deref()
+                    .and_then(alias_target_from_signature),
+                Some("Map")
+            );
+        }
+
+        // The alias uses its target
+        let uses = parser.find_uses(&code);
+        for alias in ["StringMap", "IntSet"] {
+            assert!(
+                uses.iter()
+                    .any(|(context, used, _)| *context == alias && *used == "Map"),
+                "{alias} should use Map"
+            );
+        }
+
+        assert_eq!(
+            alias_instantiation("StringMap[V any] = Map[string, V]", "int").as_deref(),
+            Some("Map[string, int]")
+        );
+        assert_eq!(
+            alias_instantiation("IntSet = Map[int, struct{}]", "").as_deref(),
+            Some("Map[int, struct{}]")
+        );
+        assert_eq!(
+            alias_instantiation(
+                "Pairs[K comparable, V any] = []Pair[K, Value[V]]",
+                "string, int"
+            )
+            .as_deref(),
+            Some("[]Pair[string, Value[int]]")
+        );
+        // Wrong argument count, and a defined type
+        assert_eq!(
+            alias_instantiation("StringMap[V any] = Map[string, V]", ""),
+            None
+        );
+        assert_eq!(alias_instantiation("Named InnerStruct", "int"), None);
+    }
+
+    #[test]
+    fn test_go_cgo_preamble_functions() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("examples/go/comprehensive.go").unwrap();
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut SymbolCounter::new());
+
+        // The cgo block leaves the rest of the file intact
+        for name in ["CallExternalFunction", "CustomError", "TestConfig", "main"] {
+            assert!(
+                symbols.iter().any(|s| s.name.as_ref() == name),
+                "{name} should be extracted"
+            );
+        }
+        let external = symbols
+            .iter()
+            .find(|s| s.name.as_ref() == "C.external_function")
+            .expect("preamble function should be extracted");
+        assert_eq!(external.kind, SymbolKind::Function);
+        assert_eq!(
+            external.signature.as_deref(),
+            Some("int external_function(int x)")
+        );
+        assert_eq!(external.visibility, Visibility::Private);
+        // Lines 318 to 320 of the file
+        assert_eq!(
+            (external.range.start_line, external.range.end_line),
+            (317, 319)
+        );
+
+        let calls = parser.find_calls(&code);
+        assert!(
+            calls
+                .iter()
+                .any(|(caller, called, _)| *caller == "CallExternalFunction"
+                    && *called == "C.external_function")
+        );
+        assert!(
+            !parser
+                .find_method_calls(&code)
+                .iter()
+                .any(|call| call.receiver.as_deref() == Some("C"))
+        );
+
+        // Prototypes count, struct definitions, typedefs and variables don't
+        let preamble = "struct point { int x; };\ntypedef int (*op)(int);\nint (*handler)(int);\nstatic inline double *scale(double *v, int n);\nvoid reset(void) { if (1) { } }\n";
+        let found: Vec<(&str, String)> = cgo_functions(preamble)
+            .into_iter()
+            .map(|(name, signature, _, _)| (name, signature))
+            .collect();
+        assert_eq!(
+            found,
+            vec![
+                (
+                    "scale",
+                    "static inline double *scale(double *v, int n)".to_string()
+                ),
+                ("reset", "void reset(void)".to_string()),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_go_two_level_package_selectors() {
+        println!("\n=== Go Two-Level Package Selectors Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package main
+
+import "app/models"
+
+func Check(role models.UserRole, cfg Config) bool {
+    if role == models.Defaults.Role {
+        return true
+    }
+    return cfg.server.Port != models.DefaultPort
+}
+"#;
+
+        let uses = parser.find_uses(code);
+        for (from, to, _) in &uses {
+            println!("  {from} -> {to}");
+        }
+        let has_use = |from: &str, to: &str| uses.iter().any(|(f, t, _)| *f == from && *t == to);
+
+        // package.Type in a type position keeps its qualifier
+        assert!(has_use("Check", "models.UserRole"));
+        // package.Var.Field drops the package
+        assert!(has_use("Check", "Defaults.Role"));
+        assert!(has_use("Check", "DefaultPort"));
+        // `cfg` is a value, so `cfg.server.Port` is not a package selector
+        assert!(!uses.iter().any(|(_, t, _)| t.contains("server")));
+    }
+
+    #[test]
+    fn test_go_methods_attach_to_defined_type() {
+        println!("\n=== Go Methods On Defined Types Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+
+        let code = r#"
+package models
 
-                    // Add to the beginning of doc_lines since we're walking backwards
-                    doc_lines.insert(0, content.to_string());
+type UserRole int
+type LogLevel int
 
-                    // Continue to previous sibling to check for more comment lines
-                    current = sibling.prev_sibling();
-                } else {
-                    // Found a non-Go comment (like /* */), stop collecting
-                    break;
-                }
-            } else {
-                // Found a non-comment node, stop collecting
-                break;
-            }
+func (r UserRole) String() string { return "role" }
+func (r UserRole) IsValid() bool { return r >= 0 }
+func (l LogLevel) String() string { return "level" }
+func (s *Stack[T]) Push(v T) {}
+"#;
+
+        let defines = parser.find_defines(code);
+        for (definer, method, _) in &defines {
+            println!("  {definer} defines {method}");
         }
 
-        // If we found any documentation comments, join them and return
-        if !doc_lines.is_empty() {
-            // Filter out empty lines and join with newlines
-            let filtered_lines: Vec<String> = doc_lines
-                .into_iter()
-                .filter(|line| !line.is_empty())
-                .collect();
+        let methods_of = |ty: &str| {
+            defines
+                .iter()
+                .filter(|(definer, _, _)| *definer == ty)
+                .map(|(_, method, _)| *method)
+                .collect::<Vec<&str>>()
+        };
+        assert_eq!(methods_of("UserRole"), vec!["String", "IsValid"]);
+        assert_eq!(methods_of("LogLevel"), vec!["String"]);
+        assert_eq!(methods_of("Stack"), vec!["Push"]);
 
-            if !filtered_lines.is_empty() {
-                let joined = filtered_lines.join("\n").trim().to_string();
-                if !joined.is_empty() {
-                    return Some(joined);
-                }
-            }
-        }
+        assert_eq!(
+            receiver_type_from_signature("func (r UserRole) String() string"),
+            Some("UserRole")
+        );
+        assert_eq!(
+            receiver_type_from_signature("func (s *Stack[T]) Push(v T)"),
+            Some("Stack")
+        );
+        assert_eq!(receiver_type_from_signature("func NewUser() *User"), None);
+    }
 
-        None
+    #[test]
+    fn test_go_method_receiver_kind() {
+        let receiver =
+            |signature: &str| method_receiver(signature).map(|r| (r.name, r.kind, r.type_name));
+        assert_eq!(
+            receiver("func (u User) GetDisplayName() string"),
+            Some((
+                Some("u".to_string()),
+                ReceiverKind::Value,
+                "User".to_string()
+            ))
+        );
+        assert_eq!(
+            receiver("func (u *User) SetAge(age int)"),
+            Some((
+                Some("u".to_string()),
+                ReceiverKind::Pointer,
+                "User".to_string()
+            ))
+        );
+        assert_eq!(
+            receiver("func (*User) Reset()"),
+            Some((None, ReceiverKind::Pointer, "User".to_string()))
+        );
+        assert_eq!(
+            receiver("func (_ Config) Default() Config"),
+            Some((None, ReceiverKind::Value, "Config".to_string()))
+        );
+        assert_eq!(
+            receiver("func (m *Map[K, V]) Set(key K, value V)"),
+            Some((
+                Some("m".to_string()),
+                ReceiverKind::Pointer,
+                "Map".to_string()
+            ))
+        );
+        assert_eq!(
+            receiver("func (Map[K, V]) Len() int"),
+            Some((None, ReceiverKind::Value, "Map".to_string()))
+        );
+        assert_eq!(receiver("func NewUser() *User"), None);
     }
 
-    /// Extract function calls from Go source code
-    ///
-    /// Returns tuples of (caller_context, called_function, range) for all function calls
-    /// including method calls via dot notation and package-qualified calls.
-    fn find_calls<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
-        let tree = match self.parser.parse(code, None) {
-            Some(tree) => tree,
-            None => return Vec::new(),
-        };
+    #[test]
+    fn test_go_range_over_int() {
+        println!("\n=== Go Range Over Int Test ===\n");
 
-        let root = tree.root_node();
-        let mut calls = Vec::new();
+        let mut parser = GoParser::new().unwrap();
+        let mut counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
 
-        // Track current function context
-        self.extract_calls_recursive(&root, code, None, &mut calls);
+        // Its own module, since ranging over an integer needs go 1.22
+        let code = std::fs::read_to_string("tests/fixtures/go/range_over_int/loops.go").unwrap();
 
-        calls
+        let symbols = parser.parse(&code, file_id, &mut counter);
+        let loop_vars: Vec<(&str, Option<&str>)> = symbols
+            .iter()
+            .filter(|s| s.kind == SymbolKind::Variable)
+            .filter(|s| {
+                s.signature
+                    .as_deref()
+                    .is_some_and(|sig| sig.contains(":= range"))
+            })
+            .map(|s| (s.name.as_str(), s.signature.as_deref()))
+            .collect();
+        for (name, signature) in &loop_vars {
+            println!("  {name}: {signature:?}");
+        }
+
+        assert_eq!(
+            loop_vars,
+            vec![
+                ("i", Some("i := range (int)")),
+                ("j", Some("j := range (int)")),
+                ("k", Some("k := range (index)")),
+                ("v", Some("v := range (value)")),
+            ]
+        );
+
+        // The loop variable is scoped to the enclosing function
+        let i = symbols.iter().find(|s| s.name.as_str() == "i").unwrap();
+        assert!(matches!(
+            &i.scope_context,
+            Some(crate::symbol::ScopeContext::Local { parent_name: Some(parent), .. })
+                if parent.as_str() == "RangeOverInt"
+        ));
     }
 
-    /// Extract method calls from Go source code
-    ///
-    /// Returns MethodCall structs containing caller, method name, and position information
-    /// for all method invocations including pointer receiver calls and chained calls.
-    fn find_method_calls(&mut self, code: &str) -> Vec<MethodCall> {
-        let tree = match self.parser.parse(code, None) {
-            Some(tree) => tree,
-            None => return Vec::new(),
-        };
+    #[test]
+    fn test_go_range_over_func_iterators() {
+        println!("\n=== Go Range Over Func Test ===\n");
 
-        let root = tree.root_node();
-        let mut method_calls = Vec::new();
+        let mut parser = GoParser::new().unwrap();
+        let mut counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
 
-        self.extract_method_calls_recursive(&root, code, None, &mut method_calls);
+        let code = r#"
+package main
 
-        method_calls
-    }
+import "iter"
 
-    /// Go uses implicit interface implementation (duck typing).
-    /// Types implement interfaces by having matching method signatures,
-    /// not through explicit declarations. This cannot be reliably detected
-    /// through AST parsing alone - it requires cross-file semantic analysis.
-    fn find_implementations<'a>(&mut self, _code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
-        // Return empty vector - Go has no explicit implementation declarations
-        Vec::new()
-    }
+func (l *List[T]) All() func(yield func(int, T) bool) {
+    return nil
+}
 
-    /// Go doesn't have class inheritance. Interfaces can embed other interfaces,
-    /// but this is composition, not inheritance. Struct types can embed other types,
-    /// but again, this is composition rather than inheritance.
-    fn find_extends<'a>(&mut self, _code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
-        // Return empty vector - Go has no class inheritance
-        Vec::new()
-    }
+func Keys(m map[string]int) iter.Seq[string] {
+    return nil
+}
 
-    /// Extract import declarations from Go source code
-    ///
-    /// Handles all Go import styles:
-    /// - Standard imports: `import "fmt"`
-    /// - Grouped imports: `import ( "fmt"; "os" )`
-    /// - Aliased imports: `import f "fmt"`
-    /// - Dot imports: `import . "fmt"`
-    /// - Blank imports: `import _ "database/sql"`
-    fn find_imports(&mut self, code: &str, file_id: FileId) -> Vec<Import> {
-        let mut imports = Vec::new();
+func Consume(seq func(yield func(User) bool), list *List[Order]) {
+    for u := range seq {
+        _ = u
+    }
+    for idx, order := range list.All() {
+        _, _ = idx, order
+    }
+    for key := range Keys(nil) {
+        _ = key
+    }
+    evens := func(yield func(int) bool) {}
+    for n := range evens {
+        _ = n
+    }
+}
+"#;
 
-        if let Some(tree) = self.parser.parse(code, None) {
-            let root = tree.root_node();
-            self.extract_imports_from_node(root, code, file_id, &mut imports);
+        let symbols = parser.parse(code, file_id, &mut counter);
+        let signature_of = |name: &str| {
+            symbols
+                .iter()
+                .find(|s| s.name.as_str() == name && s.kind == SymbolKind::Variable)
+                .and_then(|s| s.signature.as_deref())
+        };
+        for name in ["u", "idx", "order", "key", "n"] {
+            println!("  {name}: {:?}", signature_of(name));
         }
 
-        imports
+        assert_eq!(signature_of("u"), Some("u := range (User)"));
+        assert_eq!(signature_of("idx"), Some("idx := range (int)"));
+        // Type parameters are reported as declared on the iterator
+        assert_eq!(signature_of("order"), Some("order := range (T)"));
+        assert_eq!(signature_of("key"), Some("key := range (string)"));
+        assert_eq!(signature_of("n"), Some("n := range (int)"));
+
+        assert_eq!(
+            iterator_yield_types("iter.Seq2[string, []int]"),
+            Some(vec!["string".to_string(), "[]int".to_string()])
+        );
+        assert_eq!(iterator_yield_types("<-chan T"), None);
     }
 
-    /// Extract type usage references from Go source code
-    ///
-    /// Returns tuples of (context, type_name, range) for all type references
-    /// including struct field types, function parameters, and return types.
-    fn find_uses<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
-        let tree = match self.parser.parse(code, None) {
-            Some(tree) => tree,
-            None => return Vec::new(),
+    #[test]
+    fn test_go_receiver_indirection() {
+        println!("\n=== Go Receiver Indirection Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/structs.go").unwrap();
+
+        let calls = parser.find_method_calls(&code);
+        let receiver_of = |method: &str| {
+            calls
+                .iter()
+                .find(|c| c.caller == "ReceiverAdjustments" && c.method_name == method)
+                .and_then(|c| c.receiver.as_deref())
         };
+        println!("  Verify receiver: {:?}", receiver_of("Verify"));
+        println!("  GetFullName receiver: {:?}", receiver_of("GetFullName"));
 
-        let root = tree.root_node();
-        let mut uses = Vec::new();
+        // `(&u).Verify()` and `(*p).GetFullName()` call through the variable
+        assert_eq!(receiver_of("Verify"), Some("u"));
+        assert_eq!(receiver_of("GetFullName"), Some("p"));
 
-        self.extract_type_uses_recursive(&root, code, &mut uses);
+        let types = parser.find_variable_types(&code);
+        let type_of = |var: &str, line: u32| {
+            types
+                .iter()
+                .find(|(name, _, range)| *name == var && range.start_line == line)
+                .map(|(_, typ, _)| *typ)
+        };
+        let line_of = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-        uses
+        // Pointer and value declarations map to the same base type
+        assert_eq!(
+            type_of("p", line_of("func ReceiverAdjustments")),
+            Some("Person")
+        );
+        assert_eq!(type_of("u", line_of("u := User{")), Some("User"));
+        assert_eq!(type_of("user", line_of("user := NewUser(")), Some("User"));
+        assert_eq!(type_of("dest", line_of("func CopyUserInfo")), Some("User"));
     }
 
-    /// Extract method definitions from Go source code
-    ///
-    /// Returns tuples of (receiver_type, method_name, range) for all method definitions
-    /// with explicit receivers, distinguishing them from standalone functions.
-    fn find_defines<'a>(&mut self, code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
-        let tree = match self.parser.parse(code, None) {
-            Some(tree) => tree,
-            None => return Vec::new(),
-        };
+    #[test]
+    fn test_go_method_calls_on_indexed_call_results() {
+        println!("\n=== Go Indexed Call Result Receivers Test ===\n");
 
-        let root = tree.root_node();
-        let mut defines = Vec::new();
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/structs.go").unwrap();
 
-        self.extract_method_defines_recursive(&root, code, &mut defines);
+        let calls = parser.find_method_calls(&code);
+        let receivers: Vec<_> = calls
+            .iter()
+            .filter(|c| c.caller == "IndexedCallResults")
+            .map(|c| (c.method_name.as_str(), c.receiver.as_deref(), c.is_static))
+            .collect();
+        println!("  IndexedCallResults calls: {receivers:?}");
 
-        defines
+        // The element type of the map or slice result names the receiver
+        assert!(receivers.contains(&("Execute", Some("Handler"), true)));
+        assert!(receivers.contains(&("Verify", Some("User"), true)));
+        // The call producing the slice is still a call on the variable
+        assert!(receivers.contains(&("Contacts", Some("p"), false)));
     }
 
-    fn language(&self) -> crate::parsing::Language {
-        crate::parsing::Language::Go
-    }
-}
+    #[test]
+    fn test_go_make_and_append_element_types() {
+        println!("\n=== Go make/append Element Types Test ===\n");
 
-impl NodeTracker for GoParser {
-    fn get_handled_nodes(&self) -> &std::collections::HashSet<HandledNode> {
-        self.node_tracker.get_handled_nodes()
-    }
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/structs.go").unwrap();
+        let line_of = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-    fn register_handled_node(&mut self, node_kind: &str, node_id: u16) {
-        self.node_tracker.register_handled_node(node_kind, node_id)
+        let calls = parser.find_method_calls(&code);
+        let receivers: Vec<_> = calls
+            .iter()
+            .filter(|c| c.caller == "VerifyAll")
+            .map(|c| (c.method_name.as_str(), c.receiver.as_deref(), c.is_static))
+            .collect();
+        println!("  VerifyAll calls: {receivers:?}");
+        // `users[i].Verify()` calls the method on an element of the slice
+        assert!(receivers.contains(&("Verify", Some("User"), true)));
+        assert!(receivers.contains(&("SetAge", Some("User"), true)));
+
+        let bindings = parser.find_variable_bindings(&code);
+        let types_of = |var: &str, line: u32| {
+            bindings
+                .iter()
+                .find(|b| b.name == var && b.range.start_line == line)
+                .map(|b| (b.narrowed_type(), b.element_type))
+        };
+        // The slice keeps its own type and its element type, and append
+        // keeps both
+        assert_eq!(
+            types_of("users", line_of("users := make([]User, n)")),
+            Some((Some("[]User"), Some("User")))
+        );
+        assert_eq!(
+            types_of("users", line_of("users = append(users")),
+            Some((Some("[]User"), Some("User")))
+        );
+
+        // Elements read by index or range take the element type
+        let code = r#"
+package main
+
+func Elements(users []User, byName map[string]*User, ch chan Order) {
+    first := users[0]
+    for _, u := range users {
+        u.Verify()
+    }
+    for o := range ch {
+        o.Ship()
     }
+    admin := byName["admin"]
+    _, _ = first, admin
 }
-
-#[cfg(test)]
-mod tests {
-    use super::*;
-    use crate::types::FileId;
+"#;
+        let types = parser.find_variable_types(code);
+        let type_of = |var: &str| {
+            types
+                .iter()
+                .find(|(name, _, _)| *name == var)
+                .map(|(_, typ, _)| *typ)
+        };
+        assert_eq!(type_of("users"), Some("[]User"));
+        assert_eq!(type_of("byName"), Some("map[string]*User"));
+        assert_eq!(type_of("first"), Some("User"));
+        assert_eq!(type_of("u"), Some("User"));
+        assert_eq!(type_of("o"), Some("Order"));
+        assert_eq!(type_of("admin"), Some("User"));
+    }
 
     #[test]
-    fn test_go_import_extraction() {
-        println!("\n=== Go Import Extraction Test ===\n");
+    fn test_go_package_var_initializer_dependencies() {
+        println!("\n=== Go Package Variable Initializer Test ===\n");
 
         let mut parser = GoParser::new().unwrap();
-        let file_id = FileId::new(1).unwrap();
-
         let code = r#"
-package main
+package utils
 
-import (
-    "fmt"
-    "strings"
-    "net/http"
-    utils "github.com/user/repo/utils"
-    . "encoding/json"
-    _ "database/sql"
-    "path/filepath"
+import "strings"
+
+var (
+    DefaultProcessor *DataProcessor
+    separator        = strings.TrimSpace(rawSeparator)
+    rawSeparator     = ", "
 )
+
+var registry = NewRegistry(separator)
+
+func init() {
+    count := 0
+    count = countProcessors()
+    DefaultProcessor = CreateDefaultProcessor()
+    DefaultProcessor.Configure(registry)
+}
 "#;
 
-        println!("Test code:\n{code}");
+        let calls = parser.find_calls(code);
+        let edges: Vec<_> = calls.iter().map(|(from, to, _)| (*from, *to)).collect();
+        println!("  calls: {edges:?}");
+        assert!(edges.contains(&("registry", "NewRegistry")));
+        // The init assignment links both init and the variable to the constructor
+        assert!(edges.contains(&("init", "CreateDefaultProcessor")));
+        assert!(edges.contains(&("DefaultProcessor", "CreateDefaultProcessor")));
+        // Locals of init are not package variables
+        assert!(!edges.iter().any(|(from, _)| *from == "count"));
+
+        let method_calls = parser.find_method_calls(code);
+        assert!(
+            method_calls
+                .iter()
+                .any(|c| c.caller == "separator" && c.method_name == "TrimSpace")
+        );
 
-        let imports = parser.find_imports(code, file_id);
+        let uses = parser.find_uses(code);
+        let uses: Vec<_> = uses.iter().map(|(from, to, _)| (*from, *to)).collect();
+        println!("  uses: {uses:?}");
+        assert!(uses.contains(&("separator", "rawSeparator")));
+        assert!(uses.contains(&("registry", "separator")));
+    }
 
-        println!("\nExtracted {} imports:", imports.len());
-        for (i, import) in imports.iter().enumerate() {
-            println!(
-                "  {}. {} -> {:?} (type_only: {})",
-                i + 1,
-                import.path,
-                import.alias,
-                import.is_type_only
-            );
-        }
+    #[test]
+    fn test_go_field_access_through_embedded_pointer() {
+        println!("\n=== Go Embedded Pointer Field Access Test ===\n");
 
-        // Verify counts - Go should extract 7 imports
-        assert_eq!(imports.len(), 7, "Should extract 7 imports");
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/complex.go").unwrap();
 
-        // Verify specific imports
-        // Standard library import
-        assert!(imports.iter().any(|i| i.path == "fmt" && i.alias.is_none()));
+        // `*WorkerPool` is indexed as the embedded field `Application.WorkerPool`
+        let mut counter = SymbolCounter::new();
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut counter);
+        let embedded = symbols
+            .iter()
+            .find(|s| s.kind == SymbolKind::Field && &*s.name == "Application.WorkerPool")
+            .expect("embedded field should be indexed");
+        assert_eq!(
+            embedded_field_type(&embedded.name, embedded.signature.as_deref().unwrap()),
+            Some("WorkerPool")
+        );
 
-        // Aliased import
-        assert!(imports.iter().any(
-            |i| i.path == "github.com/user/repo/utils" && i.alias == Some("utils".to_string())
-        ));
+        let accesses = parser.find_field_accesses_in(&code);
+        let submit: Vec<_> = accesses
+            .iter()
+            .filter(|(context, _, _)| *context == "SubmitJob")
+            .map(|(_, field, _)| field.as_str())
+            .collect();
+        println!("  SubmitJob accesses: {submit:?}");
+        assert!(submit.contains(&"Application.WorkerPool"));
+        assert!(submit.contains(&"WorkerPool.jobQueue"));
+        assert!(submit.contains(&"Application.logger"));
 
-        // Dot import (not implemented as alias, but should be present)
-        assert!(imports.iter().any(|i| i.path == "encoding/json"));
+        // The implicit form is promoted through the embedded pointer
+        let code = r#"
+package main
 
-        // Blank import
-        assert!(imports.iter().any(|i| i.path == "database/sql"));
+type WorkerPool struct {
+    jobQueue chan Job
+}
 
-        println!("=== PASSED ===\n");
+type Application struct {
+    *WorkerPool
+    name string
+}
+
+func (a *Application) Pending() int {
+    return len(a.jobQueue) + len(a.name)
+}
+"#;
+        let accesses = parser.find_field_accesses_in(code);
+        let fields: Vec<_> = accesses
+            .iter()
+            .map(|(_, field, _)| field.as_str())
+            .collect();
+        assert_eq!(fields, vec!["WorkerPool.jobQueue", "Application.name"]);
     }
 
     #[test]
-    fn test_go_generic_type_extraction() {
-        println!("\n=== Go Generic Type Extraction Test ===\n");
+    fn test_go_type_alias_methods() {
+        println!("\n=== Go Type Alias Methods Test ===\n");
 
         let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/structs.go").unwrap();
+        let mut counter = SymbolCounter::new();
+        let symbols = parser.parse(&code, FileId::new(1).unwrap(), &mut counter);
 
-        let code = r#"
-package main
+        // `type A = B` is extracted like a defined type
+        let alias = symbols
+            .iter()
+            .find(|s| s.name.as_ref() == "PublicInnerStruct")
+            .expect("alias should be extracted");
+        println!("  alias: {:?}", alias.signature);
+        assert_eq!(alias.kind, SymbolKind::TypeAlias);
+        assert_eq!(
+            alias
+                .signature
+                .as_deref()
+                .and_then(alias_target_from_signature),
+            Some("InnerStruct")
+        );
 
-import "fmt"
+        // The call goes through a variable of the alias type, which resolution
+        // maps to `InnerStruct.Describe`
+        let calls = parser.find_method_calls(&code);
+        let call = calls
+            .iter()
+            .find(|c| c.caller == "DescribeAlias" && c.method_name == "Describe")
+            .unwrap();
+        assert_eq!(call.receiver.as_deref(), Some("p"));
+        let types = parser.find_variable_types(&code);
+        assert!(
+            types
+                .iter()
+                .any(|(name, typ, _)| *name == "p" && *typ == "PublicInnerStruct")
+        );
 
-// Generic function with type constraint
-func Identity[T any](value T) T {
-    return value
-}
+        assert_eq!(
+            alias_target_from_signature("Ptr = *models.Stack[int]"),
+            Some("models.Stack")
+        );
+        assert_eq!(alias_target_from_signature("Named InnerStruct"), None);
+    }
 
-// Generic function with multiple type parameters
-func Compare[T comparable](a, b T) bool {
-    return a == b
-}
+    #[test]
+    fn test_go_elements_of_fields_narrow_to_stored_type() {
+        println!("\n=== Go Field Element Narrowing Test ===\n");
 
-// Generic struct
-type Container[T any] struct {
-    items []T
-}
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/complex.go").unwrap();
+        let bindings = parser.find_variable_bindings(&code);
+        let line_of = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-// Generic interface
-type Processor[T any] interface {
-    Process(T) error
-}
+        // InitializeApplicationWithDefaults stores only &DefaultProcessor{}
+        let processor = bindings
+            .iter()
+            .find(|b| b.name == "processor" && b.range.start_line == line_of("range a.processors"))
+            .unwrap();
+        println!("  processor: {processor:?}");
+        assert_eq!(processor.declared_type, Some("JobProcessor"));
+        assert_eq!(processor.concrete_type, Some("DefaultProcessor"));
+
+        let code = std::fs::read_to_string("tests/fixtures/go/field_dispatch.go").unwrap();
+        let bindings = parser.find_variable_bindings(&code);
+        let binding = |name: &str| *bindings.iter().find(|b| b.name == name).unwrap();
+        assert_eq!(binding("found").narrowed_type(), Some("DefaultProcessor"));
+        // Two concrete types are appended to fallbacks
+        let fallback = binding("fallback");
+        assert_eq!(fallback.declared_type, Some("JobProcessor"));
+        assert_eq!(fallback.concrete_type, None);
+    }
 
-func main() {
-    // Instantiate generic types
-    intContainer := Container[int]{items: []int{1, 2, 3}}
-    stringContainer := Container[string]{items: []string{"a", "b"}}
-    
-    fmt.Println(Identity(42))
-    fmt.Println(Compare("hello", "world"))
-    fmt.Println(intContainer.items)
-    fmt.Println(stringContainer.items)
-}
-"#;
+    #[test]
+    fn test_go_interface_variables_keep_concrete_type() {
+        println!("\n=== Go Declared vs Concrete Types Test ===\n");
 
-        println!("Test code:\n{code}");
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/interfaces.go").unwrap();
+        let line_of = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-        // Parse the code to extract symbols
-        let mut symbol_counter = SymbolCounter::new();
-        let file_id = FileId::new(1).unwrap();
-        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+        let bindings = parser.find_variable_bindings(&code);
+        let binding_at = |var: &str, line: u32| {
+            bindings
+                .iter()
+                .find(|b| b.name == var && b.range.start_line == line)
+                .copied()
+        };
 
-        println!("\nExtracted {} symbols:", symbols.len());
-        for (i, symbol) in symbols.iter().enumerate() {
-            println!("  {}. {} ({:?})", i + 1, symbol.name, symbol.kind);
-        }
+        let p = binding_at("p", line_of("var p DataProcessor = &FileProcessor")).unwrap();
+        println!("  p: {p:?}");
+        assert_eq!(p.declared_type, Some("DataProcessor"));
+        assert_eq!(p.concrete_type, Some("FileProcessor"));
+
+        let q_declared = binding_at("q", line_of("var q DataProcessor")).unwrap();
+        assert_eq!(q_declared.concrete_type, None);
+        let q_assigned = binding_at("q", line_of("q = &JSONProcessor{}")).unwrap();
+        assert_eq!(q_assigned.declared_type, None);
+        assert_eq!(q_assigned.concrete_type, Some("JSONProcessor"));
+
+        // Parameters only have a declared type
+        let processor = binding_at("processor", line_of("func ProcessData(")).unwrap();
+        assert_eq!(processor.narrowed_type(), Some("DataProcessor"));
+
+        // Each call sees the latest binding of its own local
+        let receivers = parser.find_receiver_types_in(&code);
+        let receiver_type = |var: &str, needle: &str| {
+            receivers
+                .iter()
+                .find(|(name, _, range)| *name == var && range.start_line == line_of(needle))
+                .map(|(_, typ, _)| *typ)
+        };
+        assert_eq!(
+            receiver_type("p", "!p.Validate(data)"),
+            Some(Some("FileProcessor"))
+        );
+        assert_eq!(
+            receiver_type("q", "_ = q.GetMetadata()"),
+            Some(Some("JSONProcessor"))
+        );
+        assert_eq!(
+            receiver_type("p", "return p.Process(data)"),
+            Some(Some("FileProcessor"))
+        );
+    }
 
-        // Verify that generic functions are captured
-        let generic_functions: Vec<_> = symbols
+    #[test]
+    fn test_go_method_expression_calls() {
+        println!("\n=== Go Method Expression Calls Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/interfaces.go").unwrap();
+        let call_line = code
+            .lines()
+            .position(|l| l.contains("return f(u)"))
+            .unwrap() as u32;
+
+        // `f := (*User).String; f(u)` calls String on User
+        let method_calls = parser.find_method_calls(&code);
+        let call = method_calls
             .iter()
-            .filter(|s| {
-                matches!(s.kind, SymbolKind::Function)
-                    && s.signature.as_ref().is_some_and(|sig| sig.contains("["))
-            })
+            .find(|c| c.caller == "DescribeUser")
+            .unwrap();
+        println!("  {call:?}");
+        assert_eq!(call.method_name, "String");
+        assert_eq!(call.receiver.as_deref(), Some("User"));
+        assert!(call.is_static);
+        assert_eq!(call.range.start_line, call_line);
+
+        // ... and not a function named `f`
+        let calls = parser.find_calls(&code);
+        assert!(
+            !calls
+                .iter()
+                .any(|(caller, callee, _)| *caller == "DescribeUser" && *callee == "f")
+        );
+
+        // A method value on a variable is not a method expression: the call
+        // is on the variable
+        let code = "package main\n\ntype User struct{}\n\nfunc (u User) String() string { return \"\" }\n\nfunc Show(u User) string {\n\tg := u.String\n\treturn g()\n}\n";
+        let shown: Vec<_> = parser
+            .find_method_calls(code)
+            .into_iter()
+            .filter(|c| c.caller == "Show")
+            .map(|c| (c.receiver, c.method_name, c.is_static))
             .collect();
+        assert_eq!(
+            shown,
+            vec![(Some("u".to_string()), "String".to_string(), false)]
+        );
+    }
 
+    #[test]
+    fn test_go_method_values_in_closures() {
+        println!("\n=== Go Method Values In Closures Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/interfaces.go").unwrap();
+        let line_of = |text: &str| code.lines().position(|l| l.contains(text)).unwrap() as u32;
+
+        // `f := u.String` inside the closure, and `describe := u.String`
+        // captured by it, both call String on `u`
+        let method_calls = parser.find_method_calls(&code);
+        for (caller, call_text) in [
+            ("DeferredDescription", "return f()"),
+            ("LazyDescription", "return describe()"),
+        ] {
+            let call = method_calls
+                .iter()
+                .find(|c| c.caller == caller && c.method_name == "String")
+                .unwrap();
+            println!("  {call:?}");
+            assert_eq!(call.receiver.as_deref(), Some("u"));
+            assert!(!call.is_static);
+            assert_eq!(call.range.start_line, line_of(call_text));
+        }
+
+        // ... not functions named `f` or `describe`
+        let calls = parser.find_calls(&code);
         assert!(
-            !generic_functions.is_empty(),
-            "Should find generic functions"
+            !calls
+                .iter()
+                .any(|(_, callee, _)| matches!(*callee, "f" | "describe"))
         );
 
-        // Verify specific generic symbols
+        // `u` is a `*User` in both, so the calls resolve to `User.String`
+        let types = parser.find_variable_types(&code);
+        for caller in ["DeferredDescription", "LazyDescription"] {
+            let line = line_of(&format!("func {caller}("));
+            assert!(types.iter().any(|(name, typ, range)| *name == "u"
+                && *typ == "User"
+                && range.start_line == line));
+        }
+    }
+
+    #[test]
+    fn test_go_channel_results_of_interface_methods() {
+        println!("\n=== Go Channel Results Of Interface Methods Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/interfaces.go").unwrap();
+        let rows = |needle: &str| -> Vec<u32> {
+            code.lines()
+                .enumerate()
+                .filter(|(_, l)| l.contains(needle))
+                .map(|(i, _)| i as u32)
+                .collect()
+        };
+
+        // `Subscribe(topic string) <-chan Message` on MessageBroker: ranging
+        // over the result, directly or through a variable, binds a Message
+        let types = parser.find_variable_types(&code);
+        for row in rows("for msg := range") {
+            assert!(
+                types.iter().any(|(name, typ, range)| *name == "msg"
+                    && *typ == "Message"
+                    && range.start_line == row),
+                "msg at row {row}: {types:?}"
+            );
+        }
         assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "Identity"
-                && s.signature
-                    .as_ref()
-                    .is_some_and(|sig| sig.contains("[T any]"))),
-            "Should find Identity generic function"
+            types
+                .iter()
+                .any(|(name, typ, _)| *name == "messages" && *typ == "<-chan Message")
         );
 
+        // ... so calls and field accesses on it resolve to Message members
+        let method_calls = parser.find_method_calls(&code);
+        assert!(method_calls.iter().any(|c| c.caller == "DrainTopic"
+            && c.receiver.as_deref() == Some("msg")
+            && c.method_name == "Summary"));
+        let accesses = parser.find_field_accesses_in(&code);
         assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "Container"
-                && s.signature
-                    .as_ref()
-                    .is_some_and(|sig| sig.contains("[T any]"))),
-            "Should find Container generic struct"
+            accesses
+                .iter()
+                .any(|(context, field, _)| *context == "ForwardAll" && field == "Message.Content")
         );
+    }
 
-        assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "Processor"
-                && s.signature
-                    .as_ref()
-                    .is_some_and(|sig| sig.contains("[T any]"))),
-            "Should find Processor generic interface"
+    #[test]
+    fn test_go_parenthesized_selector_bases() {
+        println!("\n=== Go Parenthesized Selector Bases Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/parenthesized.go").unwrap();
+
+        let method_calls = parser.find_method_calls(&code);
+        let receiver = |caller: &str| {
+            method_calls
+                .iter()
+                .find(|c| c.caller == caller)
+                .map(|c| (c.receiver.clone(), c.method_name.as_str(), c.is_static))
+                .unwrap_or_else(|| panic!("no call in {caller}"))
+        };
+        // Converted, constructed and literal values name their type ...
+        let config = Some("Config".to_string());
+        assert_eq!(receiver("portOf"), (config.clone(), "Port", true));
+        assert_eq!(
+            receiver("defaultAddress"),
+            (config.clone(), "Address", true)
+        );
+        assert_eq!(receiver("literalPort"), (config.clone(), "Port", true));
+        assert_eq!(receiver("convertedAddress"), (config, "Address", true));
+        // ... a dereferenced variable is typed by its binding
+        assert_eq!(
+            receiver("addressOf"),
+            (Some("p".to_string()), "Address", false)
         );
 
-        println!("\n✅ Go generic type extraction test passed");
+        let accesses = parser.find_field_accesses_in(&code);
+        assert!(
+            accesses
+                .iter()
+                .any(|(context, field, _)| *context == "hostOf" && field == "Config.Host")
+        );
     }
 
     #[test]
-    fn test_go_interface_implementation_behavior() {
-        println!("\n=== Go Interface Implementation Behavior Test ===\n");
+    fn test_go_local_shadowing_imported_package() {
+        println!("\n=== Go Local Shadowing Imported Package Test ===\n");
 
         let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/scoping.go").unwrap();
+        let row = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-        let code = r#"
-package main
+        // Before `config := Config{...}` the selector is the package's ...
+        let uses = parser.find_uses(&code);
+        assert!(
+            uses.iter()
+                .any(|(context, used, _)| *context == "LoadSettings" && *used == "Production")
+        );
+        // ... after it, the local variable's
+        assert!(
+            !uses
+                .iter()
+                .any(|(context, used, _)| *context == "LoadSettings" && *used == "Port")
+        );
+        let types = parser.find_variable_types(&code);
+        assert!(types.iter().any(|(name, typ, range)| *name == "config"
+            && *typ == "Config"
+            && range.start_line == row("config := Config{Name: path")));
+        let accesses = parser.find_field_accesses_in(&code);
+        assert!(
+            accesses
+                .iter()
+                .any(|(context, field, _)| *context == "LoadSettings" && field == "Config.Port")
+        );
+    }
 
-import (
-    "fmt"
-    "io"
-)
+    #[test]
+    fn test_go_nested_shadowing_resolves_innermost() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/scoping.go").unwrap();
+        let tree = parser.parser.parse(&code, None).unwrap();
+        let function = tree
+            .root_node()
+            .named_children(&mut tree.root_node().walk())
+            .find(|n| {
+                n.child_by_field_name("name")
+                    .is_some_and(|name| &code[name.byte_range()] == "DemonstrateScoping")
+            })
+            .unwrap();
+        let declarations = local_declarations(function, &code);
+
+        // 1-based line of each use, and of the declaration it resolves to
+        let mut resolved = Vec::new();
+        let mut stack = vec![function];
+        while let Some(node) = stack.pop() {
+            stack.extend(node.named_children(&mut node.walk()));
+            let name = &code[node.byte_range()];
+            if !matches!(name, "globalVar" | "packageCount")
+                || declarations.iter().any(|d| d.name == node)
+            {
+                continue;
+            }
+            let declaration = GoParser::innermost_declaration(&declarations, node, &code)
+                .map(|d| d.name.start_position().row + 1);
+            resolved.push((node.start_position().row + 1, name, declaration));
+        }
+        resolved.sort();
+        assert_eq!(
+            resolved,
+            vec![
+                (207, "globalVar", Some(206)),
+                (213, "globalVar", Some(212)),
+                (219, "globalVar", Some(218)),
+                (222, "packageCount", None),
+                (226, "globalVar", Some(212)),
+                (230, "globalVar", Some(206)),
+                (233, "packageCount", None),
+                (234, "packageCount", None),
+            ]
+        );
 
-// Interface definitions
-type Writer interface {
-    Write([]byte) (int, error)
-}
+        // The package variable is only used where no local shadows it
+        let uses = parser.find_uses(&code);
+        let users = |used: &str| -> Vec<&str> {
+            let mut users: Vec<_> = uses
+                .iter()
+                .filter(|(_, u, _)| *u == used)
+                .map(|(context, _, _)| *context)
+                .collect();
+            users.sort();
+            users.dedup();
+            users
+        };
+        assert_eq!(users("globalVar"), vec!["Initialize", "main"]);
+        assert!(users("packageCount").contains(&"DemonstrateScoping"));
+        // `debug := false` shadows the constant in ProcessData only
+        assert_eq!(users("debug"), vec!["UpdatePort"]);
+    }
 
-type Reader interface {
-    Read([]byte) (int, error)
+    #[test]
+    fn test_go_shadowing_is_lexical() {
+        let mut parser = GoParser::new().unwrap();
+        let code = r#"package main
+
+var level = 1
+
+func sibling(ok bool) int {
+	total := level
+	if ok {
+		level := 2
+		total += level
+	}
+	for level := range 3 {
+		total += level
+	}
+	return total + level
 }
 
-// Struct that implements interfaces through method signatures
-type FileProcessor struct {
-    filename string
-    data     []byte
+func before() int {
+	n := level
+	level := n + level
+	return level
 }
+"#;
+        let uses = parser.find_uses(code);
+        let lines: Vec<_> = uses
+            .iter()
+            .filter(|(_, used, _)| *used == "level")
+            .map(|(context, _, range)| (*context, range.start_line))
+            .collect();
+        // A shadow in a nested block or loop leaves the later uses to the
+        // package variable, and `level := n + level` reads it before the
+        // local exists; each function records a name once
+        assert_eq!(lines, vec![("sibling", 13), ("before", 18)]);
+    }
 
-// These methods make FileProcessor implement Writer interface
-func (f *FileProcessor) Write(data []byte) (int, error) {
-    f.data = append(f.data, data...)
-    return len(data), nil
-}
+    #[test]
+    fn test_go_closure_captures() {
+        let mut parser = GoParser::new().unwrap();
+        let summary = |captures: Vec<(&str, String, &str, Range, bool)>, function: &str| {
+            captures
+                .into_iter()
+                .filter(|(f, _, _, _, _)| *f == function)
+                .map(|(_, closure, variable, range, mutated)| {
+                    format!(
+                        "{closure} {variable}@{} {}",
+                        range.start_line,
+                        if mutated { "mutated" } else { "read" }
+                    )
+                })
+                .collect::<Vec<_>>()
+        };
 
-// This method makes FileProcessor implement Reader interface  
-func (f *FileProcessor) Read(data []byte) (int, error) {
-    copy(data, f.data)
-    return len(f.data), nil
-}
+        // The counter increments `count`; `start` is only read before it
+        let code = std::fs::read_to_string("tests/fixtures/go/scoping.go").unwrap();
+        assert_eq!(
+            summary(parser.find_captures_in(&code), "CreateCounter"),
+            vec!["CreateCounter.func1 count@285 mutated"]
+        );
 
-// Interface embedding (composition, not inheritance)
-type ReadWriter interface {
-    Reader
-    Writer
-}
+        // The inner literal captures from both the function and the outer
+        // literal, and the outer one captures what its nested literal uses
+        let code = std::fs::read_to_string("tests/fixtures/go/complex.go").unwrap();
+        assert_eq!(
+            summary(parser.find_captures_in(&code), "CreateRetryFunc"),
+            vec![
+                "CreateRetryFunc.func1 maxAttempts@442 read",
+                "CreateRetryFunc.func1 backoff@442 read",
+                "CreateRetryFunc.func1.1 maxAttempts@442 read",
+                "CreateRetryFunc.func1.1 backoff@442 read",
+                "CreateRetryFunc.func1.1 original@443 read",
+            ]
+        );
 
-// Type embedding (composition, not inheritance)
-type ExtendedProcessor struct {
-    FileProcessor
-    metadata map[string]string
+        let code = r#"package main
+
+func (s *Server) watch(items []int) (total int) {
+	var mu Mutex
+	done := false
+	go func() {
+		for _, item := range items {
+			total += item
+		}
+		lock(&mu)
+		done = true
+		_ = Options{s: 1}
+	}()
+	defer func(done bool) {
+		_ = done
+		_ = s
+	}(done)
+	return total
 }
 "#;
+        assert_eq!(
+            summary(parser.find_captures_in(code), "watch"),
+            vec![
+                "Server.watch.func1 items@2 read",
+                "Server.watch.func1 total@2 mutated",
+                "Server.watch.func1 mu@3 mutated",
+                "Server.watch.func1 done@4 mutated",
+                "Server.watch.func2 s@2 read",
+            ]
+        );
+    }
 
-        println!("Test code:\n{code}");
+    #[test]
+    fn test_go_type_switch_case_bindings() {
+        let mut parser = GoParser::new().unwrap();
+        let mut counter = SymbolCounter::new();
+        let file_id = FileId::new(1).unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/scoping.go").unwrap();
+        let row = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
 
-        // Go uses implicit interface implementation
-        // No explicit "implements" declarations exist
-        let implementations = parser.find_implementations(code);
-        println!("\nImplementations found ({}):", implementations.len());
+        // `switch v := data.(type)` declares one `v` per case
+        let symbols = parser.parse(&code, file_id, &mut counter);
+        let cases: Vec<_> = symbols
+            .iter()
+            .filter(|s| s.kind == SymbolKind::Variable && s.name.as_str() == "v")
+            .collect();
+        let signatures: Vec<_> = cases
+            .iter()
+            .map(|s| (s.signature.as_deref(), s.range.start_line))
+            .collect();
         assert_eq!(
-            implementations.len(),
-            0,
-            "Go should have no explicit implementations"
+            signatures,
+            vec![
+                (Some("v := data.(string)"), row("case string:")),
+                (Some("v := data.(int)"), row("case int:")),
+            ]
+        );
+        assert_ne!(cases[0].id, cases[1].id);
+        assert!(cases.iter().all(|s| matches!(
+            &s.scope_context,
+            Some(crate::symbol::ScopeContext::Local { parent_name: Some(parent), .. })
+                if parent.as_str() == "ProcessData"
+        )));
+
+        // `len(v)` and `v * 2` see the binding of their own case
+        let bindings = parser.find_variable_bindings(&code);
+        let type_at = |line: u32| {
+            bindings
+                .iter()
+                .rfind(|b| b.name == "v" && b.range.start_line <= line)
+                .and_then(|b| b.narrowed_type())
+        };
+        assert_eq!(type_at(row("length := len(v)")), Some("string"));
+        assert_eq!(type_at(row("doubled := v * 2")), Some("int"));
+
+        // Several types, nil and default keep the switched value's type
+        let code = r#"package main
+
+func describe(x any) {
+	switch s := x.(type) {
+	case *Stringer:
+		s.String()
+	case int, uint:
+	case nil:
+	default:
+	}
+}
+"#;
+        let symbols = parser.parse(code, file_id, &mut counter);
+        let signatures: Vec<_> = symbols
+            .iter()
+            .filter(|s| s.name.as_str() == "s")
+            .filter_map(|s| s.signature.as_deref())
+            .collect();
+        assert_eq!(
+            signatures,
+            vec![
+                "s := x.(*Stringer)",
+                "s := x.(type)",
+                "s := x.(type)",
+                "s := x.(type)",
+            ]
+        );
+        let types: Vec<_> = parser
+            .find_variable_types(code)
+            .into_iter()
+            .filter(|(name, _, _)| *name == "s")
+            .map(|(_, typ, _)| typ)
+            .collect();
+        assert_eq!(types, vec!["Stringer"]);
+    }
+
+    #[test]
+    fn test_go_aliased_stdlib_constructor_bindings() {
+        println!("\n=== Go Aliased Stdlib Constructor Test ===\n");
+
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("examples/go/import_resolution_test.go").unwrap();
+
+        // `set "container/list"`: `list := set.New()` holds a `*list.List`
+        let types = parser.find_variable_types(&code);
+        let list_types: Vec<_> = types
+            .iter()
+            .filter(|(name, _, _)| *name == "list")
+            .map(|(_, typ, _)| *typ)
+            .collect();
+        println!("  list: {list_types:?}");
+        assert_eq!(
+            list_types,
+            vec!["container/list.List", "container/list.List"]
         );
 
-        // Go has no class inheritance
-        // Interface/struct embedding is composition, not inheritance
-        let extends = parser.find_extends(code);
-        println!("Extends relationships found ({}):", extends.len());
-        assert_eq!(extends.len(), 0, "Go should have no extends relationships");
+        // `set.New()` keeps the alias as receiver for package resolution, and
+        // `list.PushBack(1)` is a call on the variable
+        let method_calls = parser.find_method_calls(&code);
+        let call = |receiver: &str, method: &str| {
+            method_calls.iter().any(|c| {
+                c.caller == "main"
+                    && c.receiver.as_deref() == Some(receiver)
+                    && c.method_name == method
+            })
+        };
+        assert!(call("set", "New"));
+        assert!(call("list", "PushBack"));
 
-        println!("\n✅ Go interface implementation behavior test passed");
-        println!("✅ Verified that Go returns empty results for explicit relationships");
-        println!("✅ Go's implicit interface implementation requires semantic analysis");
+        // Unknown constructors and packages give no type
+        let code = "package main\n\nimport \"container/list\"\n\nfunc f() {\n\ta := list.Other()\n\tb := missing.New()\n\ta.X()\n\tb.Y()\n}\n";
+        assert!(parser.find_variable_types(code).is_empty());
     }
 
     #[test]
-    fn test_go_complex_import_patterns() {
-        println!("\n=== Go Complex Import Patterns Test ===\n");
-
+    fn test_go_type_assertion_bindings() {
         let mut parser = GoParser::new().unwrap();
-        let file_id = FileId::new(1).unwrap();
-
         let code = r#"
 package main
 
-import (
-    "fmt"
-    "net/http"
-    "path/filepath"
-    
-    // Aliased import
-    httputil "net/http/httputil"
-    
-    // Dot import
-    . "math"
-    
-    // Blank import
-    _ "net/http/pprof"
-    
-    // External module
-    "github.com/gorilla/mux"
-    "github.com/user/project/internal/config"
-)
+func run(x interface{}) {
+    fp := x.(*FileProcessor)
+    fp.Process()
 
-func main() {
-    fmt.Println("Hello, World!")
-}
-"#;
+    if sp, ok := x.(StreamProcessor); ok {
+        sp.Process()
+    }
 
-        let imports = parser.find_imports(code, file_id);
+    var p DataProcessor
+    p = x.(*FileProcessor)
+    p.Process()
 
-        println!("Found {} imports in Go complex patterns", imports.len());
-        for import in &imports {
-            println!(
-                "  - {} -> {:?} (type_only: {})",
-                import.path, import.alias, import.is_type_only
-            );
-        }
+    r := x.(io.Reader)
+    r.Read(nil)
+}
+"#;
 
-        // Should have 8 imports: standard lib + external modules
-        assert!(imports.len() >= 6, "Should have at least 6 imports");
+        let bindings = parser.find_variable_bindings(code);
+        let binding = |name: &str| {
+            bindings
+                .iter()
+                .rfind(|b| b.name == name)
+                .map(|b| (b.declared_type, b.concrete_type))
+        };
 
-        // Check for standard library imports
-        assert!(
-            imports.iter().any(|i| i.path == "fmt"),
-            "Should find fmt import"
+        // Both assertion forms bind the target to the asserted type
+        assert_eq!(
+            binding("fp"),
+            Some((Some("FileProcessor"), Some("FileProcessor")))
         );
-        assert!(
-            imports.iter().any(|i| i.path == "net/http"),
-            "Should find net/http import"
+        assert_eq!(
+            binding("sp"),
+            Some((Some("StreamProcessor"), Some("StreamProcessor")))
         );
-
-        // Check for aliased import
-        assert!(
-            imports
+        assert_eq!(binding("ok"), None);
+        // Assigning to a declared interface variable narrows it
+        assert_eq!(binding("p"), Some((None, Some("FileProcessor"))));
+        assert_eq!(
+            bindings
                 .iter()
-                .any(|i| i.path == "net/http/httputil" && i.alias == Some("httputil".to_string())),
-            "Should find aliased httputil import"
-        );
-
-        // Check for dot import
-        assert!(
-            imports.iter().any(|i| i.path == "math"),
-            "Should find math import"
-        );
-
-        // Check for blank import
-        assert!(
-            imports.iter().any(|i| i.path == "net/http/pprof"),
-            "Should find pprof import"
+                .rfind(|b| b.name == "p")
+                .and_then(|b| b.narrowed_type()),
+            Some("FileProcessor")
         );
+        // Imported interfaces have no local type to bind
+        assert_eq!(binding("r"), None);
 
-        // Check for external module
+        let types = parser.find_variable_types(code);
         assert!(
-            imports.iter().any(|i| i.path == "github.com/gorilla/mux"),
-            "Should find external module import"
+            types
+                .iter()
+                .any(|(name, typ, _)| *name == "fp" && *typ == "FileProcessor")
         );
-
-        println!("✅ Go complex patterns handled correctly");
     }
 
     #[test]
-    fn test_go_import_path_formats() {
-        println!("\n=== Go Import Path Formats Test ===\n");
+    fn test_go_generic_instantiation_bindings() {
+        println!("\n=== Go Generic Instantiation Bindings Test ===\n");
 
         let mut parser = GoParser::new().unwrap();
-        let file_id = FileId::new(1).unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+        let line_of = |needle: &str| code.lines().position(|l| l.contains(needle)).unwrap() as u32;
+
+        let bindings = parser.find_variable_bindings(&code);
+        let binding_at = |var: &str, line: u32| {
+            bindings
+                .iter()
+                .find(|b| b.name == var && b.range.start_line == line)
+                .copied()
+        };
+
+        // Composite literal instantiation
+        let stack = binding_at("stringStack", line_of("stringStack := &Stack[string]{}")).unwrap();
+        println!("  stringStack: {stack:?}");
+        assert_eq!(stack.narrowed_type(), Some("Stack"));
+        assert_eq!(stack.type_arguments, Some("string"));
+
+        // Constructor instantiation passing its type parameters through
+        let map = binding_at("intMap", line_of("intMap := NewMap[int, string]()")).unwrap();
+        println!("  intMap: {map:?}");
+        assert_eq!(map.narrowed_type(), Some("Map"));
+        assert_eq!(map.type_argument_list(), vec!["int", "string"]);
+
+        // `stringStack.Push("hello")` resolves to `(*Stack[T]).Push` with T=string
+        let calls = parser.find_method_calls(&code);
+        assert!(calls.iter().any(|c| c.caller == "ExampleUsage"
+            && c.method_name == "Push"
+            && c.receiver.as_deref() == Some("stringStack")));
 
+        assert_eq!(
+            stack.receiver_type_bindings("func (s *Stack[T]) Push(item T)"),
+            vec![("T", "string")]
+        );
+        assert_eq!(
+            map.receiver_type_bindings("func (m *Map[K, V]) Set(key K, value V)"),
+            vec![("K", "int"), ("V", "string")]
+        );
+
+        // Results of methods on the instantiation take the bound types
         let code = r#"
 package main
 
-import (
-    // Standard library imports
-    "fmt"
-    "strings"
-    "net/http"
-    "encoding/json"
-    
-    // External module imports
-    "github.com/gin-gonic/gin"
-    "github.com/user/repo/pkg/database"
-    "go.uber.org/zap"
-    
-    // Relative imports (rare in Go but valid)
-    "./internal/config"
-    "../shared/utils"
-)
+type Stack[T any] struct{ items []T }
+
+func (s *Stack[T]) Pop() (T, bool) { var zero T; return zero, false }
 
 func main() {
-    fmt.Println("Hello, World!")
+    stringStack := &Stack[*Document]{}
+    top, ok := stringStack.Pop()
 }
 "#;
+        let types = parser.find_variable_types(code);
+        let type_of = |var: &str| {
+            types
+                .iter()
+                .find(|(name, _, _)| *name == var)
+                .map(|(_, typ, _)| *typ)
+        };
+        assert_eq!(type_of("top"), Some("Document"));
+        assert_eq!(type_of("ok"), Some("bool"));
 
-        let imports = parser.find_imports(code, file_id);
+        // Non-generic receivers bind nothing
+        assert!(receiver_type_parameters("func (u *User) Verify() bool").is_empty());
+        assert!(
+            stack
+                .receiver_type_bindings("func (m *Map[K, V]) Set(key K, value V)")
+                .is_empty()
+        );
+    }
 
-        println!("Go path formats found:");
-        for import in &imports {
-            println!("  - {}", import.path);
-        }
+    #[test]
+    fn test_go_generic_tuple_result_bindings() {
+        println!("\n=== Go Generic Tuple Result Bindings Test ===\n");
 
-        // Check standard library imports
-        assert!(imports.iter().any(|i| i.path == "fmt"));
-        assert!(imports.iter().any(|i| i.path == "strings"));
-        assert!(imports.iter().any(|i| i.path == "net/http"));
-        assert!(imports.iter().any(|i| i.path == "encoding/json"));
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+        let line = code
+            .lines()
+            .position(|l| l.contains("first, second := Pair(1, \"x\")"))
+            .unwrap() as u32;
+
+        let types = parser.find_variable_types(&code);
+        let type_of = |var: &str| {
+            types
+                .iter()
+                .find(|(name, _, range)| *name == var && range.start_line == line)
+                .map(|(_, typ, _)| *typ)
+        };
+        println!(
+            "  first: {:?}, second: {:?}",
+            type_of("first"),
+            type_of("second")
+        );
+        // `func Pair[T, U any](first T, second U) (T, U)` with T=int, U=string
+        assert_eq!(type_of("first"), Some("int"));
+        assert_eq!(type_of("second"), Some("string"));
 
-        // Check external module imports
-        assert!(imports.iter().any(|i| i.path == "github.com/gin-gonic/gin"));
-        assert!(imports.iter().any(|i| i.path == "go.uber.org/zap"));
+        let code = r#"
+package main
 
-        // Check relative imports
-        assert!(imports.iter().any(|i| i.path == "./internal/config"));
-        assert!(imports.iter().any(|i| i.path == "../shared/utils"));
+func Pair[T, U any](first T, second U) (T, U) { return first, second }
+func Identity[T any](value T) T { return value }
+func Last[T any](values ...T) T { return values[len(values)-1] }
 
-        println!("✅ Various Go path formats extracted correctly");
+func main() {
+    u := &User{}
+    a, b := Pair[float64, *User](1, u)
+    c := Identity(u)
+    d := Last("x", "y")
+    var users []*User
+    e := Last(users...)
+}
+"#;
+        let bindings = parser.find_variable_bindings(code);
+        let type_of = |var: &str| {
+            bindings
+                .iter()
+                .find(|b| b.name == var)
+                .and_then(|b| b.narrowed_type())
+        };
+        // Explicit type arguments, inference from a variable and variadics
+        assert_eq!(type_of("a"), Some("float64"));
+        assert_eq!(type_of("b"), Some("User"));
+        assert_eq!(type_of("c"), Some("User"));
+        assert_eq!(type_of("d"), Some("string"));
+        // A spread slice binds `T` to its element type
+        assert_eq!(type_of("e"), Some("User"));
     }
 
     #[test]
-    fn test_go_visibility_variations() {
-        println!("\n=== Go Visibility Variations Test ===\n");
-
+    fn test_go_type_arguments_of_generic_instantiations() {
         let mut parser = GoParser::new().unwrap();
-        let file_id = FileId::new(1).unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
+
+        let uses = parser.find_uses(&code);
+        let has_use = |from: &str, to: &str| uses.iter().any(|(f, t, _)| *f == from && *t == to);
+
+        // `NewCache[string, *Document](10)`
+        assert!(has_use("CacheUsage", "Document"));
+        // `documents *Cache[string, *Document]` and `history []*Stack[Document]`
+        assert!(has_use("DocumentStore", "Document"));
+        assert!(uses.iter().any(|(_, t, _)| *t == "Cache"));
+        assert!(uses.iter().any(|(_, t, _)| *t == "Stack"));
+        // Type parameters passed through are not types
+        assert!(!has_use("NewCache", "K"));
+        assert!(!has_use("NewCache", "V"));
+        assert!(!has_use("Cache", "K"));
 
         let code = r#"
 package main
 
-import "fmt"
-
-// Exported (public) symbols - start with uppercase
-const PublicConstant = "public"
-var PublicVariable = 42
+func Lookup(index Cache[string, List[User]]) {}
+"#;
+        let uses = parser.find_uses(code);
+        let has_use = |from: &str, to: &str| uses.iter().any(|(f, t, _)| *f == from && *t == to);
+        assert!(has_use("Lookup", "Cache"));
+        assert!(has_use("Lookup", "List"));
+        assert!(has_use("Lookup", "User"));
+    }
 
-type PublicStruct struct {
-    PublicField   string
-    privateField  int
-}
+    #[test]
+    fn test_go_generic_method_tuple_result_bindings() {
+        let mut parser = GoParser::new().unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/generics.go").unwrap();
 
-func PublicFunction() string {
-    return "public function"
-}
+        // `func (c *Cache[K, V]) Get(key K) (V, bool)` on a
+        // `Cache[string, *Document]` binds `doc` to `Document`
+        let types = parser.find_variable_types(&code);
+        let type_of = |var: &str| {
+            types
+                .iter()
+                .find(|(name, _, _)| *name == var)
+                .map(|(_, typ, _)| *typ)
+        };
+        assert_eq!(type_of("documents"), Some("Cache"));
+        assert_eq!(type_of("doc"), Some("Document"));
+        assert_eq!(type_of("ok"), Some("bool"));
 
-func (p *PublicStruct) PublicMethod() {
-    fmt.Println("public method")
-}
+        let method_calls = parser.find_method_calls(&code);
+        assert!(method_calls.iter().any(|c| c.caller == "CacheUsage"
+            && c.receiver.as_deref() == Some("doc")
+            && c.method_name == "Title"));
 
-// Unexported (private) symbols - start with lowercase
-const privateConstant = "private"
-var privateVariable = 24
+        // Single results and receivers without type arguments
+        let code = r#"
+package main
 
-type privateStruct struct {
-    field string
-}
+type Box[T any] struct{ value T }
 
-func privateFunction() string {
-    return "private function"
-}
+func (b *Box[T]) Value() T { return b.value }
+func (b *Box[T]) Pair() (T, error) { return b.value, nil }
 
-func (p *privateStruct) privateMethod() {
-    fmt.Println("private method")
+func main() {
+    box := &Box[*User]{}
+    u := box.Value()
+    p, err := box.Pair()
+    var plain Box
+    q := plain.Value()
 }
 "#;
+        let bindings = parser.find_variable_bindings(code);
+        let type_of = |var: &str| {
+            bindings
+                .iter()
+                .find(|b| b.name == var)
+                .and_then(|b| b.narrowed_type())
+        };
+        assert_eq!(type_of("u"), Some("User"));
+        assert_eq!(type_of("p"), Some("User"));
+        assert_eq!(type_of("err"), Some("error"));
+        assert_eq!(type_of("q"), None);
+    }
 
-        let mut symbol_counter = SymbolCounter::new();
-        let symbols = parser.parse(code, file_id, &mut symbol_counter);
+    #[test]
+    fn test_go_labels_indexed_and_jumps_found() {
+        let mut parser = GoParser::new().unwrap();
+        let code = r#"
+package main
 
-        println!("Go visibility symbols found:");
-        for symbol in &symbols {
-            let visibility_str = match symbol.visibility {
-                Visibility::Public => "public",
-                _ => "private",
-            };
-            println!(
-                "  - {} ({:?}) -> {}",
-                symbol.name, symbol.kind, visibility_str
-            );
+func search(grid [][]int, target int) bool {
+outer:
+    for _, row := range grid {
+        for _, cell := range row {
+            if cell == target {
+                break outer
+            }
+            if cell < 0 {
+                continue outer
+            }
         }
+    }
+    return false
+}
 
-        // Check for exported symbols
-        let exported_symbols: Vec<_> = symbols
-            .iter()
-            .filter(|s| matches!(s.visibility, Visibility::Public))
-            .collect();
-
-        let unexported_symbols: Vec<_> = symbols
+func (w *Worker) run() {
+retry:
+    if w.step() {
+        goto retry
+    }
+}
+"#;
+        let mut counter = SymbolCounter::new();
+        let symbols = parser.parse(code, FileId::new(1).unwrap(), &mut counter);
+        let labels: Vec<_> = symbols
             .iter()
-            .filter(|s| !matches!(s.visibility, Visibility::Public))
+            .filter(|s| s.kind == SymbolKind::Label)
+            .map(|s| {
+                let parent = match &s.scope_context {
+                    Some(crate::symbol::ScopeContext::Local { parent_name, .. }) => {
+                        parent_name.as_deref().map(str::to_string)
+                    }
+                    _ => None,
+                };
+                (
+                    s.name.to_string(),
+                    parent,
+                    s.range.start_line,
+                    s.range.end_line,
+                )
+            })
             .collect();
-
-        assert!(!exported_symbols.is_empty(), "Should find exported symbols");
-        assert!(
-            !unexported_symbols.is_empty(),
-            "Should find unexported symbols"
-        );
-
-        // Check specific exported symbols
-        assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "PublicFunction"
-                && matches!(s.visibility, Visibility::Public))
-        );
-        assert!(symbols.iter().any(
-            |s| s.name.as_ref() == "PublicStruct" && matches!(s.visibility, Visibility::Public)
-        ));
-
-        // Check specific unexported symbols
-        assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "privateFunction"
-                && !matches!(s.visibility, Visibility::Public))
+        assert_eq!(
+            labels,
+            vec![
+                ("outer".to_string(), Some("search".to_string()), 4, 14),
+                ("retry".to_string(), Some("run".to_string()), 19, 22),
+            ]
         );
-        assert!(
-            symbols.iter().any(|s| s.name.as_ref() == "privateStruct"
-                && !matches!(s.visibility, Visibility::Public))
+        let outer = symbols.iter().find(|s| &*s.name == "outer").unwrap();
+        assert_eq!(outer.signature.as_deref(), Some("outer:"));
+        assert_eq!(outer.visibility, Visibility::Private);
+        // The loop under the label still declares its variables
+        assert!(symbols.iter().any(|s| &*s.name == "cell"));
+
+        let jumps: Vec<_> = parser
+            .find_label_jumps_in(code)
+            .into_iter()
+            .map(|(function, label, range)| (function, label, range.start_line))
+            .collect();
+        assert_eq!(
+            jumps,
+            vec![
+                ("search", "outer", 8),
+                ("search", "outer", 11),
+                ("run", "retry", 21),
+            ]
         );
-
-        println!("✅ Go visibility variations handled correctly");
     }
 }
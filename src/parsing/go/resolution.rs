@@ -7,11 +7,12 @@
 //! - Imported package symbols
 //! - Interface implementation tracking (implicit in Go)
 
-use crate::parsing::resolution::ImportBinding;
+use crate::parsing::resolution::{ImportBinding, ImportOrigin};
 use crate::parsing::{InheritanceResolver, ResolutionScope, ScopeLevel, ScopeType};
+use crate::project_config::StdlibResolution;
 use crate::storage::DocumentIndex;
 use crate::{FileId, SymbolId};
-use std::collections::HashMap;
+use std::collections::{HashMap, HashSet};
 
 /// Information extracted from go.mod file
 ///
@@ -32,6 +33,85 @@ pub struct GoModInfo {
     pub replacements: HashMap<String, String>,
 }
 
+impl GoModInfo {
+    /// Read and parse a go.mod file
+    pub fn read(path: &std::path::Path) -> Option<Self> {
+        std::fs::read_to_string(path)
+            .ok()
+            .map(|content| Self::parse(&content))
+    }
+
+    /// Parse the content of a go.mod file
+    ///
+    /// Extract module name, Go version, dependencies, and replace directives.
+    pub fn parse(content: &str) -> Self {
+        let mut info = GoModInfo::default();
+        let mut in_require_block = false;
+
+        for line in content.lines() {
+            let line = line.trim();
+
+            // Skip empty lines and comments
+            if line.is_empty() || line.starts_with("//") {
+                continue;
+            }
+
+            // Parse module directive
+            if line.starts_with("module ") {
+                if let Some(module_name) = line.strip_prefix("module ") {
+                    info.module_name = Some(module_name.trim().to_string());
+                }
+            }
+            // Parse go directive
+            else if line.starts_with("go ") {
+                if let Some(go_version) = line.strip_prefix("go ") {
+                    info.go_version = Some(go_version.trim().to_string());
+                }
+            }
+            // Parse replace directives
+            else if line.starts_with("replace ") {
+                if let Some(replace_part) = line.strip_prefix("replace ") {
+                    if let Some((from, to)) = replace_part.split_once(" => ") {
+                        info.replacements
+                            .insert(from.trim().to_string(), to.trim().to_string());
+                    }
+                }
+            }
+            // Parse require directive - handle both inline and block forms
+            else if line.starts_with("require ") {
+                if line.ends_with("(") {
+                    // Start of require block
+                    in_require_block = true;
+                } else {
+                    // Inline require
+                    if let Some(require_part) = line.strip_prefix("require ") {
+                        let parts: Vec<&str> = require_part.split_whitespace().collect();
+                        if parts.len() >= 2 {
+                            info.dependencies
+                                .insert(parts[0].to_string(), parts[1].to_string());
+                        }
+                    }
+                }
+            }
+            // Handle require block content
+            else if in_require_block {
+                if line == ")" {
+                    in_require_block = false;
+                } else {
+                    // Parse dependency line in block
+                    let parts: Vec<&str> = line.split_whitespace().collect();
+                    if parts.len() >= 2 {
+                        info.dependencies
+                            .insert(parts[0].to_string(), parts[1].to_string());
+                    }
+                }
+            }
+        }
+
+        info
+    }
+}
+
 /// Type information for Go type system resolution
 #[derive(Debug, Clone)]
 pub struct TypeInfo {
@@ -333,6 +413,10 @@ pub struct GoResolutionContext {
     /// Local scope (function parameters, local variables, block variables)
     local_scope: HashMap<String, SymbolId>,
 
+    /// Names of the locals declared in each function or method, keyed by
+    /// its symbol so same-named methods of different types stay apart
+    function_locals: HashMap<SymbolId, HashSet<String>>,
+
     /// Package-level symbols (functions, types, variables, constants)
     package_symbols: HashMap<String, SymbolId>,
 
@@ -350,6 +434,23 @@ pub struct GoResolutionContext {
 
     /// Binding info for imports keyed by visible name
     import_bindings: HashMap<String, ImportBinding>,
+
+    /// Names exported by more than one dot-imported package, with the import
+    /// paths providing them
+    ambiguous_dot_imports: HashMap<String, Vec<String>>,
+
+    /// Where each relative import (`./pkg`, `../pkg`) leads
+    relative_imports: HashMap<String, RelativeImport>,
+
+    /// Package directory of each import path leading to an indexed package
+    imported_packages: HashMap<String, String>,
+
+    /// `Outer.Name` selectors that embedded types promote more than once at
+    /// the same depth, with the embedded types providing them
+    ambiguous_selectors: HashMap<String, Vec<String>>,
+
+    /// Whether results of well-known standard library constructors resolve
+    stdlib: StdlibResolution,
 }
 
 impl GoResolutionContext {
@@ -360,20 +461,223 @@ impl GoResolutionContext {
         Self {
             file_id,
             local_scope: HashMap::new(),
+            function_locals: HashMap::new(),
             package_symbols: HashMap::new(),
             imported_symbols: HashMap::new(),
             scope_stack: Vec::new(),
             imports: Vec::new(),
             type_registry: TypeRegistry::new(),
             import_bindings: HashMap::new(),
+            ambiguous_dot_imports: HashMap::new(),
+            relative_imports: HashMap::new(),
+            imported_packages: HashMap::new(),
+            ambiguous_selectors: HashMap::new(),
+            stdlib: StdlibResolution::default(),
         }
     }
 
+    /// Set the standard library resolution mode, `stdlib` in `codanna.toml`
+    pub fn set_stdlib_resolution(&mut self, stdlib: StdlibResolution) {
+        self.stdlib = stdlib;
+    }
+
+    /// Standard library resolution mode of the context
+    pub fn stdlib_resolution(&self) -> StdlibResolution {
+        self.stdlib
+    }
+
     /// Add an import (import statement)
     pub fn add_import(&mut self, path: String, alias: Option<String>) {
         self.imports.push((path, alias));
     }
 
+    /// Whether `name` refers to an imported package in this file
+    ///
+    /// The visible name is the import alias or the last path segment. A
+    /// package-level symbol of the same name shadows the package, making
+    /// `name.x.y` a value chain instead, and so does a local of the function
+    /// `from`. Without a function, any local of the file counts.
+    fn is_package_qualifier(&self, name: &str, from: Option<SymbolId>) -> bool {
+        let shadowed_locally = match from {
+            Some(function) => self
+                .function_locals
+                .get(&function)
+                .is_some_and(|locals| locals.contains(name)),
+            None => self.local_scope.contains_key(name),
+        };
+        if shadowed_locally || self.package_symbols.contains_key(name) {
+            return false;
+        }
+        self.imports
+            .iter()
+            .any(|(path, alias)| match alias.as_deref() {
+                Some("." | "_") => false,
+                Some(alias) => alias == name,
+                None => path.rsplit('/').next() == Some(name),
+            })
+    }
+
+    /// Resolve `name` as referenced from the function `from`, when known
+    ///
+    /// The function decides which locals shadow an imported package.
+    fn resolve_from(&self, name: &str, from: Option<SymbolId>) -> Option<SymbolId> {
+        // Go resolution order:
+        // 1. Local scope (function parameters, local variables, block variables)
+        // 2. Package scope (functions, types, variables, constants)
+        // 3. Imported symbols (from other packages)
+
+        // 1. Check local scope first (most specific)
+        if let Some(&id) = self.local_scope.get(name) {
+            return Some(id);
+        }
+
+        // A name two dot imports provide is an error in Go, not a choice,
+        // and so is a selector two embedded types promote
+        if self.ambiguous_dot_imports.contains_key(name)
+            || self.ambiguous_selectors.contains_key(name)
+        {
+            return None;
+        }
+
+        // 2. Check package-level symbols
+        if let Some(&id) = self.package_symbols.get(name) {
+            return Some(id);
+        }
+
+        // 3. Check imported symbols
+        if let Some(&id) = self.imported_symbols.get(name) {
+            return Some(id);
+        }
+
+        // 4. Check if it's a qualified name (contains .)
+        if name.contains('.') {
+            // CRITICAL FIX: First try to resolve the full qualified path directly
+            // This handles cases where we have the full package path stored (e.g., "github.com/user/pkg.Function")
+            // Check in all scopes for the full qualified name
+            if let Some(&id) = self.imported_symbols.get(name) {
+                return Some(id);
+            }
+            if let Some(&id) = self.package_symbols.get(name) {
+                return Some(id);
+            }
+
+            // `import/path.Type.Member`, as stdlib constructor results are
+            // named: only the imported package is looked in, and only with
+            // the built-in standard library knowledge on
+            if let Some(rest) = self.imports.iter().find_map(|(path, _)| {
+                name.strip_prefix(path.as_str())
+                    .and_then(|rest| rest.strip_prefix('.'))
+            }) {
+                if self.stdlib == StdlibResolution::Off {
+                    return None;
+                }
+                return self.imported_symbols.get(rest).copied();
+            }
+
+            let parts: Vec<&str> = name.split('.').collect();
+
+            // `pkg.Type` or `pkg.Type.Member`: the package qualifier only
+            // selects where to look, so resolve the rest as written. The
+            // file's own declarations are not in the imported package:
+            // `config.InitGlobalDirs` is never a local `InitGlobalDirs`
+            if self.is_package_qualifier(parts[0], from) {
+                let rest = parts[1..].join(".");
+                if self.local_scope.contains_key(&rest) || self.package_symbols.contains_key(&rest)
+                {
+                    return self.imported_symbols.get(&rest).copied();
+                }
+                return self.resolve_from(&rest, from);
+            }
+
+            // If full path not found, try to resolve as a 2-part path
+            if parts.len() == 2 {
+                let package_or_type = parts[0];
+                let function_or_method = parts[1];
+
+                // Check if package/type exists in our codebase
+                if self.resolve_from(package_or_type, from).is_some() {
+                    // Package/type exists, resolve the function/method
+                    return self.resolve_from(function_or_method, from);
+                }
+            }
+        }
+
+        None
+    }
+
+    /// Record the locals of each function and method among a file's symbols
+    ///
+    /// A local or parameter belongs to the innermost function or method whose
+    /// range contains it.
+    pub fn add_function_locals(&mut self, symbols: &[crate::Symbol]) {
+        use crate::symbol::ScopeContext;
+
+        let position = |range: &crate::Range, end: bool| {
+            if end {
+                (range.end_line, range.end_column)
+            } else {
+                (range.start_line, range.start_column)
+            }
+        };
+        let functions: Vec<&crate::Symbol> = symbols
+            .iter()
+            .filter(|s| {
+                matches!(
+                    s.kind,
+                    crate::SymbolKind::Function | crate::SymbolKind::Method
+                )
+            })
+            .collect();
+        for symbol in symbols.iter().filter(|s| {
+            matches!(
+                s.scope_context,
+                Some(ScopeContext::Local { .. } | ScopeContext::Parameter)
+            )
+        }) {
+            let function = functions
+                .iter()
+                .filter(|f| {
+                    position(&f.range, false) <= position(&symbol.range, false)
+                        && position(&symbol.range, true) <= position(&f.range, true)
+                })
+                .max_by_key(|f| position(&f.range, false));
+            if let Some(function) = function {
+                self.function_locals
+                    .entry(function.id)
+                    .or_default()
+                    .insert(symbol.name.to_string());
+            }
+        }
+    }
+
+    /// Record a name that several dot-imported packages export
+    ///
+    /// The name no longer resolves unless a local declaration shadows it:
+    /// picking one of the packages would be a guess.
+    pub fn add_ambiguous_dot_import(&mut self, name: String, import_paths: Vec<String>) {
+        self.ambiguous_dot_imports.insert(name, import_paths);
+    }
+
+    /// Import paths of the dot-imported packages that all export `name`, when
+    /// more than one does
+    pub fn ambiguous_dot_import(&self, name: &str) -> Option<&[String]> {
+        self.ambiguous_dot_imports.get(name).map(Vec::as_slice)
+    }
+
+    /// Record a promoted `Outer.Name` selector that several embedded types
+    /// provide at the same depth
+    ///
+    /// Go rejects such a selector, so it resolves to nothing, not to the
+    /// method or field of one of the embedded types.
+    pub fn add_ambiguous_selector(&mut self, qualified: String, providers: Vec<String>) {
+        self.ambiguous_selectors.insert(qualified, providers);
+    }
+
+    /// Embedded types that all promote `qualified`, when more than one does
+    pub fn ambiguous_selector(&self, qualified: &str) -> Option<&[String]> {
+        self.ambiguous_selectors.get(qualified).map(Vec::as_slice)
+    }
+
     /// Add an imported symbol to the context
     ///
     /// This is called when an import is resolved to add the symbol to the imported symbols.
@@ -536,51 +840,35 @@ impl GoResolutionContext {
 
     /// Resolve relative imports (./pkg, ../pkg)
     ///
-    /// Handle Go relative imports which are uncommon but valid.
-    /// Relative imports are resolved relative to the importing package's directory.
+    /// Relative imports are resolved against the importing package's
+    /// directory; see [`relative_import_target`].
     pub fn resolve_relative_import(
         &self,
         import_path: &str,
         current_package_path: &str,
     ) -> Option<String> {
-        if !import_path.starts_with("./") && !import_path.starts_with("../") {
-            return None;
-        }
-
-        let current_parts: Vec<&str> = current_package_path.split('/').collect();
-        let import_parts: Vec<&str> = import_path.split('/').collect();
-
-        // Count the number of ".." in the import path
-        let up_count = import_parts
-            .iter()
-            .filter(|&part| *part == ".." || *part == "../")
-            .count();
+        relative_import_target(import_path, current_package_path)
+    }
 
-        // Calculate how many parts to keep from the current path
-        // Special case: if going up would leave us at just the module name,
-        // and we have more to go up, we go to root (outside the module)
-        let keep_count = if (up_count == current_parts.len() - 1 && current_parts.len() >= 3)
-            || up_count >= current_parts.len()
-        {
-            0 // Go to root when traversing up to/beyond module level
-        } else {
-            current_parts.len() - up_count
-        };
+    /// Record where a relative import of the file leads
+    pub fn add_relative_import(&mut self, import_path: String, target: RelativeImport) {
+        self.relative_imports.insert(import_path, target);
+    }
 
-        // Start with the parts we keep from the current path
-        let mut resolved_parts: Vec<&str> = current_parts.into_iter().take(keep_count).collect();
+    /// Where a relative import of the file leads, once resolved
+    pub fn relative_import(&self, import_path: &str) -> Option<&RelativeImport> {
+        self.relative_imports.get(import_path)
+    }
 
-        // Add non-".." parts from the import path
-        for part in import_parts {
-            match part {
-                "." | "./" => continue,   // Current directory
-                ".." | "../" => continue, // Already handled above
-                _ if !part.is_empty() => resolved_parts.push(part),
-                _ => continue,
-            }
-        }
+    /// Record the indexed package directory an import path of the file
+    /// leads to
+    pub fn add_imported_package(&mut self, import_path: String, package: String) {
+        self.imported_packages.insert(import_path, package);
+    }
 
-        Some(resolved_parts.join("/"))
+    /// Package directory an import path of the file leads to, when indexed
+    pub fn imported_package(&self, import_path: &str) -> Option<&str> {
+        self.imported_packages.get(import_path).map(String::as_str)
     }
 
     /// Check for imports in vendor directory
@@ -618,73 +906,7 @@ impl GoResolutionContext {
     /// Extract module name, Go version, dependencies, and replace directives
     /// from a go.mod file.
     pub fn parse_go_mod(&self, go_mod_path: &str) -> Option<GoModInfo> {
-        use std::fs;
-
-        let content = fs::read_to_string(go_mod_path).ok()?;
-        let mut info = GoModInfo::default();
-        let mut in_require_block = false;
-
-        for line in content.lines() {
-            let line = line.trim();
-
-            // Skip empty lines and comments
-            if line.is_empty() || line.starts_with("//") {
-                continue;
-            }
-
-            // Parse module directive
-            if line.starts_with("module ") {
-                if let Some(module_name) = line.strip_prefix("module ") {
-                    info.module_name = Some(module_name.trim().to_string());
-                }
-            }
-            // Parse go directive
-            else if line.starts_with("go ") {
-                if let Some(go_version) = line.strip_prefix("go ") {
-                    info.go_version = Some(go_version.trim().to_string());
-                }
-            }
-            // Parse replace directives
-            else if line.starts_with("replace ") {
-                if let Some(replace_part) = line.strip_prefix("replace ") {
-                    if let Some((from, to)) = replace_part.split_once(" => ") {
-                        info.replacements
-                            .insert(from.trim().to_string(), to.trim().to_string());
-                    }
-                }
-            }
-            // Parse require directive - handle both inline and block forms
-            else if line.starts_with("require ") {
-                if line.ends_with("(") {
-                    // Start of require block
-                    in_require_block = true;
-                } else {
-                    // Inline require
-                    if let Some(require_part) = line.strip_prefix("require ") {
-                        let parts: Vec<&str> = require_part.split_whitespace().collect();
-                        if parts.len() >= 2 {
-                            info.dependencies
-                                .insert(parts[0].to_string(), parts[1].to_string());
-                        }
-                    }
-                }
-            }
-            // Handle require block content
-            else if in_require_block {
-                if line == ")" {
-                    in_require_block = false;
-                } else {
-                    // Parse dependency line in block
-                    let parts: Vec<&str> = line.split_whitespace().collect();
-                    if parts.len() >= 2 {
-                        info.dependencies
-                            .insert(parts[0].to_string(), parts[1].to_string());
-                    }
-                }
-            }
-        }
-
-        Some(info)
+        GoModInfo::read(std::path::Path::new(go_mod_path))
     }
 
     /// Apply module replacements from go.mod
@@ -863,10 +1085,7 @@ impl GoResolutionContext {
                 if let Some(ref module_path) = candidate.module_path {
                     let module_str: &str = module_path.as_ref();
 
-                    // Check for exact match or last component match
-                    if (module_str == package_path
-                        || module_str.split('/').next_back()
-                            == Some(package_path.split('/').next_back().unwrap_or(package_path)))
+                    if package_matches_import(module_str, package_path)
                         && candidate.visibility == crate::Visibility::Public
                     {
                         return Some(candidate.id);
@@ -909,33 +1128,12 @@ impl GoResolutionContext {
     }
 
     /// Parse generic type parameters from a signature like "[T any, K comparable]"
+    ///
+    /// Names listed together share the constraint after them, `[T, U any]`.
     pub fn parse_and_register_generic_params(&mut self, generic_part: &str) {
-        // Remove brackets and split by comma
         let cleaned = generic_part.trim_start_matches('[').trim_end_matches(']');
-        if cleaned.is_empty() {
-            return;
-        }
-
-        for param in cleaned.split(',') {
-            let param = param.trim();
-            if param.is_empty() {
-                continue;
-            }
-
-            // Parse "T any", "K comparable", "V SomeInterface", etc.
-            let parts: Vec<&str> = param.split_whitespace().collect();
-            if parts.is_empty() {
-                continue;
-            }
-
-            let param_name = parts[0].to_string();
-            let constraint = if parts.len() > 1 {
-                Some(parts[1..].join(" "))
-            } else {
-                None
-            };
-
-            self.add_generic_parameter(param_name, constraint);
+        for (param_name, constraint) in super::parser::type_parameter_list(cleaned) {
+            self.add_generic_parameter(param_name.to_string(), Some(constraint.to_string()));
         }
     }
 }
@@ -963,53 +1161,18 @@ impl ResolutionScope for GoResolutionContext {
     }
 
     fn resolve(&self, name: &str) -> Option<SymbolId> {
-        // Go resolution order:
-        // 1. Local scope (function parameters, local variables, block variables)
-        // 2. Package scope (functions, types, variables, constants)
-        // 3. Imported symbols (from other packages)
-
-        // 1. Check local scope first (most specific)
-        if let Some(&id) = self.local_scope.get(name) {
-            return Some(id);
-        }
-
-        // 2. Check package-level symbols
-        if let Some(&id) = self.package_symbols.get(name) {
-            return Some(id);
-        }
-
-        // 3. Check imported symbols
-        if let Some(&id) = self.imported_symbols.get(name) {
-            return Some(id);
-        }
-
-        // 4. Check if it's a qualified name (contains .)
-        if name.contains('.') {
-            // CRITICAL FIX: First try to resolve the full qualified path directly
-            // This handles cases where we have the full package path stored (e.g., "github.com/user/pkg.Function")
-            // Check in all scopes for the full qualified name
-            if let Some(&id) = self.imported_symbols.get(name) {
-                return Some(id);
-            }
-            if let Some(&id) = self.package_symbols.get(name) {
-                return Some(id);
-            }
-
-            // If full path not found, try to resolve as a 2-part path
-            let parts: Vec<&str> = name.split('.').collect();
-            if parts.len() == 2 {
-                let package_or_type = parts[0];
-                let function_or_method = parts[1];
-
-                // Check if package/type exists in our codebase
-                if self.resolve(package_or_type).is_some() {
-                    // Package/type exists, resolve the function/method
-                    return self.resolve(function_or_method);
-                }
-            }
-        }
+        self.resolve_from(name, None)
+    }
 
-        None
+    fn resolve_relationship_from(
+        &self,
+        from: SymbolId,
+        _from_name: &str,
+        to_name: &str,
+        _kind: crate::RelationKind,
+        _from_file: FileId,
+    ) -> Option<SymbolId> {
+        self.resolve_from(to_name, Some(from))
     }
 
     fn clear_local_scope(&mut self) {
@@ -1051,8 +1214,12 @@ impl ResolutionScope for GoResolutionContext {
     }
 
     fn populate_imports(&mut self, imports: &[crate::parsing::Import]) {
-        // Convert Import records into our internal (path, alias) tuple format
-        for import in imports {
+        // Convert Import records into our internal (path, alias) tuple format;
+        // blank imports bind no name to qualify with
+        for import in imports
+            .iter()
+            .filter(|import| import.alias.as_deref() != Some("_"))
+        {
             self.add_import(import.path.clone(), import.alias.clone());
         }
     }
@@ -1065,6 +1232,278 @@ impl ResolutionScope for GoResolutionContext {
     fn import_binding(&self, name: &str) -> Option<ImportBinding> {
         self.import_bindings.get(name).cloned()
     }
+
+    fn is_ambiguous_selector(&self, qualified: &str) -> bool {
+        self.ambiguous_selectors.contains_key(qualified)
+    }
+
+    /// An import leading to an indexed package, relative or not, is local,
+    /// though no single symbol stands for the package
+    ///
+    /// `name` is the import's visible name, or its path as in a type
+    /// qualified by the package declaring it (`container/list.List`).
+    fn is_external_import(&self, name: &str) -> bool {
+        let Some(binding) = self.import_bindings.get(name).or_else(|| {
+            self.import_bindings
+                .values()
+                .find(|binding| binding.import.path == name)
+        }) else {
+            return false;
+        };
+        if self.imported_packages.contains_key(&binding.import.path)
+            || matches!(
+                self.relative_imports.get(&binding.import.path),
+                Some(RelativeImport::Package(_))
+            )
+        {
+            return false;
+        }
+        match binding.origin {
+            ImportOrigin::External => true,
+            ImportOrigin::Internal | ImportOrigin::Unknown => binding.resolved_symbol.is_none(),
+        }
+    }
+}
+
+/// Where a relative import (`./pkg`, `../pkg`) leads
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum RelativeImport {
+    /// An indexed package, by its directory relative to the project root
+    Package(String),
+    /// A directory outside the indexed tree, or one holding no indexed
+    /// package; kept so the import is reported rather than dropped
+    ExternalRelative,
+}
+
+/// Directory a relative import leads to from the importing package's
+/// directory
+///
+/// Both directories are relative to the project root, `.` being the root
+/// itself; `.` and `..` segments are normalized. `None` when the import
+/// isn't relative or climbs above the root, out of the indexed tree:
+/// `../shared` from `cmd/tool` is `cmd/shared`, from `.` it is `None`.
+pub fn relative_import_target(import_path: &str, package_path: &str) -> Option<String> {
+    if !import_path.starts_with("./") && !import_path.starts_with("../") {
+        return None;
+    }
+    let mut parts: Vec<&str> = package_path
+        .split('/')
+        .filter(|part| !part.is_empty() && *part != ".")
+        .collect();
+    for part in import_path.split('/') {
+        match part {
+            "" | "." => {}
+            ".." => {
+                parts.pop()?;
+            }
+            _ => parts.push(part),
+        }
+    }
+    if parts.is_empty() {
+        Some(".".to_string())
+    } else {
+        Some(parts.join("/"))
+    }
+}
+
+/// Whether a symbol's module path is the package of an import path
+///
+/// Module paths are directories relative to the project root, so besides an
+/// exact match the last components are compared: `example.com/app/models`
+/// matches `models`.
+pub fn package_matches_import(module_path: &str, import_path: &str) -> bool {
+    module_path == import_path
+        || module_path.rsplit('/').next() == Some(import_path.rsplit('/').next().unwrap_or(""))
+}
+
+/// Import path of the package a qualifier such as `models` names among the
+/// imports of a file
+///
+/// The qualifier is the import alias, or the last path segment of an import
+/// without one. Blank and dot imports name no package.
+pub fn qualifier_import_path<'i>(
+    imports: &'i [crate::parsing::Import],
+    qualifier: &str,
+) -> Option<&'i str> {
+    imports
+        .iter()
+        .find(|import| match import.alias.as_deref() {
+            Some("." | "_") => false,
+            Some(alias) => alias == qualifier,
+            None => import.path.rsplit('/').next() == Some(qualifier),
+        })
+        .map(|import| import.path.as_str())
+}
+
+/// Method sets of common standard library interfaces
+///
+/// The standard library is not indexed, so an interface embedding `io.Closer`
+/// would otherwise contribute no methods. Embedded interfaces are flattened,
+/// e.g. `io.ReadCloser` lists both `Read` and `Close`.
+const STDLIB_INTERFACES: &[(&str, &[&str])] = &[
+    ("error", &["Error() string"]),
+    ("fmt.Stringer", &["String() string"]),
+    ("io.Reader", &["Read(p []byte) (n int, err error)"]),
+    ("io.Writer", &["Write(p []byte) (n int, err error)"]),
+    ("io.Closer", &["Close() error"]),
+    (
+        "io.ReadWriter",
+        &[
+            "Read(p []byte) (n int, err error)",
+            "Write(p []byte) (n int, err error)",
+        ],
+    ),
+    (
+        "io.ReadCloser",
+        &["Read(p []byte) (n int, err error)", "Close() error"],
+    ),
+    (
+        "io.WriteCloser",
+        &["Write(p []byte) (n int, err error)", "Close() error"],
+    ),
+    (
+        "io.ReadWriteCloser",
+        &[
+            "Read(p []byte) (n int, err error)",
+            "Write(p []byte) (n int, err error)",
+            "Close() error",
+        ],
+    ),
+];
+
+/// Method signatures of a well-known standard library interface
+///
+/// `name` is the interface as written in source, e.g. `io.Closer` or `error`.
+/// Always `None` with `stdlib = "off"` in `codanna.toml`.
+pub fn stdlib_interface_methods(
+    name: &str,
+    stdlib: StdlibResolution,
+) -> Option<&'static [&'static str]> {
+    if stdlib == StdlibResolution::Off {
+        return None;
+    }
+    STDLIB_INTERFACES
+        .iter()
+        .find(|(interface, _)| *interface == name)
+        .map(|(_, methods)| *methods)
+}
+
+/// Result types of common standard library constructors
+///
+/// Keyed by import path and function. Types are qualified by the import
+/// path of the package declaring them (`container/list.List`), so
+/// `l := list.New()` makes `l.PushBack(1)` a call to that package's
+/// `List.PushBack` when the standard library sources are indexed, and never
+/// to a `List` declared next to the call.
+const STDLIB_CONSTRUCTORS: &[(&str, &str, &str)] = &[
+    ("bufio", "NewScanner", "bufio.Scanner"),
+    ("bytes", "NewBuffer", "bytes.Buffer"),
+    ("bytes", "NewBufferString", "bytes.Buffer"),
+    ("container/list", "New", "container/list.List"),
+    ("container/ring", "New", "container/ring.Ring"),
+    ("encoding/json", "NewDecoder", "encoding/json.Decoder"),
+    ("encoding/json", "NewEncoder", "encoding/json.Encoder"),
+    ("regexp", "Compile", "regexp.Regexp"),
+    ("regexp", "MustCompile", "regexp.Regexp"),
+    ("strings", "NewReplacer", "strings.Replacer"),
+    ("time", "NewTicker", "time.Ticker"),
+    ("time", "NewTimer", "time.Timer"),
+];
+
+/// Type returned by a well-known standard library constructor
+///
+/// `import_path` is the full path, e.g. `container/list`, whatever the
+/// package is imported as. The parser binds results by this table whatever
+/// the configured mode; [`GoResolutionContext`] declines to resolve them with
+/// `stdlib = "off"`.
+pub fn stdlib_constructor_type(import_path: &str, function: &str) -> Option<&'static str> {
+    STDLIB_CONSTRUCTORS
+        .iter()
+        .find(|(path, name, _)| *path == import_path && *name == function)
+        .map(|(_, _, result)| *result)
+}
+
+/// Result counts of common standard library functions returning an error
+///
+/// Keyed by import path and function; the error is the last result. The
+/// printing functions of `fmt` are left out: their errors are ignored by
+/// convention.
+const STDLIB_ERROR_RESULTS: &[(&str, &str, usize)] = &[
+    ("database/sql", "Open", 2),
+    ("encoding/json", "Marshal", 2),
+    ("encoding/json", "MarshalIndent", 2),
+    ("encoding/json", "Unmarshal", 1),
+    ("io", "Copy", 2),
+    ("io", "ReadAll", 2),
+    ("net/http", "Get", 2),
+    ("net/http", "ListenAndServe", 1),
+    ("net/http", "NewRequest", 2),
+    ("net/http", "Post", 2),
+    ("net/url", "Parse", 2),
+    ("os", "Chdir", 1),
+    ("os", "Create", 2),
+    ("os", "Getwd", 2),
+    ("os", "Hostname", 2),
+    ("os", "Mkdir", 1),
+    ("os", "MkdirAll", 1),
+    ("os", "Open", 2),
+    ("os", "OpenFile", 2),
+    ("os", "ReadDir", 2),
+    ("os", "ReadFile", 2),
+    ("os", "Remove", 1),
+    ("os", "RemoveAll", 1),
+    ("os", "Rename", 1),
+    ("os", "Setenv", 1),
+    ("os", "Stat", 2),
+    ("os", "WriteFile", 1),
+    ("path/filepath", "Abs", 2),
+    ("path/filepath", "Glob", 2),
+    ("path/filepath", "Rel", 2),
+    ("regexp", "Compile", 2),
+    ("strconv", "Atoi", 2),
+    ("strconv", "ParseBool", 2),
+    ("strconv", "ParseFloat", 2),
+    ("strconv", "ParseInt", 2),
+    ("strconv", "ParseUint", 2),
+    ("time", "Parse", 2),
+    ("time", "ParseDuration", 2),
+];
+
+/// Number of results of a well-known standard library function whose last
+/// result is an error
+///
+/// `import_path` is the full path, e.g. `encoding/json`. Always `None` with
+/// `stdlib = "off"`.
+pub fn stdlib_error_results(
+    import_path: &str,
+    function: &str,
+    stdlib: StdlibResolution,
+) -> Option<usize> {
+    if stdlib == StdlibResolution::Off {
+        return None;
+    }
+    STDLIB_ERROR_RESULTS
+        .iter()
+        .find(|(path, name, _)| *path == import_path && *name == function)
+        .map(|(_, _, count)| *count)
+}
+
+/// Names of the well-known standard library interfaces, e.g. `io.Closer`
+pub fn stdlib_interface_names(stdlib: StdlibResolution) -> impl Iterator<Item = &'static str> {
+    STDLIB_INTERFACES
+        .iter()
+        .map(|(interface, _)| *interface)
+        .filter(move |_| stdlib != StdlibResolution::Off)
+}
+
+/// Method names of a well-known standard library interface
+fn stdlib_interface_method_names(name: &str, stdlib: StdlibResolution) -> Option<Vec<String>> {
+    stdlib_interface_methods(name, stdlib).map(|methods| {
+        methods
+            .iter()
+            .map(|m| m.split('(').next().unwrap_or(m).to_string())
+            .collect()
+    })
 }
 
 /// Go interface implementation resolution system
@@ -1086,6 +1525,13 @@ pub struct GoInheritanceResolver {
     /// Tracks methods on types (structs and interfaces)
     /// Key: "TypeName", Value: Vec<"method_name">
     type_methods: HashMap<String, Vec<String>>,
+
+    /// Maps struct names to the types they embed, whose methods are promoted
+    /// Key: "StructName", Value: Vec<"EmbeddedTypeName">
+    struct_embeds: HashMap<String, Vec<String>>,
+
+    /// Whether well-known standard library interfaces are known
+    stdlib: StdlibResolution,
 }
 
 impl Default for GoInheritanceResolver {
@@ -1104,9 +1550,21 @@ impl GoInheritanceResolver {
             struct_implements: HashMap::new(),
             interface_embeds: HashMap::new(),
             type_methods: HashMap::new(),
+            struct_embeds: HashMap::new(),
+            stdlib: StdlibResolution::default(),
         }
     }
 
+    /// Set the standard library resolution mode, `stdlib` in `codanna.toml`
+    pub fn set_stdlib_resolution(&mut self, stdlib: StdlibResolution) {
+        self.stdlib = stdlib;
+    }
+
+    /// Standard library resolution mode of the resolver
+    pub fn stdlib_resolution(&self) -> StdlibResolution {
+        self.stdlib
+    }
+
     /// Check if a type is an interface
     ///
     /// This method determines whether a type is an interface based on:
@@ -1114,8 +1572,10 @@ impl GoInheritanceResolver {
     /// 2. Common Go naming conventions (interfaces often start with 'I' or end with 'er')
     /// 3. Exclusion principle (if it's not a struct, it might be an interface)
     pub fn is_interface(&self, type_name: &str) -> bool {
-        // 1. Explicitly tracked interfaces
-        if self.interface_embeds.contains_key(type_name) {
+        // 1. Explicitly tracked interfaces and well-known stdlib interfaces
+        if self.interface_embeds.contains_key(type_name)
+            || stdlib_interface_methods(type_name, self.stdlib).is_some()
+        {
             return true;
         }
 
@@ -1174,6 +1634,11 @@ impl InheritanceResolver for GoInheritanceResolver {
             if methods.iter().any(|m| m == method_name) {
                 return Some(type_name.to_string());
             }
+        } else if let Some(methods) = stdlib_interface_method_names(type_name, self.stdlib) {
+            // External stdlib interface (e.g. an embedded io.Closer)
+            if methods.iter().any(|m| m == method_name) {
+                return Some(type_name.to_string());
+            }
         }
 
         // For structs: check implemented interfaces
@@ -1300,6 +1765,14 @@ impl InheritanceResolver for GoInheritanceResolver {
                         all_methods.push(method.clone());
                     }
                 }
+            } else if let Some(methods) = stdlib_interface_method_names(type_name, resolver.stdlib)
+            {
+                // External stdlib interface (e.g. an embedded io.Closer)
+                for method in methods {
+                    if !all_methods.contains(&method) {
+                        all_methods.push(method);
+                    }
+                }
             }
 
             // For structs: check implemented interfaces
@@ -1315,6 +1788,14 @@ impl InheritanceResolver for GoInheritanceResolver {
                     collect_methods(resolver, embedded_interface, all_methods, visited);
                 }
             }
+
+            // For structs: methods promoted from embedded types, alongside
+            // the ones declared directly
+            if let Some(embedded) = resolver.struct_embeds.get(type_name) {
+                for embedded_type in embedded {
+                    collect_methods(resolver, embedded_type, all_methods, visited);
+                }
+            }
         }
 
         collect_methods(self, type_name, &mut all_methods, &mut visited);
@@ -1332,6 +1813,15 @@ impl GoInheritanceResolver {
             .push(interface_name);
     }
 
+    /// Register the types a struct embeds, so their methods are promoted
+    /// into its method set
+    pub fn add_struct_embeds(&mut self, struct_name: String, embedded: Vec<String>) {
+        self.struct_embeds
+            .entry(struct_name)
+            .or_default()
+            .extend(embedded);
+    }
+
     /// Register that an interface embeds other interfaces
     pub fn add_interface_embeds(&mut self, interface_name: String, embedded: Vec<String>) {
         self.interface_embeds.insert(interface_name, embedded);
@@ -1364,6 +1854,10 @@ impl GoInheritanceResolver {
     ///
     /// This performs structural compatibility checking - in Go, a type implements
     /// an interface if it has all the methods required by the interface.
+    ///
+    /// When both names are package-qualified (`models.Store`) and the packages
+    /// differ, an interface with unexported methods is never satisfied: those
+    /// methods can only be declared in the interface's own package.
     pub fn check_struct_implements_interface(
         &self,
         struct_name: &str,
@@ -1372,6 +1866,23 @@ impl GoInheritanceResolver {
         // Get methods required by the interface
         let interface_methods = self.get_all_methods(interface_name);
 
+        let other_package = match (
+            struct_name.rsplit_once('.'),
+            interface_name.rsplit_once('.'),
+        ) {
+            (Some((struct_package, _)), Some((interface_package, _))) => {
+                struct_package != interface_package
+            }
+            _ => false,
+        };
+        if other_package
+            && interface_methods
+                .iter()
+                .any(|m| !m.starts_with(|c: char| c.is_uppercase()))
+        {
+            return false;
+        }
+
         // Get methods available on the struct
         let struct_methods = self.get_all_methods(struct_name);
 
@@ -1572,6 +2083,130 @@ mod tests {
         );
     }
 
+    #[test]
+    fn test_package_qualified_selectors() {
+        let mut context = GoResolutionContext::new(FileId::new(1).unwrap());
+        context.add_import("example.com/app/models".to_string(), None);
+        context.add_import(
+            "example.com/app/config".to_string(),
+            Some("cfg".to_string()),
+        );
+
+        let role = SymbolId::new(10).unwrap();
+        let role_string = SymbolId::new(11).unwrap();
+        let settings = SymbolId::new(12).unwrap();
+        context.add_symbol("UserRole".to_string(), role, ScopeLevel::Package);
+        context.add_symbol(
+            "UserRole.String".to_string(),
+            role_string,
+            ScopeLevel::Package,
+        );
+        context.add_symbol("Settings".to_string(), settings, ScopeLevel::Package);
+
+        // package.Type and package.Type.Member
+        assert_eq!(context.resolve("models.UserRole"), Some(role));
+        assert_eq!(context.resolve("models.UserRole.String"), Some(role_string));
+        // Aliased imports qualify by alias only
+        assert_eq!(context.resolve("cfg.Settings"), Some(settings));
+        assert_eq!(context.resolve("config.Settings"), None);
+
+        // A value chain is not mistaken for a package selector
+        assert_eq!(context.resolve("user.profile.UserRole"), None);
+
+        // A declaration of the file's package is not the imported one
+        let own_settings = SymbolId::new(13).unwrap();
+        context.add_symbol("Settings".to_string(), own_settings, ScopeLevel::Module);
+        assert_eq!(context.resolve("Settings"), Some(own_settings));
+        assert_eq!(context.resolve("cfg.Settings"), Some(settings));
+        context.add_symbol("Load".to_string(), own_settings, ScopeLevel::Module);
+        assert_eq!(context.resolve("cfg.Load"), None);
+
+        // A local variable named like the package shadows it
+        let local = SymbolId::new(20).unwrap();
+        context.add_symbol("models".to_string(), local, ScopeLevel::Local);
+        assert_eq!(context.resolve("models.UserRole.String"), None);
+    }
+
+    #[test]
+    fn test_package_qualifier_shadowed_in_declaring_method_only() {
+        use crate::parsing::go::GoParser;
+        use crate::types::SymbolCounter;
+
+        let file_id = FileId::new(1).unwrap();
+        let code = std::fs::read_to_string("tests/fixtures/go/shadowed_methods.go").unwrap();
+        let mut parser = GoParser::new().unwrap();
+        let symbols = parser.parse(&code, file_id, &mut SymbolCounter::new());
+
+        let mut context = GoResolutionContext::new(file_id);
+        context.add_import("example.com/app/models".to_string(), None);
+        let load = SymbolId::new(1000).unwrap();
+        context.add_import_symbol("Load".to_string(), load, false);
+        context.add_function_locals(&symbols);
+
+        // Both methods are named Render; only Page's declares `models`
+        let render = |receiver: &str| {
+            symbols
+                .iter()
+                .find(|s| {
+                    s.name.as_str() == "Render"
+                        && s.signature
+                            .as_deref()
+                            .is_some_and(|sig| sig.contains(receiver))
+                })
+                .map(|s| s.id)
+                .unwrap()
+        };
+        let resolve_from = |from: SymbolId| {
+            context.resolve_relationship_from(
+                from,
+                "Render",
+                "models.Load",
+                crate::RelationKind::Calls,
+                file_id,
+            )
+        };
+        assert_eq!(resolve_from(render("*Page")), None);
+        assert_eq!(resolve_from(render("*Card")), Some(load));
+    }
+
+    #[test]
+    fn test_aliased_stdlib_selectors() {
+        let mut context = GoResolutionContext::new(FileId::new(1).unwrap());
+        context.add_import("container/list".to_string(), Some("set".to_string()));
+
+        // With the standard library indexed, `set.New` is `list.New` and a
+        // value from it has the package's `List` methods
+        let new = SymbolId::new(1).unwrap();
+        let push_back = SymbolId::new(2).unwrap();
+        context.add_symbol("New".to_string(), new, ScopeLevel::Package);
+        context.add_symbol("List.PushBack".to_string(), push_back, ScopeLevel::Package);
+        assert_eq!(context.resolve("set.New"), Some(new));
+        assert_eq!(context.resolve("list.New"), None);
+
+        let list_type = stdlib_constructor_type("container/list", "New").unwrap();
+        assert_eq!(list_type, "container/list.List");
+        assert_eq!(
+            context.resolve(&format!("{list_type}.PushBack")),
+            Some(push_back)
+        );
+        context.set_stdlib_resolution(StdlibResolution::Off);
+        assert_eq!(context.resolve(&format!("{list_type}.PushBack")), None);
+        context.set_stdlib_resolution(StdlibResolution::Builtin);
+
+        // A `Buffer` of the file's own package is not the imported one
+        let local_write = SymbolId::new(3).unwrap();
+        context.add_symbol(
+            "Buffer.WriteString".to_string(),
+            local_write,
+            ScopeLevel::Module,
+        );
+        context.add_import("bytes".to_string(), None);
+        let buffer_type = stdlib_constructor_type("bytes", "NewBuffer").unwrap();
+        assert_eq!(context.resolve(&format!("{buffer_type}.WriteString")), None);
+        assert_eq!(stdlib_constructor_type("container/list", "Other"), None);
+        assert_eq!(stdlib_constructor_type("list", "New"), None);
+    }
+
     #[test]
     fn test_standard_library_detection() {
         let context = GoResolutionContext::new(FileId::new(1).unwrap());
@@ -1728,7 +2363,15 @@ mod tests {
 
         // Test multiple parent directories
         let result = context.resolve_relative_import("../../shared", "myproject/pkg/internal");
-        assert_eq!(result, Some("shared".to_string()));
+        assert_eq!(result, Some("myproject/shared".to_string()));
+
+        // From the project root, and climbing out of it
+        let result = context.resolve_relative_import("./local", ".");
+        assert_eq!(result, Some("local".to_string()));
+        let result = context.resolve_relative_import("../shared", ".");
+        assert_eq!(result, None);
+        let result = context.resolve_relative_import("./a/../b/./c", "cmd");
+        assert_eq!(result, Some("cmd/b/c".to_string()));
 
         // Test complex relative path
         let result = context.resolve_relative_import("../lib/utils", "myproject/cmd");
@@ -1742,6 +2385,14 @@ mod tests {
         assert_eq!(result, None);
     }
 
+    #[test]
+    fn test_go_mod_info_parses_content() {
+        let info = GoModInfo::parse("// comment\nmodule example.com/loops\n\ngo 1.21\n");
+        assert_eq!(info.module_name.as_deref(), Some("example.com/loops"));
+        assert_eq!(info.go_version.as_deref(), Some("1.21"));
+        assert!(info.dependencies.is_empty());
+    }
+
     #[test]
     fn test_go_mod_parsing() {
         let context = GoResolutionContext::new(FileId::new(1).unwrap());
@@ -1988,6 +2639,36 @@ replace github.com/another/module => github.com/fork/module v1.2.3
         assert!(implementations.contains(&"FileWriter".to_string()));
     }
 
+    #[test]
+    fn test_cross_package_implementation_needs_exported_methods() {
+        let mut resolver = GoInheritanceResolver::new();
+        resolver.register_type_methods(
+            "models.Store".to_string(),
+            vec!["Save".to_string(), "Load".to_string()],
+        );
+        resolver.register_type_methods(
+            "models.sealedStore".to_string(),
+            vec!["Save".to_string(), "seal".to_string()],
+        );
+        resolver.register_type_methods(
+            "services.UserStore".to_string(),
+            vec!["Save".to_string(), "Load".to_string(), "seal".to_string()],
+        );
+        resolver.register_type_methods(
+            "models.MemoryStore".to_string(),
+            vec!["Save".to_string(), "seal".to_string()],
+        );
+
+        assert!(resolver.check_struct_implements_interface("services.UserStore", "models.Store"));
+        // services.UserStore.seal is not models' seal
+        assert!(
+            !resolver.check_struct_implements_interface("services.UserStore", "models.sealedStore")
+        );
+        assert!(
+            resolver.check_struct_implements_interface("models.MemoryStore", "models.sealedStore")
+        );
+    }
+
     #[test]
     fn test_generic_parameter_parsing() {
         let mut context = GoResolutionContext::new(FileId::new(1).unwrap());
@@ -2007,6 +2688,20 @@ replace github.com/another/module => github.com/fork/module v1.2.3
         assert!(test_context.resolve_type("T").is_some());
         assert!(test_context.resolve_type("K").is_some());
         assert!(test_context.resolve_type("V").is_some());
+
+        // Grouped names share their constraint
+        let mut grouped = GoResolutionContext::new(FileId::new(1).unwrap());
+        grouped.enter_generic_scope();
+        grouped.parse_and_register_generic_params("[T, U Number]");
+        for name in ["T", "U"] {
+            let parameter = grouped
+                .resolve_type(name)
+                .expect("type parameter registered");
+            assert_eq!(
+                parameter.constraints.get("constraint").map(String::as_str),
+                Some("Number")
+            );
+        }
     }
 
     #[test]
@@ -2143,4 +2838,67 @@ replace github.com/another/module => github.com/fork/module v1.2.3
         assert!(!registry.type_implements_interface("NonExistent", "Interface", Some(&resolver)));
         assert!(!registry.type_implements_interface("NonExistent", "Interface", None));
     }
+
+    #[test]
+    fn test_stdlib_embedded_interfaces_are_flattened() {
+        use crate::parsing::go::GoParser;
+
+        let code = std::fs::read_to_string("tests/fixtures/go/interfaces.go").unwrap();
+        let mut parser = GoParser::new().unwrap();
+
+        let mut resolver = GoInheritanceResolver::new();
+        let mut methods: HashMap<&str, Vec<String>> = HashMap::new();
+        for (definer, method, _) in parser.find_defines(&code) {
+            methods.entry(definer).or_default().push(method.to_string());
+        }
+        for (type_name, type_methods) in methods {
+            resolver.register_type_methods(type_name.to_string(), type_methods);
+        }
+        let mut embeds: HashMap<&str, Vec<String>> = HashMap::new();
+        for (interface, embedded, _) in parser.find_interface_embeds(&code) {
+            embeds
+                .entry(interface)
+                .or_default()
+                .push(embedded.to_string());
+        }
+        assert_eq!(
+            embeds.get("ReadWriteCloser"),
+            Some(&vec![
+                "Reader".to_string(),
+                "Writer".to_string(),
+                "io.Closer".to_string()
+            ])
+        );
+        for (interface, embedded) in embeds {
+            resolver.add_interface_embeds(interface.to_string(), embedded);
+        }
+
+        // io.Closer is not indexed but its method set is known
+        assert_eq!(
+            stdlib_interface_methods("io.Closer", StdlibResolution::Builtin),
+            Some(&["Close() error"][..])
+        );
+        assert_eq!(
+            stdlib_interface_methods("io.Closer", StdlibResolution::Off),
+            None
+        );
+        assert!(resolver.is_interface("io.Closer"));
+        assert_eq!(
+            resolver.resolve_method("CustomWriter", "Write"),
+            Some("io.Writer".to_string())
+        );
+
+        let required = resolver.get_all_methods("ReadWriteCloser");
+        for method in ["Read", "Write", "Close"] {
+            assert!(required.contains(&method.to_string()), "missing {method}");
+        }
+        assert!(resolver.check_struct_implements_interface("FileProcessor", "ReadWriteCloser"));
+        assert!(resolver.check_struct_implements_interface("FileProcessor", "io.Closer"));
+        assert!(!resolver.check_struct_implements_interface("FileProcessor", "fmt.Stringer"));
+
+        // With stdlib = "off" the embedded io.Closer brings no methods
+        resolver.set_stdlib_resolution(StdlibResolution::Off);
+        let required = resolver.get_all_methods("ReadWriteCloser");
+        assert!(!required.contains(&"Close".to_string()));
+    }
 }
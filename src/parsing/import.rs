@@ -10,7 +10,10 @@ use crate::FileId;
 pub struct Import {
     /// The path being imported (e.g., "std::collections::HashMap")
     pub path: String,
-    /// The alias if any (e.g., "use foo::Bar as Baz")
+    /// The alias if any (e.g., "use foo::Bar as Baz"; Go: `userModel "app/models"`)
+    ///
+    /// Go blank imports, `import _ "pkg"`, keep the alias `_`: they bind no
+    /// name and are kept only for the package's side effects.
     pub alias: Option<String>,
     /// Location in the file where this import appears
     pub file_id: FileId,
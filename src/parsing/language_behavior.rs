@@ -489,258 +489,7 @@ pub trait LanguageBehavior: Send + Sync {
         cache: &crate::storage::symbol_cache::ConcurrentSymbolCache,
         document_index: &DocumentIndex,
     ) -> IndexResult<Box<dyn ResolutionScope>> {
-        // Create language-specific resolution context
-        let mut context = self.create_resolution_context(file_id);
-
-        // 1. FIRST: Add imported symbols (HIGHEST PRIORITY)
-        // Optimized: Use cache to resolve imports when possible
-        // MERGE from both sources (Tantivy + BehaviorState)
-        let mut imports =
-            document_index
-                .get_imports_for_file(file_id)
-                .map_err(|e| IndexError::TantivyError {
-                    operation: "get_imports_for_file".to_string(),
-                    cause: e.to_string(),
-                })?;
-
-        // Merge with in-memory imports (deduplication)
-        let memory_imports = self.get_imports_for_file(file_id);
-        for import in memory_imports {
-            if !imports
-                .iter()
-                .any(|i| i.path == import.path && i.alias == import.alias)
-            {
-                imports.push(import);
-            }
-        }
-
-        // CRITICAL: Populate raw imports into context for is_external_import() checks
-        context.populate_imports(&imports);
-        let importing_module = self.get_module_path_for_file(file_id);
-        for import in imports {
-            // Try cache first for simple imports, fall back to full resolution
-            let separator = self.module_separator();
-            let symbol_name = import
-                .path
-                .split(separator)
-                .last()
-                .unwrap_or(&import.path)
-                .to_string();
-            debug_global!(
-                "DEBUG: Looking up '{}' (from import path '{}')",
-                symbol_name,
-                import.path
-            );
-
-            // Try multiple cache candidates to disambiguate by module path before DB fallback
-            let candidates = cache.lookup_candidates(&symbol_name, 16);
-            debug_global!(
-                "DEBUG: Cache candidates for '{}' (import '{}'): {}",
-                symbol_name,
-                import.path,
-                candidates.len()
-            );
-            let resolved_symbol = if candidates.is_empty() {
-                // Not in cache, use full resolution
-                debug_global!(
-                    "DEBUG: CACHE MISS for '{}' (import path: '{}') - using database",
-                    symbol_name,
-                    import.path
-                );
-                self.resolve_import(&import, document_index)
-            } else {
-                // Iterate candidates, verify with module_path and language rules
-                let mut matched: Option<SymbolId> = None;
-                for id in candidates.into_iter() {
-                    debug_global!("DEBUG: CACHE HIT for '{symbol_name}' -> SymbolId({id:?})");
-                    if let Ok(Some(symbol)) = document_index.find_symbol_by_id(id) {
-                        if let Some(module_path) = &symbol.module_path {
-                            if self.import_matches_symbol(
-                                &import.path,
-                                module_path.as_ref(),
-                                importing_module.as_deref(),
-                            ) {
-                                debug_global!("DEBUG: Cache hit VERIFIED - using cached symbol");
-                                matched = Some(id);
-                                break;
-                            }
-                            debug_global!(
-                                "DEBUG: Candidate mismatch, trying next: symbol_module='{}', import='{}'",
-                                module_path,
-                                import.path
-                            );
-                        } else {
-                            debug_global!(
-                                "DEBUG: Cache hit but no module path - trying next candidate"
-                            );
-                        }
-                    } else {
-                        debug_global!(
-                            "DEBUG: Cache hit but symbol not found by ID - trying next candidate"
-                        );
-                    }
-                }
-
-                if matched.is_some() {
-                    matched
-                } else {
-                    debug_global!("DEBUG: Cache hit but WRONG symbol - falling back to database");
-                    self.resolve_import(&import, document_index)
-                }
-            };
-
-            let origin = self.classify_import_origin(
-                &import,
-                resolved_symbol,
-                importing_module.as_deref(),
-                document_index,
-            );
-
-            let mut binding_names: Vec<String> = Vec::new();
-            if let Some(alias) = &import.alias {
-                binding_names.push(alias.clone());
-            }
-
-            if !binding_names.contains(&symbol_name) {
-                binding_names.push(symbol_name.clone());
-            }
-
-            if !binding_names.contains(&import.path) {
-                binding_names.push(import.path.clone());
-            }
-
-            let import_clone = import.clone();
-            for name in &binding_names {
-                context.register_import_binding(ImportBinding {
-                    import: import_clone.clone(),
-                    exposed_name: name.clone(),
-                    origin,
-                    resolved_symbol,
-                });
-            }
-
-            if let (ImportOrigin::Internal, Some(symbol_id)) = (origin, resolved_symbol) {
-                let primary_name = binding_names
-                    .first()
-                    .cloned()
-                    .unwrap_or_else(|| import_clone.alias.clone().unwrap_or(symbol_name.clone()));
-                context.add_symbol(primary_name, symbol_id, ScopeLevel::Module);
-            }
-        }
-
-        // 2. SECOND: Add file's local symbols (MEDIUM PRIORITY)
-        // This is necessary - we need all local symbols for the current file
-        let file_symbols =
-            document_index
-                .find_symbols_by_file(file_id)
-                .map_err(|e| IndexError::TantivyError {
-                    operation: "find_symbols_by_file".to_string(),
-                    cause: e.to_string(),
-                })?;
-
-        for symbol in file_symbols {
-            if self.is_resolvable_symbol(&symbol) {
-                context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Module);
-
-                // Also add by module_path for fully qualified resolution
-                // This allows resolving "crate::module::function" in addition to "function"
-                if let Some(module_path) = &symbol.module_path {
-                    context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Module);
-                }
-            }
-        }
-
-        // 3. THIRD: ELIMINATE get_all_symbols entirely!
-        // Instead of loading thousands of symbols, we'll only load symbols that are:
-        // - Public/exported
-        // - From files we actually import from
-        // This is a much smaller set!
-
-        // Get the list of files we import from (transitively)
-        let mut imported_files = std::collections::HashSet::new();
-        for import in self.get_imports_for_file(file_id) {
-            // Try to find which file this import comes from
-            // Use just the symbol name, not the full path
-            let symbol_name = import
-                .path
-                .split(self.module_separator())
-                .last()
-                .unwrap_or(&import.path);
-            if let Some(symbol_id) = cache.lookup_by_name(symbol_name) {
-                if let Ok(Some(symbol)) = document_index.find_symbol_by_id(symbol_id) {
-                    debug_global!(
-                        "DEBUG: Found import source file via cache: {:?} for '{}'",
-                        symbol.file_id,
-                        import.path
-                    );
-                    imported_files.insert(symbol.file_id);
-                }
-            }
-        }
-        debug_global!(
-            "DEBUG: Total imported files to load symbols from: {}",
-            imported_files.len()
-        );
-
-        // Only load public symbols from files we import from
-        for imported_file_id in &imported_files {
-            if *imported_file_id == file_id {
-                continue; // Skip current file
-            }
-
-            // Get only public symbols from this specific file
-            let imported_file_symbols = document_index
-                .find_symbols_by_file(*imported_file_id)
-                .map_err(|e| IndexError::TantivyError {
-                    operation: "find_symbols_by_file for imports".to_string(),
-                    cause: e.to_string(),
-                })?;
-
-            for symbol in imported_file_symbols {
-                // Only add if it's visible from our file
-                if self.is_symbol_visible_from_file(&symbol, file_id) {
-                    context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Global);
-
-                    // Also add by module_path for fully qualified resolution
-                    if let Some(module_path) = &symbol.module_path {
-                        context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Global);
-                    }
-                }
-            }
-        }
-
-        // If we have no imports, we might still need some standard library symbols
-        // Load a VERY small set of commonly used symbols (like String, Vec, etc.)
-        if imported_files.is_empty() {
-            debug_global!(
-                "DEBUG: No imports found - loading minimal fallback symbols (100 instead of 10000!)"
-            );
-            // Only load 100 most common symbols as a fallback
-            let minimal_symbols = document_index
-                .get_all_symbols(100) // Drastically reduced from 1000
-                .map_err(|e| IndexError::TantivyError {
-                    operation: "get_all_symbols minimal".to_string(),
-                    cause: e.to_string(),
-                })?;
-
-            for symbol in minimal_symbols {
-                if symbol.file_id != file_id && self.is_symbol_visible_from_file(&symbol, file_id) {
-                    context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Global);
-
-                    // Also add by module_path for fully qualified resolution
-                    if let Some(module_path) = &symbol.module_path {
-                        context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Global);
-                    }
-                }
-            }
-        } else {
-            debug_global!(
-                "DEBUG: SKIPPING get_all_symbols! Using only symbols from {} imported files",
-                imported_files.len()
-            );
-        }
-
-        Ok(context)
+        build_cached_resolution_context(self, file_id, cache, document_index)
     }
 
     /// Check if a symbol should be resolvable (added to resolution context)
@@ -977,6 +726,270 @@ pub trait LanguageBehavior: Send + Sync {
     }
 }
 
+/// Resolution context built through the symbol cache
+///
+/// The default [`LanguageBehavior::build_resolution_context_with_cache`];
+/// languages overriding it to add their own rules start from this.
+pub fn build_cached_resolution_context<B: LanguageBehavior + ?Sized>(
+    behavior: &B,
+    file_id: FileId,
+    cache: &crate::storage::symbol_cache::ConcurrentSymbolCache,
+    document_index: &DocumentIndex,
+) -> IndexResult<Box<dyn ResolutionScope>> {
+    // Create language-specific resolution context
+    let mut context = behavior.create_resolution_context(file_id);
+
+    // 1. FIRST: Add imported symbols (HIGHEST PRIORITY)
+    // Optimized: Use cache to resolve imports when possible
+    // MERGE from both sources (Tantivy + BehaviorState)
+    let mut imports =
+        document_index
+            .get_imports_for_file(file_id)
+            .map_err(|e| IndexError::TantivyError {
+                operation: "get_imports_for_file".to_string(),
+                cause: e.to_string(),
+            })?;
+
+    // Merge with in-memory imports (deduplication)
+    let memory_imports = behavior.get_imports_for_file(file_id);
+    for import in memory_imports {
+        if !imports
+            .iter()
+            .any(|i| i.path == import.path && i.alias == import.alias)
+        {
+            imports.push(import);
+        }
+    }
+
+    // CRITICAL: Populate raw imports into context for is_external_import() checks
+    context.populate_imports(&imports);
+    let importing_module = behavior.get_module_path_for_file(file_id);
+    for import in imports {
+        // Try cache first for simple imports, fall back to full resolution
+        let separator = behavior.module_separator();
+        let symbol_name = import
+            .path
+            .split(separator)
+            .last()
+            .unwrap_or(&import.path)
+            .to_string();
+        debug_global!(
+            "DEBUG: Looking up '{}' (from import path '{}')",
+            symbol_name,
+            import.path
+        );
+
+        // Try multiple cache candidates to disambiguate by module path before DB fallback
+        let candidates = cache.lookup_candidates(&symbol_name, 16);
+        debug_global!(
+            "DEBUG: Cache candidates for '{}' (import '{}'): {}",
+            symbol_name,
+            import.path,
+            candidates.len()
+        );
+        let resolved_symbol = if candidates.is_empty() {
+            // Not in cache, use full resolution
+            debug_global!(
+                "DEBUG: CACHE MISS for '{}' (import path: '{}') - using database",
+                symbol_name,
+                import.path
+            );
+            behavior.resolve_import(&import, document_index)
+        } else {
+            // Iterate candidates, verify with module_path and language rules
+            let mut matched: Option<SymbolId> = None;
+            for id in candidates.into_iter() {
+                debug_global!("DEBUG: CACHE HIT for '{symbol_name}' -> SymbolId({id:?})");
+                if let Ok(Some(symbol)) = document_index.find_symbol_by_id(id) {
+                    if let Some(module_path) = &symbol.module_path {
+                        if behavior.import_matches_symbol(
+                            &import.path,
+                            module_path.as_ref(),
+                            importing_module.as_deref(),
+                        ) {
+                            debug_global!("DEBUG: Cache hit VERIFIED - using cached symbol");
+                            matched = Some(id);
+                            break;
+                        }
+                        debug_global!(
+                            "DEBUG: Candidate mismatch, trying next: symbol_module='{}', import='{}'",
+                            module_path,
+                            import.path
+                        );
+                    } else {
+                        debug_global!(
+                            "DEBUG: Cache hit but no module path - trying next candidate"
+                        );
+                    }
+                } else {
+                    debug_global!(
+                        "DEBUG: Cache hit but symbol not found by ID - trying next candidate"
+                    );
+                }
+            }
+
+            if matched.is_some() {
+                matched
+            } else {
+                debug_global!("DEBUG: Cache hit but WRONG symbol - falling back to database");
+                behavior.resolve_import(&import, document_index)
+            }
+        };
+
+        let origin = behavior.classify_import_origin(
+            &import,
+            resolved_symbol,
+            importing_module.as_deref(),
+            document_index,
+        );
+
+        let mut binding_names: Vec<String> = Vec::new();
+        if let Some(alias) = &import.alias {
+            binding_names.push(alias.clone());
+        }
+
+        if !binding_names.contains(&symbol_name) {
+            binding_names.push(symbol_name.clone());
+        }
+
+        if !binding_names.contains(&import.path) {
+            binding_names.push(import.path.clone());
+        }
+
+        let import_clone = import.clone();
+        for name in &binding_names {
+            context.register_import_binding(ImportBinding {
+                import: import_clone.clone(),
+                exposed_name: name.clone(),
+                origin,
+                resolved_symbol,
+            });
+        }
+
+        if let (ImportOrigin::Internal, Some(symbol_id)) = (origin, resolved_symbol) {
+            let primary_name = binding_names
+                .first()
+                .cloned()
+                .unwrap_or_else(|| import_clone.alias.clone().unwrap_or(symbol_name.clone()));
+            context.add_symbol(primary_name, symbol_id, ScopeLevel::Module);
+        }
+    }
+
+    // 2. SECOND: Add file's local symbols (MEDIUM PRIORITY)
+    // This is necessary - we need all local symbols for the current file
+    let file_symbols =
+        document_index
+            .find_symbols_by_file(file_id)
+            .map_err(|e| IndexError::TantivyError {
+                operation: "find_symbols_by_file".to_string(),
+                cause: e.to_string(),
+            })?;
+
+    for symbol in file_symbols {
+        if behavior.is_resolvable_symbol(&symbol) {
+            context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Module);
+
+            // Also add by module_path for fully qualified resolution
+            // This allows resolving "crate::module::function" in addition to "function"
+            if let Some(module_path) = &symbol.module_path {
+                context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Module);
+            }
+        }
+    }
+
+    // 3. THIRD: ELIMINATE get_all_symbols entirely!
+    // Instead of loading thousands of symbols, we'll only load symbols that are:
+    // - Public/exported
+    // - From files we actually import from
+    // This is a much smaller set!
+
+    // Get the list of files we import from (transitively)
+    let mut imported_files = std::collections::HashSet::new();
+    for import in behavior.get_imports_for_file(file_id) {
+        // Try to find which file this import comes from
+        // Use just the symbol name, not the full path
+        let symbol_name = import
+            .path
+            .split(behavior.module_separator())
+            .last()
+            .unwrap_or(&import.path);
+        if let Some(symbol_id) = cache.lookup_by_name(symbol_name) {
+            if let Ok(Some(symbol)) = document_index.find_symbol_by_id(symbol_id) {
+                debug_global!(
+                    "DEBUG: Found import source file via cache: {:?} for '{}'",
+                    symbol.file_id,
+                    import.path
+                );
+                imported_files.insert(symbol.file_id);
+            }
+        }
+    }
+    debug_global!(
+        "DEBUG: Total imported files to load symbols from: {}",
+        imported_files.len()
+    );
+
+    // Only load public symbols from files we import from
+    for imported_file_id in &imported_files {
+        if *imported_file_id == file_id {
+            continue; // Skip current file
+        }
+
+        // Get only public symbols from this specific file
+        let imported_file_symbols = document_index
+            .find_symbols_by_file(*imported_file_id)
+            .map_err(|e| IndexError::TantivyError {
+                operation: "find_symbols_by_file for imports".to_string(),
+                cause: e.to_string(),
+            })?;
+
+        for symbol in imported_file_symbols {
+            // Only add if it's visible from our file
+            if behavior.is_symbol_visible_from_file(&symbol, file_id) {
+                context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Global);
+
+                // Also add by module_path for fully qualified resolution
+                if let Some(module_path) = &symbol.module_path {
+                    context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Global);
+                }
+            }
+        }
+    }
+
+    // If we have no imports, we might still need some standard library symbols
+    // Load a VERY small set of commonly used symbols (like String, Vec, etc.)
+    if imported_files.is_empty() {
+        debug_global!(
+            "DEBUG: No imports found - loading minimal fallback symbols (100 instead of 10000!)"
+        );
+        // Only load 100 most common symbols as a fallback
+        let minimal_symbols = document_index
+            .get_all_symbols(100) // Drastically reduced from 1000
+            .map_err(|e| IndexError::TantivyError {
+                operation: "get_all_symbols minimal".to_string(),
+                cause: e.to_string(),
+            })?;
+
+        for symbol in minimal_symbols {
+            if symbol.file_id != file_id && behavior.is_symbol_visible_from_file(&symbol, file_id) {
+                context.add_symbol(symbol.name.to_string(), symbol.id, ScopeLevel::Global);
+
+                // Also add by module_path for fully qualified resolution
+                if let Some(module_path) = &symbol.module_path {
+                    context.add_symbol(module_path.to_string(), symbol.id, ScopeLevel::Global);
+                }
+            }
+        }
+    } else {
+        debug_global!(
+            "DEBUG: SKIPPING get_all_symbols! Using only symbols from {} imported files",
+            imported_files.len()
+        );
+    }
+
+    Ok(context)
+}
+
 /// Language metadata from ABI-15
 #[derive(Debug, Clone)]
 pub struct LanguageMetadata {
@@ -95,6 +95,21 @@ pub trait LanguageParser: Send + Sync {
         Vec::new()
     }
 
+    /// Find the type of the receiver variable at each method call, for
+    /// languages whose assignments narrow a variable from that point on,
+    /// such as Go's `q = &JSONProcessor{}`
+    /// Returns tuples of (receiver, type, range), where the range is that
+    /// of the call and the type is `None` for a local of unknown type
+    ///
+    /// Default implementation returns empty - languages can override.
+    /// Calls not listed fall back to [`Self::find_variable_types`].
+    fn find_receiver_types<'a>(
+        &mut self,
+        _code: &'a str,
+    ) -> Vec<(&'a str, Option<&'a str>, Range)> {
+        Vec::new()
+    }
+
     /// Find inherent methods (methods defined directly on types)
     /// Returns tuples of (type_name, method_name, range)
     ///
@@ -106,6 +121,52 @@ pub trait LanguageParser: Send + Sync {
     fn find_inherent_methods(&mut self, _code: &str) -> Vec<(String, String, Range)> {
         Vec::new()
     }
+
+    /// Find field accesses on values of known type
+    /// Returns tuples of (context, qualified_field, range), e.g. a function
+    /// reading `Type.field`
+    ///
+    /// Default implementation returns empty - languages can override.
+    /// Returns owned strings because the qualified name is not in the source.
+    fn find_field_accesses(&mut self, _code: &str) -> Vec<(String, String, Range)> {
+        Vec::new()
+    }
+
+    /// Find jumps to labels, such as Go's `break outer`
+    /// Returns tuples of (function, label, range)
+    ///
+    /// Default implementation returns empty - languages can override.
+    fn find_label_jumps<'a>(&mut self, _code: &'a str) -> Vec<(&'a str, &'a str, Range)> {
+        Vec::new()
+    }
+
+    /// Find instantiations of generic functions and types, such as Go's
+    /// `NewMap[int, string]()`
+    /// Returns tuples of (context, generic, type_arguments, range), with
+    /// `None` arguments when they are inferred at the call
+    ///
+    /// Default implementation returns empty - languages can override.
+    fn find_instantiations<'a>(
+        &mut self,
+        _code: &'a str,
+    ) -> Vec<(&'a str, &'a str, Option<&'a str>, Range)> {
+        Vec::new()
+    }
+
+    /// Find variables of enclosing scopes captured by closures, such as a
+    /// Go function literal incrementing a local of its function
+    /// Returns tuples of (function, closure, variable, declaration, mutated),
+    /// where the range is that of the captured variable's declaration
+    ///
+    /// Default implementation returns empty - languages can override.
+    /// Returns owned closure names because anonymous functions have none in
+    /// the source.
+    fn find_captures<'a>(
+        &mut self,
+        _code: &'a str,
+    ) -> Vec<(&'a str, String, &'a str, Range, bool)> {
+        Vec::new()
+    }
 }
 
 /// Trait for creating language parsers
@@ -102,6 +102,24 @@ pub trait ResolutionScope: Send + Sync {
         self.resolve(to_name)
     }
 
+    /// [`Self::resolve_relationship`] from a known source symbol
+    ///
+    /// Languages whose resolution depends on the declaration a reference
+    /// comes from, not just its name, override this: in Go, a local of one
+    /// method shadows an imported package only in that method, not in a
+    /// same-named method of another type. The default ignores `from`.
+    fn resolve_relationship_from(
+        &self,
+        from: SymbolId,
+        from_name: &str,
+        to_name: &str,
+        kind: crate::RelationKind,
+        from_file: FileId,
+    ) -> Option<SymbolId> {
+        let _ = from;
+        self.resolve_relationship(from_name, to_name, kind, from_file)
+    }
+
     /// Populate import records into the resolution context
     ///
     /// Called during context building to load import statements from the index.
@@ -177,6 +195,19 @@ pub trait ResolutionScope: Send + Sync {
         }
     }
 
+    /// Check if a qualified member name is ambiguous
+    ///
+    /// Go promotes the methods and fields of embedded types; two embedded
+    /// types providing the same name at the same depth make `Outer.Name` an
+    /// error rather than a choice. The indexer then leaves the call
+    /// unresolved instead of falling back to the bare member name.
+    ///
+    /// # Default Behavior
+    /// Returns false - languages without promotion have no such names.
+    fn is_ambiguous_selector(&self, _qualified: &str) -> bool {
+        false
+    }
+
     /// Check if a relationship between two symbol kinds is valid
     ///
     /// This method defines which relationships are semantically valid for a language.
@@ -0,0 +1,293 @@
+//! Project-level configuration in `codanna.toml`
+//!
+//! `.codanna/settings.toml` holds per-workspace state written by `codanna
+//! init` and `add-dir`. `codanna.toml` sits at the project root, next to
+//! `go.mod`, and is meant to be committed: it says what the project indexes
+//! and how results are shown.
+//!
+//! ```toml
+//! include = ["cmd/**", "internal/**"]
+//! exclude = ["**/testdata/**", "**/*_mock.go"]
+//! build_tags = ["linux", "integration"]
+//! index_deps = false
+//! output = "json"
+//! stdlib = "builtin"
+//! implements_include_tests = false
+//! unchecked_errors_allow_blank = false
+//! ```
+//!
+//! - `include`: globs relative to the project root; when given, only
+//!   matching files are indexed
+//! - `exclude`: globs relative to the project root never indexed, even when
+//!   included
+//! - `build_tags`: tags satisfied when evaluating Go build constraints; when
+//!   given, Go files whose constraints they don't satisfy are not indexed
+//! - `index_deps`: whether `vendor/` directories are indexed (default true)
+//! - `output`: default output of commands taking `--json`, `text` or `json`
+//! - `stdlib`: `builtin` resolves well-known standard library interfaces
+//!   and constructors from a built-in table; `off` treats the standard
+//!   library as unknown
+//! - `implements_include_tests`: whether methods declared in `_test.go`
+//!   files count toward the method sets of types declared outside them when
+//!   checking which interfaces a type implements (default false, matching
+//!   the production build)
+//! - `unchecked_errors_allow_blank`: whether `analyze unchecked-errors`
+//!   accepts errors explicitly assigned to `_` (default false)
+//!
+//! The file is found by walking up from the workspace root (or the current
+//! directory). Precedence, highest first: CLI flags, `codanna.toml`,
+//! `.codanna/settings.toml`, built-in defaults. `--json` forces JSON output
+//! and `--no-json` turns `output = "json"` back to text.
+//! Unknown keys are reported as warnings and otherwise ignored.
+
+use ignore::overrides::{Override, OverrideBuilder};
+use serde::{Deserialize, Serialize};
+use std::path::{Path, PathBuf};
+
+/// File name of the project configuration
+pub const PROJECT_CONFIG_FILE: &str = "codanna.toml";
+
+/// Keys understood in `codanna.toml`
+const KNOWN_KEYS: &[&str] = &[
+    "include",
+    "exclude",
+    "build_tags",
+    "index_deps",
+    "output",
+    "stdlib",
+    "implements_include_tests",
+    "unchecked_errors_allow_blank",
+];
+
+/// Default output format of commands taking `--json`
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum DefaultOutput {
+    #[default]
+    Text,
+    Json,
+}
+
+/// How standard library types are resolved
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum StdlibResolution {
+    /// Well-known interfaces and constructors from the built-in table
+    #[default]
+    Builtin,
+    /// No standard library knowledge
+    Off,
+}
+
+/// Settings read from `codanna.toml`
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(default)]
+pub struct ProjectConfig {
+    pub include: Vec<String>,
+    pub exclude: Vec<String>,
+    pub build_tags: Vec<String>,
+    pub index_deps: bool,
+    pub output: DefaultOutput,
+    pub stdlib: StdlibResolution,
+    pub implements_include_tests: bool,
+    pub unchecked_errors_allow_blank: bool,
+
+    /// File the configuration was read from, if any
+    #[serde(skip)]
+    pub path: Option<PathBuf>,
+}
+
+impl Default for ProjectConfig {
+    fn default() -> Self {
+        Self {
+            include: Vec::new(),
+            exclude: Vec::new(),
+            build_tags: Vec::new(),
+            index_deps: true,
+            output: DefaultOutput::Text,
+            stdlib: StdlibResolution::Builtin,
+            implements_include_tests: false,
+            unchecked_errors_allow_blank: false,
+            path: None,
+        }
+    }
+}
+
+impl ProjectConfig {
+    /// Parse `codanna.toml` content, returning warnings for unknown keys
+    pub fn parse(content: &str) -> Result<(Self, Vec<String>), String> {
+        let table: toml::Table = toml::from_str(content).map_err(|e| e.to_string())?;
+        let warnings = table
+            .keys()
+            .filter(|key| !KNOWN_KEYS.contains(&key.as_str()))
+            .map(|key| format!("unknown key '{key}' in {PROJECT_CONFIG_FILE} is ignored"))
+            .collect();
+        let config = toml::Value::Table(table)
+            .try_into::<Self>()
+            .map_err(|e| e.to_string())?;
+        Ok((config, warnings))
+    }
+
+    /// Read and parse a `codanna.toml` file
+    pub fn load(path: &Path) -> Result<(Self, Vec<String>), String> {
+        let content = std::fs::read_to_string(path)
+            .map_err(|e| format!("Cannot read {}: {e}", path.display()))?;
+        let (mut config, warnings) =
+            Self::parse(&content).map_err(|e| format!("Invalid {}: {e}", path.display()))?;
+        config.path = Some(path.to_path_buf());
+        Ok((config, warnings))
+    }
+
+    /// Nearest `codanna.toml` in `start` or its parents
+    pub fn find(start: &Path) -> Option<PathBuf> {
+        start
+            .ancestors()
+            .map(|dir| dir.join(PROJECT_CONFIG_FILE))
+            .find(|candidate| candidate.is_file())
+    }
+
+    /// Load the project configuration above `start`, printing warnings and
+    /// errors to stderr; defaults when there is none or it is invalid
+    pub fn discover(start: &Path) -> Self {
+        let Some(path) = Self::find(start) else {
+            return Self::default();
+        };
+        match Self::load(&path) {
+            Ok((config, warnings)) => {
+                for warning in warnings {
+                    eprintln!("Warning: {warning}");
+                }
+                config
+            }
+            Err(e) => {
+                eprintln!("Warning: {e}");
+                eprintln!("Using default project configuration.");
+                Self::default()
+            }
+        }
+    }
+
+    /// Directory holding the configuration, which globs are relative to
+    pub fn root(&self) -> Option<&Path> {
+        self.path.as_deref().and_then(Path::parent)
+    }
+
+    /// Compiled include and exclude globs
+    pub fn file_filter(&self) -> Result<FileFilter, String> {
+        let root = self
+            .root()
+            .map(Path::to_path_buf)
+            .unwrap_or_else(|| std::env::current_dir().unwrap_or_default());
+        let build = |globs: &[String]| -> Result<Option<Override>, String> {
+            if globs.is_empty() {
+                return Ok(None);
+            }
+            let mut builder = OverrideBuilder::new(&root);
+            for glob in globs {
+                builder
+                    .add(glob)
+                    .map_err(|e| format!("Invalid glob '{glob}': {e}"))?;
+            }
+            builder.build().map(Some).map_err(|e| e.to_string())
+        };
+        Ok(FileFilter {
+            include: build(&self.include)?,
+            exclude: build(&self.exclude)?,
+            root,
+        })
+    }
+}
+
+/// Include and exclude globs of a project configuration
+#[derive(Debug, Clone)]
+pub struct FileFilter {
+    root: PathBuf,
+    include: Option<Override>,
+    exclude: Option<Override>,
+}
+
+impl FileFilter {
+    /// Whether a file is to be indexed
+    pub fn allows(&self, path: &Path) -> bool {
+        let absolute = if path.is_absolute() {
+            path.to_path_buf()
+        } else {
+            std::env::current_dir().unwrap_or_default().join(path)
+        };
+        let Ok(relative) = absolute.strip_prefix(&self.root) else {
+            // Outside the project: only an include list can rule it out
+            return self.include.is_none();
+        };
+        let included = self
+            .include
+            .as_ref()
+            .is_none_or(|globs| globs.matched(relative, false).is_whitelist());
+        let excluded = self
+            .exclude
+            .as_ref()
+            .is_some_and(|globs| globs.matched(relative, false).is_whitelist());
+        included && !excluded
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_parse_project_config_with_unknown_keys() {
+        let (config, warnings) = ProjectConfig::parse(
+            r#"
+include = ["internal/**"]
+exclude = ["**/testdata/**"]
+build_tags = ["linux"]
+index_deps = false
+output = "json"
+stdlib = "off"
+implements_include_tests = true
+unchecked_errors_allow_blank = true
+colour = "always"
+"#,
+        )
+        .unwrap();
+        assert_eq!(config.include, vec!["internal/**"]);
+        assert_eq!(config.build_tags, vec!["linux"]);
+        assert!(!config.index_deps);
+        assert_eq!(config.output, DefaultOutput::Json);
+        assert_eq!(config.stdlib, StdlibResolution::Off);
+        assert!(config.implements_include_tests);
+        assert!(config.unchecked_errors_allow_blank);
+        assert_eq!(
+            warnings,
+            vec!["unknown key 'colour' in codanna.toml is ignored"]
+        );
+
+        let (defaults, warnings) = ProjectConfig::parse("").unwrap();
+        assert_eq!(defaults, ProjectConfig::default());
+        assert!(warnings.is_empty());
+        assert!(ProjectConfig::parse("output = \"yaml\"").is_err());
+    }
+
+    #[test]
+    fn test_find_and_filter_relative_to_project_root() {
+        let temp_dir = TempDir::new().unwrap();
+        let root = temp_dir.path();
+        std::fs::write(
+            root.join(PROJECT_CONFIG_FILE),
+            "include = [\"internal/**\"]\nexclude = [\"**/testdata/**\"]\n",
+        )
+        .unwrap();
+        let nested = root.join("internal/models");
+        std::fs::create_dir_all(&nested).unwrap();
+
+        let path = ProjectConfig::find(&nested).unwrap();
+        assert_eq!(path, root.join(PROJECT_CONFIG_FILE));
+        let (config, _) = ProjectConfig::load(&path).unwrap();
+        let filter = config.file_filter().unwrap();
+
+        assert!(filter.allows(&root.join("internal/models/user.go")));
+        assert!(!filter.allows(&root.join("internal/models/testdata/user.go")));
+        assert!(!filter.allows(&root.join("cmd/main.go")));
+    }
+}
@@ -24,6 +24,21 @@ pub struct Relationship {
     pub metadata: Option<RelationshipMetadata>,
 }
 
+/// Context prefix of the type arguments of an instantiation
+const TYPE_ARGUMENTS_CONTEXT: &str = "type_arguments:";
+
+/// Type arguments recorded for an instantiation that infers them
+pub const INFERRED_TYPE_ARGUMENTS: &str = "inferred";
+
+/// Context prefix of a variable captured by a closure
+const CAPTURE_CONTEXT: &str = "captured_by:";
+
+/// Context prefix of a relationship produced by a custom extractor
+const CUSTOM_KIND_CONTEXT: &str = "custom:";
+
+/// Context prefix of an integer literal standing for an enum constant
+const ENUM_LITERAL_CONTEXT: &str = "enum_literal:";
+
 #[derive(Debug, Clone, PartialEq, Serialize, Deserialize, Default)]
 pub struct RelationshipMetadata {
     pub line: Option<u32>,
@@ -124,6 +139,64 @@ impl RelationshipMetadata {
         self.context = Some(context.into());
         self
     }
+
+    /// Record the type arguments of a generic instantiation, or that they
+    /// were inferred when `None`
+    pub fn with_type_arguments(self, type_arguments: Option<&str>) -> Self {
+        self.with_context(format!(
+            "{TYPE_ARGUMENTS_CONTEXT}{}",
+            type_arguments.unwrap_or(INFERRED_TYPE_ARGUMENTS)
+        ))
+    }
+
+    /// Type arguments recorded by [`Self::with_type_arguments`]; `inferred`
+    /// when the call site left them out
+    pub fn type_arguments(&self) -> Option<&str> {
+        self.context
+            .as_deref()?
+            .strip_prefix(TYPE_ARGUMENTS_CONTEXT)
+    }
+
+    /// Record that `closure` captures the referenced variable, and whether
+    /// it writes to it or only reads it
+    pub fn with_capture(self, closure: &str, mutated: bool) -> Self {
+        let access = if mutated { "mutated" } else { "read" };
+        self.with_context(format!("{CAPTURE_CONTEXT}{closure}:{access}"))
+    }
+
+    /// Closure and whether it mutates the variable, as recorded by
+    /// [`Self::with_capture`]
+    pub fn capture(&self) -> Option<(&str, bool)> {
+        let capture = self.context.as_deref()?.strip_prefix(CAPTURE_CONTEXT)?;
+        let (closure, access) = capture.rsplit_once(':')?;
+        Some((closure, access == "mutated"))
+    }
+
+    /// Record the free-form kind of a relationship produced by a custom
+    /// extractor, e.g. `reads-env-var`
+    pub fn with_custom_kind(self, kind: &str) -> Self {
+        self.with_context(format!("{CUSTOM_KIND_CONTEXT}{kind}"))
+    }
+
+    /// Kind recorded by [`Self::with_custom_kind`]
+    pub fn custom_kind(&self) -> Option<&str> {
+        self.context.as_deref()?.strip_prefix(CUSTOM_KIND_CONTEXT)
+    }
+
+    /// Record that the reference is the integer literal `value` written in
+    /// place of the referenced enum constant
+    pub fn with_enum_literal(self, value: i64) -> Self {
+        self.with_context(format!("{ENUM_LITERAL_CONTEXT}{value}"))
+    }
+
+    /// Literal recorded by [`Self::with_enum_literal`]
+    pub fn enum_literal(&self) -> Option<i64> {
+        self.context
+            .as_deref()?
+            .strip_prefix(ENUM_LITERAL_CONTEXT)?
+            .parse()
+            .ok()
+    }
 }
 
 pub struct RelationshipEdge {
@@ -175,6 +248,19 @@ mod tests {
         assert_eq!(meta.context.as_deref(), Some("inside main function"));
     }
 
+    #[test]
+    fn test_capture_metadata() {
+        let mutated = RelationshipMetadata::new().with_capture("CreateCounter.func1", true);
+        assert_eq!(mutated.capture(), Some(("CreateCounter.func1", true)));
+
+        let read = RelationshipMetadata::new().with_capture("Run.func1.1", false);
+        assert_eq!(read.capture(), Some(("Run.func1.1", false)));
+        assert_eq!(read.type_arguments(), None);
+
+        let other = RelationshipMetadata::new().with_type_arguments(Some("int"));
+        assert_eq!(other.capture(), None);
+    }
+
     #[test]
     fn test_relation_kind_inverse() {
         assert_eq!(RelationKind::Calls.inverse(), RelationKind::CalledBy);
@@ -6,7 +6,9 @@ use crate::io::{
 };
 use crate::symbol::context::SymbolContext;
 use crate::{SimpleIndexer, Symbol};
+use serde::Serialize;
 use std::borrow::Cow;
+use std::fmt;
 
 /// Execute retrieve symbol command
 pub fn retrieve_symbol(
@@ -29,8 +31,8 @@ pub fn retrieve_symbol(
             vec![]
         }
     } else {
-        // Name-based lookup
-        indexer.find_symbols_by_name(name, language)
+        // Name-based lookup, optionally qualified by the owning type
+        indexer.find_symbols_by_qualified_name(name, language)
     };
 
     if symbols.is_empty() {
@@ -455,6 +457,7 @@ pub fn retrieve_search(
         "module" => Some(crate::SymbolKind::Module),
         "typealias" => Some(crate::SymbolKind::TypeAlias),
         "enum" => Some(crate::SymbolKind::Enum),
+        "label" => Some(crate::SymbolKind::Label),
         _ => {
             eprintln!("Warning: Unknown symbol kind '{k}', ignoring filter");
             None
@@ -689,6 +692,8 @@ pub fn retrieve_describe(
         symbol: symbol.clone(),
         file_path,
         relationships: Default::default(),
+        receiver: SymbolContext::method_receiver(&symbol),
+        alias_target: SymbolContext::alias_target(&symbol),
     };
 
     // Get calls for this specific symbol
@@ -708,6 +713,10 @@ pub fn retrieve_describe(
     if let Some(defines) = deps.get(&crate::RelationKind::Defines) {
         context.relationships.defines = Some(defines.clone());
     }
+    let promoted = indexer.get_promoted_methods(&symbol);
+    if !promoted.is_empty() {
+        context.relationships.promoted = Some(promoted);
+    }
 
     // Load implementations (for traits/interfaces)
     use crate::SymbolKind;
@@ -747,3 +756,1409 @@ pub fn retrieve_describe(
         }
     }
 }
+
+/// Execute retrieve methods command
+///
+/// Methods are matched by receiver type within the type's package, so methods
+/// of unrelated types that share an underlying type (two `int`-based enums,
+/// for example) are never mixed in. Methods declared in `_test.go` files are
+/// left out unless `include_tests` is set.
+pub fn retrieve_methods(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    language: Option<&str>,
+    include_tests: bool,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::SymbolKind;
+    use crate::parsing::go::receiver_type_from_signature;
+    use crate::symbol::context::ContextIncludes;
+
+    let mut output = OutputManager::new(format);
+
+    let type_symbols: Vec<Symbol> = indexer
+        .find_symbols_by_name(type_name, language)
+        .into_iter()
+        .filter(|s| {
+            matches!(
+                s.kind,
+                SymbolKind::Struct
+                    | SymbolKind::Interface
+                    | SymbolKind::TypeAlias
+                    | SymbolKind::Enum
+                    | SymbolKind::Class
+            )
+        })
+        .collect();
+
+    let mut methods: Vec<Symbol> = indexer
+        .get_all_symbols()
+        .into_iter()
+        .filter(|m| m.kind == SymbolKind::Method)
+        .filter(|m| include_tests || !m.file_path.ends_with("_test.go"))
+        .filter(|m| {
+            let receiver = m
+                .signature
+                .as_deref()
+                .and_then(receiver_type_from_signature);
+            type_symbols
+                .iter()
+                .any(|t| receiver == Some(t.name.as_str()) && t.module_path == m.module_path)
+        })
+        .collect();
+    methods.sort_by(|a, b| {
+        (a.file_path.as_ref(), a.range.start_line).cmp(&(b.file_path.as_ref(), b.range.start_line))
+    });
+
+    let methods_with_path: Vec<SymbolContext> = methods
+        .into_iter()
+        .filter_map(|symbol| indexer.get_symbol_context(symbol.id, ContextIncludes::CALLERS))
+        .collect();
+
+    let unified = UnifiedOutputBuilder::items(methods_with_path, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(type_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve errors command
+///
+/// Lists the Go types satisfying `error` with their error-code enums. The
+/// check runs over the syntax trees of the indexed files, see
+/// [`crate::parsing::go::analysis::find_error_types`].
+pub fn retrieve_errors(indexer: &SimpleIndexer, format: OutputFormat) -> ExitCode {
+    use crate::parsing::go::analysis::{EnumTable, find_error_types};
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let enums = EnumTable::build(&files);
+    let errors = find_error_types(&files, &enums);
+
+    let unified = UnifiedOutputBuilder::items(errors, EntityType::Class)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed("errors")),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve enum command
+///
+/// Lists the constants declared with the Go type `type_name`, in value order.
+/// A `package.Type` name restricts the lookup to one package; otherwise every
+/// package declaring an enum of that name is listed.
+pub fn retrieve_enum(indexer: &SimpleIndexer, type_name: &str, format: OutputFormat) -> ExitCode {
+    let mut output = OutputManager::new(format);
+
+    let matches = enum_types(indexer, type_name);
+    if matches.is_empty() {
+        return write_not_found(&mut output, EntityType::Class, type_name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(matches, EntityType::Class)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(type_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Enums of the indexed Go files named `type_name`, optionally `package.Type`
+fn enum_types(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+) -> Vec<crate::parsing::go::analysis::EnumType> {
+    use crate::parsing::go::analysis::EnumTable;
+
+    let files = crate::analyze::load_go_files(indexer);
+    let enums = EnumTable::build(&files);
+    match type_name.rsplit_once('.') {
+        Some((package, name)) => enums.get(package, name).into_iter().cloned().collect(),
+        None => enums.find(type_name).into_iter().cloned().collect(),
+    }
+}
+
+/// Execute retrieve returns command
+///
+/// Lists the Go functions and methods with `type_name` among their results,
+/// which finds the constructors and factories of a type. Without `exact`,
+/// pointer, slice, map and channel forms of the type match too.
+pub fn retrieve_returns(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    exact: bool,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::{TypeQuery, find_functions_returning};
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let functions = find_functions_returning(&files, &TypeQuery::parse(type_name, exact));
+    if functions.is_empty() {
+        return write_not_found(&mut output, EntityType::Function, type_name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(functions, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(type_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve accepts command
+///
+/// Lists the Go functions and methods with a parameter of type `type_name`,
+/// the consumers of a type. Without `exact`, pointer, slice, map and channel
+/// forms match too, as do interface parameters the type satisfies.
+pub fn retrieve_accepts(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    exact: bool,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::{TypeQuery, find_functions_accepting};
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let functions = find_functions_accepting(
+        &files,
+        &TypeQuery::parse(type_name, exact),
+        indexer.settings().project.stdlib,
+    );
+    if functions.is_empty() {
+        return write_not_found(&mut output, EntityType::Function, type_name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(functions, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(type_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve conversions command
+///
+/// Lists the Go conversion expressions producing or consuming `type_name`,
+/// such as `AuthToken(raw)` and `string(token)`, with both sides' types.
+pub fn retrieve_conversions(
+    indexer: &SimpleIndexer,
+    type_name: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_type_conversions;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let conversions = find_type_conversions(&files, type_name);
+    if conversions.is_empty() {
+        return write_not_found(&mut output, EntityType::Finding, type_name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(conversions, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(type_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve higher-order-args command
+///
+/// Lists the functions passed to the Go function or method `function_name`
+/// at each call site, bound to its function-typed parameters.
+pub fn retrieve_higher_order_args(
+    indexer: &SimpleIndexer,
+    function_name: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_higher_order_args;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let args = find_higher_order_args(&files, Some(function_name));
+    if args.is_empty() {
+        return write_not_found(&mut output, EntityType::Function, function_name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(args, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(function_name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// A generic function or type instantiated by a declaration
+#[derive(Debug, Clone, Serialize)]
+pub struct InstantiationSite {
+    /// Generic function or type instantiated
+    pub generic: String,
+    /// Declaration holding the instantiation
+    pub user: String,
+    /// Type arguments as written, `inferred` when the call leaves them out
+    pub type_arguments: String,
+    pub file: String,
+    /// 1-based line of the instantiation
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub line: Option<u32>,
+}
+
+impl fmt::Display for InstantiationSite {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.file)?;
+        if let Some(line) = self.line {
+            write!(f, ":{line}")?;
+        }
+        write!(f, "  {} ", self.user)?;
+        if self.type_arguments == crate::relationship::INFERRED_TYPE_ARGUMENTS {
+            write!(f, "{} (inferred)", self.generic)
+        } else {
+            write!(f, "{}[{}]", self.generic, self.type_arguments)
+        }
+    }
+}
+
+/// Execute retrieve instantiations command
+///
+/// Lists the sites instantiating the generic function or type `name`, with
+/// the type arguments stored on each reference. A type's instantiations
+/// include those of the generic functions returning it, so
+/// `NewGenericContainer[int, string]()` instantiates `GenericContainer`. With
+/// `argument`, only sites whose type arguments mention that type are listed.
+pub fn retrieve_instantiations(
+    indexer: &SimpleIndexer,
+    name: &str,
+    argument: Option<&str>,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::SymbolKind;
+    use crate::parsing::go::analysis::{TypeQuery, find_functions_returning};
+    use crate::parsing::go::type_parameters_from_signature;
+
+    let mut output = OutputManager::new(format);
+
+    let mut generics = indexer.find_symbols_by_name(name, None);
+    if generics.iter().any(|s| {
+        matches!(
+            s.kind,
+            SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias
+        )
+    }) {
+        let files = crate::analyze::load_go_files(indexer);
+        for function in find_functions_returning(&files, &TypeQuery::parse(name, false)) {
+            if type_parameters_from_signature(&function.signature).is_empty() {
+                continue;
+            }
+            generics.extend(
+                indexer
+                    .find_symbols_by_name(&function.name, None)
+                    .into_iter()
+                    .filter(|s| {
+                        s.kind == SymbolKind::Function
+                            && s.signature.as_deref() == Some(function.signature.as_str())
+                    }),
+            );
+        }
+    }
+
+    let mentions = |type_arguments: &str, argument: &str| {
+        type_arguments
+            .split(|c: char| !(c.is_alphanumeric() || c == '_' || c == '.'))
+            .any(|word| word == argument)
+    };
+    let mut sites = Vec::new();
+    for generic in &generics {
+        for (user, metadata) in indexer.get_instantiating_symbols_with_metadata(generic.id) {
+            let Some(type_arguments) = metadata.type_arguments() else {
+                continue;
+            };
+            if argument.is_some_and(|argument| !mentions(type_arguments, argument)) {
+                continue;
+            }
+            sites.push(InstantiationSite {
+                generic: generic.name.to_string(),
+                user: user.name.to_string(),
+                type_arguments: type_arguments.to_string(),
+                file: user.file_path.to_string(),
+                // Stored rows are 0-based
+                line: metadata.line.map(|line| line + 1),
+            });
+        }
+    }
+    sites.sort_by(|a, b| (&a.file, a.line, &a.generic).cmp(&(&b.file, b.line, &b.generic)));
+    if sites.is_empty() {
+        return write_not_found(&mut output, EntityType::Function, name);
+    }
+
+    let unified = UnifiedOutputBuilder::items(sites, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve mutations command
+///
+/// Lists the assignments, compound assignments and `++`/`--` statements
+/// writing to the Go package-level variable `variable`, in every file of its
+/// package and, for exported variables, in the packages importing it.
+pub fn retrieve_mutations(
+    indexer: &SimpleIndexer,
+    variable: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_variable_mutations;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let Some(mutations) = find_variable_mutations(&files, variable) else {
+        return write_not_found(&mut output, EntityType::Variable, variable);
+    };
+
+    let unified = UnifiedOutputBuilder::items(mutations, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(variable)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve import-users command
+///
+/// Lists the Go functions, per file, that use symbols of the package imported
+/// as `import_path`, with the symbols each references.
+pub fn retrieve_import_users(
+    indexer: &SimpleIndexer,
+    import_path: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_import_users;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let Some(users) = find_import_users(&files, import_path) else {
+        return write_not_found(&mut output, EntityType::Module, import_path);
+    };
+
+    let unified = UnifiedOutputBuilder::items(users, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(import_path)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve imports command
+///
+/// Lists the imports of a Go file, with blank imports listed after the
+/// others as side-effect imports, each with the `init` functions it runs.
+pub fn retrieve_imports(indexer: &SimpleIndexer, file: &str, format: OutputFormat) -> ExitCode {
+    use crate::parsing::go::analysis::find_file_imports;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let Some(imports) = find_file_imports(&files, file) else {
+        return write_not_found(&mut output, EntityType::Module, file);
+    };
+
+    let unified = UnifiedOutputBuilder::items(imports, EntityType::Module)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(file)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve anon-funcs command
+///
+/// Lists the function literals of a function, or of every function in a
+/// file, with their synthetic names and captured variables.
+pub fn retrieve_anon_funcs(
+    indexer: &SimpleIndexer,
+    target: &str,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_anonymous_functions;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let Some(literals) = find_anonymous_functions(&files, target) else {
+        return write_not_found(&mut output, EntityType::Function, target);
+    };
+
+    let unified = UnifiedOutputBuilder::items(literals, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(target)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve todos command
+///
+/// Lists the comment markers (`TODO`, `FIXME`, ...) of the indexed Go files
+/// with the symbol each belongs to.
+pub fn retrieve_todos<S: AsRef<str>>(
+    indexer: &SimpleIndexer,
+    markers: &[S],
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_todos;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let todos = find_todos(&files, markers);
+
+    let unified = UnifiedOutputBuilder::items(todos, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed("todos")),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Execute retrieve generators command
+///
+/// Lists the `go:generate` directives of the indexed Go files, or of one
+/// file, with the symbol each sits above.
+pub fn retrieve_generators(
+    indexer: &SimpleIndexer,
+    file: Option<&str>,
+    format: OutputFormat,
+) -> ExitCode {
+    use crate::parsing::go::analysis::find_generate_directives;
+
+    let mut output = OutputManager::new(format);
+
+    let files = crate::analyze::load_go_files(indexer);
+    let query = file.unwrap_or("generators");
+    let Some(directives) = find_generate_directives(&files, file) else {
+        return write_not_found(&mut output, EntityType::Module, query);
+    };
+
+    let unified = UnifiedOutputBuilder::items(directives, EntityType::Finding)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(query)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Write a not-found result for `query`
+fn write_not_found(output: &mut OutputManager, entity_type: EntityType, query: &str) -> ExitCode {
+    let unified = UnifiedOutput {
+        status: OutputStatus::NotFound,
+        entity_type,
+        count: 0,
+        data: OutputData::<SymbolContext>::Empty,
+        metadata: Some(OutputMetadata {
+            query: Some(Cow::Borrowed(query)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        }),
+        guidance: None,
+        exit_code: ExitCode::NotFound,
+    };
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Look up exactly one symbol by name or `symbol_id:N`
+///
+/// Writes a not-found result or an ambiguity listing and returns the exit
+/// code when the lookup doesn't yield a single symbol.
+fn find_single_symbol(
+    output: &mut OutputManager,
+    indexer: &SimpleIndexer,
+    name: &str,
+    language: Option<&str>,
+    entity_type: EntityType,
+    command: &str,
+) -> Result<Symbol, ExitCode> {
+    let symbols: Vec<Symbol> = if let Some(id_str) = name.strip_prefix("symbol_id:") {
+        match id_str.parse::<u32>() {
+            Ok(id) => indexer
+                .get_symbol(crate::SymbolId(id))
+                .into_iter()
+                .collect(),
+            Err(_) => {
+                eprintln!("Invalid symbol_id format: {id_str}");
+                return Err(ExitCode::GeneralError);
+            }
+        }
+    } else {
+        indexer.find_symbols_by_name(name, language)
+    };
+
+    if symbols.is_empty() {
+        return Err(write_not_found(output, entity_type, name));
+    }
+
+    if symbols.len() > 1 {
+        // AMBIGUOUS - return error with list of symbol IDs
+        eprintln!(
+            "Ambiguous: found {} symbol(s) named '{}':",
+            symbols.len(),
+            name
+        );
+        for (i, sym) in symbols.iter().take(10).enumerate() {
+            eprintln!(
+                "  {}. symbol_id:{} - {:?} at {}:{}",
+                i + 1,
+                sym.id.value(),
+                sym.kind,
+                sym.file_path,
+                sym.range.start_line + 1
+            );
+        }
+        if symbols.len() > 10 {
+            eprintln!("  ... and {} more", symbols.len() - 10);
+        }
+        eprintln!("\nUse: codanna retrieve {command} symbol_id:<id>");
+        return Err(ExitCode::GeneralError);
+    }
+
+    Ok(symbols.into_iter().next().unwrap())
+}
+
+/// A call made inside a function body
+#[derive(Debug, Clone, Serialize)]
+pub struct CalleeSite {
+    /// Callee as written, e.g. `models.NewUser` or `user.Role`
+    pub callee: String,
+    /// 1-based line of the call
+    pub line: u32,
+    pub column: u16,
+    /// Whether the call was resolved to an indexed symbol
+    pub resolved: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub symbol_id: Option<u32>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub location: Option<String>,
+}
+
+impl fmt::Display for CalleeSite {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}:{}  {}", self.line, self.column, self.callee)?;
+        match (&self.location, self.symbol_id) {
+            (Some(location), Some(id)) => write!(f, "  -> {location} [symbol_id:{id}]"),
+            _ => write!(f, "  (unresolved)"),
+        }
+    }
+}
+
+/// Execute retrieve callees command
+///
+/// Lists every call inside the function body in source order, including calls
+/// the indexer couldn't resolve, which `retrieve calls` leaves out.
+pub fn retrieve_callees(
+    indexer: &SimpleIndexer,
+    function: &str,
+    language: Option<&str>,
+    format: OutputFormat,
+) -> ExitCode {
+    let mut output = OutputManager::new(format);
+
+    let symbol = match find_single_symbol(
+        &mut output,
+        indexer,
+        function,
+        language,
+        EntityType::Function,
+        "callees",
+    ) {
+        Ok(symbol) => symbol,
+        Err(code) => return code,
+    };
+
+    let callees = match callee_sites(indexer, &symbol) {
+        Ok(callees) => callees,
+        Err(e) => {
+            eprintln!("{e}");
+            return ExitCode::GeneralError;
+        }
+    };
+
+    let unified = UnifiedOutputBuilder::items(callees, EntityType::Function)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(function)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Calls inside the body of `symbol`, in source order, with the indexed
+/// target of each call that resolved
+fn callee_sites(indexer: &SimpleIndexer, symbol: &Symbol) -> Result<Vec<CalleeSite>, String> {
+    use crate::parsing::ParserFactory;
+    use std::sync::Arc;
+
+    let content = std::fs::read_to_string(&*symbol.file_path)
+        .map_err(|e| format!("Error reading {}: {e}", symbol.file_path))?;
+
+    let factory = ParserFactory::new(Arc::new(indexer.settings().clone()));
+    let parser = std::path::Path::new(&*symbol.file_path)
+        .extension()
+        .and_then(|ext| ext.to_str())
+        .and_then(|ext| factory.get_language_for_extension(ext))
+        .and_then(|language_id| factory.create_parser_from_registry(language_id).ok());
+    let Some(mut parser) = parser else {
+        return Err(format!("No parser available for {}", symbol.file_path));
+    };
+
+    // Parsers report 0-based rows, as symbol ranges are
+    let in_body = |caller: &str, row: u32| {
+        caller == symbol.name.as_str()
+            && row >= symbol.range.start_line
+            && row <= symbol.range.end_line
+    };
+
+    let mut sites: Vec<(CalleeSite, String)> = Vec::new();
+    for (caller, callee, range) in parser.find_calls(&content) {
+        if in_body(caller, range.start_line) {
+            let short = callee.rsplit('.').next().unwrap_or(callee).to_string();
+            sites.push((
+                CalleeSite {
+                    callee: callee.to_string(),
+                    line: range.start_line + 1,
+                    column: range.start_column,
+                    resolved: false,
+                    symbol_id: None,
+                    location: None,
+                },
+                short,
+            ));
+        }
+    }
+    for call in parser.find_method_calls(&content) {
+        if in_body(call.caller.as_str(), call.range.start_line) {
+            let callee = match &call.receiver {
+                Some(receiver) => format!("{receiver}.{}", call.method_name),
+                None => call.method_name.clone(),
+            };
+            sites.push((
+                CalleeSite {
+                    callee,
+                    line: call.range.start_line + 1,
+                    column: call.range.start_column,
+                    resolved: false,
+                    symbol_id: None,
+                    location: None,
+                },
+                call.method_name.clone(),
+            ));
+        }
+    }
+    sites.sort_by_key(|(site, _)| (site.line, site.column));
+    sites.dedup_by(|a, b| a.0.line == b.0.line && a.0.column == b.0.column);
+
+    // Attach resolution status from the stored call relationships, preferring
+    // a target recorded at the same line
+    let resolved = indexer.get_called_functions_with_metadata(symbol.id);
+    let callees: Vec<CalleeSite> = sites
+        .into_iter()
+        .map(|(mut site, short)| {
+            let same_name = |target: &Symbol| target.name.as_str() == short;
+            let target = resolved
+                .iter()
+                .find(|(target, meta)| {
+                    same_name(target) && meta.as_ref().and_then(|m| m.line) == Some(site.line - 1)
+                })
+                .or_else(|| resolved.iter().find(|(target, _)| same_name(target)));
+            if let Some((target, _)) = target {
+                site.resolved = true;
+                site.symbol_id = Some(target.id.value());
+                site.location = Some(SymbolContext::symbol_location(target));
+            }
+            site
+        })
+        .collect();
+    Ok(callees)
+}
+
+/// Role of an entry in `retrieve range`
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum RangeRole {
+    /// A symbol defined in the range
+    Definition,
+    /// A function or method call
+    Call,
+    /// A type or package-qualified value used
+    Use,
+    /// A struct field accessed
+    Field,
+    /// A `break`, `continue` or `goto` naming a label
+    Jump,
+}
+
+impl fmt::Display for RangeRole {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(match self {
+            RangeRole::Definition => "definition",
+            RangeRole::Call => "call",
+            RangeRole::Use => "use",
+            RangeRole::Field => "field",
+            RangeRole::Jump => "jump",
+        })
+    }
+}
+
+/// A definition or reference inside a line range
+#[derive(Debug, Clone, Serialize)]
+pub struct RangeEntry {
+    pub role: RangeRole,
+    /// Symbol name, or the reference as written
+    pub name: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub kind: Option<String>,
+    /// 1-based lines, clipped to the requested range
+    pub line: u32,
+    pub end_line: u32,
+    /// Whether a definition extends beyond the requested range
+    #[serde(skip_serializing_if = "std::ops::Not::not")]
+    pub clipped: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub symbol_id: Option<u32>,
+    /// Full extent of the symbol defined or referenced
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub location: Option<String>,
+}
+
+impl fmt::Display for RangeEntry {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}  {:<10} {}", self.line, self.role, self.name)?;
+        if let Some(kind) = &self.kind {
+            write!(f, " ({kind})")?;
+        }
+        if self.clipped {
+            write!(f, " [clipped]")?;
+        }
+        match (&self.location, self.symbol_id) {
+            (Some(location), Some(id)) => write!(f, "  -> {location} [symbol_id:{id}]"),
+            _ => Ok(()),
+        }
+    }
+}
+
+/// Split `<file>:<start>-<end>` (or `<file>:<line>`) into the file and its
+/// 1-based inclusive line range
+fn parse_line_range(spec: &str) -> Option<(&str, u32, u32)> {
+    let (file, lines) = spec.rsplit_once(':')?;
+    let (start, end) = match lines.split_once('-') {
+        Some((start, end)) => (start.trim().parse().ok()?, end.trim().parse().ok()?),
+        None => {
+            let line = lines.trim().parse().ok()?;
+            (line, line)
+        }
+    };
+    (!file.is_empty() && start >= 1 && start <= end).then_some((file, start, end))
+}
+
+/// Execute retrieve range command
+///
+/// Lists the symbols defined in lines `start..=end` of a file and the
+/// references made there (calls, uses, field accesses), in source order.
+/// Definitions that straddle the range are clipped to it and flagged.
+pub fn retrieve_range(indexer: &SimpleIndexer, spec: &str, format: OutputFormat) -> ExitCode {
+    let mut output = OutputManager::new(format);
+
+    let Some((file, start, end)) = parse_line_range(spec) else {
+        eprintln!("Error: invalid range '{spec}'");
+        eprintln!("Usage: codanna retrieve range <file>:<startLine>-<endLine>");
+        return ExitCode::GeneralError;
+    };
+
+    let entries = match range_entries(indexer, file, start, end) {
+        Ok(Some(entries)) => entries,
+        Ok(None) => return write_not_found(&mut output, EntityType::Mixed, spec),
+        Err(e) => {
+            eprintln!("{e}");
+            return ExitCode::GeneralError;
+        }
+    };
+
+    let unified = UnifiedOutputBuilder::items(entries, EntityType::Mixed)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(spec)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+/// Definitions and references in lines `start..=end` of `file`, in source
+/// order, or `None` when the file has no indexed symbols
+fn range_entries(
+    indexer: &SimpleIndexer,
+    file: &str,
+    start: u32,
+    end: u32,
+) -> Result<Option<Vec<RangeEntry>>, String> {
+    use crate::SymbolKind;
+    use crate::parsing::ParserFactory;
+    use crate::symbol::ScopeContext;
+    use std::sync::Arc;
+
+    // Indexed paths may be stored absolute or relative to the workspace
+    let wanted = file.trim_start_matches("./");
+    let symbols: Vec<Symbol> = indexer
+        .get_all_symbols()
+        .into_iter()
+        .filter(|s| {
+            let path = s.file_path.trim_start_matches("./");
+            path == wanted || path.ends_with(&format!("/{wanted}"))
+        })
+        .collect();
+    let Some(file_path) = symbols.first().map(|s| s.file_path.to_string()) else {
+        return Ok(None);
+    };
+
+    let mut entries = Vec::new();
+    for symbol in &symbols {
+        let (first, last) = (symbol.range.start_line + 1, symbol.range.end_line + 1);
+        if first > end || last < start {
+            continue;
+        }
+        entries.push(RangeEntry {
+            role: RangeRole::Definition,
+            name: symbol.name.to_string(),
+            kind: Some(format!("{:?}", symbol.kind)),
+            line: first.max(start),
+            end_line: last.min(end),
+            clipped: first < start || last > end,
+            symbol_id: Some(symbol.id.value()),
+            location: Some(SymbolContext::symbol_location(symbol)),
+        });
+    }
+
+    let content = std::fs::read_to_string(&file_path)
+        .map_err(|e| format!("Error reading {file_path}: {e}"))?;
+    let factory = ParserFactory::new(Arc::new(indexer.settings().clone()));
+    let parser = std::path::Path::new(&file_path)
+        .extension()
+        .and_then(|ext| ext.to_str())
+        .and_then(|ext| factory.get_language_for_extension(ext))
+        .and_then(|language_id| factory.create_parser_from_registry(language_id).ok());
+    let Some(mut parser) = parser else {
+        return Err(format!("No parser available for {file_path}"));
+    };
+
+    // Reference ranges have 0-based rows, as symbol ranges do
+    let mut references: Vec<(RangeRole, String, u32)> = Vec::new();
+    for (_, callee, range) in parser.find_calls(&content) {
+        references.push((RangeRole::Call, callee.to_string(), range.start_line + 1));
+    }
+    for call in parser.find_method_calls(&content) {
+        let name = match &call.receiver {
+            Some(receiver) => format!("{receiver}.{}", call.method_name),
+            None => call.method_name.clone(),
+        };
+        references.push((RangeRole::Call, name, call.range.start_line + 1));
+    }
+    for (_, used, range) in parser.find_uses(&content) {
+        references.push((RangeRole::Use, used.to_string(), range.start_line + 1));
+    }
+    for (_, field, range) in parser.find_field_accesses(&content) {
+        references.push((RangeRole::Field, field, range.start_line + 1));
+    }
+
+    // Labels are scoped to their function; resolve jumps within the file
+    for (function, label, range) in parser.find_label_jumps(&content) {
+        let line = range.start_line + 1;
+        if line < start || line > end {
+            continue;
+        }
+        let target = symbols.iter().find(|s| {
+            s.kind == SymbolKind::Label
+                && *s.name == *label
+                && matches!(
+                    &s.scope_context,
+                    Some(ScopeContext::Local { parent_name: Some(parent), .. })
+                        if **parent == *function
+                )
+        });
+        entries.push(RangeEntry {
+            role: RangeRole::Jump,
+            name: label.to_string(),
+            kind: target.map(|s| format!("{:?}", s.kind)),
+            line,
+            end_line: line,
+            clipped: false,
+            symbol_id: target.map(|s| s.id.value()),
+            location: target.map(SymbolContext::symbol_location),
+        });
+    }
+
+    for (role, name, line) in references {
+        if line < start || line > end {
+            continue;
+        }
+        // Resolve by name, preferring a definition in the same file
+        let short = name.rsplit(['.', ':']).next().unwrap_or(&name);
+        let candidates = indexer.find_symbols_by_name(short, None);
+        let target = candidates
+            .iter()
+            .find(|s| *s.file_path == *file_path)
+            .or(candidates.first());
+        entries.push(RangeEntry {
+            role,
+            name,
+            kind: target.map(|s| format!("{:?}", s.kind)),
+            line,
+            end_line: line,
+            clipped: false,
+            symbol_id: target.map(|s| s.id.value()),
+            location: target.map(SymbolContext::symbol_location),
+        });
+    }
+    entries.sort_by(|a, b| (a.line, a.role, &a.name).cmp(&(b.line, b.role, &b.name)));
+    entries.dedup_by(|a, b| a.line == b.line && a.role == b.role && a.name == b.name);
+    Ok(Some(entries))
+}
+
+/// Source text of a symbol's declaration
+#[derive(Debug, Clone, Serialize)]
+pub struct SymbolSource {
+    pub name: String,
+    pub kind: String,
+    pub file: String,
+    /// 1-based lines of the source, doc comment included
+    pub line: u32,
+    pub end_line: u32,
+    pub symbol_id: u32,
+    pub source: String,
+}
+
+impl fmt::Display for SymbolSource {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        writeln!(
+            f,
+            "{}:{}-{} [symbol_id:{}]",
+            self.file, self.line, self.end_line, self.symbol_id
+        )?;
+        write!(f, "{}", self.source)
+    }
+}
+
+/// Byte span of a symbol's declaration in `content`, with its doc comment
+///
+/// Go files are parsed so the span is the complete declaration, closing
+/// brace included (see [`crate::parsing::go::analysis::declaration_span`]).
+/// Other languages use the indexed range, widened to whole lines and to the
+/// comment and attribute lines directly above it.
+fn symbol_source_span(symbol: &Symbol, content: &str) -> Option<std::ops::Range<usize>> {
+    use crate::parsing::go::analysis::{GoSourceFile, declaration_span};
+
+    let row = symbol.range.start_line as usize;
+    if symbol.file_path.ends_with(".go") {
+        let file = GoSourceFile::parse(&*symbol.file_path, content.to_string()).ok()?;
+        if let Some(span) = declaration_span(&file, row) {
+            return Some(span);
+        }
+    }
+
+    let offsets: Vec<usize> = std::iter::once(0)
+        .chain(content.match_indices('\n').map(|(i, _)| i + 1))
+        .collect();
+    let lines: Vec<&str> = content.lines().collect();
+    let mut first = row;
+    while first > 0 {
+        let above = lines.get(first - 1)?.trim_start();
+        if !["//", "#", "/*", "*"].iter().any(|p| above.starts_with(p)) {
+            break;
+        }
+        first -= 1;
+    }
+    let last = (symbol.range.end_line as usize).min(lines.len().checked_sub(1)?);
+    let start = *offsets.get(first)?;
+    let end = offsets[last] + lines[last].len();
+    (start <= end).then_some(start..end)
+}
+
+/// Execute retrieve source command
+///
+/// Prints the exact source of a symbol's declaration: signature and body of
+/// functions and methods, the full declaration of types, with the doc
+/// comment above them.
+pub fn retrieve_source(
+    indexer: &SimpleIndexer,
+    name: &str,
+    language: Option<&str>,
+    format: OutputFormat,
+) -> ExitCode {
+    let mut output = OutputManager::new(format);
+    let symbol = match find_single_symbol(
+        &mut output,
+        indexer,
+        name,
+        language,
+        EntityType::Symbol,
+        "source",
+    ) {
+        Ok(symbol) => symbol,
+        Err(code) => return code,
+    };
+
+    let content = match std::fs::read_to_string(&*symbol.file_path) {
+        Ok(content) => content,
+        Err(e) => {
+            eprintln!("Error reading {}: {e}", symbol.file_path);
+            return ExitCode::GeneralError;
+        }
+    };
+    let Some(span) = symbol_source_span(&symbol, &content) else {
+        eprintln!(
+            "Error: {} no longer matches the index; re-index and try again",
+            symbol.file_path
+        );
+        return ExitCode::IndexCorrupted;
+    };
+    let line = content[..span.start].matches('\n').count() as u32 + 1;
+    let source = content[span].to_string();
+    let end_line = line + source.matches('\n').count() as u32;
+
+    let entry = SymbolSource {
+        name: symbol.name.to_string(),
+        kind: format!("{:?}", symbol.kind),
+        file: symbol.file_path.to_string(),
+        line,
+        end_line,
+        symbol_id: symbol.id.value(),
+        source,
+    };
+    let unified = UnifiedOutputBuilder::items(vec![entry], EntityType::Symbol)
+        .with_metadata(OutputMetadata {
+            query: Some(Cow::Borrowed(name)),
+            tool: None,
+            timing_ms: None,
+            truncated: None,
+            extra: Default::default(),
+        })
+        .build();
+
+    match output.unified(unified) {
+        Ok(code) => code,
+        Err(e) => {
+            eprintln!("Error writing output: {e}");
+            ExitCode::GeneralError
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::config::Settings;
+    use std::sync::Arc;
+    use tempfile::TempDir;
+
+    /// Index the given Go fixtures into a scratch workspace
+    fn index_go_fixtures(names: &[&str]) -> (TempDir, SimpleIndexer) {
+        let temp_dir = TempDir::new().unwrap();
+        let mut paths = Vec::new();
+        for name in names {
+            let target = temp_dir.path().join(name);
+            std::fs::copy(format!("tests/fixtures/go/{name}"), &target).unwrap();
+            paths.push(target);
+        }
+
+        let settings = Settings {
+            workspace_root: Some(temp_dir.path().to_path_buf()),
+            index_path: temp_dir.path().join("index"),
+            ..Default::default()
+        };
+        let mut indexer = SimpleIndexer::with_settings(Arc::new(settings));
+        for path in &paths {
+            indexer.index_file(path).expect("Failed to index file");
+        }
+        (temp_dir, indexer)
+    }
+
+    #[test]
+    fn test_callee_lines_are_one_based() {
+        let (_temp_dir, indexer) = index_go_fixtures(&["basic.go"]);
+        let code = std::fs::read_to_string("tests/fixtures/go/basic.go").unwrap();
+        let line = code
+            .lines()
+            .position(|l| l.trim() == "initialize()")
+            .unwrap() as u32
+            + 1;
+
+        let main = indexer
+            .find_symbols_by_name("main", None)
+            .into_iter()
+            .find(|s| s.kind == crate::SymbolKind::Function)
+            .unwrap();
+        let callees = callee_sites(&indexer, &main).unwrap();
+        let initialize = callees
+            .iter()
+            .find(|site| site.callee == "initialize")
+            .unwrap();
+        assert_eq!(initialize.line, line);
+        assert!(initialize.resolved);
+    }
+
+    #[test]
+    fn test_enum_lists_constants_with_values() {
+        let (_temp_dir, indexer) = index_go_fixtures(&["complex.go"]);
+
+        let enums = enum_types(&indexer, "Status");
+        assert_eq!(enums.len(), 1);
+        assert_eq!(enums[0].package, "complex");
+        let constants: Vec<_> = enums[0]
+            .constants
+            .iter()
+            .map(|c| (c.name.as_str(), c.value))
+            .collect();
+        assert_eq!(
+            constants,
+            vec![
+                ("StatusUnknown", Some(0)),
+                ("StatusPending", Some(1)),
+                ("StatusRunning", Some(2)),
+                ("StatusCompleted", Some(3)),
+                ("StatusFailed", Some(4)),
+            ]
+        );
+
+        assert_eq!(enum_types(&indexer, "complex.Status").len(), 1);
+        assert!(enum_types(&indexer, "other.Status").is_empty());
+    }
+
+    #[test]
+    fn test_range_is_inclusive_and_clips_definitions() {
+        let (_temp_dir, indexer) = index_go_fixtures(&["basic.go"]);
+
+        // add spans 51-53; divide starts on the last line of the range
+        let entries = range_entries(&indexer, "basic.go", 51, 56)
+            .unwrap()
+            .unwrap();
+        let definitions: Vec<_> = entries
+            .iter()
+            .filter(|e| e.role == RangeRole::Definition && e.kind.as_deref() == Some("Function"))
+            .map(|e| (e.name.as_str(), e.line, e.end_line, e.clipped))
+            .collect();
+        assert_eq!(
+            definitions,
+            vec![("add", 51, 53, false), ("divide", 56, 56, true)]
+        );
+        assert!(
+            entries.iter().all(|e| (51..=56).contains(&e.line)),
+            "entries outside the range: {entries:?}"
+        );
+    }
+
+    #[test]
+    fn test_range_beyond_end_of_file_is_empty() {
+        let (_temp_dir, indexer) = index_go_fixtures(&["basic.go"]);
+
+        let entries = range_entries(&indexer, "basic.go", 500, 600)
+            .unwrap()
+            .unwrap();
+        assert!(entries.is_empty(), "unexpected entries: {entries:?}");
+        assert!(
+            range_entries(&indexer, "missing.go", 1, 10)
+                .unwrap()
+                .is_none()
+        );
+    }
+}
@@ -18,7 +18,7 @@ use tantivy::{
     Term,
     collector::TopDocs,
     directory::MmapDirectory,
-    query::{BooleanQuery, FuzzyTermQuery, Occur, Query, QueryParser, TermQuery},
+    query::{BooleanQuery, FuzzyTermQuery, Occur, Query, QueryParser, RegexQuery, TermQuery},
     schema::{
         FAST, Field, IndexRecordOption, NumericOptions, STORED, STRING, Schema, SchemaBuilder,
         TextFieldIndexing, TextOptions, Value,
@@ -1403,6 +1403,51 @@ impl DocumentIndex {
         Ok(symbols)
     }
 
+    /// Find symbols named `Owner.member` for a bare `member`, such as the
+    /// `Reader.Close` and `Writer.Close` methods and `Person.Name` fields
+    pub fn find_symbols_by_member_name(
+        &self,
+        member: &str,
+        language_filter: Option<&str>,
+    ) -> StorageResult<Vec<crate::Symbol>> {
+        let searcher = self.reader.searcher();
+
+        // The name field is raw, so a regex over whole names matches one
+        // qualifier in front of the member
+        let pattern = format!(r"[^.]+\.{}", regex::escape(member));
+        let member_query = RegexQuery::from_pattern(&pattern, self.schema.name)?;
+
+        let mut query_clauses = vec![
+            (Occur::Must, Box::new(member_query) as Box<dyn Query>),
+            (
+                Occur::Must,
+                Box::new(TermQuery::new(
+                    Term::from_field_text(self.schema.doc_type, "symbol"),
+                    IndexRecordOption::Basic,
+                )) as Box<dyn Query>,
+            ),
+        ];
+        if let Some(lang) = language_filter {
+            query_clauses.push((
+                Occur::Must,
+                Box::new(TermQuery::new(
+                    Term::from_field_text(self.schema.language, lang),
+                    IndexRecordOption::Basic,
+                )),
+            ));
+        }
+
+        let top_docs =
+            searcher.search(&BooleanQuery::new(query_clauses), &TopDocs::with_limit(100))?;
+        let mut symbols = Vec::new();
+        for (_score, doc_address) in top_docs {
+            let doc = searcher.doc::<Document>(doc_address)?;
+            symbols.push(self.document_to_symbol(&doc)?);
+        }
+
+        Ok(symbols)
+    }
+
     /// Find symbols by file ID
     pub fn find_symbols_by_file(&self, file_id: FileId) -> StorageResult<Vec<crate::Symbol>> {
         let searcher = self.reader.searcher();
@@ -1768,6 +1813,52 @@ impl DocumentIndex {
         Ok(())
     }
 
+    /// Delete the relationships of one kind from or to a symbol
+    pub fn delete_relationships_of_kind(
+        &self,
+        id: SymbolId,
+        kind: RelationKind,
+    ) -> StorageResult<()> {
+        let mut writer_lock = match self.writer.lock() {
+            Ok(lock) => lock,
+            Err(poisoned) => {
+                eprintln!(
+                    "Warning: Recovering from poisoned writer mutex in delete_relationships_of_kind"
+                );
+                poisoned.into_inner()
+            }
+        };
+        let writer = writer_lock.as_mut().ok_or(StorageError::NoActiveBatch)?;
+
+        for endpoint in [self.schema.from_symbol_id, self.schema.to_symbol_id] {
+            let query = BooleanQuery::new(vec![
+                (
+                    Occur::Must,
+                    Box::new(TermQuery::new(
+                        Term::from_field_text(self.schema.doc_type, "relationship"),
+                        IndexRecordOption::Basic,
+                    )) as Box<dyn Query>,
+                ),
+                (
+                    Occur::Must,
+                    Box::new(TermQuery::new(
+                        Term::from_field_u64(endpoint, id.0 as u64),
+                        IndexRecordOption::Basic,
+                    )),
+                ),
+                (
+                    Occur::Must,
+                    Box::new(TermQuery::new(
+                        Term::from_field_text(self.schema.relation_kind, &format!("{kind:?}")),
+                        IndexRecordOption::Basic,
+                    )),
+                ),
+            ]);
+            writer.delete_query(Box::new(query))?;
+        }
+        Ok(())
+    }
+
     /// Count symbols
     pub fn count_symbols(&self) -> StorageResult<usize> {
         let searcher = self.reader.searcher();
@@ -2131,6 +2222,106 @@ impl DocumentIndex {
         Ok(())
     }
 
+    /// Store a relationship found by a custom extractor from `from` to a
+    /// target named by the extractor, which need not be an indexed symbol
+    /// (an environment variable, say)
+    pub(crate) fn store_custom_target(
+        &self,
+        from: SymbolId,
+        target: &str,
+        file_path: &str,
+        metadata: &RelationshipMetadata,
+    ) -> StorageResult<()> {
+        let mut writer_lock = match self.writer.lock() {
+            Ok(lock) => lock,
+            Err(poisoned) => {
+                eprintln!("Warning: Recovering from poisoned writer mutex in store_custom_target");
+                poisoned.into_inner()
+            }
+        };
+        let writer = writer_lock.as_mut().ok_or(StorageError::NoActiveBatch)?;
+
+        let mut doc = Document::new();
+        doc.add_text(self.schema.doc_type, "custom_target");
+        doc.add_u64(self.schema.from_symbol_id, from.value() as u64);
+        doc.add_text(self.schema.name, target);
+        // Removed with the rest of the file's documents on re-index
+        doc.add_text(self.schema.file_path, file_path);
+        if let Some(line) = metadata.line {
+            doc.add_u64(self.schema.relation_line, line as u64);
+        }
+        if let Some(column) = metadata.column {
+            doc.add_u64(self.schema.relation_column, column as u64);
+        }
+        if let Some(ref context) = metadata.context {
+            doc.add_text(self.schema.relation_context, context.as_ref());
+        }
+
+        writer.add_document(doc)?;
+        Ok(())
+    }
+
+    /// Targets stored from a symbol by [`Self::store_custom_target`], with
+    /// their metadata
+    pub fn get_custom_targets_from(
+        &self,
+        from_id: SymbolId,
+    ) -> StorageResult<Vec<(String, RelationshipMetadata)>> {
+        let searcher = self.reader.searcher();
+        let query = BooleanQuery::from(vec![
+            (
+                Occur::Must,
+                Box::new(TermQuery::new(
+                    Term::from_field_text(self.schema.doc_type, "custom_target"),
+                    IndexRecordOption::Basic,
+                )) as Box<dyn Query>,
+            ),
+            (
+                Occur::Must,
+                Box::new(TermQuery::new(
+                    Term::from_field_u64(self.schema.from_symbol_id, from_id.0 as u64),
+                    IndexRecordOption::Basic,
+                )) as Box<dyn Query>,
+            ),
+        ]);
+
+        let top_docs = searcher.search(&query, &TopDocs::with_limit(1000))?;
+        let mut targets = Vec::new();
+
+        for (_score, doc_address) in top_docs {
+            let doc = searcher.doc::<Document>(doc_address)?;
+
+            let target = doc
+                .get_first(self.schema.name)
+                .and_then(|v| v.as_str())
+                .ok_or(StorageError::InvalidFieldValue {
+                    field: "name".to_string(),
+                    reason: "missing custom target".to_string(),
+                })?
+                .to_string();
+
+            let mut metadata = RelationshipMetadata::new();
+            metadata.line = doc
+                .get_first(self.schema.relation_line)
+                .and_then(|v| v.as_u64())
+                .map(|line| line as u32);
+            metadata.column = doc
+                .get_first(self.schema.relation_column)
+                .and_then(|v| v.as_u64())
+                .map(|column| column as u16);
+            if let Some(context) = doc
+                .get_first(self.schema.relation_context)
+                .and_then(|v| v.as_str())
+            {
+                metadata = metadata.with_context(context);
+            }
+
+            targets.push((target, metadata));
+        }
+
+        Ok(targets)
+    }
+
     /// Index a symbol from a Symbol struct
     pub fn index_symbol(&self, symbol: &crate::Symbol, file_path: &str) -> StorageResult<()> {
         self.add_document(
@@ -1,7 +1,8 @@
 //! Symbol context aggregation for comprehensive metadata display
 
 use crate::relationship::RelationshipMetadata;
-use crate::{Symbol, Visibility};
+use crate::symbol::MethodReceiver;
+use crate::{Symbol, SymbolKind, Visibility};
 use bitflags::bitflags;
 use serde::Serialize;
 use std::fmt;
@@ -15,6 +16,12 @@ pub struct SymbolContext {
     pub file_path: String,
     /// All relationships this symbol has
     pub relationships: SymbolRelationships,
+    /// Receiver of a method, when its declaration has one
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub receiver: Option<MethodReceiver>,
+    /// Type an alias stands for, as written, when the symbol is one
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub alias_target: Option<String>,
 }
 
 /// Container for all types of symbol relationships
@@ -26,12 +33,26 @@ pub struct SymbolRelationships {
     pub implemented_by: Option<Vec<Symbol>>,
     /// What methods/fields this symbol defines
     pub defines: Option<Vec<Symbol>>,
+    /// Methods this struct gets from the types it embeds
+    pub promoted: Option<Vec<PromotedMethod>>,
     /// What this symbol calls (with relationship metadata including call site location)
     pub calls: Option<Vec<(Symbol, Option<RelationshipMetadata>)>>,
     /// What calls this symbol (with relationship metadata including call site location)
     pub called_by: Option<Vec<(Symbol, Option<RelationshipMetadata>)>>,
 }
 
+/// A method promoted into a Go struct from a type it embeds
+///
+/// `type Person struct { User }` gets `User.SetAge` as `Person.SetAge`,
+/// unless Person declares a `SetAge` of its own.
+#[derive(Debug, Clone, Serialize)]
+pub struct PromotedMethod {
+    /// The method as declared on the embedded type
+    pub method: Symbol,
+    /// Embedded type declaring the method
+    pub origin: String,
+}
+
 bitflags! {
     /// Flags to control what context to include
     pub struct ContextIncludes: u8 {
@@ -65,6 +86,28 @@ impl SymbolContext {
         )
     }
 
+    /// Receiver of a method symbol, read from its signature
+    ///
+    /// Only Go signatures spell the receiver out, as `func (u *User) ...`.
+    pub fn method_receiver(symbol: &Symbol) -> Option<MethodReceiver> {
+        if symbol.kind != SymbolKind::Method {
+            return None;
+        }
+        crate::parsing::go::method_receiver(symbol.signature.as_deref()?)
+    }
+
+    /// Target of a type alias symbol, read from its signature
+    ///
+    /// Go aliases are signed `StringMap[V any] = Map[string, V]`, which gives
+    /// `Map[string, V]`.
+    pub fn alias_target(symbol: &Symbol) -> Option<String> {
+        if symbol.kind != SymbolKind::TypeAlias {
+            return None;
+        }
+        let (_, target) = symbol.signature.as_deref()?.split_once('=')?;
+        Some(target.trim().to_string())
+    }
+
     /// Format location with type info
     pub fn format_location_with_type(&self) -> String {
         format!(
@@ -107,6 +150,14 @@ impl SymbolContext {
             Self::write_multiline(output, sig, indent, 2);
         }
 
+        if let Some(receiver) = &self.receiver {
+            output.push_str(&format!("{indent}Receiver: {receiver}\n"));
+        }
+
+        if let Some(target) = &self.alias_target {
+            output.push_str(&format!("{indent}Alias of: {target}\n"));
+        }
+
         // Visibility for appropriate symbols
         if !matches!(self.symbol.visibility, Visibility::Private) {
             output.push_str(&format!(
@@ -186,6 +237,27 @@ impl SymbolContext {
             }
         }
 
+        // Methods promoted from embedded types
+        if let Some(promoted) = &self.relationships.promoted {
+            if !promoted.is_empty() {
+                output.push_str(&format!(
+                    "{}Promotes {} method(s):\n",
+                    indent,
+                    promoted.len()
+                ));
+                for PromotedMethod { method, origin } in promoted {
+                    output.push_str(&format!(
+                        "{}  - {} from {} at {} [symbol_id:{}]\n",
+                        indent,
+                        method.name,
+                        origin,
+                        SymbolContext::symbol_location(method),
+                        method.id.value()
+                    ));
+                }
+            }
+        }
+
         // Calls
         if let Some(calls) = &self.relationships.calls {
             if !calls.is_empty() {
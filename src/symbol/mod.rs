@@ -18,6 +18,39 @@ pub enum Visibility {
     Private,
 }
 
+/// How a method receives the value it is called on
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum ReceiverKind {
+    /// A copy of the value: Go's `func (u User)`
+    Value,
+    /// A pointer to the value, which the method may modify: `func (u *User)`
+    Pointer,
+}
+
+/// Receiver of a method, as declared
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct MethodReceiver {
+    /// Receiver variable; `None` when the receiver is unnamed or `_`
+    pub name: Option<String>,
+    pub kind: ReceiverKind,
+    /// Base type, without pointer or type arguments
+    pub type_name: String,
+}
+
+impl fmt::Display for MethodReceiver {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        if let Some(name) = &self.name {
+            write!(f, "{name} ")?;
+        }
+        let star = match self.kind {
+            ReceiverKind::Value => "",
+            ReceiverKind::Pointer => "*",
+        };
+        write!(f, "{star}{} ({:?})", self.type_name, self.kind)
+    }
+}
+
 /// Scope context for symbol definition
 ///
 /// This enum represents where a symbol is defined in the code structure,
@@ -323,6 +356,7 @@ impl CompactSymbol {
             11 => SymbolKind::Parameter,
             12 => SymbolKind::TypeAlias,
             13 => SymbolKind::Macro,
+            14 => SymbolKind::Label,
             _ => return None,
         };
 
@@ -443,6 +477,7 @@ mod tests {
             SymbolKind::Parameter,
             SymbolKind::TypeAlias,
             SymbolKind::Macro,
+            SymbolKind::Label,
         ];
 
         let mut string_table = StringTable::new();
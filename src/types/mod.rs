@@ -57,6 +57,8 @@ pub enum SymbolKind {
     Parameter,
     TypeAlias,
     Macro,
+    /// Target of `break`, `continue` and `goto`, scoped to its function
+    Label,
 }
 
 impl SymbolId {
@@ -135,6 +137,7 @@ impl FromStr for SymbolKind {
             "Parameter" => Ok(SymbolKind::Parameter),
             "TypeAlias" => Ok(SymbolKind::TypeAlias),
             "Macro" => Ok(SymbolKind::Macro),
+            "Label" => Ok(SymbolKind::Label),
             _ => Err("Unknown symbol kind"),
         }
     }
@@ -216,9 +219,10 @@ mod tests {
             SymbolKind::Parameter,
             SymbolKind::TypeAlias,
             SymbolKind::Macro,
+            SymbolKind::Label,
         ];
 
-        assert_eq!(kinds.len(), 14);
+        assert_eq!(kinds.len(), 15);
     }
 
     #[test]
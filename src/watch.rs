@@ -0,0 +1,105 @@
+//! Watch command implementation
+//!
+//! Runs the [`FileSystemWatcher`] in the foreground so indexed files are
+//! re-indexed as they change. With diagnostics enabled, every re-index is
+//! followed by a diagnostics pass over the indexed Go files and the delta
+//! since the previous pass is written to stdout as NDJSON, one
+//! [`DiagnosticEvent`] per line. Progress messages go to stderr.
+
+use crate::analyze::load_go_files;
+use crate::indexing::FileSystemWatcher;
+use crate::io::ExitCode;
+use crate::mcp::notifications::NotificationBroadcaster;
+use crate::parsing::go::analysis::{DiagnosticEvent, DiagnosticsTracker, find_diagnostics};
+use crate::{Settings, SimpleIndexer};
+use std::io::Write;
+use std::path::Path;
+use std::sync::Arc;
+use tokio::sync::RwLock;
+use tokio::sync::broadcast::error::RecvError;
+
+/// Execute the watch command
+pub async fn watch(
+    indexer: SimpleIndexer,
+    config: &Settings,
+    index_path: &Path,
+    diagnostics: bool,
+) -> ExitCode {
+    let indexer = Arc::new(RwLock::new(indexer));
+    let broadcaster = Arc::new(NotificationBroadcaster::new(100).with_debug(config.mcp.debug));
+    // Subscribe before the watcher starts so no change is missed
+    let mut events = broadcaster.subscribe();
+
+    let watcher = match FileSystemWatcher::new(
+        indexer.clone(),
+        config.file_watch.debounce_ms,
+        config.mcp.debug,
+        index_path,
+    ) {
+        Ok(watcher) => watcher.with_broadcaster(broadcaster.clone()),
+        Err(e) => {
+            eprintln!("Failed to create file system watcher: {e}");
+            return ExitCode::GeneralError;
+        }
+    };
+    let watch_task = tokio::spawn(async move { watcher.watch().await });
+
+    if !diagnostics {
+        return match watch_task.await {
+            Ok(Ok(())) => ExitCode::Success,
+            Ok(Err(e)) => {
+                eprintln!("File watcher error: {e}");
+                ExitCode::GeneralError
+            }
+            Err(e) => {
+                eprintln!("File watcher stopped: {e}");
+                ExitCode::GeneralError
+            }
+        };
+    }
+
+    let mut tracker = DiagnosticsTracker::new();
+    emit_diagnostics(&indexer, &mut tracker).await;
+
+    loop {
+        match events.recv().await {
+            // Missed events only mean the state is stale; recompute it
+            Ok(_) | Err(RecvError::Lagged(_)) => {
+                emit_diagnostics(&indexer, &mut tracker).await;
+            }
+            Err(RecvError::Closed) => break,
+        }
+    }
+
+    ExitCode::Success
+}
+
+/// Recompute diagnostics and print the changes as NDJSON
+///
+/// Diagnostics can cross files (a sibling declaring a missing function
+/// resolves a reference), so every indexed Go file is checked again.
+async fn emit_diagnostics(indexer: &RwLock<SimpleIndexer>, tracker: &mut DiagnosticsTracker) {
+    let files = {
+        let indexer = indexer.read().await;
+        load_go_files(&indexer)
+    };
+    let changes = tracker.update(find_diagnostics(&files));
+    if let Err(e) = write_events(&changes) {
+        eprintln!("Error writing diagnostics: {e}");
+    }
+    eprintln!(
+        "Diagnostics: {} change(s), {} active",
+        changes.len(),
+        tracker.len()
+    );
+}
+
+fn write_events(events: &[DiagnosticEvent]) -> std::io::Result<()> {
+    let stdout = std::io::stdout();
+    let mut out = stdout.lock();
+    for event in events {
+        serde_json::to_writer(&mut out, event)?;
+        out.write_all(b"\n")?;
+    }
+    out.flush()
+}
@@ -0,0 +1,19 @@
+package api
+
+import "example.com/aliases/models"
+
+// Account stands for models.User, not the User below
+type Account = models.User
+
+// User is the api's own user, unrelated to Account
+type User struct{}
+
+// Greeting shares its name with models.User.Greeting
+func (u User) Greeting() string {
+	return "api"
+}
+
+// Welcome calls models.User.Greeting through the alias
+func Welcome(a Account) string {
+	return a.Greeting()
+}
@@ -0,0 +1,11 @@
+package models
+
+// User is exported to the api package through an alias
+type User struct {
+	Name string
+}
+
+// Greeting is reached through api.Account
+func (u User) Greeting() string {
+	return "hello " + u.Name
+}
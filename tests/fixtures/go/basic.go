@@ -104,6 +104,20 @@ func createMultiplier(factor int) func(int) int {
 	}
 }
 
+// Function that never returns
+func fatalf(format string, args ...interface{}) {
+	mylog.Fatalf(format, args...)
+}
+
+// Function ending the program on every path
+func exitWith(err error) {
+	if err != nil {
+		fatalf("fatal: %v", err)
+	} else {
+		os.Exit(0)
+	}
+}
+
 // Main function
 func main() {
 	initialize()
@@ -0,0 +1,8 @@
+package config
+
+// InitGlobalDirs creates the global configuration directories
+func InitGlobalDirs() {
+	ensureDir("/etc/project")
+}
+
+func ensureDir(path string) {}
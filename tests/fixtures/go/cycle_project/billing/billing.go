@@ -0,0 +1,9 @@
+package billing
+
+import "example.com/shop/customers"
+
+// Charge bills the customer owning an order
+func Charge(orderID string, amount int) error {
+    customer := customers.Owner(orderID)
+    return customer.Debit(amount)
+}
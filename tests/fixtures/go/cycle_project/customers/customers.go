@@ -0,0 +1,21 @@
+package customers
+
+// Mid-refactor: order history moved here, closing a cycle through billing
+import "example.com/shop/orders"
+
+// Customer holds an account balance
+type Customer struct {
+    Balance int
+    History []orders.Order
+}
+
+// Owner finds the customer of an order
+func Owner(orderID string) *Customer {
+    return &Customer{}
+}
+
+// Debit takes an amount from the balance
+func (c *Customer) Debit(amount int) error {
+    c.Balance -= amount
+    return nil
+}
@@ -0,0 +1,21 @@
+package orders
+
+import (
+    "fmt"
+
+    "example.com/shop/billing"
+)
+
+// Order is a customer's purchase
+type Order struct {
+    ID    string
+    Total int
+}
+
+// Checkout bills the order
+func Checkout(o Order) error {
+    if err := billing.Charge(o.ID, o.Total); err != nil {
+        return fmt.Errorf("checkout %s: %w", o.ID, err)
+    }
+    return nil
+}
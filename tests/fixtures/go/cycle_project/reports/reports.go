@@ -0,0 +1,11 @@
+package reports
+
+import (
+    "example.com/shop/archive"
+    "example.com/shop/orders"
+)
+
+// Monthly archives the orders of a month
+func Monthly(month string, all []orders.Order) {
+    archive.Store(month, len(all))
+}
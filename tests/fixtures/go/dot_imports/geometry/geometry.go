@@ -0,0 +1,11 @@
+package geometry
+
+// Area of a square with side s
+func Area(s float64) float64 {
+	return s * s
+}
+
+// Scale multiplies a length by factor
+func Scale(length, factor float64) float64 {
+	return length * factor
+}
@@ -0,0 +1,15 @@
+// Package math stands in for the standard library package, which isn't
+// indexed: `import . "math"` in shapes.go resolves to these declarations
+package math
+
+// Pi is the ratio of a circle's circumference to its diameter
+const Pi = 3.14159265358979323846
+
+// Sqrt returns the square root of x
+func Sqrt(x float64) float64 {
+	z := x / 2
+	for i := 0; i < 10; i++ {
+		z -= (z*z - x) / (2 * z)
+	}
+	return z
+}
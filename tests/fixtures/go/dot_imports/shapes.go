@@ -0,0 +1,28 @@
+package main
+
+import (
+	. "math"
+
+	. "example.com/shapes/geometry"
+	. "example.com/shapes/units"
+)
+
+func circumference(r float64) float64 {
+	return 2 * Pi * r
+}
+
+func hypotenuse(a, b float64) float64 {
+	return Sqrt(a*a + b*b)
+}
+
+func square(s float64) float64 {
+	return Area(s)
+}
+
+// Scale is exported by both geometry and units: the compiler rejects this,
+// and the indexer reports it rather than picking one
+func doubled(length float64) float64 {
+	return Scale(length, 2)
+}
+
+func main() {}
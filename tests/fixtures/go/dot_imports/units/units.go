@@ -0,0 +1,6 @@
+package units
+
+// Scale converts meters to the given unit factor; geometry exports a Scale too
+func Scale(meters, factor float64) float64 {
+	return meters / factor
+}
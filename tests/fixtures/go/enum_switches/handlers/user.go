@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"example.com/app/legacy"
+	"example.com/app/models"
+)
+
+// Request carries the caller's role
+type Request struct {
+	Role models.UserRole
+}
+
+// CanDelete handles RoleAdmin by name and RoleUser by value
+func CanDelete(req *Request) bool {
+	switch req.Role {
+	case models.RoleAdmin, 1:
+		return true
+	}
+	return false
+}
+
+// CanView has a default case
+func CanView(role models.UserRole) bool {
+	switch role {
+	case models.RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanEdit handles legacy.RoleUser, which is not models.RoleUser
+func CanEdit(role models.UserRole) bool {
+	switch role {
+	case models.RoleAdmin, legacy.RoleUser:
+		return true
+	}
+	return false
+}
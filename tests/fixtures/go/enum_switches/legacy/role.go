@@ -0,0 +1,7 @@
+package legacy
+
+// Role codes of the old permission system, not models.UserRole constants
+const (
+	RoleGuest = 10
+	RoleUser  = 11
+)
@@ -0,0 +1,32 @@
+package models
+
+// UserRole is an enum of access levels
+type UserRole int
+
+const (
+	RoleGuest UserRole = iota
+	RoleUser
+	RoleAdmin
+)
+
+// String handles every role
+func (r UserRole) String() string {
+	switch r {
+	case RoleGuest:
+		return "guest"
+	case RoleUser:
+		return "user"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
+// Label misses RoleAdmin
+func (r UserRole) Label() string {
+	switch r {
+	case RoleGuest, RoleUser:
+		return "member"
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package dispatch
+
+import "context"
+
+type Job struct {
+	ID string
+}
+
+// JobProcessor is what the registry stores
+type JobProcessor interface {
+	Process(ctx context.Context, job Job) error
+	Name() string
+}
+
+type RetryingProcessor struct {
+	attempts int
+}
+
+func (r *RetryingProcessor) Process(ctx context.Context, job Job) error {
+	return nil
+}
+
+func (r *RetryingProcessor) Name() string {
+	return "retrying"
+}
+
+type DefaultProcessor struct{}
+
+func (p *DefaultProcessor) Process(ctx context.Context, job Job) error {
+	return nil
+}
+
+func (p *DefaultProcessor) Name() string {
+	return "default"
+}
+
+// Registry only ever stores DefaultProcessor values in processors, while
+// fallbacks holds both kinds
+type Registry struct {
+	processors map[string]JobProcessor
+	fallbacks  []JobProcessor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{processors: make(map[string]JobProcessor)}
+}
+
+func RegistryWithDefaults() *Registry {
+	registry := NewRegistry()
+	registry.processors["default"] = &DefaultProcessor{}
+	registry.processors["backup"] = &DefaultProcessor{}
+	registry.fallbacks = append(registry.fallbacks, &DefaultProcessor{}, &RetryingProcessor{})
+	return registry
+}
+
+// Calls dispatch to DefaultProcessor, the only type stored in processors
+func (r *Registry) RunAll(ctx context.Context, job Job) {
+	for _, processor := range r.processors {
+		processor.Process(ctx, job)
+	}
+}
+
+func (r *Registry) Lookup(name string) string {
+	found, ok := r.processors[name]
+	if !ok {
+		return ""
+	}
+	return found.Name()
+}
+
+// Calls stay on the interface: fallbacks holds two concrete types
+func (r *Registry) RunFallbacks(ctx context.Context, job Job) {
+	for _, fallback := range r.fallbacks {
+		fallback.Process(ctx, job)
+	}
+}
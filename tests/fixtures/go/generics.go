@@ -360,6 +360,43 @@ func ExampleUsage() {
 	total := Sum(numbers)
 	even := Filter(numbers, func(n int) bool { return n%2 == 0 })
 	doubled := Map(numbers, func(n int) int { return n * 2 })
+	first, second := Pair(1, "x")
 	
 	fmt.Printf("Total: %d, Even: %v, Doubled: %v\n", total, even, doubled)
-}
\ No newline at end of file
+	fmt.Println(first, second)
+}
+
+// Document is cached by CacheUsage
+type Document struct {
+	title string
+}
+
+func (d *Document) Serialize() ([]byte, error) {
+	return []byte(d.title), nil
+}
+
+func (d *Document) Deserialize(data []byte) error {
+	d.title = string(data)
+	return nil
+}
+
+func (d *Document) Title() string {
+	return d.title
+}
+
+// Values read back from a generic cache keep their concrete type
+func CacheUsage() string {
+	documents := NewCache[string, *Document](10)
+	documents.Put("readme", &Document{title: "README"})
+	doc, ok := documents.Get("readme")
+	if !ok {
+		return ""
+	}
+	return doc.Title()
+}
+
+// DocumentStore keeps documents in a generic cache
+type DocumentStore struct {
+	documents *Cache[string, *Document]
+	history   []*Stack[Document]
+}
@@ -78,6 +78,28 @@ type Writer interface {
 	Write(data []byte) (int, error)
 }
 
+// Summary describes a message by topic
+func (m Message) Summary() string {
+	return m.Topic + ": " + m.Content
+}
+
+// DrainTopic collects the summaries of a subscription
+func DrainTopic(broker MessageBroker, topic string) []string {
+	var summaries []string
+	for msg := range broker.Subscribe(topic) {
+		summaries = append(summaries, msg.Summary())
+	}
+	return summaries
+}
+
+// ForwardAll relays every message of a subscription to the logger
+func ForwardAll(broker MessageBroker, logger Logger) {
+	messages := broker.Subscribe("all")
+	for msg := range messages {
+		logger.Log("info", "%s", msg.Content)
+	}
+}
+
 // Concrete type implementing Stringer
 type User struct {
 	Name  string
@@ -88,6 +110,28 @@ func (u User) String() string {
 	return fmt.Sprintf("User{Name: %s, Email: %s}", u.Name, u.Email)
 }
 
+// Method expression assigned to a variable and called later
+func DescribeUser(u *User) string {
+	f := (*User).String
+	return f(u)
+}
+
+// Method value taken inside a closure and called later
+func DeferredDescription(u *User) func() string {
+	return func() string {
+		f := u.String
+		return f()
+	}
+}
+
+// Method value captured by a closure
+func LazyDescription(u *User) func() string {
+	describe := u.String
+	return func() string {
+		return describe()
+	}
+}
+
 // Concrete type implementing multiple interfaces
 type FileProcessor struct {
 	filename string
@@ -155,6 +199,16 @@ func (j *JSONProcessor) GetMetadata() map[string]interface{} {
 	return j.config
 }
 
+// Implementation of io.WriteCloser: Write is promoted from the embedded
+// io.Writer, Close is declared directly
+type ClosingWriter struct {
+	io.Writer
+}
+
+func (w ClosingWriter) Close() error {
+	return nil
+}
+
 // Implementation of Logger interface
 type SimpleLogger struct {
 	level string
@@ -215,6 +269,11 @@ func LogMessage(logger Logger, message string) {
 	logger.Log("INFO", message)
 }
 
+// LogEach spreads prepared arguments into the variadic Log
+func LogEach(logger Logger, format string, args []interface{}) {
+	logger.Log("DEBUG", format, args...)
+}
+
 func CopyData(src Reader, dst Writer) error {
 	buffer := make([]byte, 1024)
 	for {
@@ -246,6 +305,18 @@ func CreateProcessor(processorType string) DataProcessor {
 	}
 }
 
+// Concrete values assigned to interface variables
+func ProcessWithFile(data []byte) ([]byte, error) {
+	var p DataProcessor = &FileProcessor{filename: "input.txt"}
+	if !p.Validate(data) {
+		return nil, fmt.Errorf("invalid data")
+	}
+	var q DataProcessor
+	q = &JSONProcessor{}
+	_ = q.GetMetadata()
+	return p.Process(data)
+}
+
 // Function with interface parameter and return
 func WrapProcessor(processor DataProcessor) DataProcessor {
 	return &ProcessorWrapper{processor: processor}
@@ -324,4 +395,9 @@ func (m *MapContainer) Keys() []string {
 		keys = append(keys, key)
 	}
 	return keys
-}
\ No newline at end of file
+}
+// Narrowing p in ProcessWithFile leaves this p alone
+func ProcessWithJSON(data []byte) ([]byte, error) {
+	var p DataProcessor = &JSONProcessor{}
+	return p.Process(data)
+}
@@ -0,0 +1,12 @@
+package local
+
+import "fmt"
+
+// DoSomething is called by main through the relative import "./local"
+func DoSomething() {
+	fmt.Println(greeting())
+}
+
+func greeting() string {
+	return "from ./local"
+}
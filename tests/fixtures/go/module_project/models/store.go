@@ -0,0 +1,26 @@
+package models
+
+// User is persisted by a Store
+type User struct {
+    ID   string
+    Name string
+}
+
+// Store persists users; implemented in the services package
+type Store interface {
+    Save(u *User) error
+    Load(id string) (*User, error)
+}
+
+// versionedStore can only be implemented inside this package
+type versionedStore interface {
+    Store
+    version() int
+}
+
+// ResettableStore can be emptied between runs; UserStore only gets Reset in
+// its test file
+type ResettableStore interface {
+    Store
+    Reset()
+}
@@ -0,0 +1,18 @@
+package models
+
+import "strings"
+
+// NewUser creates a user with a normalized name
+func NewUser(id, name string) *User {
+    return &User{ID: id, Name: strings.TrimSpace(name)}
+}
+
+// Rename changes the display name
+func (u *User) Rename(name string) {
+    u.Name = strings.TrimSpace(name)
+}
+
+// Email is never exercised by a test
+func (u *User) Email(domain string) string {
+    return strings.ToLower(u.Name) + "@" + domain
+}
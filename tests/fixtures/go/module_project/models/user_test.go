@@ -0,0 +1,15 @@
+package models
+
+import "testing"
+
+func TestNewUser(t *testing.T) {
+    u := NewUser("1", " Ada ")
+    if u.Name != "Ada" {
+        t.Fatalf("name = %q", u.Name)
+    }
+}
+
+func TestRename(t *testing.T) {
+    u := &User{ID: "2"}
+    u.Rename("Grace")
+}
@@ -0,0 +1,23 @@
+package services
+
+import "time"
+
+// Session is an authenticated user session
+type Session struct {
+    UserID    string
+    ExpiresAt time.Time
+}
+
+// NewSession starts a session that lasts TokenExpiry
+func NewSession(userID string) *Session {
+    return &Session{UserID: userID, ExpiresAt: time.Now().Add(TokenExpiry)}
+}
+
+// CanConnect reports whether another session may open a connection
+func CanConnect(active int) bool {
+    limit := MaxConnections
+    if active < defaultPoolSize {
+        return true
+    }
+    return active < limit
+}
@@ -0,0 +1,26 @@
+package services
+
+import "time"
+
+// Connection limits shared by the services of this package
+const (
+    MaxConnections = 100
+    TokenExpiry    = 24 * time.Hour
+)
+
+// defaultPoolSize is unexported but visible to every file of the package
+const defaultPoolSize = 10
+
+// ConnectionPool hands out database connections
+type ConnectionPool struct {
+    open int
+}
+
+// Acquire takes a connection unless the pool is exhausted
+func (p *ConnectionPool) Acquire() bool {
+    if p.open >= MaxConnections {
+        return false
+    }
+    p.open++
+    return true
+}
@@ -0,0 +1,23 @@
+package services
+
+import "example.com/myproject/models"
+
+// UserStore keeps users in memory
+type UserStore struct {
+    users map[string]*models.User
+}
+
+func (s *UserStore) Save(u *models.User) error {
+    s.users[u.ID] = u
+    return nil
+}
+
+func (s *UserStore) Load(id string) (*models.User, error) {
+    return s.users[id], nil
+}
+
+// version has the name versionedStore requires, but unexported methods of
+// another package never match
+func (s *UserStore) version() int {
+    return 1
+}
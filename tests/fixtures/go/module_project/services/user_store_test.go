@@ -0,0 +1,15 @@
+package services
+
+import "example.com/myproject/models"
+
+// Reset is a test helper: UserStore only has it under go test
+func (s *UserStore) Reset() {
+    s.users = map[string]*models.User{}
+}
+
+// fakeStore is declared here, so all its methods count
+type fakeStore struct{}
+
+func (f *fakeStore) Save(u *models.User) error { return nil }
+func (f *fakeStore) Load(id string) (*models.User, error) { return nil, nil }
+func (f *fakeStore) Reset() {}
@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// Config is what raw settings convert to
+type Config struct {
+	Host string
+	port int
+}
+
+// rawConfig has the same fields, so it converts to Config
+type rawConfig struct {
+	Host string
+	port int
+}
+
+// Port returns the configured port, defaulting to 80
+func (c Config) Port() int {
+	if c.port == 0 {
+		return 80
+	}
+	return c.port
+}
+
+// Address joins host and port
+func (c *Config) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port())
+}
+
+func newConfig() *Config {
+	return &Config{Host: "localhost"}
+}
+
+func portOf(raw rawConfig) int {
+	return (Config(raw)).Port()
+}
+
+func hostOf(raw rawConfig) string {
+	return (Config(raw)).Host
+}
+
+func addressOf(p *Config) string {
+	return (*p).Address()
+}
+
+func defaultAddress() string {
+	return (newConfig()).Address()
+}
+
+func literalPort() int {
+	return (&Config{port: 8080}).Port()
+}
+
+func convertedAddress(p *rawConfig) string {
+	return (*Config)(p).Address()
+}
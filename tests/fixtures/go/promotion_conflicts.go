@@ -0,0 +1,60 @@
+package promotion
+
+// File and Conn both have a Close method
+type File struct {
+	path string
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+func (f *File) Name() string {
+	return f.path
+}
+
+type Conn struct {
+	addr string
+}
+
+func (c *Conn) Close() error {
+	return nil
+}
+
+func (c *Conn) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// Session promotes Close from both File and Conn at depth 1: ambiguous
+type Session struct {
+	*File
+	Conn
+}
+
+// ManagedSession declares its own Close, which hides both promoted ones
+type ManagedSession struct {
+	File
+	Conn
+}
+
+func (m *ManagedSession) Close() error {
+	return nil
+}
+
+// Pool gets Close from Conn at depth 1, which wins over the depth 2 conflict
+// inside Session
+type Pool struct {
+	*Conn
+	Session
+}
+
+// Shutdown does not compile: s.Close is an ambiguous selector
+func Shutdown(s *Session) {
+	s.Name()
+	s.Write(nil)
+	s.Close()
+}
+
+func Drain(p *Pool) {
+	p.Close()
+}
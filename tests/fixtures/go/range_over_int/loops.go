@@ -0,0 +1,17 @@
+package loops
+
+// Go 1.22 range over an integer: i is an int in the loop's block scope
+// and there is no value variable
+func RangeOverInt(n int, items map[int]int) int {
+	total := 0
+	for i := range 10 {
+		total += i
+	}
+	for j := range n {
+		total += j
+	}
+	for k, v := range items {
+		total += k + v
+	}
+	return total
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+
+	"example.com/app/config"
 )
 
 // Package-level declarations (package scope)
@@ -180,6 +182,22 @@ func (c *Config) UpdatePort(newPort int) {
 	}
 }
 
+// A local variable shadowing an imported package (import scope)
+func LoadSettings(path string) int {
+	// Before the declaration, config is the imported package
+	if config.Mode == config.Production {
+		return 0
+	}
+
+	// From here on, config is the local Config value
+	config := Config{Name: path, Port: 8080}
+	config.UpdatePort(9090)
+	if config.Port == MAX_RETRIES {
+		return 0
+	}
+	return config.Port
+}
+
 // Function demonstrating variable shadowing at multiple levels
 func DemonstrateScoping() {
 	fmt.Println("=== Go Scoping Demonstration ===")
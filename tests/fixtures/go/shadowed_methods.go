@@ -0,0 +1,22 @@
+package views
+
+import "example.com/app/models"
+
+type Page struct{}
+
+type Card struct{}
+
+type catalog struct{}
+
+func (catalog) Load() string { return "" }
+
+// Render reads a local catalog that shadows the models package
+func (p *Page) Render() string {
+	models := catalog{}
+	return models.Load()
+}
+
+// Render on Card calls the models package
+func (c *Card) Render() string {
+	return models.Load()
+}
@@ -0,0 +1,7 @@
+package shared
+
+// Helper is called by module_project/main.go through the relative import
+// "../shared"
+func Helper() string {
+	return "shared"
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import "database/sql"
+
+type Driver struct{}
+
+func init() {
+	sql.Register("postgres", &Driver{})
+}
+
+func init() {
+	defaultOptions = Options{SSLMode: "disable"}
+}
+
+type Options struct {
+	SSLMode string
+}
+
+var defaultOptions Options
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "example.com/plugins/drivers/postgres" // registers the "postgres" driver
+	_ "net/http/pprof"
+)
+
+func main() {
+	db, err := sql.Open("postgres", "dbname=app")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer db.Close()
+}
@@ -204,4 +204,65 @@ func CreatePerson(firstName, lastName, email string) Person {
 			Country: "Unknown",
 		},
 	}
-}
\ No newline at end of file
+}
+
+// Function calling methods through explicit address-of and dereference
+func ReceiverAdjustments(p *Person) string {
+	u := User{Name: "Explicit"}
+	(&u).Verify()
+	return (*p).GetFullName()
+}
+
+// Function calling methods on elements of make and append results
+func VerifyAll(n int) []User {
+	users := make([]User, n)
+	users[0].Verify()
+	users = append(users, User{Name: "Appended"})
+	for i := range users {
+		users[i].SetAge(30)
+	}
+	return users
+}
+
+// InnerStruct is exported under another name through an alias
+type InnerStruct struct {
+	Value int
+}
+
+func (i InnerStruct) Describe() string {
+	return "inner"
+}
+
+// PublicInnerStruct shares the methods of InnerStruct
+type PublicInnerStruct = InnerStruct
+
+// Function calling a method through the alias
+func DescribeAlias(p PublicInnerStruct) string {
+	return p.Describe()
+}
+
+// Registry returning a map whose elements have methods
+func HandlerRegistry() map[string]*Handler {
+	return map[string]*Handler{"default": NewHandler("default", nil)}
+}
+
+// Contacts returns a slice whose elements have methods
+func (p Person) Contacts() []User {
+	return []User{p.User}
+}
+
+// Function calling methods on elements of call results indexed in place
+func IndexedCallResults(p Person) error {
+	p.Contacts()[0].Verify()
+	return HandlerRegistry()["default"].Execute(nil)
+}
+
+// Function calling a method Person gets from the embedded User
+func CelebrateBirthday(person *Person) {
+	person.SetAge(30)
+}
+
+// Function reading a field Person gets from the embedded User
+func ContactEmail(person Person) string {
+	return person.Email
+}
@@ -0,0 +1,80 @@
+package references
+
+// Job is referenced once from every position a type can be named in
+type Job struct {
+	ID int
+}
+
+// legacyJob has Job's underlying type, so it converts to Job
+type legacyJob struct {
+	ID int
+}
+
+// Field type
+type Queue struct {
+	pending []Job
+}
+
+// Embedded field
+type PriorityJob struct {
+	Job
+	Priority int
+}
+
+// Parameter
+func Submit(job Job) {}
+
+// Result
+func NextJob() *Job {
+	return nil
+}
+
+// Type assertion
+func IsJob(value any) bool {
+	_, ok := value.(Job)
+	return ok
+}
+
+// Type switch case
+func Describe(value any) string {
+	switch value.(type) {
+	case *Job:
+		return "job"
+	}
+	return "unknown"
+}
+
+// Conversion
+func Upgrade(old legacyJob) any {
+	return Job(old)
+}
+
+// Composite literal
+func Sample() any {
+	return Job{ID: 1}
+}
+
+// Type argument
+func First[T any](items []T) (T, bool) {
+	var zero T
+	if len(items) == 0 {
+		return zero, false
+	}
+	return items[0], true
+}
+
+func FirstJob(items []any) bool {
+	_, ok := First[Job](nil)
+	return ok && len(items) > 0
+}
+
+// make
+func Buffer() int {
+	jobs := make([]Job, 0, 8)
+	return cap(jobs)
+}
+
+// new
+func Allocate() any {
+	return new(Job)
+}